@@ -0,0 +1,173 @@
+package games
+
+import (
+	"base/app/models"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAchievementNotFound is returned when the game has no achievement with the slug
+	ErrAchievementNotFound = errors.New("achievement not found")
+	// ErrAchievementSlugTaken is returned when the game already has an achievement with the slug
+	ErrAchievementSlugTaken = errors.New("achievement slug already exists for this game")
+	// ErrInvalidAchievement is returned for an achievement without slug or title, or with negative points
+	ErrInvalidAchievement = errors.New("achievement slug and title are required and points must not be negative")
+)
+
+// CreateAchievementRequest is the payload for defining an achievement of a
+// game. Criteria holds the stat thresholds that earn it, e.g.
+// {"max_level": 5}.
+type CreateAchievementRequest struct {
+	Slug        string                 `json:"slug" binding:"required" example:"level_5"`
+	Title       string                 `json:"title" binding:"required" example:"Level 5"`
+	Description string                 `json:"description" example:"Reach level 5"`
+	Points      int                    `json:"points" binding:"min=0" example:"10"`
+	Icon        string                 `json:"icon" example:"level-5.png"`
+	Criteria    map[string]interface{} `json:"criteria"`
+}
+
+// UpdateAchievementRequest changes the fields that are set
+type UpdateAchievementRequest struct {
+	Slug        *string                `json:"slug" example:"level_5"`
+	Title       *string                `json:"title" example:"Level 5"`
+	Description *string                `json:"description" example:"Reach level 5"`
+	Points      *int                   `json:"points" binding:"omitempty,min=0" example:"10"`
+	Icon        *string                `json:"icon" example:"level-5.png"`
+	Criteria    map[string]interface{} `json:"criteria"`
+}
+
+// CreateAchievement defines a new achievement of the game and emits
+// games.achievement.defined
+func (s *Service) CreateAchievement(gameSlug string, req *CreateAchievementRequest) (*models.Achievement, error) {
+	game, err := s.GetGame(gameSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	achievement := models.Achievement{
+		GameId:      game.Id,
+		Slug:        strings.ToLower(strings.TrimSpace(req.Slug)),
+		Title:       strings.TrimSpace(req.Title),
+		Description: req.Description,
+		Points:      req.Points,
+		Icon:        req.Icon,
+	}
+	if achievement.Criteria, err = encodeCriteria(req.Criteria); err != nil {
+		return nil, err
+	}
+	if err := s.saveAchievement(&achievement); err != nil {
+		return nil, err
+	}
+
+	return &achievement, nil
+}
+
+// UpdateAchievement changes an achievement of the game and emits
+// games.achievement.defined
+func (s *Service) UpdateAchievement(gameSlug, slug string, req *UpdateAchievementRequest) (*models.Achievement, error) {
+	achievement, err := s.getAchievement(gameSlug, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Slug != nil {
+		achievement.Slug = strings.ToLower(strings.TrimSpace(*req.Slug))
+	}
+	if req.Title != nil {
+		achievement.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Description != nil {
+		achievement.Description = *req.Description
+	}
+	if req.Points != nil {
+		achievement.Points = *req.Points
+	}
+	if req.Icon != nil {
+		achievement.Icon = *req.Icon
+	}
+	if req.Criteria != nil {
+		if achievement.Criteria, err = encodeCriteria(req.Criteria); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.saveAchievement(achievement); err != nil {
+		return nil, err
+	}
+
+	return achievement, nil
+}
+
+// DeleteAchievement removes an achievement of the game. Players keep the
+// unlocks they earned, and games.achievement.deleted is emitted.
+func (s *Service) DeleteAchievement(gameSlug, slug string) error {
+	achievement, err := s.getAchievement(gameSlug, slug)
+	if err != nil {
+		return err
+	}
+
+	if err := s.DB.Delete(achievement).Error; err != nil {
+		return err
+	}
+
+	s.Emitter.EmitAsync("games.achievement.deleted", achievement)
+	return nil
+}
+
+// getAchievement returns the achievement of the game with the slug
+func (s *Service) getAchievement(gameSlug, slug string) (*models.Achievement, error) {
+	game, err := s.GetGame(gameSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	var achievement models.Achievement
+	if err := s.DB.Where("game_id = ? AND slug = ?", game.Id, slug).First(&achievement).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAchievementNotFound
+		}
+		return nil, err
+	}
+	return &achievement, nil
+}
+
+// saveAchievement validates and stores the achievement, rejecting a slug
+// another achievement of the game already uses
+func (s *Service) saveAchievement(achievement *models.Achievement) error {
+	if achievement.Slug == "" || achievement.Title == "" || achievement.Points < 0 {
+		return ErrInvalidAchievement
+	}
+
+	var count int64
+	err := s.DB.Model(&models.Achievement{}).
+		Where("game_id = ? AND slug = ? AND id <> ?", achievement.GameId, achievement.Slug, achievement.Id).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAchievementSlugTaken
+	}
+
+	if err := s.DB.Save(achievement).Error; err != nil {
+		return err
+	}
+
+	s.Emitter.EmitAsync("games.achievement.defined", achievement)
+	return nil
+}
+
+// encodeCriteria marshals criteria, storing an empty object when there are none
+func encodeCriteria(criteria map[string]interface{}) (string, error) {
+	if criteria == nil {
+		criteria = map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(criteria)
+	if err != nil {
+		return "", errors.New("invalid criteria format")
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,101 @@
+package games
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"base/app/models"
+	"base/core/router"
+)
+
+func TestDefineAchievementRoutes(t *testing.T) {
+	c := newTestController(t)
+	owner := createUser(t, c.Service.DB, "Owner")
+	member := createUser(t, c.Service.DB, "Member")
+	for _, slug := range []string{"tetris", "snake"} {
+		if _, err := c.Service.CreateGame(&CreateGameRequest{Slug: slug, Title: slug}); err != nil {
+			t.Fatalf("CreateGame: %v", err)
+		}
+	}
+	defined := make(chan *models.Achievement, 8)
+	c.Service.Emitter.On("games.achievement.defined", func(data any) {
+		defined <- data.(*models.Achievement)
+	})
+
+	do := func(userId uint, method, path, body string) *httptest.ResponseRecorder {
+		c.Authenticator = asUser(userId)
+		r := router.New()
+		c.Routes(r.Group("/api"))
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	level5 := `{"slug":"level_5","title":"Level 5","points":10,"criteria":{"max_level":5}}`
+
+	w := do(owner, http.MethodPost, "/api/games/tetris/achievements", level5)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", w.Code, w.Body)
+	}
+	var created struct {
+		Achievement models.Achievement `json:"achievement"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if a := created.Achievement; a.Slug != "level_5" || a.Points != 10 || a.Criteria != `{"max_level":5}` {
+		t.Errorf("created %+v", a)
+	}
+	select {
+	case a := <-defined:
+		if a.Id != created.Achievement.Id {
+			t.Errorf("games.achievement.defined carried %d, want %d", a.Id, created.Achievement.Id)
+		}
+	case <-time.After(time.Second):
+		t.Error("games.achievement.defined was not emitted")
+	}
+
+	tests := []struct {
+		name         string
+		userId       uint
+		method, path string
+		body         string
+		want         int
+	}{
+		{"duplicate slug", owner, http.MethodPost, "/api/games/tetris/achievements", `{"slug":" Level_5 ","title":"Again"}`, http.StatusConflict},
+		{"same slug in another game", owner, http.MethodPost, "/api/games/snake/achievements", level5, http.StatusCreated},
+		{"second achievement", owner, http.MethodPost, "/api/games/tetris/achievements", `{"slug":"level_10","title":"Level 10"}`, http.StatusCreated},
+		{"member", member, http.MethodPost, "/api/games/tetris/achievements", `{"slug":"cheat","title":"Cheat"}`, http.StatusForbidden},
+		{"negative points", owner, http.MethodPost, "/api/games/tetris/achievements", `{"slug":"minus","title":"Minus","points":-1}`, http.StatusBadRequest},
+		{"missing title", owner, http.MethodPost, "/api/games/tetris/achievements", `{"slug":"untitled"}`, http.StatusBadRequest},
+		{"unknown game", owner, http.MethodPost, "/api/games/chess/achievements", level5, http.StatusNotFound},
+		{"rename onto a taken slug", owner, http.MethodPut, "/api/games/tetris/achievements/level_10", `{"slug":"level_5"}`, http.StatusConflict},
+		{"update", owner, http.MethodPut, "/api/games/tetris/achievements/level_10", `{"title":"Ten levels","points":20}`, http.StatusOK},
+		{"update unknown", owner, http.MethodPut, "/api/games/tetris/achievements/level_99", `{"title":"x"}`, http.StatusNotFound},
+		{"member deleting", member, http.MethodDelete, "/api/games/tetris/achievements/level_5", "", http.StatusForbidden},
+		{"delete", owner, http.MethodDelete, "/api/games/tetris/achievements/level_5", "", http.StatusOK},
+		{"delete again", owner, http.MethodDelete, "/api/games/tetris/achievements/level_5", "", http.StatusNotFound},
+		{"reuse a deleted slug", owner, http.MethodPost, "/api/games/tetris/achievements", level5, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		if w := do(tt.userId, tt.method, tt.path, tt.body); w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+
+	var updated models.Achievement
+	c.Service.DB.Where("slug = ?", "level_10").First(&updated)
+	if updated.Title != "Ten levels" || updated.Points != 20 || updated.Criteria != "{}" {
+		t.Errorf("updated achievement %+v", updated)
+	}
+	var count int64
+	c.Service.DB.Model(&models.Achievement{}).Count(&count)
+	if count != 3 {
+		t.Errorf("%d achievements, want 3", count)
+	}
+}
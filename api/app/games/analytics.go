@@ -0,0 +1,248 @@
+package games
+
+import (
+	"base/app/models"
+	"base/core/database"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// analyticsCacheTTL is how long computed analytics are served before
+	// being recomputed
+	analyticsCacheTTL = time.Minute
+
+	// DefaultAnalyticsStat is the stat whose distribution is reported when
+	// the request names none
+	DefaultAnalyticsStat = "score"
+	// DefaultAnalyticsBuckets and MaxAnalyticsBuckets bound the distribution
+	DefaultAnalyticsBuckets = 10
+	MaxAnalyticsBuckets     = 50
+)
+
+// ErrInvalidBuckets is returned for a bucket count outside 1..MaxAnalyticsBuckets
+var ErrInvalidBuckets = fmt.Errorf("buckets must be between 1 and %d", MaxAnalyticsBuckets)
+
+// GameAnalytics are aggregate metrics of a game
+type GameAnalytics struct {
+	Game                 string                 `json:"game"`
+	TotalPlayers         int64                  `json:"total_players"` // Users with progress or stats
+	TotalProgressRecords int64                  `json:"total_progress_records"`
+	Achievements         []AchievementAnalytics `json:"achievements"`
+	Stat                 *StatDistribution      `json:"stat,omitempty"` // Omitted when the database cannot read JSON numbers
+	GeneratedAt          time.Time              `json:"generated_at"`
+}
+
+// AchievementAnalytics is how often an achievement was unlocked. UnlockRate
+// is the share of TotalPlayers, from 0 to 1.
+type AchievementAnalytics struct {
+	Slug       string  `json:"slug"`
+	Title      string  `json:"title"`
+	Unlocks    int64   `json:"unlocks"`
+	UnlockRate float64 `json:"unlock_rate"`
+}
+
+// StatDistribution summarizes a numeric stat over the players that have it
+type StatDistribution struct {
+	Path    string       `json:"path"`
+	Players int64        `json:"players"`
+	Min     float64      `json:"min"`
+	Max     float64      `json:"max"`
+	Average float64      `json:"average"`
+	Buckets []StatBucket `json:"buckets"`
+}
+
+// StatBucket counts the players whose stat is at least From and below To;
+// the last bucket includes To
+type StatBucket struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int64   `json:"count"`
+}
+
+type analyticsEntry struct {
+	analytics *GameAnalytics
+	expiresAt time.Time
+}
+
+// analyticsCache holds recently computed analytics per game, stat and bucket count
+var analyticsCache = struct {
+	sync.Mutex
+	entries map[string]analyticsEntry
+}{entries: make(map[string]analyticsEntry)}
+
+// GetAnalytics returns the aggregate metrics of a game with the distribution
+// of the numeric stat at statPath split into buckets of equal width. Results
+// are cached for analyticsCacheTTL.
+func (s *Service) GetAnalytics(gameSlug string, statPath string, buckets int) (*GameAnalytics, error) {
+	segments, err := models.ParseJSONPath(statPath)
+	if err != nil {
+		return nil, err
+	}
+	if buckets < 1 || buckets > MaxAnalyticsBuckets {
+		return nil, ErrInvalidBuckets
+	}
+
+	key := fmt.Sprintf("%s|%s|%d", gameSlug, strings.Join(segments, "."), buckets)
+	now := time.Now()
+	analyticsCache.Lock()
+	entry, ok := analyticsCache.entries[key]
+	analyticsCache.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.analytics, nil
+	}
+
+	analytics, err := s.computeAnalytics(gameSlug, segments, buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	analyticsCache.Lock()
+	for k, e := range analyticsCache.entries {
+		if now.After(e.expiresAt) {
+			delete(analyticsCache.entries, k)
+		}
+	}
+	analyticsCache.entries[key] = analyticsEntry{analytics: analytics, expiresAt: now.Add(analyticsCacheTTL)}
+	analyticsCache.Unlock()
+
+	return analytics, nil
+}
+
+func (s *Service) computeAnalytics(gameSlug string, segments []string, buckets int) (*GameAnalytics, error) {
+	var game models.Game
+	if err := s.DB.Where("slug = ?", gameSlug).First(&game).Error; err != nil {
+		return nil, ErrGameNotFound
+	}
+
+	db := database.Reader(s.DB)
+	analytics := &GameAnalytics{
+		Game:         game.Slug,
+		Achievements: []AchievementAnalytics{},
+		GeneratedAt:  time.Now(),
+	}
+
+	err := db.Raw(`SELECT COUNT(*) FROM (
+			SELECT user_id FROM game_progress WHERE game_id = ? AND deleted_at IS NULL
+			UNION
+			SELECT user_id FROM player_stats WHERE game_id = ? AND deleted_at IS NULL
+		) players`, game.Id, game.Id).Scan(&analytics.TotalPlayers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Model(&models.GameProgress{}).Where("game_id = ?", game.Id).Count(&analytics.TotalProgressRecords).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Table("achievements").
+		Select("achievements.slug, achievements.title, COUNT(user_achievements.id) AS unlocks").
+		Joins("LEFT JOIN user_achievements ON user_achievements.achievement_id = achievements.id AND user_achievements.unlocked_at IS NOT NULL AND user_achievements.deleted_at IS NULL").
+		Where("achievements.game_id = ? AND achievements.deleted_at IS NULL", game.Id).
+		Group("achievements.id, achievements.slug, achievements.title").
+		Order("achievements.id ASC").
+		Scan(&analytics.Achievements).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range analytics.Achievements {
+		if analytics.TotalPlayers > 0 {
+			analytics.Achievements[i].UnlockRate = float64(analytics.Achievements[i].Unlocks) / float64(analytics.TotalPlayers)
+		}
+	}
+
+	analytics.Stat, err = s.statDistribution(game.Id, segments, buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	return analytics, nil
+}
+
+// statDistribution computes the summary of a numeric stat and then counts the
+// players per bucket, both with SQL aggregates. It returns nil for dialects
+// without JSON support.
+func (s *Service) statDistribution(gameId uint, segments []string, buckets int) (*StatDistribution, error) {
+	db := database.Reader(s.DB)
+	expr := models.JSONNumberExpr(db.Dialector.Name(), "stats", segments)
+	if expr == "" {
+		return nil, nil
+	}
+
+	var summary struct {
+		Players int64
+		Min     *float64
+		Max     *float64
+		Average *float64
+	}
+	err := db.Model(&models.PlayerStats{}).
+		Select(fmt.Sprintf("COUNT(%[1]s) AS players, MIN(%[1]s) AS min, MAX(%[1]s) AS max, AVG(%[1]s) AS average", expr)).
+		Where("game_id = ?", gameId).
+		Scan(&summary).Error
+	if err != nil {
+		return nil, err
+	}
+
+	distribution := &StatDistribution{
+		Path:    strings.Join(segments, "."),
+		Players: summary.Players,
+		Buckets: []StatBucket{},
+	}
+	if summary.Players == 0 || summary.Min == nil || summary.Max == nil || summary.Average == nil {
+		return distribution, nil
+	}
+	distribution.Min, distribution.Max, distribution.Average = *summary.Min, *summary.Max, *summary.Average
+
+	// A single value needs a single bucket
+	if distribution.Min == distribution.Max {
+		buckets = 1
+	}
+	width := (distribution.Max - distribution.Min) / float64(buckets)
+
+	columns := make([]string, buckets)
+	args := make([]interface{}, 0, buckets*2)
+	for i := range columns {
+		from := distribution.Min + float64(i)*width
+		to := from + width
+		if i == buckets-1 {
+			to = distribution.Max
+			columns[i] = fmt.Sprintf("SUM(CASE WHEN %s >= ? AND %s <= ? THEN 1 ELSE 0 END) AS b%d", expr, expr, i)
+		} else {
+			columns[i] = fmt.Sprintf("SUM(CASE WHEN %s >= ? AND %s < ? THEN 1 ELSE 0 END) AS b%d", expr, expr, i)
+		}
+		args = append(args, from, to)
+		distribution.Buckets = append(distribution.Buckets, StatBucket{From: from, To: to})
+	}
+
+	rows, err := db.Model(&models.PlayerStats{}).
+		Select(strings.Join(columns, ", "), args...).
+		Where("game_id = ?", gameId).
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, errors.New("stat distribution returned no row")
+	}
+	counts := make([]*int64, buckets)
+	targets := make([]interface{}, buckets)
+	for i := range counts {
+		targets[i] = &counts[i]
+	}
+	if err := rows.Scan(targets...); err != nil {
+		return nil, err
+	}
+	for i, count := range counts {
+		if count != nil {
+			distribution.Buckets[i].Count = *count
+		}
+	}
+
+	return distribution, rows.Err()
+}
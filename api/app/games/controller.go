@@ -1,14 +1,133 @@
 package games
 
 import (
+	"base/app/models"
+	"base/core/app/authorization"
 	"base/core/logger"
 	"base/core/router"
+	"base/core/types"
+	"base/core/websocket"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"mime"
 	"strconv"
+	"time"
 )
 
 type Controller struct {
 	Service *Service
 	Logger  logger.Logger
+	// Events feeds the live event streams served by StreamEvents
+	Events *EventBroker
+	// Authenticator resolves the user for every game route; requests without a
+	// valid token are rejected before reaching a handler
+	Authenticator router.MiddlewareFunc
+	// Hub tracks the players connected over WebSocket; nil when WebSockets
+	// are disabled
+	Hub *websocket.Hub
+	// Authz checks the roles and permissions of the guarded routes
+	Authz *authorization.AuthorizationService
+}
+
+// requireUser rejects requests without an authenticated user, so handlers can
+// read "user_id" without checking it
+func requireUser(next router.HandlerFunc) router.HandlerFunc {
+	return func(ctx *router.Context) error {
+		if ctx.GetUint("user_id") == 0 {
+			return ctx.JSON(401, ErrorResponse{Error: "Unauthorized"})
+		}
+		return next(ctx)
+	}
+}
+
+// requireAdmin rejects requests from users that do not hold an administrative
+// role, as the game catalogue is managed by administrators
+func (c *Controller) requireAdmin() router.MiddlewareFunc {
+	return authorization.RequireAnyRole(c.Authz, "Owner", "Administrator")
+}
+
+// requirePermission rejects requests from users whose role does not grant
+// action on resourceType
+func (c *Controller) requirePermission(resourceType, action string) router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(ctx *router.Context) error {
+			granted, err := c.Authz.CheckPermissions(uint64(ctx.GetUint("user_id")), []authorization.PermissionCheckItem{
+				{ResourceType: resourceType, Action: action},
+			})
+			if err != nil {
+				c.Logger.Error("Failed to check permission", logger.String("error", err.Error()))
+				return ctx.JSON(500, ErrorResponse{Error: "Failed to check permission"})
+			}
+			if !granted[0] {
+				return ctx.JSON(403, ErrorResponse{Error: fmt.Sprintf("Permission %s:%s required", resourceType, action)})
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// @Summary List games
+// @Description Get all registered games
+// @Tags Games
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} GamesResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /games [get]
+func (c *Controller) ListGames(ctx *router.Context) error {
+	games, err := c.Service.ListGames()
+	if err != nil {
+		c.Logger.Error("Failed to list games", logger.String("error", err.Error()))
+		return ctx.JSON(500, ErrorResponse{Error: "Failed to list games"})
+	}
+
+	return ctx.JSON(200, GamesResponse{Games: games})
+}
+
+// @Summary Register game
+// @Description Register a new game (administrators only). progress_schema and stats_schema optionally declare JSON schemas that saved progress and stats must match.
+// @Tags Games
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param game body CreateGameRequest true "Game data"
+// @Success 201 {object} GameResponse
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /games [post]
+func (c *Controller) CreateGame(ctx *router.Context) error {
+	var req CreateGameRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return ctx.JSON(400, types.NewBindErrorResponse(err, "Invalid request body"))
+	}
+
+	if req.Slug == "" || req.Title == "" {
+		return ctx.JSON(400, ErrorResponse{Error: "Slug and title are required"})
+	}
+
+	game, err := c.Service.CreateGame(&req)
+	if err != nil {
+		if errors.Is(err, ErrGameSlugTaken) {
+			return ctx.JSON(409, ErrorResponse{Error: err.Error()})
+		}
+		if errors.Is(err, models.ErrInvalidSchema) {
+			return ctx.JSON(400, ErrorResponse{Error: err.Error()})
+		}
+		c.Logger.Error("Failed to create game", logger.String("error", err.Error()))
+		return ctx.JSON(500, ErrorResponse{Error: "Failed to create game"})
+	}
+
+	return ctx.JSON(201, GameResponse{
+		Game:    game,
+		Message: "Game created successfully",
+	})
 }
 
 // @Summary Get game progress
@@ -18,101 +137,103 @@ type Controller struct {
 // @Produce json
 // @Security BearerAuth
 // @Param game_slug path string true "Game slug (e.g., multiplex, tetris)"
-// @Success 200 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Success 200 {object} ProgressResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
 // @Router /games/{game_slug}/progress [get]
 func (c *Controller) GetProgress(ctx *router.Context) error {
-	userIdVal, _ := ctx.Get("user_id")
-	userId := userIdVal.(uint)
+	userId := ctx.GetUint("user_id")
 	gameSlug := ctx.Param("game_slug")
 
 	progress, err := c.Service.GetProgress(userId, gameSlug)
 	if err != nil {
 		c.Logger.Error("Failed to get progress", logger.String("error", err.Error()))
-		return ctx.JSON(500, map[string]interface{}{
-			"error": "Failed to get progress",
-		})
+		return ctx.JSON(500, ErrorResponse{Error: "Failed to get progress"})
 	}
 
-	return ctx.JSON(200, map[string]interface{}{
-		"progress": progress,
-	})
+	return ctx.JSON(200, ProgressResponse{Progress: progress})
 }
 
 // @Summary Save game progress
-// @Description Save the game progress for the authenticated user
+// @Description Save the game progress for the authenticated user. When the game declares a progress schema, data that does not match it is rejected with 400 and the violations in details.
 // @Tags Games
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param game_slug path string true "Game slug (e.g., multiplex, tetris)"
-// @Param data body map[string]interface{} true "Game progress data"
-// @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Param data body SaveProgressRequest true "Game progress data"
+// @Success 200 {object} ProgressResponse
+// @Failure 400 {object} SchemaErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
 // @Router /games/{game_slug}/progress [post]
 func (c *Controller) SaveProgress(ctx *router.Context) error {
-	userIdVal, _ := ctx.Get("user_id")
-	userId := userIdVal.(uint)
+	userId := ctx.GetUint("user_id")
 	gameSlug := ctx.Param("game_slug")
 
-	var data map[string]interface{}
-	if err := ctx.Bind(&data); err != nil {
-		return ctx.JSON(400, map[string]interface{}{
-			"error": "Invalid request body",
-		})
+	var data SaveProgressRequest
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		return ctx.JSON(400, types.NewBindErrorResponse(err, "Invalid request body"))
 	}
 
 	progress, err := c.Service.SaveProgress(userId, gameSlug, data)
 	if err != nil {
+		var invalid *models.SchemaValidationError
+		if errors.As(err, &invalid) {
+			return ctx.JSON(400, SchemaErrorResponse{
+				Error:   "Progress does not match the game's schema",
+				Details: invalid.Violations,
+			})
+		}
 		c.Logger.Error("Failed to save progress", logger.String("error", err.Error()))
-		return ctx.JSON(500, map[string]interface{}{
-			"error": "Failed to save progress",
-		})
+		return ctx.JSON(500, ErrorResponse{Error: "Failed to save progress"})
 	}
 
-	return ctx.JSON(200, map[string]interface{}{
-		"progress": progress,
-		"message":  "Progress saved successfully",
+	return ctx.JSON(200, ProgressResponse{
+		Progress: progress,
+		Message:  "Progress saved successfully",
 	})
 }
 
 // @Summary Get available achievements
-// @Description Get all available achievements for a game
+// @Description Get a page of achievements for a game, each flagged with whether the authenticated user has unlocked it
 // @Tags Games
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param game_slug path string true "Game slug (e.g., multiplex, tetris)"
-// @Success 200 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of achievements per page (max 100)" default(10)
+// @Success 200 {object} types.PaginatedResponse{data=[]AchievementStatus}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
 // @Router /games/{game_slug}/achievements [get]
 func (c *Controller) GetAchievements(ctx *router.Context) error {
 	gameSlug := ctx.Param("game_slug")
+	userId := ctx.GetUint("user_id")
 
-	achievements, err := c.Service.GetAchievements(gameSlug)
+	page := 1
+	if p, err := strconv.Atoi(ctx.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	limit := 10
+	if l, err := strconv.Atoi(ctx.Query("limit")); err == nil && l > 0 {
+		limit = min(l, 100)
+	}
+
+	achievements, err := c.Service.GetAchievements(userId, gameSlug, page, limit)
 	if err != nil {
+		if errors.Is(err, ErrGameNotFound) {
+			return ctx.JSON(404, ErrorResponse{Error: "Game not found"})
+		}
 		c.Logger.Error("Failed to get achievements", logger.String("error", err.Error()))
-		return ctx.JSON(500, map[string]interface{}{
-			"error": "Failed to get achievements",
-		})
+		return ctx.JSON(500, ErrorResponse{Error: "Failed to get achievements"})
 	}
 
-	// Also get user's unlocked achievements
-	userIdVal, _ := ctx.Get("user_id")
-	userId := userIdVal.(uint)
-	userAchievements, _ := c.Service.GetUserAchievements(userId, gameSlug)
-
-	return ctx.JSON(200, map[string]interface{}{
-		"achievements":      achievements,
-		"user_achievements": userAchievements,
-	})
+	return ctx.JSON(200, achievements)
 }
 
 // @Summary Unlock achievement
@@ -123,38 +244,142 @@ func (c *Controller) GetAchievements(ctx *router.Context) error {
 // @Security BearerAuth
 // @Param game_slug path string true "Game slug (e.g., multiplex, tetris)"
 // @Param slug path string true "Achievement slug"
-// @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Success 200 {object} UnlockAchievementResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
 // @Router /games/{game_slug}/achievements/{slug} [post]
 func (c *Controller) UnlockAchievement(ctx *router.Context) error {
-	userIdVal, _ := ctx.Get("user_id")
-	userId := userIdVal.(uint)
+	userId := ctx.GetUint("user_id")
 	gameSlug := ctx.Param("game_slug")
 	slug := ctx.Param("slug")
 
 	if slug == "" {
-		return ctx.JSON(400, map[string]interface{}{
-			"error": "Achievement slug is required",
-		})
+		return ctx.JSON(400, ErrorResponse{Error: "Achievement slug is required"})
 	}
 
-	userAchievement, err := c.Service.UnlockAchievement(userId, gameSlug, slug)
+	userAchievement, newlyUnlocked, err := c.Service.UnlockAchievement(userId, gameSlug, slug)
 	if err != nil {
 		c.Logger.Error("Failed to unlock achievement", logger.String("error", err.Error()))
-		return ctx.JSON(500, map[string]interface{}{
-			"error": err.Error(),
-		})
+		return ctx.JSON(500, ErrorResponse{Error: err.Error()})
+	}
+
+	message := "Achievement unlocked successfully"
+	if !newlyUnlocked {
+		message = "Achievement already unlocked"
+	}
+
+	return ctx.JSON(200, UnlockAchievementResponse{
+		Achievement:   userAchievement,
+		NewlyUnlocked: newlyUnlocked,
+		Message:       message,
+	})
+}
+
+// @Summary Define achievement
+// @Description Define a new achievement of the game (administrators only). criteria holds the stat thresholds that earn it, e.g. {"max_level": 5}. Slugs are unique per game.
+// @Tags Games
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param game_slug path string true "Game slug (e.g., multiplex, tetris)"
+// @Param achievement body CreateAchievementRequest true "Achievement data"
+// @Success 201 {object} AchievementResponse
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /games/{game_slug}/achievements [post]
+func (c *Controller) CreateAchievement(ctx *router.Context) error {
+	var req CreateAchievementRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return ctx.JSON(400, types.NewBindErrorResponse(err, "Invalid request body"))
+	}
+
+	achievement, err := c.Service.CreateAchievement(ctx.Param("game_slug"), &req)
+	if err != nil {
+		return c.achievementError(ctx, "Failed to create achievement", err)
+	}
+
+	return ctx.JSON(201, AchievementResponse{
+		Achievement: achievement,
+		Message:     "Achievement created successfully",
+	})
+}
+
+// @Summary Update achievement
+// @Description Update an achievement of the game (administrators only); only the fields sent are changed
+// @Tags Games
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param game_slug path string true "Game slug (e.g., multiplex, tetris)"
+// @Param slug path string true "Achievement slug"
+// @Param achievement body UpdateAchievementRequest true "Achievement fields"
+// @Success 200 {object} AchievementResponse
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /games/{game_slug}/achievements/{slug} [put]
+func (c *Controller) UpdateAchievement(ctx *router.Context) error {
+	var req UpdateAchievementRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return ctx.JSON(400, types.NewBindErrorResponse(err, "Invalid request body"))
+	}
+
+	achievement, err := c.Service.UpdateAchievement(ctx.Param("game_slug"), ctx.Param("slug"), &req)
+	if err != nil {
+		return c.achievementError(ctx, "Failed to update achievement", err)
 	}
 
-	return ctx.JSON(200, map[string]interface{}{
-		"achievement": userAchievement,
-		"message":     "Achievement unlocked successfully",
+	return ctx.JSON(200, AchievementResponse{
+		Achievement: achievement,
+		Message:     "Achievement updated successfully",
 	})
 }
 
+// @Summary Delete achievement
+// @Description Delete an achievement of the game (administrators only). Players keep the unlocks they earned.
+// @Tags Games
+// @Produce json
+// @Security BearerAuth
+// @Param game_slug path string true "Game slug (e.g., multiplex, tetris)"
+// @Param slug path string true "Achievement slug"
+// @Success 200 {object} MessageResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /games/{game_slug}/achievements/{slug} [delete]
+func (c *Controller) DeleteAchievement(ctx *router.Context) error {
+	if err := c.Service.DeleteAchievement(ctx.Param("game_slug"), ctx.Param("slug")); err != nil {
+		return c.achievementError(ctx, "Failed to delete achievement", err)
+	}
+
+	return ctx.JSON(200, MessageResponse{Message: "Achievement deleted successfully"})
+}
+
+// achievementError maps errors of the achievement definition endpoints to a
+// status, logging unexpected ones under message
+func (c *Controller) achievementError(ctx *router.Context, message string, err error) error {
+	switch {
+	case errors.Is(err, ErrGameNotFound), errors.Is(err, ErrAchievementNotFound):
+		return ctx.JSON(404, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrAchievementSlugTaken):
+		return ctx.JSON(409, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidAchievement):
+		return ctx.JSON(400, ErrorResponse{Error: err.Error()})
+	}
+	c.Logger.Error(message, logger.String("error", err.Error()))
+	return ctx.JSON(500, ErrorResponse{Error: message})
+}
+
 // @Summary Get player stats
 // @Description Get the player stats for the authenticated user
 // @Tags Games
@@ -162,66 +387,116 @@ func (c *Controller) UnlockAchievement(ctx *router.Context) error {
 // @Produce json
 // @Security BearerAuth
 // @Param game_slug path string true "Game slug (e.g., multiplex, tetris)"
-// @Success 200 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Success 200 {object} StatsResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
 // @Router /games/{game_slug}/stats [get]
 func (c *Controller) GetStats(ctx *router.Context) error {
-	userIdVal, _ := ctx.Get("user_id")
-	userId := userIdVal.(uint)
+	userId := ctx.GetUint("user_id")
 	gameSlug := ctx.Param("game_slug")
 
 	stats, err := c.Service.GetStats(userId, gameSlug)
 	if err != nil {
 		c.Logger.Error("Failed to get stats", logger.String("error", err.Error()))
-		return ctx.JSON(500, map[string]interface{}{
-			"error": "Failed to get stats",
-		})
+		return ctx.JSON(500, ErrorResponse{Error: "Failed to get stats"})
 	}
 
-	return ctx.JSON(200, map[string]interface{}{
-		"stats": stats,
-	})
+	return ctx.JSON(200, StatsResponse{Stats: stats})
 }
 
 // @Summary Update player stats
-// @Description Update the player stats for the authenticated user
+// @Description Update the player stats for the authenticated user. When the game declares a stats schema, stats that do not match it are rejected with 400 and the violations in details.
 // @Tags Games
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param game_slug path string true "Game slug (e.g., multiplex, tetris)"
-// @Param stats body map[string]interface{} true "Player stats data"
-// @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Param stats body UpdateStatsRequest true "Player stats data"
+// @Success 200 {object} StatsResponse
+// @Failure 400 {object} SchemaErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
 // @Router /games/{game_slug}/stats [post]
 func (c *Controller) UpdateStats(ctx *router.Context) error {
-	userIdVal, _ := ctx.Get("user_id")
-	userId := userIdVal.(uint)
+	userId := ctx.GetUint("user_id")
 	gameSlug := ctx.Param("game_slug")
 
-	var statsData map[string]interface{}
-	if err := ctx.Bind(&statsData); err != nil {
-		return ctx.JSON(400, map[string]interface{}{
-			"error": "Invalid request body",
-		})
+	var statsData UpdateStatsRequest
+	if err := ctx.ShouldBindJSON(&statsData); err != nil {
+		return ctx.JSON(400, types.NewBindErrorResponse(err, "Invalid request body"))
 	}
 
 	stats, err := c.Service.UpdateStats(userId, gameSlug, statsData)
 	if err != nil {
+		var invalid *models.SchemaValidationError
+		if errors.As(err, &invalid) {
+			return ctx.JSON(400, SchemaErrorResponse{
+				Error:   "Stats do not match the game's schema",
+				Details: invalid.Violations,
+			})
+		}
 		c.Logger.Error("Failed to update stats", logger.String("error", err.Error()))
-		return ctx.JSON(500, map[string]interface{}{
-			"error": "Failed to update stats",
-		})
+		return ctx.JSON(500, ErrorResponse{Error: "Failed to update stats"})
+	}
+
+	return ctx.JSON(200, StatsResponse{
+		Stats:   stats,
+		Message: "Stats updated successfully",
+	})
+}
+
+// IncrementStatRequest is the payload for incrementing a single stat
+type IncrementStatRequest struct {
+	Key   string  `json:"key" binding:"required" example:"score"`
+	Delta float64 `json:"delta" example:"10"`
+}
+
+// @Summary Increment player stat
+// @Description Atomically add a delta to a single numeric stat for the authenticated user. The result must still match the game's stats schema, if any. A stat holding something other than a number is rejected with 400.
+// @Tags Games
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param game_slug path string true "Game slug (e.g., multiplex, tetris)"
+// @Param body body IncrementStatRequest true "Stat key and delta"
+// @Success 200 {object} StatsResponse
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /games/{game_slug}/stats/increment [post]
+func (c *Controller) IncrementStat(ctx *router.Context) error {
+	userId := ctx.GetUint("user_id")
+	gameSlug := ctx.Param("game_slug")
+
+	var req IncrementStatRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return ctx.JSON(400, types.NewBindErrorResponse(err, "Invalid request body"))
 	}
 
-	return ctx.JSON(200, map[string]interface{}{
-		"stats":   stats,
-		"message": "Stats updated successfully",
+	stats, err := c.Service.IncrementStat(userId, gameSlug, req.Key, req.Delta)
+	if err != nil {
+		var invalid *models.SchemaValidationError
+		switch {
+		case errors.Is(err, ErrGameNotFound):
+			return ctx.JSON(404, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrStatNotNumeric):
+			return ctx.JSON(400, ErrorResponse{Error: err.Error()})
+		case errors.As(err, &invalid):
+			return ctx.JSON(400, SchemaErrorResponse{
+				Error:   "Stats do not match the game's schema",
+				Details: invalid.Violations,
+			})
+		}
+		c.Logger.Error("Failed to increment stat", logger.String("error", err.Error()))
+		return ctx.JSON(500, ErrorResponse{Error: "Failed to increment stat"})
+	}
+
+	return ctx.JSON(200, StatsResponse{
+		Stats:   stats,
+		Message: "Stat incremented successfully",
 	})
 }
 
@@ -233,10 +508,12 @@ func (c *Controller) UpdateStats(ctx *router.Context) error {
 // @Security BearerAuth
 // @Param game_slug path string true "Game slug (e.g., multiplex, tetris)"
 // @Param limit query int false "Number of top players to return" default(10)
-// @Success 200 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Param period query string false "Time window: daily, weekly, monthly or all" default(all)
+// @Success 200 {object} LeaderboardResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
 // @Router /games/{game_slug}/leaderboard [get]
 func (c *Controller) GetLeaderboard(ctx *router.Context) error {
 	gameSlug := ctx.Param("game_slug")
@@ -248,19 +525,68 @@ func (c *Controller) GetLeaderboard(ctx *router.Context) error {
 		}
 	}
 
-	leaderboard, err := c.Service.GetLeaderboard(gameSlug, limit)
+	period := ctx.DefaultQuery("period", PeriodAll)
+
+	leaderboard, err := c.Service.GetLeaderboard(gameSlug, period, limit)
 	if err != nil {
+		if errors.Is(err, ErrInvalidPeriod) {
+			return ctx.JSON(400, ErrorResponse{Error: err.Error()})
+		}
+		if errors.Is(err, ErrGameNotFound) {
+			return ctx.JSON(404, ErrorResponse{Error: "Game not found"})
+		}
 		c.Logger.Error("Failed to get leaderboard", logger.String("error", err.Error()))
-		return ctx.JSON(500, map[string]interface{}{
-			"error": "Failed to get leaderboard",
-		})
+		return ctx.JSON(500, ErrorResponse{Error: "Failed to get leaderboard"})
 	}
 
-	return ctx.JSON(200, map[string]interface{}{
-		"leaderboard": leaderboard,
+	return ctx.JSON(200, LeaderboardResponse{
+		Leaderboard: newLeaderboardEntries(leaderboard),
+		Period:      period,
 	})
 }
 
+// @Summary Get game analytics
+// @Description Aggregate metrics of a game: total players, progress records, achievement unlock counts and rates,
+// @Description and the distribution of a numeric stat. Requires the game:analytics permission. Results are cached for a minute.
+// @Tags Games
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param game_slug path string true "Game slug (e.g., multiplex, tetris)"
+// @Param stat query string false "Stat path (e.g. score or level.best)" default(score)
+// @Param buckets query int false "Number of distribution buckets, at most 50" default(10)
+// @Success 200 {object} GameAnalytics
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /games/{game_slug}/analytics [get]
+func (c *Controller) GetAnalytics(ctx *router.Context) error {
+	buckets := DefaultAnalyticsBuckets
+	if bucketsStr := ctx.Query("buckets"); bucketsStr != "" {
+		b, err := strconv.Atoi(bucketsStr)
+		if err != nil {
+			return ctx.JSON(400, ErrorResponse{Error: ErrInvalidBuckets.Error()})
+		}
+		buckets = b
+	}
+
+	analytics, err := c.Service.GetAnalytics(ctx.Param("game_slug"), ctx.DefaultQuery("stat", DefaultAnalyticsStat), buckets)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidJSONPath) || errors.Is(err, ErrInvalidBuckets) {
+			return ctx.JSON(400, ErrorResponse{Error: err.Error()})
+		}
+		if errors.Is(err, ErrGameNotFound) {
+			return ctx.JSON(404, ErrorResponse{Error: "Game not found"})
+		}
+		c.Logger.Error("Failed to compute game analytics", logger.String("error", err.Error()))
+		return ctx.JSON(500, ErrorResponse{Error: "Failed to compute game analytics"})
+	}
+
+	return ctx.JSON(200, analytics)
+}
+
 // @Summary Get player profile
 // @Description Get complete player profile with stats, achievements, and progress
 // @Tags Games
@@ -268,39 +594,229 @@ func (c *Controller) GetLeaderboard(ctx *router.Context) error {
 // @Produce json
 // @Security BearerAuth
 // @Param game_slug path string true "Game slug (e.g., multiplex, tetris)"
-// @Success 200 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Success 200 {object} ProfileResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
 // @Router /games/{game_slug}/profile [get]
 func (c *Controller) GetProfile(ctx *router.Context) error {
-	userIdVal, _ := ctx.Get("user_id")
-	userId := userIdVal.(uint)
+	userId := ctx.GetUint("user_id")
 	gameSlug := ctx.Param("game_slug")
 
 	profile, err := c.Service.GetPlayerProfile(userId, gameSlug)
 	if err != nil {
 		c.Logger.Error("Failed to get player profile", logger.String("error", err.Error()))
-		return ctx.JSON(500, map[string]interface{}{
-			"error": "Failed to get player profile",
-		})
+		return ctx.JSON(500, ErrorResponse{Error: "Failed to get player profile"})
+	}
+
+	return ctx.JSON(200, ProfileResponse{Profile: profile})
+}
+
+// @Summary Get online players
+// @Description List the authenticated users connected over WebSocket with ?game= set to this game, earliest arrival first. Players in the game receive presence_join and presence_leave messages as this list changes.
+// @Tags Games
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param game_slug path string true "Game slug (e.g., multiplex, tetris)"
+// @Success 200 {object} PresenceResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /games/{game_slug}/presence [get]
+func (c *Controller) GetPresence(ctx *router.Context) error {
+	gameSlug := ctx.Param("game_slug")
+
+	if _, err := c.Service.GetGame(gameSlug); err != nil {
+		if errors.Is(err, ErrGameNotFound) {
+			return ctx.JSON(404, ErrorResponse{Error: "Game not found"})
+		}
+		c.Logger.Error("Failed to get presence", logger.String("error", err.Error()))
+		return ctx.JSON(500, ErrorResponse{Error: "Failed to get presence"})
+	}
+
+	online := []websocket.PresenceEntry{}
+	if c.Hub != nil {
+		online = c.Hub.Presence(gameSlug)
 	}
 
-	return ctx.JSON(200, map[string]interface{}{
-		"profile": profile,
+	return ctx.JSON(200, PresenceResponse{
+		Online: online,
+		Count:  len(online),
 	})
 }
 
-// Routes registers all game routes with :game_slug parameter
+// @Summary Export leaderboard as CSV
+// @Description Stream every player of a game as CSV with rank, user id, username and score, best score first. Players tying on the stat share a rank; players without the stat come last with empty rank and score. Requires the Owner or Administrator role.
+// @Tags Games
+// @Produce text/csv
+// @Security BearerAuth
+// @Param game_slug path string true "Game slug (e.g., multiplex, tetris)"
+// @Param stat query string false "Stat to rank by, e.g. score or level.best" default(score)
+// @Success 200 {string} string "CSV file"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /games/{game_slug}/leaderboard/export.csv [get]
+func (c *Controller) ExportLeaderboard(ctx *router.Context) error {
+	gameSlug := ctx.Param("game_slug")
+	stat := ctx.DefaultQuery("stat", "score")
+
+	writer := csv.NewWriter(ctx.Writer)
+	started := false
+	start := func() {
+		started = true
+		ctx.Writer.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		ctx.Writer.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+			"filename": gameSlug + "-leaderboard.csv",
+		}))
+		ctx.Writer.WriteHeader(200)
+		writer.Write([]string{"rank", "user_id", "username", "score"})
+	}
+
+	rows := 0
+	err := c.Service.ExportLeaderboard(gameSlug, stat, func(row LeaderboardRow) error {
+		if !started {
+			start()
+		}
+		record := []string{"", strconv.FormatUint(uint64(row.UserId), 10), row.Username, ""}
+		if row.Rank != nil {
+			record[0] = strconv.Itoa(*row.Rank)
+			record[3] = strconv.FormatFloat(*row.Score, 'f', -1, 64)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%500 == 0 {
+			writer.Flush()
+			ctx.Flush()
+		}
+		return writer.Error()
+	})
+
+	if err != nil && !started {
+		if errors.Is(err, models.ErrInvalidJSONPath) {
+			return ctx.JSON(400, ErrorResponse{Error: err.Error()})
+		}
+		if errors.Is(err, ErrGameNotFound) {
+			return ctx.JSON(404, ErrorResponse{Error: "Game not found"})
+		}
+		c.Logger.Error("Failed to export leaderboard", logger.String("error", err.Error()))
+		return ctx.JSON(500, ErrorResponse{Error: "Failed to export leaderboard"})
+	}
+	if err != nil {
+		// The status is already sent; the truncated file is all we can return
+		c.Logger.Error("Leaderboard export interrupted",
+			logger.String("game", gameSlug),
+			logger.Int("rows", rows),
+			logger.String("error", err.Error()))
+		return nil
+	}
+
+	if !started {
+		start()
+	}
+	writer.Flush()
+	return nil
+}
+
+// @Summary Get my games
+// @Description Get the authenticated user's games across every game they have progress, stats or unlocked achievements in, most recently played first, with their achievement points and last-played times
+// @Tags Games
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} UserGamesResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /me/games [get]
+func (c *Controller) GetUserGames(ctx *router.Context) error {
+	userId := ctx.GetUint("user_id")
+
+	games, err := c.Service.GetUserGames(userId)
+	if err != nil {
+		c.Logger.Error("Failed to get user games", logger.String("error", err.Error()))
+		return ctx.JSON(500, ErrorResponse{Error: "Failed to get user games"})
+	}
+
+	return ctx.JSON(200, games)
+}
+
+// eventHeartbeatInterval is how often an idle event stream sends a heartbeat
+const eventHeartbeatInterval = 15 * time.Second
+
+// @Summary Stream game events
+// @Description Server-sent events stream of the authenticated user's game updates (progress saved, stats updated, achievement unlocked), for clients that cannot use WebSockets. Each event carries the same type and content as the WebSocket messages; a heartbeat comment is sent every 15 seconds.
+// @Tags Games
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Success 200 {string} string "event stream"
+// @Failure 401 {object} ErrorResponse
+// @Router /games/events [get]
+func (c *Controller) StreamEvents(ctx *router.Context) error {
+	userId := ctx.GetUint("user_id")
+
+	events, unsubscribe := c.Events.Subscribe(userId)
+	defer unsubscribe()
+
+	ctx.SSEStart()
+
+	heartbeat := time.NewTicker(eventHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	// Write errors mean the client went away; the response has already
+	// started, so they end the stream instead of being returned
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-events:
+			if err := ctx.SSEvent(event.Type, event.Content); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if err := ctx.SSEComment("heartbeat"); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// Routes registers all game routes with :game_slug parameter, and the
+// user's games under /me
 func (c *Controller) Routes(group *router.RouterGroup) {
 	gamesGroup := group.Group("/games")
+	if c.Authenticator != nil {
+		gamesGroup.Use(c.Authenticator)
+	}
+	gamesGroup.Use(requireUser)
+	gamesGroup.GET("", c.ListGames)
+	gamesGroup.POST("", c.CreateGame, c.requireAdmin())
+	gamesGroup.GET("/events", c.StreamEvents)
 	gameGroup := gamesGroup.Group("/:game_slug")
 	gameGroup.GET("/progress", c.GetProgress)
 	gameGroup.POST("/progress", c.SaveProgress)
 	gameGroup.GET("/achievements", c.GetAchievements)
+	gameGroup.POST("/achievements", c.CreateAchievement, c.requireAdmin())
+	gameGroup.PUT("/achievements/:slug", c.UpdateAchievement, c.requireAdmin())
+	gameGroup.DELETE("/achievements/:slug", c.DeleteAchievement, c.requireAdmin())
 	gameGroup.POST("/achievements/:slug", c.UnlockAchievement)
 	gameGroup.GET("/stats", c.GetStats)
 	gameGroup.POST("/stats", c.UpdateStats)
+	gameGroup.POST("/stats/increment", c.IncrementStat)
 	gameGroup.GET("/leaderboard", c.GetLeaderboard)
+	gameGroup.GET("/leaderboard/export.csv", c.ExportLeaderboard, c.requireAdmin())
 	gameGroup.GET("/profile", c.GetProfile)
+	gameGroup.GET("/presence", c.GetPresence)
+	gameGroup.GET("/analytics", c.GetAnalytics, c.requirePermission("game", "analytics"))
+
+	meGroup := group.Group("/me")
+	if c.Authenticator != nil {
+		meGroup.Use(c.Authenticator)
+	}
+	meGroup.Use(requireUser)
+	meGroup.GET("/games", c.GetUserGames)
 }
@@ -0,0 +1,197 @@
+package games
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"base/app/models"
+	"base/core/router"
+)
+
+// asUser returns a middleware authenticating every request as userId
+func asUser(userId uint) router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			c.Set("user_id", userId)
+			return next(c)
+		}
+	}
+}
+
+func TestCreateGameRequiresAdministrativeRole(t *testing.T) {
+	c := newTestController(t)
+
+	tests := []struct {
+		role string
+		slug string
+		want int
+	}{
+		{"Owner", "owner-game", http.StatusCreated},
+		{"Administrator", "admin-game", http.StatusCreated},
+		{"Administrator", "admin-game", http.StatusConflict},
+		{"Member", "member-game", http.StatusForbidden},
+		{"Viewer", "viewer-game", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		c.Authenticator = asUser(createUser(t, c.Service.DB, tt.role))
+		r := router.New()
+		c.Routes(r.Group("/api"))
+
+		body := strings.NewReader(`{"slug":"` + tt.slug + `","title":"Game"}`)
+		req := httptest.NewRequest(http.MethodPost, "/api/games", body)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s creating %s: status %d, want %d: %s", tt.role, tt.slug, w.Code, tt.want, w.Body)
+		}
+	}
+}
+
+func TestIncrementStatStatuses(t *testing.T) {
+	c := newTestController(t)
+	c.Authenticator = asUser(createUser(t, c.Service.DB, "Member"))
+	r := router.New()
+	c.Routes(r.Group("/api"))
+
+	if _, err := c.Service.CreateGame(&CreateGameRequest{Slug: "tetris", Title: "Tetris"}); err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		want int
+	}{
+		{"increment", "/api/games/tetris/stats/increment", `{"key":"score","delta":2}`, http.StatusOK},
+		{"unknown game", "/api/games/missing/stats/increment", `{"key":"score","delta":2}`, http.StatusNotFound},
+		{"missing key", "/api/games/tetris/stats/increment", `{"delta":2}`, http.StatusBadRequest},
+		{"non-numeric delta", "/api/games/tetris/stats/increment", `{"key":"score","delta":"two"}`, http.StatusBadRequest},
+		{"set a text stat", "/api/games/tetris/stats", `{"rank":"gold"}`, http.StatusOK},
+		{"non-numeric stat", "/api/games/tetris/stats/increment", `{"key":"rank","delta":1}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+}
+
+func TestGameRoutesRequireUser(t *testing.T) {
+	c := newTestController(t)
+	r := router.New()
+	c.Routes(r.Group("/api"))
+
+	for _, path := range []string{"/api/games/tetris/progress", "/api/games/tetris/stats", "/api/me/games"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("GET %s without a user: status %d, want 401", path, w.Code)
+		}
+	}
+}
+
+func TestUnlockAchievementRouteReportsNewlyUnlocked(t *testing.T) {
+	c := newTestController(t)
+	c.Authenticator = asUser(createUser(t, c.Service.DB, "Member"))
+	r := router.New()
+	c.Routes(r.Group("/api"))
+
+	game, err := c.Service.CreateGame(&CreateGameRequest{Slug: "tetris", Title: "Tetris"})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	if err := c.Service.DB.Create(&models.Achievement{GameId: game.Id, Slug: "first-line", Title: "First line", Criteria: "{}"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []bool{true, false} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/games/tetris/achievements/first-line", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("unlock: status %d: %s", w.Code, w.Body)
+		}
+		var resp UnlockAchievementResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.NewlyUnlocked != want || resp.Achievement == nil {
+			t.Errorf("newly_unlocked = %v with achievement %v, want %v", resp.NewlyUnlocked, resp.Achievement, want)
+		}
+	}
+}
+
+func TestProgressAndStatsAreCheckedAgainstGameSchema(t *testing.T) {
+	c := newTestController(t)
+	c.Authenticator = asUser(createUser(t, c.Service.DB, "Owner"))
+	r := router.New()
+	c.Routes(r.Group("/api"))
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	game := `{"slug":"tetris","title":"Tetris",
+		"progress_schema":{"type":"object","required":["level"],"properties":{"level":{"type":"integer","minimum":1}}},
+		"stats_schema":{"type":"object","additionalProperties":{"type":"number","maximum":1000}}}`
+	if w := post("/api/games", game); w.Code != http.StatusCreated {
+		t.Fatalf("create game with schemas: status %d: %s", w.Code, w.Body)
+	}
+	if w := post("/api/games", `{"slug":"untyped","title":"Untyped"}`); w.Code != http.StatusCreated {
+		t.Fatalf("create game without schemas: status %d: %s", w.Code, w.Body)
+	}
+	if w := post("/api/games", `{"slug":"broken","title":"Broken","progress_schema":{"type":"decimal"}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("create game with an invalid schema: status %d, want 400", w.Code)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		want int
+	}{
+		{"valid progress", "/api/games/tetris/progress", `{"level":3}`, http.StatusOK},
+		{"invalid progress", "/api/games/tetris/progress", `{"level":0}`, http.StatusBadRequest},
+		{"missing level", "/api/games/tetris/progress", `{"score":10}`, http.StatusBadRequest},
+		{"valid stats", "/api/games/tetris/stats", `{"score":10}`, http.StatusOK},
+		{"invalid stats", "/api/games/tetris/stats", `{"score":"ten"}`, http.StatusBadRequest},
+		{"increment past the maximum", "/api/games/tetris/stats/increment", `{"key":"score","delta":1000}`, http.StatusBadRequest},
+		{"no schema", "/api/games/untyped/progress", `{"anything":["goes"]}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := post(tt.path, tt.body)
+		if w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, w.Code, tt.want, w.Body)
+			continue
+		}
+		if tt.want != http.StatusBadRequest {
+			continue
+		}
+		var response SchemaErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || len(response.Details) == 0 {
+			t.Errorf("%s: response %s does not list the violations", tt.name, w.Body)
+		}
+	}
+
+	var progress models.GameProgress
+	if err := c.Service.DB.Joins("JOIN games ON games.id = game_progress.game_id").Where("games.slug = ?", "tetris").First(&progress).Error; err != nil {
+		t.Fatal(err)
+	}
+	if progress.Data != `{"level":3}` {
+		t.Errorf("stored progress %s, want only the valid payload", progress.Data)
+	}
+}
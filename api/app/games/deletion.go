@@ -0,0 +1,29 @@
+package games
+
+import (
+	"base/app/models"
+	"base/core/app/profile"
+
+	"gorm.io/gorm"
+)
+
+// deleteUserGameData is the profile deletion hook for game data. Anonymized
+// accounts keep their progress, stats and achievements, which then only point
+// at the erased user; hard deletion removes them permanently.
+func deleteUserGameData(tx *gorm.DB, userId uint, mode string) error {
+	if mode != profile.DeletionHardDelete {
+		return nil
+	}
+
+	for _, model := range []any{
+		&models.GameProgress{},
+		&models.PlayerStats{},
+		&models.PlayerStatsSnapshot{},
+		&models.UserAchievement{},
+	} {
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package games
+
+import (
+	"base/app/models"
+	"base/core/emitter"
+	"sync"
+)
+
+// streamedEvents are the emitter events forwarded to the user they concern
+var streamedEvents = []string{
+	"games.progress.saved",
+	"games.stats.updated",
+	"games.achievement.unlocked",
+}
+
+// eventBufferSize is how many events a slow subscriber may fall behind by
+// before further events are dropped for it
+const eventBufferSize = 32
+
+// GameEvent is a live update for one user, shaped like the WebSocket hub's
+// messages so clients can handle both transports the same way
+type GameEvent struct {
+	Type    string `json:"type"`
+	Content any    `json:"content"`
+}
+
+// EventBroker fans game events out to each user's open event streams
+type EventBroker struct {
+	mutex       sync.RWMutex
+	subscribers map[uint]map[chan GameEvent]struct{}
+}
+
+// NewEventBroker creates a broker fed by the streamed events of em
+func NewEventBroker(em *emitter.Emitter) *EventBroker {
+	broker := &EventBroker{
+		subscribers: make(map[uint]map[chan GameEvent]struct{}),
+	}
+	if em != nil {
+		for _, name := range streamedEvents {
+			event := name
+			em.On(event, func(data any) {
+				if userId := eventUserId(data); userId != 0 {
+					broker.Publish(userId, GameEvent{Type: event, Content: data})
+				}
+			})
+		}
+	}
+	return broker
+}
+
+// Subscribe returns a channel receiving the user's events and a function that
+// must be called to stop receiving them
+func (b *EventBroker) Subscribe(userId uint) (<-chan GameEvent, func()) {
+	events := make(chan GameEvent, eventBufferSize)
+
+	b.mutex.Lock()
+	if b.subscribers[userId] == nil {
+		b.subscribers[userId] = make(map[chan GameEvent]struct{})
+	}
+	b.subscribers[userId][events] = struct{}{}
+	b.mutex.Unlock()
+
+	return events, func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		delete(b.subscribers[userId], events)
+		if len(b.subscribers[userId]) == 0 {
+			delete(b.subscribers, userId)
+		}
+	}
+}
+
+// Publish sends an event to every stream of the user without blocking; a
+// subscriber whose buffer is full misses the event
+func (b *EventBroker) Publish(userId uint, event GameEvent) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	for events := range b.subscribers[userId] {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// eventUserId returns the user a game event belongs to
+func eventUserId(data any) uint {
+	switch value := data.(type) {
+	case *models.GameProgress:
+		return value.UserId
+	case *models.PlayerStats:
+		return value.UserId
+	case *models.UserAchievement:
+		return value.UserId
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package games
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"base/app/models"
+	"base/core/app/authorization"
+	"base/core/app/profile"
+	"base/core/emitter"
+	"base/core/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// newTestController returns a games controller on a fresh sqlite database
+// holding the game, user and authorization tables. sqlite has no row locks,
+// so transactions take the write lock when they begin, which serializes them
+// like SELECT ... FOR UPDATE does elsewhere.
+func newTestController(t *testing.T) *Controller {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_busy_timeout=10000&_txlock=immediate"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := models.AutoMigrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := db.AutoMigrate(&profile.User{}); err != nil {
+		t.Fatalf("migrate users: %v", err)
+	}
+
+	log := logger.NewLoggerFromZap(zap.NewNop())
+	authzModule := authorization.NewAuthorizationModule(db, nil, log, nil)
+	if err := authzModule.Migrate(); err != nil {
+		t.Fatalf("migrate authorization: %v", err)
+	}
+	authorization.SetPermissionCacheTTL(0)
+	t.Cleanup(func() { authorization.SetPermissionCacheTTL(authorization.DefaultPermissionCacheTTL) })
+
+	return &Controller{
+		Service: &Service{DB: db, Emitter: emitter.New(), Logger: log},
+		Logger:  log,
+		Authz:   authorization.NewAuthorizationService(db),
+	}
+}
+
+// createUser adds a user holding the named role and returns their id
+func createUser(t *testing.T, db *gorm.DB, roleName string) uint {
+	t.Helper()
+
+	var role authorization.Role
+	if err := db.Where("name = ?", roleName).First(&role).Error; err != nil {
+		t.Fatalf("find role %s: %v", roleName, err)
+	}
+	var count int64
+	db.Model(&profile.User{}).Count(&count)
+	user := profile.User{
+		FirstName: "Test",
+		LastName:  roleName,
+		Username:  fmt.Sprintf("user%d", count+1),
+		Phone:     fmt.Sprintf("+3834400%04d", count+1),
+		Email:     fmt.Sprintf("user%d@example.com", count+1),
+		RoleId:    role.Id,
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user.Id
+}
@@ -0,0 +1,134 @@
+package games
+
+import (
+	"base/app/models"
+	"base/core/database"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Leaderboard periods accepted by GetLeaderboard
+const (
+	PeriodDaily   = "daily"
+	PeriodWeekly  = "weekly"
+	PeriodMonthly = "monthly"
+	PeriodAll     = "all"
+)
+
+// snapshotPeriods are the periods whose starting stats are recorded
+var snapshotPeriods = []string{PeriodDaily, PeriodWeekly, PeriodMonthly}
+
+// IsValidPeriod reports whether period is a supported leaderboard period
+func IsValidPeriod(period string) bool {
+	switch period {
+	case PeriodDaily, PeriodWeekly, PeriodMonthly, PeriodAll:
+		return true
+	}
+	return false
+}
+
+// PeriodStart returns the start of the calendar period containing now, in now's
+// location: midnight for daily, Monday midnight for weekly and the first of the
+// month for monthly. The zero time is returned for all.
+func PeriodStart(period string, now time.Time) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch period {
+	case PeriodDaily:
+		return midnight
+	case PeriodWeekly:
+		// time.Weekday starts on Sunday; weeks here start on Monday
+		daysSinceMonday := (int(now.Weekday()) + 6) % 7
+		return midnight.AddDate(0, 0, -daysSinceMonday)
+	case PeriodMonthly:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	}
+	return time.Time{}
+}
+
+// snapshotStats records the player's current stats as the baseline of every
+// period that has no snapshot yet. It must run before the stats are changed.
+func snapshotStats(tx *gorm.DB, stats *models.PlayerStats, now time.Time) error {
+	baseline := stats.Stats
+	if baseline == "" {
+		baseline = "{}"
+	}
+
+	for _, period := range snapshotPeriods {
+		snapshot := models.PlayerStatsSnapshot{
+			UserId:      stats.UserId,
+			GameId:      stats.GameId,
+			Period:      period,
+			PeriodStart: PeriodStart(period, now),
+			Stats:       baseline,
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&snapshot).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyPeriodSnapshots replaces each entry's stats with what was achieved since
+// the period began. Players without a snapshot keep their totals.
+func (s *Service) applyPeriodSnapshots(stats []models.PlayerStats, gameId uint, period string, start time.Time) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	userIds := make([]uint, len(stats))
+	for i, entry := range stats {
+		userIds[i] = entry.UserId
+	}
+
+	var snapshots []models.PlayerStatsSnapshot
+	err := database.Reader(s.DB).Where("game_id = ? AND period = ? AND period_start = ? AND user_id IN ?", gameId, period, start, userIds).
+		Find(&snapshots).Error
+	if err != nil {
+		return err
+	}
+
+	baselines := make(map[uint]string, len(snapshots))
+	for _, snapshot := range snapshots {
+		baselines[snapshot.UserId] = snapshot.Stats
+	}
+
+	for i := range stats {
+		if baseline, ok := baselines[stats[i].UserId]; ok {
+			stats[i].Stats = statsSince(stats[i].Stats, baseline)
+		}
+	}
+	return nil
+}
+
+// statsSince subtracts numeric baseline values from current ones; other values
+// are reported as they are now
+func statsSince(current, baseline string) string {
+	currentData := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(current), &currentData); err != nil {
+		return current
+	}
+	baselineData := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(baseline), &baselineData); err != nil {
+		return current
+	}
+
+	for key, value := range currentData {
+		now, ok := value.(float64)
+		if !ok {
+			continue
+		}
+		if before, ok := baselineData[key].(float64); ok {
+			currentData[key] = now - before
+		}
+	}
+
+	result, err := json.Marshal(currentData)
+	if err != nil {
+		return current
+	}
+	return string(result)
+}
@@ -0,0 +1,80 @@
+package games
+
+import (
+	"base/app/models"
+	"base/core/database"
+	"database/sql"
+	"fmt"
+)
+
+// LeaderboardRow is one player in a leaderboard export. Rank and Score are
+// nil for players without a numeric value for the stat, who are listed last.
+type LeaderboardRow struct {
+	Rank     *int
+	UserId   uint
+	Username string
+	Score    *float64
+}
+
+// ExportLeaderboard calls each with every player of the game, best stat
+// first. Players tying on the stat share a rank. Rows are read through a
+// cursor, so memory does not grow with the number of players. Errors
+// returned before the first call mean nothing was exported.
+func (s *Service) ExportLeaderboard(gameSlug string, stat string, each func(LeaderboardRow) error) error {
+	segments, err := models.ParseJSONPath(stat)
+	if err != nil {
+		return err
+	}
+
+	var game models.Game
+	if err := s.DB.Where("slug = ?", gameSlug).First(&game).Error; err != nil {
+		return ErrGameNotFound
+	}
+
+	db := database.Reader(s.DB)
+	expr := models.JSONNumberExpr(db.Dialector.Name(), "player_stats.stats", segments)
+	if expr == "" {
+		return fmt.Errorf("leaderboard export is not supported on %s", db.Dialector.Name())
+	}
+
+	rows, err := db.Model(&models.PlayerStats{}).
+		Select(fmt.Sprintf("player_stats.user_id, users.username, %s AS score", expr)).
+		Joins("LEFT JOIN users ON users.id = player_stats.user_id").
+		Where("player_stats.game_id = ?", game.Id).
+		Order(fmt.Sprintf("CASE WHEN %s IS NULL THEN 1 ELSE 0 END, %s DESC, player_stats.user_id ASC", expr, expr)).
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	position, rank := 0, 0
+	var previous *float64
+	for rows.Next() {
+		var (
+			row      LeaderboardRow
+			username sql.NullString
+			score    sql.NullFloat64
+		)
+		if err := rows.Scan(&row.UserId, &username, &score); err != nil {
+			return err
+		}
+		row.Username = username.String
+
+		position++
+		if score.Valid {
+			if previous == nil || *previous != score.Float64 {
+				rank = position
+			}
+			playerRank := rank
+			row.Rank = &playerRank
+			row.Score = &score.Float64
+			previous = row.Score
+		}
+
+		if err := each(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
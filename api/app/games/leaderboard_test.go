@@ -0,0 +1,167 @@
+package games
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"base/app/models"
+	"base/core/router"
+)
+
+func TestPeriodStartBoundaries(t *testing.T) {
+	at := func(month time.Month, day, hour, min, sec int) time.Time {
+		return time.Date(2024, month, day, hour, min, sec, 0, time.UTC)
+	}
+
+	tests := []struct {
+		period string
+		now    time.Time
+		want   time.Time
+	}{
+		{PeriodDaily, at(5, 15, 0, 0, 0), at(5, 15, 0, 0, 0)},
+		{PeriodDaily, at(5, 15, 23, 59, 59), at(5, 15, 0, 0, 0)},
+		// 2024-05-13 is a Monday
+		{PeriodWeekly, at(5, 13, 0, 0, 0), at(5, 13, 0, 0, 0)},
+		{PeriodWeekly, at(5, 15, 12, 0, 0), at(5, 13, 0, 0, 0)},
+		{PeriodWeekly, at(5, 19, 23, 59, 59), at(5, 13, 0, 0, 0)},
+		{PeriodWeekly, at(5, 20, 0, 0, 0), at(5, 20, 0, 0, 0)},
+		// Weeks may start in the previous month
+		{PeriodWeekly, at(6, 1, 10, 0, 0), at(5, 27, 0, 0, 0)},
+		{PeriodMonthly, at(5, 1, 0, 0, 0), at(5, 1, 0, 0, 0)},
+		{PeriodMonthly, at(5, 31, 23, 59, 59), at(5, 1, 0, 0, 0)},
+		{PeriodMonthly, at(2, 29, 8, 0, 0), at(2, 1, 0, 0, 0)},
+		{PeriodAll, at(5, 15, 12, 0, 0), time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := PeriodStart(tt.period, tt.now); !got.Equal(tt.want) {
+			t.Errorf("PeriodStart(%s, %s) = %s, want %s", tt.period, tt.now, got, tt.want)
+		}
+	}
+
+	// Periods start at midnight where now is, not in UTC
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone data")
+	}
+	now := time.Date(2024, 5, 15, 0, 30, 0, 0, berlin)
+	if got, want := PeriodStart(PeriodDaily, now), time.Date(2024, 5, 15, 0, 0, 0, 0, berlin); !got.Equal(want) {
+		t.Errorf("daily start in Berlin = %s, want %s", got, want)
+	}
+}
+
+// leaderboardUsers returns the entries of a leaderboard by user id
+func leaderboardUsers(t *testing.T, s *Service, period string) map[uint]models.PlayerStats {
+	t.Helper()
+	stats, err := s.GetLeaderboard("tetris", period, 100)
+	if err != nil {
+		t.Fatalf("GetLeaderboard(%s): %v", period, err)
+	}
+	users := make(map[uint]models.PlayerStats, len(stats))
+	for _, entry := range stats {
+		users[entry.UserId] = entry
+	}
+	return users
+}
+
+func TestLeaderboardPeriodWindows(t *testing.T) {
+	c := newTestController(t)
+	s := c.Service
+	game, err := s.CreateGame(&CreateGameRequest{Slug: "tetris", Title: "Tetris"})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+
+	now := time.Now()
+	for _, period := range []string{PeriodDaily, PeriodWeekly, PeriodMonthly} {
+		start := PeriodStart(period, now)
+		inside, outside := createUser(t, s.DB, "Member"), createUser(t, s.DB, "Member")
+		for userId, updatedAt := range map[uint]time.Time{inside: start, outside: start.Add(-time.Second)} {
+			stats := models.PlayerStats{UserId: userId, GameId: game.Id, Stats: `{"score":1}`}
+			if err := s.DB.Create(&stats).Error; err != nil {
+				t.Fatal(err)
+			}
+			if err := s.DB.Model(&stats).UpdateColumn("updated_at", updatedAt).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		users := leaderboardUsers(t, s, period)
+		if _, ok := users[inside]; !ok {
+			t.Errorf("%s board misses a player active when the period began", period)
+		}
+		if _, ok := users[outside]; ok {
+			t.Errorf("%s board lists a player last active a second before the period", period)
+		}
+		if _, ok := leaderboardUsers(t, s, PeriodAll)[outside]; !ok {
+			t.Errorf("all-time board misses a player inactive this %s", period)
+		}
+	}
+
+	if _, err := s.GetLeaderboard("tetris", "yearly", 10); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("yearly period: got %v, want ErrInvalidPeriod", err)
+	}
+}
+
+func TestPeriodicLeaderboardReportsScoresWithinThePeriod(t *testing.T) {
+	c := newTestController(t)
+	s := c.Service
+	game, err := s.CreateGame(&CreateGameRequest{Slug: "tetris", Title: "Tetris"})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	userId := createUser(t, s.DB, "Member")
+
+	// 10 points scored before any snapshot existed
+	if err := s.DB.Create(&models.PlayerStats{UserId: userId, GameId: game.Id, Stats: `{"score":10,"level":"gold"}`}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.IncrementStat(userId, "tetris", "score", 5); err != nil {
+		t.Fatalf("IncrementStat: %v", err)
+	}
+
+	score := func(period string) float64 {
+		var stats map[string]any
+		if err := json.Unmarshal([]byte(leaderboardUsers(t, s, period)[userId].Stats), &stats); err != nil {
+			t.Fatalf("%s stats: %v", period, err)
+		}
+		if stats["level"] != "gold" {
+			t.Errorf("%s board changed the non-numeric stat to %v", period, stats["level"])
+		}
+		return stats["score"].(float64)
+	}
+
+	if got := score(PeriodAll); got != 15 {
+		t.Errorf("all-time score %v, want 15", got)
+	}
+	for _, period := range []string{PeriodDaily, PeriodWeekly, PeriodMonthly} {
+		if got := score(period); got != 5 {
+			t.Errorf("%s score %v, want the 5 points scored in the period", period, got)
+		}
+	}
+}
+
+func TestLeaderboardRouteRejectsUnknownPeriod(t *testing.T) {
+	c := newTestController(t)
+	c.Authenticator = asUser(createUser(t, c.Service.DB, "Member"))
+	r := router.New()
+	c.Routes(r.Group("/api"))
+	if _, err := c.Service.CreateGame(&CreateGameRequest{Slug: "tetris", Title: "Tetris"}); err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+
+	for query, want := range map[string]int{
+		"":               http.StatusOK,
+		"?period=weekly": http.StatusOK,
+		"?period=yearly": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/games/tetris/leaderboard"+query, nil))
+		if w.Code != want {
+			t.Errorf("leaderboard%s: status %d, want %d: %s", query, w.Code, want, w.Body)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package games
+
+import (
+	"time"
+
+	"base/app/models"
+	"base/core/websocket"
+)
+
+// Request and response bodies of the games endpoints. They marshal exactly
+// as the maps the handlers used to build, fields in key order, so the wire
+// format is unchanged.
+
+// SaveProgressRequest is the progress data of a game, stored as sent and
+// checked against the game's progress schema, if any
+type SaveProgressRequest map[string]interface{}
+
+// UpdateStatsRequest is the player stats of a game, stored as sent and
+// checked against the game's stats schema, if any
+type UpdateStatsRequest map[string]interface{}
+
+// ErrorResponse is the body of a failed games request
+type ErrorResponse struct {
+	Error string `json:"error" example:"Game not found"`
+}
+
+// SchemaErrorResponse is the body of progress or stats rejected by the
+// game's schema
+type SchemaErrorResponse struct {
+	Details []models.SchemaViolation `json:"details"`
+	Error   string                   `json:"error" example:"Stats do not match the game's schema"`
+}
+
+// MessageResponse confirms an operation without returning a resource
+type MessageResponse struct {
+	Message string `json:"message" example:"Achievement deleted successfully"`
+}
+
+// GamesResponse lists the registered games
+type GamesResponse struct {
+	Games []models.Game `json:"games"`
+}
+
+// GameResponse is a registered game
+type GameResponse struct {
+	Game    *models.Game `json:"game"`
+	Message string       `json:"message" example:"Game created successfully"`
+}
+
+// ProgressResponse is the user's progress in a game. Message is only set
+// when the progress was saved.
+type ProgressResponse struct {
+	Message  string               `json:"message,omitempty" example:"Progress saved successfully"`
+	Progress *models.GameProgress `json:"progress"`
+}
+
+// StatsResponse is the user's stats in a game. Message is only set when the
+// stats were changed.
+type StatsResponse struct {
+	Message string              `json:"message,omitempty" example:"Stats updated successfully"`
+	Stats   *models.PlayerStats `json:"stats"`
+}
+
+// AchievementResponse is an achievement definition of a game
+type AchievementResponse struct {
+	Achievement *models.Achievement `json:"achievement"`
+	Message     string              `json:"message" example:"Achievement created successfully"`
+}
+
+// UnlockAchievementResponse is the user's unlock of an achievement.
+// NewlyUnlocked is false when the user already held it.
+type UnlockAchievementResponse struct {
+	Achievement   *models.UserAchievement `json:"achievement"`
+	Message       string                  `json:"message" example:"Achievement unlocked successfully"`
+	NewlyUnlocked bool                    `json:"newly_unlocked" example:"true"`
+}
+
+// LeaderboardEntry is the stats of a player on the leaderboard, best first
+type LeaderboardEntry struct {
+	models.PlayerStats
+}
+
+// LeaderboardResponse is the leaderboard of a game over period
+type LeaderboardResponse struct {
+	Leaderboard []LeaderboardEntry `json:"leaderboard"`
+	Period      string             `json:"period" example:"all"`
+}
+
+// ProfileResponse is the user's complete profile in a game
+type ProfileResponse struct {
+	Profile *PlayerProfile `json:"profile"`
+}
+
+// PresenceResponse lists the players connected to a game
+type PresenceResponse struct {
+	Count  int                       `json:"count" example:"3"`
+	Online []websocket.PresenceEntry `json:"online"`
+}
+
+// UserGame is the user's standing in one game they played. Progress and
+// Stats hold the stored JSON, empty when the user has none.
+type UserGame struct {
+	GameId               uint       `json:"game_id" example:"1"`
+	Slug                 string     `json:"slug" example:"tetris"`
+	Title                string     `json:"title" example:"Tetris"`
+	Icon                 string     `json:"icon" example:"tetris.png"`
+	Progress             string     `json:"progress,omitempty" example:"{\"level\":3}"`
+	Stats                string     `json:"stats,omitempty" example:"{\"score\":1200}"`
+	AchievementsUnlocked int        `json:"achievements_unlocked" example:"4"`
+	AchievementPoints    int        `json:"achievement_points" example:"40"`
+	LastPlayedAt         *time.Time `json:"last_played_at"` // Latest progress save, stats update or unlock
+}
+
+// UserGamesResponse is the user's gaming profile across every game
+type UserGamesResponse struct {
+	Games                     []UserGame `json:"games"`
+	TotalGames                int        `json:"total_games" example:"2"`
+	TotalAchievementsUnlocked int        `json:"total_achievements_unlocked" example:"7"`
+	TotalAchievementPoints    int        `json:"total_achievement_points" example:"85"`
+	LastPlayedAt              *time.Time `json:"last_played_at"`
+}
+
+// newLeaderboardEntries wraps the leaderboard stats, keeping an empty
+// leaderboard an empty list
+func newLeaderboardEntries(stats []models.PlayerStats) []LeaderboardEntry {
+	entries := make([]LeaderboardEntry, len(stats))
+	for i := range stats {
+		entries[i] = LeaderboardEntry{PlayerStats: stats[i]}
+	}
+	return entries
+}
@@ -0,0 +1,113 @@
+package games
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"base/app/models"
+	"base/core/router"
+	"base/core/websocket"
+)
+
+// TestTypedResponsesMarshalAsMaps checks each response type against the map
+// the handlers built before it
+func TestTypedResponsesMarshalAsMaps(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	game := &models.Game{Id: 1, Slug: "tetris", Title: "Tetris", CreatedAt: at, UpdatedAt: at}
+	progress := &models.GameProgress{Id: 2, UserId: 3, GameId: 1, Data: `{"level":4}`, CreatedAt: at, UpdatedAt: at}
+	stats := models.PlayerStats{Id: 4, UserId: 3, GameId: 1, Stats: `{"score":90}`, CreatedAt: at, UpdatedAt: at}
+	achievement := &models.Achievement{Id: 5, GameId: 1, Slug: "level_5", Title: "Level 5", Points: 10, CreatedAt: at, UpdatedAt: at}
+	unlocked := &models.UserAchievement{Id: 6, UserId: 3, AchievementId: 5, UnlockedAt: &at}
+	profile := &PlayerProfile{}
+	online := []websocket.PresenceEntry{{UserId: 3, Nickname: "neo", JoinedAt: at}}
+
+	tests := []struct {
+		name   string
+		typed  any
+		legacy map[string]interface{}
+	}{
+		{"error", ErrorResponse{Error: "Game not found"}, map[string]interface{}{"error": "Game not found"}},
+		{"schema error",
+			SchemaErrorResponse{Error: "Stats do not match the game's schema", Details: []models.SchemaViolation{{Path: "$.score", Message: "must be a number"}}},
+			map[string]interface{}{"error": "Stats do not match the game's schema", "details": []models.SchemaViolation{{Path: "$.score", Message: "must be a number"}}}},
+		{"message", MessageResponse{Message: "Achievement deleted successfully"}, map[string]interface{}{"message": "Achievement deleted successfully"}},
+		{"games", GamesResponse{Games: []models.Game{*game}}, map[string]interface{}{"games": []models.Game{*game}}},
+		{"game", GameResponse{Game: game, Message: "Game created successfully"}, map[string]interface{}{"game": game, "message": "Game created successfully"}},
+		{"progress", ProgressResponse{Progress: progress}, map[string]interface{}{"progress": progress}},
+		{"saved progress",
+			ProgressResponse{Progress: progress, Message: "Progress saved successfully"},
+			map[string]interface{}{"progress": progress, "message": "Progress saved successfully"}},
+		{"stats", StatsResponse{Stats: &stats}, map[string]interface{}{"stats": &stats}},
+		{"updated stats",
+			StatsResponse{Stats: &stats, Message: "Stats updated successfully"},
+			map[string]interface{}{"stats": &stats, "message": "Stats updated successfully"}},
+		{"achievement",
+			AchievementResponse{Achievement: achievement, Message: "Achievement created successfully"},
+			map[string]interface{}{"achievement": achievement, "message": "Achievement created successfully"}},
+		{"unlock",
+			UnlockAchievementResponse{Achievement: unlocked, Message: "Achievement unlocked successfully", NewlyUnlocked: true},
+			map[string]interface{}{"achievement": unlocked, "message": "Achievement unlocked successfully", "newly_unlocked": true}},
+		{"leaderboard",
+			LeaderboardResponse{Leaderboard: newLeaderboardEntries([]models.PlayerStats{stats}), Period: "all"},
+			map[string]interface{}{"leaderboard": []models.PlayerStats{stats}, "period": "all"}},
+		{"empty leaderboard",
+			LeaderboardResponse{Leaderboard: newLeaderboardEntries(nil), Period: "weekly"},
+			map[string]interface{}{"leaderboard": []models.PlayerStats{}, "period": "weekly"}},
+		{"profile", ProfileResponse{Profile: profile}, map[string]interface{}{"profile": profile}},
+		{"presence", PresenceResponse{Count: 1, Online: online}, map[string]interface{}{"count": 1, "online": online}},
+	}
+	for _, tt := range tests {
+		typed, err := json.Marshal(tt.typed)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		legacy, _ := json.Marshal(tt.legacy)
+		if string(typed) != string(legacy) {
+			t.Errorf("%s:\n got %s\nwant %s", tt.name, typed, legacy)
+		}
+	}
+}
+
+func TestTypedRequestsAreValidated(t *testing.T) {
+	c := newTestController(t)
+	c.Authenticator = asUser(createUser(t, c.Service.DB, "Owner"))
+	r := router.New()
+	c.Routes(r.Group("/api"))
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for path, body := range map[string]string{
+		"/api/games":                     `{"title":"No slug"}`,
+		"/api/games/tetris/achievements": `{"slug":"level_5","title":"Level 5","points":-1}`,
+	} {
+		if w := post(path, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s %s: status %d, want 400", path, body, w.Code)
+		}
+	}
+	if w := post("/api/games", `{"slug":"tetris","title":"Tetris"}`); w.Code != http.StatusCreated {
+		t.Fatalf("create game: status %d: %s", w.Code, w.Body)
+	}
+
+	// Progress keeps accepting any object the game's schema allows
+	w := post("/api/games/tetris/progress", `{"level":4,"checkpoint":{"x":1}}`)
+	var saved struct {
+		Message  string              `json:"message"`
+		Progress models.GameProgress `json:"progress"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &saved)
+	if w.Code != http.StatusOK || saved.Message != "Progress saved successfully" || !strings.Contains(saved.Progress.Data, `"checkpoint"`) {
+		t.Errorf("save progress: status %d: %s", w.Code, w.Body)
+	}
+	if w := post("/api/games/tetris/progress", `[1,2]`); w.Code != http.StatusBadRequest {
+		t.Errorf("progress array: status %d, want 400", w.Code)
+	}
+}
@@ -1,8 +1,13 @@
 package games
 
 import (
+	"base/app/models"
+	"base/core/app/authorization"
+	"base/core/app/notification"
+	"base/core/app/profile"
 	"base/core/module"
 	"base/core/router"
+	"base/core/router/middleware"
 )
 
 type Module struct {
@@ -30,15 +35,26 @@ func (m *Module) Routes(group *router.RouterGroup) {
 
 // NewModule creates a new Games module instance
 func NewModule(deps module.Dependencies) module.Module {
+	profile.RegisterDeletionHook("games", deleteUserGameData)
+	if deps.Emitter != nil {
+		deps.Emitter.Persist("games.achievement.unlocked", &models.UserAchievement{})
+		subscribeNotifications(deps.Emitter, notification.NewNotificationService(deps.DB), deps.Logger)
+	}
+
 	service := &Service{
 		DB:      deps.DB,
 		Emitter: deps.Emitter,
 		Logger:  deps.Logger,
 	}
 
+	authz := authorization.NewAuthorizationService(deps.DB)
 	controller := &Controller{
-		Service: service,
-		Logger:  deps.Logger,
+		Service:       service,
+		Logger:        deps.Logger,
+		Events:        NewEventBroker(deps.Emitter),
+		Authenticator: middleware.Authenticate(authz),
+		Hub:           deps.WSHub,
+		Authz:         authz,
 	}
 
 	return &Module{
@@ -0,0 +1,35 @@
+package games
+
+import (
+	"base/app/models"
+	"base/core/app/notification"
+	"base/core/emitter"
+	"base/core/logger"
+)
+
+// subscribeNotifications turns achievement unlocks into notifications for
+// the player
+func subscribeNotifications(em *emitter.Emitter, notifications *notification.NotificationService, log logger.Logger) {
+	em.On("games.achievement.unlocked", func(data any) {
+		unlocked, ok := data.(*models.UserAchievement)
+		if !ok || unlocked == nil {
+			return
+		}
+
+		body := "You unlocked a new achievement."
+		details := map[string]any{"achievement_id": unlocked.AchievementId}
+		if unlocked.Achievement != nil {
+			body = "You unlocked " + unlocked.Achievement.Title + "."
+			details["game_id"] = unlocked.Achievement.GameId
+			details["slug"] = unlocked.Achievement.Slug
+		}
+
+		_, err := notifications.Notify(unlocked.UserId, "achievement_unlocked", "Achievement unlocked", body, details)
+		if err != nil {
+			log.Error("Failed to create notification",
+				logger.String("event", "games.achievement.unlocked"),
+				logger.Uint("user_id", unlocked.UserId),
+				logger.String("error", err.Error()))
+		}
+	})
+}
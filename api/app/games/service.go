@@ -3,13 +3,29 @@ package games
 import (
 	"base/app/models"
 	"base/core/app/profile"
+	"base/core/database"
 	"base/core/emitter"
 	"base/core/logger"
+	"base/core/types"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrGameSlugTaken is returned when registering a game whose slug is already in use
+	ErrGameSlugTaken = errors.New("game slug already exists")
+	// ErrGameNotFound is returned when no game matches the requested slug
+	ErrGameNotFound = errors.New("game not found")
+	// ErrInvalidPeriod is returned for a leaderboard period other than daily, weekly, monthly or all
+	ErrInvalidPeriod = errors.New("invalid period, expected daily, weekly, monthly or all")
+	// ErrStatNotNumeric is returned when incrementing a stat that holds something other than a number
+	ErrStatNotNumeric = errors.New("stat is not numeric")
 )
 
 type Service struct {
@@ -18,6 +34,97 @@ type Service struct {
 	Logger  logger.Logger
 }
 
+// CreateGameRequest is the payload for registering a new game
+type CreateGameRequest struct {
+	Slug        string                 `json:"slug" binding:"required,max=255" example:"tetris"`
+	Title       string                 `json:"title" binding:"required,max=255" example:"Tetris"`
+	Description string                 `json:"description" example:"Stack the falling blocks"`
+	Icon        string                 `json:"icon" example:"tetris.png"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	Active      *bool                  `json:"active" example:"true"`
+
+	// Optional JSON schemas that saved progress and stats must match
+	ProgressSchema map[string]interface{} `json:"progress_schema"`
+	StatsSchema    map[string]interface{} `json:"stats_schema"`
+}
+
+// ListGames returns all registered games ordered by slug
+func (s *Service) ListGames() ([]models.Game, error) {
+	var games []models.Game
+	if err := s.DB.Order("slug ASC").Find(&games).Error; err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+// GetGame returns the game with the slug, or ErrGameNotFound
+func (s *Service) GetGame(gameSlug string) (*models.Game, error) {
+	var game models.Game
+	if err := s.DB.Where("slug = ?", gameSlug).First(&game).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGameNotFound
+		}
+		return nil, err
+	}
+	return &game, nil
+}
+
+// CreateGame registers a new game, rejecting slugs that are already taken
+func (s *Service) CreateGame(req *CreateGameRequest) (*models.Game, error) {
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	title := strings.TrimSpace(req.Title)
+	if slug == "" || title == "" {
+		return nil, errors.New("slug and title are required")
+	}
+
+	metadata := req.Metadata
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, errors.New("invalid metadata format")
+	}
+
+	progressSchema, err := encodeSchema(req.ProgressSchema)
+	if err != nil {
+		return nil, fmt.Errorf("progress_schema: %w", err)
+	}
+	statsSchema, err := encodeSchema(req.StatsSchema)
+	if err != nil {
+		return nil, fmt.Errorf("stats_schema: %w", err)
+	}
+
+	game := models.Game{
+		Slug:           slug,
+		Title:          title,
+		Description:    req.Description,
+		Icon:           req.Icon,
+		Metadata:       string(metadataJSON),
+		Active:         true,
+		ProgressSchema: progressSchema,
+		StatsSchema:    statsSchema,
+	}
+	// The unique index on slug decides, so concurrent registrations cannot
+	// both succeed; it also covers soft-deleted games
+	if err := s.DB.Create(&game).Error; err != nil {
+		if database.IsDuplicateKey(s.DB, err) {
+			return nil, ErrGameSlugTaken
+		}
+		return nil, err
+	}
+
+	// Active defaults to true at the column level, so an explicit false needs its own update
+	if req.Active != nil && !*req.Active {
+		if err := s.DB.Model(&game).Update("active", false).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	s.Emitter.EmitAsync("games.game.created", &game)
+	return &game, nil
+}
+
 // GetProgress retrieves the game progress for a user
 func (s *Service) GetProgress(userId uint, gameSlug string) (*models.GameProgress, error) {
 	var progress models.GameProgress
@@ -25,7 +132,7 @@ func (s *Service) GetProgress(userId uint, gameSlug string) (*models.GameProgres
 
 	// Find the game by slug
 	if err := s.DB.Where("slug = ?", gameSlug).First(&game).Error; err != nil {
-		return nil, errors.New("game not found")
+		return nil, ErrGameNotFound
 	}
 
 	// Find or create progress
@@ -56,7 +163,7 @@ func (s *Service) SaveProgress(userId uint, gameSlug string, data map[string]int
 
 	// Find the game by slug
 	if err := s.DB.Where("slug = ?", gameSlug).First(&game).Error; err != nil {
-		return nil, errors.New("game not found")
+		return nil, ErrGameNotFound
 	}
 
 	// Convert data to JSON
@@ -64,6 +171,9 @@ func (s *Service) SaveProgress(userId uint, gameSlug string, data map[string]int
 	if err != nil {
 		return nil, errors.New("invalid data format")
 	}
+	if err := validateAgainstSchema(game.ProgressSchema, dataJSON); err != nil {
+		return nil, err
+	}
 
 	var progress models.GameProgress
 	err = s.DB.Where("user_id = ? AND game_id = ?", userId, game.Id).First(&progress).Error
@@ -92,96 +202,131 @@ func (s *Service) SaveProgress(userId uint, gameSlug string, data map[string]int
 		}
 	}
 
-	s.Emitter.Emit("games.progress.saved", &progress)
+	s.Emitter.EmitAsync("games.progress.saved", &progress)
 	return &progress, nil
 }
 
-// GetAchievements retrieves available achievements for a game
-func (s *Service) GetAchievements(gameSlug string) ([]models.Achievement, error) {
+// AchievementStatus is an achievement merged with the requesting user's unlock state
+type AchievementStatus struct {
+	models.Achievement
+	Unlocked   bool       `gorm:"column:unlocked" json:"unlocked"`
+	UnlockedAt *time.Time `gorm:"column:unlocked_at" json:"unlocked_at"`
+}
+
+// GetAchievements retrieves a page of a game's achievements, each flagged with
+// whether the user has unlocked it. Unlock state comes from a single LEFT JOIN.
+func (s *Service) GetAchievements(userId uint, gameSlug string, page, limit int) (*types.PaginatedResponse, error) {
 	var game models.Game
-	var achievements []models.Achievement
+	var total int64
+	achievements := []AchievementStatus{}
 
 	// Find the game by slug
 	if err := s.DB.Where("slug = ?", gameSlug).First(&game).Error; err != nil {
-		return nil, errors.New("game not found")
+		return nil, ErrGameNotFound
 	}
 
-	if err := s.DB.Where("game_id = ?", game.Id).Find(&achievements).Error; err != nil {
+	query := s.DB.Model(&models.Achievement{}).
+		Joins("LEFT JOIN user_achievements ON user_achievements.achievement_id = achievements.id AND user_achievements.user_id = ? AND user_achievements.deleted_at IS NULL", userId).
+		Where("achievements.game_id = ?", game.Id)
+
+	if err := query.Count(&total).Error; err != nil {
 		return nil, err
 	}
 
-	return achievements, nil
+	err := query.
+		Select("achievements.*, CASE WHEN user_achievements.id IS NULL THEN 0 ELSE 1 END AS unlocked, user_achievements.unlocked_at").
+		Order("achievements.id ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Scan(&achievements).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.PaginatedResponse{
+		Data: achievements,
+		Pagination: types.Pagination{
+			Total:      int(total),
+			Page:       page,
+			PageSize:   limit,
+			TotalPages: int(total+int64(limit)-1) / limit,
+		},
+	}, nil
 }
 
 // GetUserAchievements retrieves unlocked achievements for a user
 func (s *Service) GetUserAchievements(userId uint, gameSlug string) ([]models.UserAchievement, error) {
 	var game models.Game
-	var achievements []models.Achievement
 	var userAchievements []models.UserAchievement
 
 	// Find the game by slug
 	if err := s.DB.Where("slug = ?", gameSlug).First(&game).Error; err != nil {
-		return nil, errors.New("game not found")
-	}
-
-	// Get all game achievements
-	if err := s.DB.Where("game_id = ?", game.Id).Find(&achievements).Error; err != nil {
-		return nil, err
-	}
-
-	// Get user's unlocked achievements
-	achievementIds := make([]uint, len(achievements))
-	for i, ach := range achievements {
-		achievementIds[i] = ach.Id
+		return nil, ErrGameNotFound
 	}
 
-	if err := s.DB.Preload("Achievement").Where("user_id = ? AND achievement_id IN ?", userId, achievementIds).Find(&userAchievements).Error; err != nil {
+	// Load unlocked achievements together with their achievement in one joined query
+	err := s.DB.InnerJoins("Achievement", s.DB.Where(&models.Achievement{GameId: game.Id})).
+		Where("user_achievements.user_id = ?", userId).
+		Find(&userAchievements).Error
+	if err != nil {
 		return nil, err
 	}
 
 	return userAchievements, nil
 }
 
-// UnlockAchievement unlocks an achievement for a user
-func (s *Service) UnlockAchievement(userId uint, gameSlug string, achievementSlug string) (*models.UserAchievement, error) {
+// UnlockAchievement unlocks an achievement for a user. Unlocking is
+// idempotent: an achievement that is already unlocked is returned as is with
+// newlyUnlocked false, and games.achievement.unlocked is only emitted when it
+// is new.
+func (s *Service) UnlockAchievement(userId uint, gameSlug string, achievementSlug string) (userAchievement *models.UserAchievement, newlyUnlocked bool, err error) {
 	var game models.Game
 	var achievement models.Achievement
 
 	// Find the game by slug
 	if err := s.DB.Where("slug = ?", gameSlug).First(&game).Error; err != nil {
-		return nil, errors.New("game not found")
+		return nil, false, ErrGameNotFound
 	}
 
 	// Find the achievement
 	if err := s.DB.Where("game_id = ? AND slug = ?", game.Id, achievementSlug).First(&achievement).Error; err != nil {
-		return nil, errors.New("achievement not found")
+		return nil, false, errors.New("achievement not found")
 	}
 
 	// Check if already unlocked
 	var existing models.UserAchievement
-	err := s.DB.Where("user_id = ? AND achievement_id = ?", userId, achievement.Id).First(&existing).Error
+	err = s.DB.Where("user_id = ? AND achievement_id = ?", userId, achievement.Id).First(&existing).Error
 	if err == nil {
-		return &existing, nil // Already unlocked
+		existing.Achievement = &achievement
+		return &existing, false, nil // Already unlocked
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
 	}
 
 	// Unlock achievement
 	now := time.Now()
-	userAchievement := models.UserAchievement{
+	userAchievement = &models.UserAchievement{
 		UserId:        userId,
 		AchievementId: achievement.Id,
 		UnlockedAt:    &now,
 		Progress:      "{}",
 	}
 
-	if err := s.DB.Create(&userAchievement).Error; err != nil {
-		return nil, err
-	}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(userAchievement).Error; err != nil {
+			return err
+		}
+		userAchievement.Achievement = &achievement
 
-	// Preload the achievement details
-	s.DB.Preload("Achievement").First(&userAchievement, userAchievement.Id)
+		// The event is persisted, so it is stored with the unlock
+		return s.Emitter.EmitTx(tx, "games.achievement.unlocked", userAchievement)
+	})
+	if err != nil {
+		return nil, false, err
+	}
 
-	s.Emitter.Emit("games.achievement.unlocked", &userAchievement)
-	return &userAchievement, nil
+	return userAchievement, true, nil
 }
 
 // GetStats retrieves player stats
@@ -191,7 +336,7 @@ func (s *Service) GetStats(userId uint, gameSlug string) (*models.PlayerStats, e
 
 	// Find the game by slug
 	if err := s.DB.Where("slug = ?", gameSlug).First(&game).Error; err != nil {
-		return nil, errors.New("game not found")
+		return nil, ErrGameNotFound
 	}
 
 	// Find or create stats
@@ -221,7 +366,7 @@ func (s *Service) UpdateStats(userId uint, gameSlug string, statsData map[string
 
 	// Find the game by slug
 	if err := s.DB.Where("slug = ?", gameSlug).First(&game).Error; err != nil {
-		return nil, errors.New("game not found")
+		return nil, ErrGameNotFound
 	}
 
 	// Convert stats to JSON
@@ -229,6 +374,9 @@ func (s *Service) UpdateStats(userId uint, gameSlug string, statsData map[string
 	if err != nil {
 		return nil, errors.New("invalid stats format")
 	}
+	if err := validateAgainstSchema(game.StatsSchema, statsJSON); err != nil {
+		return nil, err
+	}
 
 	var stats models.PlayerStats
 	err = s.DB.Where("user_id = ? AND game_id = ?", userId, game.Id).First(&stats).Error
@@ -239,8 +387,11 @@ func (s *Service) UpdateStats(userId uint, gameSlug string, statsData map[string
 			stats = models.PlayerStats{
 				UserId: userId,
 				GameId: game.Id,
-				Stats:  string(statsJSON),
 			}
+			if err := snapshotStats(s.DB, &stats, time.Now()); err != nil {
+				return nil, err
+			}
+			stats.Stats = string(statsJSON)
 			if err := s.DB.Create(&stats).Error; err != nil {
 				return nil, err
 			}
@@ -249,31 +400,122 @@ func (s *Service) UpdateStats(userId uint, gameSlug string, statsData map[string
 		}
 	} else {
 		// Update existing stats
+		if err := snapshotStats(s.DB, &stats, time.Now()); err != nil {
+			return nil, err
+		}
 		stats.Stats = string(statsJSON)
 		if err := s.DB.Save(&stats).Error; err != nil {
 			return nil, err
 		}
 	}
 
-	s.Emitter.Emit("games.stats.updated", &stats)
+	s.Emitter.EmitAsync("games.stats.updated", &stats)
+	return &stats, nil
+}
+
+// IncrementStat atomically adds delta to a single numeric stat, locking the
+// stats row for the duration so concurrent increments are not lost
+func (s *Service) IncrementStat(userId uint, gameSlug string, key string, delta float64) (*models.PlayerStats, error) {
+	var game models.Game
+
+	// Find the game by slug
+	if err := s.DB.Where("slug = ?", gameSlug).First(&game).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGameNotFound
+		}
+		return nil, err
+	}
+
+	var stats models.PlayerStats
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		// A missing row locks nothing, so create it first. The unique index
+		// on user_id and game_id leaves concurrent first increments one row,
+		// which the lock below then serializes them on.
+		blank := models.PlayerStats{UserId: userId, GameId: game.Id, Stats: "{}"}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&blank).Error; err != nil {
+			return err
+		}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND game_id = ?", userId, game.Id).
+			First(&stats).Error
+		if err != nil {
+			return err
+		}
+
+		if err := snapshotStats(tx, &stats, time.Now()); err != nil {
+			return err
+		}
+
+		statsData := map[string]interface{}{}
+		if stats.Stats != "" {
+			if err := json.Unmarshal([]byte(stats.Stats), &statsData); err != nil {
+				return errors.New("invalid stats format")
+			}
+		}
+
+		current := 0.0
+		if existing, ok := statsData[key]; ok {
+			value, ok := existing.(float64)
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrStatNotNumeric, key)
+			}
+			current = value
+		}
+		statsData[key] = current + delta
+
+		statsJSON, err := json.Marshal(statsData)
+		if err != nil {
+			return errors.New("invalid stats format")
+		}
+		if err := validateAgainstSchema(game.StatsSchema, statsJSON); err != nil {
+			return err
+		}
+		stats.Stats = string(statsJSON)
+
+		return tx.Save(&stats).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Emitter.EmitAsync("games.stats.updated", &stats)
 	return &stats, nil
 }
 
-// GetLeaderboard retrieves top players by a specific stat
-func (s *Service) GetLeaderboard(gameSlug string, limit int) ([]models.PlayerStats, error) {
+// GetLeaderboard retrieves top players by a specific stat. For daily, weekly and
+// monthly periods only players active in the current period are included, and
+// numeric stats report what was achieved since the period began.
+func (s *Service) GetLeaderboard(gameSlug string, period string, limit int) ([]models.PlayerStats, error) {
 	var game models.Game
 	var stats []models.PlayerStats
 
+	if !IsValidPeriod(period) {
+		return nil, ErrInvalidPeriod
+	}
+
 	// Find the game by slug
 	if err := s.DB.Where("slug = ?", gameSlug).First(&game).Error; err != nil {
-		return nil, errors.New("game not found")
+		return nil, ErrGameNotFound
+	}
+
+	// Leaderboards tolerate replication lag, so read from a replica when available
+	query := database.Reader(s.DB).Preload("User").Where("game_id = ?", game.Id)
+	start := PeriodStart(period, time.Now())
+	if period != PeriodAll {
+		query = query.Where("updated_at >= ?", start)
 	}
 
 	// Get top players (you may want to sort by a specific stat in the JSON)
-	if err := s.DB.Preload("User").Where("game_id = ?", game.Id).Limit(limit).Order("updated_at DESC").Find(&stats).Error; err != nil {
+	if err := query.Limit(limit).Order("updated_at DESC").Find(&stats).Error; err != nil {
 		return nil, err
 	}
 
+	if period != PeriodAll {
+		if err := s.applyPeriodSnapshots(stats, game.Id, period, start); err != nil {
+			return nil, err
+		}
+	}
+
 	return stats, nil
 }
 
@@ -294,7 +536,7 @@ func (s *Service) GetPlayerProfile(userId uint, gameSlug string) (*PlayerProfile
 
 	// Find the game by slug
 	if err := s.DB.Where("slug = ?", gameSlug).First(&game).Error; err != nil {
-		return nil, errors.New("game not found")
+		return nil, ErrGameNotFound
 	}
 
 	// Get user
@@ -342,3 +584,35 @@ func (s *Service) GetPlayerProfile(userId uint, gameSlug string) (*PlayerProfile
 
 	return profile, nil
 }
+
+// encodeSchema checks a schema from a request and returns it as stored on
+// the game; a nil schema is stored empty
+func encodeSchema(schema map[string]interface{}) (string, error) {
+	if schema == nil {
+		return "", nil
+	}
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", models.ErrInvalidSchema, err)
+	}
+	if _, err := models.ParseJSONSchema(string(schemaJSON)); err != nil {
+		return "", err
+	}
+	return string(schemaJSON), nil
+}
+
+// validateAgainstSchema checks encoded data against a game's stored schema,
+// returning a *models.SchemaValidationError when it does not match. Data is
+// accepted as is when the game has no schema.
+func validateAgainstSchema(schema string, dataJSON []byte) error {
+	parsed, err := models.ParseJSONSchema(schema)
+	if err != nil || parsed == nil {
+		return err
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(dataJSON, &data); err != nil {
+		return err
+	}
+	return parsed.Validate(data)
+}
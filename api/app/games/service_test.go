@@ -0,0 +1,216 @@
+package games
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"base/app/models"
+	"base/core/emitter"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func TestCreateGameRejectsTakenSlug(t *testing.T) {
+	s := newTestController(t).Service
+
+	if _, err := s.CreateGame(&CreateGameRequest{Slug: "tetris", Title: "Tetris"}); err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	// Slugs are stored lower-cased
+	if _, err := s.CreateGame(&CreateGameRequest{Slug: "Tetris", Title: "Another Tetris"}); !errors.Is(err, ErrGameSlugTaken) {
+		t.Errorf("duplicate slug: got %v, want ErrGameSlugTaken", err)
+	}
+}
+
+func TestCreateGameConcurrentRegistrationsCreateOne(t *testing.T) {
+	s := newTestController(t).Service
+
+	const attempts = 8
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		taken int
+		other []error
+	)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.CreateGame(&CreateGameRequest{Slug: "snake", Title: "Snake"})
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, ErrGameSlugTaken):
+				taken++
+			case err != nil:
+				other = append(other, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(other) > 0 {
+		t.Fatalf("unexpected errors: %v", other)
+	}
+	if taken != attempts-1 {
+		t.Errorf("%d registrations were rejected, want %d", taken, attempts-1)
+	}
+}
+
+func TestIncrementStatConcurrentIncrementsAreExact(t *testing.T) {
+	s := newTestController(t).Service
+	if _, err := s.CreateGame(&CreateGameRequest{Slug: "tetris", Title: "Tetris"}); err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+
+	const workers, increments = 10, 5
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*increments)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				if _, err := s.IncrementStat(1, "tetris", "score", 1.5); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("IncrementStat: %v", err)
+	}
+
+	stats, err := s.IncrementStat(1, "tetris", "score", 0)
+	if err != nil {
+		t.Fatalf("IncrementStat: %v", err)
+	}
+	var values map[string]float64
+	if err := json.Unmarshal([]byte(stats.Stats), &values); err != nil {
+		t.Fatalf("decode stats: %v", err)
+	}
+	if want := workers * increments * 1.5; values["score"] != want {
+		t.Errorf("score = %v, want %v", values["score"], want)
+	}
+}
+
+func TestIncrementStatFirstIncrementRacedOnMissingRow(t *testing.T) {
+	c := newTestController(t)
+	game, err := c.Service.CreateGame(&CreateGameRequest{Slug: "tetris", Title: "Tetris"})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+
+	// Without _txlock=immediate a transaction takes no lock until it writes,
+	// so another connection can insert the row between its begin and insert
+	var file string
+	c.Service.DB.Raw("SELECT file FROM pragma_database_list WHERE name = 'main'").Scan(&file)
+	db, err := gorm.Open(sqlite.Open(file+"?_busy_timeout=10000"), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raced := false
+	db.Callback().Create().Before("gorm:create").Register("test:competing_insert", func(tx *gorm.DB) {
+		if tx.Statement.Table != "player_stats" || raced {
+			return
+		}
+		raced = true
+		err := c.Service.DB.Exec("INSERT INTO player_stats (user_id, game_id, stats, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+			1, game.Id, `{"score":2}`, time.Now(), time.Now()).Error
+		if err != nil {
+			t.Errorf("competing insert: %v", err)
+		}
+	})
+	s := &Service{DB: db, Emitter: emitter.New(), Logger: c.Service.Logger}
+
+	stats, err := s.IncrementStat(1, "tetris", "score", 1.5)
+	if err != nil {
+		t.Fatalf("IncrementStat: %v", err)
+	}
+	if !raced {
+		t.Fatal("the increment created no stats row")
+	}
+	var count int64
+	db.Model(&models.PlayerStats{}).Where("user_id = ? AND game_id = ?", 1, game.Id).Count(&count)
+	if count != 1 {
+		t.Errorf("%d stats rows, want the raced row only", count)
+	}
+	var values map[string]float64
+	json.Unmarshal([]byte(stats.Stats), &values)
+	if values["score"] != 3.5 {
+		t.Errorf("score = %v, want the competing 2 plus 1.5", values["score"])
+	}
+
+	// The index refuses a second row outright
+	duplicate := models.PlayerStats{UserId: 1, GameId: game.Id, Stats: "{}"}
+	if err := db.Create(&duplicate).Error; err == nil {
+		t.Error("a second stats row for the same user and game was stored")
+	}
+}
+
+func TestIncrementStatErrors(t *testing.T) {
+	s := newTestController(t).Service
+	if _, err := s.CreateGame(&CreateGameRequest{Slug: "tetris", Title: "Tetris"}); err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	if _, err := s.UpdateStats(1, "tetris", map[string]interface{}{"rank": "gold"}); err != nil {
+		t.Fatalf("UpdateStats: %v", err)
+	}
+
+	if _, err := s.IncrementStat(1, "missing", "score", 1); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("missing game: got %v, want ErrGameNotFound", err)
+	}
+	if _, err := s.IncrementStat(1, "tetris", "rank", 1); !errors.Is(err, ErrStatNotNumeric) {
+		t.Errorf("non-numeric stat: got %v, want ErrStatNotNumeric", err)
+	}
+}
+
+func TestUnlockAchievementTwiceReportsNoop(t *testing.T) {
+	s := newTestController(t).Service
+	game, err := s.CreateGame(&CreateGameRequest{Slug: "tetris", Title: "Tetris"})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	if err := s.DB.Create(&models.Achievement{GameId: game.Id, Slug: "first-line", Title: "First line", Criteria: "{}"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	userId := createUser(t, s.DB, "Member")
+
+	var emitted int
+	s.Emitter.On("games.achievement.unlocked", func(any) { emitted++ })
+
+	first, newlyUnlocked, err := s.UnlockAchievement(userId, "tetris", "first-line")
+	if err != nil {
+		t.Fatalf("first unlock: %v", err)
+	}
+	if !newlyUnlocked {
+		t.Error("first unlock was not reported as new")
+	}
+
+	second, newlyUnlocked, err := s.UnlockAchievement(userId, "tetris", "first-line")
+	if err != nil {
+		t.Fatalf("second unlock: %v", err)
+	}
+	if newlyUnlocked {
+		t.Error("second unlock was reported as new")
+	}
+	if second.Id != first.Id || second.Achievement == nil || second.Achievement.Slug != "first-line" {
+		t.Errorf("second unlock returned %+v, want the existing unlock with its achievement", second)
+	}
+	if emitted != 1 {
+		t.Errorf("unlocked event emitted %d times, want once", emitted)
+	}
+
+	var count int64
+	s.DB.Model(&models.UserAchievement{}).Where("user_id = ?", userId).Count(&count)
+	if count != 1 {
+		t.Errorf("%d unlocks stored, want 1", count)
+	}
+}
@@ -0,0 +1,159 @@
+package games
+
+import (
+	"base/app/models"
+	"base/core/database"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidStatOperator is returned for a comparison other than =, !=, <, <=, > or >=
+	ErrInvalidStatOperator = errors.New("invalid operator, expected =, !=, <, <=, > or >=")
+	// ErrInvalidStatValue is returned for a value that is not a number, string or bool
+	ErrInvalidStatValue = errors.New("invalid value, expected a number, string or bool")
+)
+
+var statOperators = map[string]bool{
+	"=": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+}
+
+// QueryStats returns the players of a game whose stat at jsonPath ("score",
+// "$.score" or "level.best") compares to value with op. Numbers compare
+// numerically, strings and bools by their text. MySQL and Postgres filter in
+// SQL, using the indexes on models.IndexedStatKeys; SQLite filters in Go.
+func (s *Service) QueryStats(gameSlug string, jsonPath string, op string, value interface{}) ([]models.PlayerStats, error) {
+	segments, err := models.ParseJSONPath(jsonPath)
+	if err != nil {
+		return nil, err
+	}
+	if !statOperators[op] {
+		return nil, ErrInvalidStatOperator
+	}
+	value, err = normalizeStatValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var game models.Game
+	if err := s.DB.Where("slug = ?", gameSlug).First(&game).Error; err != nil {
+		return nil, ErrGameNotFound
+	}
+
+	db := database.Reader(s.DB)
+	query := db.Preload("User").Where("game_id = ?", game.Id).Order("id ASC")
+	stats := []models.PlayerStats{}
+
+	dialect := db.Dialector.Name()
+	if dialect != "mysql" && dialect != "postgres" {
+		if err := query.Find(&stats).Error; err != nil {
+			return nil, err
+		}
+		return filterStats(stats, segments, op, value), nil
+	}
+
+	var expr string
+	switch typed := value.(type) {
+	case float64:
+		expr = models.JSONNumberExpr(dialect, "stats", segments)
+	case bool:
+		expr = models.JSONTextExpr(dialect, "stats", segments)
+		value = fmt.Sprint(typed)
+	default:
+		expr = models.JSONTextExpr(dialect, "stats", segments)
+	}
+
+	// A missing key yields NULL, which matches no comparison; != needs the
+	// value to be present too, the same as the Go-side filter
+	if err := query.Where(fmt.Sprintf("%s %s ?", expr, op), value).Find(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// normalizeStatValue converts numbers to float64, the type encoding/json
+// decodes them to
+func normalizeStatValue(value interface{}) (interface{}, error) {
+	switch typed := value.(type) {
+	case float64, string, bool:
+		return typed, nil
+	case float32:
+		return float64(typed), nil
+	case int:
+		return float64(typed), nil
+	case int32:
+		return float64(typed), nil
+	case int64:
+		return float64(typed), nil
+	case uint:
+		return float64(typed), nil
+	case uint32:
+		return float64(typed), nil
+	case uint64:
+		return float64(typed), nil
+	}
+	return nil, ErrInvalidStatValue
+}
+
+// filterStats keeps the entries whose stat at segments compares to value with op
+func filterStats(stats []models.PlayerStats, segments []string, op string, value interface{}) []models.PlayerStats {
+	matches := []models.PlayerStats{}
+	for _, entry := range stats {
+		data, err := entry.StatsMap()
+		if err != nil {
+			continue
+		}
+		actual, ok := models.LookupJSONPath(data, segments)
+		if ok && compareStat(actual, op, value) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// compareStat compares a decoded stat with value. Numbers only match numbers;
+// strings and bools compare by text, as they do in SQL.
+func compareStat(actual interface{}, op string, value interface{}) bool {
+	cmp := 0
+	switch expected := value.(type) {
+	case float64:
+		number, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch {
+		case number < expected:
+			cmp = -1
+		case number > expected:
+			cmp = 1
+		}
+	default:
+		var text string
+		switch typed := actual.(type) {
+		case string:
+			text = typed
+		case bool:
+			text = fmt.Sprint(typed)
+		default:
+			return false
+		}
+		cmp = strings.Compare(text, fmt.Sprint(expected))
+	}
+
+	switch op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
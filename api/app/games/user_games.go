@@ -0,0 +1,126 @@
+package games
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// userGameRow is a game the user played, joined with their progress and stats
+type userGameRow struct {
+	GameId         uint
+	Slug           string
+	Title          string
+	Icon           string
+	Progress       *string
+	ProgressSynced *time.Time
+	Stats          *string
+	StatsUpdated   *time.Time
+}
+
+// userUnlockRow is an achievement the user unlocked
+type userUnlockRow struct {
+	GameId     uint
+	Points     int
+	UnlockedAt *time.Time
+}
+
+// GetUserGames returns every game the user has progress, stats or unlocked
+// achievements in, most recently played first. Two joined queries cover all
+// games, whatever their number.
+func (s *Service) GetUserGames(userId uint) (*UserGamesResponse, error) {
+	var unlocks []userUnlockRow
+	err := s.DB.Table("user_achievements AS ua").
+		Select("a.game_id, a.points, ua.unlocked_at").
+		Joins("JOIN achievements AS a ON a.id = ua.achievement_id AND a.deleted_at IS NULL").
+		Where("ua.user_id = ? AND ua.deleted_at IS NULL", userId).
+		Scan(&unlocks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []userGameRow
+	err = s.DB.Table("games AS g").
+		Select("g.id AS game_id, g.slug, g.title, g.icon, "+
+			"gp.data AS progress, gp.last_synced_at AS progress_synced, "+
+			"ps.stats, ps.updated_at AS stats_updated").
+		Joins("LEFT JOIN game_progress AS gp ON gp.game_id = g.id AND gp.user_id = ? AND gp.deleted_at IS NULL", userId).
+		Joins("LEFT JOIN player_stats AS ps ON ps.game_id = g.id AND ps.user_id = ? AND ps.deleted_at IS NULL", userId).
+		Where("g.deleted_at IS NULL").
+		Where("gp.id IS NOT NULL OR ps.id IS NOT NULL OR g.id IN (?)",
+			s.DB.Table("achievements AS a").
+				Select("a.game_id").
+				Joins("JOIN user_achievements AS ua ON ua.achievement_id = a.id AND ua.deleted_at IS NULL").
+				Where("ua.user_id = ? AND a.deleted_at IS NULL", userId)).
+		Order("g.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	response := &UserGamesResponse{Games: []UserGame{}}
+	index := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		// A user has one progress and one stats row per game; should the
+		// joins still repeat a game, its first row wins
+		if _, seen := index[row.GameId]; seen {
+			continue
+		}
+		game := UserGame{
+			GameId:       row.GameId,
+			Slug:         row.Slug,
+			Title:        row.Title,
+			Icon:         row.Icon,
+			LastPlayedAt: latest(row.ProgressSynced, row.StatsUpdated),
+		}
+		if row.Progress != nil {
+			game.Progress = *row.Progress
+		}
+		if row.Stats != nil {
+			game.Stats = *row.Stats
+		}
+		index[row.GameId] = len(response.Games)
+		response.Games = append(response.Games, game)
+	}
+
+	for _, unlock := range unlocks {
+		i, ok := index[unlock.GameId]
+		if !ok {
+			continue // The game was deleted
+		}
+		game := &response.Games[i]
+		game.AchievementsUnlocked++
+		game.AchievementPoints += unlock.Points
+		game.LastPlayedAt = latest(game.LastPlayedAt, unlock.UnlockedAt)
+	}
+
+	for _, game := range response.Games {
+		response.TotalAchievementsUnlocked += game.AchievementsUnlocked
+		response.TotalAchievementPoints += game.AchievementPoints
+		response.LastPlayedAt = latest(response.LastPlayedAt, game.LastPlayedAt)
+	}
+	response.TotalGames = len(response.Games)
+
+	// Most recently played first; never played games last, by slug
+	slices.SortStableFunc(response.Games, func(a, b UserGame) int {
+		switch {
+		case a.LastPlayedAt == nil && b.LastPlayedAt == nil:
+			return cmp.Compare(a.Slug, b.Slug)
+		case a.LastPlayedAt == nil:
+			return 1
+		case b.LastPlayedAt == nil:
+			return -1
+		}
+		return b.LastPlayedAt.Compare(*a.LastPlayedAt)
+	})
+
+	return response, nil
+}
+
+// latest returns the later of two optional times
+func latest(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.After(*a)) {
+		return b
+	}
+	return a
+}
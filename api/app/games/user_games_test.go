@@ -0,0 +1,117 @@
+package games
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"base/app/models"
+	"base/core/router"
+
+	"gorm.io/gorm"
+)
+
+func TestGetUserGamesAcrossTwoGames(t *testing.T) {
+	c := newTestController(t)
+	db := c.Service.DB
+	user := createUser(t, db, "Member")
+	other := createUser(t, db, "Member")
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	games := make(map[string]uint)
+	for _, slug := range []string{"tetris", "chess", "snake", "go"} {
+		game, err := c.Service.CreateGame(&CreateGameRequest{Slug: slug, Title: slug})
+		if err != nil {
+			t.Fatal(err)
+		}
+		games[slug] = game.Id
+	}
+	progress := func(userId uint, slug, data string, syncedAt time.Time) {
+		row := models.GameProgress{UserId: userId, GameId: games[slug], Data: data}
+		db.Create(&row)
+		db.Model(&row).UpdateColumn("last_synced_at", syncedAt)
+	}
+	unlock := func(slug string, points int, at time.Time) *models.Achievement {
+		achievement := models.Achievement{GameId: games[slug], Slug: slug + at.Format("1504"), Title: "Achievement", Points: points, Criteria: "{}"}
+		db.Create(&achievement)
+		db.Create(&models.UserAchievement{UserId: user, AchievementId: achievement.Id, UnlockedAt: &at})
+		return &achievement
+	}
+
+	// tetris: progress, stats and two unlocks, last played by the stats update
+	progress(user, "tetris", `{"level":3}`, start.Add(time.Hour))
+	stats := models.PlayerStats{UserId: user, GameId: games["tetris"], Stats: `{"score":1200}`}
+	db.Create(&stats)
+	db.Model(&stats).UpdateColumn("updated_at", start.Add(2*time.Hour))
+	unlock("tetris", 10, start)
+	unlock("tetris", 15, start.Add(30*time.Minute))
+	// An unlock of a deleted achievement counts for nothing
+	db.Delete(unlock("tetris", 100, start.Add(10*time.Hour)))
+
+	// chess: progress and a later unlock
+	progress(user, "chess", `{"moves":12}`, start.Add(3*time.Hour))
+	unlock("chess", 5, start.Add(5*time.Hour))
+
+	// go is only played by someone else and snake by nobody
+	progress(other, "go", `{"stones":4}`, start.Add(6*time.Hour))
+
+	queries := 0
+	count := func(tx *gorm.DB) {
+		// Subqueries are only compiled, in a dry run
+		if !tx.DryRun {
+			queries++
+		}
+	}
+	db.Callback().Query().After("gorm:query").Register("test:count_user_games_queries", count)
+	db.Callback().Row().After("gorm:row").Register("test:count_user_games_rows", count)
+
+	c.Authenticator = asUser(user)
+	r := router.New()
+	c.Routes(r.Group("/api"))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/me/games", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if queries != 2 {
+		t.Errorf("%d queries, want the two joined queries", queries)
+	}
+	var response UserGamesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+
+	if response.TotalGames != 2 || len(response.Games) != 2 {
+		t.Fatalf("games = %+v, want chess and tetris", response.Games)
+	}
+	chess, tetris := response.Games[0], response.Games[1]
+	if chess.Slug != "chess" || tetris.Slug != "tetris" {
+		t.Fatalf("order %s, %s; want the most recently played first", chess.Slug, tetris.Slug)
+	}
+	if chess.Progress != `{"moves":12}` || chess.Stats != "" || chess.AchievementsUnlocked != 1 || chess.AchievementPoints != 5 {
+		t.Errorf("chess = %+v", chess)
+	}
+	if chess.LastPlayedAt == nil || !chess.LastPlayedAt.Equal(start.Add(5*time.Hour)) {
+		t.Errorf("chess last played %v, want the unlock time", chess.LastPlayedAt)
+	}
+	if tetris.Progress != `{"level":3}` || tetris.Stats != `{"score":1200}` || tetris.AchievementsUnlocked != 2 || tetris.AchievementPoints != 25 {
+		t.Errorf("tetris = %+v", tetris)
+	}
+	if tetris.LastPlayedAt == nil || !tetris.LastPlayedAt.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("tetris last played %v, want the stats update", tetris.LastPlayedAt)
+	}
+	if response.TotalAchievementsUnlocked != 3 || response.TotalAchievementPoints != 30 {
+		t.Errorf("totals %d unlocked, %d points; want 3 and 30", response.TotalAchievementsUnlocked, response.TotalAchievementPoints)
+	}
+	if response.LastPlayedAt == nil || !response.LastPlayedAt.Equal(start.Add(5*time.Hour)) {
+		t.Errorf("last played %v, want the chess unlock", response.LastPlayedAt)
+	}
+
+	// A user who never played gets an empty list
+	empty, err := c.Service.GetUserGames(createUser(t, db, "Member"))
+	if err != nil || empty.Games == nil || empty.TotalGames != 0 || empty.LastPlayedAt != nil {
+		t.Errorf("new user: %+v, %v", empty, err)
+	}
+}
@@ -13,10 +13,15 @@ type Game struct {
 	Title       string         `gorm:"column:title;not null;size:255" json:"title" validate:"required"`
 	Description string         `gorm:"column:description;type:text" json:"description"`
 	Icon        string         `gorm:"column:icon" json:"icon"`
+	Metadata    string         `gorm:"column:metadata;type:json" json:"metadata"` // JSON field for game-specific settings
 	Active      bool           `gorm:"column:active;default:true" json:"active"`
 	CreatedAt   time.Time      `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt   time.Time      `gorm:"column:updated_at" json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"column:deleted_at;index" json:"-"`
+
+	// Optional JSON schemas that saved progress and stats must match; empty accepts any data
+	ProgressSchema string `gorm:"column:progress_schema;type:text" json:"progress_schema,omitempty"`
+	StatsSchema    string `gorm:"column:stats_schema;type:text" json:"stats_schema,omitempty"`
 }
 
 func (Game) TableName() string {
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidJSONPath is returned for a path that is empty or has segments
+// other than letters, digits and underscores
+var ErrInvalidJSONPath = errors.New("invalid JSON path")
+
+// jsonPathSegment restricts path segments to plain keys, since paths are
+// placed into SQL expressions when querying JSON columns
+var jsonPathSegment = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// ParseJSONPath splits a path such as "score", "$.score" or "level.best"
+// into its keys
+func ParseJSONPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(path), "$"), ".")
+	if path == "" {
+		return nil, ErrInvalidJSONPath
+	}
+
+	segments := strings.Split(path, ".")
+	for _, segment := range segments {
+		if !jsonPathSegment.MatchString(segment) {
+			return nil, ErrInvalidJSONPath
+		}
+	}
+	return segments, nil
+}
+
+// LookupJSONPath returns the value at segments in data
+func LookupJSONPath(data map[string]interface{}, segments []string) (interface{}, bool) {
+	var current interface{} = data
+	for _, segment := range segments {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = object[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setJSONPath sets the value at segments, creating intermediate objects and
+// replacing non-object values on the way
+func setJSONPath(data map[string]interface{}, segments []string, value interface{}) {
+	current := data
+	for _, segment := range segments[:len(segments)-1] {
+		next, ok := current[segment].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			current[segment] = next
+		}
+		current = next
+	}
+	current[segments[len(segments)-1]] = value
+}
+
+// decodeJSONObject decodes a JSON column, treating an empty column as {}
+func decodeJSONObject(raw string) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	if strings.TrimSpace(raw) == "" {
+		return data, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// updateJSONObject decodes raw, sets value at path and encodes the result
+func updateJSONObject(raw, path string, value interface{}) (string, error) {
+	segments, err := ParseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+	data, err := decodeJSONObject(raw)
+	if err != nil {
+		return "", err
+	}
+	setJSONPath(data, segments, value)
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// lookupJSONObject decodes raw and returns the value at path
+func lookupJSONObject(raw, path string) (interface{}, bool) {
+	segments, err := ParseJSONPath(path)
+	if err != nil {
+		return nil, false
+	}
+	data, err := decodeJSONObject(raw)
+	if err != nil {
+		return nil, false
+	}
+	return LookupJSONPath(data, segments)
+}
+
+// StatsMap decodes the stats JSON
+func (p *PlayerStats) StatsMap() (map[string]interface{}, error) {
+	return decodeJSONObject(p.Stats)
+}
+
+// Stat returns the stat at path, e.g. "score" or "level.best"
+func (p *PlayerStats) Stat(path string) (interface{}, bool) {
+	return lookupJSONObject(p.Stats, path)
+}
+
+// StatFloat returns the numeric stat at path
+func (p *PlayerStats) StatFloat(path string) (float64, bool) {
+	value, ok := p.Stat(path)
+	if !ok {
+		return 0, false
+	}
+	number, ok := value.(float64)
+	return number, ok
+}
+
+// SetStat sets the stat at path; the change is not saved
+func (p *PlayerStats) SetStat(path string, value interface{}) error {
+	stats, err := updateJSONObject(p.Stats, path, value)
+	if err != nil {
+		return err
+	}
+	p.Stats = stats
+	return nil
+}
+
+// DataMap decodes the progress JSON
+func (g *GameProgress) DataMap() (map[string]interface{}, error) {
+	return decodeJSONObject(g.Data)
+}
+
+// Value returns the progress value at path, e.g. "level" or "inventory.coins"
+func (g *GameProgress) Value(path string) (interface{}, bool) {
+	return lookupJSONObject(g.Data, path)
+}
+
+// SetValue sets the progress value at path; the change is not saved
+func (g *GameProgress) SetValue(path string, value interface{}) error {
+	data, err := updateJSONObject(g.Data, path, value)
+	if err != nil {
+		return err
+	}
+	g.Data = data
+	return nil
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// IndexedStatKeys are the top-level PlayerStats keys that get an index on
+// MySQL and Postgres, so range queries on them avoid full scans
+var IndexedStatKeys = []string{"score"}
+
+// JSONNumberExpr returns a SQL expression for the numeric value at segments in
+// a JSON column, NULL when the value is missing or not a number. The same
+// expression is used for the indexes, which lets the database match queries to
+// them. Segments must come from ParseJSONPath.
+func JSONNumberExpr(dialect, column string, segments []string) string {
+	switch dialect {
+	case "postgres":
+		path := postgresJSONPath(segments)
+		return fmt.Sprintf("(CASE WHEN json_typeof(%s #> %s) = 'number' THEN (%s #>> %s)::numeric END)", column, path, column, path)
+	case "mysql":
+		path := mysqlJSONPath(segments)
+		return fmt.Sprintf("(CASE WHEN JSON_TYPE(JSON_EXTRACT(%s, %s)) IN ('INTEGER', 'UNSIGNED INTEGER', 'DOUBLE', 'DECIMAL') THEN JSON_EXTRACT(%s, %s) + 0 END)", column, path, column, path)
+	case "sqlite":
+		path := mysqlJSONPath(segments)
+		return fmt.Sprintf("(CASE WHEN json_type(%s, %s) IN ('integer', 'real') THEN json_extract(%s, %s) END)", column, path, column, path)
+	}
+	return ""
+}
+
+// JSONTextExpr returns a SQL expression for the value at segments in a JSON
+// column as unquoted text
+func JSONTextExpr(dialect, column string, segments []string) string {
+	switch dialect {
+	case "postgres":
+		return fmt.Sprintf("(%s #>> %s)", column, postgresJSONPath(segments))
+	case "mysql":
+		return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, %s))", column, mysqlJSONPath(segments))
+	}
+	return ""
+}
+
+func postgresJSONPath(segments []string) string {
+	return "'{" + strings.Join(segments, ",") + "}'"
+}
+
+func mysqlJSONPath(segments []string) string {
+	return `'$."` + strings.Join(segments, `"."`) + `"'`
+}
+
+// createStatsIndexes indexes IndexedStatKeys per game. MySQL indexes a virtual
+// generated column, Postgres an expression; SQLite has neither and queries
+// filter in Go instead.
+func createStatsIndexes(db *gorm.DB) error {
+	dialect := db.Dialector.Name()
+
+	for _, key := range IndexedStatKeys {
+		segments, err := ParseJSONPath(key)
+		if err != nil {
+			return fmt.Errorf("indexed stat %q: %w", key, err)
+		}
+		expr := JSONNumberExpr(dialect, "stats", segments)
+		index := "idx_player_stats_" + key
+
+		switch dialect {
+		case "postgres":
+			err = db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON player_stats (game_id, %s)", index, expr)).Error
+		case "mysql":
+			column := "stats_" + key
+			if !db.Migrator().HasColumn(&PlayerStats{}, column) {
+				err = db.Exec(fmt.Sprintf("ALTER TABLE player_stats ADD COLUMN %s DOUBLE GENERATED ALWAYS AS %s VIRTUAL", column, expr)).Error
+			}
+			if err == nil && !db.Migrator().HasIndex(&PlayerStats{}, index) {
+				err = db.Exec(fmt.Sprintf("CREATE INDEX %s ON player_stats (game_id, %s)", index, column)).Error
+			}
+		default:
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("indexed stat %q: %w", key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidSchema is returned for a game schema that is not a JSON object or
+// uses a supported keyword with a value of the wrong kind
+var ErrInvalidSchema = errors.New("invalid JSON schema")
+
+// JSONSchema validates game data against a subset of JSON Schema: type,
+// enum, const, properties, required, additionalProperties, items, minItems,
+// maxItems, minLength, maxLength, pattern, minimum, maximum, exclusiveMinimum
+// and exclusiveMaximum. Other keywords are ignored.
+type JSONSchema struct {
+	Type                 []string               `json:"-"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Const                interface{}            `json:"const,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"-"` // nil allows any value
+	NoAdditional         bool                   `json:"-"` // additionalProperties: false
+	Items                *JSONSchema            `json:"items,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64               `json:"exclusiveMaximum,omitempty"`
+
+	hasConst bool
+	pattern  *regexp.Regexp
+}
+
+// SchemaViolation is one place where data does not match a schema
+type SchemaViolation struct {
+	Path    string `json:"path"` // e.g. "$.level.best"
+	Message string `json:"message"`
+}
+
+// SchemaValidationError lists every violation found in the data
+type SchemaValidationError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Path + ": " + violation.Message
+	}
+	return "data does not match schema: " + strings.Join(messages, "; ")
+}
+
+var schemaTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+// ParseJSONSchema parses and checks a schema. An empty string is no schema
+// and returns nil.
+func ParseJSONSchema(raw string) (*JSONSchema, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var schema JSONSchema
+	if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	return &schema, nil
+}
+
+// UnmarshalJSON reads the keywords whose value may take several forms and
+// checks the others
+func (s *JSONSchema) UnmarshalJSON(data []byte) error {
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return errors.New("schema must be an object")
+	}
+
+	// Decode the plain keywords through an alias without this method
+	type plain JSONSchema
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+
+	if raw, ok := keywords["type"]; ok {
+		var single string
+		if err := json.Unmarshal(raw, &single); err == nil {
+			s.Type = []string{single}
+		} else if err := json.Unmarshal(raw, &s.Type); err != nil {
+			return errors.New("type must be a string or an array of strings")
+		}
+		for _, name := range s.Type {
+			if !schemaTypes[name] {
+				return fmt.Errorf("unknown type %q", name)
+			}
+		}
+	}
+
+	if raw, ok := keywords["additionalProperties"]; ok {
+		var allowed bool
+		if err := json.Unmarshal(raw, &allowed); err == nil {
+			s.NoAdditional = !allowed
+		} else {
+			s.AdditionalProperties = &JSONSchema{}
+			if err := json.Unmarshal(raw, s.AdditionalProperties); err != nil {
+				return fmt.Errorf("additionalProperties: %w", err)
+			}
+		}
+	}
+
+	_, s.hasConst = keywords["const"]
+
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern: %w", err)
+		}
+		s.pattern = pattern
+	}
+	return nil
+}
+
+// Validate checks data, as decoded by encoding/json, and returns a
+// *SchemaValidationError listing all violations, or nil when it matches
+func (s *JSONSchema) Validate(data interface{}) error {
+	if s == nil {
+		return nil
+	}
+	var violations []SchemaViolation
+	s.validate("$", data, &violations)
+	if len(violations) > 0 {
+		return &SchemaValidationError{Violations: violations}
+	}
+	return nil
+}
+
+func (s *JSONSchema) validate(path string, value interface{}, violations *[]SchemaViolation) {
+	fail := func(format string, args ...interface{}) {
+		*violations = append(*violations, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.Type) > 0 && !matchesAnyType(value, s.Type) {
+		fail("expected %s, got %s", strings.Join(s.Type, " or "), jsonTypeOf(value))
+		return
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, allowed := range s.Enum {
+			if reflect.DeepEqual(value, allowed) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of the allowed values")
+		}
+	}
+	if s.hasConst && !reflect.DeepEqual(value, s.Const) {
+		fail("must equal the constant value")
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range s.Required {
+			if _, ok := v[key]; !ok {
+				*violations = append(*violations, SchemaViolation{Path: path + "." + key, Message: "is required"})
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if property, ok := s.Properties[key]; ok {
+				property.validate(path+"."+key, v[key], violations)
+			} else if s.NoAdditional {
+				*violations = append(*violations, SchemaViolation{Path: path + "." + key, Message: "is not allowed"})
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(path+"."+key, v[key], violations)
+			}
+		}
+
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("must have at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, violations)
+			}
+		}
+
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("must match pattern %s", s.Pattern)
+		}
+
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("must be at most %v", *s.Maximum)
+		}
+		if s.ExclusiveMinimum != nil && v <= *s.ExclusiveMinimum {
+			fail("must be greater than %v", *s.ExclusiveMinimum)
+		}
+		if s.ExclusiveMaximum != nil && v >= *s.ExclusiveMaximum {
+			fail("must be less than %v", *s.ExclusiveMaximum)
+		}
+	}
+}
+
+func matchesAnyType(value interface{}, types []string) bool {
+	actual := jsonTypeOf(value)
+	for _, expected := range types {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeOf names the JSON type of a decoded value; whole numbers are
+// integers
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const sampleSchema = `{
+	"type": "object",
+	"required": ["level", "lives"],
+	"additionalProperties": false,
+	"properties": {
+		"level": {"type": "integer", "minimum": 1, "maximum": 99},
+		"lives": {"type": "integer", "exclusiveMinimum": 0},
+		"name": {"type": "string", "minLength": 2, "maxLength": 12, "pattern": "^[a-z]+$"},
+		"mode": {"enum": ["easy", "hard"]},
+		"version": {"const": 2},
+		"items": {"type": "array", "maxItems": 2, "items": {"type": ["string", "null"]}},
+		"extra": {"type": "object", "additionalProperties": {"type": "number"}}
+	}
+}`
+
+func decode(t *testing.T, data string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestJSONSchemaAcceptsValidData(t *testing.T) {
+	schema, err := ParseJSONSchema(sampleSchema)
+	if err != nil {
+		t.Fatalf("ParseJSONSchema: %v", err)
+	}
+	for _, data := range []string{
+		`{"level": 1, "lives": 3}`,
+		`{"level": 99, "lives": 1, "name": "ada", "mode": "hard", "version": 2, "items": ["sword", null], "extra": {"x": 1.5}}`,
+	} {
+		if err := schema.Validate(decode(t, data)); err != nil {
+			t.Errorf("%s: %v", data, err)
+		}
+	}
+
+	// No schema accepts anything
+	none, err := ParseJSONSchema("  ")
+	if err != nil || none != nil {
+		t.Fatalf("empty schema = %v, %v; want no schema", none, err)
+	}
+	if err := none.Validate(decode(t, `[1, "two"]`)); err != nil {
+		t.Errorf("no schema rejected data: %v", err)
+	}
+}
+
+func TestJSONSchemaReportsEveryViolation(t *testing.T) {
+	schema, err := ParseJSONSchema(sampleSchema)
+	if err != nil {
+		t.Fatalf("ParseJSONSchema: %v", err)
+	}
+
+	data := `{"level": 1.5, "lives": 0, "name": "A", "mode": "nightmare", "version": 3, "items": ["a", 1, "c"], "extra": {"x": "y"}, "cheat": true}`
+	err = schema.Validate(decode(t, data))
+	var invalid *SchemaValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Validate = %v, want a SchemaValidationError", err)
+	}
+
+	got := make(map[string][]string)
+	for _, violation := range invalid.Violations {
+		got[violation.Path] = append(got[violation.Path], violation.Message)
+	}
+	want := map[string][]string{
+		"$.cheat":    {"is not allowed"},
+		"$.extra.x":  {"expected number, got string"},
+		"$.items":    {"must have at most 2 items"},
+		"$.items[1]": {"expected string or null, got integer"},
+		"$.level":    {"expected integer, got number"},
+		"$.lives":    {"must be greater than 0"},
+		"$.mode":     {"must be one of the allowed values"},
+		"$.name":     {"must be at least 2 characters", "must match pattern ^[a-z]+$"},
+		"$.version":  {"must equal the constant value"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violations:\n got %v\nwant %v", got, want)
+	}
+
+	err = schema.Validate(decode(t, `{"level": 100}`))
+	if !errors.As(err, &invalid) || len(invalid.Violations) != 2 {
+		t.Errorf("Validate = %v, want the range and the missing lives", err)
+	}
+}
+
+func TestParseJSONSchemaRejectsInvalidSchemas(t *testing.T) {
+	for _, raw := range []string{
+		`[]`,
+		`{"type": "decimal"}`,
+		`{"type": 3}`,
+		`{"pattern": "("}`,
+		`{"properties": {"name": {"minLength": "two"}}}`,
+		`{"additionalProperties": 1}`,
+	} {
+		if _, err := ParseJSONSchema(raw); !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("ParseJSONSchema(%s) = %v, want ErrInvalidSchema", raw, err)
+		}
+	}
+}
@@ -6,22 +6,33 @@ import (
 	"gorm.io/gorm"
 )
 
-// AutoMigrate runs all model migrations
-func AutoMigrate(db *gorm.DB) error {
-	log.Println("Running game models migrations...")
-
-	// Migrate all game-related models
-	if err := db.AutoMigrate(
+// Models returns every game-related model
+func Models() []any {
+	return []any{
 		&Game{},
 		&Achievement{},
 		&UserAchievement{},
 		&GameProgress{},
 		&PlayerStats{},
-	); err != nil {
+		&PlayerStatsSnapshot{},
+	}
+}
+
+// AutoMigrate runs all model migrations
+func AutoMigrate(db *gorm.DB) error {
+	log.Println("Running game models migrations...")
+
+	// Migrate all game-related models
+	if err := db.AutoMigrate(Models()...); err != nil {
 		log.Printf("Failed to migrate game models: %v", err)
 		return err
 	}
 
+	// Stats indexes only speed up queries, so a failure is not fatal
+	if err := createStatsIndexes(db); err != nil {
+		log.Printf("Failed to create player stats indexes: %v", err)
+	}
+
 	log.Println("Game models migrated successfully")
 	return nil
 }
@@ -7,12 +7,12 @@ import (
 	"gorm.io/gorm"
 )
 
-// PlayerStats stores player statistics per game
+// PlayerStats stores player statistics per game, one row per user and game
 type PlayerStats struct {
 	Id        uint           `gorm:"column:id;primary_key;auto_increment" json:"id"`
-	UserId    uint           `gorm:"column:user_id;not null;index" json:"user_id" validate:"required"`
+	UserId    uint           `gorm:"column:user_id;not null;uniqueIndex:idx_player_stats_user_game" json:"user_id" validate:"required"`
 	User      *profile.User  `json:"user,omitempty" gorm:"foreignKey:UserId"`
-	GameId    uint           `gorm:"column:game_id;not null;index" json:"game_id" validate:"required"`
+	GameId    uint           `gorm:"column:game_id;not null;index;uniqueIndex:idx_player_stats_user_game" json:"game_id" validate:"required"`
 	Game      *Game          `json:"game,omitempty" gorm:"foreignKey:GameId"`
 	Stats     string         `gorm:"column:stats;type:json" json:"stats"` // JSON for scores, playtime, wins, etc.
 	CreatedAt time.Time      `gorm:"column:created_at" json:"created_at"`
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// PlayerStatsSnapshot stores a player's stats as they were when a leaderboard
+// period began, so periodic leaderboards can report the scores achieved within
+// the period rather than all-time totals
+type PlayerStatsSnapshot struct {
+	Id          uint      `gorm:"column:id;primary_key;auto_increment" json:"id"`
+	UserId      uint      `gorm:"column:user_id;not null;uniqueIndex:idx_player_stats_snapshot" json:"user_id"`
+	GameId      uint      `gorm:"column:game_id;not null;uniqueIndex:idx_player_stats_snapshot" json:"game_id"`
+	Period      string    `gorm:"column:period;not null;size:16;uniqueIndex:idx_player_stats_snapshot" json:"period"`
+	PeriodStart time.Time `gorm:"column:period_start;not null;uniqueIndex:idx_player_stats_snapshot" json:"period_start"`
+	Stats       string    `gorm:"column:stats;type:json" json:"stats"` // Stats at the start of the period
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (PlayerStatsSnapshot) TableName() string {
+	return "player_stats_snapshots"
+}
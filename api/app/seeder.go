@@ -23,6 +23,7 @@ func SeedGamesData(db *gorm.DB) error {
 		Title:       "Multiplex",
 		Description: "A challenging puzzle game where you manage multiple tasks simultaneously",
 		Icon:        "/static/icons/multiplex.png",
+		Metadata:    "{}",
 		Active:      true,
 	}
 
@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"base/core/app/authorization"
+	"base/core/config"
+	"base/core/logger"
+	"base/core/router"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func TestConfigDumpIsOwnerOnlyAndMasked(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	// The columns of the users table the role guard reads
+	if err := db.Exec("CREATE TABLE users (id integer PRIMARY KEY, role_id integer, deleted_at datetime)").Error; err != nil {
+		t.Fatal(err)
+	}
+	log := logger.NewLoggerFromZap(zap.NewNop())
+	if err := authorization.NewAuthorizationModule(db, nil, log, nil).(*authorization.AuthorizationModule).Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	userWithRole := func(name string) string {
+		var role authorization.Role
+		if err := db.Where("name = ?", name).First(&role).Error; err != nil {
+			t.Fatal(err)
+		}
+		var id string
+		db.Raw("INSERT INTO users (role_id) VALUES (?) RETURNING id", role.Id).Scan(&id)
+		return id
+	}
+
+	cfg := &config.Config{JWTSecret: "jwt-secret-value", DBHost: "db.internal"}
+	controller := NewAdminController(NewAdminService(db, cfg, log), authorization.NewAuthorizationService(db), log)
+	r := router.New()
+	api := r.Group("/api")
+	api.Use(func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			c.Set("user_id", c.Request.Header.Get("X-User-Id"))
+			return next(c)
+		}
+	})
+	controller.Routes(api)
+	get := func(userId string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/config", nil)
+		req.Header.Set("X-User-Id", userId)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get(userWithRole("Owner"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("owner: status %d: %s", w.Code, w.Body)
+	}
+	if strings.Contains(w.Body.String(), "jwt-secret-value") {
+		t.Errorf("the JWT secret is in the dump: %s", w.Body)
+	}
+	if !strings.Contains(w.Body.String(), `"JWTSecret":"`+logger.Redacted+`"`) || !strings.Contains(w.Body.String(), "db.internal") {
+		t.Errorf("dump %s, want the masked secret and the plain host", w.Body)
+	}
+
+	for _, role := range []string{"Administrator", "Member"} {
+		if w := get(userWithRole(role)); w.Code != http.StatusForbidden {
+			t.Errorf("%s: status %d, want 403", role, w.Code)
+		}
+	}
+}
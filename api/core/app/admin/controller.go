@@ -0,0 +1,253 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"base/core/app/authorization"
+	"base/core/emitter"
+	"base/core/logger"
+	"base/core/router"
+	"base/core/types"
+)
+
+// AdminController handles runtime administration endpoints
+type AdminController struct {
+	service      *AdminService
+	authzService *authorization.AuthorizationService
+	logger       logger.Logger
+}
+
+// NewAdminController creates a new admin controller
+func NewAdminController(service *AdminService, authzService *authorization.AuthorizationService, logger logger.Logger) *AdminController {
+	return &AdminController{
+		service:      service,
+		authzService: authzService,
+		logger:       logger,
+	}
+}
+
+// Routes registers the admin routes, restricted to Owner and Administrator roles.
+// Stopping an impersonation is sent with the impersonation token, whose user
+// need not be an admin, so it is registered outside the role check.
+func (c *AdminController) Routes(router *router.RouterGroup) {
+	router.POST("/admin/impersonate/stop", c.StopImpersonation)
+
+	adminRoutes := router.Group("/admin", authorization.RequireAnyRole(c.authzService, "Owner", "Administrator"))
+	adminRoutes.GET("/config", c.GetConfig, authorization.RequireAnyRole(c.authzService, "Owner"))
+	adminRoutes.POST("/config/reload", c.ReloadConfig)
+	adminRoutes.GET("/migrations/status", c.MigrationStatus)
+	adminRoutes.GET("/events", c.Events)
+	adminRoutes.POST("/impersonate/:user_id", c.Impersonate)
+}
+
+// GetConfig godoc
+// @Summary Get the effective configuration
+// @Description Returns the configuration the server runs with, including the middleware settings as currently applied,
+// @Description to debug which value an environment variable resolved to. Secret-like values are masked. Owner only.
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Admin
+// @Produce json
+// @Success 200 {object} types.SuccessResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 403 {object} types.ErrorResponse
+// @Router /admin/config [get]
+func (c *AdminController) GetConfig(ctx *router.Context) error {
+	return ctx.JSON(http.StatusOK, types.SuccessResponse{
+		Message: "Effective configuration",
+		Success: true,
+		Data:    c.service.ConfigDump(),
+	})
+}
+
+// ReloadConfig godoc
+// @Summary Reload middleware configuration
+// @Description Re-reads the middleware environment variables (and .env if present) and applies them without a restart.
+// @Description Hot-reloadable: API key, auth, rate limit and logging settings, webhook settings and per-endpoint overrides.
+// @Description Recovery and CORS toggles still require a restart.
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Admin
+// @Produce json
+// @Success 200 {object} types.SuccessResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 403 {object} types.ErrorResponse
+// @Failure 422 {object} types.ErrorResponse
+// @Router /admin/config/reload [post]
+func (c *AdminController) ReloadConfig(ctx *router.Context) error {
+	snapshot, err := c.service.ReloadConfig()
+	if err != nil {
+		return ctx.JSON(http.StatusUnprocessableEntity, types.ErrorResponse{
+			Error: "Configuration not reloaded: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, types.SuccessResponse{
+		Message: "Configuration reloaded",
+		Success: true,
+		Data:    snapshot,
+	})
+}
+
+// MigrationStatus godoc
+// @Summary Get migration status
+// @Description Lists each module's tables with whether they exist, the latest migration run and the most recent runs.
+// @Description Runs are recorded at startup and by the migrate command.
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Admin
+// @Produce json
+// @Success 200 {object} types.SuccessResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 403 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /admin/migrations/status [get]
+func (c *AdminController) MigrationStatus(ctx *router.Context) error {
+	status, err := c.service.MigrationStatus()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error: "Failed to load migration status",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, types.SuccessResponse{
+		Message: "Migration status",
+		Success: true,
+		Data:    status,
+	})
+}
+
+// Events godoc
+// @Summary List outbox events
+// @Description Lists events stored in the outbox, newest first, with their delivery status, attempts and last error.
+// @Description Only events registered for persistence are stored.
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Admin
+// @Produce json
+// @Param action query string false "Filter by event name, e.g. user.registered"
+// @Param name query string false "Alias of action"
+// @Param from query string false "Only events created at or after this RFC3339 time"
+// @Param to query string false "Only events created before this RFC3339 time"
+// @Param status query string false "Filter by status: pending, delivered or failed"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page, capped at MAX_PAGE_SIZE"
+// @Success 200 {object} types.PaginatedResponse
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 403 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /admin/events [get]
+func (c *AdminController) Events(ctx *router.Context) error {
+	audit, err := ctx.AuditFilters()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: err.Error()})
+	}
+	// name is the parameter the list had before it shared the audit filters
+	if audit.Action == "" {
+		audit.Action = ctx.Query("name")
+	}
+
+	filter := EventFilter{
+		AuditFilters: *audit,
+		Status:       ctx.Query("status"),
+	}
+
+	switch filter.Status {
+	case "", emitter.EventPending, emitter.EventDelivered, emitter.EventFailed:
+	default:
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error: "Invalid status; use pending, delivered or failed",
+		})
+	}
+
+	events, err := c.service.Events(filter)
+	if err != nil {
+		if errors.Is(err, router.ErrInvalidQuery) {
+			return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error: "Failed to load events",
+		})
+	}
+	events.AddLinks(ctx.Request.URL)
+
+	return ctx.JSON(http.StatusOK, events)
+}
+
+// Impersonate godoc
+// @Summary Impersonate a user
+// @Description Issues a short-lived token (IMPERSONATION_TTL, 15m by default) that acts as the user, for support.
+// @Description The token carries an impersonated_by claim; requests made with it are logged with impersonated_by.
+// @Description It cannot be refreshed, cannot start another impersonation, and only owners may impersonate owners.
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Admin
+// @Produce json
+// @Param user_id path int true "User to impersonate"
+// @Success 200 {object} ImpersonationResponse
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 403 {object} types.ErrorResponse
+// @Failure 404 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /admin/impersonate/{user_id} [post]
+func (c *AdminController) Impersonate(ctx *router.Context) error {
+	userId, err := strconv.ParseUint(ctx.Param("user_id"), 10, 32)
+	if err != nil || userId == 0 {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid user Id"})
+	}
+
+	response, err := c.service.Impersonate(ctx.GetUint("user_id"), uint(userId), ctx.GetUint("impersonated_by"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{Error: "User not found"})
+		case errors.Is(err, ErrImpersonateSelf):
+			return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrNestedImpersonation), errors.Is(err, ErrImpersonateOwner):
+			return ctx.JSON(http.StatusForbidden, types.ErrorResponse{Error: err.Error()})
+		}
+		c.logger.Error("Failed to start impersonation", logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to start impersonation"})
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// StopImpersonation godoc
+// @Summary Stop impersonating a user
+// @Description Revokes the impersonation token the request is made with. The admin's own token stays valid,
+// @Description so the client returns to the original identity by using it again.
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Admin
+// @Produce json
+// @Success 200 {object} object{success=boolean,user_id=int} "user_id is the admin who started the impersonation"
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /admin/impersonate/stop [post]
+func (c *AdminController) StopImpersonation(ctx *router.Context) error {
+	token, found := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if !found || token == "" {
+		return ctx.JSON(http.StatusUnauthorized, types.ErrorResponse{Error: "Missing bearer token"})
+	}
+
+	adminId, err := c.service.StopImpersonation(token)
+	if err != nil {
+		if errors.Is(err, ErrNotImpersonating) {
+			return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Not an impersonation token"})
+		}
+		c.logger.Error("Failed to stop impersonation", logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to stop impersonation"})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"success": true,
+		"user_id": adminId,
+	})
+}
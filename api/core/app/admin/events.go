@@ -0,0 +1,64 @@
+package admin
+
+import (
+	"fmt"
+	"math"
+
+	"base/core/emitter"
+	"base/core/logger"
+	"base/core/router"
+	"base/core/types"
+)
+
+// EventFilter narrows the events listed by Events. The audit action is the
+// event name; events have no actor.
+type EventFilter struct {
+	router.AuditFilters
+	Status string
+}
+
+// eventColumns are the outbox columns the audit filters apply to
+var eventColumns = router.AuditColumns{Time: "created_at", Action: "name"}
+
+// Events lists events stored in the outbox, newest first
+func (s *AdminService) Events(filter EventFilter) (*types.PaginatedResponse, error) {
+	page, pageSize := types.NormalizePagination(filter.Page, filter.Limit)
+
+	events := []emitter.Event{}
+	var total int64
+
+	// The table only exists once the outbox was enabled
+	if s.db.Migrator().HasTable(&emitter.Event{}) {
+		query, err := filter.Apply(s.db.Model(&emitter.Event{}), eventColumns)
+		if err != nil {
+			return nil, err
+		}
+		if filter.Status != "" {
+			query = query.Where("status = ?", filter.Status)
+		}
+
+		if err := query.Count(&total).Error; err != nil {
+			s.logger.Error("Failed to count events", logger.String("error", err.Error()))
+			return nil, fmt.Errorf("failed to count events: %w", err)
+		}
+		if err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&events).Error; err != nil {
+			s.logger.Error("Failed to load events", logger.String("error", err.Error()))
+			return nil, fmt.Errorf("failed to load events: %w", err)
+		}
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	return &types.PaginatedResponse{
+		Data: events,
+		Pagination: types.Pagination{
+			Total:      int(total),
+			Page:       page,
+			PageSize:   pageSize,
+			TotalPages: totalPages,
+		},
+	}, nil
+}
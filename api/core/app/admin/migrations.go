@@ -0,0 +1,95 @@
+package admin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"base/core/logger"
+	"base/core/module"
+)
+
+// recentMigrationRuns is how many runs MigrationStatus returns
+const recentMigrationRuns = 20
+
+// MigrationTable reports whether a migrated table exists
+type MigrationTable struct {
+	Name   string `json:"name"`
+	Exists bool   `json:"exists"`
+}
+
+// ModuleMigrationStatus is the latest migration run of a module
+type ModuleMigrationStatus struct {
+	Module     string           `json:"module"`
+	Tables     []MigrationTable `json:"tables"`
+	Source     string           `json:"source"`
+	Success    bool             `json:"success"`
+	Error      string           `json:"error,omitempty"`
+	DurationMs int64            `json:"duration_ms"`
+	LastRunAt  time.Time        `json:"last_run_at"`
+	Runs       int64            `json:"runs"`
+}
+
+// MigrationStatus is the response of the migration status endpoint
+type MigrationStatus struct {
+	Modules    []ModuleMigrationStatus `json:"modules"`
+	RecentRuns []module.MigrationRun   `json:"recent_runs"`
+}
+
+// MigrationStatus reports the latest migration run of every module, whether
+// its tables exist, and the most recent runs
+func (s *AdminService) MigrationStatus() (*MigrationStatus, error) {
+	var latest []module.MigrationRun
+	err := s.db.Where("id IN (?)", s.db.Model(&module.MigrationRun{}).Select("MAX(id)").Group("module")).
+		Find(&latest).Error
+	if err != nil {
+		s.logger.Error("Failed to load migration runs", logger.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to load migration runs: %w", err)
+	}
+
+	var counts []struct {
+		Module string
+		Runs   int64
+	}
+	err = s.db.Model(&module.MigrationRun{}).Select("module, COUNT(*) AS runs").Group("module").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count migration runs: %w", err)
+	}
+	runs := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		runs[count.Module] = count.Runs
+	}
+
+	status := &MigrationStatus{Modules: make([]ModuleMigrationStatus, 0, len(latest))}
+	migrator := s.db.Migrator()
+	for _, run := range latest {
+		tables := []MigrationTable{}
+		if run.Tables != "" {
+			for _, name := range strings.Split(run.Tables, ",") {
+				tables = append(tables, MigrationTable{Name: name, Exists: migrator.HasTable(name)})
+			}
+		}
+		status.Modules = append(status.Modules, ModuleMigrationStatus{
+			Module:     run.Module,
+			Tables:     tables,
+			Source:     run.Source,
+			Success:    run.Success,
+			Error:      run.Error,
+			DurationMs: run.DurationMs,
+			LastRunAt:  run.RanAt,
+			Runs:       runs[run.Module],
+		})
+	}
+	sort.Slice(status.Modules, func(i, j int) bool {
+		return status.Modules[i].Module < status.Modules[j].Module
+	})
+
+	err = s.db.Order("id DESC").Limit(recentMigrationRuns).Find(&status.RecentRuns).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recent migration runs: %w", err)
+	}
+
+	return status, nil
+}
@@ -0,0 +1,33 @@
+package admin
+
+import (
+	"base/core/app/authorization"
+	"base/core/config"
+	"base/core/logger"
+	"base/core/module"
+	"base/core/router"
+
+	"gorm.io/gorm"
+)
+
+type AdminModule struct {
+	module.DefaultModule
+	Controller *AdminController
+	Service    *AdminService
+	Logger     logger.Logger
+}
+
+func NewAdminModule(db *gorm.DB, router *router.RouterGroup, logger logger.Logger, cfg *config.Config) module.Module {
+	service := NewAdminService(db, cfg, logger)
+	controller := NewAdminController(service, authorization.NewAuthorizationService(db), logger)
+
+	return &AdminModule{
+		Controller: controller,
+		Service:    service,
+		Logger:     logger,
+	}
+}
+
+func (m *AdminModule) Routes(router *router.RouterGroup) {
+	m.Controller.Routes(router)
+}
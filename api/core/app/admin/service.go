@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"os"
+
+	"base/core/config"
+	"base/core/logger"
+
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+)
+
+// AdminService performs runtime administration tasks
+type AdminService struct {
+	db     *gorm.DB
+	config *config.Config
+	logger logger.Logger
+}
+
+// NewAdminService creates a new admin service
+func NewAdminService(db *gorm.DB, cfg *config.Config, logger logger.Logger) *AdminService {
+	return &AdminService{
+		db:     db,
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// ConfigDump returns the effective configuration with secrets masked
+func (s *AdminService) ConfigDump() map[string]any {
+	return s.config.Dump()
+}
+
+// ReloadConfig re-reads the .env file (when present) and applies the
+// middleware settings to the running server
+func (s *AdminService) ReloadConfig() (*config.MiddlewareConfig, error) {
+	if _, err := os.Stat(".env"); err == nil {
+		if err := godotenv.Overload(); err != nil {
+			s.logger.Error("Failed to read .env file", logger.String("error", err.Error()))
+			return nil, err
+		}
+	}
+
+	if err := s.config.Reload(); err != nil {
+		s.logger.Error("Failed to reload configuration", logger.String("error", err.Error()))
+		return nil, err
+	}
+
+	snapshot := s.config.MiddlewareSnapshot()
+	s.logger.Info("Middleware configuration reloaded",
+		logger.Bool("api_key_enabled", snapshot.APIKeyEnabled),
+		logger.Bool("auth_enabled", snapshot.AuthEnabled),
+		logger.Bool("rate_limit_enabled", snapshot.RateLimitEnabled),
+		logger.Int("overrides", len(snapshot.Overrides)))
+
+	return &snapshot, nil
+}
@@ -0,0 +1,78 @@
+package authentication
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"base/core/app/profile"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// DeleteAccount deletes the user's own account after checking their password.
+// In one transaction it revokes every active session, runs the registered
+// profile deletion hooks so modules remove or anonymize the user's data,
+// erases the user's personal details and soft-deletes the user. The erased
+// email and username can be registered again.
+func (s *AuthService) DeleteAccount(userId uint, password string) error {
+	var user AuthUser
+	if err := s.db.First(&user, userId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return ErrInvalidPassword
+	}
+
+	now := time.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var sessions []Session
+		err := tx.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userId, now).
+			Find(&sessions).Error
+		if err != nil {
+			return fmt.Errorf("failed to load sessions: %w", err)
+		}
+		for _, session := range sessions {
+			if err := revokeToken(tx, session.TokenId, session.ExpiresAt, now); err != nil {
+				return err
+			}
+		}
+
+		if err := profile.RunDeletionHooks(tx, userId, s.deletionMode); err != nil {
+			return fmt.Errorf("failed to delete account data: %w", err)
+		}
+
+		placeholder := fmt.Sprintf("deleted-%d", userId)
+		err = tx.Model(&user).Updates(map[string]any{
+			"first_name":         "",
+			"last_name":          "",
+			"username":           placeholder,
+			"email":              placeholder + "@deleted.invalid",
+			"phone":              nil,
+			"language":           "",
+			"avatar":             nil,
+			"password":           "",
+			"reset_token":        "",
+			"reset_token_expiry": nil,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to erase user: %w", err)
+		}
+
+		if err := tx.Delete(&user).Error; err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.emitter.EmitAsync("user.deleted", userId)
+	return nil
+}
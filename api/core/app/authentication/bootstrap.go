@@ -0,0 +1,57 @@
+package authentication
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"base/core/app/profile"
+)
+
+// ErrBootstrapCredentials is returned when the bootstrap admin has no email
+// or a password shorter than registration allows
+var ErrBootstrapCredentials = errors.New("bootstrap admin needs an email and a password of at least 8 characters")
+
+// BootstrapAdmin creates an Owner with the given credentials when the users
+// table is empty, including soft-deleted users, and reports whether it did.
+// Once any user exists it does nothing, so it is safe to run on every start
+// and never touches an existing account.
+func (s *AuthService) BootstrapAdmin(email, password string) (bool, error) {
+	email = strings.TrimSpace(email)
+	if email == "" || len(password) < 8 {
+		return false, ErrBootstrapCredentials
+	}
+
+	var userCount int64
+	if err := s.db.Unscoped().Model(&AuthUser{}).Count(&userCount).Error; err != nil {
+		return false, fmt.Errorf("failed to count users: %w", err)
+	}
+	if userCount > 0 {
+		return false, nil
+	}
+
+	roleId, err := s.roleIdByName(OwnerRole)
+	if err != nil {
+		return false, fmt.Errorf("%s role is required to bootstrap the admin: %w", OwnerRole, err)
+	}
+
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return false, err
+	}
+
+	username, _, _ := strings.Cut(email, "@")
+	user := AuthUser{
+		User: profile.User{
+			Email:     email,
+			Password:  hashedPassword,
+			FirstName: "Admin",
+			Username:  username,
+			RoleId:    roleId,
+		},
+	}
+	if err := s.db.Create(&user).Error; err != nil {
+		return false, fmt.Errorf("failed to create admin: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,76 @@
+package authentication
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBootstrapAdminRunsOnce(t *testing.T) {
+	service := newTestService(t)
+	roles := withRoles(t, service)
+
+	created, err := service.BootstrapAdmin(" admin@example.com ", "bootstrap-pass")
+	if err != nil || !created {
+		t.Fatalf("first run: created %v, %v", created, err)
+	}
+	var admin AuthUser
+	if err := service.db.Where("email = ?", "admin@example.com").First(&admin).Error; err != nil {
+		t.Fatalf("admin not stored: %v", err)
+	}
+	if admin.RoleId != roles[OwnerRole] || admin.Username != "admin" {
+		t.Errorf("admin role %d username %q, want the Owner role and admin", admin.RoleId, admin.Username)
+	}
+	// The password is hashed and works with Login
+	if admin.Password == "bootstrap-pass" {
+		t.Error("the password is stored in plain text")
+	}
+	if _, err := service.Login(&LoginRequest{Email: "admin@example.com", Password: "bootstrap-pass"}, ClientInfo{}); err != nil {
+		t.Errorf("login as the bootstrap admin: %v", err)
+	}
+
+	// A rerun, even with other credentials, leaves the users alone
+	created, err = service.BootstrapAdmin("other@example.com", "another-pass")
+	if err != nil || created {
+		t.Errorf("rerun: created %v, %v", created, err)
+	}
+	var count int64
+	service.db.Model(&AuthUser{}).Count(&count)
+	if count != 1 {
+		t.Errorf("%d users after the rerun, want 1", count)
+	}
+	if _, err := service.Login(&LoginRequest{Email: "admin@example.com", Password: "another-pass"}, ClientInfo{}); err == nil {
+		t.Error("the rerun changed the admin's password")
+	}
+}
+
+func TestBootstrapAdminSkipsExistingUsers(t *testing.T) {
+	service := newTestService(t)
+	withRoles(t, service)
+	if _, err := register(t, service, "member", ""); err != nil {
+		t.Fatal(err)
+	}
+	// Soft-deleted users count as existing as well
+	service.db.Where("username = ?", "member").Delete(&AuthUser{})
+
+	if created, err := service.BootstrapAdmin("admin@example.com", "bootstrap-pass"); err != nil || created {
+		t.Errorf("created %v, %v; want nothing with a user present", created, err)
+	}
+}
+
+func TestBootstrapAdminValidation(t *testing.T) {
+	service := newTestService(t)
+	withRoles(t, service)
+
+	for _, credentials := range [][2]string{{"", "bootstrap-pass"}, {"  ", "bootstrap-pass"}, {"admin@example.com", "short"}} {
+		if _, err := service.BootstrapAdmin(credentials[0], credentials[1]); !errors.Is(err, ErrBootstrapCredentials) {
+			t.Errorf("%q: got %v, want ErrBootstrapCredentials", credentials, err)
+		}
+	}
+
+	// Without an Owner role there is nobody to bootstrap
+	empty := newTestService(t)
+	seedRoles(t, empty, "Member")
+	if created, err := empty.BootstrapAdmin("admin@example.com", "bootstrap-pass"); err == nil || created {
+		t.Errorf("no Owner role: created %v, %v", created, err)
+	}
+}
@@ -0,0 +1,148 @@
+package authentication
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"base/core/logger"
+
+	"gorm.io/gorm"
+)
+
+// tokenCleanupTimeout bounds a single cleanup cycle, so a slow database
+// cannot hold up shutdown for long
+const tokenCleanupTimeout = 30 * time.Second
+
+// CleanupResult counts the rows a cleanup cycle changed
+type CleanupResult struct {
+	ResetTokens   int64 // Users whose expired reset token was cleared
+	Sessions      int64 // Expired sessions deleted
+	RevokedTokens int64 // Denylist entries of expired tokens deleted
+}
+
+// Total is the number of rows changed
+func (r CleanupResult) Total() int64 {
+	return r.ResetTokens + r.Sessions + r.RevokedTokens
+}
+
+// TokenCleaner periodically clears expired password reset tokens and deletes
+// expired sessions and revoked token entries. None of them can be used once
+// expired, so only their rows are left to remove.
+type TokenCleaner struct {
+	db       *gorm.DB
+	logger   logger.Logger
+	interval time.Duration
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	stopped chan struct{}
+}
+
+// NewTokenCleaner creates a cleaner running every interval once started
+func NewTokenCleaner(db *gorm.DB, log logger.Logger, interval time.Duration) *TokenCleaner {
+	return &TokenCleaner{
+		db:       db,
+		logger:   log,
+		interval: interval,
+	}
+}
+
+// Start runs the cleanup in the background until Stop is called. It does
+// nothing when already running or the interval is not positive.
+func (c *TokenCleaner) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancel != nil || c.interval <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.stopped = make(chan struct{})
+	go c.run(ctx, c.stopped)
+}
+
+// Stop cancels the cycle in progress and waits for it to return
+func (c *TokenCleaner) Stop() {
+	c.mu.Lock()
+	cancel, stopped := c.cancel, c.stopped
+	c.cancel = nil
+	c.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	<-stopped
+}
+
+func (c *TokenCleaner) run(ctx context.Context, stopped chan<- struct{}) {
+	defer close(stopped)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.cleanOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *TokenCleaner) cleanOnce(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, tokenCleanupTimeout)
+	defer cancel()
+
+	result, err := c.Clean(ctx)
+	if err != nil {
+		if ctx.Err() != nil && result.Total() == 0 {
+			return // Stopped or timed out before anything was done
+		}
+		c.logger.Error("Failed to clean expired tokens", logger.String("error", err.Error()))
+	}
+	if result.Total() > 0 {
+		c.logger.Info("Cleaned expired tokens",
+			logger.Int64("reset_tokens", result.ResetTokens),
+			logger.Int64("sessions", result.Sessions),
+			logger.Int64("revoked_tokens", result.RevokedTokens))
+	}
+}
+
+// Clean removes everything that expired before now and reports how many rows
+// each step changed, including those done before a failing step
+func (c *TokenCleaner) Clean(ctx context.Context) (CleanupResult, error) {
+	var result CleanupResult
+	db := c.db.WithContext(ctx)
+	now := time.Now()
+
+	update := db.Model(&AuthUser{}).
+		Where("reset_token_expiry <= ?", now).
+		Updates(map[string]any{
+			"reset_token":        "",
+			"reset_token_expiry": nil,
+			"reset_attempts":     0,
+		})
+	if update.Error != nil {
+		return result, fmt.Errorf("failed to clear reset tokens: %w", update.Error)
+	}
+	result.ResetTokens = update.RowsAffected
+
+	sessions := db.Where("expires_at <= ?", now).Delete(&Session{})
+	if sessions.Error != nil {
+		return result, fmt.Errorf("failed to delete expired sessions: %w", sessions.Error)
+	}
+	result.Sessions = sessions.RowsAffected
+
+	revoked := db.Where("expires_at <= ?", now).Delete(&RevokedToken{})
+	if revoked.Error != nil {
+		return result, fmt.Errorf("failed to prune revoked tokens: %w", revoked.Error)
+	}
+	result.RevokedTokens = revoked.RowsAffected
+
+	return result, nil
+}
@@ -1,27 +1,33 @@
 package authentication
 
 import (
+	"base/core/app/authorization"
 	"base/core/email"
 	"base/core/logger"
 	"base/core/router"
+	"base/core/router/middleware"
+	"base/core/types"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"go.uber.org/zap"
 )
 
 type AuthController struct {
-	service     *AuthService
-	emailSender email.Sender
-	logger      logger.Logger
+	service      *AuthService
+	emailSender  email.Sender
+	logger       logger.Logger
+	authzService *authorization.AuthorizationService
 }
 
-func NewAuthController(service *AuthService, emailSender email.Sender, logger logger.Logger) *AuthController {
+func NewAuthController(service *AuthService, emailSender email.Sender, logger logger.Logger, authzService *authorization.AuthorizationService) *AuthController {
 	return &AuthController{
-		service:     service,
-		emailSender: emailSender,
-		logger:      logger,
+		service:      service,
+		emailSender:  emailSender,
+		logger:       logger,
+		authzService: authzService,
 	}
 }
 
@@ -29,8 +35,21 @@ func (c *AuthController) Routes(router *router.RouterGroup) {
 	router.POST("/register", c.Register)
 	router.POST("/login", c.Login)
 	router.POST("/logout", c.Logout)
-	router.POST("/forgot-password", c.ForgotPassword)
-	router.POST("/reset-password", c.ResetPassword)
+	router.POST("/forgot-password", c.ForgotPassword, c.resetRateLimit()...)
+	router.POST("/reset-password", c.ResetPassword, c.resetRateLimit()...)
+	router.GET("/sessions", c.ListSessions)
+	router.DELETE("/sessions/:id", c.RevokeSession)
+	router.DELETE("/account", c.DeleteAccount)
+	router.POST("/invites", c.CreateInvite, authorization.RequireAnyRole(c.authzService, "Owner", "Administrator"))
+}
+
+// resetRateLimit limits how often a client may use a reset endpoint when
+// resets use short codes, which are easier to guess than tokens
+func (c *AuthController) resetRateLimit() []router.MiddlewareFunc {
+	if !c.service.resetTokens.IsOTP() {
+		return nil
+	}
+	return []router.MiddlewareFunc{middleware.PerEndpointRateLimit(otpRateLimit, otpRateWindow)}
 }
 
 // @Summary Register
@@ -41,7 +60,8 @@ func (c *AuthController) Routes(router *router.RouterGroup) {
 // @Produce json
 // @Param body body RegisterRequest true "Register Request"
 // @Success 201 {object} AuthResponse
-// @Failure 400 {object} ErrorResponse
+// @Failure 400 {object} ErrorResponse "Invalid request, or an invalid, expired or used invite"
+// @Failure 403 {object} ErrorResponse "Registration requires an invite"
 // @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /auth/register [post]
@@ -51,10 +71,10 @@ func (c *AuthController) Register(ctx *router.Context) error {
 		// Log why the request was invalid
 		c.logger.Error("Invalid register request",
 			logger.String("error", err.Error()))
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, err.Error()))
 	}
 
-	user, err := c.service.Register(&req)
+	user, err := c.service.Register(&req, clientInfo(ctx))
 	if err != nil {
 		// Log the underlying service error to help debug 500s
 		c.logger.Error("Failed to register user",
@@ -63,6 +83,10 @@ func (c *AuthController) Register(ctx *router.Context) error {
 		// Provide a better status for common cases
 		if strings.Contains(strings.ToLower(err.Error()), "user already exists") {
 			status = http.StatusConflict // 409
+		} else if errors.Is(err, ErrRegistrationClosed) {
+			status = http.StatusForbidden
+		} else if isInviteError(err) {
+			status = http.StatusBadRequest
 		}
 		return ctx.JSON(status, ErrorResponse{Error: err.Error()})
 	}
@@ -90,7 +114,7 @@ func (c *AuthController) Register(ctx *router.Context) error {
 }
 
 // @Summary Login
-// @Description Login user
+// @Description Login user. A user.login_attempt listener may deny the login, answering 403 (or the 4xx it chose) with its reason and code.
 // @Security ApiKeyAuth
 // @Tags Core/Auth
 // @Accept json
@@ -99,23 +123,21 @@ func (c *AuthController) Register(ctx *router.Context) error {
 // @Success 200 {object} AuthResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} LoginRejectedResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /auth/login [post]
 func (c *AuthController) Login(ctx *router.Context) error {
 	var req LoginRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, err.Error()))
 	}
 
-	response, err := c.service.Login(&req)
+	response, err := c.service.Login(&req, clientInfo(ctx))
 	if err != nil {
-		if strings.Contains(err.Error(), "access_denied") {
-			// Return both the response and error when user is not an author
-			return ctx.JSON(http.StatusForbidden, map[string]any{
-				"error": err.Error(),
-				"data":  response,
-			})
+		var rejected *LoginRejectedError
+		if errors.As(err, &rejected) {
+			return ctx.JSON(rejected.Status, LoginRejectedResponse{Error: rejected.Reason, Code: rejected.Code})
 		}
 		if strings.Contains(err.Error(), "invalid credentials") {
 			return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
@@ -128,8 +150,9 @@ func (c *AuthController) Login(ctx *router.Context) error {
 
 // Logout handles user logout
 // @Summary Logout
-// @Description Logout user
+// @Description Logout user; the bearer token is revoked and rejected from then on
 // @Security ApiKeyAuth
+// @Security BearerAuth
 // @Tags Core/Auth
 // @Accept json
 // @Produce json
@@ -138,11 +161,24 @@ func (c *AuthController) Login(ctx *router.Context) error {
 // @Failure 401 {object} ErrorResponse
 // @Router /auth/logout [post]
 func (c *AuthController) Logout(ctx *router.Context) error {
+	token, found := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if !found || token == "" {
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Missing bearer token"})
+	}
+
+	if err := c.service.Logout(token); err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid token"})
+		}
+		c.logger.Error("Failed to revoke token", logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+	}
+
 	return ctx.JSON(http.StatusOK, SuccessResponse{Message: "Logout successful"})
 }
 
 // @Summary Forgot Password
-// @Description Request to reset password
+// @Description Request to reset password. Emails a reset token, or a short numeric code when PASSWORD_RESET_FORMAT is otp.
 // @Security ApiKeyAuth
 // @Tags Core/Auth
 // @Accept json
@@ -152,18 +188,23 @@ func (c *AuthController) Logout(ctx *router.Context) error {
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse "Email sending is not configured"
 // @Router /auth/forgot-password [post]
 func (c *AuthController) ForgotPassword(ctx *router.Context) error {
 	var req ForgotPasswordRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		c.logger.Error("Failed to bind JSON in ForgotPassword", zap.Error(err))
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, err.Error()))
 	}
 
 	c.logger.Info("Processing forgot password request", zap.String("email", req.Email))
 
 	err := c.service.ForgotPassword(req.Email)
 	if err != nil {
+		if errors.Is(err, ErrEmailUnavailable) {
+			c.logger.Warn("Password reset requested but email sending is not configured")
+			return ctx.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "Password reset is temporarily unavailable"})
+		}
 		if strings.Contains(err.Error(), "user not found") {
 			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
 		} else {
@@ -176,7 +217,7 @@ func (c *AuthController) ForgotPassword(ctx *router.Context) error {
 
 // ResetPassword handles password reset requests
 // @Summary Reset Password
-// @Description Reset user password using token
+// @Description Reset user password using the emailed token or code. In otp mode too many wrong codes cancel the reset.
 // @Security ApiKeyAuth
 // @Tags Core/Auth
 // @Accept json
@@ -191,13 +232,13 @@ func (c *AuthController) ForgotPassword(ctx *router.Context) error {
 func (c *AuthController) ResetPassword(ctx *router.Context) error {
 	var req ResetPasswordRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid request format"))
 	}
 
 	err := c.service.ResetPassword(req.Email, req.Token, req.NewPassword)
 	if err != nil {
 		switch {
-		case errors.Is(err, ErrInvalidToken):
+		case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrTokenExpired):
 			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid or expired token"})
 		case errors.Is(err, ErrUserNotFound):
 			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
@@ -209,6 +250,154 @@ func (c *AuthController) ResetPassword(ctx *router.Context) error {
 	return ctx.JSON(http.StatusOK, SuccessResponse{Message: "Password reset successful"})
 }
 
+// ListSessions returns the caller's active sessions
+// @Summary List sessions
+// @Description List the authenticated user's active login sessions
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Auth
+// @Produce json
+// @Success 200 {array} Session
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/sessions [get]
+func (c *AuthController) ListSessions(ctx *router.Context) error {
+	userId := ctx.GetUint("user_id")
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+	}
+
+	sessions, err := c.service.ListSessions(userId)
+	if err != nil {
+		c.logger.Error("Failed to list sessions", logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list sessions"})
+	}
+
+	return ctx.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession revokes one of the caller's sessions
+// @Summary Revoke session
+// @Description Revoke one of the authenticated user's sessions; its token stops working immediately
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Auth
+// @Produce json
+// @Param id path int true "Session Id"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/sessions/{id} [delete]
+func (c *AuthController) RevokeSession(ctx *router.Context) error {
+	userId := ctx.GetUint("user_id")
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+	}
+
+	sessionId, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid session Id"})
+	}
+
+	if err := c.service.RevokeSession(userId, uint(sessionId)); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "Session not found"})
+		}
+		c.logger.Error("Failed to revoke session", logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to revoke session"})
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessResponse{Message: "Session revoked"})
+}
+
+// DeleteAccount deletes the caller's account
+// @Summary Delete account
+// @Description Delete the authenticated user's account. The current password is required; all sessions are revoked and the user's data is removed or anonymized depending on ACCOUNT_DELETION_MODE
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Auth
+// @Accept json
+// @Produce json
+// @Param body body DeleteAccountRequest true "Delete Account Request"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/account [delete]
+func (c *AuthController) DeleteAccount(ctx *router.Context) error {
+	userId := ctx.GetUint("user_id")
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+	}
+
+	var req DeleteAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid request format"))
+	}
+
+	if err := c.service.DeleteAccount(userId, req.Password); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidPassword):
+			return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid password"})
+		case errors.Is(err, ErrUserNotFound):
+			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		default:
+			c.logger.Error("Failed to delete account", logger.String("error", err.Error()))
+			return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete account"})
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessResponse{Message: "Account deleted"})
+}
+
+// CreateInvite creates an invite to register with a role
+// @Summary Create invite
+// @Description Create a single-use invite token to register with a role. With an email, only that address can use it.
+// @Description Only owners can invite owners.
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Auth
+// @Accept json
+// @Produce json
+// @Param body body CreateInviteRequest true "Invite Request"
+// @Success 201 {object} InviteResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/invites [post]
+func (c *AuthController) CreateInvite(ctx *router.Context) error {
+	var req CreateInviteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, err.Error()))
+	}
+
+	invite, err := c.service.CreateInvite(ctx.GetUint("user_id"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRoleNotFound):
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, ErrInviteOwner):
+			return ctx.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		}
+		c.logger.Error("Failed to create invite", logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create invite"})
+	}
+
+	return ctx.JSON(http.StatusCreated, invite)
+}
+
+// clientInfo captures the caller's user agent and IP for session records
+func clientInfo(ctx *router.Context) ClientInfo {
+	return ClientInfo{
+		UserAgent: ctx.Request.UserAgent(),
+		IP:        ctx.ClientIP(),
+	}
+}
+
 func (c *AuthController) getWelcomeEmailBody(name string) string {
 	return "<h1>Welcome to Base!</h1>" +
 		"<p>Hi " + name + ",</p>" +
@@ -0,0 +1,151 @@
+package authentication
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"sync"
+)
+
+// Email template names
+const (
+	EmailPasswordReset   = "password_reset"
+	EmailPasswordChanged = "password_changed"
+)
+
+// DefaultEmailLocale is used when a user has no preferred language or no
+// template exists for it
+const DefaultEmailLocale = "en"
+
+// EmailTemplate holds the localized copy of one email. Content is an HTML
+// template rendered with the email data, so values such as names are escaped
+// before they are placed into the layout.
+type EmailTemplate struct {
+	Subject string
+	Title   string
+	Content string
+}
+
+var (
+	emailTemplatesMutex sync.RWMutex
+	emailTemplates      = map[string]map[string]EmailTemplate{
+		"en": {
+			EmailPasswordReset: {
+				Subject: "Reset Your Base Password",
+				Title:   "Reset Your Base Password",
+				Content: `
+		<p>Hi {{.FirstName}},</p>
+		<p>You have requested to reset your password. Use the following code to reset your password:</p>
+		<h2>{{.Token}}</h2>
+		<p>This code will expire in 15 minutes.</p>
+		<p>If you didn't request a password reset, please ignore this email or contact support if you have concerns.</p>
+	`,
+			},
+			EmailPasswordChanged: {
+				Subject: "Your Base Password Has Been Changed",
+				Title:   "Your Base Password Has Been Changed",
+				Content: `<p>Hi {{.FirstName}},</p><p>Your password has been successfully changed. If you did not make this change, please contact support immediately.</p>`,
+			},
+		},
+		"sq": {
+			EmailPasswordReset: {
+				Subject: "Rivendosni fjalëkalimin tuaj në Base",
+				Title:   "Rivendosni fjalëkalimin tuaj në Base",
+				Content: `
+		<p>Përshëndetje {{.FirstName}},</p>
+		<p>Keni kërkuar të rivendosni fjalëkalimin tuaj. Përdorni kodin e mëposhtëm për ta rivendosur:</p>
+		<h2>{{.Token}}</h2>
+		<p>Ky kod skadon pas 15 minutash.</p>
+		<p>Nëse nuk e keni kërkuar rivendosjen e fjalëkalimit, injorojeni këtë email ose kontaktoni mbështetjen nëse keni shqetësime.</p>
+	`,
+			},
+			EmailPasswordChanged: {
+				Subject: "Fjalëkalimi juaj në Base u ndryshua",
+				Title:   "Fjalëkalimi juaj në Base u ndryshua",
+				Content: `<p>Përshëndetje {{.FirstName}},</p><p>Fjalëkalimi juaj u ndryshua me sukses. Nëse nuk e keni bërë ju këtë ndryshim, ju lutemi kontaktoni menjëherë mbështetjen.</p>`,
+			},
+		},
+		"de": {
+			EmailPasswordReset: {
+				Subject: "Setzen Sie Ihr Base-Passwort zurück",
+				Title:   "Setzen Sie Ihr Base-Passwort zurück",
+				Content: `
+		<p>Hallo {{.FirstName}},</p>
+		<p>Sie haben angefordert, Ihr Passwort zurückzusetzen. Verwenden Sie den folgenden Code, um Ihr Passwort zurückzusetzen:</p>
+		<h2>{{.Token}}</h2>
+		<p>Dieser Code läuft in 15 Minuten ab.</p>
+		<p>Wenn Sie das Zurücksetzen nicht angefordert haben, ignorieren Sie diese E-Mail oder wenden Sie sich an den Support, falls Sie Bedenken haben.</p>
+	`,
+			},
+			EmailPasswordChanged: {
+				Subject: "Ihr Base-Passwort wurde geändert",
+				Title:   "Ihr Base-Passwort wurde geändert",
+				Content: `<p>Hallo {{.FirstName}},</p><p>Ihr Passwort wurde erfolgreich geändert. Wenn Sie diese Änderung nicht vorgenommen haben, wenden Sie sich bitte umgehend an den Support.</p>`,
+			},
+		},
+	}
+)
+
+// RegisterEmailTemplate adds or replaces the copy for an email in a locale
+func RegisterEmailTemplate(locale, name string, tmpl EmailTemplate) {
+	locale = normalizeEmailLocale(locale)
+
+	emailTemplatesMutex.Lock()
+	defer emailTemplatesMutex.Unlock()
+	if emailTemplates[locale] == nil {
+		emailTemplates[locale] = make(map[string]EmailTemplate)
+	}
+	emailTemplates[locale][name] = tmpl
+}
+
+// lookupEmailTemplate finds the copy for name in locale, trying the exact
+// locale ("pt-br"), then its language ("pt"), then English. It also returns
+// the locale the copy was found in.
+func lookupEmailTemplate(locale, name string) (EmailTemplate, string, bool) {
+	locale = normalizeEmailLocale(locale)
+	candidates := []string{locale}
+	if language, _, found := strings.Cut(locale, "-"); found {
+		candidates = append(candidates, language)
+	}
+	candidates = append(candidates, DefaultEmailLocale)
+
+	emailTemplatesMutex.RLock()
+	defer emailTemplatesMutex.RUnlock()
+	for _, candidate := range candidates {
+		if tmpl, ok := emailTemplates[candidate][name]; ok {
+			return tmpl, candidate, true
+		}
+	}
+	return EmailTemplate{}, "", false
+}
+
+// renderEmailContent renders the localized copy for name with data
+func renderEmailContent(locale, name string, data map[string]any) (EmailTemplate, string, error) {
+	tmpl, resolved, ok := lookupEmailTemplate(locale, name)
+	if !ok {
+		return EmailTemplate{}, "", fmt.Errorf("email template %q not found", name)
+	}
+
+	parsed, err := template.New(name).Parse(tmpl.Content)
+	if err != nil {
+		return EmailTemplate{}, "", fmt.Errorf("error parsing email template %q (%s): %w", name, resolved, err)
+	}
+
+	var content bytes.Buffer
+	if err := parsed.Execute(&content, data); err != nil {
+		return EmailTemplate{}, "", fmt.Errorf("failed to execute email template %q (%s): %w", name, resolved, err)
+	}
+
+	tmpl.Content = content.String()
+	return tmpl, resolved, nil
+}
+
+func normalizeEmailLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	locale = strings.ReplaceAll(locale, "_", "-")
+	if locale == "" {
+		return DefaultEmailLocale
+	}
+	return locale
+}
@@ -0,0 +1,108 @@
+package authentication
+
+import (
+	"strings"
+	"testing"
+
+	"base/core/email"
+)
+
+// captureSender records the messages it is asked to send
+type captureSender struct {
+	sent []email.Message
+}
+
+func (s *captureSender) Send(msg email.Message) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func TestSendEmailRendersUserLanguage(t *testing.T) {
+	service := newTestService(t)
+	sender := &captureSender{}
+	service.emailSender = sender
+
+	user := &AuthUser{}
+	user.Email = "ana@example.com"
+	user.FirstName = "Ana <b>"
+	user.Language = "sq"
+	if err := service.sendPasswordResetEmail(user, "123456"); err != nil {
+		t.Fatalf("sendPasswordResetEmail: %v", err)
+	}
+
+	if len(sender.sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sender.sent))
+	}
+	msg := sender.sent[0]
+	if msg.Subject != "Rivendosni fjalëkalimin tuaj në Base" {
+		t.Errorf("subject = %q, want the Albanian subject", msg.Subject)
+	}
+	if len(msg.To) != 1 || msg.To[0] != user.Email || !msg.IsHTML {
+		t.Errorf("message To %v, IsHTML %v", msg.To, msg.IsHTML)
+	}
+	for _, want := range []string{
+		`lang="sq"`,
+		"Përshëndetje Ana &lt;b&gt;,",
+		"Ky kod skadon pas 15 minutash.",
+		"<h2>123456</h2>",
+	} {
+		if !strings.Contains(msg.Body, want) {
+			t.Errorf("body does not contain %q:\n%s", want, msg.Body)
+		}
+	}
+	if strings.Contains(msg.Body, "You have requested") {
+		t.Error("body contains the English copy")
+	}
+}
+
+func TestRenderEmailContentFallsBack(t *testing.T) {
+	tests := []struct {
+		locale  string
+		want    string
+		subject string
+	}{
+		{"de", "de", "Ihr Base-Passwort wurde geändert"},
+		{"de_AT", "de", "Ihr Base-Passwort wurde geändert"},
+		{" SQ ", "sq", "Fjalëkalimi juaj në Base u ndryshua"},
+		{"fr", "en", "Your Base Password Has Been Changed"},
+		{"", "en", "Your Base Password Has Been Changed"},
+	}
+	for _, tt := range tests {
+		tmpl, resolved, err := renderEmailContent(tt.locale, EmailPasswordChanged, map[string]any{"FirstName": "Ana"})
+		if err != nil {
+			t.Fatalf("%q: %v", tt.locale, err)
+		}
+		if resolved != tt.want || tmpl.Subject != tt.subject {
+			t.Errorf("%q resolved to %q with subject %q, want %q with %q", tt.locale, resolved, tmpl.Subject, tt.want, tt.subject)
+		}
+		if !strings.Contains(tmpl.Content, "Ana") {
+			t.Errorf("%q: content was not rendered: %s", tt.locale, tmpl.Content)
+		}
+	}
+
+	if _, _, err := renderEmailContent("sq", "missing", nil); err == nil {
+		t.Error("rendering an unknown template succeeded")
+	}
+}
+
+func TestRegisterEmailTemplatePrefersRegion(t *testing.T) {
+	const name = "test_welcome"
+	RegisterEmailTemplate("pt", name, EmailTemplate{Subject: "Bem-vindo", Content: "<p>Olá {{.FirstName}}</p>"})
+	RegisterEmailTemplate("pt_BR", name, EmailTemplate{Subject: "Bem-vindo!", Content: "<p>Oi {{.FirstName}}</p>"})
+
+	tmpl, resolved, err := renderEmailContent("pt-BR", name, map[string]any{"FirstName": "Rui"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != "pt-br" || tmpl.Content != "<p>Oi Rui</p>" {
+		t.Errorf("pt-BR resolved to %q with %q, want the regional copy", resolved, tmpl.Content)
+	}
+
+	tmpl, resolved, err = renderEmailContent("pt-PT", name, map[string]any{"FirstName": "Rui"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != "pt" || tmpl.Content != "<p>Olá Rui</p>" {
+		t.Errorf("pt-PT resolved to %q with %q, want the language copy", resolved, tmpl.Content)
+	}
+}
@@ -0,0 +1,68 @@
+package authentication
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/logger"
+	"base/core/router"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestForgotPasswordWithoutEmailSender(t *testing.T) {
+	service := newTestService(t)
+	if service.emailSender != nil {
+		t.Fatal("test service has an email sender")
+	}
+	user := newResetUser(t, service)
+
+	if err := service.ForgotPassword(user.Email); !errors.Is(err, ErrEmailUnavailable) {
+		t.Fatalf("ForgotPassword = %v, want ErrEmailUnavailable", err)
+	}
+	var stored AuthUser
+	service.db.First(&stored, user.Id)
+	if stored.ResetToken != "" || stored.ResetTokenExpiry != nil {
+		t.Error("a reset token was issued although it cannot be emailed")
+	}
+
+	if err := service.sendPasswordChangedEmail(user); !errors.Is(err, ErrEmailUnavailable) {
+		t.Errorf("sendPasswordChangedEmail = %v, want ErrEmailUnavailable", err)
+	}
+
+	// Resetting with a code issued earlier still works; only the
+	// confirmation email is skipped
+	expiry := time.Now().Add(time.Minute)
+	service.db.Model(&stored).Updates(map[string]any{"reset_token": "pending", "reset_token_expiry": expiry})
+	if err := service.ResetPassword(user.Email, "pending", "new-password"); err != nil {
+		t.Errorf("ResetPassword without an email sender: %v", err)
+	}
+}
+
+func TestForgotPasswordRouteWithoutEmailSender(t *testing.T) {
+	service := newTestService(t)
+	user := newResetUser(t, service)
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	r := router.New()
+	NewAuthController(service, nil, logger.NewLoggerFromZap(zap.New(core)), nil).Routes(r.Group("/api/auth"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/forgot-password", strings.NewReader(`{"email":"`+user.Email+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want 503: %s", w.Code, w.Body)
+	}
+	warnings := logs.FilterLevelExact(zapcore.WarnLevel).FilterMessageSnippet("email sending is not configured")
+	if warnings.Len() != 1 {
+		t.Errorf("logged %d warnings about the missing email sender, want 1", warnings.Len())
+	}
+}
@@ -1,6 +1,9 @@
 package authentication
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+)
 
 // Auth-specific errors
 var (
@@ -10,4 +13,46 @@ var (
 	ErrInvalidPassword = errors.New("invalid password")
 	ErrEmailExists     = errors.New("email already exists")
 	ErrInvalidEmail    = errors.New("invalid email")
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrEmailUnavailable is returned when a flow needs to send an email but
+	// no email sender is configured
+	ErrEmailUnavailable = errors.New("email sending is not configured")
+	// ErrLoginRejected matches every LoginRejectedError
+	ErrLoginRejected = errors.New("login rejected")
 )
+
+// LoginRejectedError is returned by Login when a user.login_attempt listener
+// denies the login
+type LoginRejectedError struct {
+	Status int
+	Code   string
+	Reason string
+}
+
+// newLoginRejectedError builds the rejection of event, falling back to 403
+// and the login_rejected code
+func newLoginRejectedError(event *LoginEvent) *LoginRejectedError {
+	rejection := &LoginRejectedError{
+		Status: event.Status,
+		Code:   event.Code,
+		Reason: "not authorized",
+	}
+	if rejection.Status < 400 || rejection.Status > 499 {
+		rejection.Status = http.StatusForbidden
+	}
+	if rejection.Code == "" {
+		rejection.Code = "login_rejected"
+	}
+	if event.Error != nil && event.Error.Error != "" {
+		rejection.Reason = event.Error.Error
+	}
+	return rejection
+}
+
+func (e *LoginRejectedError) Error() string {
+	return e.Reason
+}
+
+func (e *LoginRejectedError) Is(target error) bool {
+	return target == ErrLoginRejected
+}
@@ -0,0 +1,162 @@
+package authentication
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultInviteTTL is how long an invite can be used when the config sets none
+const DefaultInviteTTL = 7 * 24 * time.Hour
+
+// Invite and registration errors
+var (
+	ErrRegistrationClosed = errors.New("registration requires an invite")
+	ErrInvalidInvite      = errors.New("invalid invite token")
+	ErrInviteExpired      = errors.New("invite has expired")
+	ErrInviteUsed         = errors.New("invite has already been used")
+	ErrInviteEmail        = errors.New("invite was issued for another email address")
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrInviteOwner        = errors.New("only an owner can invite an owner")
+)
+
+// Invite lets one person register with RoleId. An invite with an Email is
+// only valid for that address. UsedAt is set when it is consumed, so it
+// cannot be used twice.
+type Invite struct {
+	Id        uint       `gorm:"column:id;primary_key;auto_increment" json:"id"`
+	Token     string     `gorm:"column:token;size:64;not null;uniqueIndex" json:"-"`
+	Email     string     `gorm:"column:email;size:255" json:"email,omitempty"`
+	RoleId    uint       `gorm:"column:role_id;not null" json:"role_id"`
+	CreatedBy uint       `gorm:"column:created_by;not null" json:"created_by"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;not null;index" json:"expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at" json:"used_at,omitempty"`
+	UsedBy    *uint      `gorm:"column:used_by" json:"used_by,omitempty"`
+	CreatedAt time.Time  `gorm:"column:created_at" json:"created_at"`
+}
+
+func (Invite) TableName() string {
+	return "invites"
+}
+
+// CreateInviteRequest is the payload for creating an invite
+type CreateInviteRequest struct {
+	RoleId uint   `json:"role_id" binding:"required" example:"3"`
+	Email  string `json:"email,omitempty" binding:"omitempty,email" example:"jane@example.com"`
+}
+
+// InviteResponse carries the token to hand to the invited person. The token
+// is only returned here.
+type InviteResponse struct {
+	Invite
+	Token string `json:"token"`
+}
+
+// CreateInvite issues an invite to register with roleId, valid for the
+// configured invite lifetime. Only owners may invite owners.
+func (s *AuthService) CreateInvite(creatorId uint, req *CreateInviteRequest) (*InviteResponse, error) {
+	roleName, err := s.roleName(req.RoleId)
+	if err != nil {
+		return nil, err
+	}
+	if roleName == OwnerRole {
+		var creator AuthUser
+		if err := s.db.Select("role_id").First(&creator, creatorId).Error; err != nil {
+			return nil, fmt.Errorf("failed to get inviting user: %w", err)
+		}
+		creatorRole, err := s.roleName(creator.RoleId)
+		if err != nil && !errors.Is(err, ErrRoleNotFound) {
+			return nil, err
+		}
+		if creatorRole != OwnerRole {
+			return nil, ErrInviteOwner
+		}
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invite := Invite{
+		Token:     token,
+		Email:     strings.ToLower(strings.TrimSpace(req.Email)),
+		RoleId:    req.RoleId,
+		CreatedBy: creatorId,
+		ExpiresAt: time.Now().Add(s.inviteTTL),
+	}
+	if err := s.db.Create(&invite).Error; err != nil {
+		return nil, fmt.Errorf("failed to create invite: %w", err)
+	}
+
+	return &InviteResponse{Invite: invite, Token: token}, nil
+}
+
+// findInvite returns the usable invite with token for email
+func (s *AuthService) findInvite(token, email string) (*Invite, error) {
+	var invite Invite
+	if err := s.db.Where("token = ?", token).First(&invite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvite
+		}
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+
+	switch {
+	case invite.UsedAt != nil:
+		return nil, ErrInviteUsed
+	case !time.Now().Before(invite.ExpiresAt):
+		return nil, ErrInviteExpired
+	case invite.Email != "" && !strings.EqualFold(invite.Email, strings.TrimSpace(email)):
+		return nil, ErrInviteEmail
+	}
+	return &invite, nil
+}
+
+// consumeInvite marks an invite used by userId. Two registrations racing for
+// one invite both pass findInvite, but only one of them consumes it.
+func consumeInvite(tx *gorm.DB, inviteId, userId uint) error {
+	result := tx.Model(&Invite{}).
+		Where("id = ? AND used_at IS NULL", inviteId).
+		Updates(map[string]any{"used_at": time.Now(), "used_by": userId})
+	if result.Error != nil {
+		return fmt.Errorf("failed to consume invite: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInviteUsed
+	}
+	return nil
+}
+
+// roleName returns the name of an existing role
+func (s *AuthService) roleName(roleId uint) (string, error) {
+	var names []string
+	if err := s.db.Table("roles").Where("id = ?", roleId).Limit(1).Pluck("name", &names).Error; err != nil {
+		return "", fmt.Errorf("failed to get role: %w", err)
+	}
+	if len(names) == 0 {
+		return "", ErrRoleNotFound
+	}
+	return names[0], nil
+}
+
+// roleIdByName returns the ID of the role with the given name
+func (s *AuthService) roleIdByName(name string) (uint, error) {
+	var ids []uint
+	if err := s.db.Table("roles").Where("name = ?", name).Limit(1).Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("failed to get role: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, ErrRoleNotFound
+	}
+	return ids[0], nil
+}
+
+// isInviteError reports errors caused by the invite a registration carried
+func isInviteError(err error) bool {
+	return errors.Is(err, ErrInvalidInvite) || errors.Is(err, ErrInviteExpired) ||
+		errors.Is(err, ErrInviteUsed) || errors.Is(err, ErrInviteEmail)
+}
@@ -0,0 +1,247 @@
+package authentication
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/app/authorization"
+	"base/core/logger"
+	"base/core/router"
+
+	"go.uber.org/zap"
+)
+
+// withRoles stores the Owner, Administrator and Member roles and returns
+// their IDs by name
+func withRoles(t *testing.T, service *AuthService) map[string]uint {
+	t.Helper()
+	return seedRoles(t, service, OwnerRole, "Administrator", MemberRole)
+}
+
+// seedRoles stores roles with names in order and returns their IDs by name
+func seedRoles(t *testing.T, service *AuthService, names ...string) map[string]uint {
+	t.Helper()
+	if err := service.db.AutoMigrate(&authorization.Role{}); err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]uint)
+	for _, name := range names {
+		role := authorization.Role{Name: name}
+		if err := service.db.Create(&role).Error; err != nil {
+			t.Fatal(err)
+		}
+		ids[name] = role.Id
+	}
+	return ids
+}
+
+// register signs up name with an optional invite and returns the new user's role
+func register(t *testing.T, service *AuthService, name, invite string) (uint, error) {
+	t.Helper()
+	response, err := service.Register(&RegisterRequest{
+		FirstName:   name,
+		LastName:    "Test",
+		Username:    name,
+		Email:       name + "@example.com",
+		Phone:       "+383-" + name,
+		Password:    "password123",
+		InviteToken: invite,
+	}, ClientInfo{})
+	if err != nil {
+		return 0, err
+	}
+	var user AuthUser
+	if err := service.db.First(&user, response.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user.RoleId, nil
+}
+
+func TestOpenRegistration(t *testing.T) {
+	service := newTestService(t)
+	roles := withRoles(t, service)
+
+	if role, err := register(t, service, "first", ""); err != nil || role != roles[OwnerRole] {
+		t.Errorf("first user: role %d, %v; want Owner", role, err)
+	}
+	if role, err := register(t, service, "second", ""); err != nil || role != roles[MemberRole] {
+		t.Errorf("second user: role %d, %v; want Member", role, err)
+	}
+
+	// An invite still decides the role in open mode
+	invite, err := service.CreateInvite(1, &CreateInviteRequest{RoleId: roles["Administrator"]})
+	if err != nil {
+		t.Fatalf("CreateInvite: %v", err)
+	}
+	if role, err := register(t, service, "third", invite.Token); err != nil || role != roles["Administrator"] {
+		t.Errorf("invited user: role %d, %v; want Administrator", role, err)
+	}
+}
+
+func TestInviteRequiredRegistration(t *testing.T) {
+	service := newTestService(t)
+	service.registrationOpen = false
+	roles := withRoles(t, service)
+
+	// The first user can always register, so the instance gets an owner
+	if role, err := register(t, service, "owner", ""); err != nil || role != roles[OwnerRole] {
+		t.Fatalf("first user: role %d, %v; want Owner", role, err)
+	}
+	if _, err := register(t, service, "stranger", ""); !errors.Is(err, ErrRegistrationClosed) {
+		t.Errorf("without an invite: got %v, want ErrRegistrationClosed", err)
+	}
+	if _, err := register(t, service, "guesser", "not-a-token"); !errors.Is(err, ErrInvalidInvite) {
+		t.Errorf("unknown invite: got %v, want ErrInvalidInvite", err)
+	}
+
+	invite, err := service.CreateInvite(1, &CreateInviteRequest{RoleId: roles["Administrator"]})
+	if err != nil {
+		t.Fatalf("CreateInvite: %v", err)
+	}
+	if role, err := register(t, service, "invited", invite.Token); err != nil || role != roles["Administrator"] {
+		t.Fatalf("invited user: role %d, %v; want Administrator", role, err)
+	}
+	var stored Invite
+	service.db.First(&stored, invite.Id)
+	if stored.UsedAt == nil || stored.UsedBy == nil {
+		t.Errorf("the invite was not marked used: %+v", stored)
+	}
+
+	// A consumed invite cannot be used again
+	if _, err := register(t, service, "reuser", invite.Token); !errors.Is(err, ErrInviteUsed) {
+		t.Errorf("reused invite: got %v, want ErrInviteUsed", err)
+	}
+
+	// An invite for an address only works for that address
+	personal, err := service.CreateInvite(1, &CreateInviteRequest{RoleId: roles[MemberRole], Email: " Jane@Example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := register(t, service, "john", personal.Token); !errors.Is(err, ErrInviteEmail) {
+		t.Errorf("another address: got %v, want ErrInviteEmail", err)
+	}
+	if _, err := register(t, service, "jane", personal.Token); err != nil {
+		t.Errorf("the invited address: %v", err)
+	}
+
+	var users int64
+	service.db.Model(&AuthUser{}).Count(&users)
+	if users != 3 {
+		t.Errorf("%d users registered, want 3", users)
+	}
+}
+
+func TestExpiredInviteIsRejected(t *testing.T) {
+	service := newTestService(t)
+	service.registrationOpen = false
+	roles := withRoles(t, service)
+	register(t, service, "owner", "")
+
+	service.inviteTTL = time.Hour
+	invite, err := service.CreateInvite(1, &CreateInviteRequest{RoleId: roles[MemberRole]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if until := time.Until(invite.ExpiresAt); until > time.Hour || until < time.Hour-time.Minute {
+		t.Errorf("invite expires in %s, want about an hour", until)
+	}
+	service.db.Model(&Invite{}).Where("id = ?", invite.Id).Update("expires_at", time.Now().Add(-time.Second))
+
+	if _, err := register(t, service, "late", invite.Token); !errors.Is(err, ErrInviteExpired) {
+		t.Errorf("got %v, want ErrInviteExpired", err)
+	}
+	var stored Invite
+	service.db.First(&stored, invite.Id)
+	if stored.UsedAt != nil {
+		t.Error("a rejected invite was consumed")
+	}
+}
+
+func TestCreateInviteChecksRoles(t *testing.T) {
+	service := newTestService(t)
+	roles := withRoles(t, service)
+	register(t, service, "owner", "")
+	admin, _ := service.CreateInvite(1, &CreateInviteRequest{RoleId: roles["Administrator"]})
+	register(t, service, "admin", admin.Token)
+
+	if _, err := service.CreateInvite(2, &CreateInviteRequest{RoleId: roles[OwnerRole]}); !errors.Is(err, ErrInviteOwner) {
+		t.Errorf("administrator inviting an owner: got %v, want ErrInviteOwner", err)
+	}
+	if _, err := service.CreateInvite(1, &CreateInviteRequest{RoleId: roles[OwnerRole]}); err != nil {
+		t.Errorf("owner inviting an owner: %v", err)
+	}
+	if _, err := service.CreateInvite(1, &CreateInviteRequest{RoleId: 99}); !errors.Is(err, ErrRoleNotFound) {
+		t.Errorf("unknown role: got %v, want ErrRoleNotFound", err)
+	}
+}
+
+func TestInviteRoutes(t *testing.T) {
+	service := newTestService(t)
+	service.registrationOpen = false
+	roles := withRoles(t, service)
+	register(t, service, "owner", "")
+	member, _ := service.CreateInvite(1, &CreateInviteRequest{RoleId: roles[MemberRole]})
+	register(t, service, "member", member.Token)
+
+	r := router.New()
+	api := r.Group("/api/auth")
+	api.Use(func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			if id := c.Request.Header.Get("X-User-Id"); id != "" {
+				userId, _ := strconv.Atoi(id)
+				c.Set("user_id", uint(userId))
+			}
+			return next(c)
+		}
+	})
+	NewAuthController(service, nil, logger.NewLoggerFromZap(zap.NewNop()), authorization.NewAuthorizationService(service.db)).Routes(api)
+	do := func(userId, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if userId != "" {
+			req.Header.Set("X-User-Id", userId)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	body := `{"role_id":` + strconv.Itoa(int(roles[MemberRole])) + `}`
+	if w := do("2", "/api/auth/invites", body); w.Code != http.StatusForbidden {
+		t.Errorf("member creating an invite: status %d, want 403", w.Code)
+	}
+	if w := do("", "/api/auth/invites", body); w.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous invite: status %d, want 401", w.Code)
+	}
+	w := do("1", "/api/auth/invites", body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("owner creating an invite: status %d: %s", w.Code, w.Body)
+	}
+	var invite InviteResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &invite); err != nil || invite.Token == "" {
+		t.Fatalf("invite response %s: %v", w.Body, err)
+	}
+	if w := do("1", "/api/auth/invites", `{"role_id":99}`); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown role: status %d, want 400", w.Code)
+	}
+
+	signup := func(name, token string) int {
+		return do("", "/api/auth/register", `{"first_name":"`+name+`","last_name":"Test","username":"`+name+
+			`","phone":"+383-`+name+`","email":"`+name+`@example.com","password":"password123","invite_token":"`+token+`"}`).Code
+	}
+	if code := signup("closed", ""); code != http.StatusForbidden {
+		t.Errorf("register without an invite: status %d, want 403", code)
+	}
+	if code := signup("invited", invite.Token); code != http.StatusCreated {
+		t.Errorf("register with an invite: status %d, want 201", code)
+	}
+	if code := signup("again", invite.Token); code != http.StatusBadRequest {
+		t.Errorf("register with a used invite: status %d, want 400", code)
+	}
+}
@@ -0,0 +1,111 @@
+package authentication
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"base/core/logger"
+	"base/core/router"
+
+	"go.uber.org/zap"
+)
+
+func TestLoginListenerRejection(t *testing.T) {
+	service := newTestService(t)
+	withRoles(t, service)
+	if _, err := register(t, service, "player", ""); err != nil {
+		t.Fatal(err)
+	}
+	var before AuthUser
+	service.db.Where("username = ?", "player").First(&before)
+	sessionsBefore, _ := service.ListSessions(before.Id)
+
+	reject := func(event *LoginEvent) {}
+	service.emitter.On("user.login_attempt", func(data any) { reject(data.(*LoginEvent)) })
+
+	r := router.New()
+	NewAuthController(service, nil, logger.NewLoggerFromZap(zap.NewNop()), nil).Routes(r.Group("/api/auth"))
+	login := func() (int, LoginRejectedResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(`{"email":"player@example.com","password":"password123"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var body LoginRejectedResponse
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body
+	}
+
+	cases := []struct {
+		name   string
+		reject func(*LoginEvent)
+		status int
+		body   LoginRejectedResponse
+	}{
+		{
+			name:   "reject with status and code",
+			reject: func(e *LoginEvent) { e.Reject(http.StatusLocked, "account_locked", "account suspended") },
+			status: http.StatusLocked,
+			body:   LoginRejectedResponse{Error: "account suspended", Code: "account_locked"},
+		},
+		{
+			name: "cleared flag with a reason",
+			reject: func(e *LoginEvent) {
+				*e.LoginAllowed = false
+				e.Error = &ErrorResponse{Error: "email not verified"}
+			},
+			status: http.StatusForbidden,
+			body:   LoginRejectedResponse{Error: "email not verified", Code: "login_rejected"},
+		},
+		{
+			name:   "cleared flag alone",
+			reject: func(e *LoginEvent) { *e.LoginAllowed = false },
+			status: http.StatusForbidden,
+			body:   LoginRejectedResponse{Error: "not authorized", Code: "login_rejected"},
+		},
+		{
+			name:   "status outside 4xx",
+			reject: func(e *LoginEvent) { e.Reject(http.StatusOK, "", "maintenance") },
+			status: http.StatusForbidden,
+			body:   LoginRejectedResponse{Error: "maintenance", Code: "login_rejected"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reject = tc.reject
+			if status, body := login(); status != tc.status || body != tc.body {
+				t.Errorf("got %d %+v, want %d %+v", status, body, tc.status, tc.body)
+			}
+		})
+	}
+
+	// Rejected logins leave last_login alone and open no session
+	var after AuthUser
+	service.db.First(&after, before.Id)
+	if (before.LastLogin == nil) != (after.LastLogin == nil) || (after.LastLogin != nil && !after.LastLogin.Equal(*before.LastLogin)) {
+		t.Errorf("last_login moved from %v to %v", before.LastLogin, after.LastLogin)
+	}
+	if sessions, _ := service.ListSessions(before.Id); len(sessions) != len(sessionsBefore) {
+		t.Errorf("%d sessions after rejected logins, want %d", len(sessions), len(sessionsBefore))
+	}
+
+	// The service reports the rejection as a typed error
+	reject = func(e *LoginEvent) { e.Reject(0, "banned", "banned") }
+	_, err := service.Login(&LoginRequest{Email: "player@example.com", Password: "password123"}, ClientInfo{})
+	var rejected *LoginRejectedError
+	if !errors.Is(err, ErrLoginRejected) || !errors.As(err, &rejected) || rejected.Status != http.StatusForbidden {
+		t.Errorf("Login returned %v, want a 403 LoginRejectedError", err)
+	}
+
+	reject = func(*LoginEvent) {}
+	if status, _ := login(); status != http.StatusOK {
+		t.Fatalf("allowed login: status %d", status)
+	}
+	service.db.First(&after, before.Id)
+	if after.LastLogin == nil || (before.LastLogin != nil && !after.LastLogin.After(*before.LastLogin)) {
+		t.Errorf("last_login %v not updated by the allowed login", after.LastLogin)
+	}
+}
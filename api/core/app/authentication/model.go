@@ -10,17 +10,78 @@ type AuthUser struct {
 	LastLogin        *time.Time `gorm:"column:last_login"`
 	ResetToken       string     `gorm:"column:reset_token"`
 	ResetTokenExpiry *time.Time `gorm:"column:reset_token_expiry"`
+	ResetAttempts    int        `gorm:"column:reset_attempts;not null;default:0"` // Wrong codes entered for the pending reset
 }
 
 func (AuthUser) TableName() string {
 	return "users"
 }
 
+// Session records a login so users can review and revoke the tokens issued to them.
+// TokenId is the jti claim of the access token issued for the session.
+type Session struct {
+	Id        uint       `gorm:"column:id;primary_key;auto_increment" json:"id"`
+	UserId    uint       `gorm:"column:user_id;not null;index" json:"-"`
+	TokenId   string     `gorm:"column:token_id;size:64;not null;uniqueIndex" json:"-"`
+	Device    string     `gorm:"column:device;size:32" json:"device"`
+	UserAgent string     `gorm:"column:user_agent;size:512" json:"user_agent"`
+	IP        string     `gorm:"column:ip;size:64" json:"ip"`
+	IssuedAt  time.Time  `gorm:"column:issued_at;not null" json:"issued_at"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;not null;index" json:"expires_at"`
+	RevokedAt *time.Time `gorm:"column:revoked_at" json:"revoked_at,omitempty"`
+}
+
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// RevokedToken is a denylist entry for an access token that must be rejected
+// before it expires. Entries are pruned once the token would have expired anyway.
+type RevokedToken struct {
+	TokenId   string    `gorm:"column:token_id;size:64;primaryKey" json:"-"`
+	ExpiresAt time.Time `gorm:"column:expires_at;not null;index" json:"expires_at"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
+
+// ClientInfo describes the client a login request came from
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// LoginEvent is emitted as user.login_attempt once the credentials checked
+// out. A listener denies the login with Reject, or by clearing LoginAllowed
+// and setting Error; the token is then revoked, last_login is left untouched
+// and the client gets Status with a LoginRejectedResponse. It is emitted
+// with EmitSync, so a listener that fails or panics denies the login too.
 type LoginEvent struct {
 	User         *AuthUser
 	LoginAllowed *bool
 	Error        *ErrorResponse
 	Response     *AuthResponse
+	Status       int    // HTTP status of a rejection, 403 unless a 4xx is set
+	Code         string // Machine-readable rejection code, "login_rejected" when empty
+}
+
+// Reject denies the login, answering status with code and reason. A zero
+// status answers 403.
+func (e *LoginEvent) Reject(status int, code, reason string) {
+	if e.LoginAllowed != nil {
+		*e.LoginAllowed = false
+	}
+	e.Status = status
+	e.Code = code
+	e.Error = &ErrorResponse{Error: reason}
+}
+
+// LoginRejectedResponse is the body of a login denied by a login listener
+type LoginRejectedResponse struct {
+	Error string `json:"error" example:"account suspended"`
+	Code  string `json:"code" example:"login_rejected"`
 }
 
 // RegisterRequest represents the payload for user registration
@@ -39,6 +100,10 @@ type RegisterRequest struct {
 	Email string `json:"email" binding:"required,email" example:"john@example.com"`
 	// @Description Password for the account (minimum 8 characters)
 	Password string `json:"password" binding:"required,min=8" example:"password123"`
+	// @Description Preferred locale for emails, defaults to English
+	Language string `json:"language,omitempty" binding:"max=16" example:"en"`
+	// @Description Invite token; required when open registration is disabled
+	InviteToken string `json:"invite_token,omitempty" example:"3f5c..."`
 }
 
 // LoginRequest represents the payload for user login
@@ -59,6 +124,11 @@ type ResetPasswordRequest struct {
 	NewPassword string `json:"new_password" binding:"required,min=6" example:"newpassword123"`
 }
 
+// DeleteAccountRequest confirms account deletion with the current password
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required" example:"password123"`
+}
+
 type AuthResponse struct {
 	profile.UserResponse
 	AccessToken string `json:"accessToken"`
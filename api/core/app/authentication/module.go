@@ -1,11 +1,14 @@
 package authentication
 
 import (
+	"base/core/app/authorization"
+	"base/core/config"
 	"base/core/email"
 	"base/core/emitter"
 	"base/core/logger"
 	"base/core/module"
 	"base/core/router"
+	"base/core/types"
 
 	"gorm.io/gorm"
 )
@@ -20,9 +23,38 @@ type AuthenticationModule struct {
 	Emitter     *emitter.Emitter
 }
 
-func NewAuthenticationModule(db *gorm.DB, router *router.RouterGroup, emailSender email.Sender, logger logger.Logger, emitter *emitter.Emitter) module.Module {
+func NewAuthenticationModule(db *gorm.DB, router *router.RouterGroup, emailSender email.Sender, logger logger.Logger, emitter *emitter.Emitter, cfg *config.Config) module.Module {
 	service := NewAuthService(db, emailSender, emitter)
-	controller := NewAuthController(service, emailSender, logger)
+	if cfg != nil && cfg.AccountDeletionMode != "" {
+		service.deletionMode = cfg.AccountDeletionMode
+	}
+	if cfg != nil && cfg.PasswordResetFormat != "" {
+		service.resetTokens = ResetTokenConfig{
+			Format:    cfg.PasswordResetFormat,
+			Expiry:    cfg.PasswordResetExpiry,
+			OTPLength: cfg.PasswordResetOTPLength,
+			OTPExpiry: cfg.PasswordResetOTPExpiry,
+		}
+	}
+	if cfg != nil {
+		service.registrationOpen = cfg.RegistrationOpen
+		if cfg.InviteTTL > 0 {
+			service.inviteTTL = cfg.InviteTTL
+		}
+		if cfg.DefaultUserRole != "" {
+			service.defaultRole = cfg.DefaultUserRole
+		}
+	}
+	controller := NewAuthController(service, emailSender, logger, authorization.NewAuthorizationService(db))
+
+	// Welcome emails and other follow-ups must not miss a registration
+	if emitter != nil {
+		emitter.Persist("user.registered", types.UserData{})
+	}
+
+	// Reject tokens that are on the revoked_tokens denylist
+	types.TokenRevocationCheck = service.IsTokenRevoked
+	types.TokenRevoker = service.RevokeToken
 
 	authModule := &AuthenticationModule{
 		DB:          db,
@@ -44,11 +76,14 @@ func (m *AuthenticationModule) Routes(router *router.RouterGroup) {
 }
 
 func (m *AuthenticationModule) Migrate() error {
-	return m.DB.AutoMigrate(&AuthUser{})
+	return m.DB.AutoMigrate(&AuthUser{}, &Session{}, &RevokedToken{}, &Invite{})
 }
 
 func (m *AuthenticationModule) GetModels() []any {
 	return []any{
 		&AuthUser{},
+		&Session{},
+		&RevokedToken{},
+		&Invite{},
 	}
 }
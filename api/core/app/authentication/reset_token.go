@@ -0,0 +1,79 @@
+package authentication
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Formats of the password reset credential sent by email
+const (
+	ResetFormatToken = "token" // 64 hex characters, meant for reset links
+	ResetFormatOTP   = "otp"   // A short numeric code typed in by the user
+)
+
+// otpMaxAttempts is how many wrong codes clear a pending OTP reset, so a code
+// cannot be guessed within its lifetime
+const otpMaxAttempts = 5
+
+// otpRateLimit is the per-client request budget of the reset endpoints in OTP
+// mode, per otpRateWindow
+const (
+	otpRateLimit  = 5
+	otpRateWindow = 15 * time.Minute
+)
+
+// ResetTokenConfig chooses the password reset credential and its lifetime
+type ResetTokenConfig struct {
+	Format    string
+	Expiry    time.Duration // Lifetime of a token
+	OTPLength int
+	OTPExpiry time.Duration // Lifetime of a code
+}
+
+// DefaultResetTokenConfig sends a token valid for 15 minutes
+func DefaultResetTokenConfig() ResetTokenConfig {
+	return ResetTokenConfig{
+		Format:    ResetFormatToken,
+		Expiry:    15 * time.Minute,
+		OTPLength: 6,
+		OTPExpiry: 5 * time.Minute,
+	}
+}
+
+// IsOTP reports whether resets use short numeric codes
+func (c ResetTokenConfig) IsOTP() bool {
+	return c.Format == ResetFormatOTP
+}
+
+// generate returns a new credential and how long it stays valid
+func (c ResetTokenConfig) generate() (string, time.Duration, error) {
+	if c.IsOTP() {
+		code, err := generateOTP(c.OTPLength)
+		return code, c.OTPExpiry, err
+	}
+	token, err := generateToken()
+	return token, c.Expiry, err
+}
+
+// generateOTP returns length uniformly random digits
+func generateOTP(length int) (string, error) {
+	var code strings.Builder
+	for i := 0; i < length; i++ {
+		digit, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random digit: %w", err)
+		}
+		code.WriteByte(byte('0' + digit.Int64()))
+	}
+	return code.String(), nil
+}
+
+// resetTokenMatches compares in constant time so timing does not reveal how
+// much of a guess was right
+func resetTokenMatches(stored, given string) bool {
+	return stored != "" && subtle.ConstantTimeCompare([]byte(stored), []byte(given)) == 1
+}
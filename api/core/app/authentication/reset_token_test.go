@@ -0,0 +1,177 @@
+package authentication
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/logger"
+	"base/core/router"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newResetUser stores a user who can request password resets
+func newResetUser(t *testing.T, service *AuthService) *AuthUser {
+	t.Helper()
+	user := &AuthUser{}
+	user.FirstName, user.LastName = "Ada", "Lovelace"
+	user.Username, user.Email, user.Phone = "ada", "ada@example.com", "+38344000001"
+	if err := service.db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+// requestReset asks for a reset and returns the stored user and the emailed body
+func requestReset(t *testing.T, service *AuthService, sender *captureSender, user *AuthUser) (*AuthUser, string) {
+	t.Helper()
+	if err := service.ForgotPassword(user.Email); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	var stored AuthUser
+	if err := service.db.First(&stored, user.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &stored, sender.sent[len(sender.sent)-1].Body
+}
+
+func checkExpiry(t *testing.T, user *AuthUser, lifetime time.Duration) {
+	t.Helper()
+	if user.ResetTokenExpiry == nil {
+		t.Fatal("no reset expiry stored")
+	}
+	if left := time.Until(*user.ResetTokenExpiry); left > lifetime || left < lifetime-time.Minute {
+		t.Errorf("reset expires in %s, want about %s", left, lifetime)
+	}
+}
+
+func TestResetWithToken(t *testing.T) {
+	service := newTestService(t)
+	sender := &captureSender{}
+	service.emailSender = sender
+	service.resetTokens.Expiry = 30 * time.Minute
+	user := newResetUser(t, service)
+
+	stored, body := requestReset(t, service, sender, user)
+	if !regexp.MustCompile(`^[0-9a-f]{64}$`).MatchString(stored.ResetToken) {
+		t.Errorf("reset token %q is not 64 hex characters", stored.ResetToken)
+	}
+	if !strings.Contains(body, stored.ResetToken) {
+		t.Error("the emailed message does not contain the token")
+	}
+	checkExpiry(t, stored, 30*time.Minute)
+
+	if err := service.ResetPassword(user.Email, strings.Repeat("0", 64), "new-password"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("wrong token: got %v, want ErrInvalidToken", err)
+	}
+	if err := service.ResetPassword(user.Email, stored.ResetToken, "new-password"); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+
+	var reset AuthUser
+	service.db.First(&reset, user.Id)
+	if bcrypt.CompareHashAndPassword([]byte(reset.Password), []byte("new-password")) != nil {
+		t.Error("password was not changed")
+	}
+	if err := service.ResetPassword(user.Email, stored.ResetToken, "again"); err == nil {
+		t.Error("a used token was accepted again")
+	}
+}
+
+func TestResetWithOTP(t *testing.T) {
+	service := newTestService(t)
+	sender := &captureSender{}
+	service.emailSender = sender
+	service.resetTokens = ResetTokenConfig{Format: ResetFormatOTP, Expiry: 15 * time.Minute, OTPLength: 6, OTPExpiry: 5 * time.Minute}
+	user := newResetUser(t, service)
+
+	stored, body := requestReset(t, service, sender, user)
+	if !regexp.MustCompile(`^[0-9]{6}$`).MatchString(stored.ResetToken) {
+		t.Errorf("reset code %q is not 6 digits", stored.ResetToken)
+	}
+	if !strings.Contains(body, stored.ResetToken) {
+		t.Error("the emailed message does not contain the code")
+	}
+	checkExpiry(t, stored, 5*time.Minute)
+
+	if err := service.ResetPassword(user.Email, stored.ResetToken, "new-password"); err != nil {
+		t.Fatalf("ResetPassword with the code: %v", err)
+	}
+}
+
+func TestResetOTPIsClearedAfterTooManyWrongCodes(t *testing.T) {
+	service := newTestService(t)
+	sender := &captureSender{}
+	service.emailSender = sender
+	service.resetTokens = ResetTokenConfig{Format: ResetFormatOTP, OTPLength: 6, OTPExpiry: 5 * time.Minute}
+	user := newResetUser(t, service)
+
+	stored, _ := requestReset(t, service, sender, user)
+	wrong := "000000"
+	if stored.ResetToken == wrong {
+		wrong = "111111"
+	}
+	for i := 0; i < otpMaxAttempts; i++ {
+		if err := service.ResetPassword(user.Email, wrong, "guess"); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("wrong code %d: got %v, want ErrInvalidToken", i+1, err)
+		}
+	}
+	if err := service.ResetPassword(user.Email, stored.ResetToken, "new-password"); err == nil {
+		t.Error("the right code was accepted after too many wrong ones")
+	}
+
+	// A new request starts over
+	stored, _ = requestReset(t, service, sender, user)
+	if stored.ResetAttempts != 0 {
+		t.Errorf("new reset starts with %d attempts, want 0", stored.ResetAttempts)
+	}
+	if err := service.ResetPassword(user.Email, stored.ResetToken, "new-password"); err != nil {
+		t.Errorf("new code: %v", err)
+	}
+}
+
+func TestResetRejectsExpiredCredential(t *testing.T) {
+	for _, format := range []string{ResetFormatToken, ResetFormatOTP} {
+		service := newTestService(t)
+		sender := &captureSender{}
+		service.emailSender = sender
+		service.resetTokens.Format = format
+		user := newResetUser(t, service)
+
+		stored, _ := requestReset(t, service, sender, user)
+		if err := service.db.Model(stored).Update("reset_token_expiry", time.Now().Add(-time.Second)).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := service.ResetPassword(user.Email, stored.ResetToken, "new-password"); !errors.Is(err, ErrTokenExpired) {
+			t.Errorf("%s: expired credential: got %v, want ErrTokenExpired", format, err)
+		}
+	}
+}
+
+func TestResetEndpointsAreRateLimitedForOTP(t *testing.T) {
+	for _, format := range []string{ResetFormatToken, ResetFormatOTP} {
+		service := newTestService(t)
+		service.resetTokens.Format = format
+
+		r := router.New()
+		NewAuthController(service, nil, logger.NewLoggerFromZap(zap.NewNop()), nil).Routes(r.Group("/api/auth"))
+
+		limited := false
+		for i := 0; i <= otpRateLimit; i++ {
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/reset-password", strings.NewReader(`{"email":"ada@example.com","token":"123456","new_password":"new-password"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			limited = limited || w.Code == http.StatusTooManyRequests
+		}
+		if want := format == ResetFormatOTP; limited != want {
+			t.Errorf("%s: rate limited = %v, want %v", format, limited, want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package authentication
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFirstUserBecomesOwnerByName(t *testing.T) {
+	service := newTestService(t)
+	// Custom seeding puts the roles at other ids than the defaults
+	roles := seedRoles(t, service, "Guest", MemberRole, "Editor", OwnerRole)
+
+	if role, err := register(t, service, "first", ""); err != nil || role != roles[OwnerRole] {
+		t.Errorf("first user: role %d, %v; want Owner (%d)", role, err, roles[OwnerRole])
+	}
+	if role, err := register(t, service, "second", ""); err != nil || role != roles[MemberRole] {
+		t.Errorf("second user: role %d, %v; want Member (%d)", role, err, roles[MemberRole])
+	}
+}
+
+func TestConfiguredDefaultUserRole(t *testing.T) {
+	service := newTestService(t)
+	roles := seedRoles(t, service, OwnerRole, MemberRole, "Player")
+	service.defaultRole = "Player"
+
+	if role, err := register(t, service, "first", ""); err != nil || role != roles[OwnerRole] {
+		t.Errorf("first user: role %d, %v; want Owner", role, err)
+	}
+	if role, err := register(t, service, "second", ""); err != nil || role != roles["Player"] {
+		t.Errorf("second user: role %d, %v; want the configured Player", role, err)
+	}
+
+	// A configured role that does not exist falls back to Member
+	service.defaultRole = "Missing"
+	if role, err := register(t, service, "third", ""); err != nil || role != roles[MemberRole] {
+		t.Errorf("unknown default role: role %d, %v; want Member", role, err)
+	}
+}
+
+func TestUserRoleFallbacks(t *testing.T) {
+	// Without an Owner role the first user gets the default role
+	service := newTestService(t)
+	roles := seedRoles(t, service, MemberRole)
+	if role, err := register(t, service, "first", ""); err != nil || role != roles[MemberRole] {
+		t.Errorf("no Owner role: role %d, %v; want Member", role, err)
+	}
+
+	// Without a usable role nobody is registered with a made-up id
+	service = newTestService(t)
+	seedRoles(t, service, OwnerRole)
+	register(t, service, "owner", "")
+	service.defaultRole = "Missing"
+	if _, err := register(t, service, "second", ""); !errors.Is(err, ErrRoleNotFound) {
+		t.Errorf("no default or Member role: got %v, want ErrRoleNotFound", err)
+	}
+	var users int64
+	service.db.Model(&AuthUser{}).Count(&users)
+	if users != 1 {
+		t.Errorf("%d users, want only the owner", users)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"text/template"
 	"time"
@@ -18,6 +19,13 @@ import (
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Names of the roles registration hands out
+const (
+	OwnerRole  = "Owner"
+	MemberRole = "Member"
 )
 
 var (
@@ -30,14 +38,28 @@ type AuthService struct {
 	db          *gorm.DB
 	emailSender email.Sender
 	emitter     *emitter.Emitter
+	// deletionMode is profile.DeletionAnonymize or profile.DeletionHardDelete
+	deletionMode string
+	resetTokens  ResetTokenConfig
+	// registrationOpen lets anyone register; otherwise Register requires an
+	// invite, except for the first user
+	registrationOpen bool
+	inviteTTL        time.Duration
+	// defaultRole is the name of the role users after the first one get
+	defaultRole string
 }
 
 // NewAuthService creates a new authentication service
 func NewAuthService(db *gorm.DB, emailSender email.Sender, emitter *emitter.Emitter) *AuthService {
 	return &AuthService{
-		db:          db,
-		emailSender: emailSender,
-		emitter:     emitter,
+		db:               db,
+		emailSender:      emailSender,
+		emitter:          emitter,
+		deletionMode:     profile.DeletionAnonymize,
+		resetTokens:      DefaultResetTokenConfig(),
+		registrationOpen: true,
+		inviteTTL:        DefaultInviteTTL,
+		defaultRole:      MemberRole,
 	}
 }
 
@@ -45,6 +67,15 @@ func (s *AuthService) ValidateKey(key string) (any, error) {
 	return nil, nil
 }
 
+// hashPassword returns the bcrypt hash stored for password
+func hashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hashed), nil
+}
+
 // validateUser checks if username or email already exists
 func (s *AuthService) validateUser(email, username string) error {
 	var count int64
@@ -60,31 +91,49 @@ func (s *AuthService) validateUser(email, username string) error {
 	return nil
 }
 
-func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
+func (s *AuthService) Register(req *RegisterRequest, client ClientInfo) (*AuthResponse, error) {
 	// Validate unique constraints first
 	if err := s.validateUser(req.Email, req.Username); err != nil {
 		return nil, err
 	}
 
-	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	// Determine role: the first user gets Owner, subsequent users the default role
+	roleId, first, err := s.determineUserRole()
 	if err != nil {
-		return nil, fmt.Errorf("failed to hash password: %w", err)
+		return nil, err
 	}
 
-	// Determine role: first user gets Owner (1), subsequent users get Member (3)
-	roleId := s.determineUserRole()
+	// An invite decides the role; without open registration only the first
+	// user may register without one
+	var invite *Invite
+	if req.InviteToken != "" {
+		if invite, err = s.findInvite(req.InviteToken, req.Email); err != nil {
+			return nil, err
+		}
+		if !first {
+			roleId = invite.RoleId
+		}
+	} else if !s.registrationOpen && !first {
+		return nil, ErrRegistrationClosed
+	}
+
+	// Hash password
+	hashedPassword, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
 
 	now := time.Now()
 
 	user := AuthUser{
 		User: profile.User{
 			Email:     req.Email,
-			Password:  string(hashedPassword),
+			Password:  hashedPassword,
 			FirstName: req.FirstName,
 			LastName:  req.LastName,
 			Username:  req.Username,
 			Phone:     req.Phone,
+			Language:  req.Language,
 			RoleId:    roleId,
 		},
 		LastLogin: &now,
@@ -104,17 +153,11 @@ func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	// Get extended data for JWT token
-	extendData := app.Extend(user.User.Id)
-
-	// Generate JWT token
-	token, err := types.GenerateJWT(user.User.Id, extendData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+	if invite != nil {
+		if err := consumeInvite(tx, invite.Id, user.Id); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	}
 
 	userData := types.UserData{
@@ -125,13 +168,36 @@ func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
 		Email:     user.Email,
 	}
 
-	// Emit registration event
+	// Emit registration event; it is persisted, so it commits with the user
 	if s.emitter != nil {
-		s.emitter.Emit("user.registered", userData)
+		if err := s.emitter.EmitTx(tx, "user.registered", userData); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	} else {
 		fmt.Printf("Emitter is nil in AuthService.Register; cannot emit 'user.registered' event")
 	}
 
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Get extended data for JWT token
+	extendData := app.Extend(user.User.Id)
+
+	// Generate JWT token bound to a new session
+	tokenId, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token, err := types.GenerateJWTWithId(user.User.Id, extendData, tokenId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	if err := s.createSession(user.User.Id, tokenId, client, now); err != nil {
+		return nil, err
+	}
+
 	// Send welcome email asynchronously
 	// go func() {
 	// 	if err := s.sendWelcomeEmail(&user); err != nil {
@@ -150,7 +216,7 @@ func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
 	}, nil
 }
 
-func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
+func (s *AuthService) Login(req *LoginRequest, client ClientInfo) (*AuthResponse, error) {
 	var user AuthUser
 	if err := s.db.Where("email = ?", req.Email).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -168,7 +234,11 @@ func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
 
 	// Proceed with generating token and response
 	now := time.Now()
-	token, err := types.GenerateJWT(user.User.Id, extendData)
+	tokenId, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token, err := types.GenerateJWTWithId(user.User.Id, extendData, tokenId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -194,15 +264,20 @@ func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
 		Response:     response,
 	}
 
-	// Emit the login attempt event
-	s.emitter.Emit("user.login_attempt", &event)
+	// Emit the login attempt event; its listeners run before the login
+	// goes on, and a failing one denies it
+	listenerErr := s.emitter.EmitSync("user.login_attempt", &event)
 
 	// Check if login was allowed after event listeners have processed it
-	if !loginAllowed {
-		if event.Error != nil {
-			return event.Response, errors.New(event.Error.Error)
+	if listenerErr != nil || !loginAllowed {
+		// Listeners saw the token, so make sure it cannot be used
+		if err := s.RevokeToken(tokenId, now.Add(24*time.Hour)); err != nil {
+			return nil, err
+		}
+		if listenerErr != nil {
+			return nil, fmt.Errorf("login listener failed: %w", listenerErr)
 		}
-		return event.Response, errors.New("not authorized")
+		return nil, newLoginRejectedError(&event)
 	}
 
 	// Update last login with proper time handling
@@ -213,10 +288,162 @@ func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
 		return nil, fmt.Errorf("failed to update last login: %w", err)
 	}
 
+	// Record the session only once the login has been allowed
+	if err := s.createSession(user.User.Id, tokenId, client, now); err != nil {
+		return nil, err
+	}
+
 	return response, nil
 }
 
+// createSession records the session for a freshly issued token
+func (s *AuthService) createSession(userId uint, tokenId string, client ClientInfo, issuedAt time.Time) error {
+	session := Session{
+		UserId:    userId,
+		TokenId:   tokenId,
+		Device:    deviceFromUserAgent(client.UserAgent),
+		UserAgent: truncate(client.UserAgent, 512),
+		IP:        client.IP,
+		IssuedAt:  issuedAt,
+		ExpiresAt: issuedAt.Add(24 * time.Hour),
+	}
+	if err := s.db.Create(&session).Error; err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// ListSessions returns the user's active (unrevoked, unexpired) sessions, newest first
+func (s *AuthService) ListSessions(userId uint) ([]Session, error) {
+	sessions := []Session{}
+	err := s.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userId, time.Now()).
+		Order("issued_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes one of the user's sessions, invalidating its token
+func (s *AuthService) RevokeSession(userId, sessionId uint) error {
+	var session Session
+	if err := s.db.Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionId, userId).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	if err := s.RevokeToken(session.TokenId, session.ExpiresAt); err != nil {
+		return err
+	}
+
+	s.emitter.EmitAsync("user.session_revoked", sessionId)
+	return nil
+}
+
+// Logout revokes the given access token so it is rejected before it expires
+func (s *AuthService) Logout(tokenString string) error {
+	claims, err := types.ParseJWT(tokenString)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	tokenId, _ := claims["jti"].(string)
+	if tokenId == "" {
+		// Tokens issued without a jti cannot be revoked; they simply expire
+		return nil
+	}
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return ErrInvalidToken
+	}
+
+	if err := s.RevokeToken(tokenId, expiresAt.Time); err != nil {
+		return err
+	}
+
+	if userId, ok := claims["user_id"].(float64); ok {
+		s.emitter.EmitAsync("user.logout", uint(userId))
+	}
+	return nil
+}
+
+// RevokeToken adds a token to the denylist until expiresAt and marks its
+// session, if any, as revoked
+func (s *AuthService) RevokeToken(tokenId string, expiresAt time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return revokeToken(tx, tokenId, expiresAt, time.Now())
+	})
+}
+
+func revokeToken(tx *gorm.DB, tokenId string, expiresAt, now time.Time) error {
+	entry := RevokedToken{TokenId: tokenId, ExpiresAt: expiresAt}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entry).Error; err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	if err := tx.Model(&Session{}).
+		Where("token_id = ? AND revoked_at IS NULL", tokenId).
+		Update("revoked_at", now).Error; err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// IsTokenRevoked reports whether the token with the given jti is on the
+// denylist. Lookup failures count as revoked so tokens fail closed.
+func (s *AuthService) IsTokenRevoked(tokenId string) bool {
+	var count int64
+	err := s.db.Model(&RevokedToken{}).
+		Where("token_id = ?", tokenId).
+		Count(&count).Error
+	return err != nil || count > 0
+}
+
+// PruneRevokedTokens removes denylist entries for tokens that have expired,
+// since those are rejected on their expiry alone
+func (s *AuthService) PruneRevokedTokens() (int64, error) {
+	result := s.db.Where("expires_at <= ?", time.Now()).Delete(&RevokedToken{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune revoked tokens: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// deviceFromUserAgent derives a coarse device label from a User-Agent header
+func deviceFromUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case ua == "":
+		return "Unknown"
+	case strings.Contains(ua, "bot") || strings.Contains(ua, "curl") || strings.Contains(ua, "postman"):
+		return "API client"
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet"):
+		return "Tablet"
+	case strings.Contains(ua, "mobile") || strings.Contains(ua, "iphone") || strings.Contains(ua, "android"):
+		return "Mobile"
+	default:
+		return "Desktop"
+	}
+}
+
+// truncate shortens s to at most max bytes
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
+
+// ForgotPassword stores a reset token for the user and emails it. Without an
+// email sender it returns ErrEmailUnavailable before issuing a token the user
+// could never receive.
 func (s *AuthService) ForgotPassword(email string) error {
+	if s.emailSender == nil {
+		return ErrEmailUnavailable
+	}
+
 	var user AuthUser
 	if err := s.db.Where("email = ?", email).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -225,11 +452,11 @@ func (s *AuthService) ForgotPassword(email string) error {
 		return fmt.Errorf("database error: %w", err)
 	}
 
-	token, err := generateToken()
+	token, lifetime, err := s.resetTokens.generate()
 	if err != nil {
 		return fmt.Errorf("failed to generate token: %w", err)
 	}
-	expiry := time.Now().Add(15 * time.Minute)
+	expiry := time.Now().Add(lifetime)
 
 	// Update reset token fields in transaction
 	tx := s.db.Begin()
@@ -240,6 +467,7 @@ func (s *AuthService) ForgotPassword(email string) error {
 	updates := map[string]any{
 		"reset_token":        token,
 		"reset_token_expiry": sql.NullTime{Time: expiry, Valid: true},
+		"reset_attempts":     0,
 	}
 
 	if err := tx.Model(&user).Updates(updates).Error; err != nil {
@@ -267,12 +495,15 @@ func (s *AuthService) ResetPassword(email, token, newPassword string) error {
 		return fmt.Errorf("database error: %w", err)
 	}
 
-	if user.ResetToken != token {
-		return errors.New("invalid token")
+	if user.ResetTokenExpiry == nil || time.Now().After(*user.ResetTokenExpiry) {
+		return ErrTokenExpired
 	}
 
-	if user.ResetTokenExpiry == nil || time.Now().After(*user.ResetTokenExpiry) {
-		return errors.New("token expired")
+	if !resetTokenMatches(user.ResetToken, token) {
+		if s.resetTokens.IsOTP() {
+			s.recordWrongResetCode(&user)
+		}
+		return ErrInvalidToken
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
@@ -290,6 +521,7 @@ func (s *AuthService) ResetPassword(email, token, newPassword string) error {
 		"password":           string(hashedPassword),
 		"reset_token":        "",
 		"reset_token_expiry": nil,
+		"reset_attempts":     0,
 	}
 
 	if err := tx.Model(&user).Updates(updates).Error; err != nil {
@@ -311,6 +543,20 @@ func (s *AuthService) ResetPassword(email, token, newPassword string) error {
 	return nil
 }
 
+// recordWrongResetCode counts a wrong code and clears the pending reset once
+// otpMaxAttempts is reached, so a new code has to be requested
+func (s *AuthService) recordWrongResetCode(user *AuthUser) {
+	updates := map[string]any{"reset_attempts": gorm.Expr("reset_attempts + 1")}
+	if user.ResetAttempts+1 >= otpMaxAttempts {
+		updates["reset_token"] = ""
+		updates["reset_token_expiry"] = nil
+		updates["reset_attempts"] = 0
+	}
+	if err := s.db.Model(user).Updates(updates).Error; err != nil {
+		fmt.Printf("Failed to record wrong reset code: %v\n", err)
+	}
+}
+
 func generateToken() (string, error) {
 	b := make([]byte, 32)
 	if _, err := rand.Read(b); err != nil {
@@ -320,7 +566,14 @@ func generateToken() (string, error) {
 }
 
 // Email sending functions
-func (s *AuthService) sendEmail(to, subject, title, content string) error {
+
+// sendEmail renders the named template in the user's preferred language
+// (falling back to English) inside the shared layout and sends it
+func (s *AuthService) sendEmail(user *AuthUser, name string, data map[string]any) error {
+	if s.emailSender == nil {
+		return ErrEmailUnavailable
+	}
+
 	var cachedTemplate *template.Template
 	emailTemplateMutex.RLock()
 	cachedTemplate = emailTemplateCache
@@ -339,10 +592,21 @@ func (s *AuthService) sendEmail(to, subject, title, content string) error {
 		cachedTemplate = newTemplate
 	}
 
+	if data == nil {
+		data = make(map[string]any)
+	}
+	data["FirstName"] = user.FirstName
+
+	localized, lang, err := renderEmailContent(user.Language, name, data)
+	if err != nil {
+		return err
+	}
+
 	var body bytes.Buffer
-	err := cachedTemplate.Execute(&body, map[string]any{
-		"Title":   title,
-		"Content": content,
+	err = cachedTemplate.Execute(&body, map[string]any{
+		"Title":   localized.Title,
+		"Content": localized.Content,
+		"Lang":    lang,
 		"Year":    time.Now().Year(),
 	})
 	if err != nil {
@@ -350,9 +614,9 @@ func (s *AuthService) sendEmail(to, subject, title, content string) error {
 	}
 
 	msg := email.Message{
-		To:      []string{to},
+		To:      []string{user.Email},
 		From:    "no-reply@base.al",
-		Subject: subject,
+		Subject: localized.Subject,
 		Body:    body.String(),
 		IsHTML:  true,
 	}
@@ -360,35 +624,41 @@ func (s *AuthService) sendEmail(to, subject, title, content string) error {
 }
 
 func (s *AuthService) sendPasswordResetEmail(user *AuthUser, token string) error {
-	title := "Reset Your Base Password"
-	content := fmt.Sprintf(`
-		<p>Hi %s,</p>
-		<p>You have requested to reset your password. Use the following code to reset your password:</p>
-		<h2>%s</h2>
-		<p>This code will expire in 15 minutes.</p>
-		<p>If you didn't request a password reset, please ignore this email or contact support if you have concerns.</p>
-	`, user.FirstName, token)
-	return s.sendEmail(user.Email, title, title, content)
+	return s.sendEmail(user, EmailPasswordReset, map[string]any{"Token": token})
 }
 
 func (s *AuthService) sendPasswordChangedEmail(user *AuthUser) error {
-	title := "Your Base Password Has Been Changed"
-	content := fmt.Sprintf("<p>Hi %s,</p><p>Your password has been successfully changed. If you did not make this change, please contact support immediately.</p>", user.FirstName)
-	return s.sendEmail(user.Email, title, title, content)
+	return s.sendEmail(user, EmailPasswordChanged, nil)
 }
 
-// determineUserRole returns the appropriate role ID for a new user
-// First user gets Owner role (1), subsequent users get Member role (3)
-func (s *AuthService) determineUserRole() uint {
+// determineUserRole returns the role ID for a new user and whether it is the
+// first user. The first user gets the Owner role, subsequent users the
+// default role. Roles are resolved by name; a default role that does not
+// exist falls back to Member, and the first user falls back to the default
+// role when there is no Owner role.
+func (s *AuthService) determineUserRole() (uint, bool, error) {
 	var userCount int64
 	if err := s.db.Model(&AuthUser{}).Count(&userCount).Error; err != nil {
-		// If we can't count users, default to Member role for safety
-		return 3 // Member role
+		// If we can't count users, never hand out the Owner role
+		userCount = 1
 	}
 
-	// First user gets Owner role, all others get Member role
 	if userCount == 0 {
-		return 1 // Owner role
+		if roleId, err := s.roleIdByName(OwnerRole); err == nil {
+			return roleId, true, nil
+		} else if !errors.Is(err, ErrRoleNotFound) {
+			return 0, false, err
+		}
+		fmt.Printf("Owner role not found; the first user gets the default role\n")
+	}
+
+	roleId, err := s.roleIdByName(s.defaultRole)
+	if errors.Is(err, ErrRoleNotFound) && s.defaultRole != MemberRole {
+		fmt.Printf("Default user role %q not found; falling back to %s\n", s.defaultRole, MemberRole)
+		roleId, err = s.roleIdByName(MemberRole)
+	}
+	if err != nil {
+		return 0, false, err
 	}
-	return 3 // Member role
+	return roleId, userCount == 0, nil
 }
@@ -0,0 +1,153 @@
+package authentication
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"base/core/emitter"
+	"base/core/helper"
+	"base/core/logger"
+	"base/core/router"
+	"base/core/router/middleware"
+	"base/core/types"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// newTestService returns an auth service on a fresh sqlite database whose
+// denylist backs token validation for the duration of the test
+func newTestService(t *testing.T) *AuthService {
+	t.Helper()
+
+	t.Setenv("JWT_SECRET", "authentication-test-secret")
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(&AuthUser{}, &Session{}, &RevokedToken{}, &Invite{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	service := NewAuthService(db, nil, emitter.New())
+	check := types.TokenRevocationCheck
+	types.TokenRevocationCheck = service.IsTokenRevoked
+	t.Cleanup(func() { types.TokenRevocationCheck = check })
+	return service
+}
+
+func TestGenerateJWTHasUniqueTokenId(t *testing.T) {
+	newTestService(t)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		token, err := types.GenerateJWT(1, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		claims, err := types.ParseJWT(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		jti, _ := claims["jti"].(string)
+		if jti == "" || seen[jti] {
+			t.Fatalf("token %d has jti %q, want a new unique one", i, jti)
+		}
+		seen[jti] = true
+	}
+}
+
+func TestLoggedOutTokenIsRejectedBeforeExpiry(t *testing.T) {
+	service := newTestService(t)
+
+	// Protected routes validate tokens the way the app's auth middleware does
+	auth := middleware.DefaultAuthConfig()
+	auth.TokenValidator = func(token string) (any, error) {
+		_, userId, err := helper.ValidateJWT(token)
+		return userId, err
+	}
+
+	r := router.New()
+	NewAuthController(service, nil, logger.NewLoggerFromZap(zap.NewNop()), nil).Routes(r.Group("/api/auth"))
+	r.GET("/api/me", func(c *router.Context) error {
+		return c.JSON(http.StatusOK, map[string]uint{"user_id": c.GetUint("user_id")})
+	}, middleware.Auth(auth))
+
+	send := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	token, err := types.GenerateJWT(7, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := types.GenerateJWT(7, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code := send(http.MethodGet, "/api/me", token); code != http.StatusOK {
+		t.Fatalf("before logout: status %d, want 200", code)
+	}
+	if code := send(http.MethodPost, "/api/auth/logout", token); code != http.StatusOK {
+		t.Fatalf("logout: status %d, want 200", code)
+	}
+
+	claims, err := types.ParseJWT(token)
+	if err != nil {
+		t.Fatalf("logged out token no longer parses: %v", err)
+	}
+	if expiry, _ := claims.GetExpirationTime(); expiry == nil || !expiry.After(time.Now()) {
+		t.Fatal("token expired on its own; the test needs it still valid")
+	}
+	if code := send(http.MethodGet, "/api/me", token); code != http.StatusUnauthorized {
+		t.Errorf("after logout: status %d, want 401", code)
+	}
+	if _, err := types.ValidateJWT(token); !errors.Is(err, types.ErrTokenRevoked) {
+		t.Errorf("ValidateJWT = %v, want ErrTokenRevoked", err)
+	}
+
+	// Other tokens of the same user stay valid
+	if code := send(http.MethodGet, "/api/me", other); code != http.StatusOK {
+		t.Errorf("other token after logout: status %d, want 200", code)
+	}
+}
+
+func TestPruneRevokedTokensKeepsUnexpiredEntries(t *testing.T) {
+	service := newTestService(t)
+
+	if err := service.RevokeToken("expired", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.RevokeToken("active", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	// Revoking twice is harmless
+	if err := service.RevokeToken("active", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("revoking again: %v", err)
+	}
+
+	pruned, err := service.PruneRevokedTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned != 1 {
+		t.Errorf("pruned %d entries, want 1", pruned)
+	}
+	if service.IsTokenRevoked("expired") {
+		t.Error("expired entry was kept")
+	}
+	if !service.IsTokenRevoked("active") {
+		t.Error("entry of a token still valid was pruned")
+	}
+}
@@ -0,0 +1,108 @@
+package authorization
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// auditPage decodes an audit log response
+func auditPage(t *testing.T, api *testAPI, userId uint64, query string) (int, []AuditLog) {
+	t.Helper()
+	w := api.do(t, userId, http.MethodGet, "/api/authorization/audit"+query, "")
+	var response struct {
+		Data []AuditLog `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w.Code, response.Data
+}
+
+func actions(entries []AuditLog) []string {
+	var actions []string
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+	}
+	return actions
+}
+
+func TestAuthorizationChangesAreAudited(t *testing.T) {
+	api := newTestAPI(t)
+	owner := createUser(t, api.module.DB, "Owner")
+	other := createUser(t, api.module.DB, "Owner")
+
+	w := api.do(t, owner, http.MethodPost, "/api/authorization/roles", `{"name":"Editor"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create role: status %d: %s", w.Code, w.Body)
+	}
+	editor := findRole(t, api.module.DB, "Editor")
+	var permission Permission
+	api.module.DB.Where("resource_type = ? AND action = ?", "media", "read").First(&permission)
+	path := fmt.Sprintf("/api/authorization/roles/%d/permissions", editor.Id)
+	if w := api.do(t, other, http.MethodPost, path, fmt.Sprintf(`{"permission_id":"%d"}`, permission.Id)); w.Code != http.StatusOK {
+		t.Fatalf("assign: status %d: %s", w.Code, w.Body)
+	}
+	// Refused changes are not recorded
+	if w := api.do(t, other, http.MethodPost, path, fmt.Sprintf(`{"permission_id":"%d"}`, permission.Id)); w.Code != http.StatusConflict {
+		t.Fatalf("duplicate assign: status %d", w.Code)
+	}
+
+	code, entries := auditPage(t, api, owner, "")
+	if code != http.StatusOK || fmt.Sprint(actions(entries)) != fmt.Sprint([]string{AuditPermissionAssigned, AuditRoleCreated}) {
+		t.Fatalf("status %d, actions %v; want the assignment and the creation, newest first", code, actions(entries))
+	}
+	assigned := entries[0]
+	if assigned.ActorId != uint(other) || assigned.TargetType != "role" || assigned.TargetId != editor.Id || assigned.Details != fmt.Sprintf("permission %d", permission.Id) {
+		t.Errorf("assignment entry = %+v", assigned)
+	}
+
+	if _, entries := auditPage(t, api, owner, fmt.Sprintf("?actor_id=%d", owner)); fmt.Sprint(actions(entries)) != fmt.Sprint([]string{AuditRoleCreated}) {
+		t.Errorf("actor_id filter: %v, want the owner's role creation", actions(entries))
+	}
+	if _, entries := auditPage(t, api, owner, "?action="+AuditPermissionAssigned); len(entries) != 1 || entries[0].ActorId != uint(other) {
+		t.Errorf("action filter: %+v", entries)
+	}
+
+	// Listing the audit log needs role management
+	if code, _ := auditPage(t, api, createUser(t, api.module.DB, "Member"), ""); code != http.StatusForbidden {
+		t.Errorf("member: status %d, want 403", code)
+	}
+}
+
+func TestAuditLogDateRange(t *testing.T) {
+	api := newTestAPI(t)
+	owner := createUser(t, api.module.DB, "Owner")
+	for i, at := range []string{"2024-04-30T23:59:59Z", "2024-05-01T00:00:00Z", "2024-05-31T12:00:00Z", "2024-06-01T00:00:00Z"} {
+		created, _ := time.Parse(time.RFC3339, at)
+		entry := AuditLog{ActorId: uint(owner), Action: AuditRoleUpdated, TargetType: "role", TargetId: uint(i + 1), CreatedAt: created}
+		if err := api.module.DB.Create(&entry).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []uint // Target ids, newest first
+	}{
+		{"?from=2024-05-01T00:00:00Z&to=2024-06-01T00:00:00Z", []uint{3, 2}},
+		{"?from=2024-05-31T14:00:00%2B02:00", []uint{4, 3}},
+		{"?to=2024-05-01T00:00:00Z", []uint{1}},
+	}
+	for _, tt := range tests {
+		code, entries := auditPage(t, api, owner, tt.query)
+		var got []uint
+		for _, entry := range entries {
+			got = append(got, entry.TargetId)
+		}
+		if code != http.StatusOK || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: status %d, entries %v, want %v", tt.query, code, got, tt.want)
+		}
+	}
+
+	for _, query := range []string{"?from=2024-05-01", "?to=yesterday", "?from=2024-06-01T00:00:00Z&to=2024-05-01T00:00:00Z", "?actor_id=abc"} {
+		if code, _ := auditPage(t, api, owner, query); code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, code)
+		}
+	}
+}
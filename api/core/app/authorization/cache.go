@@ -0,0 +1,125 @@
+package authorization
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"base/core/emitter"
+	"base/core/types"
+)
+
+// DefaultPermissionCacheTTL is how long a user's permission set is reused
+// before CheckPermissions loads it again
+const DefaultPermissionCacheTTL = 30 * time.Second
+
+// EventRolePermissionsChanged is emitted with a RolePermissionsChange when
+// the permissions a role grants change, directly or through its parent
+const EventRolePermissionsChanged = "role.permissions_changed"
+
+// RolePermissionsChange is the payload of the role.permissions_changed event
+type RolePermissionsChange struct {
+	RoleId uint `json:"role_id"`
+}
+
+// userPermissionSet is what CheckPermissions needs to know about a user
+type userPermissionSet struct {
+	granted         map[string]bool // permissionKey of role and resource-type permissions
+	resourceGranted map[string]bool // permissionKey plus ":" and the resource id
+	expiresAt       time.Time
+}
+
+// permissionCache holds recently loaded permission sets by user id. It is
+// shared by every AuthorizationService, so a change made through one is seen
+// by all of them.
+var permissionCache = struct {
+	sync.RWMutex
+	ttl     time.Duration
+	entries map[uint64]*userPermissionSet
+}{ttl: DefaultPermissionCacheTTL, entries: make(map[uint64]*userPermissionSet)}
+
+// SetPermissionCacheTTL changes how long permission sets are cached; 0
+// disables the cache. Cached sets are dropped.
+func SetPermissionCacheTTL(ttl time.Duration) {
+	permissionCache.Lock()
+	defer permissionCache.Unlock()
+	permissionCache.ttl = ttl
+	permissionCache.entries = make(map[uint64]*userPermissionSet)
+}
+
+// InvalidateUserPermissions drops the cached permission set of a user
+func InvalidateUserPermissions(userId uint64) {
+	permissionCache.Lock()
+	defer permissionCache.Unlock()
+	delete(permissionCache.entries, userId)
+}
+
+// InvalidateAllPermissions drops every cached permission set, as a role
+// change can affect any number of users
+func InvalidateAllPermissions() {
+	permissionCache.Lock()
+	defer permissionCache.Unlock()
+	permissionCache.entries = make(map[uint64]*userPermissionSet)
+}
+
+func cachedPermissions(userId uint64) *userPermissionSet {
+	permissionCache.RLock()
+	defer permissionCache.RUnlock()
+	set := permissionCache.entries[userId]
+	if set == nil || !time.Now().Before(set.expiresAt) {
+		return nil
+	}
+	return set
+}
+
+func cachePermissions(userId uint64, set *userPermissionSet) {
+	permissionCache.Lock()
+	defer permissionCache.Unlock()
+	if permissionCache.ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	for id, entry := range permissionCache.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(permissionCache.entries, id)
+		}
+	}
+	set.expiresAt = now.Add(permissionCache.ttl)
+	permissionCache.entries[userId] = set
+}
+
+// rolePermissionsChanged drops the cached permission sets after the
+// permissions of roleId changed and tells the other subscribers
+func (s *AuthorizationService) rolePermissionsChanged(roleId uint) {
+	InvalidateAllPermissions()
+	if s.Emitter != nil {
+		if err := s.Emitter.EmitSync(EventRolePermissionsChanged, RolePermissionsChange{RoleId: roleId}); err != nil {
+			fmt.Printf("Role permissions change listener failed: %v\n", err)
+		}
+	}
+}
+
+// subscribeCacheInvalidation drops cached permission sets on the events that
+// change them, including those emitted by other services
+func subscribeCacheInvalidation(e *emitter.Emitter) {
+	e.On(types.EventRoleChanged, func(data any) {
+		switch change := data.(type) {
+		case types.RoleChange:
+			InvalidateUserPermissions(uint64(change.UserId))
+		case *types.RoleChange:
+			InvalidateUserPermissions(uint64(change.UserId))
+		}
+	})
+	e.On(EventRolePermissionsChanged, func(any) {
+		InvalidateAllPermissions()
+	})
+}
+
+// sameRoleId reports whether two optional role ids are equal
+func sameRoleId(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,155 @@
+package authorization
+
+import (
+	"testing"
+	"time"
+
+	"base/core/emitter"
+	"base/core/types"
+)
+
+// useCacheTTL enables the permission cache for the rest of the test
+func useCacheTTL(t *testing.T, ttl time.Duration) {
+	t.Helper()
+	SetPermissionCacheTTL(ttl)
+	t.Cleanup(func() { SetPermissionCacheTTL(0) })
+}
+
+// manageRoles returns the id of the role:manage permission
+func manageRoles(t *testing.T, s *AuthorizationService) uint {
+	t.Helper()
+	var permission Permission
+	if err := s.DB.Where("resource_type = ? AND action = ?", "role", "manage").First(&permission).Error; err != nil {
+		t.Fatal(err)
+	}
+	return permission.Id
+}
+
+func canManageRoles(t *testing.T, s *AuthorizationService, userId uint64) bool {
+	t.Helper()
+	allowed, err := s.HasPermission(userId, "role", "manage")
+	if err != nil {
+		t.Fatalf("HasPermission: %v", err)
+	}
+	return allowed
+}
+
+func TestPermissionCacheServesRepeatedChecks(t *testing.T) {
+	s := newTestModule(t).Service
+	useCacheTTL(t, time.Minute)
+	admin := createUser(t, s.DB, "Administrator")
+	if !canManageRoles(t, s, admin) {
+		t.Fatal("administrator cannot manage roles")
+	}
+
+	// A change behind the service's back is not seen until the set is reloaded
+	s.DB.Where("role_id = ? AND permission_id = ?", findRole(t, s.DB, "Administrator").Id, manageRoles(t, s)).Delete(&RolePermission{})
+	if !canManageRoles(t, s, admin) {
+		t.Error("the check did not use the cached permission set")
+	}
+
+	// Sensitive checks bypass the cache and refresh it
+	fresh, err := s.CheckPermissionsFresh(admin, []PermissionCheckItem{{ResourceType: "role", Action: "manage"}})
+	if err != nil || fresh[0] {
+		t.Errorf("CheckPermissionsFresh = %v, %v; want the revoked permission denied", fresh, err)
+	}
+	if canManageRoles(t, s, admin) {
+		t.Error("the cache was not refreshed by the fresh check")
+	}
+}
+
+func TestPermissionCacheExpires(t *testing.T) {
+	s := newTestModule(t).Service
+	useCacheTTL(t, 20*time.Millisecond)
+	admin := createUser(t, s.DB, "Administrator")
+	canManageRoles(t, s, admin)
+
+	s.DB.Where("role_id = ? AND permission_id = ?", findRole(t, s.DB, "Administrator").Id, manageRoles(t, s)).Delete(&RolePermission{})
+	time.Sleep(30 * time.Millisecond)
+	if canManageRoles(t, s, admin) {
+		t.Error("an expired permission set was used")
+	}
+}
+
+func TestPermissionCacheInvalidatedOnRoleChange(t *testing.T) {
+	s := newTestModule(t).Service
+	useCacheTTL(t, time.Minute)
+	e := emitter.New()
+	subscribeCacheInvalidation(e)
+
+	for _, payload := range []func(uint) any{
+		func(id uint) any { return types.RoleChange{UserId: id} },
+		func(id uint) any { return &types.RoleChange{UserId: id} },
+	} {
+		user := createUser(t, s.DB, "Viewer")
+		if canManageRoles(t, s, user) {
+			t.Fatal("viewer can manage roles")
+		}
+		s.DB.Exec("UPDATE users SET role_id = ? WHERE id = ?", findRole(t, s.DB, "Owner").Id, user)
+		if canManageRoles(t, s, user) {
+			t.Fatal("the check did not use the cached permission set")
+		}
+
+		if err := e.EmitSync(types.EventRoleChanged, payload(uint(user))); err != nil {
+			t.Fatal(err)
+		}
+		if !canManageRoles(t, s, user) {
+			t.Errorf("%T: the new role was not seen after user.role_changed", payload(0))
+		}
+	}
+}
+
+func TestPermissionCacheInvalidatedByPermissionChanges(t *testing.T) {
+	s := newTestModule(t).Service
+	useCacheTTL(t, time.Minute)
+	s.Emitter = emitter.New()
+	var changed []uint
+	s.Emitter.On(EventRolePermissionsChanged, func(data any) {
+		changed = append(changed, data.(RolePermissionsChange).RoleId)
+	})
+
+	role := findRole(t, s.DB, "Administrator")
+	admin := createUser(t, s.DB, "Administrator")
+	permission := manageRoles(t, s)
+	canManageRoles(t, s, admin)
+
+	if err := s.RevokePermissionFromRole(uint64(role.Id), uint64(permission)); err != nil {
+		t.Fatal(err)
+	}
+	if canManageRoles(t, s, admin) {
+		t.Error("a revoked permission was served from the cache")
+	}
+	if err := s.AssignPermissionToRole(uint64(role.Id), uint64(permission)); err != nil {
+		t.Fatal(err)
+	}
+	if !canManageRoles(t, s, admin) {
+		t.Error("an assigned permission was not seen")
+	}
+	if len(changed) != 2 || changed[0] != role.Id || changed[1] != role.Id {
+		t.Errorf("role.permissions_changed emitted for %v, want the administrator role twice", changed)
+	}
+
+	// A resource grant invalidates its user
+	viewer := createUser(t, s.DB, "Viewer")
+	canEdit := func() bool {
+		allowed, err := s.HasResourcePermission(viewer, "role", "7", "manage")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return allowed
+	}
+	canEdit()
+	grant := &ResourcePermission{ResourceType: "role", ResourceId: "7", Action: "manage", UserId: uint(viewer)}
+	if err := s.CreateResourcePermission(grant); err != nil {
+		t.Fatal(err)
+	}
+	if !canEdit() {
+		t.Error("a new resource grant was not seen")
+	}
+	if err := s.DeleteResourcePermission(uint64(grant.Id)); err != nil {
+		t.Fatal(err)
+	}
+	if canEdit() {
+		t.Error("a deleted resource grant was served from the cache")
+	}
+}
@@ -0,0 +1,70 @@
+package authorization
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"base/core/router"
+	"base/core/types"
+)
+
+func TestHasPermission(t *testing.T) {
+	m := newTestModule(t)
+	owner := createUser(t, m.DB, "Owner")
+	viewer := createUser(t, m.DB, "Viewer")
+
+	tests := []struct {
+		name   string
+		userId uint64
+		want   bool
+	}{
+		{"owner", owner, true},
+		{"viewer", viewer, false},
+		{"unknown user", 999, false},
+	}
+	for _, tt := range tests {
+		got, err := m.Service.HasPermission(tt.userId, "role", "manage")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: HasPermission(role, manage) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRoleManagementRoutesRequireManagePermission(t *testing.T) {
+	t.Setenv("JWT_SECRET", "route-guard-test-secret")
+	m := newTestModule(t)
+
+	r := router.New()
+	m.Controller.Routes(r.Group("/api"))
+
+	tests := []struct {
+		role string
+		want int
+	}{
+		{"Owner", http.StatusOK},
+		{"Administrator", http.StatusOK},
+		{"Member", http.StatusForbidden},
+		{"Viewer", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		token, err := types.GenerateJWT(uint(createUser(t, m.DB, tt.role)), nil)
+		if err != nil {
+			t.Fatalf("generate token: %v", err)
+		}
+
+		for _, path := range []string{"/api/authorization/roles", "/api/authorization/permissions"} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("%s GET %s: status %d, want %d", tt.role, path, w.Code, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package authorization
+
+import (
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// crudResourceTypes each get a permission per crudActions entry
+var (
+	crudResourceTypes = []string{"user", "authorization", "media", "profile"}
+	crudActions       = []string{"create", "read", "update", "delete", "list"}
+)
+
+// specialPermissions are the declared permissions outside the CRUD set
+var specialPermissions = []Permission{
+	{
+		Name:         "Manage Roles",
+		Description:  "Create, update, and delete roles",
+		ResourceType: "role",
+		Action:       "manage",
+	},
+	{
+		Name:         "Assign Permissions",
+		Description:  "Assign permissions to roles",
+		ResourceType: "permission",
+		Action:       "assign",
+	},
+	{
+		Name:         "View Game Analytics",
+		Description:  "View aggregate player metrics of games",
+		ResourceType: "game",
+		Action:       "analytics",
+	},
+}
+
+var registered = struct {
+	sync.Mutex
+	permissions []Permission
+}{}
+
+// RegisterPermissions declares permissions of a module, so they are seeded
+// with the defaults and survive pruning. Later declarations of the same
+// resource_type and action replace earlier ones. A module retiring a feature
+// declares its permissions with Deprecated set rather than dropping them.
+func RegisterPermissions(permissions ...Permission) {
+	registered.Lock()
+	defer registered.Unlock()
+	registered.permissions = append(registered.permissions, permissions...)
+}
+
+// DeclaredPermissions returns the permissions the code declares: the CRUD
+// permissions of the core resources, the special permissions and those
+// registered by modules, one per resource_type and action
+func DeclaredPermissions() []Permission {
+	var permissions []Permission
+	for _, resourceType := range crudResourceTypes {
+		for _, action := range crudActions {
+			permissions = append(permissions, Permission{
+				Name:         resourceType + " " + action,
+				Description:  "Allows " + action + " operations on " + resourceType,
+				ResourceType: resourceType,
+				Action:       action,
+			})
+		}
+	}
+	permissions = append(permissions, specialPermissions...)
+
+	registered.Lock()
+	permissions = append(permissions, registered.permissions...)
+	registered.Unlock()
+
+	// Keep the first position of each pair with its last declaration
+	index := make(map[string]int, len(permissions))
+	result := make([]Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		key := permission.ResourceType + ":" + permission.Action
+		if i, ok := index[key]; ok {
+			result[i] = permission
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, permission)
+	}
+	return result
+}
+
+// UndeclaredPolicy is what ReconcilePermissions does with the permissions the
+// code no longer declares, including those created through the API
+type UndeclaredPolicy int
+
+const (
+	// KeepUndeclared leaves them as they are
+	KeepUndeclared UndeclaredPolicy = iota
+	// DeprecateUndeclared marks them deprecated; roles keep them
+	DeprecateUndeclared
+	// PruneUndeclared deletes them along with their role and resource grants
+	PruneUndeclared
+)
+
+// PermissionSyncResult counts the changes made by ReconcilePermissions
+type PermissionSyncResult struct {
+	Added      int `json:"added"`
+	Updated    int `json:"updated"` // Name, description or deprecation changed
+	Deprecated int `json:"deprecated"`
+	Pruned     int `json:"pruned"`
+}
+
+// ReconcilePermissions aligns the permissions table with DeclaredPermissions.
+// Missing permissions are created and existing ones, matched by resource_type
+// and action, take the declared name, description and deprecation. The
+// permissions the code no longer declares are handled by undeclared.
+func (s *AuthorizationService) ReconcilePermissions(undeclared UndeclaredPolicy) (PermissionSyncResult, error) {
+	var result PermissionSyncResult
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var existing []Permission
+		if err := tx.Find(&existing).Error; err != nil {
+			return err
+		}
+		byKey := make(map[string]Permission, len(existing))
+		for _, permission := range existing {
+			byKey[permission.ResourceType+":"+permission.Action] = permission
+		}
+
+		declared := make(map[string]bool)
+		for _, permission := range DeclaredPermissions() {
+			key := permission.ResourceType + ":" + permission.Action
+			declared[key] = true
+
+			current, ok := byKey[key]
+			if !ok {
+				if err := tx.Create(&permission).Error; err != nil {
+					return fmt.Errorf("failed to create permission %s: %w", key, err)
+				}
+				result.Added++
+				continue
+			}
+			if current.Name == permission.Name && current.Description == permission.Description && current.Deprecated == permission.Deprecated {
+				continue
+			}
+			err := tx.Model(&current).Updates(map[string]any{
+				"name":        permission.Name,
+				"description": permission.Description,
+				"deprecated":  permission.Deprecated,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update permission %s: %w", key, err)
+			}
+			result.Updated++
+		}
+
+		if undeclared == KeepUndeclared {
+			return nil
+		}
+
+		var stale []uint
+		for key, permission := range byKey {
+			if !declared[key] {
+				stale = append(stale, permission.Id)
+			}
+		}
+		// Duplicates of a declared pair are stale too
+		for _, permission := range existing {
+			if byKey[permission.ResourceType+":"+permission.Action].Id != permission.Id {
+				stale = append(stale, permission.Id)
+			}
+		}
+		if len(stale) == 0 {
+			return nil
+		}
+
+		if undeclared == DeprecateUndeclared {
+			deprecated := tx.Model(&Permission{}).Where("id IN ? AND deprecated = ?", stale, false).Update("deprecated", true)
+			if deprecated.Error != nil {
+				return fmt.Errorf("failed to deprecate permissions: %w", deprecated.Error)
+			}
+			result.Deprecated = int(deprecated.RowsAffected)
+			return nil
+		}
+
+		if err := tx.Where("permission_id IN ?", stale).Delete(&RolePermission{}).Error; err != nil {
+			return fmt.Errorf("failed to revoke pruned permissions: %w", err)
+		}
+		if err := tx.Where("permission_id IN ?", stale).Delete(&ResourcePermission{}).Error; err != nil {
+			return fmt.Errorf("failed to revoke pruned permissions: %w", err)
+		}
+		pruned := tx.Where("id IN ?", stale).Delete(&Permission{})
+		if pruned.Error != nil {
+			return fmt.Errorf("failed to prune permissions: %w", pruned.Error)
+		}
+		result.Pruned = int(pruned.RowsAffected)
+		return nil
+	})
+	if err != nil {
+		return PermissionSyncResult{}, err
+	}
+	if result.Pruned > 0 {
+		InvalidateAllPermissions()
+	}
+
+	return result, nil
+}
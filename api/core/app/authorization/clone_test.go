@@ -0,0 +1,128 @@
+package authorization
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// rolePermissionIds returns the sorted permission ids assigned to roleId
+func rolePermissionIds(t *testing.T, db *gorm.DB, roleId uint) []uint {
+	t.Helper()
+	var ids []uint
+	if err := db.Model(&RolePermission{}).Where("role_id = ?", roleId).Pluck("permission_id", &ids).Error; err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func findRole(t *testing.T, db *gorm.DB, name string) *Role {
+	t.Helper()
+	var role Role
+	if err := db.Where("name = ?", name).First(&role).Error; err != nil {
+		t.Fatalf("find role %s: %v", name, err)
+	}
+	return &role
+}
+
+func TestCloneRoleCopiesPermissions(t *testing.T) {
+	s := newTestModule(t).Service
+	source := findRole(t, s.DB, "Administrator")
+	want := rolePermissionIds(t, s.DB, source.Id)
+	if len(want) == 0 {
+		t.Fatal("Administrator has no permissions to clone")
+	}
+
+	clone, err := s.CloneRole(uint64(source.Id), "")
+	if err != nil {
+		t.Fatalf("CloneRole: %v", err)
+	}
+	if clone.Name != "Administrator Copy" || clone.IsSystem || clone.Description != source.Description {
+		t.Errorf("clone = %+v, want a non-system Administrator Copy with the same description", clone)
+	}
+	if clone.PermissionCount != len(want) {
+		t.Errorf("PermissionCount = %d, want %d", clone.PermissionCount, len(want))
+	}
+	if got := rolePermissionIds(t, s.DB, clone.Id); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("clone permissions %v, want %v", got, want)
+	}
+
+	// The clone is independent of its source
+	if err := s.RevokePermissionFromRole(uint64(clone.Id), uint64(want[0])); err != nil {
+		t.Fatal(err)
+	}
+	if got := rolePermissionIds(t, s.DB, source.Id); len(got) != len(want) {
+		t.Errorf("removing from the clone changed the source to %v", got)
+	}
+
+	// Default names are numbered once taken; given names are not
+	if second, err := s.CloneRole(uint64(source.Id), ""); err != nil || second.Name != "Administrator Copy 2" {
+		t.Errorf("second clone = %v, %v; want Administrator Copy 2", second, err)
+	}
+	if named, err := s.CloneRole(uint64(source.Id), "  Moderator "); err != nil || named.Name != "Moderator" {
+		t.Errorf("named clone = %v, %v; want Moderator", named, err)
+	}
+	if _, err := s.CloneRole(uint64(source.Id), "Moderator"); !errors.Is(err, ErrDuplicateRole) {
+		t.Errorf("taken name: got %v, want ErrDuplicateRole", err)
+	}
+	if _, err := s.CloneRole(9999, ""); !errors.Is(err, ErrRoleNotFound) {
+		t.Errorf("missing source: got %v, want ErrRoleNotFound", err)
+	}
+}
+
+func TestCloneRoleRoute(t *testing.T) {
+	api := newTestAPI(t)
+	owner := createUser(t, api.module.DB, "Owner")
+	source := findRole(t, api.module.DB, "Member")
+	path := fmt.Sprintf("/api/authorization/roles/%d/clone", source.Id)
+
+	// Without a body the default name is used
+	w := api.do(t, owner, http.MethodPost, path, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("clone: status %d: %s", w.Code, w.Body)
+	}
+	var response struct {
+		Data struct {
+			Id              uint   `json:"id"`
+			Name            string `json:"name"`
+			PermissionCount int    `json:"permission_count"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	want := rolePermissionIds(t, api.module.DB, source.Id)
+	if response.Data.Name != "Member Copy" || response.Data.PermissionCount != len(want) {
+		t.Errorf("clone = %+v, want Member Copy with %d permissions", response.Data, len(want))
+	}
+	if got := rolePermissionIds(t, api.module.DB, response.Data.Id); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("clone permissions %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		path, body string
+		want       int
+	}{
+		{path, `{"name":"Editor"}`, http.StatusCreated},
+		{path, `{"name":"Editor"}`, http.StatusConflict},
+		{path, `{"name":`, http.StatusBadRequest},
+		{"/api/authorization/roles/9999/clone", "", http.StatusNotFound},
+		{"/api/authorization/roles/abc/clone", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if w := api.do(t, owner, http.MethodPost, tt.path, tt.body); w.Code != tt.want {
+			t.Errorf("POST %s %s: status %d, want %d", tt.path, tt.body, w.Code, tt.want)
+		}
+	}
+
+	// Cloning needs role management
+	if w := api.do(t, createUser(t, api.module.DB, "Member"), http.MethodPost, path, ""); w.Code != http.StatusForbidden {
+		t.Errorf("member cloning a role: status %d, want 403", w.Code)
+	}
+}
@@ -3,8 +3,11 @@ package authorization
 import (
 	"base/core/logger"
 	"base/core/router"
+	"base/core/router/middleware"
 	"base/core/types"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 )
@@ -26,32 +29,47 @@ func NewAuthorizationController(service *AuthorizationService, logger logger.Log
 // Routes registers routes for the authorization controller
 func (c *AuthorizationController) Routes(router *router.RouterGroup) {
 	c.Logger.Info("Setting up authorization routes")
-	authzRoutes := router.Group("/authorization")
+	authzRoutes := router.Group("/authorization", middleware.Authenticate(c.Service))
 	{
+		// Everything but the permission checks needs the role manage permission
+		manageRoutes := authzRoutes.Group("", Can("manage", "role"))
+
 		c.Logger.Info("Registering authorization role management routes")
 		// Role management
-		authzRoutes.GET("/roles", c.GetRoles)
-		authzRoutes.GET("/roles/:id", c.GetRole)
-		authzRoutes.POST("/roles", c.CreateRole)
-		authzRoutes.PUT("/roles/:id", c.UpdateRole)
-		authzRoutes.DELETE("/roles/:id", c.DeleteRole)
+		manageRoutes.GET("/roles", c.GetRoles)
+		manageRoutes.GET("/roles/:id", c.GetRole)
+		manageRoutes.POST("/roles", c.CreateRole)
+		manageRoutes.PUT("/roles/:id", c.UpdateRole)
+		manageRoutes.DELETE("/roles/:id", c.DeleteRole)
 
 		// Permission management
-		authzRoutes.GET("/permissions", c.GetPermissions)
+		manageRoutes.GET("/permissions", c.GetPermissions)
 
 		// Role-permission management
-		authzRoutes.GET("/roles/:id/permissions", c.GetRolePermissions)
-		authzRoutes.PUT("/roles/:id/permissions", c.UpdateRolePermissions)
-		authzRoutes.POST("/roles/:id/permissions", c.AssignPermission)
-		authzRoutes.DELETE("/roles/:id/permissions/:permissionId", c.RevokePermission)
+		manageRoutes.GET("/roles/:id/permissions", c.GetRolePermissions)
+		manageRoutes.GET("/roles/:id/effective-permissions", c.GetEffectivePermissions)
+		manageRoutes.PUT("/roles/:id/permissions", c.UpdateRolePermissions)
+		manageRoutes.POST("/roles/:id/permissions", c.AssignPermission)
+		manageRoutes.DELETE("/roles/:id/permissions/:permissionId", c.RevokePermission)
+		manageRoutes.POST("/roles/:id/apply-preset", c.ApplyPreset)
+		manageRoutes.POST("/roles/:id/clone", c.CloneRole)
+
+		// Permission presets
+		manageRoutes.GET("/presets", c.GetPresets)
 
 		// Resource permissions
-		authzRoutes.POST("/resource-permissions", c.CreateResourcePermission)
-		authzRoutes.DELETE("/resource-permissions/:id", c.DeleteResourcePermission)
+		manageRoutes.POST("/resource-permissions", c.CreateResourcePermission)
+		manageRoutes.DELETE("/resource-permissions/:id", c.DeleteResourcePermission)
+
+		// Audit log of the changes made through these routes
+		manageRoutes.GET("/audit", c.GetAuditLogs)
+
+		// Only an Owner can hand the Owner role over
+		authzRoutes.POST("/transfer-ownership", c.TransferOwnership, RequireAnyRole(c.Service, OwnerRoleName))
 
 		// Permission checks
 		authzRoutes.POST("/check", c.CheckPermission)
-
+		authzRoutes.POST("/check-batch", c.CheckPermissionBatch)
 	}
 	c.Logger.Info("Authorization routes registered successfully")
 }
@@ -130,26 +148,79 @@ func (c *AuthorizationController) GetRole(ctx *router.Context) error {
 
 // CreateRole creates a new role
 // @Summary Create a new role
-// @Description Creates a new role with the provided information
+// @Description Creates a new role with the provided information. When preset is set, the preset's
+// @Description permissions are assigned in the same transaction and returned as permissions.
 // @Tags Core/Authorization
 // @Security BearerAuth
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
-// @Param role body Role true "Role object to be created"
-// @Success 201 {object} object{data=Role} "Role created successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid role data"
+// @Param role body CreateRoleRequest true "Role object to be created"
+// @Success 201 {object} object{data=Role,permissions=[]Permission} "Role created successfully"
+// @Failure 400 {object} types.ErrorResponse "Invalid role data, unknown preset or invalid parent role"
+// @Failure 409 {object} types.ErrorResponse "Role name already exists"
 // @Failure 500 {object} types.ErrorResponse "Internal server error"
 // @Router /authorization/roles [post]
 func (c *AuthorizationController) CreateRole(ctx *router.Context) error {
-	var role Role
-	if err := ctx.ShouldBindJSON(&role); err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid role data: " + err.Error(),
+	var request CreateRoleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid role data: "+err.Error()))
+	}
+
+	role := Role{
+		Name:        request.Name,
+		Description: request.Description,
+		IsSystem:    request.IsSystem,
+	}
+
+	if request.Preset != "" {
+		permissions, err := c.Service.CreateRoleFromPreset(&role, request.Preset)
+		if err != nil {
+			if errors.Is(err, ErrPresetNotFound) {
+				return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
+					Error: "Unknown permission preset: " + request.Preset,
+				})
+			}
+			if errors.Is(err, ErrDuplicateRole) {
+				return ctx.JSON(http.StatusConflict, types.ErrorResponse{
+					Error: "Role name already exists",
+				})
+			}
+			if errors.Is(err, ErrInvalidParentRole) {
+				return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
+					Error: "Parent role does not exist or would create a cycle",
+				})
+			}
+
+			c.Logger.Error("Error creating role from preset",
+				logger.String("error", err.Error()),
+				logger.String("role_name", role.Name),
+				logger.String("preset", request.Preset))
+
+			return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error: "Failed to create role: " + err.Error(),
+			})
+		}
+
+		c.audit(ctx, AuditRoleCreated, "role", role.Id, "preset "+request.Preset)
+		return ctx.JSON(http.StatusCreated, map[string]any{
+			"data":        role,
+			"permissions": permissions,
 		})
 	}
 
 	if err := c.Service.CreateRole(&role); err != nil {
+		if errors.Is(err, ErrDuplicateRole) {
+			return ctx.JSON(http.StatusConflict, types.ErrorResponse{
+				Error: "Role name already exists",
+			})
+		}
+		if errors.Is(err, ErrInvalidParentRole) {
+			return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error: "Parent role does not exist or would create a cycle",
+			})
+		}
+
 		c.Logger.Error("Error creating role",
 			logger.String("error", err.Error()),
 			logger.String("role_name", role.Name))
@@ -159,6 +230,7 @@ func (c *AuthorizationController) CreateRole(ctx *router.Context) error {
 		})
 	}
 
+	c.audit(ctx, AuditRoleCreated, "role", role.Id, "")
 	return ctx.JSON(http.StatusCreated, map[string]any{
 		"data": role,
 	})
@@ -175,9 +247,10 @@ func (c *AuthorizationController) CreateRole(ctx *router.Context) error {
 // @Param id path string true "Role Id"
 // @Param role body Role true "Updated role object"
 // @Success 200 {object} object{data=Role} "Role updated successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid role data"
+// @Failure 400 {object} types.ErrorResponse "Invalid role data or invalid parent role"
 // @Failure 403 {object} types.ErrorResponse "System role cannot be modified"
 // @Failure 404 {object} types.ErrorResponse "Role not found"
+// @Failure 409 {object} types.ErrorResponse "Role name already exists"
 // @Failure 500 {object} types.ErrorResponse "Internal server error"
 // @Router /authorization/roles/{id} [put]
 func (c *AuthorizationController) UpdateRole(ctx *router.Context) error {
@@ -191,9 +264,7 @@ func (c *AuthorizationController) UpdateRole(ctx *router.Context) error {
 
 	var role Role
 	if err := ctx.ShouldBindJSON(&role); err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid role data: " + err.Error(),
-		})
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid role data: "+err.Error()))
 	}
 
 	role.Id = uint(roleIdInt)
@@ -208,6 +279,14 @@ func (c *AuthorizationController) UpdateRole(ctx *router.Context) error {
 			return ctx.JSON(http.StatusForbidden, types.ErrorResponse{
 				Error: "System roles cannot be modified",
 			})
+		case ErrDuplicateRole:
+			return ctx.JSON(http.StatusConflict, types.ErrorResponse{
+				Error: "Role name already exists",
+			})
+		case ErrInvalidParentRole:
+			return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error: "Parent role does not exist or would create a cycle",
+			})
 		}
 
 		c.Logger.Error("Error updating role",
@@ -219,6 +298,7 @@ func (c *AuthorizationController) UpdateRole(ctx *router.Context) error {
 		})
 	}
 
+	c.audit(ctx, AuditRoleUpdated, "role", role.Id, "")
 	return ctx.JSON(http.StatusOK, map[string]any{
 		"data": role,
 	})
@@ -268,6 +348,7 @@ func (c *AuthorizationController) DeleteRole(ctx *router.Context) error {
 		})
 	}
 
+	c.audit(ctx, AuditRoleDeleted, "role", uint(roleIdUint), "")
 	return ctx.JSON(http.StatusOK, map[string]any{
 		"success": true,
 	})
@@ -275,18 +356,20 @@ func (c *AuthorizationController) DeleteRole(ctx *router.Context) error {
 
 // GetPermissions returns all permissions in the system
 // @Summary Get all permissions
-// @Description Get all permissions in the system
+// @Description Get all permissions in the system. Deprecated permissions, which can no longer be assigned, are only listed with include_deprecated=true.
 // @Tags Core/Authorization
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
+// @Param include_deprecated query bool false "Also list deprecated permissions"
 // @Success 200 {object} object{data=[]Permission} "Successful operation"
 // @Failure 500 {object} types.ErrorResponse "Internal server error"
 // @Router /authorization/permissions [get]
 func (c *AuthorizationController) GetPermissions(ctx *router.Context) error {
 	c.Logger.Info("Fetching all permissions")
 
-	permissions, err := c.Service.GetPermissions()
+	includeDeprecated, _ := strconv.ParseBool(ctx.Query("include_deprecated"))
+	permissions, err := c.Service.GetPermissions(includeDeprecated)
 	if err != nil {
 		c.Logger.Error("Error getting permissions",
 			logger.String("error", err.Error()))
@@ -345,9 +428,56 @@ func (c *AuthorizationController) GetRolePermissions(ctx *router.Context) error
 	})
 }
 
+// GetEffectivePermissions returns a role's permissions including inherited ones
+// @Summary Get effective permissions for a role
+// @Description Merges the role's own permissions with those of its parent role and all further ancestors.
+// @Description Each permission appears once; inherited is false when the role holds it directly, otherwise
+// @Description source_role_id names the nearest ancestor that holds it. Inheritance cycles are ignored.
+// @Tags Core/Authorization
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Role Id"
+// @Success 200 {object} object{data=[]EffectivePermission} "Successful operation"
+// @Failure 400 {object} types.ErrorResponse "Invalid role Id"
+// @Failure 404 {object} types.ErrorResponse "Role not found"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Router /authorization/roles/{id}/effective-permissions [get]
+func (c *AuthorizationController) GetEffectivePermissions(ctx *router.Context) error {
+	roleId := ctx.Param("id")
+	roleIdUint, err := strconv.ParseUint(roleId, 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error: "Invalid role Id: " + err.Error(),
+		})
+	}
+
+	permissions, err := c.Service.GetEffectivePermissions(roleIdUint)
+	if err != nil {
+		if errors.Is(err, ErrRoleNotFound) {
+			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error: "Role not found",
+			})
+		}
+
+		c.Logger.Error("Error getting effective role permissions",
+			logger.String("error", err.Error()),
+			logger.String("role_id", roleId))
+
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error: "Failed to retrieve permissions",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"data": permissions,
+	})
+}
+
 // UpdateRolePermissions updates all permissions for a role (bulk update)
 // @Summary Update all permissions for a role
-// @Description Replaces all permissions for a role with the provided list
+// @Description Replaces all permissions for a role with the provided list. Deprecated permissions may only be kept, not added.
 // @Tags Core/Authorization
 // @Security BearerAuth
 // @Security ApiKeyAuth
@@ -356,8 +486,8 @@ func (c *AuthorizationController) GetRolePermissions(ctx *router.Context) error
 // @Param id path string true "Role Id"
 // @Param permissions body object{permission_ids=[]int} true "List of permission IDs to assign"
 // @Success 200 {object} object{success=boolean} "Permissions updated successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid request data"
-// @Failure 404 {object} types.ErrorResponse "Role not found"
+// @Failure 400 {object} types.ErrorResponse "Invalid request data or deprecated permission"
+// @Failure 404 {object} types.ErrorResponse "Role or permission not found"
 // @Failure 500 {object} types.ErrorResponse "Internal server error"
 // @Router /authorization/roles/{id}/permissions [put]
 func (c *AuthorizationController) UpdateRolePermissions(ctx *router.Context) error {
@@ -374,9 +504,7 @@ func (c *AuthorizationController) UpdateRolePermissions(ctx *router.Context) err
 	}
 
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid request: " + err.Error(),
-		})
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid request: "+err.Error()))
 	}
 
 	// Convert int slice to uint64 slice
@@ -391,6 +519,14 @@ func (c *AuthorizationController) UpdateRolePermissions(ctx *router.Context) err
 			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{
 				Error: "Role not found",
 			})
+		case ErrPermissionNotFound:
+			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error: "Permission not found",
+			})
+		case ErrPermissionDeprecated:
+			return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error: "Deprecated permissions cannot be assigned",
+			})
 		}
 
 		c.Logger.Error("Error updating role permissions",
@@ -402,14 +538,148 @@ func (c *AuthorizationController) UpdateRolePermissions(ctx *router.Context) err
 		})
 	}
 
+	c.audit(ctx, AuditRolePermissionsUpdated, "role", uint(roleIdUint), fmt.Sprintf("permissions %v", permissionIds))
 	return ctx.JSON(http.StatusOK, map[string]any{
 		"success": true,
 	})
 }
 
+// ApplyPreset assigns a permission preset to a role
+// @Summary Apply permission preset to role
+// @Description Assigns every permission of the preset to the role in one transaction, keeping the permissions it already has
+// @Tags Core/Authorization
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Role Id"
+// @Param preset body ApplyPresetRequest true "Preset to apply"
+// @Success 200 {object} object{data=[]Permission} "Role permissions after applying the preset"
+// @Failure 400 {object} types.ErrorResponse "Invalid request or unknown preset"
+// @Failure 404 {object} types.ErrorResponse "Role not found"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Router /authorization/roles/{id}/apply-preset [post]
+func (c *AuthorizationController) ApplyPreset(ctx *router.Context) error {
+	roleId := ctx.Param("id")
+	roleIdUint, err := strconv.ParseUint(roleId, 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error: "Invalid role Id: " + err.Error(),
+		})
+	}
+
+	var request ApplyPresetRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid request: "+err.Error()))
+	}
+
+	permissions, err := c.Service.ApplyPreset(roleIdUint, request.Preset)
+	if err != nil {
+		switch err {
+		case ErrPresetNotFound:
+			return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error: "Unknown permission preset: " + request.Preset,
+			})
+		case ErrRoleNotFound:
+			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error: "Role not found",
+			})
+		}
+
+		c.Logger.Error("Error applying permission preset",
+			logger.String("error", err.Error()),
+			logger.String("role_id", roleId),
+			logger.String("preset", request.Preset))
+
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error: "Failed to apply permission preset",
+		})
+	}
+
+	c.audit(ctx, AuditRolePresetApplied, "role", uint(roleIdUint), "preset "+request.Preset)
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"data": permissions,
+	})
+}
+
+// CloneRole duplicates a role with its permissions
+// @Summary Clone a role
+// @Description Creates a non-system role with the source role's description, parent role and permissions.
+// @Description The name defaults to the source name suffixed with "Copy" (numbered when taken).
+// @Tags Core/Authorization
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Source role Id"
+// @Param role body CloneRoleRequest false "Name of the new role"
+// @Success 201 {object} object{data=Role} "Role cloned; permission_count is the number of copied permissions"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 404 {object} types.ErrorResponse "Role not found"
+// @Failure 409 {object} types.ErrorResponse "Role name already exists"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Router /authorization/roles/{id}/clone [post]
+func (c *AuthorizationController) CloneRole(ctx *router.Context) error {
+	roleId := ctx.Param("id")
+	roleIdUint, err := strconv.ParseUint(roleId, 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error: "Invalid role Id: " + err.Error(),
+		})
+	}
+
+	// The body is optional
+	var request CloneRoleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid request: "+err.Error()))
+	}
+
+	role, err := c.Service.CloneRole(roleIdUint, request.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRoleNotFound):
+			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{
+				Error: "Role not found",
+			})
+		case errors.Is(err, ErrDuplicateRole):
+			return ctx.JSON(http.StatusConflict, types.ErrorResponse{
+				Error: "Role name already exists",
+			})
+		}
+
+		c.Logger.Error("Error cloning role",
+			logger.String("error", err.Error()),
+			logger.String("role_id", roleId))
+
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error: "Failed to clone role",
+		})
+	}
+
+	c.audit(ctx, AuditRoleCloned, "role", role.Id, fmt.Sprintf("from role %d", roleIdUint))
+	return ctx.JSON(http.StatusCreated, map[string]any{
+		"data": role,
+	})
+}
+
+// GetPresets returns the available permission presets
+// @Summary Get permission presets
+// @Description Lists the named permission presets that can be applied to roles. Resource type "*" matches every resource type.
+// @Tags Core/Authorization
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} object{data=[]PermissionPreset} "Successful operation"
+// @Router /authorization/presets [get]
+func (c *AuthorizationController) GetPresets(ctx *router.Context) error {
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"data": GetPermissionPresets(),
+	})
+}
+
 // AssignPermission assigns a permission to a role
 // @Summary Assign permission to role
-// @Description Assigns a permission to a role
+// @Description Assigns a permission to a role. Deprecated permissions cannot be assigned.
 // @Tags Core/Authorization
 // @Security BearerAuth
 // @Security ApiKeyAuth
@@ -418,7 +688,7 @@ func (c *AuthorizationController) UpdateRolePermissions(ctx *router.Context) err
 // @Param id path string true "Role Id"
 // @Param assignRequest body object{permission_id=string} true "Permission Id to assign"
 // @Success 200 {object} object{success=boolean} "Permission assigned successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid request data"
+// @Failure 400 {object} types.ErrorResponse "Invalid request data or deprecated permission"
 // @Failure 404 {object} types.ErrorResponse "Role or permission not found"
 // @Failure 409 {object} types.ErrorResponse "Permission already assigned"
 // @Failure 500 {object} types.ErrorResponse "Internal server error"
@@ -437,9 +707,7 @@ func (c *AuthorizationController) AssignPermission(ctx *router.Context) error {
 	}
 
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid request: " + err.Error(),
-		})
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid request: "+err.Error()))
 	}
 
 	permissionIdUint, err := strconv.ParseUint(request.PermissionId, 10, 64)
@@ -463,6 +731,10 @@ func (c *AuthorizationController) AssignPermission(ctx *router.Context) error {
 			return ctx.JSON(http.StatusConflict, types.ErrorResponse{
 				Error: "Permission already assigned to this role",
 			})
+		case ErrPermissionDeprecated:
+			return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error: "Deprecated permissions cannot be assigned",
+			})
 		}
 
 		c.Logger.Error("Error assigning permission",
@@ -475,6 +747,7 @@ func (c *AuthorizationController) AssignPermission(ctx *router.Context) error {
 		})
 	}
 
+	c.audit(ctx, AuditPermissionAssigned, "role", uint(roleIdUint), fmt.Sprintf("permission %d", permissionIdUint))
 	return ctx.JSON(http.StatusOK, map[string]any{
 		"success": true,
 	})
@@ -534,6 +807,7 @@ func (c *AuthorizationController) RevokePermission(ctx *router.Context) error {
 		})
 	}
 
+	c.audit(ctx, AuditPermissionRevoked, "role", uint(roleIdUint), fmt.Sprintf("permission %d", permissionIdUint))
 	return ctx.JSON(http.StatusOK, map[string]any{
 		"success": true,
 	})
@@ -541,7 +815,9 @@ func (c *AuthorizationController) RevokePermission(ctx *router.Context) error {
 
 // CreateResourcePermission creates a resource-specific permission
 // @Summary Create resource permission
-// @Description Creates a resource-specific permission override
+// @Description Creates a resource-specific permission override. The action must be a known action with an existing
+// @Description permission for the resource type, default_scope one of own, team or all, and permission_id and role_id,
+// @Description when given, must exist. Invalid fields are listed in the fields map of the 400 response.
 // @Tags Core/Authorization
 // @Security BearerAuth
 // @Security ApiKeyAuth
@@ -555,12 +831,15 @@ func (c *AuthorizationController) RevokePermission(ctx *router.Context) error {
 func (c *AuthorizationController) CreateResourcePermission(ctx *router.Context) error {
 	var resourcePermission ResourcePermission
 	if err := ctx.ShouldBindJSON(&resourcePermission); err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid resource permission data: " + err.Error(),
-		})
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid resource permission data: "+err.Error()))
 	}
 
 	if err := c.Service.CreateResourcePermission(&resourcePermission); err != nil {
+		var fields types.FieldErrors
+		if errors.As(err, &fields) {
+			return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid resource permission data"))
+		}
+
 		c.Logger.Error("Error creating resource permission",
 			logger.String("error", err.Error()),
 			logger.String("resource_type", resourcePermission.ResourceType),
@@ -571,6 +850,7 @@ func (c *AuthorizationController) CreateResourcePermission(ctx *router.Context)
 		})
 	}
 
+	c.audit(ctx, AuditResourcePermissionCreated, "resource_permission", resourcePermission.Id, "")
 	return ctx.JSON(http.StatusCreated, map[string]any{
 		"data": resourcePermission,
 	})
@@ -607,6 +887,7 @@ func (c *AuthorizationController) DeleteResourcePermission(ctx *router.Context)
 		})
 	}
 
+	c.audit(ctx, AuditResourcePermissionDeleted, "resource_permission", uint(idUint), "")
 	return ctx.JSON(http.StatusOK, map[string]any{
 		"success": true,
 	})
@@ -620,14 +901,16 @@ func (c *AuthorizationController) DeleteResourcePermission(ctx *router.Context)
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
-// @Param checkRequest body object{user_id=string,organization_id=string,resource_type=string,action=string,resource_id=string} true "Permission check request"
+// @Param checkRequest body object{user_id=string,organization_id=string,resource_type=string,action=string,resource_id=string} true "Permission check request; user_id defaults to the authenticated user, another user needs role:manage"
 // @Success 200 {object} object{has_permission=boolean} "Permission check result"
 // @Failure 400 {object} types.ErrorResponse "Invalid request data"
+// @Failure 401 {object} types.ErrorResponse "Unauthorized"
+// @Failure 403 {object} types.ErrorResponse "Checking another user requires role:manage"
 // @Failure 500 {object} types.ErrorResponse "Internal server error"
 // @Router /authorization/check [post]
 func (c *AuthorizationController) CheckPermission(ctx *router.Context) error {
 	var request struct {
-		UserId       uint64 `json:"user_id" binding:"required"`
+		UserId       uint64 `json:"user_id"` // Defaults to the authenticated user
 		OrgId        uint64 `json:"organization_id" binding:"required"`
 		ResourceType string `json:"resource_type" binding:"required"`
 		Action       string `json:"action" binding:"required"`
@@ -635,13 +918,16 @@ func (c *AuthorizationController) CheckPermission(ctx *router.Context) error {
 	}
 
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid request: " + err.Error(),
-		})
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid request: "+err.Error()))
+	}
+
+	userId, status, err := c.checkedUserId(ctx, request.UserId)
+	if err != nil {
+		return ctx.JSON(status, types.ErrorResponse{Error: err.Error()})
 	}
+	request.UserId = userId
 
 	var hasPermission bool
-	var err error
 
 	if request.ResourceId != "" {
 		hasPermission, err = c.Service.HasResourcePermission(
@@ -676,3 +962,206 @@ func (c *AuthorizationController) CheckPermission(ctx *router.Context) error {
 		"has_permission": hasPermission,
 	})
 }
+
+// CheckPermissionBatch checks many permissions for a user in one request
+// @Summary Check user permissions in batch
+// @Description Checks a list of resource/action pairs and returns one boolean per item, in request order.
+// @Description user_id defaults to the authenticated user; checking another user needs the role:manage permission.
+// @Tags Core/Authorization
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param checkRequest body BatchPermissionCheckRequest true "Batch permission check request"
+// @Success 200 {object} BatchPermissionCheckResponse "Permission check results"
+// @Failure 400 {object} types.ErrorResponse "Invalid request data"
+// @Failure 401 {object} types.ErrorResponse "Unauthorized"
+// @Failure 403 {object} types.ErrorResponse "Checking another user requires role:manage"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Router /authorization/check-batch [post]
+func (c *AuthorizationController) CheckPermissionBatch(ctx *router.Context) error {
+	var request BatchPermissionCheckRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid request: "+err.Error()))
+	}
+
+	for i, check := range request.Checks {
+		if check.ResourceType == "" || check.Action == "" {
+			return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error: fmt.Sprintf("Invalid request: checks[%d] requires resource_type and action", i),
+			})
+		}
+	}
+
+	userId, status, err := c.checkedUserId(ctx, request.UserId)
+	if err != nil {
+		return ctx.JSON(status, types.ErrorResponse{Error: err.Error()})
+	}
+
+	results, err := c.Service.CheckPermissions(userId, request.Checks)
+	if err != nil {
+		c.Logger.Error("Error checking permissions in batch",
+			logger.String("error", err.Error()),
+			logger.String("user_id", fmt.Sprintf("%d", userId)),
+			logger.Int("checks", len(request.Checks)))
+
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error: "Failed to check permissions",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, BatchPermissionCheckResponse{
+		Results: results,
+	})
+}
+
+// checkedUserId returns the user whose permissions a check request asks
+// about: the authenticated user unless requested names another one, which
+// only role managers may check. The status goes with the error.
+func (c *AuthorizationController) checkedUserId(ctx *router.Context, requested uint64) (uint64, int, error) {
+	userId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return 0, http.StatusUnauthorized, err
+	}
+	if requested == 0 || requested == userId {
+		return userId, 0, nil
+	}
+
+	canManage, err := c.Service.HasPermission(userId, "role", "manage")
+	if err != nil {
+		c.Logger.Error("Error checking role manage permission",
+			logger.String("error", err.Error()),
+			logger.String("user_id", fmt.Sprintf("%d", userId)))
+		return 0, http.StatusInternalServerError, errors.New("Failed to check permission")
+	}
+	if !canManage {
+		return 0, http.StatusForbidden, errors.New("checking the permissions of another user requires the role:manage permission")
+	}
+	return requested, 0, nil
+}
+
+// TransferOwnership hands the Owner role to another user
+// @Summary Transfer ownership
+// @Description Gives the Owner role to another user. With demote the current owner gets demote_to (Administrator by default) instead of staying Owner.
+// @Description The change is atomic and never leaves the system without an Owner. Emits authorization.ownership_transferred.
+// @Tags Core/Authorization
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param transfer body TransferOwnershipRequest true "New owner"
+// @Success 200 {object} object{data=OwnershipTransfer} "Ownership transferred"
+// @Failure 400 {object} types.ErrorResponse "Invalid request"
+// @Failure 401 {object} types.ErrorResponse "Unauthorized"
+// @Failure 403 {object} types.ErrorResponse "Caller is not an Owner"
+// @Failure 409 {object} types.ErrorResponse "The transfer would leave no Owner"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Router /authorization/transfer-ownership [post]
+func (c *AuthorizationController) TransferOwnership(ctx *router.Context) error {
+	userId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, types.ErrorResponse{
+			Error: err.Error(),
+		})
+	}
+
+	var request TransferOwnershipRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid request: "+err.Error()))
+	}
+
+	transfer, err := c.Service.TransferOwnership(uint(userId), &request)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNotOwner):
+			return ctx.JSON(http.StatusForbidden, types.ErrorResponse{
+				Error: err.Error(),
+			})
+		case errors.Is(err, ErrTransferTarget), errors.Is(err, ErrInvalidDemoteRole):
+			return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error: err.Error(),
+			})
+		case errors.Is(err, ErrLastOwner):
+			return ctx.JSON(http.StatusConflict, types.ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+
+		c.Logger.Error("Error transferring ownership",
+			logger.String("error", err.Error()),
+			logger.String("user_id", fmt.Sprintf("%d", userId)),
+			logger.Uint("to_user_id", request.UserId))
+
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error: "Failed to transfer ownership",
+		})
+	}
+
+	c.audit(ctx, AuditOwnershipTransferred, "user", transfer.ToUserId, fmt.Sprintf("from user %d", transfer.FromUserId))
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"data": transfer,
+	})
+}
+
+// GetAuditLogs lists the changes made through the authorization routes
+// @Summary List the authorization audit log
+// @Description Lists the role, permission and ownership changes and who made them, newest first. from is inclusive and to exclusive.
+// @Tags Core/Authorization
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param from query string false "RFC3339 time the entries are at or after"
+// @Param to query string false "RFC3339 time the entries are before"
+// @Param actor_id query int false "User who made the change"
+// @Param action query string false "Action, e.g. role.created"
+// @Param page query int false "Page number"
+// @Param limit query int false "Entries per page"
+// @Success 200 {object} types.PaginatedResponse{data=[]AuditLog} "Audit log entries"
+// @Failure 400 {object} types.ErrorResponse "Invalid filter"
+// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Router /authorization/audit [get]
+func (c *AuthorizationController) GetAuditLogs(ctx *router.Context) error {
+	filters, err := ctx.AuditFilters()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: err.Error()})
+	}
+
+	entries, err := c.Service.AuditLogs(filters)
+	if err != nil {
+		if errors.Is(err, router.ErrInvalidQuery) {
+			return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: err.Error()})
+		}
+		c.Logger.Error("Error listing the audit log",
+			logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error: "Failed to load the audit log",
+		})
+	}
+	entries.AddLinks(ctx.Request.URL)
+
+	return ctx.JSON(http.StatusOK, entries)
+}
+
+// audit records a change the authenticated user made. The change is already
+// made, so a failure to record it is only logged.
+func (c *AuthorizationController) audit(ctx *router.Context, action, targetType string, targetId uint, details string) {
+	actorId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		c.Logger.Error("Cannot audit a change without a user",
+			logger.String("action", action))
+		return
+	}
+
+	entry := &AuditLog{
+		ActorId:    uint(actorId),
+		Action:     action,
+		TargetType: targetType,
+		TargetId:   targetId,
+		Details:    details,
+	}
+	if err := c.Service.RecordAudit(entry); err != nil {
+		c.Logger.Error("Error recording audit log entry",
+			logger.String("error", err.Error()),
+			logger.String("action", action))
+	}
+}
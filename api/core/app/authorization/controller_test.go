@@ -0,0 +1,131 @@
+package authorization
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"base/core/router"
+	"base/core/router/middleware"
+	"base/core/types"
+)
+
+// testAPI serves the authorization routes of a test module
+type testAPI struct {
+	module *AuthorizationModule
+	router *router.Router
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "authorization-test-secret")
+
+	// The app authenticates every api route before it reaches the module
+	auth := middleware.DefaultAuthConfig()
+	auth.TokenValidator = func(token string) (any, error) {
+		return types.ValidateJWT(token)
+	}
+
+	m := newTestModule(t)
+	r := router.New()
+	m.Controller.Routes(r.Group("/api", middleware.Auth(auth)))
+	return &testAPI{module: m, router: r}
+}
+
+// do sends body as userId and returns the recorded response
+func (api *testAPI) do(t *testing.T, userId uint64, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	token, err := types.GenerateJWT(uint(userId), nil)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	api.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCheckPermissionBatch(t *testing.T) {
+	api := newTestAPI(t)
+	viewer := createUser(t, api.module.DB, "Viewer")
+
+	body := `{"checks":[
+		{"resource_type":"media","action":"read"},
+		{"resource_type":"media","action":"delete"},
+		{"resource_type":"role","action":"manage"},
+		{"resource_type":"profile","action":"read"},
+		{"resource_type":"unknown","action":"read"}
+	]}`
+	w := api.do(t, viewer, http.MethodPost, "/api/authorization/check-batch", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+
+	var response BatchPermissionCheckResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if want := []bool{true, false, false, true, false}; !reflect.DeepEqual(response.Results, want) {
+		t.Errorf("results %v, want %v", response.Results, want)
+	}
+}
+
+func TestCheckingAnotherUserRequiresRoleManage(t *testing.T) {
+	api := newTestAPI(t)
+	owner := createUser(t, api.module.DB, "Owner")
+	member := createUser(t, api.module.DB, "Member")
+	other := createUser(t, api.module.DB, "Viewer")
+
+	check := `{"user_id":%d,"organization_id":1,"resource_type":"media","action":"read"}`
+	batch := `{"user_id":%d,"checks":[{"resource_type":"media","action":"read"}]}`
+
+	tests := []struct {
+		name   string
+		caller uint64
+		userId uint64
+		want   int
+	}{
+		{"member checking themselves", member, member, http.StatusOK},
+		{"member checking without user_id", member, 0, http.StatusOK},
+		{"member checking another user", member, other, http.StatusForbidden},
+		{"owner checking another user", owner, other, http.StatusOK},
+	}
+	for _, tt := range tests {
+		for path, body := range map[string]string{
+			"/api/authorization/check":       fmt.Sprintf(check, tt.userId),
+			"/api/authorization/check-batch": fmt.Sprintf(batch, tt.userId),
+		} {
+			if w := api.do(t, tt.caller, http.MethodPost, path, body); w.Code != tt.want {
+				t.Errorf("%s on %s: status %d, want %d: %s", tt.name, path, w.Code, tt.want, w.Body)
+			}
+		}
+	}
+}
+
+func TestCreateRoleTwiceConflicts(t *testing.T) {
+	api := newTestAPI(t)
+	owner := createUser(t, api.module.DB, "Owner")
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"new role", `{"name":"Editor"}`, http.StatusCreated},
+		{"same name", `{"name":"Editor"}`, http.StatusConflict},
+		{"other case", `{"name":"editor"}`, http.StatusConflict},
+		{"from a preset", `{"name":"EDITOR","preset":"read-only"}`, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		if w := api.do(t, owner, http.MethodPost, "/api/authorization/roles", tt.body); w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+}
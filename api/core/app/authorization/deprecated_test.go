@@ -0,0 +1,236 @@
+package authorization
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// deprecate marks the permission resourceType:action deprecated and returns its id
+func deprecate(t *testing.T, db *gorm.DB, resourceType, action string) uint {
+	t.Helper()
+	var permission Permission
+	if err := db.Where("resource_type = ? AND action = ?", resourceType, action).First(&permission).Error; err != nil {
+		t.Fatalf("find permission %s:%s: %v", resourceType, action, err)
+	}
+	if err := db.Model(&permission).Update("deprecated", true).Error; err != nil {
+		t.Fatal(err)
+	}
+	return permission.Id
+}
+
+func hasPermissionId(permissions []Permission, id uint) bool {
+	for _, permission := range permissions {
+		if permission.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDeprecatedPermissionsAreHiddenFromListing(t *testing.T) {
+	api := newTestAPI(t)
+	s := api.module.Service
+	id := deprecate(t, s.DB, "game", "analytics")
+
+	active, err := s.GetPermissions(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := s.GetPermissions(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasPermissionId(active, id) || !hasPermissionId(all, id) || len(all) != len(active)+1 {
+		t.Errorf("listed %d active and %d in all, want the deprecated permission only in all", len(active), len(all))
+	}
+
+	owner := createUser(t, s.DB, "Owner")
+	list := func(path string) []Permission {
+		t.Helper()
+		w := api.do(t, owner, http.MethodGet, path, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d: %s", path, w.Code, w.Body)
+		}
+		var response struct {
+			Data []Permission `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		return response.Data
+	}
+	if listed := list("/api/authorization/permissions"); hasPermissionId(listed, id) || len(listed) != len(active) {
+		t.Errorf("default listing has %d permissions, want the %d active ones", len(listed), len(active))
+	}
+	listed := list("/api/authorization/permissions?include_deprecated=true")
+	if len(listed) != len(all) {
+		t.Errorf("listing with include_deprecated has %d permissions, want %d", len(listed), len(all))
+	}
+	for _, permission := range listed {
+		if permission.Deprecated != (permission.Id == id) {
+			t.Errorf("%s:%s listed with deprecated %v", permission.ResourceType, permission.Action, permission.Deprecated)
+		}
+	}
+}
+
+func TestDeprecatedPermissionCannotBeAssigned(t *testing.T) {
+	api := newTestAPI(t)
+	s := api.module.Service
+	owner := createUser(t, s.DB, "Owner")
+	role := findRole(t, s.DB, "Member")
+	held := rolePermissionIds(t, s.DB, role.Id)
+	if len(held) == 0 {
+		t.Fatal("Member has no permissions")
+	}
+
+	// Deprecate one permission the role holds and one it does not
+	var heldPermission, otherPermission Permission
+	s.DB.First(&heldPermission, held[0])
+	if err := s.DB.Where("id NOT IN ?", held).First(&otherPermission).Error; err != nil {
+		t.Fatal(err)
+	}
+	deprecate(t, s.DB, heldPermission.ResourceType, heldPermission.Action)
+	deprecate(t, s.DB, otherPermission.ResourceType, otherPermission.Action)
+
+	if err := s.AssignPermissionToRole(uint64(role.Id), uint64(otherPermission.Id)); !errors.Is(err, ErrPermissionDeprecated) {
+		t.Errorf("assign: got %v, want ErrPermissionDeprecated", err)
+	}
+	path := fmt.Sprintf("/api/authorization/roles/%d/permissions", role.Id)
+	if w := api.do(t, owner, http.MethodPost, path, fmt.Sprintf(`{"permission_id":"%d"}`, otherPermission.Id)); w.Code != http.StatusBadRequest {
+		t.Errorf("POST deprecated permission: status %d, want 400: %s", w.Code, w.Body)
+	}
+
+	// A replacement keeps the deprecated permission the role already holds
+	ids := make([]uint64, len(held))
+	for i, id := range held {
+		ids[i] = uint64(id)
+	}
+	if err := s.UpdateRolePermissions(uint64(role.Id), ids); err != nil {
+		t.Fatalf("keeping the held deprecated permission: %v", err)
+	}
+	if got := rolePermissionIds(t, s.DB, role.Id); fmt.Sprint(got) != fmt.Sprint(held) {
+		t.Errorf("permissions %v after replacing, want %v", got, held)
+	}
+
+	// Adding one is refused and the role is left as it was
+	if err := s.UpdateRolePermissions(uint64(role.Id), append(ids, uint64(otherPermission.Id))); !errors.Is(err, ErrPermissionDeprecated) {
+		t.Errorf("adding a deprecated permission: got %v, want ErrPermissionDeprecated", err)
+	}
+	if got := rolePermissionIds(t, s.DB, role.Id); fmt.Sprint(got) != fmt.Sprint(held) {
+		t.Errorf("a refused replacement changed the permissions to %v", got)
+	}
+
+	tests := []struct {
+		body string
+		want int
+	}{
+		{fmt.Sprintf(`{"permission_ids":%s}`, jsonIds(ids)), http.StatusOK},
+		{fmt.Sprintf(`{"permission_ids":%s}`, jsonIds(append(ids, uint64(otherPermission.Id)))), http.StatusBadRequest},
+		{`{"permission_ids":[9999]}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := api.do(t, owner, http.MethodPut, path, tt.body); w.Code != tt.want {
+			t.Errorf("PUT %s: status %d, want %d: %s", tt.body, w.Code, tt.want, w.Body)
+		}
+	}
+
+	// Once revoked, the held permission cannot be added back
+	if err := s.RevokePermissionFromRole(uint64(role.Id), uint64(heldPermission.Id)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AssignPermissionToRole(uint64(role.Id), uint64(heldPermission.Id)); !errors.Is(err, ErrPermissionDeprecated) {
+		t.Errorf("re-assigning a revoked deprecated permission: got %v, want ErrPermissionDeprecated", err)
+	}
+}
+
+func jsonIds(ids []uint64) string {
+	encoded, _ := json.Marshal(ids)
+	return string(encoded)
+}
+
+func TestCloneRoleSkipsDeprecatedPermissions(t *testing.T) {
+	s := newTestModule(t).Service
+	source := findRole(t, s.DB, "Administrator")
+	held := rolePermissionIds(t, s.DB, source.Id)
+	var permission Permission
+	s.DB.First(&permission, held[0])
+	deprecate(t, s.DB, permission.ResourceType, permission.Action)
+
+	clone, err := s.CloneRole(uint64(source.Id), "")
+	if err != nil {
+		t.Fatalf("CloneRole: %v", err)
+	}
+	if got := rolePermissionIds(t, s.DB, clone.Id); fmt.Sprint(got) != fmt.Sprint(held[1:]) || clone.PermissionCount != len(held)-1 {
+		t.Errorf("clone permissions %v (count %d), want %v", got, clone.PermissionCount, held[1:])
+	}
+	// The source keeps it
+	if got := rolePermissionIds(t, s.DB, source.Id); len(got) != len(held) {
+		t.Errorf("source permissions %v, want %v", got, held)
+	}
+}
+
+func TestReconcileDeprecatesUndeclaredPermissions(t *testing.T) {
+	s := newTestModule(t).Service
+	custom := Permission{Name: "Export reports", ResourceType: "report", Action: "export"}
+	if err := s.DB.Create(&custom).Error; err != nil {
+		t.Fatal(err)
+	}
+	role := findRole(t, s.DB, "Member")
+	if err := s.AssignPermissionToRole(uint64(role.Id), uint64(custom.Id)); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.ReconcilePermissions(DeprecateUndeclared)
+	if err != nil {
+		t.Fatalf("ReconcilePermissions: %v", err)
+	}
+	if result.Deprecated != 1 || result.Pruned != 0 {
+		t.Errorf("result = %+v, want one deprecated permission", result)
+	}
+	var reloaded Permission
+	s.DB.First(&reloaded, custom.Id)
+	if !reloaded.Deprecated {
+		t.Error("the undeclared permission was not deprecated")
+	}
+	held := rolePermissionIds(t, s.DB, role.Id)
+	if !containsId(held, custom.Id) {
+		t.Errorf("Member lost the deprecated permission: %v", held)
+	}
+
+	// Reconciling again changes nothing
+	if result, err := s.ReconcilePermissions(DeprecateUndeclared); err != nil || result != (PermissionSyncResult{}) {
+		t.Errorf("second reconcile: %+v, %v; want no changes", result, err)
+	}
+
+	// A module declaring it deprecated keeps it so under KeepUndeclared
+	registered.Lock()
+	saved := registered.permissions
+	registered.Unlock()
+	t.Cleanup(func() {
+		registered.Lock()
+		registered.permissions = saved
+		registered.Unlock()
+	})
+	RegisterPermissions(Permission{Name: "Export reports", ResourceType: "report", Action: "export", Deprecated: true})
+	if result, err := s.ReconcilePermissions(KeepUndeclared); err != nil || result != (PermissionSyncResult{}) {
+		t.Errorf("reconcile with the declaration: %+v, %v; want no changes", result, err)
+	}
+	s.DB.First(&reloaded, custom.Id)
+	if !reloaded.Deprecated {
+		t.Error("the declared deprecated permission was revived")
+	}
+}
+
+func containsId(ids []uint, id uint) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
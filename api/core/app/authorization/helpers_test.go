@@ -0,0 +1,54 @@
+package authorization
+
+import (
+	"path/filepath"
+	"testing"
+
+	"base/core/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// newTestModule returns a migrated and seeded authorization module on a
+// fresh sqlite database, with the permission cache disabled
+func newTestModule(t *testing.T) *AuthorizationModule {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: gormLogger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	// The columns of the users table the authorization module reads
+	if err := db.Exec("CREATE TABLE users (id integer PRIMARY KEY, role_id integer, deleted_at datetime)").Error; err != nil {
+		t.Fatalf("create users: %v", err)
+	}
+
+	m := NewAuthorizationModule(db, nil, logger.NewLoggerFromZap(zap.NewNop()), nil).(*AuthorizationModule)
+	if err := m.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	SetPermissionCacheTTL(0)
+	t.Cleanup(func() { SetPermissionCacheTTL(DefaultPermissionCacheTTL) })
+	return m
+}
+
+// createUser adds a user holding the named role and returns their id
+func createUser(t *testing.T, db *gorm.DB, roleName string) uint64 {
+	t.Helper()
+
+	var role Role
+	if err := db.Where("name = ?", roleName).First(&role).Error; err != nil {
+		t.Fatalf("find role %s: %v", roleName, err)
+	}
+	var id uint64
+	if err := db.Raw("INSERT INTO users (role_id) VALUES (?) RETURNING id", role.Id).Scan(&id).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return id
+}
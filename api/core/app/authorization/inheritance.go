@@ -0,0 +1,143 @@
+package authorization
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// GetEffectivePermissions returns the permissions of a role merged with those
+// of all its ancestors. A permission held by several roles in the hierarchy
+// is listed once, attributed to the nearest one, so direct permissions are
+// never reported as inherited.
+func (s *AuthorizationService) GetEffectivePermissions(roleId uint64) ([]EffectivePermission, error) {
+	lineage, err := roleLineage(s.DB, uint(roleId))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(lineage))
+	for i, role := range lineage {
+		ids[i] = role.Id
+	}
+
+	var rows []struct {
+		Permission
+		RoleId uint
+	}
+	err = s.DB.Table("permissions").
+		Select("permissions.*, role_permissions.role_id AS role_id").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Where("role_permissions.role_id IN ?", ids).
+		Order("permissions.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Depth of each role in the lineage, 0 being the role itself
+	depth := make(map[uint]int, len(lineage))
+	for i, role := range lineage {
+		depth[role.Id] = i
+	}
+
+	nearest := make(map[uint]int, len(rows))
+	var order []uint
+	for _, row := range rows {
+		current, seen := nearest[row.Id]
+		if !seen {
+			order = append(order, row.Id)
+		}
+		if !seen || depth[row.RoleId] < current {
+			nearest[row.Id] = depth[row.RoleId]
+		}
+	}
+
+	byId := make(map[uint]Permission, len(rows))
+	for _, row := range rows {
+		byId[row.Id] = row.Permission
+	}
+
+	permissions := make([]EffectivePermission, 0, len(order))
+	for _, id := range order {
+		source := lineage[nearest[id]]
+		permissions = append(permissions, EffectivePermission{
+			Permission:     byId[id],
+			Inherited:      nearest[id] > 0,
+			SourceRoleId:   source.Id,
+			SourceRoleName: source.Name,
+		})
+	}
+	return permissions, nil
+}
+
+// roleLineage returns the role followed by its parent, grandparent and so on.
+// The walk stops at the first role seen twice, so a cycle stored before it
+// could be rejected cannot loop forever.
+func roleLineage(db *gorm.DB, roleId uint) ([]Role, error) {
+	var role Role
+	if err := db.First(&role, "id = ?", roleId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	lineage := []Role{role}
+	seen := map[uint]bool{role.Id: true}
+	for role.ParentRoleId != nil && !seen[*role.ParentRoleId] {
+		parentId := *role.ParentRoleId
+		role = Role{}
+		if err := db.First(&role, "id = ?", parentId).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				break
+			}
+			return nil, err
+		}
+		seen[role.Id] = true
+		lineage = append(lineage, role)
+	}
+	return lineage, nil
+}
+
+// roleLineageIds returns the ids of roleLineage, or none for role id 0
+func roleLineageIds(db *gorm.DB, roleId uint) ([]uint, error) {
+	if roleId == 0 {
+		return nil, nil
+	}
+	lineage, err := roleLineage(db, roleId)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(lineage))
+	for i, role := range lineage {
+		ids[i] = role.Id
+	}
+	return ids, nil
+}
+
+// checkParentRole returns ErrInvalidParentRole unless parentId is empty or an
+// existing role that does not have roleId among its ancestors. Pass roleId 0
+// for a role not created yet.
+func checkParentRole(db *gorm.DB, roleId uint, parentId *uint) error {
+	if parentId == nil {
+		return nil
+	}
+	if *parentId == roleId {
+		return ErrInvalidParentRole
+	}
+
+	lineage, err := roleLineage(db, *parentId)
+	if err != nil {
+		if errors.Is(err, ErrRoleNotFound) {
+			return ErrInvalidParentRole
+		}
+		return err
+	}
+	for _, ancestor := range lineage {
+		if roleId != 0 && ancestor.Id == roleId {
+			return ErrInvalidParentRole
+		}
+	}
+	return nil
+}
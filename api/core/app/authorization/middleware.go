@@ -196,3 +196,40 @@ func RequireRole(roleName string) router.MiddlewareFunc {
 		}
 	}
 }
+
+// RequireAnyRole creates a middleware function that only lets users holding one of the given roles through
+func RequireAnyRole(service *AuthorizationService, roleNames ...string) router.MiddlewareFunc {
+	allowed := make(map[string]bool, len(roleNames))
+	for _, name := range roleNames {
+		allowed[name] = true
+	}
+
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			userId, err := GetUserIdFromContext(c)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, map[string]any{
+					"error": err.Error(),
+				})
+				return nil
+			}
+
+			roleName, err := service.GetUserRoleName(userId)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, map[string]any{
+					"error": fmt.Sprintf("error checking user role: %v", err),
+				})
+				return nil
+			}
+
+			if !allowed[roleName] {
+				c.AbortWithStatusJSON(http.StatusForbidden, map[string]any{
+					"error": "insufficient role permissions",
+				})
+				return nil
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -16,14 +16,21 @@ var (
 	ErrInvalidRoleId          = errors.New("invalid role id")
 	ErrSystemRoleUnmodifiable = errors.New("system role unmodifiable")
 	ErrDuplicatePermission    = errors.New("duplicate permission")
+	ErrPresetNotFound         = errors.New("permission preset not found")
+	ErrDuplicateRole          = errors.New("role name already exists")
+	ErrInvalidParentRole      = errors.New("invalid parent role")
+	ErrPermissionDeprecated   = errors.New("permission is deprecated")
 )
 
-// Role represents a set of permissions assigned to users within an organization
+// Role represents a set of permissions assigned to users within an organization.
+// Names are unique; the service also rejects names differing only in case.
+// A role inherits every permission of its parent role and the parent's ancestors.
 type Role struct {
 	Id              uint      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
-	Name            string    `gorm:"not null" json:"name"`
+	Name            string    `gorm:"not null;size:255;uniqueIndex:idx_roles_name" json:"name"`
 	Description     string    `json:"description"`
 	IsSystem        bool      `gorm:"default:false" json:"is_system"`
+	ParentRoleId    *uint     `gorm:"column:parent_role_id;index" json:"parent_role_id"`
 	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 	PermissionCount int       `json:"permission_count"` // New field
@@ -39,6 +46,7 @@ func (r *Role) ToResponse() *RoleResponse {
 		Name:            r.Name,
 		Description:     r.Description,
 		IsSystem:        r.IsSystem,
+		ParentRoleId:    r.ParentRoleId,
 		CreatedAt:       r.CreatedAt,
 		UpdatedAt:       r.UpdatedAt,
 		PermissionCount: r.PermissionCount,
@@ -51,6 +59,7 @@ type RoleResponse struct {
 	Name            string    `json:"name"`
 	Description     string    `json:"description"`
 	IsSystem        bool      `json:"is_system"`
+	ParentRoleId    *uint     `json:"parent_role_id"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 	PermissionCount int       `json:"permission_count"` // New field
@@ -61,21 +70,51 @@ type CreateRoleRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
 	IsSystem    bool   `json:"is_system"`
+	// Preset names a permission preset to assign to the new role
+	Preset string `json:"preset,omitempty" example:"read-only"`
+	// ParentRoleId names a role whose permissions the new role inherits
+	ParentRoleId *uint `json:"parent_role_id,omitempty"`
+}
+
+// CloneRoleRequest represents the optional payload for cloning a role
+type CloneRoleRequest struct {
+	// Name of the new role; defaults to the source name suffixed with "Copy"
+	Name string `json:"name,omitempty" example:"Moderator Copy"`
+}
+
+// TransferOwnershipRequest represents the payload for handing the Owner role
+// to another user
+type TransferOwnershipRequest struct {
+	// UserId is the user receiving the Owner role
+	UserId uint `json:"user_id" binding:"required" example:"2"`
+	// Demote gives the current owner the DemoteTo role; otherwise both stay Owner
+	Demote bool `json:"demote" example:"true"`
+	// DemoteTo names the role of a demoted owner, Administrator by default
+	DemoteTo string `json:"demote_to,omitempty" example:"Administrator"`
+}
+
+// ApplyPresetRequest represents the payload for applying a permission preset to a role
+type ApplyPresetRequest struct {
+	Preset string `json:"preset" binding:"required" example:"content-editor"`
 }
 
 // UpdateRoleRequest represents the payload for updating a role
 type UpdateRoleRequest struct {
-	Name        string `json:"name,omitempty"`
-	Description string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+	ParentRoleId *uint  `json:"parent_role_id,omitempty"`
 }
 
-// Permission defines an action that can be performed on a resource
+// Permission defines an action that can be performed on a resource.
+// A deprecated permission belongs to a removed feature: roles keep it, but it
+// is hidden from the default listing and cannot be assigned anew.
 type Permission struct {
 	Id           uint      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
 	Name         string    `gorm:"not null" json:"name"`
 	Description  string    `json:"description"`
 	ResourceType string    `gorm:"not null" json:"resource_type"`
 	Action       string    `gorm:"not null" json:"action"`
+	Deprecated   bool      `gorm:"not null;default:false" json:"deprecated"`
 	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -91,6 +130,7 @@ func (p *Permission) ToResponse() *PermissionResponse {
 		Description:  p.Description,
 		ResourceType: p.ResourceType,
 		Action:       p.Action,
+		Deprecated:   p.Deprecated,
 		CreatedAt:    p.CreatedAt,
 		UpdatedAt:    p.UpdatedAt,
 	}
@@ -103,6 +143,7 @@ type PermissionResponse struct {
 	Description  string    `json:"description"`
 	ResourceType string    `json:"resource_type"`
 	Action       string    `json:"action"`
+	Deprecated   bool      `json:"deprecated"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -152,6 +193,15 @@ type RolePermissionResponse struct {
 	CreatedAt    time.Time `json:"created_at"`
 }
 
+// EffectivePermission is a permission a role holds directly or inherits
+// from one of its ancestors
+type EffectivePermission struct {
+	Permission
+	Inherited      bool   `json:"inherited"`
+	SourceRoleId   uint   `json:"source_role_id"` // Nearest role in the hierarchy that holds the permission
+	SourceRoleName string `json:"source_role_name"`
+}
+
 // ResourcePermission grants permissions on resource types or specific resources
 type ResourcePermission struct {
 	Id           uint       `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
@@ -204,6 +254,24 @@ type ResourcePermissionResponse struct {
 	RoleDetails  *RoleResponse       `json:"role_details,omitempty"`
 }
 
+// PermissionCheckItem describes a single permission to evaluate in a batch check
+type PermissionCheckItem struct {
+	ResourceType string `json:"resource_type" binding:"required"`
+	Action       string `json:"action" binding:"required"`
+	ResourceId   string `json:"resource_id,omitempty"`
+}
+
+// BatchPermissionCheckRequest represents the payload for checking many permissions at once
+type BatchPermissionCheckRequest struct {
+	UserId uint64                `json:"user_id,omitempty"` // Defaults to the authenticated user; another user needs role:manage
+	Checks []PermissionCheckItem `json:"checks" binding:"required"`
+}
+
+// BatchPermissionCheckResponse holds one result per requested check, in request order
+type BatchPermissionCheckResponse struct {
+	Results []bool `json:"results"`
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error string `json:"error"`
@@ -1,9 +1,11 @@
 package authorization
 
 import (
+	"base/core/emitter"
 	"base/core/logger"
 	"base/core/module"
 	"base/core/router"
+	"fmt"
 	"strings"
 
 	"gorm.io/gorm"
@@ -18,8 +20,12 @@ type AuthorizationModule struct {
 	Logger     logger.Logger
 }
 
-func NewAuthorizationModule(db *gorm.DB, router *router.RouterGroup, logger logger.Logger) module.Module {
+func NewAuthorizationModule(db *gorm.DB, router *router.RouterGroup, logger logger.Logger, emitter *emitter.Emitter) module.Module {
 	service := NewAuthorizationService(db)
+	service.Emitter = emitter
+	if emitter != nil {
+		subscribeCacheInvalidation(emitter)
+	}
 	controller := NewAuthorizationController(service, logger)
 
 	authzModule := &AuthorizationModule{
@@ -40,12 +46,18 @@ func (m *AuthorizationModule) Routes(router *router.RouterGroup) {
 }
 
 func (m *AuthorizationModule) Migrate() error {
+	// The unique index on role names cannot be created over duplicates
+	if err := m.checkDuplicateRoles(); err != nil {
+		return err
+	}
+
 	err := m.DB.AutoMigrate(
 		&Role{},
 		&Permission{},
 		&RolePermission{},
 		&ResourcePermission{},
 		&ResourceAccess{},
+		&AuditLog{},
 	)
 	if err != nil {
 		return err
@@ -60,6 +72,24 @@ func (m *AuthorizationModule) Migrate() error {
 	return nil
 }
 
+// checkDuplicateRoles reports role names held by more than one role, which
+// must be renamed before the roles name index can be created
+func (m *AuthorizationModule) checkDuplicateRoles() error {
+	if !m.DB.Migrator().HasTable(&Role{}) || m.DB.Migrator().HasIndex(&Role{}, "idx_roles_name") {
+		return nil
+	}
+
+	var names []string
+	err := m.DB.Model(&Role{}).Select("name").Group("name").Having("COUNT(*) > 1").Pluck("name", &names).Error
+	if err != nil {
+		return err
+	}
+	if len(names) > 0 {
+		return fmt.Errorf("duplicate role names must be renamed before migrating: %s", strings.Join(names, ", "))
+	}
+	return nil
+}
+
 func (m *AuthorizationModule) GetObject(foreignKey string, dbTableName string) []any {
 
 	var result []any
@@ -94,52 +124,8 @@ func (m *AuthorizationModule) seedDefaultData() error {
 		},
 	}
 
-	// Create resource types
-	resourceTypes := []string{
-		"user",
-		"authorization",
-		"media",
-		"profile",
-	}
-
-	// Define actions
-	actions := []string{
-		"create",
-		"read",
-		"update",
-		"delete",
-		"list",
-	}
-
-	// Create default permissions based on resources and actions
-	var defaultPermissions []Permission
-	for _, resourceType := range resourceTypes {
-		for _, action := range actions {
-			defaultPermissions = append(defaultPermissions, Permission{
-				Name:         resourceType + " " + action,
-				Description:  "Allows " + action + " operations on " + resourceType,
-				ResourceType: resourceType,
-				Action:       action,
-			})
-		}
-	}
-
-	// Add special permissions
-	specialPermissions := []Permission{
-		{
-			Name:         "Manage Roles",
-			Description:  "Create, update, and delete roles",
-			ResourceType: "role",
-			Action:       "manage",
-		},
-		{
-			Name:         "Assign Permissions",
-			Description:  "Assign permissions to roles",
-			ResourceType: "permission",
-			Action:       "assign",
-		},
-	}
-	defaultPermissions = append(defaultPermissions, specialPermissions...)
+	// Permissions declared by the code, see DeclaredPermissions
+	defaultPermissions := DeclaredPermissions()
 
 	// Start transaction with silent logger for seeding (to avoid "record not found" noise)
 	tx := m.DB.Session(&gorm.Session{Logger: gormLogger.Discard}).Begin()
@@ -174,9 +160,9 @@ func (m *AuthorizationModule) seedDefaultData() error {
 	// Assign all permissions to Owner role
 	var ownerRole Role
 	if err := tx.Where("name = ? AND is_system = ?", "Owner", true).First(&ownerRole).Error; err == nil {
-		// Get all permissions
+		// Get all permissions but the deprecated ones
 		var allPermissions []Permission
-		if err := tx.Find(&allPermissions).Error; err != nil {
+		if err := tx.Where("deprecated = ?", false).Find(&allPermissions).Error; err != nil {
 			tx.Rollback()
 			return err
 		}
@@ -205,9 +191,10 @@ func (m *AuthorizationModule) seedDefaultData() error {
 			"authorization:create", "authorization:read", "authorization:update", "authorization:delete", "authorization:list",
 			"media:create", "media:read", "media:update", "media:delete", "media:list",
 			"profile:create", "profile:read", "profile:update", "profile:delete", "profile:list",
-			"role:create", "role:read", "role:update", "role:delete", "role:list",
+			"role:create", "role:read", "role:update", "role:delete", "role:list", "role:manage",
 			"permission:create", "permission:read", "permission:update", "permission:delete", "permission:list",
 			"resource_permission:create", "resource_permission:read", "resource_permission:update", "resource_permission:delete", "resource_permission:list",
+			"game:analytics",
 		}
 
 		for _, permName := range adminPermissions {
@@ -218,7 +205,7 @@ func (m *AuthorizationModule) seedDefaultData() error {
 			resourceType, action := parts[0], parts[1]
 
 			var permission Permission
-			if err := tx.Where("resource_type = ? AND action = ?", resourceType, action).First(&permission).Error; err != nil {
+			if err := tx.Where("resource_type = ? AND action = ? AND deprecated = ?", resourceType, action, false).First(&permission).Error; err != nil {
 				if err == gorm.ErrRecordNotFound {
 					continue // Skip if permission not found - this is normal
 				}
@@ -261,7 +248,7 @@ func (m *AuthorizationModule) seedDefaultData() error {
 			resourceType, action := parts[0], parts[1]
 
 			var permission Permission
-			if err := tx.Where("resource_type = ? AND action = ?", resourceType, action).First(&permission).Error; err != nil {
+			if err := tx.Where("resource_type = ? AND action = ? AND deprecated = ?", resourceType, action, false).First(&permission).Error; err != nil {
 				if err == gorm.ErrRecordNotFound {
 					continue // Skip if permission not found - this is normal
 				}
@@ -304,7 +291,7 @@ func (m *AuthorizationModule) seedDefaultData() error {
 			resourceType, action := parts[0], parts[1]
 
 			var permission Permission
-			if err := tx.Where("resource_type = ? AND action = ?", resourceType, action).First(&permission).Error; err != nil {
+			if err := tx.Where("resource_type = ? AND action = ? AND deprecated = ?", resourceType, action, false).First(&permission).Error; err != nil {
 				if err == gorm.ErrRecordNotFound {
 					continue // Skip if permission not found - this is normal
 				}
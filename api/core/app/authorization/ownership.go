@@ -0,0 +1,148 @@
+package authorization
+
+import (
+	"errors"
+	"fmt"
+
+	"base/core/types"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EventOwnershipTransferred is emitted with an OwnershipTransfer once the
+// Owner role was given to another user
+const EventOwnershipTransferred = "authorization.ownership_transferred"
+
+// OwnerRoleName is the system role with every permission
+const OwnerRoleName = "Owner"
+
+// AdministratorRoleName is the system role for day-to-day administration
+const AdministratorRoleName = "Administrator"
+
+// DefaultDemotedRoleName is the role a demoted owner gets unless another is named
+const DefaultDemotedRoleName = AdministratorRoleName
+
+var (
+	ErrNotOwner          = errors.New("only an Owner can transfer ownership")
+	ErrTransferTarget    = errors.New("ownership must go to another existing user")
+	ErrInvalidDemoteRole = errors.New("demoted owner needs an existing role other than Owner")
+	ErrLastOwner         = errors.New("at least one Owner must remain")
+)
+
+// OwnershipTransfer is the payload of the authorization.ownership_transferred event
+type OwnershipTransfer struct {
+	FromUserId uint `json:"from_user_id"`
+	ToUserId   uint `json:"to_user_id"`
+	// DemotedRoleId is the role the previous owner now holds, 0 when they stayed Owner
+	DemotedRoleId uint `json:"demoted_role_id,omitempty"`
+}
+
+// ownershipUser is the part of a users row the transfer reads and writes
+type ownershipUser struct {
+	Id     uint
+	RoleId uint
+}
+
+// TransferOwnership gives the Owner role to req.UserId on behalf of the owner
+// fromUserId, optionally demoting them. It runs in one transaction that fails
+// with ErrLastOwner rather than leave no Owner behind.
+func (s *AuthorizationService) TransferOwnership(fromUserId uint, req *TransferOwnershipRequest) (*OwnershipTransfer, error) {
+	if req.UserId == 0 || req.UserId == fromUserId {
+		return nil, ErrTransferTarget
+	}
+
+	transfer := &OwnershipTransfer{FromUserId: fromUserId, ToUserId: req.UserId}
+	var changes []types.RoleChange
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var owner Role
+		if err := tx.Where("name = ? AND is_system = ?", OwnerRoleName, true).First(&owner).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %s", ErrRoleNotFound, OwnerRoleName)
+			}
+			return err
+		}
+
+		// Lock both rows so concurrent transfers cannot interleave
+		var users []ownershipUser
+		err := tx.Table("users").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id, role_id").
+			Where("id IN ? AND deleted_at IS NULL", []uint{fromUserId, req.UserId}).
+			Order("id").
+			Find(&users).Error
+		if err != nil {
+			return err
+		}
+
+		var from, to *ownershipUser
+		for i := range users {
+			switch users[i].Id {
+			case fromUserId:
+				from = &users[i]
+			case req.UserId:
+				to = &users[i]
+			}
+		}
+		if from == nil || from.RoleId != owner.Id {
+			return ErrNotOwner
+		}
+		if to == nil {
+			return ErrTransferTarget
+		}
+		if to.RoleId != owner.Id {
+			if err := tx.Table("users").Where("id = ?", to.Id).Update("role_id", owner.Id).Error; err != nil {
+				return err
+			}
+			changes = append(changes, types.RoleChange{UserId: to.Id, RoleId: owner.Id, RoleName: owner.Name, PreviousRoleId: to.RoleId})
+		}
+
+		if req.Demote {
+			name := req.DemoteTo
+			if name == "" {
+				name = DefaultDemotedRoleName
+			}
+			var demoted Role
+			if err := tx.Where("name = ?", name).First(&demoted).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrInvalidDemoteRole
+				}
+				return err
+			}
+			if demoted.Id == owner.Id {
+				return ErrInvalidDemoteRole
+			}
+			if err := tx.Table("users").Where("id = ?", from.Id).Update("role_id", demoted.Id).Error; err != nil {
+				return err
+			}
+			transfer.DemotedRoleId = demoted.Id
+			changes = append(changes, types.RoleChange{UserId: from.Id, RoleId: demoted.Id, RoleName: demoted.Name, PreviousRoleId: owner.Id})
+		}
+
+		var owners int64
+		if err := tx.Table("users").Where("role_id = ? AND deleted_at IS NULL", owner.Id).Count(&owners).Error; err != nil {
+			return err
+		}
+		if owners == 0 {
+			return ErrLastOwner
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		InvalidateUserPermissions(uint64(change.UserId))
+	}
+	if s.Emitter != nil {
+		// The transfer is committed, so listener errors are only reported
+		for _, change := range changes {
+			if err := s.Emitter.EmitSync(types.EventRoleChanged, change); err != nil {
+				fmt.Printf("Role change listener failed: %v\n", err)
+			}
+		}
+		s.Emitter.EmitAsync(EventOwnershipTransferred, *transfer)
+	}
+	return transfer, nil
+}
@@ -0,0 +1,115 @@
+package authorization
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"base/core/emitter"
+	"base/core/types"
+)
+
+// roleOf returns the name of the role the user holds
+func roleOf(t *testing.T, api *testAPI, userId uint64) string {
+	t.Helper()
+	var name string
+	api.module.DB.Raw("SELECT roles.name FROM users JOIN roles ON roles.id = users.role_id WHERE users.id = ?", userId).Scan(&name)
+	return name
+}
+
+func TestTransferOwnership(t *testing.T) {
+	api := newTestAPI(t)
+	events := emitter.New()
+	api.module.Service.Emitter = events
+	transferred := make(chan OwnershipTransfer, 1)
+	events.On(EventOwnershipTransferred, func(data any) { transferred <- data.(OwnershipTransfer) })
+	var changes []types.RoleChange
+	events.On(types.EventRoleChanged, func(data any) { changes = append(changes, data.(types.RoleChange)) })
+
+	owner := createUser(t, api.module.DB, "Owner")
+	member := createUser(t, api.module.DB, "Member")
+
+	w := api.do(t, owner, http.MethodPost, "/api/authorization/transfer-ownership", fmt.Sprintf(`{"user_id":%d,"demote":true}`, member))
+	if w.Code != http.StatusOK {
+		t.Fatalf("transfer: status %d: %s", w.Code, w.Body)
+	}
+	if roleOf(t, api, member) != OwnerRoleName || roleOf(t, api, owner) != AdministratorRoleName {
+		t.Errorf("after the transfer the new owner is %s and the previous one %s", roleOf(t, api, member), roleOf(t, api, owner))
+	}
+	select {
+	case event := <-transferred:
+		if event.FromUserId != uint(owner) || event.ToUserId != uint(member) || event.DemotedRoleId == 0 {
+			t.Errorf("event = %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Error("no ownership_transferred event")
+	}
+	if len(changes) != 2 || changes[0].UserId != uint(member) || changes[1].UserId != uint(owner) {
+		t.Errorf("role changes = %+v, want the promotion then the demotion", changes)
+	}
+
+	// The previous owner lost the right to transfer
+	if w := api.do(t, owner, http.MethodPost, "/api/authorization/transfer-ownership", fmt.Sprintf(`{"user_id":%d}`, owner)); w.Code != http.StatusForbidden {
+		t.Errorf("demoted owner transferring: status %d, want 403", w.Code)
+	}
+
+	// Without demote both stay Owner
+	viewer := createUser(t, api.module.DB, "Viewer")
+	if w := api.do(t, member, http.MethodPost, "/api/authorization/transfer-ownership", fmt.Sprintf(`{"user_id":%d}`, viewer)); w.Code != http.StatusOK {
+		t.Fatalf("transfer without demote: status %d: %s", w.Code, w.Body)
+	}
+	if roleOf(t, api, member) != OwnerRoleName || roleOf(t, api, viewer) != OwnerRoleName {
+		t.Errorf("owners are %s and %s, want both Owner", roleOf(t, api, member), roleOf(t, api, viewer))
+	}
+}
+
+func TestTransferOwnershipGuards(t *testing.T) {
+	api := newTestAPI(t)
+	owner := createUser(t, api.module.DB, "Owner")
+	member := createUser(t, api.module.DB, "Member")
+	deleted := createUser(t, api.module.DB, "Member")
+	api.module.DB.Exec("UPDATE users SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?", deleted)
+
+	transfer := func(from, to uint64, demoteTo string) *http.Response {
+		body := fmt.Sprintf(`{"user_id":%d,"demote":true,"demote_to":%q}`, to, demoteTo)
+		return api.do(t, from, http.MethodPost, "/api/authorization/transfer-ownership", body).Result()
+	}
+	tests := []struct {
+		name     string
+		from, to uint64
+		demoteTo string
+		want     int
+	}{
+		{"to themselves", owner, owner, "", http.StatusBadRequest},
+		{"to a missing user", owner, 999, "", http.StatusBadRequest},
+		{"to a deleted user", owner, deleted, "", http.StatusBadRequest},
+		{"demoted to Owner", owner, member, OwnerRoleName, http.StatusBadRequest},
+		{"demoted to an unknown role", owner, member, "Nobody", http.StatusBadRequest},
+		{"by a member", member, member, "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if resp := transfer(tt.from, tt.to, tt.demoteTo); resp.StatusCode != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, tt.want)
+		}
+	}
+	if roleOf(t, api, owner) != OwnerRoleName || roleOf(t, api, member) != "Member" {
+		t.Errorf("rejected transfers changed roles: owner %s, member %s", roleOf(t, api, owner), roleOf(t, api, member))
+	}
+
+	// A promotion that does not stick, as when a concurrent change demotes
+	// the new owner, would leave no Owner, so the whole transfer rolls back
+	api.module.DB.Exec(fmt.Sprintf(`CREATE TRIGGER undo_promotion AFTER UPDATE OF role_id ON users WHEN NEW.id = %d
+		BEGIN UPDATE users SET role_id = OLD.role_id WHERE id = NEW.id; END`, member))
+	_, err := api.module.Service.TransferOwnership(uint(owner), &TransferOwnershipRequest{UserId: uint(member), Demote: true})
+	if !errors.Is(err, ErrLastOwner) {
+		t.Fatalf("got %v, want ErrLastOwner", err)
+	}
+	if roleOf(t, api, owner) != OwnerRoleName {
+		t.Errorf("the owner was demoted to %s with nobody else Owner", roleOf(t, api, owner))
+	}
+	if resp := transfer(owner, member, ""); resp.StatusCode != http.StatusConflict {
+		t.Errorf("zero owner transfer: status %d, want 409", resp.StatusCode)
+	}
+}
@@ -0,0 +1,166 @@
+package authorization
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// AnyResourceType in a preset matches every resource type
+const AnyResourceType = "*"
+
+// PermissionPreset is a named set of permissions that can be applied to a role
+type PermissionPreset struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Actions by resource type
+	Permissions map[string][]string `json:"permissions"`
+}
+
+var (
+	permissionPresets = map[string]PermissionPreset{
+		"read-only": {
+			Name:        "read-only",
+			Description: "Read and list every resource",
+			Permissions: map[string][]string{AnyResourceType: {"read", "list"}},
+		},
+		"content-editor": {
+			Name:        "content-editor",
+			Description: "Manage media and read users and profiles",
+			Permissions: map[string][]string{
+				"media":   {"create", "read", "update", "delete", "list"},
+				"user":    {"read", "list"},
+				"profile": {"read", "list"},
+			},
+		},
+	}
+	permissionPresetsMutex sync.RWMutex
+)
+
+// RegisterPermissionPreset adds or replaces a preset, so modules can offer
+// presets for their own resource types
+func RegisterPermissionPreset(preset PermissionPreset) {
+	permissionPresetsMutex.Lock()
+	defer permissionPresetsMutex.Unlock()
+	permissionPresets[strings.ToLower(preset.Name)] = preset
+}
+
+// GetPermissionPreset returns the named preset
+func GetPermissionPreset(name string) (PermissionPreset, bool) {
+	permissionPresetsMutex.RLock()
+	defer permissionPresetsMutex.RUnlock()
+	preset, ok := permissionPresets[strings.ToLower(name)]
+	return preset, ok
+}
+
+// GetPermissionPresets returns every preset sorted by name
+func GetPermissionPresets() []PermissionPreset {
+	permissionPresetsMutex.RLock()
+	defer permissionPresetsMutex.RUnlock()
+
+	presets := make([]PermissionPreset, 0, len(permissionPresets))
+	for _, preset := range permissionPresets {
+		presets = append(presets, preset)
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	return presets
+}
+
+// ApplyPreset assigns the preset's permissions to a role in one transaction
+// and returns the role's resulting permissions. Permissions the role already
+// has are kept.
+func (s *AuthorizationService) ApplyPreset(roleId uint64, presetName string) ([]Permission, error) {
+	preset, ok := GetPermissionPreset(presetName)
+	if !ok {
+		return nil, ErrPresetNotFound
+	}
+
+	var role Role
+	if err := s.DB.First(&role, "id = ?", roleId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		return applyPreset(tx, role.Id, preset)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.rolePermissionsChanged(role.Id)
+
+	return s.GetRolePermissions(roleId)
+}
+
+// CreateRoleFromPreset creates a role and assigns the preset's permissions
+// in one transaction, returning the role's permissions
+func (s *AuthorizationService) CreateRoleFromPreset(role *Role, presetName string) ([]Permission, error) {
+	preset, ok := GetPermissionPreset(presetName)
+	if !ok {
+		return nil, ErrPresetNotFound
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := createRole(tx, role); err != nil {
+			return err
+		}
+		return applyPreset(tx, role.Id, preset)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetRolePermissions(uint64(role.Id))
+}
+
+// applyPreset assigns the preset's permissions that exist, are not
+// deprecated and the role lacks
+func applyPreset(tx *gorm.DB, roleId uint, preset PermissionPreset) error {
+	var permissions []Permission
+	if err := tx.Where("deprecated = ?", false).Find(&permissions).Error; err != nil {
+		return err
+	}
+
+	var assigned []uint
+	if err := tx.Model(&RolePermission{}).Where("role_id = ?", roleId).Pluck("permission_id", &assigned).Error; err != nil {
+		return err
+	}
+	has := make(map[uint]bool, len(assigned))
+	for _, id := range assigned {
+		has[id] = true
+	}
+
+	for _, permission := range permissions {
+		if has[permission.Id] || !preset.includes(permission) {
+			continue
+		}
+		rolePermission := RolePermission{
+			RoleId:       roleId,
+			PermissionId: permission.Id,
+		}
+		if err := tx.Create(&rolePermission).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// includes reports whether the preset grants the permission
+func (p PermissionPreset) includes(permission Permission) bool {
+	for resourceType, actions := range p.Permissions {
+		if resourceType != AnyResourceType && !strings.EqualFold(resourceType, permission.ResourceType) {
+			continue
+		}
+		for _, action := range actions {
+			if strings.EqualFold(action, permission.Action) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package authorization
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"base/core/types"
+
+	"gorm.io/gorm"
+)
+
+func TestCreateResourcePermissionValidatesFields(t *testing.T) {
+	m := newTestModule(t)
+	var mediaRead, mediaDelete Permission
+	m.DB.Where("resource_type = ? AND action = ?", "media", "read").First(&mediaRead)
+	m.DB.Where("resource_type = ? AND action = ?", "media", "delete").First(&mediaDelete)
+	member := roleIdOf(t, m.DB, "Member")
+
+	tests := []struct {
+		name  string
+		grant ResourcePermission
+		field string
+	}{
+		{"missing resource type", ResourcePermission{Action: "read"}, "resource_type"},
+		{"missing action", ResourcePermission{ResourceType: "media"}, "action"},
+		{"unknown action", ResourcePermission{ResourceType: "media", Action: "teleport"}, "action"},
+		{"action without a permission", ResourcePermission{ResourceType: "media", Action: "assign"}, "action"},
+		{"unknown scope", ResourcePermission{ResourceType: "media", Action: "read", DefaultScope: "everyone"}, "default_scope"},
+		{"own scope without a resolver", ResourcePermission{ResourceType: "media", Action: "read", DefaultScope: "own"}, "default_scope"},
+		{"missing permission", ResourcePermission{ResourceType: "media", Action: "read", PermissionId: 9999}, "permission_id"},
+		{"mismatched permission", ResourcePermission{ResourceType: "media", Action: "read", PermissionId: mediaDelete.Id}, "permission_id"},
+		{"malformed role", ResourcePermission{ResourceType: "media", Action: "read", RoleId: "admins"}, "role_id"},
+		{"missing role", ResourcePermission{ResourceType: "media", Action: "read", RoleId: "9999"}, "role_id"},
+	}
+	for _, tt := range tests {
+		err := m.Service.CreateResourcePermission(&tt.grant)
+		var fields types.FieldErrors
+		if !errors.As(err, &fields) {
+			t.Errorf("%s: got %v, want field errors", tt.name, err)
+			continue
+		}
+		if _, ok := fields[tt.field]; !ok || len(fields) != 1 {
+			t.Errorf("%s: fields %v, want only %s", tt.name, fields, tt.field)
+		}
+	}
+	var count int64
+	m.DB.Model(&ResourcePermission{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d invalid grants were stored", count)
+	}
+
+	// Every invalid field is reported at once
+	err := m.Service.CreateResourcePermission(&ResourcePermission{Action: "teleport", DefaultScope: "everyone", RoleId: "x"})
+	var fields types.FieldErrors
+	if !errors.As(err, &fields) || len(fields) != 4 {
+		t.Errorf("got %v, want resource_type, action, default_scope and role_id", fields)
+	}
+
+	valid := []ResourcePermission{
+		{ResourceType: "media", Action: "read", PermissionId: mediaRead.Id, RoleId: member},
+		{ResourceType: "Media", Action: "DELETE", DefaultScope: "all", ResourceId: "7", UserId: 3},
+	}
+	RegisterOwnerResolver("media", func(*gorm.DB, string) (uint64, error) { return 0, nil })
+	t.Cleanup(func() {
+		ownerResolversMutex.Lock()
+		delete(ownerResolvers, "media")
+		ownerResolversMutex.Unlock()
+	})
+	valid = append(valid, ResourcePermission{ResourceType: "media", Action: "update", DefaultScope: "own", RoleId: member})
+	for _, grant := range valid {
+		if err := m.Service.CreateResourcePermission(&grant); err != nil || grant.Id == 0 {
+			t.Errorf("%+v: %v", grant, err)
+		}
+	}
+}
+
+func TestCreateResourcePermissionRouteReportsFields(t *testing.T) {
+	api := newTestAPI(t)
+	owner := createUser(t, api.module.DB, "Owner")
+
+	w := api.do(t, owner, http.MethodPost, "/api/authorization/resource-permissions",
+		`{"resource_type":"media","action":"teleport","default_scope":"everyone","role_id":"9999"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var response types.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if len(response.Fields) != 3 || response.Fields["action"] == "" || response.Fields["default_scope"] == "" || response.Fields["role_id"] == "" {
+		t.Errorf("fields %v, want action, default_scope and role_id", response.Fields)
+	}
+
+	body := `{"resource_type":"media","action":"read","role_id":"` + roleIdOf(t, api.module.DB, "Member") + `"}`
+	if w := api.do(t, owner, http.MethodPost, "/api/authorization/resource-permissions", body); w.Code != http.StatusCreated {
+		t.Errorf("valid grant: status %d: %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,159 @@
+package authorization
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// newNotes creates a notes table owned by users, registers its owner resolver
+// and returns the id of a note owned by ownerId
+func newNotes(t *testing.T, db *gorm.DB, ownerId uint64) string {
+	t.Helper()
+
+	if err := db.Exec("CREATE TABLE notes (id integer PRIMARY KEY, user_id integer)").Error; err != nil {
+		t.Fatalf("create notes: %v", err)
+	}
+	RegisterOwnerResolver("note", func(db *gorm.DB, resourceId string) (uint64, error) {
+		var note struct{ UserId uint64 }
+		err := db.Table("notes").Where("id = ?", resourceId).Take(&note).Error
+		return note.UserId, err
+	})
+	t.Cleanup(func() {
+		ownerResolversMutex.Lock()
+		delete(ownerResolvers, "note")
+		ownerResolversMutex.Unlock()
+	})
+
+	return addNote(t, db, ownerId)
+}
+
+func grant(t *testing.T, db *gorm.DB, rp ResourcePermission) {
+	t.Helper()
+	if err := db.Create(&rp).Error; err != nil {
+		t.Fatalf("create resource permission: %v", err)
+	}
+}
+
+func roleIdOf(t *testing.T, db *gorm.DB, name string) string {
+	t.Helper()
+	var role Role
+	if err := db.Where("name = ?", name).First(&role).Error; err != nil {
+		t.Fatalf("find role %s: %v", name, err)
+	}
+	return strconv.FormatUint(uint64(role.Id), 10)
+}
+
+func TestHasResourcePermissionScopes(t *testing.T) {
+	m := newTestModule(t)
+	owner := createUser(t, m.DB, "Member")
+	other := createUser(t, m.DB, "Member")
+	viewer := createUser(t, m.DB, "Viewer")
+	editor := createUser(t, m.DB, "Viewer")
+	note := newNotes(t, m.DB, owner)
+
+	// Members may update their own notes, editors every note
+	grant(t, m.DB, ResourcePermission{ResourceType: "note", RoleId: roleIdOf(t, m.DB, "Member"), Action: "update", DefaultScope: ScopeOwn})
+	grant(t, m.DB, ResourcePermission{ResourceType: "note", UserId: uint(editor), Action: "update", DefaultScope: ScopeAll})
+
+	tests := []struct {
+		name   string
+		userId uint64
+		action string
+		want   bool
+	}{
+		{"own scope, owner", owner, "update", true},
+		{"own scope, not owner", other, "update", false},
+		{"all scope", editor, "update", true},
+		{"no grant", viewer, "update", false},
+		{"action not granted", owner, "delete", false},
+	}
+	for _, tt := range tests {
+		got, err := m.Service.HasResourcePermission(tt.userId, "note", note, tt.action)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: HasResourcePermission = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// A missing resource has no owner
+	if got, err := m.Service.HasResourcePermission(owner, "note", "999", "update"); err != nil || got {
+		t.Errorf("own scope on a missing note = %v, %v; want denied", got, err)
+	}
+}
+
+func TestHasResourcePermissionWidestScopeWins(t *testing.T) {
+	m := newTestModule(t)
+	owner := createUser(t, m.DB, "Member")
+	other := createUser(t, m.DB, "Member")
+	note := newNotes(t, m.DB, owner)
+	otherNote := addNote(t, m.DB, other)
+
+	grant(t, m.DB, ResourcePermission{ResourceType: "note", RoleId: roleIdOf(t, m.DB, "Member"), Action: "read", DefaultScope: ScopeTeam})
+	// A grant on one note reaches only that note
+	grant(t, m.DB, ResourcePermission{ResourceType: "note", ResourceId: otherNote, UserId: uint(owner), Action: "read", DefaultScope: ScopeOwn})
+
+	check := func(userId uint64, resourceId string) bool {
+		t.Helper()
+		got, err := m.Service.HasResourcePermission(userId, "note", resourceId, "read")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	if !check(owner, note) {
+		t.Error("team scope denied the owner")
+	}
+	if !check(owner, otherNote) {
+		t.Error("grant on a specific note was not honored")
+	}
+	if check(other, note) {
+		t.Error("team scope granted a user who only shares the role")
+	}
+
+	// Resource access rows widen the scope too
+	if err := m.DB.Create(&ResourceAccess{RoleId: roleIdOf(t, m.DB, "Member"), MemberId: uint(other), ResourceType: "note", ResourceId: "*", AccessType: ScopeAll}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !check(other, note) {
+		t.Error("access type all did not grant another user's note")
+	}
+}
+
+func TestHasResourcePermissionNeedsOwnerResolver(t *testing.T) {
+	m := newTestModule(t)
+	user := createUser(t, m.DB, "Member")
+	grant(t, m.DB, ResourcePermission{ResourceType: "invoice", UserId: uint(user), Action: "read", DefaultScope: ScopeOwn})
+
+	if _, err := m.Service.HasResourcePermission(user, "invoice", "1", "read"); !errors.Is(err, ErrOwnerResolverNotFound) {
+		t.Errorf("own scope without a resolver: got %v, want ErrOwnerResolverNotFound", err)
+	}
+}
+
+func TestHasResourcePermissionFallsBackToRole(t *testing.T) {
+	m := newTestModule(t)
+	owner := createUser(t, m.DB, "Owner")
+	viewer := createUser(t, m.DB, "Viewer")
+
+	// Without resource-level rows the role permission grants every resource
+	if got, err := m.Service.HasResourcePermission(owner, "role", "1", "manage"); err != nil || !got {
+		t.Errorf("owner role: %v, %v; want granted", got, err)
+	}
+	if got, err := m.Service.HasResourcePermission(viewer, "role", "1", "manage"); err != nil || got {
+		t.Errorf("viewer role: %v, %v; want denied", got, err)
+	}
+}
+
+// addNote adds a note owned by ownerId to the table created by newNotes
+func addNote(t *testing.T, db *gorm.DB, ownerId uint64) string {
+	t.Helper()
+	var id uint64
+	if err := db.Raw("INSERT INTO notes (user_id) VALUES (?) RETURNING id", ownerId).Scan(&id).Error; err != nil {
+		t.Fatalf("create note: %v", err)
+	}
+	return strconv.FormatUint(id, 10)
+}
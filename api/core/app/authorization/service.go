@@ -1,10 +1,14 @@
 package authorization
 
 import (
+	"base/core/database"
+	"base/core/emitter"
+	"base/core/types"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
-	"time"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -12,6 +16,9 @@ import (
 // AuthorizationService handles business logic for authorization
 type AuthorizationService struct {
 	DB *gorm.DB
+	// Emitter, when set, announces role permission changes so other
+	// services can react; the permission cache is invalidated either way
+	Emitter *emitter.Emitter
 }
 
 // NewAuthorizationService creates a new authorization service
@@ -47,10 +54,15 @@ func (s *AuthorizationService) GetRoles() ([]Role, error) {
 	return roles, nil
 }
 
-// GetPermissions returns all permissions
-func (s *AuthorizationService) GetPermissions() ([]Permission, error) {
+// GetPermissions returns all permissions, the deprecated ones only with
+// includeDeprecated
+func (s *AuthorizationService) GetPermissions(includeDeprecated bool) ([]Permission, error) {
 	var permissions []Permission
-	result := s.DB.Find(&permissions)
+	query := s.DB
+	if !includeDeprecated {
+		query = query.Where("deprecated = ?", false)
+	}
+	result := query.Find(&permissions)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -74,14 +86,123 @@ func (s *AuthorizationService) GetRole(id uint64) (*Role, error) {
 	return &role, nil
 }
 
-// CreateRole creates a new role
+// CreateRole creates a new role. A name already taken, ignoring case,
+// returns ErrDuplicateRole.
 func (s *AuthorizationService) CreateRole(role *Role) error {
-	// Set creation time
-	role.CreatedAt = time.Now()
-	role.UpdatedAt = time.Now()
+	return createRole(s.DB, role)
+}
+
+// createRole creates a role on db, which may be a transaction
+func createRole(db *gorm.DB, role *Role) error {
+	if err := checkRoleName(db, role.Name, 0); err != nil {
+		return err
+	}
+	if err := checkParentRole(db, 0, role.ParentRoleId); err != nil {
+		return err
+	}
 
-	result := s.DB.Create(role)
-	return result.Error
+	if err := db.Create(role).Error; err != nil {
+		if database.IsDuplicateKey(db, err) {
+			return ErrDuplicateRole
+		}
+		return err
+	}
+	return nil
+}
+
+// maxCloneNameAttempts bounds the "Copy 2", "Copy 3"... names tried for a clone
+const maxCloneNameAttempts = 100
+
+// CloneRole creates a non-system role with the description, parent and
+// permissions of the role sourceId, in one transaction. Without a name the
+// clone is called "<source> Copy", numbered when that is taken; a given name
+// that is taken returns ErrDuplicateRole.
+func (s *AuthorizationService) CloneRole(sourceId uint64, name string) (*Role, error) {
+	var clone *Role
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var source Role
+		if err := tx.First(&source, "id = ?", sourceId).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRoleNotFound
+			}
+			return err
+		}
+
+		name = strings.TrimSpace(name)
+		if name == "" {
+			var err error
+			if name, err = cloneName(tx, source.Name); err != nil {
+				return err
+			}
+		}
+
+		clone = &Role{
+			Name:         name,
+			Description:  source.Description,
+			IsSystem:     false,
+			ParentRoleId: source.ParentRoleId,
+		}
+		if err := createRole(tx, clone); err != nil {
+			return err
+		}
+
+		// Deprecated permissions are not assigned anew, so the clone lacks them
+		var permissionIds []uint
+		err := tx.Model(&RolePermission{}).
+			Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+			Where("role_permissions.role_id = ? AND permissions.deprecated = ?", source.Id, false).
+			Pluck("role_permissions.permission_id", &permissionIds).Error
+		if err != nil {
+			return err
+		}
+		for _, permissionId := range permissionIds {
+			rolePermission := RolePermission{
+				RoleId:       clone.Id,
+				PermissionId: permissionId,
+			}
+			if err := tx.Create(&rolePermission).Error; err != nil {
+				return err
+			}
+		}
+		clone.PermissionCount = len(permissionIds)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// cloneName returns the first free name of "<name> Copy", "<name> Copy 2"...
+func cloneName(db *gorm.DB, name string) (string, error) {
+	candidate := name + " Copy"
+	for i := 2; i <= maxCloneNameAttempts; i++ {
+		err := checkRoleName(db, candidate, 0)
+		if err == nil {
+			return candidate, nil
+		}
+		if !errors.Is(err, ErrDuplicateRole) {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s Copy %d", name, i)
+	}
+	return "", ErrDuplicateRole
+}
+
+// checkRoleName returns ErrDuplicateRole when another role than exceptId
+// has the name, ignoring case
+func checkRoleName(db *gorm.DB, name string, exceptId uint) error {
+	var count int64
+	err := db.Model(&Role{}).
+		Where("LOWER(name) = LOWER(?) AND id <> ?", strings.TrimSpace(name), exceptId).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDuplicateRole
+	}
+	return nil
 }
 
 // UpdateRole updates an existing role
@@ -101,16 +222,33 @@ func (s *AuthorizationService) UpdateRole(role *Role) error {
 		return ErrSystemRoleUnmodifiable
 	}
 
+	if err := checkRoleName(s.DB, role.Name, existingRole.Id); err != nil {
+		return err
+	}
+	if err := checkParentRole(s.DB, existingRole.Id, role.ParentRoleId); err != nil {
+		return err
+	}
+
+	parentChanged := !sameRoleId(existingRole.ParentRoleId, role.ParentRoleId)
+
 	// Update fields
 	existingRole.Name = role.Name
 	existingRole.Description = role.Description
-	existingRole.UpdatedAt = time.Now()
+	existingRole.ParentRoleId = role.ParentRoleId
 
 	result = s.DB.Save(&existingRole)
 	if result.Error != nil {
+		if database.IsDuplicateKey(s.DB, result.Error) {
+			return ErrDuplicateRole
+		}
 		return result.Error
 	}
 
+	// A new parent changes the inherited permissions
+	if parentChanged {
+		s.rolePermissionsChanged(existingRole.Id)
+	}
+
 	// Update the role object with saved data
 	*role = existingRole
 
@@ -139,9 +277,19 @@ func (s *AuthorizationService) DeleteRole(id uint64) error {
 		return err
 	}
 
+	// Child roles keep inheriting from the deleted role's parent
+	if err := s.DB.Model(&Role{}).Where("parent_role_id = ?", id).
+		Update("parent_role_id", existingRole.ParentRoleId).Error; err != nil {
+		return err
+	}
+
 	// Then delete the role
 	result = s.DB.Delete(&existingRole)
-	return result.Error
+	if result.Error != nil {
+		return result.Error
+	}
+	s.rolePermissionsChanged(existingRole.Id)
+	return nil
 }
 
 // GetRolePermissions returns all permissions for a role
@@ -186,6 +334,16 @@ func (s *AuthorizationService) UpdateRolePermissions(roleId uint64, permissionId
 		return result.Error
 	}
 
+	// Deprecated permissions may stay with the role but not be added to it
+	var heldIds []uint64
+	if err := s.DB.Model(&RolePermission{}).Where("role_id = ?", roleId).Pluck("permission_id", &heldIds).Error; err != nil {
+		return err
+	}
+	held := make(map[uint64]bool, len(heldIds))
+	for _, id := range heldIds {
+		held[id] = true
+	}
+
 	// Begin transaction
 	tx := s.DB.Begin()
 	defer func() {
@@ -216,12 +374,15 @@ func (s *AuthorizationService) UpdateRolePermissions(roleId uint64, permissionId
 			tx.Rollback()
 			return err
 		}
+		if permission.Deprecated && !held[permissionId] {
+			tx.Rollback()
+			return ErrPermissionDeprecated
+		}
 
 		// Create role permission
 		rolePermission := RolePermission{
 			RoleId:       uint(roleId),
 			PermissionId: uint(permissionId),
-			CreatedAt:    time.Now(),
 		}
 
 		if err := tx.Create(&rolePermission).Error; err != nil {
@@ -231,7 +392,11 @@ func (s *AuthorizationService) UpdateRolePermissions(roleId uint64, permissionId
 	}
 
 	// Commit transaction
-	return tx.Commit().Error
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	s.rolePermissionsChanged(uint(roleId))
+	return nil
 }
 
 // AssignPermissionToRole assigns a permission to a role
@@ -258,6 +423,9 @@ func (s *AuthorizationService) AssignPermissionToRole(roleId uint64, permissionI
 		}
 		return result.Error
 	}
+	if permission.Deprecated {
+		return ErrPermissionDeprecated
+	}
 
 	// Check if permission is already assigned
 	var count int64
@@ -273,11 +441,14 @@ func (s *AuthorizationService) AssignPermissionToRole(roleId uint64, permissionI
 	rolePermission := RolePermission{
 		RoleId:       uint(roleId),
 		PermissionId: uint(permissionId),
-		CreatedAt:    time.Now(),
 	}
 
 	result = s.DB.Create(&rolePermission)
-	return result.Error
+	if result.Error != nil {
+		return result.Error
+	}
+	s.rolePermissionsChanged(uint(roleId))
+	return nil
 }
 
 // RevokePermissionFromRole removes a permission from a role
@@ -307,24 +478,137 @@ func (s *AuthorizationService) RevokePermissionFromRole(roleId uint64, permissio
 	// Delete role permission
 	result = s.DB.Where("role_id = ? AND permission_id = ?", roleId, permissionId).
 		Delete(&RolePermission{})
-
-	return result.Error
+	if result.Error != nil {
+		return result.Error
+	}
+	s.rolePermissionsChanged(uint(roleId))
+	return nil
 }
 
-// CreateResourcePermission creates a resource-specific permission
+// CreateResourcePermission creates a resource-specific permission. Grants
+// that could never be enforced are rejected with types.FieldErrors.
 func (s *AuthorizationService) CreateResourcePermission(rp *ResourcePermission) error {
-	// Set creation time
-	rp.CreatedAt = time.Now()
-	rp.UpdatedAt = time.Now()
+	if err := s.validateResourcePermission(rp); err != nil {
+		return err
+	}
 
 	result := s.DB.Create(rp)
-	return result.Error
+	if result.Error != nil {
+		return result.Error
+	}
+	InvalidateUserPermissions(uint64(rp.UserId))
+	return nil
+}
+
+// validateResourcePermission checks that the grant names a declared action on
+// an existing permission that is not deprecated, a known scope and, when
+// given, an existing permission and role. Failures are returned as types.FieldErrors keyed by
+// the JSON field.
+func (s *AuthorizationService) validateResourcePermission(rp *ResourcePermission) error {
+	fields := make(types.FieldErrors)
+	resourceType := strings.ToLower(strings.TrimSpace(rp.ResourceType))
+	action := strings.ToLower(strings.TrimSpace(rp.Action))
+
+	if resourceType == "" {
+		fields["resource_type"] = "is required"
+	}
+	switch {
+	case action == "":
+		fields["action"] = "is required"
+	case !isKnownAction(action):
+		fields["action"] = "is not a known action"
+	case resourceType != "":
+		var deprecated []bool
+		err := s.DB.Model(&Permission{}).
+			Where("LOWER(resource_type) = ? AND LOWER(action) = ?", resourceType, action).
+			Pluck("deprecated", &deprecated).Error
+		if err != nil {
+			return err
+		}
+		if len(deprecated) == 0 {
+			fields["action"] = fmt.Sprintf("no %s permission exists for resource type %s", action, resourceType)
+		} else if !slices.Contains(deprecated, false) {
+			fields["action"] = fmt.Sprintf("the %s permission of resource type %s is deprecated", action, resourceType)
+		}
+	}
+
+	switch strings.ToLower(rp.DefaultScope) {
+	case "", ScopeAll:
+	case ScopeOwn, ScopeTeam:
+		if _, ok := getOwnerResolver(resourceType); resourceType != "" && !ok {
+			fields["default_scope"] = "needs an owner resolver for resource type " + resourceType
+		}
+	default:
+		fields["default_scope"] = "must be one of own, team, all"
+	}
+
+	if rp.PermissionId != 0 {
+		var permission Permission
+		err := s.DB.First(&permission, rp.PermissionId).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			fields["permission_id"] = "does not exist"
+		case err != nil:
+			return err
+		case !strings.EqualFold(permission.ResourceType, resourceType) || !strings.EqualFold(permission.Action, action):
+			fields["permission_id"] = "does not match resource_type and action"
+		case permission.Deprecated:
+			fields["permission_id"] = "is deprecated"
+		}
+	}
+
+	if rp.RoleId != "" {
+		roleId, err := strconv.ParseUint(rp.RoleId, 10, 32)
+		if err != nil {
+			fields["role_id"] = "must be a role id"
+		} else {
+			var count int64
+			if err := s.DB.Model(&Role{}).Where("id = ?", roleId).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				fields["role_id"] = "does not exist"
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		return fields
+	}
+	return nil
+}
+
+// isKnownAction reports whether action is one of the action constants or the
+// action of a declared permission
+func isKnownAction(action string) bool {
+	switch action {
+	case ActionCreate, ActionRead, ActionUpdate, ActionDelete, ActionList, ActionAssign, ActionManageRole:
+		return true
+	}
+	for _, permission := range DeclaredPermissions() {
+		if strings.EqualFold(permission.Action, action) {
+			return true
+		}
+	}
+	return false
 }
 
 // DeleteResourcePermission deletes a resource-specific permission
 func (s *AuthorizationService) DeleteResourcePermission(id uint64) error {
-	result := s.DB.Delete(&ResourcePermission{}, "id = ?", id)
-	return result.Error
+	var grant ResourcePermission
+	if err := s.DB.First(&grant, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	result := s.DB.Delete(&grant)
+	if result.Error != nil {
+		return result.Error
+	}
+	InvalidateUserPermissions(uint64(grant.UserId))
+	return nil
 }
 
 // GetUserMembershipInfo retrieves user membership information (simplified without organizations)
@@ -341,20 +625,122 @@ func (s *AuthorizationService) GetUserMembershipInfo(userId uint64) (*UserMember
 	}, nil
 }
 
-// HasPermission checks if a user has permission for a resource type
+// GetUserRoleName returns the name of the role assigned to a user
+func (s *AuthorizationService) GetUserRoleName(userId uint64) (string, error) {
+	var roleName string
+	err := s.DB.Table("users").
+		Select("roles.name").
+		Joins("JOIN roles ON roles.id = users.role_id").
+		Where("users.id = ? AND users.deleted_at IS NULL", userId).
+		Scan(&roleName).Error
+	if err != nil {
+		return "", err
+	}
+	return roleName, nil
+}
+
+// HasPermission checks if a user has permission for a resource type, through
+// their role or a resource-type grant. It reads the cached permission set of
+// CheckPermissions.
 func (s *AuthorizationService) HasPermission(userId uint64, resourceType, action string) (bool, error) {
-	// Simplified permission check without organization context
-	// For now, return true for all permission checks
-	// This should be implemented with proper user role system
-	return true, nil
+	results, err := s.CheckPermissions(userId, []PermissionCheckItem{{ResourceType: resourceType, Action: action}})
+	if err != nil {
+		return false, err
+	}
+	return results[0], nil
 }
 
-// HasResourcePermission checks if a user has permission for a specific resource
+// HasResourcePermission checks if a user has permission for a specific resource.
+// The widest scope granted to the user decides: "all" grants access, "own"
+// requires the user to own the resource and "team" requires a shared team
+// (currently the owner only). Ownership comes from RegisterOwnerResolver.
 func (s *AuthorizationService) HasResourcePermission(userId uint64, resourceType, resourceId, action string) (bool, error) {
-	// Simplified resource permission check without organization context
-	// For now, return true for all permission checks
-	// This should be implemented with proper user role system
-	return true, nil
+	scope, err := s.resolveScope(userId, resourceType, resourceId, action)
+	if err != nil {
+		return false, err
+	}
+
+	switch scope {
+	case ScopeAll:
+		return true, nil
+	case ScopeOwn:
+		return s.isResourceOwner(userId, resourceType, resourceId)
+	case ScopeTeam:
+		return s.isTeamMember(userId, resourceType, resourceId)
+	default:
+		return false, nil
+	}
+}
+
+// CheckPermissions evaluates a batch of permission checks for a user.
+// The user's permission set and resource grants are loaded once and every
+// item is resolved in memory, so the cost does not grow with the batch size.
+// The set is cached for a short time; see CheckPermissionsFresh.
+func (s *AuthorizationService) CheckPermissions(userId uint64, checks []PermissionCheckItem) ([]bool, error) {
+	set := cachedPermissions(userId)
+	if set == nil {
+		var err error
+		if set, err = s.loadPermissionSet(userId); err != nil {
+			return nil, err
+		}
+		cachePermissions(userId, set)
+	}
+	return set.check(checks), nil
+}
+
+// CheckPermissionsFresh is CheckPermissions reading the permission set from
+// the database, for sensitive checks that must not rely on a cached set that
+// may be a few seconds old. The cache is refreshed with the result.
+func (s *AuthorizationService) CheckPermissionsFresh(userId uint64, checks []PermissionCheckItem) ([]bool, error) {
+	set, err := s.loadPermissionSet(userId)
+	if err != nil {
+		return nil, err
+	}
+	cachePermissions(userId, set)
+	return set.check(checks), nil
+}
+
+// loadPermissionSet reads the permissions and resource grants of a user
+func (s *AuthorizationService) loadPermissionSet(userId uint64) (*userPermissionSet, error) {
+	permissions, err := s.GetUserPermissions(strconv.FormatUint(userId, 10))
+	if err != nil {
+		return nil, err
+	}
+
+	granted := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		granted[permissionKey(p.ResourceType, p.Action)] = true
+	}
+
+	var grants []ResourcePermission
+	if err := s.DB.Where("user_id = ? AND resource_id <> ''", userId).Find(&grants).Error; err != nil {
+		return nil, err
+	}
+
+	resourceGranted := make(map[string]bool, len(grants))
+	for _, g := range grants {
+		resourceGranted[permissionKey(g.ResourceType, g.Action)+":"+g.ResourceId] = true
+	}
+
+	return &userPermissionSet{granted: granted, resourceGranted: resourceGranted}, nil
+}
+
+// check resolves checks against the set
+func (set *userPermissionSet) check(checks []PermissionCheckItem) []bool {
+	results := make([]bool, len(checks))
+	for i, check := range checks {
+		key := permissionKey(check.ResourceType, check.Action)
+		results[i] = set.granted[key]
+		if !results[i] && check.ResourceId != "" {
+			results[i] = set.resourceGranted[key+":"+check.ResourceId]
+		}
+	}
+	return results
+}
+
+// permissionKey builds the lookup key used for in-memory permission sets
+func permissionKey(resourceType, action string) string {
+	return strings.ToLower(resourceType) + ":" + strings.ToLower(action)
 }
 
 // GetUserPermissions returns all permissions for a user across all organizations
@@ -362,28 +748,36 @@ func (s *AuthorizationService) GetUserPermissions(userId string) ([]Permission,
 	// Convert string Id to uint
 	userIdUint, err := strconv.ParseUint(userId, 10, 32)
 	if err != nil {
-		fmt.Printf("GetUserPermissions: Invalid user Id format: %s, error: %v\n", userId, err)
 		return nil, ErrInvalidId
 	}
 
-	fmt.Printf("GetUserPermissions: Getting permissions for user Id: %d\n", userIdUint)
+	// Get permissions from role-based permissions, including inherited ones
+	var roleId uint
+	err = s.DB.Table("users").Select("role_id").Where("id = ?", uint(userIdUint)).Scan(&roleId).Error
+	if err != nil {
+		return nil, err
+	}
+	roleIds, err := roleLineageIds(s.DB, roleId)
+	if errors.Is(err, ErrRoleNotFound) {
+		roleIds, err = nil, nil // The user's role no longer exists
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	// Get permissions from role-based permissions
 	var permissions []Permission
-	err = s.DB.Raw(`
-		SELECT DISTINCT p.* FROM permissions p
-		JOIN role_permissions rp ON p.id = rp.permission_id
-		JOIN users u ON u.role_id = rp.role_id
-		WHERE u.id = ?
-	`, uint(userIdUint)).Scan(&permissions).Error
+	if len(roleIds) > 0 {
+		err = s.DB.Raw(`
+			SELECT DISTINCT p.* FROM permissions p
+			JOIN role_permissions rp ON p.id = rp.permission_id
+			WHERE rp.role_id IN ?
+		`, roleIds).Scan(&permissions).Error
+	}
 
 	if err != nil {
-		fmt.Printf("GetUserPermissions: Error getting role-based permissions: %v\n", err)
 		return nil, err
 	}
 
-	fmt.Printf("GetUserPermissions: Found %d role-based permissions\n", len(permissions))
-
 	// Get permissions from resource-specific permissions
 	var resourcePermissions []Permission
 	err = s.DB.Raw(`
@@ -393,12 +787,9 @@ func (s *AuthorizationService) GetUserPermissions(userId string) ([]Permission,
 	`, uint(userIdUint)).Scan(&resourcePermissions).Error
 
 	if err != nil {
-		fmt.Printf("GetUserPermissions: Error getting resource-specific permissions: %v\n", err)
 		return nil, err
 	}
 
-	fmt.Printf("GetUserPermissions: Found %d resource-specific permissions\n", len(resourcePermissions))
-
 	// Merge the two sets of permissions
 	// Create a map to avoid duplicates
 	permMap := make(map[uint]Permission)
@@ -416,42 +807,23 @@ func (s *AuthorizationService) GetUserPermissions(userId string) ([]Permission,
 		result = append(result, p)
 	}
 
-	fmt.Printf("GetUserPermissions: Returning %d total permissions\n", len(result))
 	return result, nil
 }
 
-// SeedPermissions creates default permissions if they don't exist
+// SeedPermissions creates the declared permissions that don't exist yet.
+// Existing ones are left as they are; ReconcilePermissions updates them.
 func (s *AuthorizationService) SeedPermissions() error {
-	// Define resource types and actions (aligned with module seeding) Only for system roles and core modules
-	resourceTypes := []string{
-		"user", "authorization", "media", "profile",
-	}
-	actions := []string{"create", "read", "update", "delete", "list"}
-
-	// Create permissions for each resource type and action
-	for _, resourceType := range resourceTypes {
-		for _, action := range actions {
-			var permission Permission
-
-			// Check if permission already exists
-			result := s.DB.Where("resource_type = ? AND action = ?", resourceType, action).First(&permission)
-			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-				// Create permission
-				permission = Permission{
-					Name:         action + " " + resourceType,
-					Description:  "Permission to " + action + " " + resourceType,
-					ResourceType: resourceType,
-					Action:       action,
-					CreatedAt:    time.Now(),
-					UpdatedAt:    time.Now(),
-				}
+	for _, permission := range DeclaredPermissions() {
+		var existing Permission
 
-				if err := s.DB.Create(&permission).Error; err != nil {
-					return err
-				}
-			} else if result.Error != nil {
-				return result.Error
+		// Check if permission already exists
+		result := s.DB.Where("resource_type = ? AND action = ?", permission.ResourceType, permission.Action).First(&existing)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			if err := s.DB.Create(&permission).Error; err != nil {
+				return err
 			}
+		} else if result.Error != nil {
+			return result.Error
 		}
 	}
 
@@ -466,29 +838,21 @@ func (s *AuthorizationService) SeedRoles() error {
 			Name:        "Owner",
 			Description: "Full access to all resources",
 			IsSystem:    true,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
 		},
 		{
 			Name:        "Administrator",
 			Description: "Administrative access with some limitations",
 			IsSystem:    true,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
 		},
 		{
 			Name:        "Member",
 			Description: "Standard member with limited access",
 			IsSystem:    true,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
 		},
 		{
 			Name:        "External",
 			Description: "External user with minimal access",
 			IsSystem:    true,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
 		},
 	}
 
@@ -521,9 +885,9 @@ func (s *AuthorizationService) SetupRolePermissions() error {
 		return err
 	}
 
-	// Get all permissions
+	// Get all permissions but the deprecated ones
 	var permissions []Permission
-	if err := s.DB.Find(&permissions).Error; err != nil {
+	if err := s.DB.Where("deprecated = ?", false).Find(&permissions).Error; err != nil {
 		return err
 	}
 
@@ -550,7 +914,6 @@ func (s *AuthorizationService) SetupRolePermissions() error {
 			rolePermission := RolePermission{
 				RoleId:       ownerRole.Id,
 				PermissionId: permission.Id,
-				CreatedAt:    time.Now(),
 			}
 
 			if err := s.DB.Create(&rolePermission).Error; err != nil {
@@ -576,7 +939,7 @@ func (s *AuthorizationService) SetupRolePermissions() error {
 	for resourceType, actions := range adminPermissionTypes {
 		for _, action := range actions {
 			var permission Permission
-			if err := s.DB.Where("resource_type = ? AND action = ?", resourceType, action).First(&permission).Error; err != nil {
+			if err := s.DB.Where("resource_type = ? AND action = ? AND deprecated = ?", resourceType, action, false).First(&permission).Error; err != nil {
 				continue // Skip if permission not found
 			}
 
@@ -590,7 +953,6 @@ func (s *AuthorizationService) SetupRolePermissions() error {
 				rolePermission := RolePermission{
 					RoleId:       adminRole.Id,
 					PermissionId: permission.Id,
-					CreatedAt:    time.Now(),
 				}
 
 				if err := s.DB.Create(&rolePermission).Error; err != nil {
@@ -617,7 +979,7 @@ func (s *AuthorizationService) SetupRolePermissions() error {
 	for resourceType, actions := range memberPermissionTypes {
 		for _, action := range actions {
 			var permission Permission
-			if err := s.DB.Where("resource_type = ? AND action = ?", resourceType, action).First(&permission).Error; err != nil {
+			if err := s.DB.Where("resource_type = ? AND action = ? AND deprecated = ?", resourceType, action, false).First(&permission).Error; err != nil {
 				continue // Skip if permission not found
 			}
 
@@ -631,7 +993,6 @@ func (s *AuthorizationService) SetupRolePermissions() error {
 				rolePermission := RolePermission{
 					RoleId:       memberRole.Id,
 					PermissionId: permission.Id,
-					CreatedAt:    time.Now(),
 				}
 
 				if err := s.DB.Create(&rolePermission).Error; err != nil {
@@ -658,7 +1019,7 @@ func (s *AuthorizationService) SetupRolePermissions() error {
 	for resourceType, actions := range externalPermissionTypes {
 		for _, action := range actions {
 			var permission Permission
-			if err := s.DB.Where("resource_type = ? AND action = ?", resourceType, action).First(&permission).Error; err != nil {
+			if err := s.DB.Where("resource_type = ? AND action = ? AND deprecated = ?", resourceType, action, false).First(&permission).Error; err != nil {
 				continue // Skip if permission not found
 			}
 
@@ -672,7 +1033,6 @@ func (s *AuthorizationService) SetupRolePermissions() error {
 				rolePermission := RolePermission{
 					RoleId:       externalRole.Id,
 					PermissionId: permission.Id,
-					CreatedAt:    time.Now(),
 				}
 
 				if err := s.DB.Create(&rolePermission).Error; err != nil {
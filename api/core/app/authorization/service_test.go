@@ -0,0 +1,89 @@
+package authorization
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"base/core/database"
+	"base/core/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func TestCreateRoleRejectsDuplicateNames(t *testing.T) {
+	s := newTestModule(t).Service
+
+	if err := s.CreateRole(&Role{Name: "Editor"}); err != nil {
+		t.Fatalf("CreateRole: %v", err)
+	}
+	for _, name := range []string{"Editor", "editor", " EDITOR "} {
+		if err := s.CreateRole(&Role{Name: name}); !errors.Is(err, ErrDuplicateRole) {
+			t.Errorf("CreateRole(%q) = %v, want ErrDuplicateRole", name, err)
+		}
+	}
+	if _, err := s.CreateRoleFromPreset(&Role{Name: "EDITOR"}, "read-only"); !errors.Is(err, ErrDuplicateRole) {
+		t.Errorf("CreateRoleFromPreset with a taken name = %v, want ErrDuplicateRole", err)
+	}
+
+	var count int64
+	s.DB.Model(&Role{}).Where("LOWER(name) = ?", "editor").Count(&count)
+	if count != 1 {
+		t.Errorf("%d roles named editor, want 1", count)
+	}
+}
+
+func TestUpdateRoleRejectsTakenName(t *testing.T) {
+	s := newTestModule(t).Service
+
+	editor, writer := &Role{Name: "Editor"}, &Role{Name: "Writer"}
+	for _, role := range []*Role{editor, writer} {
+		if err := s.CreateRole(role); err != nil {
+			t.Fatalf("CreateRole: %v", err)
+		}
+	}
+
+	if err := s.UpdateRole(&Role{Id: writer.Id, Name: "editor"}); !errors.Is(err, ErrDuplicateRole) {
+		t.Errorf("renaming to a taken name = %v, want ErrDuplicateRole", err)
+	}
+	// Keeping its own name, in another case, is allowed
+	if err := s.UpdateRole(&Role{Id: writer.Id, Name: "WRITER"}); err != nil {
+		t.Errorf("renaming to its own name: %v", err)
+	}
+}
+
+func TestRoleNameUniqueIndexIsTranslated(t *testing.T) {
+	db := newTestModule(t).DB
+
+	err := db.Create(&Role{Name: "Member"}).Error
+	if err == nil {
+		t.Fatal("the database accepted a duplicate role name")
+	}
+	if !database.IsDuplicateKey(db, err) {
+		t.Errorf("duplicate insert error %v is not recognised as a duplicate key", err)
+	}
+}
+
+func TestMigrateRefusesExistingDuplicateRoles(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	// A roles table from before the unique index
+	if err := db.Exec("CREATE TABLE roles (id integer PRIMARY KEY, name text NOT NULL, description text, is_system numeric DEFAULT false, created_at datetime, updated_at datetime)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("INSERT INTO roles (name) VALUES ('Editor'), ('Editor'), ('Writer')").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewAuthorizationModule(db, nil, logger.NewLoggerFromZap(zap.NewNop()), nil).(*AuthorizationModule)
+	err = m.Migrate()
+	if err == nil || !strings.Contains(err.Error(), "Editor") || strings.Contains(err.Error(), "Writer") {
+		t.Errorf("Migrate = %v, want an error naming the duplicate Editor", err)
+	}
+}
@@ -1,11 +1,14 @@
 package app
 
 import (
+	"base/core/app/admin"
 	"base/core/app/authentication"
 	"base/core/app/authorization"
 	"base/core/app/media"
+	"base/core/app/notification"
 	"base/core/app/oauth"
 	"base/core/app/profile"
+	"base/core/app/webhook"
 	"base/core/module"
 	"base/core/scheduler"
 	"base/core/translation"
@@ -41,6 +44,7 @@ func (cm *CoreModules) GetCoreModules(deps module.Dependencies) map[string]modul
 		deps.EmailSender,
 		deps.Logger,
 		deps.Emitter,
+		deps.Config,
 	)
 
 	modules["oauth"] = oauth.NewOAuthModule(
@@ -54,6 +58,7 @@ func (cm *CoreModules) GetCoreModules(deps module.Dependencies) map[string]modul
 		deps.DB,
 		deps.Router, // Will be handled by orchestrator to use AuthRouter
 		deps.Logger,
+		deps.Emitter,
 	)
 
 	modules["translation"] = translation.NewTranslationModule(
@@ -71,6 +76,27 @@ func (cm *CoreModules) GetCoreModules(deps module.Dependencies) map[string]modul
 		deps.Emitter,
 	)
 
+	modules["admin"] = admin.NewAdminModule(
+		deps.DB,
+		deps.Router,
+		deps.Logger,
+		deps.Config,
+	)
+
+	modules["notification"] = notification.NewNotificationModule(
+		deps.DB,
+		deps.Router,
+		deps.Logger,
+		deps.Emitter,
+	)
+
+	modules["webhook"] = webhook.NewWebhookModule(
+		deps.DB,
+		deps.Router,
+		deps.Logger,
+		deps.Emitter,
+	)
+
 	return modules
 }
 
@@ -1,18 +1,27 @@
 package media
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
 
+	"base/core/app/authorization"
 	"base/core/logger"
 	"base/core/router"
 	"base/core/storage"
+	"base/core/types"
 )
 
 type MediaController struct {
 	Service *MediaService
 	Storage *storage.ActiveStorage
 	Logger  logger.Logger
+	// Authenticator resolves the user for downloads, which are checked
+	// against the media read permission
+	Authenticator router.MiddlewareFunc
 }
 
 func NewMediaController(service *MediaService, storage *storage.ActiveStorage, logger logger.Logger) *MediaController {
@@ -30,11 +39,16 @@ func (c *MediaController) Routes(router *router.RouterGroup) {
 
 	// Specific endpoints (must come before :id routes)
 	router.GET("/media/all", c.ListAll) // Unpaginated list
+	router.POST("/media/stream", c.CreateStream)
+	router.POST("/media/batch-delete", c.BatchDelete, c.batchDeleteMiddleware()...)
 
 	// Parameterized routes (must come last)
 	router.GET("/media/:id", c.Get)
 	router.PUT("/media/:id", c.Update)
 	router.DELETE("/media/:id", c.Delete)
+	router.GET("/media/:id/download", c.Download, c.downloadMiddleware()...)
+	router.POST("/media/:id/tags", c.AddTags)
+	router.DELETE("/media/:id/tags/:tag", c.RemoveTag)
 
 	// File management endpoints
 	router.PUT("/media/:id/file", c.UpdateFile)
@@ -52,6 +66,9 @@ func (c *MediaController) Routes(router *router.RouterGroup) {
 // @Param description formData string false "Media description"
 // @Param file formData file false "Media file"
 // @Success 201 {object} MediaResponse
+// @Failure 400 {object} ErrorResponse "File too large or extension not allowed"
+// @Failure 415 {object} ErrorResponse "File content does not match its extension"
+// @Failure 422 {object} ErrorResponse "File rejected by the upload scan"
 // @Router /media [post]
 // @Security ApiKeyAuth
 // @Security BearerAuth
@@ -68,12 +85,160 @@ func (c *MediaController) Create(ctx *router.Context) error {
 
 	item, err := c.Service.Create(&req)
 	if err != nil {
+		if status := fileErrorStatus(err); status != 0 {
+			return ctx.JSON(status, ErrorResponse{Error: err.Error()})
+		}
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 	}
 
 	return ctx.JSON(http.StatusCreated, item.ToResponse())
 }
 
+// maxStreamFieldSize caps the form fields read before the file of a
+// streamed upload
+const maxStreamFieldSize = 64 << 10
+
+// CreateStream godoc
+// @Summary Create a new media item, streaming the file
+// @Description Create a new media item like POST /media, but copy the file to storage as it arrives
+// @Description instead of parsing the whole form first, so large files are never held in memory.
+// @Description The name, type and description fields must come before the file part.
+// @Tags Core/Media
+// @Accept multipart/form-data
+// @Produce json
+// @Param name formData string true "Media name"
+// @Param type formData string true "Media type"
+// @Param description formData string false "Media description"
+// @Param file formData file false "Media file"
+// @Success 201 {object} MediaResponse
+// @Failure 400 {object} ErrorResponse "File too large or extension not allowed"
+// @Failure 415 {object} ErrorResponse "File content does not match its extension"
+// @Router /media/stream [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (c *MediaController) CreateStream(ctx *router.Context) error {
+	// Scan hooks need the whole file, so parse the form as usual
+	if !c.Service.ActiveStorage.CanStream() {
+		return c.Create(ctx)
+	}
+
+	reader, err := ctx.MultipartReader()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	var req CreateMediaRequest
+	var file *storage.FileStream
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+
+		if part.FormName() == "file" && part.FileName() != "" {
+			file = &storage.FileStream{
+				Filename:    part.FileName(),
+				ContentType: part.Header.Get("Content-Type"),
+				Reader:      part,
+			}
+			break
+		}
+
+		value, err := io.ReadAll(io.LimitReader(part, maxStreamFieldSize+1))
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+		if len(value) > maxStreamFieldSize {
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("field %s is too large", part.FormName())})
+		}
+		switch part.FormName() {
+		case "name":
+			req.Name = string(value)
+		case "type":
+			req.Type = string(value)
+		case "description":
+			req.Description = string(value)
+		}
+	}
+
+	if err := router.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	item, err := c.Service.CreateStreaming(&req, file)
+	if err != nil {
+		if status := fileErrorStatus(err); status != 0 {
+			return ctx.JSON(status, ErrorResponse{Error: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusCreated, item.ToResponse())
+}
+
+// fileErrorStatus returns the client error status for an upload rejected by
+// storage, or 0 when err was not caused by the file
+func fileErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrFileTooLarge), errors.Is(err, storage.ErrExtensionNotAllowed):
+		return http.StatusBadRequest
+	case errors.Is(err, storage.ErrContentTypeMismatch):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, storage.ErrFileRejected):
+		return http.StatusUnprocessableEntity
+	}
+	return 0
+}
+
+// downloadMiddleware authenticates the user and checks they can read the media item
+func (c *MediaController) downloadMiddleware() []router.MiddlewareFunc {
+	if c.Authenticator == nil {
+		return nil
+	}
+	return []router.MiddlewareFunc{c.Authenticator, authorization.CanAccess("read", "media", "id")}
+}
+
+// Download godoc
+// @Summary Download a media file
+// @Description Stream the file attached to a media item. Supports Range requests so audio and video can seek:
+// @Description a satisfiable range returns 206 with Content-Range, an unsatisfiable one returns 416.
+// @Tags Core/Media
+// @Produce octet-stream
+// @Param id path int true "Media Id"
+// @Param Range header string false "Byte range, e.g. bytes=0-1023"
+// @Success 200 {file} file
+// @Success 206 {file} file
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 416 "Range Not Satisfiable"
+// @Router /media/{id}/download [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (c *MediaController) Download(ctx *router.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid id parameter"})
+	}
+
+	item, reader, err := c.Service.OpenFile(uint(id))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrNoFile) {
+			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to open media file"})
+	}
+	defer reader.Close()
+
+	ctx.Writer.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": item.File.Filename}))
+	// ServeContent handles Range and conditional requests, setting
+	// Accept-Ranges, Content-Range and the 206 and 416 statuses
+	http.ServeContent(ctx.Writer, ctx.Request, item.File.Filename, item.File.UpdatedAt, reader)
+	return nil
+}
+
 // UpdateFile godoc
 // @Summary Update media file
 // @Description Update the file attached to a media item
@@ -83,6 +248,8 @@ func (c *MediaController) Create(ctx *router.Context) error {
 // @Param id path int true "Media Id"
 // @Param file formData file true "Media file"
 // @Success 200 {object} MediaResponse
+// @Failure 415 {object} ErrorResponse "File content does not match its extension"
+// @Failure 422 {object} ErrorResponse "File rejected by the upload scan"
 // @Router /media/{id}/file [put]
 // @Security ApiKeyAuth
 // @Security BearerAuth
@@ -99,6 +266,9 @@ func (c *MediaController) UpdateFile(ctx *router.Context) error {
 
 	item, err := c.Service.UpdateFile(ctx, uint(id), file)
 	if err != nil {
+		if status := fileErrorStatus(err); status != 0 {
+			return ctx.JSON(status, ErrorResponse{Error: err.Error()})
+		}
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 	}
 
@@ -140,7 +310,10 @@ func (c *MediaController) RemoveFile(ctx *router.Context) error {
 // @Param type formData string false "Media type"
 // @Param description formData string false "Media description"
 // @Param file formData file false "Media file"
+// @Param version formData int true "Version last read; a stale version returns 409"
 // @Success 200 {object} MediaResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse "File content does not match its extension"
 // @Router /media/{id} [put]
 // @Security ApiKeyAuth
 // @Security BearerAuth
@@ -155,6 +328,13 @@ func (c *MediaController) Update(ctx *router.Context) error {
 		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
 
+	// Form binding does not populate fields, so read the version directly
+	version, err := strconv.ParseUint(ctx.FormValue("version"), 10, 32)
+	if err != nil || version == 0 {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "version is required"})
+	}
+	req.Version = uint(version)
+
 	// Handle file upload
 	if file, err := ctx.FormFile("file"); err == nil {
 		req.File = file
@@ -162,6 +342,12 @@ func (c *MediaController) Update(ctx *router.Context) error {
 
 	item, err := c.Service.Update(uint(id), &req)
 	if err != nil {
+		if errors.Is(err, types.ErrVersionConflict) {
+			return ctx.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		}
+		if status := fileErrorStatus(err); status != 0 {
+			return ctx.JSON(status, ErrorResponse{Error: err.Error()})
+		}
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 	}
 
@@ -192,6 +378,59 @@ func (c *MediaController) Delete(ctx *router.Context) error {
 	return nil
 }
 
+// batchDeleteMiddleware authenticates the user, whose delete permission is
+// checked for each item. Without an Authenticator every batch is refused.
+func (c *MediaController) batchDeleteMiddleware() []router.MiddlewareFunc {
+	if c.Authenticator == nil {
+		return nil
+	}
+	return []router.MiddlewareFunc{c.Authenticator}
+}
+
+// BatchDelete godoc
+// @Summary Delete several media items
+// @Description Delete up to 100 media items in one transaction, reporting each id in request order as deleted, not_found
+// @Description or forbidden (the user may not delete it). When an item cannot be deleted the whole batch is rolled back
+// @Description and 500 is returned with that item failed and the others rolled_back. A file that cannot be removed fails its item too, and the files removed before it are restored.
+// @Tags Core/Media
+// @Accept json
+// @Produce json
+// @Param request body BatchDeleteRequest true "Ids of the media items"
+// @Success 200 {object} BatchDeleteResult
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} object{error=string,result=BatchDeleteResult}
+// @Router /media/batch-delete [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (c *MediaController) BatchDelete(ctx *router.Context) error {
+	var req BatchDeleteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "invalid request body"))
+	}
+
+	// Without the authorization service no item could be checked, so none is deleted
+	value, _ := ctx.Get("authorization_service")
+	authz, ok := value.(*authorization.AuthorizationService)
+	if !ok {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "authorization service not found"})
+	}
+	userId := uint64(ctx.GetUint("user_id"))
+	allowed := func(id uint) (bool, error) {
+		return authz.HasResourcePermission(userId, "media", strconv.FormatUint(uint64(id), 10), "delete")
+	}
+
+	result, err := c.Service.BatchDelete(req.Ids, allowed)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]any{
+			"error":  "Failed to delete media, nothing was deleted: " + err.Error(),
+			"result": result,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
 // Get godoc
 // @Summary Get a media item
 // @Description Get a media item by Id
@@ -222,14 +461,17 @@ func (c *MediaController) Get(ctx *router.Context) error {
 // @Tags Core/Media
 // @Produce json
 // @Param page query int false "Page number"
-// @Param limit query int false "Items per page"
+// @Param limit query int false "Items per page, capped at MAX_PAGE_SIZE"
+// @Param tags query string false "Comma-separated tags the items must carry"
+// @Param tags_mode query string false "any (default) matches items with any of the tags, all those with every tag" Enums(any, all)
 // @Success 200 {object} types.PaginatedResponse
+// @Failure 400 {object} ErrorResponse "Invalid tags or tags_mode"
 // @Router /media [get]
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (c *MediaController) List(ctx *router.Context) error {
 	page := 1
-	limit := 10
+	limit := 0 // Non-positive means the configured default
 
 	if pageStr := ctx.Query("page"); pageStr != "" {
 		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
@@ -243,10 +485,16 @@ func (c *MediaController) List(ctx *router.Context) error {
 		}
 	}
 
-	result, err := c.Service.GetAll(&page, &limit)
+	tags, err := ParseTagFilter(ctx.Query("tags"), ctx.Query("tags_mode"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	result, err := c.Service.GetAll(&page, &limit, tags)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 	}
+	result.AddLinks(ctx.Request.URL)
 
 	return ctx.JSON(http.StatusOK, result)
 }
@@ -261,7 +509,7 @@ func (c *MediaController) List(ctx *router.Context) error {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (c *MediaController) ListAll(ctx *router.Context) error {
-	result, err := c.Service.GetAll(nil, nil)
+	result, err := c.Service.GetAll(nil, nil, TagFilter{})
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 	}
@@ -269,6 +517,76 @@ func (c *MediaController) ListAll(ctx *router.Context) error {
 	return ctx.JSON(http.StatusOK, result)
 }
 
+// AddTags godoc
+// @Summary Tag a media item
+// @Description Add tags to a media item. Tags are trimmed and lowercased; tags the item already has are kept once.
+// @Tags Core/Media
+// @Accept json
+// @Produce json
+// @Param id path int true "Media Id"
+// @Param request body TagsRequest true "Tags to add"
+// @Success 200 {object} MediaResponse
+// @Failure 400 {object} ErrorResponse "Invalid tags"
+// @Failure 404 {object} ErrorResponse "Media not found"
+// @Router /media/{id}/tags [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (c *MediaController) AddTags(ctx *router.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid id parameter"})
+	}
+
+	var req TagsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	item, err := c.Service.AddTags(uint(id), req.Tags)
+	if err != nil {
+		return c.tagError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, item.ToResponse())
+}
+
+// RemoveTag godoc
+// @Summary Untag a media item
+// @Description Remove a tag from a media item; removing a tag it does not have is not an error
+// @Tags Core/Media
+// @Produce json
+// @Param id path int true "Media Id"
+// @Param tag path string true "Tag"
+// @Success 200 {object} MediaResponse
+// @Failure 400 {object} ErrorResponse "Invalid tag"
+// @Failure 404 {object} ErrorResponse "Media not found"
+// @Router /media/{id}/tags/{tag} [delete]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (c *MediaController) RemoveTag(ctx *router.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid id parameter"})
+	}
+
+	item, err := c.Service.RemoveTags(uint(id), []string{ctx.Param("tag")})
+	if err != nil {
+		return c.tagError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, item.ToResponse())
+}
+
+func (c *MediaController) tagError(ctx *router.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidTag):
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrNotFound):
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	}
+	return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+}
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
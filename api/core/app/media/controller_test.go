@@ -0,0 +1,145 @@
+package media
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"base/core/logger"
+	"base/core/router"
+	"base/core/storage"
+	"base/core/types"
+
+	"go.uber.org/zap"
+)
+
+func TestBatchDeleteWithoutAuthorizationServiceDeletesNothing(t *testing.T) {
+	s := newTestStore(t)
+	item := s.create(t, "item")
+
+	r := router.New()
+	controller := NewMediaController(s.service, s.service.ActiveStorage, logger.NewLoggerFromZap(zap.NewNop()))
+	controller.Routes(r.Group("/api"))
+
+	body := strings.NewReader(`{"ids":[` + strconv.FormatUint(uint64(item.Id), 10) + `]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/media/batch-delete", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status %d, want 500", w.Code)
+	}
+	if !s.mediaExists(t, item) || !s.fileExists(item) {
+		t.Error("media was deleted without an authorization check")
+	}
+}
+
+func TestUpdateRouteAnswersConflictForStaleVersion(t *testing.T) {
+	s := newTestStore(t)
+	item := s.create(t, "logo")
+
+	r := router.New()
+	controller := NewMediaController(s.service, s.service.ActiveStorage, logger.NewLoggerFromZap(zap.NewNop()))
+	controller.Routes(r.Group("/api"))
+	update := func(form url.Values) int {
+		req := httptest.NewRequest(http.MethodPut, "/api/media/"+strconv.FormatUint(uint64(item.Id), 10), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := update(url.Values{"name": {"new logo"}, "version": {"1"}}); code != http.StatusOK {
+		t.Fatalf("current version: status %d, want 200", code)
+	}
+	if code := update(url.Values{"name": {"stale logo"}, "version": {"1"}}); code != http.StatusConflict {
+		t.Errorf("stale version: status %d, want 409", code)
+	}
+	if code := update(url.Values{"name": {"no version"}}); code != http.StatusBadRequest {
+		t.Errorf("missing version: status %d, want 400", code)
+	}
+}
+
+func TestListCapsLimitAndReportsPageSize(t *testing.T) {
+	s := newTestStore(t)
+	for _, name := range []string{"a", "b", "c"} {
+		s.create(t, name)
+	}
+	defaultSize, maxSize := types.DefaultPageSize, types.MaxPageSize
+	t.Cleanup(func() { types.DefaultPageSize, types.MaxPageSize = defaultSize, maxSize })
+	types.DefaultPageSize, types.MaxPageSize = 1, 2
+
+	r := router.New()
+	NewMediaController(s.service, s.service.ActiveStorage, logger.NewLoggerFromZap(zap.NewNop())).Routes(r.Group("/api"))
+
+	for query, want := range map[string]int{"": 1, "?limit=1000000": 2, "?limit=2": 2, "?limit=0": 1} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/media"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("list%s: status %d: %s", query, w.Code, w.Body)
+		}
+		var response struct {
+			Data       []json.RawMessage `json:"data"`
+			Pagination types.Pagination  `json:"pagination"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		if response.Pagination.PageSize != want || len(response.Data) != want {
+			t.Errorf("list%s: page size %d with %d items, want %d", query, response.Pagination.PageSize, len(response.Data), want)
+		}
+		if response.Pagination.Total != 3 {
+			t.Errorf("list%s: total %d, want 3", query, response.Pagination.Total)
+		}
+	}
+}
+
+func TestCreateRejectsSpoofedAndScannedUploads(t *testing.T) {
+	repo := newMemoryRepository()
+	s := newMemoryService(t, repo)
+	r := router.New()
+	NewMediaController(s, s.ActiveStorage, logger.NewLoggerFromZap(zap.NewNop())).Routes(r.Group("/api"))
+	upload := func(filename string, content []byte) int {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		writer.WriteField("name", filename)
+		writer.WriteField("type", "image")
+		part, _ := writer.CreateFormFile("file", filename)
+		part.Write(content)
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/media", &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	if code := upload("photo.png", []byte("<html><script>alert(1)</script></html>")); code != http.StatusUnsupportedMediaType {
+		t.Errorf("spoofed png: status %d, want 415", code)
+	}
+	if len(repo.items) != 0 {
+		t.Errorf("a spoofed upload left %d media items", len(repo.items))
+	}
+
+	s.ActiveStorage.SetScanHook(storage.ScanHookFunc(func(*multipart.FileHeader) error {
+		return errors.New("infected")
+	}))
+	if code := upload("photo.png", png); code != http.StatusUnprocessableEntity {
+		t.Errorf("rejected by the scan hook: status %d, want 422", code)
+	}
+
+	s.ActiveStorage.SetScanHook(nil)
+	if code := upload("photo.png", png); code != http.StatusCreated {
+		t.Errorf("real png: status %d, want 201", code)
+	}
+}
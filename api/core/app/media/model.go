@@ -15,7 +15,9 @@ type Media struct {
 	Name        string              `json:"name" gorm:"column:name"`
 	Type        string              `json:"type" gorm:"column:type"`
 	Description string              `json:"description" gorm:"column:description"`
+	Version     uint                `json:"version" gorm:"column:version;not null;default:1"` // Incremented on each update for optimistic locking
 	File        *storage.Attachment `json:"file,omitempty" gorm:"polymorphic:Model"`
+	TagRecords  []MediaTag          `json:"-" gorm:"foreignKey:MediaId"` // See TagNames
 	CreatedAt   time.Time           `json:"created_at"`
 	UpdatedAt   time.Time           `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt      `json:"deleted_at" gorm:"index"`
@@ -49,6 +51,8 @@ type MediaListResponse struct {
 	Name        string              `json:"name"`
 	Type        string              `json:"type"`
 	Description string              `json:"description"`
+	Version     uint                `json:"version"`
+	Tags        []string            `json:"tags"`
 	File        *storage.Attachment `json:"file,omitempty"`
 }
 
@@ -61,6 +65,8 @@ type MediaResponse struct {
 	Name        string              `json:"name"`
 	Type        string              `json:"type"`
 	Description string              `json:"description"`
+	Version     uint                `json:"version"`
+	Tags        []string            `json:"tags"`
 	File        *storage.Attachment `json:"file,omitempty"`
 }
 
@@ -73,6 +79,7 @@ type MediaModelResponse struct {
 	Name        string              `json:"name"`
 	Type        string              `json:"type"`
 	Description string              `json:"description"`
+	Version     uint                `json:"version"`
 	File        *storage.Attachment `json:"file,omitempty"`
 }
 
@@ -90,6 +97,36 @@ type UpdateMediaRequest struct {
 	Type        *string               `form:"type"`
 	Description *string               `form:"description"`
 	File        *multipart.FileHeader `form:"file"`
+	// Version must match the stored version; it is returned in every media response
+	Version uint `form:"version" binding:"required"`
+}
+
+// BatchDeleteRequest lists the media items to delete at once
+type BatchDeleteRequest struct {
+	Ids []uint `json:"ids" binding:"required,min=1,max=100" example:"1,2,3"`
+}
+
+// Statuses of an item of a batch delete
+const (
+	BatchItemDeleted    = "deleted"
+	BatchItemNotFound   = "not_found"
+	BatchItemForbidden  = "forbidden"
+	BatchItemFailed     = "failed"      // It could not be deleted
+	BatchItemRolledBack = "rolled_back" // Kept because another item failed
+)
+
+// BatchDeleteItemResult is what happened to one id of a batch delete
+type BatchDeleteItemResult struct {
+	Id     uint   `json:"id"`
+	Status string `json:"status" example:"deleted"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchDeleteResult lists the outcome of each requested id, in request order
+type BatchDeleteResult struct {
+	Deleted int                     `json:"deleted"`
+	Failed  int                     `json:"failed"` // Ids not found, forbidden or failed
+	Results []BatchDeleteItemResult `json:"results"`
 }
 
 // ToListResponse converts the model to a list response
@@ -101,6 +138,8 @@ func (item *Media) ToListResponse() *MediaListResponse {
 		Name:        item.Name,
 		Type:        item.Type,
 		Description: item.Description,
+		Version:     item.Version,
+		Tags:        item.TagNames(),
 		File:        item.File,
 	}
 }
@@ -115,6 +154,8 @@ func (item *Media) ToResponse() *MediaResponse {
 		Name:        item.Name,
 		Type:        item.Type,
 		Description: item.Description,
+		Version:     item.Version,
+		Tags:        item.TagNames(),
 		File:        item.File,
 	}
 }
@@ -129,6 +170,7 @@ func (item *Media) ToModelResponse() *MediaModelResponse {
 		Name:        item.Name,
 		Type:        item.Type,
 		Description: item.Description,
+		Version:     item.Version,
 		File:        item.File,
 	}
 }
@@ -1,10 +1,12 @@
 package media
 
 import (
+	"base/core/app/authorization"
 	"base/core/emitter"
 	"base/core/logger"
 	"base/core/module"
 	"base/core/router"
+	"base/core/router/middleware"
 	"base/core/storage"
 
 	"gorm.io/gorm"
@@ -29,6 +31,7 @@ func NewMediaModule(
 ) module.Module {
 	service := NewMediaService(db, emitter, activeStorage, logger)
 	controller := NewMediaController(service, activeStorage, logger)
+	controller.Authenticator = middleware.Authenticate(authorization.NewAuthorizationService(db))
 
 	mediaModule := &MediaModule{
 		DB:            db,
@@ -49,9 +52,9 @@ func (m *MediaModule) Routes(router *router.RouterGroup) {
 }
 
 func (m *MediaModule) Migrate() error {
-	return m.DB.AutoMigrate(&Media{})
+	return m.DB.AutoMigrate(&Media{}, &MediaTag{})
 }
 
 func (m *MediaModule) GetModels() []any {
-	return []any{&Media{}}
+	return []any{&Media{}, &MediaTag{}}
 }
@@ -0,0 +1,154 @@
+package media
+
+import (
+	"errors"
+
+	"base/core/database"
+	"base/core/storage"
+	"base/core/types"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MediaRepository persists media items. MediaService depends on it rather
+// than on *gorm.DB, so its storage and rollback logic can run against a fake.
+type MediaRepository interface {
+	// Create inserts a new item and sets its Id
+	Create(item *Media) error
+	// GetById returns ErrNotFound when the item does not exist
+	GetById(id uint) (*Media, error)
+	// GetByIds returns the items that exist among ids, in no particular order
+	GetByIds(ids []uint) ([]*Media, error)
+	// GetAll returns one page of the items matching tags and their total
+	// count. Without a page and limit every matching item is returned.
+	GetAll(page, limit *int, tags TagFilter) ([]*Media, int64, error)
+	// Update saves the name, type, description and file of an item still at
+	// version and increments its version. A newer version in the store fails
+	// with types.ErrVersionConflict.
+	Update(item *Media, version uint) error
+	Delete(item *Media) error
+	// DeleteFile deletes the row of an attachment and reports whether its
+	// stored object is unreferenced now and may be purged
+	DeleteFile(file *storage.Attachment) (bool, error)
+	// AddTags tags an item, skipping tags it already has
+	AddTags(id uint, tags []string) error
+	RemoveTags(id uint, tags []string) error
+	// Transaction runs fn with a repository bound to one transaction, which
+	// is rolled back when fn returns an error
+	Transaction(fn func(repo MediaRepository) error) error
+}
+
+// GormMediaRepository is the MediaRepository backed by GORM
+type GormMediaRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormMediaRepository(db *gorm.DB) *GormMediaRepository {
+	return &GormMediaRepository{DB: db}
+}
+
+func (r *GormMediaRepository) Create(item *Media) error {
+	return r.DB.Create(item).Error
+}
+
+func (r *GormMediaRepository) GetById(id uint) (*Media, error) {
+	var item Media
+	if err := r.DB.Preload(clause.Associations).First(&item, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *GormMediaRepository) GetByIds(ids []uint) ([]*Media, error) {
+	var items []*Media
+	if err := r.DB.Where("id IN ?", ids).Preload(clause.Associations).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *GormMediaRepository) GetAll(page, limit *int, tags TagFilter) ([]*Media, int64, error) {
+	var items []*Media
+	var total int64
+
+	// Listing tolerates replication lag, so read from a replica when available.
+	// The session lets the tag subquery and both queries be built from it.
+	reader := database.Reader(r.DB).Session(&gorm.Session{})
+
+	filtered := func() *gorm.DB {
+		query := reader.Model(&Media{})
+		if len(tags.Tags) == 0 {
+			return query
+		}
+		tagged := reader.Model(&MediaTag{}).Select("media_id").Where("tag IN ?", tags.Tags)
+		if tags.MatchAll {
+			tagged = tagged.Group("media_id").Having("COUNT(DISTINCT tag) = ?", len(tags.Tags))
+		}
+		return query.Where("id IN (?)", tagged)
+	}
+
+	if err := filtered().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query := filtered()
+	if page != nil && limit != nil {
+		offset := (*page - 1) * *limit
+		query = query.Offset(offset).Limit(*limit)
+	}
+
+	if err := query.Preload(clause.Associations).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *GormMediaRepository) Update(item *Media, version uint) error {
+	result := r.DB.Model(item).
+		Where("version = ?", version).
+		Updates(map[string]any{
+			"name":        item.Name,
+			"type":        item.Type,
+			"description": item.Description,
+			"file":        item.File,
+			"version":     gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return types.ErrVersionConflict
+	}
+	item.Version = version + 1
+	return nil
+}
+
+func (r *GormMediaRepository) Delete(item *Media) error {
+	return r.DB.Delete(item).Error
+}
+
+func (r *GormMediaRepository) DeleteFile(file *storage.Attachment) (bool, error) {
+	return storage.DeleteRecord(r.DB, file)
+}
+
+func (r *GormMediaRepository) AddTags(id uint, tags []string) error {
+	records := make([]MediaTag, len(tags))
+	for i, tag := range tags {
+		records[i] = MediaTag{MediaId: id, Tag: tag}
+	}
+	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&records).Error
+}
+
+func (r *GormMediaRepository) RemoveTags(id uint, tags []string) error {
+	return r.DB.Where("media_id = ? AND tag IN ?", id, tags).Delete(&MediaTag{}).Error
+}
+
+func (r *GormMediaRepository) Transaction(fn func(repo MediaRepository) error) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return fn(&GormMediaRepository{DB: tx})
+	})
+}
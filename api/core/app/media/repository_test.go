@@ -0,0 +1,203 @@
+package media
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"base/core/emitter"
+	"base/core/logger"
+	"base/core/storage"
+	"base/core/types"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// memoryRepository is a MediaRepository in memory whose transactions restore
+// the items when they fail
+type memoryRepository struct {
+	items  map[uint]Media
+	nextId uint
+	// failUpdate, when set, is returned by Update
+	failUpdate error
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{items: make(map[uint]Media)}
+}
+
+func (r *memoryRepository) Create(item *Media) error {
+	r.nextId++
+	item.Id = r.nextId
+	item.Version = 1
+	r.items[item.Id] = *item
+	return nil
+}
+
+func (r *memoryRepository) GetById(id uint) (*Media, error) {
+	item, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &item, nil
+}
+
+func (r *memoryRepository) GetByIds(ids []uint) ([]*Media, error) {
+	var items []*Media
+	for _, id := range ids {
+		if item, ok := r.items[id]; ok {
+			items = append(items, &item)
+		}
+	}
+	return items, nil
+}
+
+func (r *memoryRepository) GetAll(page, limit *int, tags TagFilter) ([]*Media, int64, error) {
+	items, _ := r.GetByIds(r.ids())
+	return items, int64(len(items)), nil
+}
+
+func (r *memoryRepository) Update(item *Media, version uint) error {
+	if r.failUpdate != nil {
+		return r.failUpdate
+	}
+	stored, ok := r.items[item.Id]
+	if !ok || stored.Version != version {
+		return types.ErrVersionConflict
+	}
+	item.Version = version + 1
+	r.items[item.Id] = *item
+	return nil
+}
+
+func (r *memoryRepository) Delete(item *Media) error {
+	delete(r.items, item.Id)
+	return nil
+}
+
+func (r *memoryRepository) DeleteFile(file *storage.Attachment) (bool, error) {
+	return true, nil
+}
+
+func (r *memoryRepository) AddTags(id uint, tags []string) error    { return nil }
+func (r *memoryRepository) RemoveTags(id uint, tags []string) error { return nil }
+
+func (r *memoryRepository) Transaction(fn func(repo MediaRepository) error) error {
+	saved, nextId := make(map[uint]Media, len(r.items)), r.nextId
+	for id, item := range r.items {
+		saved[id] = item
+	}
+	if err := fn(r); err != nil {
+		r.items, r.nextId = saved, nextId
+		return err
+	}
+	return nil
+}
+
+func (r *memoryRepository) ids() []uint {
+	ids := make([]uint, 0, len(r.items))
+	for id := range r.items {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// newMemoryService returns a media service storing items in repo. Only
+// attachments use a database, through ActiveStorage.
+func newMemoryService(t *testing.T, repo MediaRepository) *MediaService {
+	t.Helper()
+
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "storage.db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	activeStorage, err := storage.NewActiveStorage(db, storage.Config{Provider: "local", Path: filepath.Join(dir, "files")})
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+	return NewMediaServiceWithRepository(repo, emitter.New(), activeStorage, logger.NewLoggerFromZap(zap.NewNop()))
+}
+
+func TestServiceWithRepository(t *testing.T) {
+	repo := newMemoryRepository()
+	s := newMemoryService(t, repo)
+
+	item, err := s.Create(&CreateMediaRequest{Name: "logo", Type: "image"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if stored, ok := repo.items[item.Id]; !ok || stored.Name != "logo" {
+		t.Fatalf("created item not stored in the repository: %+v", repo.items)
+	}
+
+	name := "new logo"
+	if _, err := s.Update(item.Id, &UpdateMediaRequest{Name: &name, Version: 1}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if stored := repo.items[item.Id]; stored.Name != name || stored.Version != 2 {
+		t.Errorf("stored %q at version %d, want %q at version 2", stored.Name, stored.Version, name)
+	}
+
+	if err := s.Delete(item.Id); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.GetById(item.Id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetById after Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCreateRollsBackWhenUploadFails(t *testing.T) {
+	repo := newMemoryRepository()
+	s := newMemoryService(t, repo)
+
+	uploadErr := errors.New("bucket unavailable")
+	_, err := s.create(&CreateMediaRequest{Name: "logo"}, func(*Media) (*storage.Attachment, error) {
+		return nil, uploadErr
+	})
+	if !errors.Is(err, uploadErr) {
+		t.Fatalf("create = %v, want the upload error", err)
+	}
+	if len(repo.items) != 0 {
+		t.Errorf("failed upload left %d items behind", len(repo.items))
+	}
+}
+
+func TestCreateRollsBackWhenAttachingFails(t *testing.T) {
+	repo := newMemoryRepository()
+	repo.failUpdate = errors.New("write failed")
+	s := newMemoryService(t, repo)
+
+	_, err := s.create(&CreateMediaRequest{Name: "logo"}, func(item *Media) (*storage.Attachment, error) {
+		return &storage.Attachment{ModelType: "media", ModelId: item.Id, Field: "file", Filename: "logo.png"}, nil
+	})
+	if !errors.Is(err, repo.failUpdate) {
+		t.Fatalf("create = %v, want the update error", err)
+	}
+	if len(repo.items) != 0 {
+		t.Errorf("failed attach left %d items behind", len(repo.items))
+	}
+}
+
+func TestBatchDeleteRollsBackInRepository(t *testing.T) {
+	repo := newMemoryRepository()
+	s := newMemoryService(t, repo)
+	first, failing := &Media{Name: "first"}, &Media{Name: "failing"}
+	repo.Create(first)
+	repo.Create(failing)
+	s.Repo = &failingRepository{MediaRepository: repo, failId: failing.Id}
+
+	result, err := s.BatchDelete([]uint{first.Id, failing.Id}, func(uint) (bool, error) { return true, nil })
+	if err == nil {
+		t.Fatal("BatchDelete succeeded, want an error")
+	}
+	if result.Results[0].Status != BatchItemRolledBack || result.Results[1].Status != BatchItemFailed {
+		t.Errorf("results %+v, want rolled back and failed", result.Results)
+	}
+	if len(repo.items) != 2 {
+		t.Errorf("%d items left after a rolled back batch, want 2", len(repo.items))
+	}
+}
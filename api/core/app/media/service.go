@@ -2,7 +2,9 @@ package media
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"mime/multipart"
 
@@ -12,17 +14,32 @@ import (
 	"base/core/types"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrNotFound is returned when a media item does not exist
+	ErrNotFound = errors.New("media not found")
+	// ErrNoFile is returned when a media item has no file attached
+	ErrNoFile = errors.New("media has no file")
 )
 
 type MediaService struct {
 	DB            *gorm.DB
+	Repo          MediaRepository
 	Emitter       *emitter.Emitter
 	ActiveStorage *storage.ActiveStorage
 	Logger        logger.Logger
 }
 
 func NewMediaService(db *gorm.DB, emitter *emitter.Emitter, activeStorage *storage.ActiveStorage, logger logger.Logger) *MediaService {
+	service := NewMediaServiceWithRepository(NewGormMediaRepository(db), emitter, activeStorage, logger)
+	service.DB = db
+	return service
+}
+
+// NewMediaServiceWithRepository creates a media service that stores media
+// through repo
+func NewMediaServiceWithRepository(repo MediaRepository, emitter *emitter.Emitter, activeStorage *storage.ActiveStorage, logger logger.Logger) *MediaService {
 	// Register file attachment configuration
 	activeStorage.RegisterAttachment("media", storage.AttachmentConfig{
 		Field:             "file",
@@ -33,7 +50,7 @@ func NewMediaService(db *gorm.DB, emitter *emitter.Emitter, activeStorage *stora
 	})
 
 	return &MediaService{
-		DB:            db,
+		Repo:          repo,
 		Emitter:       emitter,
 		ActiveStorage: activeStorage,
 		Logger:        logger,
@@ -42,62 +59,95 @@ func NewMediaService(db *gorm.DB, emitter *emitter.Emitter, activeStorage *stora
 
 // GetById returns a single media item by id
 func (s *MediaService) GetById(id uint) (*Media, error) {
-	var item Media
+	return s.getById(s.Repo, id)
+}
 
-	if err := s.DB.First(&item, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("media not found")
+func (s *MediaService) getById(repo MediaRepository, id uint) (*Media, error) {
+	item, err := repo.GetById(id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound
 		}
 		s.Logger.Error("failed to get media", logger.String("error", err.Error()))
 		return nil, fmt.Errorf("failed to get media: %w", err)
 	}
+	return item, nil
+}
 
-	// Load relationships
-	if err := s.DB.Preload(clause.Associations).First(&item, id).Error; err != nil {
-		s.Logger.Error("failed to load media relationships", logger.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to load media relationships: %w", err)
+// OpenFile returns a media item with a seekable reader over its file.
+// The caller closes the reader.
+func (s *MediaService) OpenFile(id uint) (*Media, io.ReadSeekCloser, error) {
+	item, err := s.GetById(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if item.File == nil || item.File.Path == "" {
+		return nil, nil, ErrNoFile
 	}
 
-	return &item, nil
+	reader, err := s.ActiveStorage.Open(item.File)
+	if err != nil {
+		s.Logger.Error("failed to open media file",
+			logger.String("error", err.Error()),
+			logger.Int("media_id", int(id)))
+		return nil, nil, err
+	}
+
+	return item, reader, nil
 }
 
-// GetByIds returns multiple media items by their IDs
+// GetByIds returns the media items with the given IDs in the order of ids,
+// loaded with a single query. Missing IDs are skipped.
 func (s *MediaService) GetByIds(ids []uint) ([]*Media, error) {
+	return s.getByIds(ids, false)
+}
+
+// GetByIdsAligned is GetByIds returning a nil entry for each missing ID, so
+// the results line up with ids
+func (s *MediaService) GetByIdsAligned(ids []uint) ([]*Media, error) {
+	return s.getByIds(ids, true)
+}
+
+func (s *MediaService) getByIds(ids []uint, keepMissing bool) ([]*Media, error) {
 	if len(ids) == 0 {
 		return []*Media{}, nil
 	}
 
-	var items []*Media
-	if err := s.DB.Where("id IN ?", ids).Preload(clause.Associations).Find(&items).Error; err != nil {
+	items, err := s.Repo.GetByIds(ids)
+	if err != nil {
 		s.Logger.Error("failed to get media by ids", logger.String("error", err.Error()))
 		return nil, fmt.Errorf("failed to get media by ids: %w", err)
 	}
 
-	return items, nil
+	byId := make(map[uint]*Media, len(items))
+	for _, item := range items {
+		byId[item.Id] = item
+	}
+	ordered := make([]*Media, 0, len(ids))
+	for _, id := range ids {
+		if item, ok := byId[id]; ok || keepMissing {
+			ordered = append(ordered, item)
+		}
+	}
+
+	return ordered, nil
 }
 
-// GetAll returns a paginated list of media items
-func (s *MediaService) GetAll(page, limit *int) (*types.PaginatedResponse, error) {
+// GetAll returns a paginated list of the media items matching tags. Without
+// a page and limit every matching item is returned.
+func (s *MediaService) GetAll(page, limit *int, tags TagFilter) (*types.PaginatedResponse, error) {
+	currentPage, pageSize := types.NormalizePagination(page, limit)
+	paginated := page != nil || limit != nil
+
 	var items []*Media
 	var total int64
-
-	// Get total count
-	if err := s.DB.Model(&Media{}).Count(&total).Error; err != nil {
-		s.Logger.Error("failed to count media", logger.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to count media: %w", err)
-	}
-
-	// Build query
-	query := s.DB.Model(&Media{})
-
-	// Add pagination if provided
-	if page != nil && limit != nil {
-		offset := (*page - 1) * *limit
-		query = query.Offset(offset).Limit(*limit)
+	var err error
+	if paginated {
+		items, total, err = s.Repo.GetAll(&currentPage, &pageSize, tags)
+	} else {
+		items, total, err = s.Repo.GetAll(nil, nil, tags)
 	}
-
-	// Execute query with preloads
-	if err := query.Preload(clause.Associations).Find(&items).Error; err != nil {
+	if err != nil {
 		s.Logger.Error("failed to get media", logger.String("error", err.Error()))
 		return nil, fmt.Errorf("failed to get media: %w", err)
 	}
@@ -109,14 +159,6 @@ func (s *MediaService) GetAll(page, limit *int) (*types.PaginatedResponse, error
 	}
 
 	// Calculate pagination
-	pageSize := 10
-	currentPage := 1
-	if limit != nil {
-		pageSize = *limit
-	}
-	if page != nil {
-		currentPage = *page
-	}
 	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
 	if totalPages == 0 {
 		totalPages = 1
@@ -134,56 +176,62 @@ func (s *MediaService) GetAll(page, limit *int) (*types.PaginatedResponse, error
 	}, nil
 }
 
-// Create creates a new media item
+// Create creates a new media item. A failed upload rolls back the new row.
 func (s *MediaService) Create(req *CreateMediaRequest) (*Media, error) {
-	// Begin transaction
-	tx := s.DB.Begin()
-	if tx.Error != nil {
-		s.Logger.Error("failed to begin transaction", logger.String("error", tx.Error.Error()))
-		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
-	}
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	return s.create(req, func(item *Media) (*storage.Attachment, error) {
+		if req.File == nil {
+			return nil, nil
+		}
+		return s.ActiveStorage.Attach(item, "file", req.File)
+	})
+}
+
+// CreateStreaming creates a media item like Create, but copies the file from
+// its reader to storage as it arrives instead of from a parsed form. file
+// may be nil for an item without a file.
+func (s *MediaService) CreateStreaming(req *CreateMediaRequest, file *storage.FileStream) (*Media, error) {
+	return s.create(req, func(item *Media) (*storage.Attachment, error) {
+		if file == nil {
+			return nil, nil
 		}
-	}()
+		return s.ActiveStorage.AttachStream(item, "file", file)
+	})
+}
 
-	// Create media item
+// create stores a new media item with the file attach uploads, if any
+func (s *MediaService) create(req *CreateMediaRequest, attach func(item *Media) (*storage.Attachment, error)) (*Media, error) {
 	item := &Media{
 		Name:        req.Name,
 		Type:        req.Type,
 		Description: req.Description,
 	}
 
-	if err := tx.Create(item).Error; err != nil {
-		tx.Rollback()
-		s.Logger.Error("failed to create media", logger.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to create media: %w", err)
-	}
+	err := s.Repo.Transaction(func(repo MediaRepository) error {
+		if err := repo.Create(item); err != nil {
+			s.Logger.Error("failed to create media", logger.String("error", err.Error()))
+			return fmt.Errorf("failed to create media: %w", err)
+		}
 
-	// Handle file upload if provided
-	if req.File != nil {
 		// Upload the file using storage system
-		attachment, err := s.ActiveStorage.Attach(item, "file", req.File)
+		attachment, err := attach(item)
 		if err != nil {
-			tx.Rollback()
 			s.Logger.Error("failed to upload file", logger.String("error", err.Error()))
-			return nil, fmt.Errorf("failed to upload file: %w", err)
+			return fmt.Errorf("failed to upload file: %w", err)
+		}
+		if attachment == nil {
+			return nil
 		}
 
 		// Update media with file information
 		item.File = attachment
-		if err := tx.Save(item).Error; err != nil {
-			tx.Rollback()
+		if err := repo.Update(item, item.Version); err != nil {
 			s.Logger.Error("failed to update media with file", logger.String("error", err.Error()))
-			return nil, fmt.Errorf("failed to update media with file: %w", err)
+			return fmt.Errorf("failed to update media with file: %w", err)
 		}
-	}
-
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		s.Logger.Error("failed to commit transaction", logger.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Reload item with relationships
@@ -192,70 +240,47 @@ func (s *MediaService) Create(req *CreateMediaRequest) (*Media, error) {
 
 // Update updates a media item
 func (s *MediaService) Update(id uint, req *UpdateMediaRequest) (*Media, error) {
-	// Begin transaction
-	tx := s.DB.Begin()
-	if tx.Error != nil {
-		s.Logger.Error("failed to begin transaction", logger.String("error", tx.Error.Error()))
-		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
-	}
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	err := s.Repo.Transaction(func(repo MediaRepository) error {
+		item, err := s.getById(repo, id)
+		if err != nil {
+			return err
 		}
-	}()
 
-	// Get existing item
-	item, err := s.GetById(id)
-	if err != nil {
-		tx.Rollback()
-		return nil, err
-	}
+		// Reject stale writes before touching any stored file
+		if item.Version != req.Version {
+			return types.ErrVersionConflict
+		}
 
-	// Update fields if provided
-	if req.Name != nil {
-		item.Name = *req.Name
-	}
-	if req.Type != nil {
-		item.Type = *req.Type
-	}
-	if req.Description != nil {
-		item.Description = *req.Description
-	}
+		// Update fields if provided
+		if req.Name != nil {
+			item.Name = *req.Name
+		}
+		if req.Type != nil {
+			item.Type = *req.Type
+		}
+		if req.Description != nil {
+			item.Description = *req.Description
+		}
 
-	// Handle file update if provided
-	if req.File != nil {
-		// Remove existing file if any
-		if item.File != nil {
-			if err := s.ActiveStorage.Delete(item.File); err != nil {
-				tx.Rollback()
-				s.Logger.Error("failed to delete existing file", logger.String("error", err.Error()))
-				return nil, fmt.Errorf("failed to delete existing file: %w", err)
+		// Handle file update if provided
+		if req.File != nil {
+			if err := s.replaceFile(item, req.File); err != nil {
+				return err
 			}
 		}
 
-		// Upload new file
-		attachment, err := s.ActiveStorage.Attach(item, "file", req.File)
-		if err != nil {
-			tx.Rollback()
-			s.Logger.Error("failed to upload file", logger.String("error", err.Error()))
-			return nil, fmt.Errorf("failed to upload file: %w", err)
+		// Save changes only if the version is still the one the client read
+		if err := repo.Update(item, req.Version); err != nil {
+			if errors.Is(err, types.ErrVersionConflict) {
+				return err
+			}
+			s.Logger.Error("failed to update media", logger.String("error", err.Error()))
+			return fmt.Errorf("failed to update media: %w", err)
 		}
-
-		// Update media with new file information
-		item.File = attachment
-	}
-
-	// Save changes
-	if err := tx.Save(item).Error; err != nil {
-		tx.Rollback()
-		s.Logger.Error("failed to update media", logger.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to update media: %w", err)
-	}
-
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		s.Logger.Error("failed to commit transaction", logger.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Reload item with relationships
@@ -264,98 +289,163 @@ func (s *MediaService) Update(id uint, req *UpdateMediaRequest) (*Media, error)
 
 // Delete deletes a media item
 func (s *MediaService) Delete(id uint) error {
-	// Get existing item
-	item, err := s.GetById(id)
-	if err != nil {
-		return err
-	}
+	return s.Repo.Transaction(func(repo MediaRepository) error {
+		item, err := s.getById(repo, id)
+		if err != nil {
+			return err
+		}
 
-	// Begin transaction
-	tx := s.DB.Begin()
-	if tx.Error != nil {
-		s.Logger.Error("failed to begin transaction", logger.String("error", tx.Error.Error()))
-		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
-	}
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+		// Delete the file if it exists
+		if item.File != nil {
+			if err := s.ActiveStorage.Delete(item.File); err != nil {
+				s.Logger.Error("failed to delete file", logger.String("error", err.Error()))
+				return fmt.Errorf("failed to delete file: %w", err)
+			}
 		}
-	}()
 
-	// Delete the file if it exists
-	if item.File != nil {
-		if err := s.ActiveStorage.Delete(item.File); err != nil {
-			s.Logger.Error("failed to delete file", logger.String("error", err.Error()))
-			return fmt.Errorf("failed to delete file: %w", err)
+		// Delete the media item
+		if err := repo.Delete(item); err != nil {
+			s.Logger.Error("failed to delete media", logger.String("error", err.Error()))
+			return fmt.Errorf("failed to delete media: %w", err)
 		}
-	}
+		return nil
+	})
+}
 
-	// Delete the media item
-	if err := tx.Delete(item).Error; err != nil {
-		tx.Rollback()
-		s.Logger.Error("failed to delete media", logger.String("error", err.Error()))
-		return fmt.Errorf("failed to delete media: %w", err)
+// BatchDelete deletes the media items of ids and their files in one
+// transaction. Ids that do not exist or that allowed rejects are reported and
+// skipped. An item or file that cannot be deleted rolls the whole batch back:
+// the result is returned with the error, the failing item marked failed and
+// the others rolled back. Files are purged through a StagedPurge, so those
+// removed before the failure are restored. Errors before any item was decided
+// return no result.
+func (s *MediaService) BatchDelete(ids []uint, allowed func(id uint) (bool, error)) (*BatchDeleteResult, error) {
+	result := &BatchDeleteResult{Results: make([]BatchDeleteItemResult, 0, len(ids))}
+	decided := false
+	purge := s.ActiveStorage.StagePurge()
+
+	err := s.Repo.Transaction(func(repo MediaRepository) error {
+		items, err := repo.GetByIds(ids)
+		if err != nil {
+			return err
+		}
+		byId := make(map[uint]*Media, len(items))
+		for _, item := range items {
+			byId[item.Id] = item
+		}
+
+		// Decide on every id before deleting anything
+		var deletions []*Media
+		seen := make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			item, ok := byId[id]
+			status := BatchItemDeleted
+			if !ok {
+				status = BatchItemNotFound
+			} else {
+				permitted, err := allowed(id)
+				if err != nil {
+					return err
+				}
+				if !permitted {
+					status = BatchItemForbidden
+				}
+			}
+			if status == BatchItemDeleted {
+				deletions = append(deletions, item)
+			}
+			result.Results = append(result.Results, BatchDeleteItemResult{Id: id, Status: status})
+		}
+		decided = true
+
+		for _, item := range deletions {
+			if item.File != nil {
+				unreferenced, err := repo.DeleteFile(item.File)
+				if err != nil {
+					s.Logger.Error("failed to delete attachment", logger.Uint("media_id", item.Id), logger.String("error", err.Error()))
+					result.rollBack(item.Id, err)
+					return fmt.Errorf("failed to delete file of media %d: %w", item.Id, err)
+				}
+				if unreferenced {
+					if err := purge.Purge(item.File); err != nil {
+						s.Logger.Error("failed to delete file", logger.Uint("media_id", item.Id), logger.String("error", err.Error()))
+						result.rollBack(item.Id, err)
+						return fmt.Errorf("failed to delete file of media %d: %w", item.Id, err)
+					}
+				}
+			}
+			if err := repo.Delete(item); err != nil {
+				s.Logger.Error("failed to delete media", logger.String("error", err.Error()))
+				result.rollBack(item.Id, err)
+				return fmt.Errorf("failed to delete media: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if restoreErr := purge.Rollback(); restoreErr != nil {
+			s.Logger.Error("failed to restore files of rolled back media", logger.String("error", restoreErr.Error()))
+		}
+		if !decided {
+			return nil, err
+		}
+		return result, err
+	}
+	if err := purge.Commit(); err != nil {
+		s.Logger.Error("failed to remove staged files of deleted media", logger.String("error", err.Error()))
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		s.Logger.Error("failed to commit transaction", logger.String("error", err.Error()))
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	for _, item := range result.Results {
+		if item.Status == BatchItemDeleted {
+			result.Deleted++
+		} else {
+			result.Failed++
+		}
 	}
+	return result, nil
+}
 
-	return nil
+// rollBack marks the item that failed and turns the other deletions of the
+// batch into rollbacks
+func (r *BatchDeleteResult) rollBack(failedId uint, err error) {
+	for i := range r.Results {
+		switch {
+		case r.Results[i].Id == failedId:
+			r.Results[i].Status = BatchItemFailed
+			r.Results[i].Error = err.Error()
+		case r.Results[i].Status == BatchItemDeleted:
+			r.Results[i].Status = BatchItemRolledBack
+		}
+	}
+	r.Deleted = 0
+	r.Failed = len(r.Results)
 }
 
 // UpdateFile updates the file of a media item
 func (s *MediaService) UpdateFile(ctx context.Context, id uint, file *multipart.FileHeader) (*Media, error) {
-	// Begin transaction
-	tx := s.DB.Begin()
-	if tx.Error != nil {
-		s.Logger.Error("failed to begin transaction", logger.String("error", tx.Error.Error()))
-		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
-	}
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	err := s.Repo.Transaction(func(repo MediaRepository) error {
+		item, err := s.getById(repo, id)
+		if err != nil {
+			return err
 		}
-	}()
-
-	// Get existing item
-	item, err := s.GetById(id)
-	if err != nil {
-		tx.Rollback()
-		return nil, err
-	}
 
-	// Remove existing file if any
-	if item.File != nil {
-		if err := s.ActiveStorage.Delete(item.File); err != nil {
-			tx.Rollback()
-			s.Logger.Error("failed to delete existing file", logger.String("error", err.Error()))
-			return nil, fmt.Errorf("failed to delete existing file: %w", err)
+		if err := s.replaceFile(item, file); err != nil {
+			return err
 		}
-	}
 
-	// Upload new file
-	attachment, err := s.ActiveStorage.Attach(item, "file", file)
+		if err := repo.Update(item, item.Version); err != nil {
+			s.Logger.Error("failed to update media with file", logger.String("error", err.Error()))
+			return fmt.Errorf("failed to update media with file: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		tx.Rollback()
-		s.Logger.Error("failed to upload file", logger.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to upload file: %w", err)
-	}
-
-	// Update media with new file information
-	item.File = attachment
-	if err := tx.Save(item).Error; err != nil {
-		tx.Rollback()
-		s.Logger.Error("failed to update media with file", logger.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to update media with file: %w", err)
-	}
-
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		s.Logger.Error("failed to commit transaction", logger.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, err
 	}
 
 	// Reload item with relationships
@@ -364,48 +454,52 @@ func (s *MediaService) UpdateFile(ctx context.Context, id uint, file *multipart.
 
 // RemoveFile removes the file from a media item
 func (s *MediaService) RemoveFile(ctx context.Context, id uint) (*Media, error) {
-	// Begin transaction
-	tx := s.DB.Begin()
-	if tx.Error != nil {
-		s.Logger.Error("failed to begin transaction", logger.String("error", tx.Error.Error()))
-		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
-	}
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	err := s.Repo.Transaction(func(repo MediaRepository) error {
+		item, err := s.getById(repo, id)
+		if err != nil {
+			return err
+		}
+		if item.File == nil {
+			return nil
 		}
-	}()
-
-	// Get existing item
-	item, err := s.GetById(id)
-	if err != nil {
-		tx.Rollback()
-		return nil, err
-	}
 
-	// Remove file if exists
-	if item.File != nil {
 		if err := s.ActiveStorage.Delete(item.File); err != nil {
-			tx.Rollback()
 			s.Logger.Error("failed to delete file", logger.String("error", err.Error()))
-			return nil, fmt.Errorf("failed to delete file: %w", err)
+			return fmt.Errorf("failed to delete file: %w", err)
 		}
 
 		// Update media item
 		item.File = nil
-		if err := tx.Save(item).Error; err != nil {
-			tx.Rollback()
+		if err := repo.Update(item, item.Version); err != nil {
 			s.Logger.Error("failed to update media", logger.String("error", err.Error()))
-			return nil, fmt.Errorf("failed to update media: %w", err)
+			return fmt.Errorf("failed to update media: %w", err)
 		}
-	}
-
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		s.Logger.Error("failed to commit transaction", logger.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Reload item with relationships
 	return s.GetById(id)
 }
+
+// replaceFile deletes the stored file of item, if any, and attaches file
+// in its place. The item is not saved.
+func (s *MediaService) replaceFile(item *Media, file *multipart.FileHeader) error {
+	if item.File != nil {
+		if err := s.ActiveStorage.Delete(item.File); err != nil {
+			s.Logger.Error("failed to delete existing file", logger.String("error", err.Error()))
+			return fmt.Errorf("failed to delete existing file: %w", err)
+		}
+	}
+
+	attachment, err := s.ActiveStorage.Attach(item, "file", file)
+	if err != nil {
+		s.Logger.Error("failed to upload file", logger.String("error", err.Error()))
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	item.File = attachment
+	return nil
+}
@@ -0,0 +1,357 @@
+package media
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"base/core/emitter"
+	"base/core/logger"
+	"base/core/storage"
+	"base/core/types"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// testStore is a media service on a fresh sqlite database and local storage
+type testStore struct {
+	service *MediaService
+	db      *gorm.DB
+	dir     string
+}
+
+func newTestStore(t *testing.T) *testStore {
+	t.Helper()
+
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "test.db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(&Media{}, &MediaTag{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	activeStorage, err := storage.NewActiveStorage(db, storage.Config{Provider: "local", Path: filepath.Join(dir, "files")})
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+
+	service := NewMediaService(db, emitter.New(), activeStorage, logger.NewLoggerFromZap(zap.NewNop()))
+	return &testStore{service: service, db: db, dir: dir}
+}
+
+// create stores a media item with a file and returns it
+func (s *testStore) create(t *testing.T, name string) *Media {
+	t.Helper()
+
+	item := &Media{Name: name, Type: "image"}
+	if err := s.db.Create(item).Error; err != nil {
+		t.Fatalf("create media: %v", err)
+	}
+	key := "media/files/" + name + ".png"
+	if err := os.MkdirAll(filepath.Dir(s.path(key)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(s.path(key), []byte(name), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	item.File = &storage.Attachment{ModelType: "media", ModelId: item.Id, Field: "file", Filename: name + ".png", Path: key}
+	if err := s.db.Create(item.File).Error; err != nil {
+		t.Fatalf("create attachment: %v", err)
+	}
+	if err := s.db.Model(item).Update("file", item.File).Error; err != nil {
+		t.Fatalf("attach file: %v", err)
+	}
+	return item
+}
+
+func (s *testStore) path(key string) string {
+	return filepath.Join(s.dir, "files", filepath.FromSlash(key))
+}
+
+func (s *testStore) fileExists(item *Media) bool {
+	_, err := os.Stat(s.path(item.File.Path))
+	return err == nil
+}
+
+func (s *testStore) mediaExists(t *testing.T, item *Media) bool {
+	t.Helper()
+	var count int64
+	if err := s.db.Model(&Media{}).Where("id = ?", item.Id).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count > 0
+}
+
+func TestBatchDelete(t *testing.T) {
+	s := newTestStore(t)
+	first, second, kept := s.create(t, "first"), s.create(t, "second"), s.create(t, "kept")
+
+	allowed := func(id uint) (bool, error) { return id != kept.Id, nil }
+	result, err := s.service.BatchDelete([]uint{first.Id, 999, second.Id, kept.Id, first.Id}, allowed)
+	if err != nil {
+		t.Fatalf("BatchDelete: %v", err)
+	}
+
+	want := []BatchDeleteItemResult{
+		{Id: first.Id, Status: BatchItemDeleted},
+		{Id: 999, Status: BatchItemNotFound},
+		{Id: second.Id, Status: BatchItemDeleted},
+		{Id: kept.Id, Status: BatchItemForbidden},
+	}
+	if len(result.Results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(result.Results), len(want), result.Results)
+	}
+	for i := range want {
+		if result.Results[i] != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, result.Results[i], want[i])
+		}
+	}
+	if result.Deleted != 2 || result.Failed != 2 {
+		t.Errorf("deleted %d, failed %d, want 2 and 2", result.Deleted, result.Failed)
+	}
+
+	for _, item := range []*Media{first, second} {
+		if s.mediaExists(t, item) || s.fileExists(item) {
+			t.Errorf("media %d or its file still exists", item.Id)
+		}
+	}
+	if !s.mediaExists(t, kept) || !s.fileExists(kept) {
+		t.Errorf("forbidden media %d or its file was deleted", kept.Id)
+	}
+}
+
+// failingRepository fails to delete one item
+type failingRepository struct {
+	MediaRepository
+	failId uint
+}
+
+func (r *failingRepository) Delete(item *Media) error {
+	if item.Id == r.failId {
+		return errors.New("disk on fire")
+	}
+	return r.MediaRepository.Delete(item)
+}
+
+func (r *failingRepository) Transaction(fn func(repo MediaRepository) error) error {
+	return r.MediaRepository.Transaction(func(repo MediaRepository) error {
+		return fn(&failingRepository{MediaRepository: repo, failId: r.failId})
+	})
+}
+
+func TestBatchDeleteRollsBackFilesAndRows(t *testing.T) {
+	s := newTestStore(t)
+	first, failing := s.create(t, "first"), s.create(t, "failing")
+	s.service.Repo = &failingRepository{MediaRepository: s.service.Repo, failId: failing.Id}
+
+	allowAll := func(uint) (bool, error) { return true, nil }
+	result, err := s.service.BatchDelete([]uint{first.Id, failing.Id}, allowAll)
+	if err == nil {
+		t.Fatal("BatchDelete succeeded, want an error")
+	}
+
+	if got := result.Results[0].Status; got != BatchItemRolledBack {
+		t.Errorf("first item is %s, want %s", got, BatchItemRolledBack)
+	}
+	if got := result.Results[1].Status; got != BatchItemFailed {
+		t.Errorf("failing item is %s, want %s", got, BatchItemFailed)
+	}
+
+	for _, item := range []*Media{first, failing} {
+		if !s.mediaExists(t, item) {
+			t.Errorf("media %d was deleted by a rolled back batch", item.Id)
+		}
+		if !s.fileExists(item) {
+			t.Errorf("file of media %d was deleted by a rolled back batch", item.Id)
+		}
+		var attachments int64
+		s.db.Model(&storage.Attachment{}).Where("id = ?", item.File.Id).Count(&attachments)
+		if attachments != 1 {
+			t.Errorf("attachment of media %d was deleted by a rolled back batch", item.Id)
+		}
+	}
+}
+
+// failingDeleteProvider is local storage refusing to delete the file of the
+// media item named failing
+type failingDeleteProvider struct {
+	storage.Provider
+}
+
+func (p failingDeleteProvider) Delete(path string) error {
+	if path == "media/files/failing.png" {
+		return errors.New("bucket unavailable")
+	}
+	return p.Provider.Delete(path)
+}
+
+func init() {
+	storage.RegisterProvider("failing-delete", func(config storage.Config) (storage.Provider, error) {
+		local, err := storage.NewLocalProvider(storage.LocalConfig{BasePath: config.Path})
+		return failingDeleteProvider{local}, err
+	})
+}
+
+func TestBatchDeleteRollsBackWhenStorageDeleteFails(t *testing.T) {
+	s := newTestStore(t)
+	first, second, failing := s.create(t, "first"), s.create(t, "second"), s.create(t, "failing")
+	activeStorage, err := storage.NewActiveStorage(s.db, storage.Config{Provider: "failing-delete", Path: filepath.Join(s.dir, "files")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.service.ActiveStorage = activeStorage
+
+	allowAll := func(uint) (bool, error) { return true, nil }
+	result, err := s.service.BatchDelete([]uint{first.Id, second.Id, failing.Id}, allowAll)
+	if err == nil {
+		t.Fatal("BatchDelete succeeded, want the storage error")
+	}
+	want := []string{BatchItemRolledBack, BatchItemRolledBack, BatchItemFailed}
+	for i, item := range result.Results {
+		if item.Status != want[i] {
+			t.Errorf("item %d is %s, want %s", item.Id, item.Status, want[i])
+		}
+	}
+
+	// The files purged before the failure are back with their rows
+	for _, item := range []*Media{first, second, failing} {
+		if !s.mediaExists(t, item) || !s.fileExists(item) {
+			t.Errorf("media %d or its file is gone after the rollback", item.Id)
+		}
+	}
+	if entries, _ := filepath.Glob(s.path(".trash/media/files/*")); len(entries) != 0 {
+		t.Errorf("staged copies left behind: %v", entries)
+	}
+
+	// Without the failing item the batch goes through and drops the copies
+	result, err = s.service.BatchDelete([]uint{first.Id, second.Id}, allowAll)
+	if err != nil || result.Deleted != 2 {
+		t.Fatalf("BatchDelete: %+v, %v", result, err)
+	}
+	for _, item := range []*Media{first, second} {
+		if s.mediaExists(t, item) || s.fileExists(item) {
+			t.Errorf("media %d or its file still exists", item.Id)
+		}
+	}
+	if entries, _ := filepath.Glob(s.path(".trash/media/files/*")); len(entries) != 0 {
+		t.Errorf("staged copies left behind: %v", entries)
+	}
+}
+
+func TestUpdateRequiresCurrentVersion(t *testing.T) {
+	s := newTestStore(t)
+	item := s.create(t, "logo")
+
+	name := "new logo"
+	updated, err := s.service.Update(item.Id, &UpdateMediaRequest{Name: &name, Version: 1})
+	if err != nil {
+		t.Fatalf("Update with the current version: %v", err)
+	}
+	if updated.Version != 2 || updated.Name != name {
+		t.Errorf("got version %d name %q, want version 2 name %q", updated.Version, updated.Name, name)
+	}
+
+	stale := "stale logo"
+	if _, err := s.service.Update(item.Id, &UpdateMediaRequest{Name: &stale, Version: 1}); !errors.Is(err, types.ErrVersionConflict) {
+		t.Fatalf("Update with a stale version = %v, want ErrVersionConflict", err)
+	}
+	var stored Media
+	if err := s.db.First(&stored, item.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.Name != name || stored.Version != 2 {
+		t.Errorf("stored version %d name %q, want version 2 name %q", stored.Version, stored.Name, name)
+	}
+}
+
+// racingRepository lets another writer update the row between the read and
+// the versioned write
+type racingRepository struct {
+	MediaRepository
+	db *gorm.DB
+}
+
+func (r *racingRepository) Update(item *Media, version uint) error {
+	if err := r.db.Model(&Media{}).Where("id = ?", item.Id).Update("version", gorm.Expr("version + 1")).Error; err != nil {
+		return err
+	}
+	return r.MediaRepository.Update(item, version)
+}
+
+func (r *racingRepository) Transaction(fn func(repo MediaRepository) error) error {
+	return r.MediaRepository.Transaction(func(repo MediaRepository) error {
+		return fn(&racingRepository{MediaRepository: repo, db: repo.(*GormMediaRepository).DB})
+	})
+}
+
+func TestUpdateLosesRaceWithConcurrentWriter(t *testing.T) {
+	s := newTestStore(t)
+	item := s.create(t, "logo")
+	s.service.Repo = &racingRepository{MediaRepository: s.service.Repo}
+
+	name := "new logo"
+	if _, err := s.service.Update(item.Id, &UpdateMediaRequest{Name: &name, Version: 1}); !errors.Is(err, types.ErrVersionConflict) {
+		t.Fatalf("Update = %v, want ErrVersionConflict", err)
+	}
+	var stored Media
+	if err := s.db.First(&stored, item.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.Name != "logo" {
+		t.Errorf("stored name %q, want the losing write discarded", stored.Name)
+	}
+}
+
+func TestGetByIdsKeepsRequestedOrder(t *testing.T) {
+	s := newTestStore(t)
+	a, b, c := s.create(t, "a"), s.create(t, "b"), s.create(t, "c")
+	missing := c.Id + 100
+
+	queries := 0
+	s.db.Callback().Query().After("gorm:query").Register("test:count_media", func(db *gorm.DB) {
+		if db.Statement.Table == "media" {
+			queries++
+		}
+	})
+	names := func(items []*Media) []string {
+		var names []string
+		for _, item := range items {
+			if item == nil {
+				names = append(names, "<nil>")
+			} else {
+				names = append(names, item.Name)
+			}
+		}
+		return names
+	}
+
+	items, err := s.service.GetByIds([]uint{c.Id, missing, a.Id, b.Id})
+	if err != nil {
+		t.Fatalf("GetByIds: %v", err)
+	}
+	if got := names(items); !reflect.DeepEqual(got, []string{"c", "a", "b"}) {
+		t.Errorf("got %v, want c, a, b", got)
+	}
+	if queries != 1 {
+		t.Errorf("%d media queries, want 1", queries)
+	}
+
+	// Placeholders line the results up with the ids
+	items, err = s.service.GetByIdsAligned([]uint{b.Id, missing, a.Id})
+	if err != nil {
+		t.Fatalf("GetByIdsAligned: %v", err)
+	}
+	if got := names(items); !reflect.DeepEqual(got, []string{"b", "<nil>", "a"}) {
+		t.Errorf("with placeholders got %v, want b, <nil>, a", got)
+	}
+
+	if items, err := s.service.GetByIdsAligned(nil); err != nil || len(items) != 0 {
+		t.Errorf("no ids: got %v, %v", items, err)
+	}
+}
@@ -0,0 +1,130 @@
+package media
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"base/core/logger"
+	"base/core/router"
+	"base/core/storage"
+
+	"go.uber.org/zap"
+)
+
+// zeros reads zero bytes forever
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// streamUpload posts a multipart body to /api/media/stream, writing it while
+// the request is read. The fields are sent before a PNG of size bytes, or
+// after it when fieldsLast is set.
+func streamUpload(r *router.Router, size int64, fieldsLast bool) *httptest.ResponseRecorder {
+	body, pipe := io.Pipe()
+	writer := multipart.NewWriter(pipe)
+	go func() {
+		fields := func() {
+			writer.WriteField("name", "large")
+			writer.WriteField("type", "image")
+		}
+		if !fieldsLast {
+			fields()
+		}
+		part, _ := writer.CreateFormFile("file", "large.png")
+		png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+		io.Copy(part, io.MultiReader(bytes.NewReader(png), io.LimitReader(zeros{}, size-int64(len(png)))))
+		if fieldsLast {
+			fields()
+		}
+		pipe.CloseWithError(writer.Close())
+	}()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/media/stream", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	body.Close()
+	return w
+}
+
+func newStreamRouter(t *testing.T) (*router.Router, *MediaService, *memoryRepository) {
+	t.Helper()
+	repo := newMemoryRepository()
+	s := newMemoryService(t, repo)
+	r := router.New()
+	NewMediaController(s, s.ActiveStorage, logger.NewLoggerFromZap(zap.NewNop())).Routes(r.Group("/api"))
+	return r, s, repo
+}
+
+func TestCreateStreamKeepsMemoryBounded(t *testing.T) {
+	r, _, repo := newStreamRouter(t)
+	const size = 64 << 20
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	w := streamUpload(r, size, false)
+	runtime.ReadMemStats(&after)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	// The multipart form parser would hold up to 32 MB of it in memory
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 8<<20 {
+		t.Errorf("a %d MB upload allocated %d KB", size>>20, allocated>>10)
+	}
+
+	var response MediaResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	item, ok := repo.items[response.Id]
+	if !ok || item.Name != "large" || item.File == nil || item.File.Size != size {
+		t.Errorf("stored item %+v, want large with a %d byte file", item, size)
+	}
+}
+
+func TestCreateStreamValidation(t *testing.T) {
+	r, s, repo := newStreamRouter(t)
+
+	// Fields after the file are not read, so the item has no name
+	if w := streamUpload(r, 1024, true); w.Code != http.StatusBadRequest {
+		t.Errorf("fields after the file: status %d, want 400", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/media/stream", bytes.NewReader([]byte(`{}`))))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("not multipart: status %d, want 400", w.Code)
+	}
+
+	// With a scan hook the upload is parsed as a form instead
+	var scanned int
+	s.ActiveStorage.SetScanHook(storage.ScanHookFunc(func(*multipart.FileHeader) error {
+		scanned++
+		return nil
+	}))
+	if w := streamUpload(r, 1024, false); w.Code != http.StatusCreated || scanned != 1 {
+		t.Errorf("with a scan hook: status %d, %d scans; want 201 after one scan", w.Code, scanned)
+	}
+	if len(repo.items) != 1 {
+		t.Errorf("%d items stored, want 1", len(repo.items))
+	}
+}
+
+func TestCreateStreamingWithoutFile(t *testing.T) {
+	s := newMemoryService(t, newMemoryRepository())
+	item, err := s.CreateStreaming(&CreateMediaRequest{Name: "empty", Type: "image"}, nil)
+	if err != nil || item.File != nil {
+		t.Errorf("CreateStreaming without a file = %+v, %v", item, err)
+	}
+}
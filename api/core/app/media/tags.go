@@ -0,0 +1,136 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"base/core/logger"
+)
+
+// MaxTagLength is the longest tag accepted, in characters
+const MaxTagLength = 64
+
+// ErrInvalidTag is returned for empty or overlong tags
+var ErrInvalidTag = errors.New("tags must be non-empty and at most 64 characters")
+
+// MediaTag is a tag on a media item. The unique index keeps a tag once per
+// item and the tag index serves the list filter.
+type MediaTag struct {
+	Id      uint   `gorm:"primaryKey"`
+	MediaId uint   `gorm:"column:media_id;not null;uniqueIndex:idx_media_tags_media_tag,priority:1"`
+	Tag     string `gorm:"column:tag;size:64;not null;uniqueIndex:idx_media_tags_media_tag,priority:2;index:idx_media_tags_tag"`
+}
+
+func (MediaTag) TableName() string {
+	return "media_tags"
+}
+
+// TagFilter restricts a media list to items carrying Tags: any of them, or
+// all of them with MatchAll. An empty filter matches every item.
+type TagFilter struct {
+	Tags     []string
+	MatchAll bool
+}
+
+// TagsRequest is the payload adding tags to a media item
+type TagsRequest struct {
+	Tags []string `json:"tags" binding:"required,min=1"`
+}
+
+// TagNames returns the item's tags in alphabetical order
+func (item *Media) TagNames() []string {
+	names := make([]string, len(item.TagRecords))
+	for i, tag := range item.TagRecords {
+		names[i] = tag.Tag
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NormalizeTags trims and lowercases tags and drops duplicates, so "Cover"
+// and "cover " are one tag
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || len([]rune(tag)) > MaxTagLength {
+			return nil, ErrInvalidTag
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
+
+// ParseTagFilter reads the comma-separated tags of a list request. mode is
+// "any" (the default) or "all".
+func ParseTagFilter(tags, mode string) (TagFilter, error) {
+	var filter TagFilter
+	switch mode {
+	case "", "any":
+	case "all":
+		filter.MatchAll = true
+	default:
+		return filter, fmt.Errorf("tags_mode must be any or all")
+	}
+	if strings.TrimSpace(tags) == "" {
+		return filter, nil
+	}
+
+	var err error
+	filter.Tags, err = NormalizeTags(strings.Split(tags, ","))
+	return filter, err
+}
+
+// AddTags tags a media item; tags it already has are kept once
+func (s *MediaService) AddTags(id uint, tags []string) (*Media, error) {
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Repo.Transaction(func(repo MediaRepository) error {
+		if _, err := s.getById(repo, id); err != nil {
+			return err
+		}
+		if err := repo.AddTags(id, tags); err != nil {
+			s.Logger.Error("failed to tag media", logger.String("error", err.Error()))
+			return fmt.Errorf("failed to tag media: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetById(id)
+}
+
+// RemoveTags removes tags from a media item; tags it does not have are ignored
+func (s *MediaService) RemoveTags(id uint, tags []string) (*Media, error) {
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Repo.Transaction(func(repo MediaRepository) error {
+		if _, err := s.getById(repo, id); err != nil {
+			return err
+		}
+		if err := repo.RemoveTags(id, tags); err != nil {
+			s.Logger.Error("failed to untag media", logger.String("error", err.Error()))
+			return fmt.Errorf("failed to untag media: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetById(id)
+}
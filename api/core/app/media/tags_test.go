@@ -0,0 +1,206 @@
+package media
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"base/core/logger"
+	"base/core/router"
+
+	"go.uber.org/zap"
+)
+
+// tagged creates a media item carrying tags
+func (s *testStore) tagged(t *testing.T, name string, tags ...string) *Media {
+	t.Helper()
+	item := &Media{Name: name, Type: "image"}
+	if err := s.db.Create(item).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) > 0 {
+		if _, err := s.service.AddTags(item.Id, tags); err != nil {
+			t.Fatalf("AddTags: %v", err)
+		}
+	}
+	return item
+}
+
+// listNames returns the sorted names of the items matching filter
+func listNames(t *testing.T, s *MediaService, filter TagFilter) []string {
+	t.Helper()
+	result, err := s.GetAll(nil, nil, filter)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	names := []string{}
+	for _, item := range result.Data.([]any) {
+		names = append(names, item.(*MediaListResponse).Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestAddAndRemoveTags(t *testing.T) {
+	s := newTestStore(t)
+	item := s.tagged(t, "logo")
+
+	got, err := s.service.AddTags(item.Id, []string{"Cover", " cover ", "brand"})
+	if err != nil {
+		t.Fatalf("AddTags: %v", err)
+	}
+	if want := []string{"brand", "cover"}; !reflect.DeepEqual(got.TagNames(), want) {
+		t.Errorf("tags = %v, want %v", got.TagNames(), want)
+	}
+	// Adding a tag the item has keeps it once
+	if got, err = s.service.AddTags(item.Id, []string{"BRAND", "dark"}); err != nil {
+		t.Fatalf("AddTags again: %v", err)
+	}
+	if want := []string{"brand", "cover", "dark"}; !reflect.DeepEqual(got.TagNames(), want) {
+		t.Errorf("tags = %v, want %v", got.TagNames(), want)
+	}
+
+	if got, err = s.service.RemoveTags(item.Id, []string{"Cover", "missing"}); err != nil {
+		t.Fatalf("RemoveTags: %v", err)
+	}
+	if want := []string{"brand", "dark"}; !reflect.DeepEqual(got.TagNames(), want) {
+		t.Errorf("tags after removal = %v, want %v", got.TagNames(), want)
+	}
+
+	for _, tags := range [][]string{{""}, {"  "}, {strings.Repeat("x", MaxTagLength+1)}} {
+		if _, err := s.service.AddTags(item.Id, tags); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("AddTags(%q): got %v, want ErrInvalidTag", tags, err)
+		}
+	}
+	if _, err := s.service.AddTags(9999, []string{"cover"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("tagging a missing item: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestListFiltersByTags(t *testing.T) {
+	s := newTestStore(t)
+	s.tagged(t, "a", "red", "round")
+	s.tagged(t, "b", "red")
+	s.tagged(t, "c", "round", "blue")
+	s.tagged(t, "d")
+
+	tests := []struct {
+		filter TagFilter
+		want   []string
+	}{
+		{TagFilter{}, []string{"a", "b", "c", "d"}},
+		{TagFilter{Tags: []string{"red"}}, []string{"a", "b"}},
+		{TagFilter{Tags: []string{"red", "blue"}}, []string{"a", "b", "c"}},
+		{TagFilter{Tags: []string{"red", "round"}, MatchAll: true}, []string{"a"}},
+		{TagFilter{Tags: []string{"red", "blue"}, MatchAll: true}, []string{}},
+		{TagFilter{Tags: []string{"green"}}, []string{}},
+	}
+	for _, tt := range tests {
+		if got := listNames(t, s.service, tt.filter); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v: got %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	// The total counts matching items, not tag rows
+	limit := 1
+	result, err := s.service.GetAll(nil, &limit, TagFilter{Tags: []string{"red", "round"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Pagination.Total != 3 {
+		t.Errorf("total %d, want 3", result.Pagination.Total)
+	}
+}
+
+func TestParseTagFilter(t *testing.T) {
+	filter, err := ParseTagFilter(" Red,round ,red", "all")
+	if err != nil || !filter.MatchAll || !reflect.DeepEqual(filter.Tags, []string{"red", "round"}) {
+		t.Errorf("got %+v, %v", filter, err)
+	}
+	if filter, err := ParseTagFilter("", ""); err != nil || filter.Tags != nil || filter.MatchAll {
+		t.Errorf("empty: got %+v, %v", filter, err)
+	}
+	if _, err := ParseTagFilter("red", "some"); err == nil {
+		t.Error("an unknown mode was accepted")
+	}
+	if _, err := ParseTagFilter("red,,blue", ""); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("empty tag: got %v, want ErrInvalidTag", err)
+	}
+}
+
+func TestTagRoutes(t *testing.T) {
+	s := newTestStore(t)
+	item := s.tagged(t, "logo")
+	s.tagged(t, "banner", "hero")
+	r := router.New()
+	NewMediaController(s.service, s.service.ActiveStorage, logger.NewLoggerFromZap(zap.NewNop())).Routes(r.Group("/api"))
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	itemPath := "/api/media/" + strconv.FormatUint(uint64(item.Id), 10)
+
+	w := do(http.MethodPost, itemPath+"/tags", `{"tags":["Hero","dark"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("add tags: status %d: %s", w.Code, w.Body)
+	}
+	var response MediaResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(response.Tags, []string{"dark", "hero"}) {
+		t.Errorf("tags = %v, want dark and hero", response.Tags)
+	}
+
+	if w := do(http.MethodDelete, itemPath+"/tags/dark", ""); w.Code != http.StatusOK {
+		t.Errorf("remove tag: status %d: %s", w.Code, w.Body)
+	}
+
+	list := func(query string) []string {
+		w := do(http.MethodGet, "/api/media?"+query, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("list %s: status %d: %s", query, w.Code, w.Body)
+		}
+		var page struct {
+			Data []MediaListResponse `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &page)
+		names := []string{}
+		for _, item := range page.Data {
+			names = append(names, item.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+	if got := list("tags=hero"); !reflect.DeepEqual(got, []string{"banner", "logo"}) {
+		t.Errorf("tags=hero: %v", got)
+	}
+	if got := list("tags=hero,dark&tags_mode=all"); len(got) != 0 {
+		t.Errorf("the removed tag still matches: %v", got)
+	}
+
+	tests := []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPost, itemPath + "/tags", `{"tags":[]}`, http.StatusBadRequest},
+		{http.MethodPost, itemPath + "/tags", `{"tags":[" "]}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/media/9999/tags", `{"tags":["x"]}`, http.StatusNotFound},
+		{http.MethodDelete, "/api/media/9999/tags/x", "", http.StatusNotFound},
+		{http.MethodGet, "/api/media?tags=x&tags_mode=some", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if w := do(tt.method, tt.path, tt.body); w.Code != tt.want {
+			t.Errorf("%s %s %s: status %d, want %d", tt.method, tt.path, tt.body, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package notification
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"base/core/logger"
+	"base/core/router"
+	"base/core/types"
+)
+
+type NotificationController struct {
+	service *NotificationService
+	logger  logger.Logger
+}
+
+func NewNotificationController(service *NotificationService, logger logger.Logger) *NotificationController {
+	return &NotificationController{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Routes registers the notification routes of the authenticated user.
+// read-all is registered before :id so the static segment wins.
+func (c *NotificationController) Routes(router *router.RouterGroup) {
+	router.GET("/notifications", c.List)
+	router.POST("/notifications/read-all", c.MarkAllRead)
+	router.POST("/notifications/:id/read", c.MarkRead)
+}
+
+// List godoc
+// @Summary List notifications
+// @Description Lists the authenticated user's notifications, newest first, with the number of unread ones
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Notifications
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page, capped at MAX_PAGE_SIZE"
+// @Success 200 {object} ListResponse
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /notifications [get]
+func (c *NotificationController) List(ctx *router.Context) error {
+	userId := ctx.GetUint("user_id")
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, types.ErrorResponse{Error: "Unauthorized"})
+	}
+
+	filters, err := ctx.QueryFilters()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: err.Error()})
+	}
+	unreadOnly := false
+	if unread := ctx.Query("unread"); unread != "" {
+		if unreadOnly, err = strconv.ParseBool(unread); err != nil {
+			return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid unread value"})
+		}
+	}
+
+	response, err := c.service.List(userId, unreadOnly, filters.Page, filters.Limit)
+	if err != nil {
+		c.logger.Error("Failed to list notifications",
+			logger.Uint("user_id", userId),
+			logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to fetch notifications"})
+	}
+	response.AddLinks(ctx.Request.URL)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// MarkRead godoc
+// @Summary Mark a notification as read
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Notifications
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} NotificationResponse
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 404 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /notifications/{id}/read [post]
+func (c *NotificationController) MarkRead(ctx *router.Context) error {
+	userId := ctx.GetUint("user_id")
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, types.ErrorResponse{Error: "Unauthorized"})
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid ID"})
+	}
+
+	notification, err := c.service.MarkRead(userId, uint(id))
+	if err != nil {
+		if errors.Is(err, ErrNotificationNotFound) {
+			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{Error: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to update notification"})
+	}
+
+	return ctx.JSON(http.StatusOK, notification.ToResponse())
+}
+
+// MarkAllRead godoc
+// @Summary Mark all notifications as read
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Notifications
+// @Produce json
+// @Success 200 {object} ReadAllResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /notifications/read-all [post]
+func (c *NotificationController) MarkAllRead(ctx *router.Context) error {
+	userId := ctx.GetUint("user_id")
+	if userId == 0 {
+		return ctx.JSON(http.StatusUnauthorized, types.ErrorResponse{Error: "Unauthorized"})
+	}
+
+	updated, err := c.service.MarkAllRead(userId)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to update notifications"})
+	}
+
+	return ctx.JSON(http.StatusOK, ReadAllResponse{Updated: updated})
+}
@@ -0,0 +1,65 @@
+package notification
+
+import (
+	"encoding/json"
+	"time"
+
+	"base/core/types"
+)
+
+// Notification is an in-app message for a user, created from events that
+// concern them
+type Notification struct {
+	Id        uint       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserId    uint       `gorm:"column:user_id;not null;index:idx_notifications_user_read,priority:1" json:"-"`
+	Type      string     `gorm:"column:type;size:64;not null" json:"type"` // Event-derived kind, e.g. "achievement_unlocked"
+	Title     string     `gorm:"column:title;size:255;not null" json:"title"`
+	Body      string     `gorm:"column:body;type:text" json:"body"`
+	Data      string     `gorm:"column:data;type:text" json:"-"` // JSON details for clients, such as ids to link to
+	ReadAt    *time.Time `gorm:"column:read_at;index:idx_notifications_user_read,priority:2" json:"read_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;index" json:"created_at"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationResponse is a notification as returned by the API
+type NotificationResponse struct {
+	Id        uint            `json:"id"`
+	Type      string          `json:"type"`
+	Title     string          `json:"title"`
+	Body      string          `json:"body,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+	Read      bool            `json:"read"`
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// ToResponse converts the notification to its API representation
+func (n *Notification) ToResponse() *NotificationResponse {
+	response := &NotificationResponse{
+		Id:        n.Id,
+		Type:      n.Type,
+		Title:     n.Title,
+		Body:      n.Body,
+		Read:      n.ReadAt != nil,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+	if n.Data != "" {
+		response.Data = json.RawMessage(n.Data)
+	}
+	return response
+}
+
+// ListResponse is a page of notifications with the user's unread count
+type ListResponse struct {
+	types.PaginatedResponse
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// ReadAllResponse reports how many notifications were marked as read
+type ReadAllResponse struct {
+	Updated int64 `json:"updated"`
+}
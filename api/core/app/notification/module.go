@@ -0,0 +1,53 @@
+package notification
+
+import (
+	"base/core/app/profile"
+	"base/core/emitter"
+	"base/core/logger"
+	"base/core/module"
+	"base/core/router"
+
+	"gorm.io/gorm"
+)
+
+type NotificationModule struct {
+	module.DefaultModule
+	DB         *gorm.DB
+	Controller *NotificationController
+	Service    *NotificationService
+	Logger     logger.Logger
+}
+
+func NewNotificationModule(db *gorm.DB, router *router.RouterGroup, logger logger.Logger, emitter *emitter.Emitter) module.Module {
+	service := NewNotificationService(db)
+	controller := NewNotificationController(service, logger)
+
+	notificationModule := &NotificationModule{
+		DB:         db,
+		Controller: controller,
+		Service:    service,
+		Logger:     logger,
+	}
+	if emitter != nil {
+		notificationModule.subscribe(emitter)
+	}
+
+	// Notifications go with the account in either deletion mode
+	profile.RegisterDeletionHook("notifications", func(tx *gorm.DB, userId uint, mode string) error {
+		return tx.Where("user_id = ?", userId).Delete(&Notification{}).Error
+	})
+
+	return notificationModule
+}
+
+func (m *NotificationModule) Routes(router *router.RouterGroup) {
+	m.Controller.Routes(router)
+}
+
+func (m *NotificationModule) Migrate() error {
+	return m.DB.AutoMigrate(&Notification{})
+}
+
+func (m *NotificationModule) GetModels() []any {
+	return []any{&Notification{}}
+}
@@ -0,0 +1,134 @@
+package notification
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"base/core/types"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotificationNotFound is returned for notifications that do not exist or
+// belong to another user
+var ErrNotificationNotFound = errors.New("notification not found")
+
+type NotificationService struct {
+	DB *gorm.DB
+}
+
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{DB: db}
+}
+
+// Notify creates an unread notification for userId. data is stored as JSON
+// for clients and may be nil.
+func (s *NotificationService) Notify(userId uint, kind, title, body string, data any) (*Notification, error) {
+	if userId == 0 {
+		return nil, fmt.Errorf("notification %s has no user", kind)
+	}
+
+	notification := &Notification{
+		UserId: userId,
+		Type:   kind,
+		Title:  title,
+		Body:   body,
+	}
+	if data != nil {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode notification data: %w", err)
+		}
+		notification.Data = string(encoded)
+	}
+
+	if err := s.DB.Create(notification).Error; err != nil {
+		return nil, fmt.Errorf("failed to create notification: %w", err)
+	}
+	return notification, nil
+}
+
+// List returns a page of the user's notifications, newest first, with the
+// number of unread ones
+func (s *NotificationService) List(userId uint, unreadOnly bool, page, limit *int) (*ListResponse, error) {
+	currentPage, pageSize := types.NormalizePagination(page, limit)
+
+	query := s.DB.Model(&Notification{}).Where("user_id = ?", userId)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var notifications []*Notification
+	offset := (currentPage - 1) * pageSize
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(pageSize).Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+
+	unread, err := s.UnreadCount(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*NotificationResponse, len(notifications))
+	for i, notification := range notifications {
+		responses[i] = notification.ToResponse()
+	}
+
+	return &ListResponse{
+		PaginatedResponse: types.PaginatedResponse{
+			Data: responses,
+			Pagination: types.Pagination{
+				Total:      int(total),
+				Page:       currentPage,
+				PageSize:   pageSize,
+				TotalPages: int(total+int64(pageSize)-1) / pageSize,
+			},
+		},
+		UnreadCount: unread,
+	}, nil
+}
+
+// UnreadCount returns the number of the user's unread notifications
+func (s *NotificationService) UnreadCount(userId uint) (int64, error) {
+	var count int64
+	err := s.DB.Model(&Notification{}).Where("user_id = ? AND read_at IS NULL", userId).Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks one of the user's notifications as read. Marking a read
+// notification again keeps its first read time.
+func (s *NotificationService) MarkRead(userId, id uint) (*Notification, error) {
+	var notification Notification
+	err := s.DB.Where("id = ? AND user_id = ?", id, userId).First(&notification).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotificationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		if err := s.DB.Model(&notification).Update("read_at", now).Error; err != nil {
+			return nil, err
+		}
+		notification.ReadAt = &now
+	}
+	return &notification, nil
+}
+
+// MarkAllRead marks all of the user's unread notifications as read and
+// returns how many there were
+func (s *NotificationService) MarkAllRead(userId uint) (int64, error) {
+	result := s.DB.Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userId).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
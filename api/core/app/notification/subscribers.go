@@ -0,0 +1,40 @@
+package notification
+
+import (
+	"fmt"
+
+	"base/core/emitter"
+	"base/core/logger"
+	"base/core/types"
+)
+
+// EventRoleChanged is emitted with a types.RoleChange when a user is given
+// another role
+const EventRoleChanged = types.EventRoleChanged
+
+// subscribe creates notifications from the core events that concern a user.
+// Modules subscribe to their own events with NotificationService.Notify.
+func (m *NotificationModule) subscribe(e *emitter.Emitter) {
+	e.On(EventRoleChanged, func(data any) {
+		var change types.RoleChange
+		switch payload := data.(type) {
+		case types.RoleChange:
+			change = payload
+		case *types.RoleChange:
+			change = *payload
+		default:
+			return
+		}
+
+		_, err := m.Service.Notify(change.UserId, "role_changed",
+			"Your role has changed",
+			fmt.Sprintf("You now have the %s role.", change.RoleName),
+			change)
+		if err != nil {
+			m.Logger.Error("Failed to create notification",
+				logger.String("event", EventRoleChanged),
+				logger.Uint("user_id", change.UserId),
+				logger.String("error", err.Error()))
+		}
+	})
+}
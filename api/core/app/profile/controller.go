@@ -3,6 +3,7 @@ package profile
 import (
 	"base/core/logger"
 	"base/core/router"
+	"base/core/storage"
 	"base/core/types"
 	"errors"
 	"net/http"
@@ -27,6 +28,8 @@ func (c *ProfileController) Routes(router *router.RouterGroup) {
 	router.GET("/profile", c.Get)
 	router.PUT("/profile", c.Update)
 	router.PUT("/profile/avatar", c.UpdateAvatar)
+	router.POST("/profile/avatar", c.UploadAvatar)
+	router.DELETE("/profile/avatar", c.RemoveAvatar)
 	router.PUT("/profile/password", c.UpdatePassword)
 }
 
@@ -108,6 +111,8 @@ func (c *ProfileController) Update(ctx *router.Context) error {
 // @Success 200 {object} User
 // @Failure 400 {object} types.ErrorResponse
 // @Failure 404 {object} types.ErrorResponse
+// @Failure 415 {object} types.ErrorResponse "File content does not match its extension"
+// @Failure 422 {object} types.ErrorResponse "File rejected by the upload scan"
 // @Failure 500 {object} types.ErrorResponse
 // @Router /profile/avatar [put]
 func (c *ProfileController) UpdateAvatar(ctx *router.Context) error {
@@ -128,6 +133,8 @@ func (c *ProfileController) UpdateAvatar(ctx *router.Context) error {
 
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{Error: "User not found"})
+		} else if status := avatarErrorStatus(err); status != 0 {
+			return ctx.JSON(status, types.ErrorResponse{Error: err.Error()})
 		} else {
 			return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to update avatar: " + err.Error()})
 		}
@@ -136,6 +143,94 @@ func (c *ProfileController) UpdateAvatar(ctx *router.Context) error {
 	return ctx.JSON(http.StatusOK, updatedUser)
 }
 
+// @Summary Upload profile avatar
+// @Description Upload an avatar image for the authenticated user, replacing any existing one. Allowed: jpg, jpeg, png, gif, webp up to 5MB
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Profile
+// @Accept multipart/form-data
+// @Produce json
+// @Param avatar formData file true "Avatar image"
+// @Success 200 {object} AvatarResponse
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 404 {object} types.ErrorResponse
+// @Failure 415 {object} types.ErrorResponse "File content does not match its extension"
+// @Failure 422 {object} types.ErrorResponse "File rejected by the upload scan"
+// @Failure 500 {object} types.ErrorResponse
+// @Router /profile/avatar [post]
+func (c *ProfileController) UploadAvatar(ctx *router.Context) error {
+	id := ctx.GetUint("user_id")
+	if id == 0 {
+		return ctx.JSON(http.StatusUnauthorized, types.ErrorResponse{Error: "Unauthorized"})
+	}
+
+	file, err := ctx.FormFile("avatar")
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Failed to get avatar file: " + err.Error()})
+	}
+
+	avatar, err := c.service.UploadAvatar(ctx, id, file)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{Error: "User not found"})
+		}
+		if status := avatarErrorStatus(err); status != 0 {
+			return ctx.JSON(status, types.ErrorResponse{Error: err.Error()})
+		}
+		c.logger.Error("Failed to upload avatar",
+			logger.Uint("user_id", id),
+			logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to upload avatar"})
+	}
+
+	return ctx.JSON(http.StatusOK, avatar)
+}
+
+// @Summary Remove profile avatar
+// @Description Remove the authenticated user's avatar
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Profile
+// @Produce json
+// @Success 200 {object} UserResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 404 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /profile/avatar [delete]
+func (c *ProfileController) RemoveAvatar(ctx *router.Context) error {
+	id := ctx.GetUint("user_id")
+	if id == 0 {
+		return ctx.JSON(http.StatusUnauthorized, types.ErrorResponse{Error: "Unauthorized"})
+	}
+
+	updatedUser, err := c.service.RemoveAvatar(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{Error: "User not found"})
+		}
+		c.logger.Error("Failed to remove avatar",
+			logger.Uint("user_id", id),
+			logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to remove avatar"})
+	}
+
+	return ctx.JSON(http.StatusOK, updatedUser)
+}
+
+// avatarErrorStatus returns the client error status for a rejected file, or
+// 0 when err was not caused by the file
+func avatarErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrFileTooLarge), errors.Is(err, storage.ErrExtensionNotAllowed):
+		return http.StatusBadRequest
+	case errors.Is(err, storage.ErrContentTypeMismatch):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, storage.ErrFileRejected):
+		return http.StatusUnprocessableEntity
+	}
+	return 0
+}
+
 // @Summary Update profile password from Authenticated User Token
 // @Description Update profile password by Bearer Token
 // @Security ApiKeyAuth
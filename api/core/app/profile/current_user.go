@@ -0,0 +1,40 @@
+package profile
+
+import (
+	"base/core/router"
+
+	"gorm.io/gorm"
+)
+
+// LoadCurrentUser is middleware that makes the authenticated user available
+// through CurrentUser. The user is read from "user_id", so the middleware can
+// run before or after authentication, and is only queried, with its role,
+// when a handler asks for it.
+func LoadCurrentUser(db *gorm.DB) router.MiddlewareFunc {
+	loader := func(c *router.Context) (any, error) {
+		userId := c.GetUint("user_id")
+		if userId == 0 {
+			return nil, nil
+		}
+
+		var user User
+		if err := db.Preload("Role").First(&user, userId).Error; err != nil {
+			return nil, err
+		}
+		return &user, nil
+	}
+
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			c.SetUserLoader(loader)
+			return next(c)
+		}
+	}
+}
+
+// CurrentUser returns the authenticated user loaded by LoadCurrentUser, or
+// nil for anonymous requests
+func CurrentUser(c *router.Context) *User {
+	user, _ := c.CurrentUser().(*User)
+	return user
+}
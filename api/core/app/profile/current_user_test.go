@@ -0,0 +1,94 @@
+package profile
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"base/core/app/authorization"
+	"base/core/router"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func TestCurrentUserReturnsAuthenticatedUser(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(&authorization.Role{}, &User{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	role := authorization.Role{Name: "Member"}
+	if err := db.Create(&role).Error; err != nil {
+		t.Fatal(err)
+	}
+	user := User{FirstName: "Ada", LastName: "Lovelace", Username: "ada", Phone: "+38344000001", Email: "ada@example.com", RoleId: role.Id}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	queries := 0
+	db.Callback().Query().After("gorm:query").Register("test:count", func(*gorm.DB) { queries++ })
+
+	// Authenticates the user named by the X-User header, if any
+	authenticate := func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			if id, err := strconv.ParseUint(c.Request.Header.Get("X-User"), 10, 64); err == nil {
+				c.Set("user_id", uint(id))
+			}
+			return next(c)
+		}
+	}
+
+	var current *User
+	r := router.New()
+	r.Use(LoadCurrentUser(db), authenticate)
+	r.GET("/me", func(c *router.Context) error {
+		current = CurrentUser(c)
+		CurrentUser(c)
+		return c.String(http.StatusOK, "ok")
+	})
+	r.GET("/ping", func(c *router.Context) error {
+		return c.String(http.StatusOK, "pong")
+	})
+
+	send := func(path, userId string) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if userId != "" {
+			req.Header.Set("X-User", userId)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	send("/me", strconv.FormatUint(uint64(user.Id), 10))
+	if current == nil || current.Id != user.Id || current.Email != "ada@example.com" {
+		t.Fatalf("CurrentUser = %+v, want the authenticated user", current)
+	}
+	if current.Role == nil || current.Role.Name != "Member" {
+		t.Errorf("CurrentUser role = %+v, want Member preloaded", current.Role)
+	}
+	// The user and its role, once per request
+	if queries != 2 {
+		t.Errorf("%d queries for two CurrentUser calls, want 2", queries)
+	}
+
+	queries = 0
+	send("/ping", strconv.FormatUint(uint64(user.Id), 10))
+	if queries != 0 {
+		t.Errorf("handler without CurrentUser ran %d queries, want none", queries)
+	}
+
+	send("/me", "")
+	if current != nil {
+		t.Errorf("anonymous CurrentUser = %+v, want nil", current)
+	}
+	send("/me", "999")
+	if current != nil {
+		t.Errorf("CurrentUser of a missing user = %+v, want nil", current)
+	}
+}
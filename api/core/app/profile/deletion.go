@@ -0,0 +1,57 @@
+package profile
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Account deletion modes, selected with ACCOUNT_DELETION_MODE
+const (
+	// DeletionAnonymize keeps a deleted user's data, tied only to the erased account
+	DeletionAnonymize = "anonymize"
+	// DeletionHardDelete permanently removes a deleted user's data
+	DeletionHardDelete = "delete"
+)
+
+// DeletionHook removes or anonymizes a module's data for a user whose account
+// is being deleted. It runs inside the deletion transaction; returning an
+// error rolls the whole deletion back.
+type DeletionHook func(tx *gorm.DB, userId uint, mode string) error
+
+var (
+	deletionHooksMutex sync.RWMutex
+	deletionHooks      = make(map[string]DeletionHook)
+)
+
+// RegisterDeletionHook registers the data cleanup a module needs when a user
+// deletes their account. Registering a name again replaces its hook.
+func RegisterDeletionHook(name string, hook DeletionHook) {
+	deletionHooksMutex.Lock()
+	defer deletionHooksMutex.Unlock()
+	deletionHooks[name] = hook
+}
+
+// RunDeletionHooks runs every registered hook in name order
+func RunDeletionHooks(tx *gorm.DB, userId uint, mode string) error {
+	deletionHooksMutex.RLock()
+	names := make([]string, 0, len(deletionHooks))
+	for name := range deletionHooks {
+		names = append(names, name)
+	}
+	hooks := make(map[string]DeletionHook, len(deletionHooks))
+	for name, hook := range deletionHooks {
+		hooks[name] = hook
+	}
+	deletionHooksMutex.RUnlock()
+
+	sort.Strings(names)
+	for _, name := range names {
+		if err := hooks[name](tx, userId, mode); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
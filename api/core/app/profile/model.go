@@ -15,6 +15,7 @@ type User struct {
 	Username  string              `gorm:"column:username;unique;not null;size:255"`
 	Phone     string              `gorm:"column:phone;unique;size:255"`
 	Email     string              `gorm:"column:email;unique;not null;size:255"`
+	Language  string              `gorm:"column:language;size:16"` // Preferred locale, e.g. "en" or "sq"
 	RoleId    uint                `gorm:"column:role_id;default:3"`
 	Role      *authorization.Role `gorm:"foreignKey:RoleId"`
 	Avatar    *storage.Attachment `gorm:"foreignKey:ModelId;references:Id"`
@@ -44,6 +45,7 @@ type UpdateRequest struct {
 	Username  string `form:"username" binding:"max=255"`
 	Phone     string `form:"phone" binding:"max=255"`
 	Email     string `form:"email" binding:"email,max=255"`
+	Language  string `form:"language" binding:"max=16"`
 }
 
 type UpdatePasswordRequest struct {
@@ -68,6 +70,7 @@ type UserResponse struct {
 	Username  string `json:"username"`
 	Phone     string `json:"phone"`
 	Email     string `json:"email"`
+	Language  string `json:"language"`
 	RoleId    uint   `json:"role_id"`
 	RoleName  string `json:"role_name"`
 	AvatarURL string `json:"avatar_url"`
@@ -93,6 +96,7 @@ func (u *User) ToResponse() *UserResponse {
 		Username:  u.Username,
 		Phone:     u.Phone,
 		Email:     u.Email,
+		Language:  u.Language,
 		RoleId:    u.RoleId,
 	}
 
@@ -34,7 +34,7 @@ func NewProfileService(db *gorm.DB, logger logger.Logger, activeStorage *storage
 	activeStorage.RegisterAttachment("users", storage.AttachmentConfig{
 		Field:             "avatar",
 		Path:              "avatars",
-		AllowedExtensions: []string{".jpg", ".jpeg", ".png", ".gif"},
+		AllowedExtensions: []string{".jpg", ".jpeg", ".png", ".gif", ".webp"},
 		MaxFileSize:       5 << 20, // 5MB
 		Multiple:          false,
 	})
@@ -89,6 +89,9 @@ func (s *ProfileService) Update(id uint, req *UpdateRequest) (*UserResponse, err
 	if req.Email != "" {
 		user.Email = req.Email
 	}
+	if req.Language != "" {
+		user.Language = req.Language
+	}
 
 	if err := s.db.Save(&user).Error; err != nil {
 		s.logger.Error("Failed to save user updates",
@@ -100,59 +103,90 @@ func (s *ProfileService) Update(id uint, req *UpdateRequest) (*UserResponse, err
 	return s.ToResponse(&user), nil
 }
 
+// UpdateAvatar replaces the user's avatar and returns the updated profile
 func (s *ProfileService) UpdateAvatar(ctx context.Context, id uint, avatarFile *multipart.FileHeader) (*UserResponse, error) {
+	user, err := s.replaceAvatar(id, avatarFile)
+	if err != nil {
+		return nil, err
+	}
+	return s.ToResponse(user), nil
+}
+
+// UploadAvatar replaces the user's avatar and returns the new avatar
+func (s *ProfileService) UploadAvatar(ctx context.Context, id uint, avatarFile *multipart.FileHeader) (*AvatarResponse, error) {
+	user, err := s.replaceAvatar(id, avatarFile)
+	if err != nil {
+		return nil, err
+	}
+	return &AvatarResponse{
+		Id:       user.Avatar.Id,
+		Filename: user.Avatar.Filename,
+		URL:      user.Avatar.URL,
+	}, nil
+}
+
+// replaceAvatar stores a new avatar for the user and then removes the previous
+// one, so a failed upload leaves the existing avatar in place
+func (s *ProfileService) replaceAvatar(id uint, avatarFile *multipart.FileHeader) (*User, error) {
 	var user User
-	if err := s.db.First(&user, id).Error; err != nil {
+	if err := s.db.Preload("Role").First(&user, id).Error; err != nil {
 		return nil, err
 	}
 
-	// Just attach the new file - cleanup is handled inside Attach
+	previous, err := s.activeStorage.AttachmentsFor(&user, "avatar")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load avatar: %w", err)
+	}
+
 	attachment, err := s.activeStorage.Attach(&user, "avatar", avatarFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload avatar: %w", err)
 	}
 
-	// Update user's avatar
-	user.Avatar = attachment
-	if err := s.db.Save(&user).Error; err != nil {
+	if err := s.db.Model(&user).Update("avatar", attachment).Error; err != nil {
+		_ = s.activeStorage.Delete(attachment)
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
+	user.Avatar = attachment
 
-	return s.ToResponse(&user), nil
+	for i := range previous {
+		if err := s.activeStorage.Delete(&previous[i]); err != nil {
+			// The new avatar is already in place; a stale file is only logged
+			s.logger.Error("Failed to delete previous avatar",
+				zap.Error(err),
+				zap.Uint("user_id", id),
+				zap.Uint("attachment_id", previous[i].Id))
+		}
+	}
+
+	return &user, nil
 }
 
+// RemoveAvatar deletes the user's avatar files and attachment records
 func (s *ProfileService) RemoveAvatar(ctx context.Context, id uint) (*UserResponse, error) {
-	tx := s.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
 	var user User
-	if err := tx.First(&user, id).Error; err != nil {
-		tx.Rollback()
+	if err := s.db.Preload("Role").First(&user, id).Error; err != nil {
 		return nil, err
 	}
 
-	if user.Avatar != nil {
-		if err := s.activeStorage.Delete(user.Avatar); err != nil {
-			tx.Rollback()
+	attachments, err := s.activeStorage.AttachmentsFor(&user, "avatar")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load avatar: %w", err)
+	}
+
+	for i := range attachments {
+		if err := s.activeStorage.Delete(&attachments[i]); err != nil {
 			s.logger.Error("Failed to delete avatar",
 				zap.Error(err),
 				zap.Uint("user_id", id))
 			return nil, fmt.Errorf("failed to delete avatar: %w", err)
 		}
-		user.Avatar = nil
-		if err := tx.Save(&user).Error; err != nil {
-			tx.Rollback()
-			return nil, err
-		}
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		return nil, err
+	if err := s.db.Model(&user).Update("avatar", nil).Error; err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
+	user.Avatar = nil
 
 	return s.ToResponse(&user), nil
 }
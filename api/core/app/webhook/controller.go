@@ -0,0 +1,250 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"base/core/app/authorization"
+	"base/core/logger"
+	"base/core/router"
+	"base/core/types"
+)
+
+type WebhookController struct {
+	service      *WebhookService
+	authzService *authorization.AuthorizationService
+	logger       logger.Logger
+}
+
+func NewWebhookController(service *WebhookService, authzService *authorization.AuthorizationService, logger logger.Logger) *WebhookController {
+	return &WebhookController{
+		service:      service,
+		authzService: authzService,
+		logger:       logger,
+	}
+}
+
+// Routes registers the webhook management routes, restricted to Owner and
+// Administrator roles. They live under /admin because /webhooks is the
+// unauthenticated path of inbound webhooks.
+func (c *WebhookController) Routes(router *router.RouterGroup) {
+	webhooks := router.Group("/admin/webhooks", authorization.RequireAnyRole(c.authzService, "Owner", "Administrator"))
+	webhooks.GET("", c.List)
+	webhooks.POST("", c.Create)
+	webhooks.GET("/:id", c.Get)
+	webhooks.PUT("/:id", c.Update)
+	webhooks.DELETE("/:id", c.Delete)
+	webhooks.GET("/:id/deliveries", c.Deliveries)
+}
+
+// List godoc
+// @Summary List webhooks
+// @Description Lists the registered outbound webhooks, oldest first. Secrets are never returned.
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Webhooks
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page, capped at MAX_PAGE_SIZE"
+// @Success 200 {object} types.PaginatedResponse{data=[]Webhook}
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 403 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /admin/webhooks [get]
+func (c *WebhookController) List(ctx *router.Context) error {
+	filters, err := ctx.QueryFilters()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: err.Error()})
+	}
+
+	response, err := c.service.List(filters.Page, filters.Limit)
+	if err != nil {
+		c.logger.Error("Failed to list webhooks", logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to fetch webhooks"})
+	}
+	response.AddLinks(ctx.Request.URL)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// Create godoc
+// @Summary Register a webhook
+// @Description Registers an external url receiving the listed emitter events, e.g. user.registered or games.achievement.unlocked.
+// @Description Each event is POSTed as JSON with X-Webhook-Event, X-Webhook-Delivery, X-Webhook-Timestamp and X-Webhook-Signature headers;
+// @Description the signature is sha256= and the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret.
+// @Description A secret is generated when none is given. It is only returned by this call.
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body CreateWebhookRequest true "Webhook"
+// @Success 201 {object} CreateWebhookResponse
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 403 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /admin/webhooks [post]
+func (c *WebhookController) Create(ctx *router.Context) error {
+	var req CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: err.Error()})
+	}
+
+	response, err := c.service.Create(&req)
+	if err != nil {
+		return c.serviceError(ctx, "Failed to create webhook", err)
+	}
+
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+// Get godoc
+// @Summary Get a webhook
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Webhooks
+// @Produce json
+// @Param id path int true "Webhook Id"
+// @Success 200 {object} Webhook
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 403 {object} types.ErrorResponse
+// @Failure 404 {object} types.ErrorResponse
+// @Router /admin/webhooks/{id} [get]
+func (c *WebhookController) Get(ctx *router.Context) error {
+	id, err := parseId(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: err.Error()})
+	}
+
+	webhook, err := c.service.Get(id)
+	if err != nil {
+		return c.serviceError(ctx, "Failed to fetch webhook", err)
+	}
+
+	return ctx.JSON(http.StatusOK, webhook)
+}
+
+// Update godoc
+// @Summary Update a webhook
+// @Description Changes the fields sent. Queued deliveries are sent with the new url and secret.
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook Id"
+// @Param webhook body UpdateWebhookRequest true "Webhook fields"
+// @Success 200 {object} Webhook
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 403 {object} types.ErrorResponse
+// @Failure 404 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /admin/webhooks/{id} [put]
+func (c *WebhookController) Update(ctx *router.Context) error {
+	id, err := parseId(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: err.Error()})
+	}
+
+	var req UpdateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: err.Error()})
+	}
+
+	webhook, err := c.service.Update(id, &req)
+	if err != nil {
+		return c.serviceError(ctx, "Failed to update webhook", err)
+	}
+
+	return ctx.JSON(http.StatusOK, webhook)
+}
+
+// Delete godoc
+// @Summary Delete a webhook
+// @Description Removes the webhook and its delivery log
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Webhooks
+// @Param id path int true "Webhook Id"
+// @Success 204
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 403 {object} types.ErrorResponse
+// @Failure 404 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /admin/webhooks/{id} [delete]
+func (c *WebhookController) Delete(ctx *router.Context) error {
+	id, err := parseId(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: err.Error()})
+	}
+
+	if err := c.service.Delete(id); err != nil {
+		return c.serviceError(ctx, "Failed to delete webhook", err)
+	}
+
+	return ctx.NoContent()
+}
+
+// Deliveries godoc
+// @Summary List webhook deliveries
+// @Description Lists the deliveries of a webhook, newest first, with their status, attempts and last error
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Tags Core/Webhooks
+// @Produce json
+// @Param id path int true "Webhook Id"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page, capped at MAX_PAGE_SIZE"
+// @Success 200 {object} types.PaginatedResponse{data=[]DeliveryResponse}
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 401 {object} types.ErrorResponse
+// @Failure 403 {object} types.ErrorResponse
+// @Failure 404 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /admin/webhooks/{id}/deliveries [get]
+func (c *WebhookController) Deliveries(ctx *router.Context) error {
+	id, err := parseId(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: err.Error()})
+	}
+	filters, err := ctx.QueryFilters()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: err.Error()})
+	}
+
+	response, err := c.service.Deliveries(id, filters.Page, filters.Limit)
+	if err != nil {
+		return c.serviceError(ctx, "Failed to fetch webhook deliveries", err)
+	}
+	response.AddLinks(ctx.Request.URL)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// serviceError maps service errors to a status, logging unexpected ones
+// under message
+func (c *WebhookController) serviceError(ctx *router.Context, message string, err error) error {
+	switch {
+	case errors.Is(err, ErrWebhookNotFound):
+		return ctx.JSON(http.StatusNotFound, types.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidURL), errors.Is(err, ErrNoEvents):
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: err.Error()})
+	}
+	c.logger.Error(message, logger.String("error", err.Error()))
+	return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: message})
+}
+
+// parseId reads the webhook id path parameter
+func parseId(ctx *router.Context) (uint, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return 0, errors.New("invalid id parameter")
+	}
+	return uint(id), nil
+}
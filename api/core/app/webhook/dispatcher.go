@@ -0,0 +1,239 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"base/core/logger"
+
+	"gorm.io/gorm"
+)
+
+// Headers of a webhook request. The signature is "sha256=" followed by the
+// hex HMAC-SHA256 of the timestamp, a dot and the body, keyed with the
+// webhook secret; receivers should also reject old timestamps.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// DispatcherConfig tunes the dispatcher. Zero values use the defaults.
+type DispatcherConfig struct {
+	PollInterval time.Duration // How often due deliveries are looked up, default 2s
+	BatchSize    int           // Deliveries sent per poll, default 50
+	MaxAttempts  int           // Attempts before a delivery is marked failed, default 8
+	Timeout      time.Duration // Timeout of a single request, default 10s
+}
+
+// Dispatcher sends the queued deliveries in the background, retrying failed
+// ones with exponential backoff. A delivery succeeds on any 2xx response.
+type Dispatcher struct {
+	db     *gorm.DB
+	logger logger.Logger
+	config DispatcherConfig
+	client *http.Client
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	stopped chan struct{}
+}
+
+// NewDispatcher creates a dispatcher sending deliveries once started
+func NewDispatcher(db *gorm.DB, log logger.Logger, config DispatcherConfig) *Dispatcher {
+	if config.PollInterval <= 0 {
+		config.PollInterval = 2 * time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 50
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 8
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	return &Dispatcher{
+		db:     db,
+		logger: log,
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Start sends deliveries in the background until Stop is called
+func (d *Dispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.stopped = make(chan struct{})
+	go d.run(ctx, d.stopped)
+}
+
+// Stop cancels the requests in flight and waits for the dispatcher to return
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	cancel, stopped := d.cancel, d.stopped
+	d.cancel = nil
+	d.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	<-stopped
+}
+
+func (d *Dispatcher) run(ctx context.Context, stopped chan<- struct{}) {
+	defer close(stopped)
+
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep sending while full batches come back
+		for d.DeliverBatch(ctx) == d.config.BatchSize && ctx.Err() == nil {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverBatch sends the deliveries that are due and returns how many it
+// looked at
+func (d *Dispatcher) DeliverBatch(ctx context.Context) int {
+	now := time.Now()
+
+	var due []Delivery
+	err := d.db.Where("status = ? AND available_at <= ?", DeliveryPending, now).
+		Order("id ASC").
+		Limit(d.config.BatchSize).
+		Find(&due).Error
+	if err != nil {
+		d.logger.Error("Failed to load pending webhook deliveries", logger.String("error", err.Error()))
+		return 0
+	}
+
+	for _, delivery := range due {
+		if ctx.Err() != nil {
+			break
+		}
+
+		// Claim the delivery past the request timeout, so a concurrent
+		// dispatcher skips it
+		claim := d.db.Model(&Delivery{}).
+			Where("id = ? AND status = ? AND available_at <= ?", delivery.Id, DeliveryPending, now).
+			Updates(map[string]any{
+				"attempts":     gorm.Expr("attempts + 1"),
+				"available_at": now.Add(2 * d.config.Timeout),
+			})
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			continue
+		}
+		delivery.Attempts++
+
+		var webhook Webhook
+		if err := d.db.First(&webhook, delivery.WebhookId).Error; err != nil || !webhook.Active {
+			d.db.Model(&Delivery{}).Where("id = ?", delivery.Id).Updates(map[string]any{
+				"status":     DeliveryFailed,
+				"last_error": "webhook removed or inactive",
+			})
+			continue
+		}
+
+		status, err := d.send(ctx, &webhook, &delivery)
+		if err != nil {
+			d.retryLater(delivery, status, err)
+			continue
+		}
+
+		delivered := time.Now()
+		d.db.Model(&Delivery{}).Where("id = ?", delivery.Id).Updates(map[string]any{
+			"status":          DeliveryDelivered,
+			"response_status": status,
+			"delivered_at":    &delivered,
+			"last_error":      "",
+		})
+	}
+
+	return len(due)
+}
+
+// send POSTs the delivery and returns the response status
+func (d *Dispatcher) send(ctx context.Context, webhook *Webhook, delivery *Delivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.Id), 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryLater backs off exponentially, up to an hour, and marks the delivery
+// failed once it used up its attempts
+func (d *Dispatcher) retryLater(delivery Delivery, status int, err error) {
+	d.logger.Warn("Webhook delivery failed",
+		logger.Uint("delivery_id", delivery.Id),
+		logger.Uint("webhook_id", delivery.WebhookId),
+		logger.String("event", delivery.Event),
+		logger.Int("attempt", delivery.Attempts),
+		logger.String("error", err.Error()))
+
+	updates := map[string]any{
+		"response_status": status,
+		"last_error":      err.Error(),
+	}
+	if delivery.Attempts >= d.config.MaxAttempts {
+		updates["status"] = DeliveryFailed
+	} else {
+		backoff := time.Second << min(delivery.Attempts, 12)
+		updates["available_at"] = time.Now().Add(min(backoff, time.Hour))
+	}
+	d.db.Model(&Delivery{}).Where("id = ?", delivery.Id).Updates(updates)
+}
+
+// Sign returns the signature header value of body sent at timestamp
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
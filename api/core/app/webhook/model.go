@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Statuses of a delivery
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is an external endpoint receiving the emitter events it subscribes
+// to. Secret signs every request, see Sign.
+type Webhook struct {
+	Id          uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	URL         string    `gorm:"column:url;size:2048;not null" json:"url"`
+	Secret      string    `gorm:"column:secret;size:128;not null" json:"-"`
+	Events      []string  `gorm:"column:events;type:text;serializer:json" json:"events"`
+	Description string    `gorm:"column:description;size:255" json:"description"`
+	Active      bool      `gorm:"column:active;not null" json:"active"`
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Subscribes reports whether the webhook receives event
+func (w *Webhook) Subscribes(event string) bool {
+	for _, name := range w.Events {
+		if name == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is one event sent, or still to be sent, to a webhook. Failed
+// attempts are retried with backoff until the delivery is marked failed.
+type Delivery struct {
+	Id             uint       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	WebhookId      uint       `gorm:"column:webhook_id;not null;index" json:"webhook_id"`
+	Event          string     `gorm:"column:event;size:255;not null" json:"event"`
+	Payload        string     `gorm:"column:payload;type:text" json:"-"`
+	Status         string     `gorm:"column:status;size:16;not null;index:idx_webhook_deliveries_status_available" json:"status"`
+	Attempts       int        `gorm:"column:attempts;not null;default:0" json:"attempts"`
+	ResponseStatus int        `gorm:"column:response_status" json:"response_status,omitempty"` // HTTP status of the last attempt
+	LastError      string     `gorm:"column:last_error;type:text" json:"last_error,omitempty"`
+	AvailableAt    time.Time  `gorm:"column:available_at;index:idx_webhook_deliveries_status_available" json:"available_at"` // Not attempted before this time
+	CreatedAt      time.Time  `gorm:"column:created_at;index" json:"created_at"`
+	DeliveredAt    *time.Time `gorm:"column:delivered_at" json:"delivered_at,omitempty"`
+}
+
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// DeliveryResponse is a delivery as returned by the API
+type DeliveryResponse struct {
+	Delivery
+	Payload json.RawMessage `json:"payload" swaggertype:"object"`
+}
+
+// ToResponse converts the delivery to its API representation
+func (d *Delivery) ToResponse() *DeliveryResponse {
+	return &DeliveryResponse{Delivery: *d, Payload: json.RawMessage(d.Payload)}
+}
+
+// Envelope is the JSON body POSTed to a webhook
+type Envelope struct {
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// CreateWebhookRequest registers a webhook. A secret is generated when none
+// is given.
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required" example:"https://example.com/hooks/multiplex"`
+	Events      []string `json:"events" binding:"required,min=1" example:"user.registered,games.achievement.unlocked"`
+	Secret      string   `json:"secret"`
+	Description string   `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+// UpdateWebhookRequest changes the fields that are set
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url"`
+	Events      []string `json:"events"`
+	Secret      *string  `json:"secret"`
+	Description *string  `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+// CreateWebhookResponse is a new webhook with its secret, which is not
+// returned again
+type CreateWebhookResponse struct {
+	*Webhook
+	Secret string `json:"secret"`
+}
@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"base/core/app/authorization"
+	"base/core/emitter"
+	"base/core/logger"
+	"base/core/module"
+	"base/core/router"
+
+	"gorm.io/gorm"
+)
+
+type WebhookModule struct {
+	module.DefaultModule
+	DB         *gorm.DB
+	Controller *WebhookController
+	Service    *WebhookService
+	Logger     logger.Logger
+}
+
+func NewWebhookModule(db *gorm.DB, router *router.RouterGroup, logger logger.Logger, emitter *emitter.Emitter) module.Module {
+	service := NewWebhookService(db, emitter, logger)
+	controller := NewWebhookController(service, authorization.NewAuthorizationService(db), logger)
+
+	return &WebhookModule{
+		DB:         db,
+		Controller: controller,
+		Service:    service,
+		Logger:     logger,
+	}
+}
+
+func (m *WebhookModule) Routes(router *router.RouterGroup) {
+	m.Controller.Routes(router)
+}
+
+func (m *WebhookModule) Migrate() error {
+	if err := m.DB.AutoMigrate(&Webhook{}, &Delivery{}); err != nil {
+		return err
+	}
+
+	// Listen to the events of the webhooks stored by previous runs
+	return m.Service.SubscribeStored()
+}
+
+func (m *WebhookModule) GetModels() []any {
+	return []any{&Webhook{}, &Delivery{}}
+}
@@ -0,0 +1,307 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"base/core/emitter"
+	"base/core/logger"
+	"base/core/types"
+
+	"gorm.io/gorm"
+)
+
+// Webhook errors
+var (
+	ErrWebhookNotFound = errors.New("webhook not found")
+	ErrInvalidURL      = errors.New("webhook url must be an absolute http or https url")
+	ErrNoEvents        = errors.New("webhook must subscribe to at least one event")
+)
+
+// WebhookService manages webhooks and queues a delivery for each webhook
+// subscribed to an emitted event. The Dispatcher sends them.
+type WebhookService struct {
+	DB      *gorm.DB
+	Emitter *emitter.Emitter
+	Logger  logger.Logger
+
+	mu         sync.Mutex
+	subscribed map[string]bool
+}
+
+func NewWebhookService(db *gorm.DB, emitter *emitter.Emitter, logger logger.Logger) *WebhookService {
+	return &WebhookService{
+		DB:         db,
+		Emitter:    emitter,
+		Logger:     logger,
+		subscribed: make(map[string]bool),
+	}
+}
+
+// Create registers a webhook and subscribes to its events
+func (s *WebhookService) Create(req *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+	webhook := &Webhook{
+		URL:         strings.TrimSpace(req.URL),
+		Events:      normalizeEvents(req.Events),
+		Secret:      req.Secret,
+		Description: req.Description,
+		Active:      req.Active == nil || *req.Active,
+	}
+	if err := validate(webhook); err != nil {
+		return nil, err
+	}
+	if webhook.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		webhook.Secret = secret
+	}
+
+	if err := s.DB.Create(webhook).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	s.subscribe(webhook.Events)
+
+	return &CreateWebhookResponse{Webhook: webhook, Secret: webhook.Secret}, nil
+}
+
+// List returns a page of the webhooks, oldest first
+func (s *WebhookService) List(page, limit *int) (*types.PaginatedResponse, error) {
+	currentPage, pageSize := types.NormalizePagination(page, limit)
+
+	var total int64
+	if err := s.DB.Model(&Webhook{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	webhooks := []*Webhook{}
+	offset := (currentPage - 1) * pageSize
+	if err := s.DB.Order("id ASC").Offset(offset).Limit(pageSize).Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.PaginatedResponse{
+		Data: webhooks,
+		Pagination: types.Pagination{
+			Total:      int(total),
+			Page:       currentPage,
+			PageSize:   pageSize,
+			TotalPages: int(total+int64(pageSize)-1) / pageSize,
+		},
+	}, nil
+}
+
+// Get returns a webhook or ErrWebhookNotFound
+func (s *WebhookService) Get(id uint) (*Webhook, error) {
+	var webhook Webhook
+	if err := s.DB.First(&webhook, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// Update changes a webhook. Deliveries already queued keep their payload but
+// are sent with the new url and secret.
+func (s *WebhookService) Update(id uint, req *UpdateWebhookRequest) (*Webhook, error) {
+	webhook, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		webhook.URL = strings.TrimSpace(*req.URL)
+	}
+	if req.Events != nil {
+		webhook.Events = normalizeEvents(req.Events)
+	}
+	if req.Secret != nil && *req.Secret != "" {
+		webhook.Secret = *req.Secret
+	}
+	if req.Description != nil {
+		webhook.Description = *req.Description
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+	if err := validate(webhook); err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.Save(webhook).Error; err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+	s.subscribe(webhook.Events)
+
+	return webhook, nil
+}
+
+// Delete removes a webhook with its delivery log
+func (s *WebhookService) Delete(id uint) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&Webhook{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrWebhookNotFound
+		}
+		return tx.Where("webhook_id = ?", id).Delete(&Delivery{}).Error
+	})
+}
+
+// Deliveries returns a page of the webhook's delivery log, newest first
+func (s *WebhookService) Deliveries(id uint, page, limit *int) (*types.PaginatedResponse, error) {
+	if _, err := s.Get(id); err != nil {
+		return nil, err
+	}
+	currentPage, pageSize := types.NormalizePagination(page, limit)
+
+	query := s.DB.Model(&Delivery{}).Where("webhook_id = ?", id)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var deliveries []*Delivery
+	offset := (currentPage - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]*DeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		responses[i] = delivery.ToResponse()
+	}
+
+	return &types.PaginatedResponse{
+		Data: responses,
+		Pagination: types.Pagination{
+			Total:      int(total),
+			Page:       currentPage,
+			PageSize:   pageSize,
+			TotalPages: int(total+int64(pageSize)-1) / pageSize,
+		},
+	}, nil
+}
+
+// Enqueue queues a delivery of event to every active webhook subscribed to it
+func (s *WebhookService) Enqueue(event string, data any) error {
+	var webhooks []*Webhook
+	if err := s.DB.Where("active = ?", true).Find(&webhooks).Error; err != nil {
+		return fmt.Errorf("failed to load webhooks: %w", err)
+	}
+
+	now := time.Now()
+	var deliveries []*Delivery
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(event) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(Envelope{Event: event, CreatedAt: now, Data: data}); err != nil {
+				return fmt.Errorf("failed to encode event %s: %w", event, err)
+			}
+		}
+		deliveries = append(deliveries, &Delivery{
+			WebhookId:   webhook.Id,
+			Event:       event,
+			Payload:     string(payload),
+			Status:      DeliveryPending,
+			AvailableAt: now,
+			CreatedAt:   now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return s.DB.Create(&deliveries).Error
+}
+
+// SubscribeStored subscribes to the events of the stored webhooks, so they
+// are delivered after a restart
+func (s *WebhookService) SubscribeStored() error {
+	var webhooks []*Webhook
+	if err := s.DB.Select("events").Find(&webhooks).Error; err != nil {
+		return err
+	}
+	for _, webhook := range webhooks {
+		s.subscribe(webhook.Events)
+	}
+	return nil
+}
+
+// subscribe listens to the events not listened to yet. Listeners stay when
+// a webhook is removed; Enqueue skips events nobody subscribes to anymore.
+func (s *WebhookService) subscribe(events []string) {
+	if s.Emitter == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range events {
+		if s.subscribed[event] {
+			continue
+		}
+		s.subscribed[event] = true
+
+		name := event
+		s.Emitter.On(name, func(data any) {
+			if err := s.Enqueue(name, data); err != nil {
+				s.Logger.Error("Failed to queue webhook deliveries",
+					logger.String("event", name),
+					logger.String("error", err.Error()))
+			}
+		})
+	}
+}
+
+// validate checks the url and events of a webhook
+func validate(webhook *Webhook) error {
+	parsed, err := url.Parse(webhook.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidURL
+	}
+	if len(webhook.Events) == 0 {
+		return ErrNoEvents
+	}
+	return nil
+}
+
+// normalizeEvents trims event names and drops empty and repeated ones
+func normalizeEvents(events []string) []string {
+	seen := make(map[string]bool, len(events))
+	normalized := []string{}
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		if event == "" || seen[event] {
+			continue
+		}
+		seen[event] = true
+		normalized = append(normalized, event)
+	}
+	return normalized
+}
+
+// generateSecret returns a random hex encoded signing secret
+func generateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -1,12 +1,18 @@
 package config
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"base/core/logger"
 )
 
 // Configuration defaults - centralized for easier maintenance
@@ -27,15 +33,72 @@ const (
 	DefaultDBName     = "mydatabase"
 	DefaultDBPath     = "test.db"
 
+	// Connection pool defaults for MySQL and Postgres; see DefaultDBPool
+	DefaultDBMaxOpenConns    = 25
+	DefaultDBMaxIdleConns    = 10
+	DefaultDBConnMaxLifetime = 30 * time.Minute
+
+	// Slow query logging is disabled unless a threshold is configured
+	DefaultSlowQueryThreshold = time.Duration(0)
+
+	// Times are stored in UTC and rendered in this zone in responses
+	DefaultResponseTimezone = "UTC"
+
 	// Security defaults
-	DefaultJWTSecret = "secret"
-	DefaultAPIKey    = "test_api_key"
+	DefaultJWTSecret    = "secret"
+	DefaultAPIKey       = "test_api_key"
+	DefaultJWTAlgorithm = JWTAlgorithmHS256
+
+	// JWT signing algorithms: HS256 signs with JWT_SECRET, RS256 with a
+	// private key whose public key other services can verify with
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
 
 	// Email defaults
 	DefaultEmailProvider    = "default"
 	DefaultEmailFromAddress = "no-reply@localhost"
 	DefaultSMTPPort         = 587
 
+	// Email retry defaults
+	DefaultEmailRetryAttempts  = 3
+	DefaultEmailRetryBaseDelay = 500 * time.Millisecond
+	DefaultEmailRetryMaxDelay  = 5 * time.Second
+
+	// Account defaults
+	DefaultAccountDeletionMode = "anonymize"
+	DefaultRegistrationOpen    = true
+	DefaultInviteTTL           = 7 * 24 * time.Hour
+	DefaultUserRole            = "Member"
+	DefaultWebhookTimeout      = 10 * time.Second
+	DefaultWebhookMaxAttempts  = 8
+
+	// Password reset defaults
+	DefaultPasswordResetFormat    = "token"
+	DefaultPasswordResetExpiry    = 15 * time.Minute
+	DefaultPasswordResetOTPLength = 6
+	DefaultPasswordResetOTPExpiry = 5 * time.Minute
+
+	// CORS defaults
+	DefaultCORSMaxAge = 12 * time.Hour
+
+	// HTTP server limits
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultReadTimeout       = 60 * time.Second
+	DefaultWriteTimeout      = 60 * time.Second
+	DefaultIdleTimeout       = 120 * time.Second
+	DefaultMaxHeaderBytes    = 65536 // 64KB
+
+	// Request body defaults
+	DefaultJSONMaxBodySize = 1048576 // 1MB
+	DefaultJSONStrict      = false
+
+	// Expired token cleanup defaults
+	DefaultTokenCleanupInterval = time.Hour
+
+	// Impersonation defaults
+	DefaultImpersonationTTL = 15 * time.Minute
+	MaxImpersonationTTL     = time.Hour
+
 	// Storage defaults
 	DefaultStorageProvider   = "local"
 	DefaultStoragePath       = "storage/uploads"
@@ -44,9 +107,23 @@ const (
 	DefaultStorageBucket     = "default"
 	DefaultStorageExtensions = ".jpg,.jpeg,.png,.gif,.pdf,.doc,.docx"
 
+	// Pagination defaults
+	DefaultPageSize        = 10
+	DefaultMaxPageSize     = 100
+	DefaultPaginationLinks = false
+
+	// Static file defaults
+	DefaultStaticMounts = "/static=./static,/storage=./storage,/docs=./docs"
+
+	// Middleware defaults
+	DefaultMiddlewareOrder = "recovery,compression,api_key,auth,rate_limit,logging,cors"
+
 	// Feature toggles defaults
-	DefaultWebSocketEnabled = true
-	DefaultSwaggerEnabled   = true
+	DefaultWebSocketEnabled   = true
+	DefaultWSCompression      = false
+	DefaultWSBatchWindow      = 25 * time.Millisecond
+	DefaultSwaggerEnabled     = true
+	DefaultEventOutboxEnabled = true
 )
 
 // Config holds the application configuration.
@@ -63,14 +140,36 @@ type Config struct {
 	DBName               string
 	DBPath               string
 	DBURL                string
+	DBReadURLs           []string // Read replica DSNs, used by queries opted in through database.Reader
+	SlowQueryThreshold   time.Duration
+	ResponseTimezone     string        // IANA zone the times read from the database are rendered in
+	DBMaxOpenConns       int           // 0 means unlimited
+	DBMaxIdleConns       int           // Capped at DBMaxOpenConns by database/sql
+	DBConnMaxLifetime    time.Duration // 0 keeps connections open indefinitely
 	ApiKey               string
 	JWTSecret            string
+	JWTAlgorithm         string // HS256 or RS256
+	JWTPrivateKeyFile    string // PEM RSA private key signing RS256 tokens
+	JWTPublicKeyFile     string // PEM RSA public key; derived from the private key when empty
 	ServerAddress        string
 	ServerPort           string
+	TLSCert              string // PEM certificate file; with TLSKey the server uses HTTPS and HTTP/2
+	TLSKey               string // PEM private key file of TLSCert
 	CORSAllowedOrigins   []string
+	CORSGroupOrigins     map[string][]string // Origins per path prefix, replacing CORSAllowedOrigins under it
+	CORSMaxAge           time.Duration       // Access-Control-Max-Age of preflight responses; 0 omits it
+	TrustedProxies       []string // CIDRs or IPs whose X-Forwarded-For and X-Real-IP headers are honoured
+	ReadHeaderTimeout    time.Duration // Time a client has to send the request headers; 0 disables it
+	ReadTimeout          time.Duration // Time a client has to send the whole request; 0 disables it
+	WriteTimeout         time.Duration // Time to write a response; 0 disables it
+	IdleTimeout          time.Duration // How long a keep-alive connection waits for the next request; 0 disables it
+	MaxHeaderBytes       int      // Largest request headers in bytes
+	JSONMaxBodySize      int64    // Largest JSON request body in bytes
+	JSONStrict           bool     // Reject JSON fields the request struct does not declare
 	Version              string
 	EmailProvider        string
 	EmailFromAddress     string
+	EmailFallbackProviders []string // Tried in order when EmailProvider fails with a retryable error
 	SMTPHost             string
 	SMTPPort             int
 	SMTPUsername         string
@@ -78,6 +177,23 @@ type Config struct {
 	SendGridAPIKey       string
 	PostmarkServerToken  string
 	PostmarkAccountToken string
+	EmailRetryAttempts   int
+	EmailRetryBaseDelay  time.Duration
+	EmailRetryMaxDelay   time.Duration
+	AccountDeletionMode  string // "anonymize" keeps game data of deleted accounts, "delete" removes it
+	RegistrationOpen     bool          // Lets anyone register; otherwise registering needs an invite, except for the first user
+	InviteTTL            time.Duration // How long a registration invite can be used
+	DefaultUserRole      string        // Name of the role new users get; the first user always becomes Owner
+	BootstrapAdminEmail    string        // With BootstrapAdminPassword, the Owner created at startup when there are no users
+	BootstrapAdminPassword string
+	PasswordResetFormat    string        // "token" for a long opaque token, "otp" for a short numeric code
+	PasswordResetExpiry    time.Duration // Lifetime of a reset token
+	PasswordResetOTPLength int           // Digits in a reset code
+	PasswordResetOTPExpiry time.Duration // Lifetime of a reset code
+	ImpersonationTTL       time.Duration // Lifetime of an admin impersonation token
+	TokenCleanupInterval   time.Duration // How often expired reset tokens, sessions and revoked tokens are removed; 0 disables
+	WebhookTimeout         time.Duration // Timeout of one outbound webhook request
+	WebhookMaxAttempts     int           // Attempts before an outbound webhook delivery is marked failed
 	StorageProvider      string   `json:"storage_provider"`
 	StoragePath          string   `json:"storage_path"`
 	StorageBaseURL       string   `json:"storage_base_url"`
@@ -88,16 +204,39 @@ type Config struct {
 	StorageRegion        string   `json:"storage_region"`
 	StorageBucket        string   `json:"storage_bucket"`
 	StoragePublicURL     string   `json:"storage_public_url"`
+	StorageCredentialsFile string `json:"storage_credentials_file"`
 	StorageMaxSize       int64    `json:"storage_max_size"`
 	StorageAllowedExt    []string `json:"storage_allowed_ext"`
+	StaticMounts         []StaticMount `json:"static_mounts"`
+	DefaultPageSize      int      `json:"default_page_size"` // Page size of list endpoints when the request gives no limit
+	MaxPageSize          int      `json:"max_page_size"`     // Larger requested limits are capped to this
+	PaginationLinks      bool     `json:"pagination_links"`  // Add first/prev/next/last links to paginated responses
 	WebSocketEnabled     bool     `json:"websocket_enabled"`
+	WSCompression        bool     `json:"ws_compression"`  // Negotiate permessage-deflate with clients supporting it
+	WSBatchWindow        time.Duration `json:"ws_batch_window"` // How long messages are coalesced for clients connecting with batch=true; 0 disables batching
 	SwaggerEnabled       bool     `json:"swagger_enabled"`
+	EventOutboxEnabled   bool     `json:"event_outbox_enabled"` // Store persisted events in the events table and deliver them in the background
 	
 	// Middleware configuration
 	Middleware MiddlewareConfig `json:"middleware"`
 }
 
-// MiddlewareConfig holds middleware configuration settings
+// StaticMount serves the files in Dir under the URL Prefix
+type StaticMount struct {
+	Prefix string `json:"prefix"`
+	Dir    string `json:"dir"`
+}
+
+// MiddlewareConfig holds middleware configuration settings.
+//
+// Config.Reload swaps the live value at runtime. Hot-reloadable fields are the
+// API key, auth, rate limit and logging toggles with their skip paths, the
+// rate limit user paths, the webhook settings and the per-endpoint overrides.
+// RecoveryEnabled, CORSEnabled, the compression settings and Order decide
+// which middleware is mounted at startup, so changing them requires a restart.
+//
+// Read the fields through the methods below; they hold middlewareMu so a
+// request never sees a half-applied reload.
 type MiddlewareConfig struct {
 	// Global middleware toggles
 	APIKeyEnabled     bool     `json:"api_key_enabled"`
@@ -108,10 +247,17 @@ type MiddlewareConfig struct {
 	RateLimitRequests int      `json:"rate_limit_requests"`
 	RateLimitWindow   string   `json:"rate_limit_window"`
 	RateLimitSkipPaths []string `json:"rate_limit_skip_paths"`
+	RateLimitUserPaths []string `json:"rate_limit_user_paths"` // Limited per user instead of per IP
 	LoggingEnabled    bool     `json:"logging_enabled"`
 	LoggingSkipPaths  []string `json:"logging_skip_paths"`
 	RecoveryEnabled   bool     `json:"recovery_enabled"`
 	CORSEnabled       bool     `json:"cors_enabled"`
+	CompressionEnabled bool    `json:"compression_enabled"`
+	CompressionMinSize int     `json:"compression_min_size"` // Bytes; smaller responses are sent as is
+	CompressionLevel   int     `json:"compression_level"`    // -1 (default) or 1 (fastest) to 9 (best)
+	// Order lists the middleware names to mount, outermost first; a name left
+	// out is not mounted at all. See MiddlewareNames.
+	Order []string `json:"order"`
 	
 	// Webhook-specific settings
 	WebhookPaths              []string `json:"webhook_paths"`
@@ -125,26 +271,154 @@ type MiddlewareConfig struct {
 	Overrides map[string]map[string]string `json:"overrides"`
 }
 
+// middlewareMu guards every MiddlewareConfig against concurrent Reload calls.
+// It lives at package level so the config structs stay copyable.
+var middlewareMu sync.RWMutex
+
 // GetRateLimitDuration returns the rate limit window as time.Duration
 func (m *MiddlewareConfig) GetRateLimitDuration() time.Duration {
-	duration, err := time.ParseDuration(m.RateLimitWindow)
-	if err != nil {
-		return time.Minute // default to 1 minute
-	}
-	return duration
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+
+	return parseWindow(m.RateLimitWindow, time.Minute)
 }
 
 // GetWebhookRateLimitDuration returns the webhook rate limit window as time.Duration
 func (m *MiddlewareConfig) GetWebhookRateLimitDuration() time.Duration {
-	duration, err := time.ParseDuration(m.WebhookRateLimitWindow)
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+
+	return parseWindow(m.WebhookRateLimitWindow, time.Hour)
+}
+
+// RateLimitFor returns the request budget and window that apply to a path. A
+// "rate_limit" override wins, then the webhook settings for webhook paths,
+// then the global ones. scope is the pattern of the override applied, empty
+// otherwise, so an override counts its requests apart from other paths.
+func (m *MiddlewareConfig) RateLimitFor(path string) (requests int, window time.Duration, scope string) {
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+
+	// When several overrides match, the strictest applies
+	for overridePath, settings := range m.Overrides {
+		value, exists := settings["rate_limit"]
+		if !exists || value == RateLimitDisabled || !m.pathMatches(path, overridePath) {
+			continue
+		}
+		overrideRequests, overrideWindow, err := ParseRateLimit(value)
+		if err != nil {
+			continue
+		}
+		rate, current := float64(overrideRequests)/overrideWindow.Seconds(), float64(requests)/window.Seconds()
+		if scope == "" || rate < current || (rate == current && overridePath < scope) {
+			requests, window, scope = overrideRequests, overrideWindow, overridePath
+		}
+	}
+	if scope != "" {
+		return requests, window, scope
+	}
+
+	if m.isWebhookPath(path) {
+		return m.WebhookRateLimitRequests, parseWindow(m.WebhookRateLimitWindow, time.Hour), ""
+	}
+	return m.RateLimitRequests, parseWindow(m.RateLimitWindow, time.Minute), ""
+}
+
+// RateLimitBudget is a request budget in effect for a rate limit scope, as
+// returned by RateLimitFor
+type RateLimitBudget struct {
+	Scope    string
+	Requests int
+	Window   time.Duration
+}
+
+// RateLimitBudgets returns every budget RateLimitFor can currently return: the
+// global and webhook ones under the empty scope and one per valid "rate_limit"
+// override under its path pattern.
+func (m *MiddlewareConfig) RateLimitBudgets() []RateLimitBudget {
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+
+	budgets := []RateLimitBudget{
+		{Requests: m.RateLimitRequests, Window: parseWindow(m.RateLimitWindow, time.Minute)},
+		{Requests: m.WebhookRateLimitRequests, Window: parseWindow(m.WebhookRateLimitWindow, time.Hour)},
+	}
+	for overridePath, settings := range m.Overrides {
+		value, exists := settings["rate_limit"]
+		if !exists || value == RateLimitDisabled {
+			continue
+		}
+		if requests, window, err := ParseRateLimit(value); err == nil {
+			budgets = append(budgets, RateLimitBudget{Scope: overridePath, Requests: requests, Window: window})
+		}
+	}
+	return budgets
+}
+
+// RateLimitDisabled is the "rate_limit" override value that turns rate
+// limiting off for a path
+const RateLimitDisabled = "disabled"
+
+// ParseRateLimit parses a "rate_limit" override such as "5/1m": a positive
+// request count, a slash and a positive window
+func ParseRateLimit(value string) (int, time.Duration, error) {
+	requestsStr, windowStr, found := strings.Cut(strings.TrimSpace(value), "/")
+	if !found {
+		return 0, 0, fmt.Errorf("rate limit %q must be <requests>/<window>, e.g. 5/1m", value)
+	}
+	requests, err := strconv.Atoi(strings.TrimSpace(requestsStr))
+	if err != nil || requests <= 0 {
+		return 0, 0, fmt.Errorf("rate limit %q must allow a positive number of requests", value)
+	}
+	window, err := time.ParseDuration(strings.TrimSpace(windowStr))
+	if err != nil || window <= 0 {
+		return 0, 0, fmt.Errorf("rate limit %q must have a positive window such as 30s, 1m or 1h", value)
+	}
+	return requests, window, nil
+}
+
+// validateOverrides checks the override values that have a format
+func validateOverrides(overrides map[string]map[string]string) error {
+	for path, settings := range overrides {
+		if value, exists := settings["rate_limit"]; exists && value != RateLimitDisabled {
+			if _, _, err := ParseRateLimit(value); err != nil {
+				return fmt.Errorf("invalid MIDDLEWARE_OVERRIDES for %s: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// IsWebhookPath checks if a path is configured as a webhook path
+func (m *MiddlewareConfig) IsWebhookPath(path string) bool {
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+
+	return m.isWebhookPath(path)
+}
+
+// IsWebhookSignatureRequired checks if webhook signature verification applies to a path
+func (m *MiddlewareConfig) IsWebhookSignatureRequired(path string) bool {
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+
+	return m.WebhookSignatureEnabled && m.isWebhookPath(path)
+}
+
+// parseWindow parses a rate limit window, falling back when it is invalid
+func parseWindow(window string, fallback time.Duration) time.Duration {
+	duration, err := time.ParseDuration(window)
 	if err != nil {
-		return time.Hour // default to 1 hour
+		return fallback
 	}
 	return duration
 }
 
 // IsAPIKeyRequired checks if API key is required for a given path
 func (m *MiddlewareConfig) IsAPIKeyRequired(path string) bool {
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+
 	if !m.APIKeyEnabled {
 		return false
 	}
@@ -175,6 +449,9 @@ func (m *MiddlewareConfig) IsAPIKeyRequired(path string) bool {
 
 // IsAuthRequired checks if authentication is required for a given path
 func (m *MiddlewareConfig) IsAuthRequired(path string) bool {
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+
 	if !m.AuthEnabled {
 		return false
 	}
@@ -205,6 +482,9 @@ func (m *MiddlewareConfig) IsAuthRequired(path string) bool {
 
 // IsRateLimitRequired checks if rate limiting is required for a given path
 func (m *MiddlewareConfig) IsRateLimitRequired(path string) bool {
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+
 	if !m.RateLimitEnabled {
 		return false
 	}
@@ -215,12 +495,89 @@ func (m *MiddlewareConfig) IsRateLimitRequired(path string) bool {
 			return false
 		}
 	}
+
+	// Check per-endpoint overrides
+	for overridePath, settings := range m.Overrides {
+		if m.pathMatches(path, overridePath) && settings["rate_limit"] == RateLimitDisabled {
+			return false
+		}
+	}
 	
 	return true
 }
 
+// Middleware names for MiddlewareConfig.Order
+const (
+	MiddlewareRecovery    = "recovery"
+	MiddlewareCompression = "compression"
+	MiddlewareAPIKey      = "api_key"
+	MiddlewareAuth        = "auth"
+	MiddlewareRateLimit   = "rate_limit"
+	MiddlewareLogging     = "logging"
+	MiddlewareCORS        = "cors"
+)
+
+// MiddlewareNames are the names MiddlewareConfig.Order accepts
+var MiddlewareNames = []string{
+	MiddlewareRecovery,
+	MiddlewareCompression,
+	MiddlewareAPIKey,
+	MiddlewareAuth,
+	MiddlewareRateLimit,
+	MiddlewareLogging,
+	MiddlewareCORS,
+}
+
+// ValidateMiddlewareOrder reports unknown and repeated names in order
+func ValidateMiddlewareOrder(order []string) error {
+	seen := make(map[string]bool, len(order))
+	for _, name := range order {
+		if !slices.Contains(MiddlewareNames, name) {
+			return fmt.Errorf("MIDDLEWARE_ORDER has unknown middleware %q; known are %s", name, strings.Join(MiddlewareNames, ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("MIDDLEWARE_ORDER lists %q twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// Rate limit keys, chosen per path by RateLimitKeyFor
+const (
+	RateLimitKeyIP   = "ip"
+	RateLimitKeyUser = "user"
+)
+
+// RateLimitKeyFor returns what requests to a path are counted by. A
+// "rate_limit_key" override wins; otherwise RateLimitUserPaths are counted per
+// user and everything else per client IP.
+func (m *MiddlewareConfig) RateLimitKeyFor(path string) string {
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+
+	for overridePath, settings := range m.Overrides {
+		if m.pathMatches(path, overridePath) {
+			if key, exists := settings["rate_limit_key"]; exists && (key == RateLimitKeyIP || key == RateLimitKeyUser) {
+				return key
+			}
+		}
+	}
+
+	for _, userPath := range m.RateLimitUserPaths {
+		if m.pathMatches(path, userPath) {
+			return RateLimitKeyUser
+		}
+	}
+
+	return RateLimitKeyIP
+}
+
 // IsLoggingRequired checks if logging is required for a given path
 func (m *MiddlewareConfig) IsLoggingRequired(path string) bool {
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+
 	if !m.LoggingEnabled {
 		return false
 	}
@@ -245,19 +602,9 @@ func (m *MiddlewareConfig) isWebhookPath(path string) bool {
 	return false
 }
 
-// pathMatches checks if a path matches a pattern (supports wildcards)
+// pathMatches checks if a path matches a pattern; see pathPattern for the wildcard syntax
 func (m *MiddlewareConfig) pathMatches(path, pattern string) bool {
-	if pattern == path {
-		return true
-	}
-	
-	// Handle wildcard patterns
-	if strings.HasSuffix(pattern, "/*") {
-		prefix := strings.TrimSuffix(pattern, "/*")
-		return strings.HasPrefix(path, prefix)
-	}
-	
-	return false
+	return compilePathPattern(pattern).match(path)
 }
 
 // NewConfig returns a new Config instance with default values.
@@ -276,6 +623,8 @@ func NewConfig() *Config {
 		Env:           getEnvWithLog("ENV", DefaultEnvironment),
 		ServerAddress: serverAddr,
 		ServerPort:    serverPort,
+		TLSCert:       getEnvWithLog("TLS_CERT", ""),
+		TLSKey:        getEnvWithLog("TLS_KEY", ""),
 		Version:       getEnvWithLog("APP_VERSION", DefaultVersion),
 
 		// Database settings
@@ -288,9 +637,15 @@ func NewConfig() *Config {
 		DBPath:     getEnvWithLog("DB_PATH", DefaultDBPath),
 		DBURL:      getEnvWithLog("DB_URL", ""),
 
+		// Zone of the times rendered in responses
+		ResponseTimezone: getEnvWithLog("RESPONSE_TIMEZONE", DefaultResponseTimezone),
+
 		// Security settings
-		ApiKey:    getEnvWithLog("API_KEY", DefaultAPIKey),
-		JWTSecret: getEnvWithLog("JWT_SECRET", DefaultJWTSecret),
+		ApiKey:            getEnvWithLog("API_KEY", DefaultAPIKey),
+		JWTSecret:         getEnvWithLog("JWT_SECRET", DefaultJWTSecret),
+		JWTAlgorithm:      strings.ToUpper(getEnvWithLog("JWT_ALGORITHM", DefaultJWTAlgorithm)),
+		JWTPrivateKeyFile: getEnvWithLog("JWT_PRIVATE_KEY_FILE", ""),
+		JWTPublicKeyFile:  getEnvWithLog("JWT_PUBLIC_KEY_FILE", ""),
 
 		// Email settings
 		EmailProvider:        getEnvWithLog("EMAIL_PROVIDER", DefaultEmailProvider),
@@ -302,6 +657,16 @@ func NewConfig() *Config {
 		PostmarkServerToken:  getEnvWithLog("POSTMARK_SERVER_TOKEN", ""),
 		PostmarkAccountToken: getEnvWithLog("POSTMARK_ACCOUNT_TOKEN", ""),
 
+		// Account settings
+		AccountDeletionMode: getEnvWithLog("ACCOUNT_DELETION_MODE", DefaultAccountDeletionMode),
+		RegistrationOpen:    parseBoolWithDefault("REGISTRATION_OPEN", DefaultRegistrationOpen),
+		DefaultUserRole:     getEnvWithLog("DEFAULT_USER_ROLE", DefaultUserRole),
+		PasswordResetFormat: getEnvWithLog("PASSWORD_RESET_FORMAT", DefaultPasswordResetFormat),
+
+		// Initial Owner of a fresh database
+		BootstrapAdminEmail:    getEnvWithLog("BOOTSTRAP_ADMIN_EMAIL", ""),
+		BootstrapAdminPassword: getEnvWithLog("BOOTSTRAP_ADMIN_PASSWORD", ""),
+
 		// Storage settings
 		StorageProvider:  getEnvWithLog("STORAGE_PROVIDER", DefaultStorageProvider),
 		StoragePath:      getEnvWithLog("STORAGE_PATH", DefaultStoragePath),
@@ -313,13 +678,19 @@ func NewConfig() *Config {
 		StorageRegion:    getEnvWithLog("STORAGE_REGION", DefaultStorageRegion),
 		StorageBucket:    getEnvWithLog("STORAGE_BUCKET", DefaultStorageBucket),
 		StoragePublicURL: getEnvWithLog("STORAGE_PUBLIC_URL", ""),
+		StorageCredentialsFile: getEnvWithLog("STORAGE_CREDENTIALS_FILE", ""),
 	}
 
 	// Parse complex values with proper error handling
 	parseCORSOrigins(config)
+	parseDBReadURLs(config)
+	parseTrustedProxies(config)
+	parseEmailFallbackProviders(config)
 	parseStorageExtensions(config)
+	parseStaticMounts(config)
 	parseIntegerValues(config)
 	parseBooleanValues(config)
+	parseDurationValues(config)
 	parseMiddlewareConfig(config)
 
 	return config
@@ -336,6 +707,82 @@ func parseCORSOrigins(config *Config) {
 		}
 		config.CORSAllowedOrigins = origins
 	}
+
+	groupsStr := getEnvWithLog("CORS_GROUP_ORIGINS", "")
+	if groupsStr != "" {
+		var groups map[string][]string
+		if err := json.Unmarshal([]byte(groupsStr), &groups); err != nil {
+			logConfigError("invalid CORS_GROUP_ORIGINS JSON: %s. Using CORS_ALLOWED_ORIGINS everywhere", groupsStr)
+			return
+		}
+		for prefix, origins := range groups {
+			for i, origin := range origins {
+				origins[i] = strings.TrimSpace(origin)
+			}
+			groups[prefix] = origins
+		}
+		config.CORSGroupOrigins = groups
+	}
+}
+
+// parseDBReadURLs parses the comma-separated read replica DSNs
+func parseDBReadURLs(config *Config) {
+	urlsStr := getEnvWithLog("DB_READ_URLS", "")
+	for _, url := range strings.Split(urlsStr, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			config.DBReadURLs = append(config.DBReadURLs, url)
+		}
+	}
+}
+
+// parseTrustedProxies parses the comma-separated trusted proxy CIDRs
+func parseTrustedProxies(config *Config) {
+	proxiesStr := getEnvWithLog("TRUSTED_PROXIES", "")
+	for _, proxy := range strings.Split(proxiesStr, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			config.TrustedProxies = append(config.TrustedProxies, proxy)
+		}
+	}
+}
+
+// parseEmailFallbackProviders parses the comma-separated, ordered fallback email providers
+func parseEmailFallbackProviders(config *Config) {
+	providersStr := getEnvWithLog("EMAIL_FALLBACK_PROVIDER", "")
+	for _, provider := range strings.Split(providersStr, ",") {
+		if provider = strings.TrimSpace(provider); provider != "" && provider != config.EmailProvider {
+			config.EmailFallbackProviders = append(config.EmailFallbackProviders, provider)
+		}
+	}
+}
+
+// parseStaticMounts parses comma-separated prefix=directory pairs. A prefix
+// listed twice keeps its last directory; malformed pairs are skipped.
+func parseStaticMounts(config *Config) {
+	mountsStr := getEnvWithLog("STATIC_MOUNTS", DefaultStaticMounts)
+	for _, pair := range strings.Split(mountsStr, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		prefix, dir, ok := strings.Cut(pair, "=")
+		prefix = "/" + strings.Trim(strings.TrimSpace(prefix), "/")
+		dir = strings.TrimSpace(dir)
+		if !ok || prefix == "/" || dir == "" {
+			logConfigError("Invalid STATIC_MOUNTS entry: %s. Expected /prefix=directory", pair)
+			continue
+		}
+
+		mount := StaticMount{Prefix: prefix, Dir: dir}
+		replaced := false
+		for i := range config.StaticMounts {
+			if config.StaticMounts[i].Prefix == prefix {
+				config.StaticMounts[i] = mount
+				replaced = true
+			}
+		}
+		if !replaced {
+			config.StaticMounts = append(config.StaticMounts, mount)
+		}
+	}
 }
 
 // parseStorageExtensions parses allowed storage extensions
@@ -351,31 +798,127 @@ func parseStorageExtensions(config *Config) {
 	}
 }
 
+// DefaultDBPool returns the connection pool defaults for a driver. SQLite
+// serializes writes to a local file, so a few connections suffice and never
+// need replacing; servers get a larger pool whose connections are recycled
+// before MySQL's wait_timeout or a proxy closes them.
+func DefaultDBPool(driver string) (maxOpen, maxIdle int, maxLifetime time.Duration) {
+	if driver == "sqlite" {
+		return 4, 2, 0
+	}
+	return DefaultDBMaxOpenConns, DefaultDBMaxIdleConns, DefaultDBConnMaxLifetime
+}
+
 // parseIntegerValues parses all integer configuration values
 func parseIntegerValues(config *Config) {
 	// SMTP Port
 	config.SMTPPort = parseIntWithDefault("SMTP_PORT", DefaultSMTPPort)
 
+	// Email retry attempts
+	config.EmailRetryAttempts = parseIntWithDefault("EMAIL_RETRY_ATTEMPTS", DefaultEmailRetryAttempts)
+
+	// Password reset code length
+	config.PasswordResetOTPLength = parseIntWithDefault("PASSWORD_RESET_OTP_LENGTH", DefaultPasswordResetOTPLength)
+
+	// Database connection pool, defaulting per driver
+	maxOpen, maxIdle, _ := DefaultDBPool(config.DBDriver)
+	config.DBMaxOpenConns = parseIntWithDefault("DB_MAX_OPEN_CONNS", maxOpen)
+	if config.DBMaxOpenConns > 0 && maxIdle > config.DBMaxOpenConns {
+		maxIdle = config.DBMaxOpenConns // A smaller configured pool lowers the idle default with it
+	}
+	config.DBMaxIdleConns = parseIntWithDefault("DB_MAX_IDLE_CONNS", maxIdle)
+
+	// Largest request headers
+	config.MaxHeaderBytes = parseIntWithDefault("MAX_HEADER_BYTES", DefaultMaxHeaderBytes)
+
+	// JSON request body limit
+	config.JSONMaxBodySize = parseInt64WithDefault("JSON_MAX_BODY_SIZE", DefaultJSONMaxBodySize)
+
 	// Storage Max Size
 	config.StorageMaxSize = parseInt64WithDefault("STORAGE_MAX_SIZE", DefaultStorageMaxSize)
+
+	// Pagination
+	config.DefaultPageSize = parseIntWithDefault("DEFAULT_PAGE_SIZE", DefaultPageSize)
+	config.MaxPageSize = parseIntWithDefault("MAX_PAGE_SIZE", DefaultMaxPageSize)
 }
 
 // parseBooleanValues parses all boolean configuration values
 func parseBooleanValues(config *Config) {
 	// WebSocket enabled
 	config.WebSocketEnabled = parseBoolWithDefault("WS_ENABLED", DefaultWebSocketEnabled)
+	config.WSCompression = parseBoolWithDefault("WS_COMPRESSION", DefaultWSCompression)
 
 	// Swagger enabled
 	config.SwaggerEnabled = parseBoolWithDefault("SWAGGER_ENABLED", DefaultSwaggerEnabled)
+
+	// Pagination links
+	config.PaginationLinks = parseBoolWithDefault("PAGINATION_LINKS", DefaultPaginationLinks)
+
+	// Event outbox enabled
+	config.EventOutboxEnabled = parseBoolWithDefault("EVENT_OUTBOX_ENABLED", DefaultEventOutboxEnabled)
+
+	// Strict JSON binding
+	config.JSONStrict = parseBoolWithDefault("JSON_STRICT", DefaultJSONStrict)
+}
+
+// parseDurationValues parses all duration configuration values
+func parseDurationValues(config *Config) {
+	// Slow query threshold (e.g. "200ms"); zero disables slow query logging
+	config.SlowQueryThreshold = parseDurationWithDefault("SLOW_QUERY_THRESHOLD", DefaultSlowQueryThreshold)
+
+	// How long a pooled database connection is reused before being replaced
+	_, _, lifetime := DefaultDBPool(config.DBDriver)
+	config.DBConnMaxLifetime = parseDurationWithDefault("DB_CONN_MAX_LIFETIME", lifetime)
+
+	// Email retry backoff
+	config.EmailRetryBaseDelay = parseDurationWithDefault("EMAIL_RETRY_BASE_DELAY", DefaultEmailRetryBaseDelay)
+	config.EmailRetryMaxDelay = parseDurationWithDefault("EMAIL_RETRY_MAX_DELAY", DefaultEmailRetryMaxDelay)
+
+	// Password reset token and code lifetimes
+	config.PasswordResetExpiry = parseDurationWithDefault("PASSWORD_RESET_EXPIRY", DefaultPasswordResetExpiry)
+	config.PasswordResetOTPExpiry = parseDurationWithDefault("PASSWORD_RESET_OTP_EXPIRY", DefaultPasswordResetOTPExpiry)
+	config.ImpersonationTTL = parseDurationWithDefault("IMPERSONATION_TTL", DefaultImpersonationTTL)
+	config.InviteTTL = parseDurationWithDefault("INVITE_TTL", DefaultInviteTTL)
+	config.TokenCleanupInterval = parseDurationWithDefault("TOKEN_CLEANUP_INTERVAL", DefaultTokenCleanupInterval)
+	config.WebhookTimeout = parseDurationWithDefault("WEBHOOK_TIMEOUT", DefaultWebhookTimeout)
+	config.WebhookMaxAttempts = parseIntWithDefault("WEBHOOK_MAX_ATTEMPTS", DefaultWebhookMaxAttempts)
+
+	// How long WebSocket messages are coalesced into one frame for batching clients
+	config.WSBatchWindow = parseDurationWithDefault("WS_BATCH_WINDOW", DefaultWSBatchWindow)
+
+	// HTTP server timeouts, protecting against slow clients
+	config.ReadHeaderTimeout = parseDurationWithDefault("READ_HEADER_TIMEOUT", DefaultReadHeaderTimeout)
+	config.ReadTimeout = parseDurationWithDefault("READ_TIMEOUT", DefaultReadTimeout)
+	config.WriteTimeout = parseDurationWithDefault("WRITE_TIMEOUT", DefaultWriteTimeout)
+	config.IdleTimeout = parseDurationWithDefault("IDLE_TIMEOUT", DefaultIdleTimeout)
+
+	// How long browsers may cache a CORS preflight response
+	config.CORSMaxAge = parseDurationWithDefault("CORS_MAX_AGE", DefaultCORSMaxAge)
 }
 
 // parseMiddlewareConfig parses middleware configuration from environment variables
 func parseMiddlewareConfig(config *Config) {
+	middleware, err := readMiddlewareConfig()
+	if err != nil {
+		logConfigError("%v. Using empty overrides", err)
+	}
+	config.Middleware = *middleware
+}
+
+// readMiddlewareConfig reads middleware settings from the environment.
+// Invalid MIDDLEWARE_OVERRIDES JSON or override values are reported as an
+// error alongside a config that has empty overrides, so callers can choose to keep or discard it.
+func readMiddlewareConfig() (*MiddlewareConfig, error) {
+	var parseErr error
+
 	// Parse middleware overrides JSON if provided
 	overridesStr := getEnvWithLog("MIDDLEWARE_OVERRIDES", "{}")
 	var overrides map[string]map[string]string
 	if err := json.Unmarshal([]byte(overridesStr), &overrides); err != nil {
-		logConfigError("Invalid MIDDLEWARE_OVERRIDES JSON: %s. Using empty overrides", overridesStr)
+		parseErr = fmt.Errorf("invalid MIDDLEWARE_OVERRIDES JSON: %s", overridesStr)
+		overrides = make(map[string]map[string]string)
+	} else if err := validateOverrides(overrides); err != nil {
+		parseErr = err
 		overrides = make(map[string]map[string]string)
 	}
 	
@@ -389,20 +932,25 @@ func parseMiddlewareConfig(config *Config) {
 		}
 	}
 	
-	config.Middleware = MiddlewareConfig{
+	return &MiddlewareConfig{
 		// Global middleware settings
 		APIKeyEnabled:     parseBoolWithDefault("MIDDLEWARE_API_KEY_ENABLED", true),
-		APIKeySkipPaths:   parsePathList("MIDDLEWARE_API_KEY_SKIP_PATHS", "/health,/,/docs,/swagger"),
+		APIKeySkipPaths:   parsePathList("MIDDLEWARE_API_KEY_SKIP_PATHS", "/health,/,/docs,/swagger,/.well-known/jwks.json"),
 		AuthEnabled:       parseBoolWithDefault("MIDDLEWARE_AUTH_ENABLED", false),
 		AuthSkipPaths:     parsePathList("MIDDLEWARE_AUTH_SKIP_PATHS", "/api/auth/login,/api/auth/register,/api/auth/forgot-password"),
 		RateLimitEnabled:  parseBoolWithDefault("MIDDLEWARE_RATE_LIMIT_ENABLED", true),
 		RateLimitRequests: parseIntWithDefault("MIDDLEWARE_RATE_LIMIT_REQUESTS", 60),
 		RateLimitWindow:   getEnvWithLog("MIDDLEWARE_RATE_LIMIT_WINDOW", "1m"),
 		RateLimitSkipPaths: parsePathList("MIDDLEWARE_RATE_LIMIT_SKIP_PATHS", "/health,/"),
+		RateLimitUserPaths: parsePathList("MIDDLEWARE_RATE_LIMIT_USER_PATHS", "/api/games/*/progress,/api/games/*/stats,/api/games/*/stats/increment,/api/games/*/achievements/*"),
 		LoggingEnabled:    parseBoolWithDefault("MIDDLEWARE_LOGGING_ENABLED", true),
 		LoggingSkipPaths:  parsePathList("MIDDLEWARE_LOGGING_SKIP_PATHS", ""),
 		RecoveryEnabled:   parseBoolWithDefault("MIDDLEWARE_RECOVERY_ENABLED", true),
 		CORSEnabled:       parseBoolWithDefault("MIDDLEWARE_CORS_ENABLED", true),
+		CompressionEnabled: parseBoolWithDefault("MIDDLEWARE_COMPRESSION_ENABLED", true),
+		CompressionMinSize: parseIntWithDefault("MIDDLEWARE_COMPRESSION_MIN_SIZE", 1024),
+		CompressionLevel:   parseIntWithDefault("MIDDLEWARE_COMPRESSION_LEVEL", -1),
+		Order:              parsePathList("MIDDLEWARE_ORDER", DefaultMiddlewareOrder),
 		
 		// Webhook-specific settings
 		WebhookPaths:              webhookPaths,
@@ -414,7 +962,31 @@ func parseMiddlewareConfig(config *Config) {
 		
 		// Per-endpoint overrides
 		Overrides: overrides,
+	}, parseErr
+}
+
+// Reload re-reads the middleware environment variables and applies them to the
+// live Middleware config. The current settings are kept when the new ones are
+// invalid. See MiddlewareConfig for the fields that take effect without a restart.
+func (c *Config) Reload() error {
+	middleware, err := readMiddlewareConfig()
+	if err != nil {
+		return err
 	}
+
+	middlewareMu.Lock()
+	c.Middleware = *middleware
+	middlewareMu.Unlock()
+
+	return nil
+}
+
+// MiddlewareSnapshot returns a copy of the live middleware settings
+func (c *Config) MiddlewareSnapshot() MiddlewareConfig {
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+
+	return c.Middleware
 }
 
 // parsePathList parses a comma-separated list of paths
@@ -470,6 +1042,20 @@ func parseBoolWithDefault(key string, defaultValue bool) bool {
 	return value
 }
 
+// parseDurationWithDefault parses a duration environment variable with default fallback
+func parseDurationWithDefault(key string, defaultValue time.Duration) time.Duration {
+	valueStr := getEnvWithLog(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		logConfigError("Invalid %s value: %s. Using default: %s", key, valueStr, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
 // normalizePort ensures port starts with ":"
 func normalizePort(port string) string {
 	if port != "" && port[0] != ':' {
@@ -501,6 +1087,7 @@ func (c *Config) GetStorageConfig() map[string]any {
 		"region":      c.StorageRegion,
 		"bucket":      c.StorageBucket,
 		"public_url":  c.StoragePublicURL,
+		"credentials_file": c.StorageCredentialsFile,
 		"base_url":    c.StorageBaseURL,
 		"max_size":    c.StorageMaxSize,
 		"allowed_ext": c.StorageAllowedExt,
@@ -509,13 +1096,30 @@ func (c *Config) GetStorageConfig() map[string]any {
 	}
 }
 
-// getEnvWithLog returns the value of an environment variable with a fallback default value
+// loggedConfigKeys holds the keys getEnvWithLog printed, so a configuration
+// loaded again is not printed again
+var loggedConfigKeys sync.Map
+
+// getEnvWithLog returns the value of an environment variable with a fallback
+// default value. With CONFIG_LOG_VALUES=true each key is printed the first
+// time it is read. Values of sensitive keys are masked, and secrets embedded
+// in other values, such as the password of a URL, are redacted.
 func getEnvWithLog(key, fallback string) string {
 	value, exists := os.LookupEnv(key)
-	if exists {
-		return value
+	if !exists {
+		value = fallback
 	}
-	return fallback
+
+	if logValues, _ := strconv.ParseBool(os.Getenv("CONFIG_LOG_VALUES")); logValues {
+		if _, logged := loggedConfigKeys.LoadOrStore(key, true); !logged {
+			source := "env"
+			if !exists {
+				source = "default"
+			}
+			fmt.Printf("[CONFIG] %s=%s (%s)\n", key, logger.RedactText(logger.RedactValue(key, value)), source)
+		}
+	}
+	return value
 }
 
 // Validation methods for production use
@@ -538,6 +1142,17 @@ func (c *Config) Validate() []error {
 		errors = append(errors, fmt.Errorf("DB_PATH is required for SQLite driver"))
 	}
 
+	if _, err := time.LoadLocation(c.ResponseTimezone); err != nil || c.ResponseTimezone == "" {
+		errors = append(errors, fmt.Errorf("RESPONSE_TIMEZONE must be an IANA time zone such as UTC or Europe/Berlin, got %q", c.ResponseTimezone))
+	}
+
+	if c.DBMaxOpenConns < 0 || c.DBMaxIdleConns < 0 || c.DBConnMaxLifetime < 0 {
+		errors = append(errors, fmt.Errorf("DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS and DB_CONN_MAX_LIFETIME must not be negative"))
+	}
+	if c.DBMaxOpenConns > 0 && c.DBMaxIdleConns > c.DBMaxOpenConns {
+		errors = append(errors, fmt.Errorf("DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)", c.DBMaxIdleConns, c.DBMaxOpenConns))
+	}
+
 	// Validate storage configuration
 	if c.StorageProvider == "s3" || c.StorageProvider == "r2" {
 		if c.StorageAPIKey == "" {
@@ -550,15 +1165,165 @@ func (c *Config) Validate() []error {
 			errors = append(errors, fmt.Errorf("STORAGE_BUCKET is required for %s provider", c.StorageProvider))
 		}
 	}
+	if c.StorageProvider == "gcs" {
+		if c.StorageCredentialsFile == "" {
+			errors = append(errors, fmt.Errorf("STORAGE_CREDENTIALS_FILE is required for gcs provider"))
+		}
+		if c.StorageBucket == "" {
+			errors = append(errors, fmt.Errorf("STORAGE_BUCKET is required for gcs provider"))
+		}
+	}
+
+	// Validate trusted proxies
+	for _, proxy := range c.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				errors = append(errors, fmt.Errorf("TRUSTED_PROXIES has invalid entry %q: must be an IP or CIDR", proxy))
+			}
+		}
+	}
+
+	// Validate CORS configuration
+	for prefix := range c.CORSGroupOrigins {
+		if !strings.HasPrefix(prefix, "/") {
+			errors = append(errors, fmt.Errorf("CORS_GROUP_ORIGINS prefix %q must start with /", prefix))
+		}
+	}
+	if c.CORSMaxAge < 0 {
+		errors = append(errors, fmt.Errorf("CORS_MAX_AGE must not be negative"))
+	}
+
+	// Validate server limits
+	if c.ReadHeaderTimeout < 0 || c.ReadTimeout < 0 || c.WriteTimeout < 0 || c.IdleTimeout < 0 {
+		errors = append(errors, fmt.Errorf("READ_HEADER_TIMEOUT, READ_TIMEOUT, WRITE_TIMEOUT and IDLE_TIMEOUT must not be negative"))
+	}
+	if c.MaxHeaderBytes <= 0 {
+		errors = append(errors, fmt.Errorf("MAX_HEADER_BYTES must be positive"))
+	}
+
+	// Validate request body configuration
+	if c.JSONMaxBodySize <= 0 {
+		errors = append(errors, fmt.Errorf("JSON_MAX_BODY_SIZE must be positive"))
+	}
+
+	// Validate compression configuration
+	if c.Middleware.CompressionEnabled {
+		if c.Middleware.CompressionLevel < -1 || c.Middleware.CompressionLevel > 9 {
+			errors = append(errors, fmt.Errorf("MIDDLEWARE_COMPRESSION_LEVEL must be -1 or between 0 and 9"))
+		}
+		if c.Middleware.CompressionMinSize < 0 {
+			errors = append(errors, fmt.Errorf("MIDDLEWARE_COMPRESSION_MIN_SIZE must not be negative"))
+		}
+	}
+
+	// Validate middleware order
+	if err := ValidateMiddlewareOrder(c.Middleware.Order); err != nil {
+		errors = append(errors, err)
+	}
 
 	// Validate email configuration
 	if c.EmailProvider == "smtp" && c.SMTPHost == "" {
 		errors = append(errors, fmt.Errorf("SMTP_HOST is required for SMTP email provider"))
 	}
+	for _, provider := range c.EmailFallbackProviders {
+		switch provider {
+		case "smtp":
+			if c.SMTPHost == "" {
+				errors = append(errors, fmt.Errorf("SMTP_HOST is required for the smtp fallback email provider"))
+			}
+		case "sendgrid":
+			if c.SendGridAPIKey == "" {
+				errors = append(errors, fmt.Errorf("SENDGRID_API_KEY is required for the sendgrid fallback email provider"))
+			}
+		case "postmark":
+			if c.PostmarkServerToken == "" {
+				errors = append(errors, fmt.Errorf("POSTMARK_SERVER_TOKEN is required for the postmark fallback email provider"))
+			}
+		case "default":
+		default:
+			errors = append(errors, fmt.Errorf("EMAIL_FALLBACK_PROVIDER has unsupported provider: %s", provider))
+		}
+	}
+
+	// Validate account configuration
+	if c.AccountDeletionMode != "anonymize" && c.AccountDeletionMode != "delete" {
+		errors = append(errors, fmt.Errorf("ACCOUNT_DELETION_MODE must be anonymize or delete"))
+	}
+	if c.PasswordResetFormat != "token" && c.PasswordResetFormat != "otp" {
+		errors = append(errors, fmt.Errorf("PASSWORD_RESET_FORMAT must be token or otp"))
+	}
+	if c.PasswordResetExpiry <= 0 || c.PasswordResetOTPExpiry <= 0 {
+		errors = append(errors, fmt.Errorf("PASSWORD_RESET_EXPIRY and PASSWORD_RESET_OTP_EXPIRY must be positive"))
+	}
+	if c.PasswordResetOTPLength < 4 || c.PasswordResetOTPLength > 10 {
+		errors = append(errors, fmt.Errorf("PASSWORD_RESET_OTP_LENGTH must be between 4 and 10"))
+	}
+	if c.ImpersonationTTL <= 0 || c.ImpersonationTTL > MaxImpersonationTTL {
+		errors = append(errors, fmt.Errorf("IMPERSONATION_TTL must be positive and at most %s", MaxImpersonationTTL))
+	}
+	if c.InviteTTL <= 0 {
+		errors = append(errors, fmt.Errorf("INVITE_TTL must be positive"))
+	}
+	if strings.TrimSpace(c.DefaultUserRole) == "" {
+		errors = append(errors, fmt.Errorf("DEFAULT_USER_ROLE must not be empty"))
+	}
+	if (c.BootstrapAdminEmail == "") != (c.BootstrapAdminPassword == "") {
+		errors = append(errors, fmt.Errorf("BOOTSTRAP_ADMIN_EMAIL and BOOTSTRAP_ADMIN_PASSWORD must be set together"))
+	} else if c.BootstrapAdminPassword != "" && len(c.BootstrapAdminPassword) < 8 {
+		errors = append(errors, fmt.Errorf("BOOTSTRAP_ADMIN_PASSWORD must be at least 8 characters"))
+	}
+	if c.TokenCleanupInterval < 0 {
+		errors = append(errors, fmt.Errorf("TOKEN_CLEANUP_INTERVAL must not be negative"))
+	}
+	if c.WebhookTimeout <= 0 {
+		errors = append(errors, fmt.Errorf("WEBHOOK_TIMEOUT must be positive"))
+	}
+	if c.WebhookMaxAttempts < 1 {
+		errors = append(errors, fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be at least 1"))
+	}
+
+	// Validate pagination configuration
+	if c.DefaultPageSize < 1 {
+		errors = append(errors, fmt.Errorf("DEFAULT_PAGE_SIZE must be at least 1"))
+	}
+	if c.MaxPageSize < 1 {
+		errors = append(errors, fmt.Errorf("MAX_PAGE_SIZE must be at least 1"))
+	}
+	if c.DefaultPageSize > c.MaxPageSize {
+		errors = append(errors, fmt.Errorf("DEFAULT_PAGE_SIZE (%d) must not exceed MAX_PAGE_SIZE (%d)", c.DefaultPageSize, c.MaxPageSize))
+	}
+
+	// Validate JWT signing
+	switch c.JWTAlgorithm {
+	case JWTAlgorithmHS256:
+	case JWTAlgorithmRS256:
+		if c.JWTPrivateKeyFile == "" {
+			errors = append(errors, fmt.Errorf("JWT_PRIVATE_KEY_FILE is required for RS256"))
+		}
+		for _, file := range []string{c.JWTPrivateKeyFile, c.JWTPublicKeyFile} {
+			if file == "" {
+				continue
+			}
+			if _, err := os.Stat(file); err != nil {
+				errors = append(errors, fmt.Errorf("JWT key file %s cannot be read: %w", file, err))
+			}
+		}
+	default:
+		errors = append(errors, fmt.Errorf("JWT_ALGORITHM must be %s or %s", JWTAlgorithmHS256, JWTAlgorithmRS256))
+	}
+
+	// Validate TLS serving
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		errors = append(errors, fmt.Errorf("TLS_CERT and TLS_KEY must be set together"))
+	} else if c.TLSEnabled() {
+		if _, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey); err != nil {
+			errors = append(errors, fmt.Errorf("TLS_CERT and TLS_KEY cannot be loaded: %w", err))
+		}
+	}
 
 	// Security validations for production
 	if c.Env == "production" {
-		if c.JWTSecret == DefaultJWTSecret {
+		if c.JWTAlgorithm == JWTAlgorithmHS256 && c.JWTSecret == DefaultJWTSecret {
 			errors = append(errors, fmt.Errorf("JWT_SECRET must be changed from default value in production"))
 		}
 		if c.ApiKey == DefaultAPIKey {
@@ -574,6 +1339,11 @@ func (c *Config) IsProduction() bool {
 	return c.Env == "production"
 }
 
+// TLSEnabled reports whether the server is served over HTTPS
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCert != "" && c.TLSKey != ""
+}
+
 // IsDevelopment returns true if the environment is development/debug
 func (c *Config) IsDevelopment() bool {
 	return c.Env == "debug" || c.Env == "development"
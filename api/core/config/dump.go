@@ -0,0 +1,71 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"base/core/logger"
+)
+
+// Dump returns the effective configuration keyed like its JSON encoding,
+// with the middleware settings as currently applied. Strings held by a
+// secret-like field or map key are masked, durations are written as text.
+func (c *Config) Dump() map[string]any {
+	cfg := *c
+	cfg.Middleware = c.MiddlewareSnapshot()
+	return dumpValue("", reflect.ValueOf(cfg)).(map[string]any)
+}
+
+// dumpValue converts v for Dump; key is the name v is stored under
+func dumpValue(key string, v reflect.Value) any {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := make(map[string]any, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := field.Name
+			if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+			fields[name] = dumpValue(name, v.Field(i))
+		}
+		return fields
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		entries := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entryKey := iter.Key().String()
+			entries[entryKey] = dumpValue(entryKey, iter.Value())
+		}
+		return entries
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = dumpValue(key, v.Index(i))
+		}
+		return items
+	case reflect.String:
+		// Skip lists hold URL paths, never secrets
+		if logger.IsSensitiveKey(key) && !strings.HasSuffix(strings.ToLower(key), "paths") {
+			return logger.Mask(v.String())
+		}
+		return v.String()
+	}
+	return v.Interface()
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/logger"
+)
+
+func TestDumpMasksSecrets(t *testing.T) {
+	cfg := &Config{
+		DBHost:           "db.internal",
+		DBPassword:       "db-password-value",
+		DBURL:            "postgres://app:dburl-password@db/app",
+		JWTSecret:        "jwt-secret-value",
+		ApiKey:           "api-key-value",
+		StorageAPISecret: "storage-secret-value",
+		ImpersonationTTL: 15 * time.Minute,
+		CORSGroupOrigins: map[string][]string{"/api/public": {"https://example.com"}},
+		Middleware: MiddlewareConfig{
+			APIKeyEnabled:     true,
+			APIKeySkipPaths:   []string{"/health"},
+			RateLimitRequests: 100,
+		},
+	}
+
+	dump := cfg.Dump()
+	encoded, err := json.Marshal(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"db-password-value", "dburl-password", "jwt-secret-value", "api-key-value", "storage-secret-value"} {
+		if strings.Contains(string(encoded), secret) {
+			t.Errorf("the dump contains %q", secret)
+		}
+	}
+	if dump["JWTSecret"] != logger.Redacted || dump["storage_api_secret"] != logger.Redacted {
+		t.Errorf("JWTSecret %v and storage_api_secret %v, want both masked", dump["JWTSecret"], dump["storage_api_secret"])
+	}
+
+	// Everything else is shown as configured
+	if dump["DBHost"] != "db.internal" || dump["ImpersonationTTL"] != "15m0s" {
+		t.Errorf("DBHost %v and ImpersonationTTL %v", dump["DBHost"], dump["ImpersonationTTL"])
+	}
+	middleware := dump["middleware"].(map[string]any)
+	if middleware["api_key_enabled"] != true || middleware["rate_limit_requests"] != 100 {
+		t.Errorf("middleware = %v", middleware)
+	}
+	if paths := middleware["api_key_skip_paths"].([]any); len(paths) != 1 || paths[0] != "/health" {
+		t.Errorf("skip paths %v were masked", paths)
+	}
+	if origins := dump["CORSGroupOrigins"].(map[string]any)["/api/public"].([]any); origins[0] != "https://example.com" {
+		t.Errorf("group origins = %v", origins)
+	}
+}
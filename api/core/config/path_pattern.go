@@ -0,0 +1,94 @@
+package config
+
+import (
+	"strings"
+	"sync"
+)
+
+// pathPattern is a compiled middleware path pattern.
+//
+// Patterns are matched segment by segment:
+//   - "*" matches exactly one segment: /api/games/*/progress
+//   - "**" matches zero or more segments: /api/**/export
+//   - a trailing "/*" keeps its original meaning of "everything under this
+//     prefix" and is treated as "/**", so existing skip lists behave the same
+//
+// Any other segment must match literally.
+type pathPattern struct {
+	exact    string
+	segments []string
+	wildcard bool
+}
+
+// compiledPatterns caches patterns so each one is only parsed once
+var compiledPatterns sync.Map
+
+// compilePathPattern returns the cached compiled form of pattern
+func compilePathPattern(pattern string) *pathPattern {
+	if cached, ok := compiledPatterns.Load(pattern); ok {
+		return cached.(*pathPattern)
+	}
+
+	compiled := &pathPattern{exact: pattern}
+	if strings.Contains(pattern, "*") {
+		normalized := pattern
+		if strings.HasSuffix(normalized, "/*") {
+			normalized = strings.TrimSuffix(normalized, "*") + "**"
+		}
+		compiled.segments = splitPath(normalized)
+		compiled.wildcard = true
+	}
+
+	actual, _ := compiledPatterns.LoadOrStore(pattern, compiled)
+	return actual.(*pathPattern)
+}
+
+// match reports whether path matches the pattern
+func (p *pathPattern) match(path string) bool {
+	if path == p.exact {
+		return true
+	}
+	if !p.wildcard {
+		return false
+	}
+	return matchSegments(p.segments, splitPath(path))
+}
+
+// matchSegments matches path segments against pattern segments, backtracking on "**"
+func matchSegments(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case "**":
+			// Collapse consecutive "**" and try every possible split point
+			rest := pattern[1:]
+			for len(rest) > 0 && rest[0] == "**" {
+				rest = rest[1:]
+			}
+			if len(rest) == 0 {
+				return true
+			}
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(rest, path[i:]) {
+					return true
+				}
+			}
+			return false
+		case "*":
+			if len(path) == 0 {
+				return false
+			}
+		default:
+			if len(path) == 0 || pattern[0] != path[0] {
+				return false
+			}
+		}
+		pattern = pattern[1:]
+		path = path[1:]
+	}
+	return len(path) == 0
+}
+
+// splitPath splits a URL path into its non-empty segments
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+}
@@ -0,0 +1,83 @@
+package config
+
+import "testing"
+
+func TestPathPatternMatrix(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		// Exact patterns
+		{"/health", "/health", true},
+		{"/health", "/health/live", false},
+		{"/", "/", true},
+		{"/", "/api", false},
+
+		// "*" is exactly one segment
+		{"/api/games/*/progress", "/api/games/chess/progress", true},
+		{"/api/games/*/progress", "/api/games/progress", false},
+		{"/api/games/*/progress", "/api/games/chess/extra/progress", false},
+		{"/api/games/*/progress", "/api/games/chess/progress/1", false},
+		{"/api/*/users/*", "/api/v1/users/7", true},
+
+		// "**" is zero or more segments
+		{"/api/**/export", "/api/export", true},
+		{"/api/**/export", "/api/reports/export", true},
+		{"/api/**/export", "/api/reports/2024/export", true},
+		{"/api/**/export", "/api/reports/2024/export/csv", false},
+		{"/api/**/**/export", "/api/reports/export", true},
+		{"/**", "/anything/at/all", true},
+
+		// A trailing "/*" means everything under the prefix
+		{"/api/webhooks/*", "/api/webhooks/stripe", true},
+		{"/api/webhooks/*", "/api/webhooks/stripe/events", true},
+		{"/api/webhooks/*", "/api/webhooks", true},
+		{"/api/webhooks/*", "/api/webhooksfoo", false},
+		{"/docs/*", "/documents", false},
+
+		// Repeated and trailing slashes are ignored by wildcard patterns
+		{"/api/games/*/progress", "/api/games//chess/progress/", true},
+	}
+	for _, tt := range tests {
+		if got := compilePathPattern(tt.pattern).match(tt.path); got != tt.want {
+			t.Errorf("%q matching %q = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPathPatternsAreCompiledOnce(t *testing.T) {
+	if compilePathPattern("/api/**/cached") != compilePathPattern("/api/**/cached") {
+		t.Error("a pattern was compiled twice")
+	}
+}
+
+func TestMiddlewareChecksUseWildcards(t *testing.T) {
+	m := &MiddlewareConfig{
+		APIKeyEnabled:   true,
+		APIKeySkipPaths: []string{"/api/public/**"},
+		AuthEnabled:     true,
+		AuthSkipPaths:   []string{"/api/games/*/leaderboard"},
+		WebhookPaths:    []string{"/hooks/*/incoming"},
+		Overrides:       map[string]map[string]string{"/api/games/*/progress": {"auth": "disabled"}},
+	}
+
+	if m.IsAPIKeyRequired("/api/public/docs/v1") {
+		t.Error("API key required under a ** skip path")
+	}
+	if !m.IsAPIKeyRequired("/api/private") {
+		t.Error("API key not required outside the skip paths")
+	}
+	if m.IsAuthRequired("/api/games/chess/leaderboard") {
+		t.Error("auth required on a * skip path")
+	}
+	if !m.IsAuthRequired("/api/games/chess/leaderboard/weekly") {
+		t.Error("auth skipped for a path deeper than the * skip path")
+	}
+	if m.IsAuthRequired("/api/games/chess/progress") {
+		t.Error("auth required despite a * override disabling it")
+	}
+	if !m.isWebhookPath("/hooks/stripe/incoming") || m.isWebhookPath("/hooks/stripe/outgoing") {
+		t.Error("webhook paths do not match by segment")
+	}
+}
@@ -8,8 +8,12 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
+// ReadReplicas names the dbresolver configuration holding the read replicas
+const ReadReplicas = "read_replicas"
+
 var DB *gorm.DB
 
 type Database struct {
@@ -24,13 +28,13 @@ func InitDB(cfg *config.Config) (*Database, error) {
 		DB, err = gorm.Open(sqlite.Open(cfg.DBPath), &gorm.Config{})
 	case "mysql":
 		if cfg.DBURL == "" {
-			cfg.DBURL = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			cfg.DBURL = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=UTC",
 				cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBName)
 		}
 		DB, err = gorm.Open(mysql.Open(cfg.DBURL), &gorm.Config{})
 	case "postgres":
 		if cfg.DBURL == "" {
-			cfg.DBURL = fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=disable",
+			cfg.DBURL = fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=disable TimeZone=UTC",
 				cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBName, cfg.DBPassword)
 		}
 		DB, err = gorm.Open(postgres.Open(cfg.DBURL), &gorm.Config{})
@@ -42,5 +46,75 @@ func InitDB(cfg *config.Config) (*Database, error) {
 		return nil, fmt.Errorf("failed to connect to the database: %v", err)
 	}
 
+	if err := UseTimezone(DB, cfg.ResponseTimezone); err != nil {
+		return nil, fmt.Errorf("failed to configure the response timezone: %v", err)
+	}
+
+	if err := ConfigurePool(DB, cfg); err != nil {
+		return nil, fmt.Errorf("failed to configure the connection pool: %v", err)
+	}
+
+	if err := registerReadReplicas(DB, cfg); err != nil {
+		return nil, fmt.Errorf("failed to connect to the read replicas: %v", err)
+	}
+
 	return &Database{DB: DB}, nil
 }
+
+// registerReadReplicas opens the replica connections under the ReadReplicas
+// resolver. Queries only use them when opted in through Reader, so reads that
+// must see a write made moments earlier keep going to the primary.
+// Each replica gets a pool of its own with the primary's settings.
+func registerReadReplicas(db *gorm.DB, cfg *config.Config) error {
+	if len(cfg.DBReadURLs) == 0 {
+		return nil
+	}
+
+	replicas := make([]gorm.Dialector, 0, len(cfg.DBReadURLs))
+	for _, dsn := range cfg.DBReadURLs {
+		switch cfg.DBDriver {
+		case "sqlite":
+			replicas = append(replicas, sqlite.Open(dsn))
+		case "mysql":
+			replicas = append(replicas, mysql.Open(dsn))
+		case "postgres":
+			replicas = append(replicas, postgres.Open(dsn))
+		default:
+			return fmt.Errorf("unsupported database driver: %s", cfg.DBDriver)
+		}
+	}
+
+	return db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}, ReadReplicas).
+		SetMaxOpenConns(cfg.DBMaxOpenConns).
+		SetMaxIdleConns(cfg.DBMaxIdleConns).
+		SetConnMaxLifetime(cfg.DBConnMaxLifetime))
+}
+
+// ConfigurePool applies DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS and
+// DB_CONN_MAX_LIFETIME to the connection pool of db
+func ConfigurePool(db *gorm.DB, cfg *config.Config) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	return nil
+}
+
+// Reader returns a session whose queries run on a read replica, falling back to
+// the primary when no replicas are configured. Writes made through it still go
+// to the primary.
+func (d *Database) Reader() *gorm.DB {
+	return Reader(d.DB)
+}
+
+// Reader opts the queries of db onto a read replica. Use it for read-heavy
+// endpoints that tolerate replication lag; it is a no-op without replicas.
+func Reader(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Use(ReadReplicas))
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"base/core/config"
+)
+
+func TestInitDBAppliesPoolSettings(t *testing.T) {
+	previous := DB
+	t.Cleanup(func() { DB = previous })
+
+	cfg := &config.Config{
+		DBDriver:          "sqlite",
+		DBPath:            filepath.Join(t.TempDir(), "test.db"),
+		DBMaxOpenConns:    3,
+		DBMaxIdleConns:    1,
+		DBConnMaxLifetime: 20 * time.Millisecond,
+	}
+	db, err := InitDB(cfg)
+	if err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if max := sqlDB.Stats().MaxOpenConnections; max != 3 {
+		t.Errorf("MaxOpenConnections = %d, want 3", max)
+	}
+
+	// Three connections in use keep only one idle once released
+	ctx := context.Background()
+	conns := make([]*sql.Conn, 3)
+	for i := range conns {
+		if conns[i], err = sqlDB.Conn(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, conn := range conns {
+		conn.Close()
+	}
+	stats := sqlDB.Stats()
+	if stats.Idle != 1 || stats.MaxIdleClosed != 2 {
+		t.Errorf("%d idle and %d closed as surplus, want 1 and 2", stats.Idle, stats.MaxIdleClosed)
+	}
+
+	// A connection past its lifetime is replaced instead of reused
+	time.Sleep(2 * cfg.DBConnMaxLifetime)
+	if err := db.Exec("SELECT 1").Error; err != nil {
+		t.Fatal(err)
+	}
+	if closed := sqlDB.Stats().MaxLifetimeClosed; closed == 0 {
+		t.Error("an expired connection was reused")
+	}
+}
@@ -0,0 +1,23 @@
+package database
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// IsDuplicateKey reports whether err is a unique constraint violation. The
+// dialect translates its own error codes, so this works whether or not the
+// connection was opened with TranslateError.
+func IsDuplicateKey(db *gorm.DB, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		return errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey)
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"time"
+
+	"base/core/logger"
+
+	"gorm.io/gorm"
+)
+
+const slowQueryStartKey = "slow_query:start"
+
+// SlowQueryLogger is a GORM plugin that logs statements running longer than Threshold.
+// Only the SQL with placeholders is logged; bound parameter values are never included,
+// so passwords and tokens in writes do not end up in the logs.
+type SlowQueryLogger struct {
+	Threshold time.Duration
+	Logger    logger.Logger
+}
+
+// NewSlowQueryLogger creates a slow query plugin for the given threshold
+func NewSlowQueryLogger(threshold time.Duration, log logger.Logger) *SlowQueryLogger {
+	return &SlowQueryLogger{
+		Threshold: threshold,
+		Logger:    log,
+	}
+}
+
+// Name implements gorm.Plugin
+func (p *SlowQueryLogger) Name() string {
+	return "slow_query_logger"
+}
+
+// Initialize implements gorm.Plugin by registering timing callbacks around every operation
+func (p *SlowQueryLogger) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Create().Before("gorm:create").Register("slow_query:before_create", p.before); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("slow_query:after_create", p.after("create")); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("slow_query:before_query", p.before); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("slow_query:after_query", p.after("query")); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("slow_query:before_update", p.before); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("slow_query:after_update", p.after("update")); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("slow_query:before_delete", p.before); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("slow_query:after_delete", p.after("delete")); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("slow_query:before_row", p.before); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("slow_query:after_row", p.after("row")); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("slow_query:before_raw", p.before); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("slow_query:after_raw", p.after("raw"))
+}
+
+func (p *SlowQueryLogger) before(db *gorm.DB) {
+	db.InstanceSet(slowQueryStartKey, time.Now())
+}
+
+func (p *SlowQueryLogger) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(slowQueryStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		elapsed := time.Since(start)
+		if elapsed < p.Threshold {
+			return
+		}
+
+		fields := []logger.Field{
+			logger.String("operation", operation),
+			logger.String("sql", db.Statement.SQL.String()),
+			logger.Duration("duration", elapsed),
+			logger.Duration("threshold", p.Threshold),
+			logger.Int64("rows", db.Statement.RowsAffected),
+		}
+		if db.Statement.Table != "" {
+			fields = append(fields, logger.String("table", db.Statement.Table))
+		}
+		if db.Error != nil {
+			fields = append(fields, logger.String("error", db.Error.Error()))
+		}
+
+		p.Logger.Warn("Slow query detected", fields...)
+	}
+}
+
+// EnableSlowQueryLog registers the slow query plugin on db when threshold is positive
+func EnableSlowQueryLog(db *gorm.DB, threshold time.Duration, log logger.Logger) error {
+	if threshold <= 0 {
+		return nil
+	}
+	return db.Use(NewSlowQueryLogger(threshold, log))
+}
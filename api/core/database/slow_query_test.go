@@ -0,0 +1,123 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/logger"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+type account struct {
+	Id       uint
+	Email    string
+	Password string
+}
+
+// newSlowQueryDB opens a sqlite database logging queries slower than threshold
+func newSlowQueryDB(t *testing.T, threshold time.Duration) (*gorm.DB, *observer.ObservedLogs) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(&account{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	core, logs := observer.New(zap.WarnLevel)
+	if err := db.Use(NewSlowQueryLogger(threshold, logger.NewLoggerFromZap(zap.New(core)))); err != nil {
+		t.Fatalf("register plugin: %v", err)
+	}
+	return db, logs
+}
+
+// slowCondition counts to a few million in a recursive CTE before matching
+const slowCondition = "(WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 2000000) SELECT count(*) FROM c) > 0"
+
+func TestSlowQueryIsLogged(t *testing.T) {
+	db, logs := newSlowQueryDB(t, time.Millisecond)
+
+	if err := db.Create(&account{Email: "a@example.com"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var accounts []account
+	if err := db.Where(slowCondition).Find(&accounts).Error; err != nil {
+		t.Fatalf("slow query: %v", err)
+	}
+
+	entries := logs.FilterMessage("Slow query detected").All()
+	if len(entries) == 0 {
+		t.Fatal("slow query was not logged")
+	}
+	fields := entries[len(entries)-1].ContextMap()
+	if sql, _ := fields["sql"].(string); !strings.Contains(sql, "WITH RECURSIVE") {
+		t.Errorf("logged sql %q, want the slow statement", sql)
+	}
+	if duration, _ := fields["duration"].(time.Duration); duration < time.Millisecond {
+		t.Errorf("logged duration %v, want at least the threshold", duration)
+	}
+	if rows, _ := fields["rows"].(int64); rows != 1 {
+		t.Errorf("logged %v rows, want 1", fields["rows"])
+	}
+}
+
+func TestFastQueryIsNotLogged(t *testing.T) {
+	db, logs := newSlowQueryDB(t, time.Hour)
+
+	if err := db.Create(&account{Email: "a@example.com"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var accounts []account
+	if err := db.Find(&accounts).Error; err != nil {
+		t.Fatal(err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("got %d logs for fast queries, want none", logs.Len())
+	}
+}
+
+func TestSlowQueryLogOmitsParameterValues(t *testing.T) {
+	// Every statement is slow at this threshold
+	db, logs := newSlowQueryDB(t, time.Nanosecond)
+
+	item := account{Email: "a@example.com", Password: "initial-secret"}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&item).Update("password", "hunter2-secret").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if logs.Len() < 2 {
+		t.Fatalf("got %d logs, want the create and the update", logs.Len())
+	}
+	for _, entry := range logs.All() {
+		for key, value := range entry.ContextMap() {
+			if text := fmt.Sprint(value); strings.Contains(text, "secret") {
+				t.Errorf("field %s leaks a parameter value: %s", key, text)
+			}
+		}
+	}
+}
+
+func TestEnableSlowQueryLogIsOptIn(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EnableSlowQueryLog(db, 0, logger.NewLoggerFromZap(zap.NewNop())); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := db.Config.Plugins[(&SlowQueryLogger{}).Name()]; ok {
+		t.Error("plugin registered with a zero threshold")
+	}
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	timePointerType = reflect.TypeOf(&time.Time{})
+	deletedAtType   = reflect.TypeOf(gorm.DeletedAt{})
+)
+
+// Timezone is a GORM plugin storing every model time in UTC and presenting
+// the times it reads or writes in Location, so API responses serialize them
+// in a single configured zone whatever the driver returns.
+//
+// Only model fields are converted. Times passed as query arguments are
+// compared as given, so build them from time.Now() rather than parsing
+// local wall-clock strings.
+type Timezone struct {
+	Location *time.Location
+}
+
+// NewTimezone creates the plugin presenting times in location, UTC when nil
+func NewTimezone(location *time.Location) *Timezone {
+	if location == nil {
+		location = time.UTC
+	}
+	return &Timezone{Location: location}
+}
+
+// Name implements gorm.Plugin
+func (p *Timezone) Name() string {
+	return "timezone"
+}
+
+// Initialize implements gorm.Plugin. Auto timestamps are generated in UTC and
+// the model times are converted to UTC before writes and to Location after
+// every operation filling the model.
+func (p *Timezone) Initialize(db *gorm.DB) error {
+	db.Config.NowFunc = func() time.Time {
+		return time.Now().UTC()
+	}
+
+	cb := db.Callback()
+	toUTC := p.convert(time.UTC)
+	toLocation := p.convert(p.Location)
+
+	if err := cb.Create().Before("gorm:create").Register("timezone:before_create", toUTC); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("timezone:after_create", toLocation); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("timezone:before_update", toUTC); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("timezone:after_update", toLocation); err != nil {
+		return err
+	}
+	return cb.Query().After("gorm:query").Register("timezone:after_query", toLocation)
+}
+
+// convert returns a callback moving the times of the statement's model, and of
+// a map passed to Updates, into location
+func (p *Timezone) convert(location *time.Location) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || db.Statement == nil {
+			return
+		}
+
+		if values, ok := db.Statement.Dest.(map[string]any); ok {
+			for key, value := range values {
+				switch t := value.(type) {
+				case time.Time:
+					values[key] = t.In(location)
+				case *time.Time:
+					if t != nil {
+						converted := t.In(location)
+						values[key] = &converted
+					}
+				}
+			}
+		}
+
+		if db.Statement.Schema == nil || !db.Statement.ReflectValue.IsValid() {
+			return
+		}
+
+		fields := timeFields(db.Statement.Schema)
+		if len(fields) == 0 {
+			return
+		}
+
+		ctx := db.Statement.Context
+		rv := reflect.Indirect(db.Statement.ReflectValue)
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				convertFields(ctx, fields, reflect.Indirect(rv.Index(i)), location)
+			}
+		case reflect.Struct:
+			convertFields(ctx, fields, rv, location)
+		}
+	}
+}
+
+// timeFields returns the fields of s holding a time
+func timeFields(s *schema.Schema) []*schema.Field {
+	var fields []*schema.Field
+	for _, field := range s.Fields {
+		switch field.FieldType {
+		case timeType, timePointerType, deletedAtType:
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// convertFields moves the time fields of the struct value into location,
+// leaving values that cannot be set untouched
+func convertFields(ctx context.Context, fields []*schema.Field, value reflect.Value, location *time.Location) {
+	if value.Kind() != reflect.Struct || !value.CanAddr() {
+		return
+	}
+
+	for _, field := range fields {
+		fv := field.ReflectValueOf(ctx, value)
+		if !fv.CanSet() {
+			continue
+		}
+
+		switch t := fv.Addr().Interface().(type) {
+		case *time.Time:
+			if !t.IsZero() {
+				*t = t.In(location)
+			}
+		case **time.Time:
+			if *t != nil {
+				converted := (*t).In(location)
+				*t = &converted
+			}
+		case *gorm.DeletedAt:
+			if t.Valid {
+				t.Time = t.Time.In(location)
+			}
+		}
+	}
+}
+
+// UseTimezone registers the timezone plugin on db, presenting times in the
+// named IANA location
+func UseTimezone(db *gorm.DB, name string) error {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return err
+	}
+	return db.Use(NewTimezone(location))
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+type tzItem struct {
+	Id        uint
+	Name      string
+	SeenAt    *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+// newTimezoneDB opens a database with the timezone plugin presenting times
+// in location, while the process runs in a zone east of UTC
+func newTimezoneDB(t *testing.T, location *time.Location) *gorm.DB {
+	t.Helper()
+	local := time.Local
+	time.Local = time.FixedZone("UTC+5", 5*3600)
+	t.Cleanup(func() { time.Local = local })
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.Use(NewTimezone(location)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&tzItem{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// storedTimes returns the raw column values of item id
+func storedTimes(t *testing.T, db *gorm.DB, id uint) map[string]string {
+	t.Helper()
+	var created, updated, seen string
+	row := db.Raw("SELECT CAST(created_at AS TEXT), CAST(updated_at AS TEXT), COALESCE(CAST(seen_at AS TEXT), '') FROM tz_items WHERE id = ?", id).Row()
+	if err := row.Scan(&created, &updated, &seen); err != nil {
+		t.Fatal(err)
+	}
+	return map[string]string{"created_at": created, "updated_at": updated, "seen_at": seen}
+}
+
+func TestTimestampsAreUTCAndMonotonic(t *testing.T) {
+	db := newTimezoneDB(t, time.UTC)
+
+	seen := time.Now() // in the process's UTC+5 zone
+	item := tzItem{Name: "first", SeenAt: &seen}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatal(err)
+	}
+	if item.CreatedAt.Location() != time.UTC || item.UpdatedAt.Location() != time.UTC || item.SeenAt.Location() != time.UTC {
+		t.Errorf("created %v, updated %v, seen %v; want UTC", item.CreatedAt, item.UpdatedAt, item.SeenAt)
+	}
+	for column, value := range storedTimes(t, db, item.Id) {
+		if !strings.HasSuffix(value, "+00:00") {
+			t.Errorf("%s stored as %q, want UTC", column, value)
+		}
+	}
+
+	created := item.CreatedAt
+	time.Sleep(2 * time.Millisecond)
+	if err := db.Model(&item).Update("name", "second").Error; err != nil {
+		t.Fatal(err)
+	}
+	var reloaded tzItem
+	if err := db.First(&reloaded, item.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !reloaded.CreatedAt.Equal(created) {
+		t.Errorf("created_at moved from %v to %v", created, reloaded.CreatedAt)
+	}
+	if !reloaded.UpdatedAt.After(reloaded.CreatedAt) {
+		t.Errorf("updated_at %v is not after created_at %v", reloaded.UpdatedAt, reloaded.CreatedAt)
+	}
+	if reloaded.CreatedAt.Location() != time.UTC || reloaded.UpdatedAt.Location() != time.UTC || !reloaded.SeenAt.Equal(seen) {
+		t.Errorf("reloaded %+v, want UTC times at the same instants", reloaded)
+	}
+
+	// A local time passed to Updates is stored in UTC as well
+	later := time.Now().Add(time.Hour)
+	db.Model(&reloaded).Updates(map[string]any{"seen_at": later})
+	if value := storedTimes(t, db, item.Id)["seen_at"]; !strings.HasSuffix(value, "+00:00") {
+		t.Errorf("seen_at updated to %q, want UTC", value)
+	}
+}
+
+func TestTimesAreRenderedInResponseTimezone(t *testing.T) {
+	berlin := time.FixedZone("CET", 3600)
+	db := newTimezoneDB(t, berlin)
+
+	item := tzItem{Name: "item"}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatal(err)
+	}
+	if value := storedTimes(t, db, item.Id)["created_at"]; !strings.HasSuffix(value, "+00:00") {
+		t.Errorf("created_at stored as %q, want UTC", value)
+	}
+
+	var items []tzItem
+	if err := db.Find(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].CreatedAt.Location() != berlin || !items[0].CreatedAt.Equal(item.CreatedAt) {
+		t.Fatalf("read %+v, want the created instant in CET", items)
+	}
+	encoded, _ := json.Marshal(items[0])
+	if !strings.Contains(string(encoded), "+01:00") {
+		t.Errorf("response %s, want times rendered in CET", encoded)
+	}
+
+	db.Delete(&item)
+	var deleted tzItem
+	db.Unscoped().First(&deleted, item.Id)
+	if !deleted.DeletedAt.Valid || deleted.DeletedAt.Time.Location() != berlin {
+		t.Errorf("deleted_at %+v, want it in CET", deleted.DeletedAt)
+	}
+}
+
+func TestUseTimezoneRejectsUnknownZone(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := UseTimezone(db, "Mars/Olympus_Mons"); err == nil {
+		t.Error("an unknown zone was accepted")
+	}
+	if err := UseTimezone(db, "UTC"); err != nil {
+		t.Errorf("UTC: %v", err)
+	}
+}
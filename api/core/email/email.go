@@ -43,8 +43,12 @@ func Send(msg Message) error {
 // NewEmailSender creates a new email sender based on the configuration
 func NewSender(cfg *config.Config) (Sender, error) {
 	fmt.Printf("Initializing email sender with provider: %s\n", cfg.EmailProvider)
+	return newProviderSender(cfg, cfg.EmailProvider)
+}
 
-	switch cfg.EmailProvider {
+// newProviderSender creates the sender for a named provider
+func newProviderSender(cfg *config.Config, provider string) (Sender, error) {
+	switch provider {
 	case "smtp":
 		return NewSMTPSender(cfg)
 	case "sendgrid":
@@ -57,6 +61,6 @@ func NewSender(cfg *config.Config) (Sender, error) {
 		fmt.Println("EMAIL_PROVIDER not set, using default sender")
 		return NewDefaultSender(cfg)
 	default:
-		return nil, fmt.Errorf("unsupported email provider: %s", cfg.EmailProvider)
+		return nil, fmt.Errorf("unsupported email provider: %s", provider)
 	}
 }
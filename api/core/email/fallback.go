@@ -0,0 +1,109 @@
+package email
+
+import (
+	"fmt"
+
+	"base/core/config"
+	"base/core/logger"
+)
+
+// ProviderSender is a Sender together with the provider name it delivers through
+type ProviderSender struct {
+	Name   string
+	Sender Sender
+}
+
+// FallbackSender tries providers in order. When one fails with a retryable
+// error the next is tried; permanent errors (such as an invalid recipient)
+// are returned straight away since another provider would reject them too.
+type FallbackSender struct {
+	providers []ProviderSender
+	logger    logger.Logger
+}
+
+// NewFallbackSender creates a sender that falls back through providers in order
+func NewFallbackSender(log logger.Logger, providers ...ProviderSender) *FallbackSender {
+	return &FallbackSender{
+		providers: providers,
+		logger:    log,
+	}
+}
+
+// Send implements Sender
+func (s *FallbackSender) Send(msg Message) error {
+	if len(s.providers) == 0 {
+		return fmt.Errorf("no email providers configured")
+	}
+
+	var err error
+	for i, provider := range s.providers {
+		err = provider.Sender.Send(msg)
+		if err == nil {
+			s.logger.Info("Email delivered",
+				logger.String("provider", provider.Name),
+				logger.Bool("fallback", i > 0),
+				logger.String("subject", msg.Subject))
+			return nil
+		}
+
+		if !IsRetryable(err) {
+			return err
+		}
+		if i < len(s.providers)-1 {
+			s.logger.Warn("Email provider failed, trying next provider",
+				logger.String("provider", provider.Name),
+				logger.String("next_provider", s.providers[i+1].Name),
+				logger.String("error", err.Error()))
+		}
+	}
+
+	return err
+}
+
+// NewSenderFromConfig builds the configured primary provider followed by the
+// EMAIL_FALLBACK_PROVIDER providers, each retrying transient failures before
+// the next one is tried. Providers that cannot be created are skipped with a
+// warning; an error is returned only if none can be.
+func NewSenderFromConfig(cfg *config.Config, log logger.Logger) (Sender, error) {
+	primary, err := NewSender(cfg)
+	if err != nil && len(cfg.EmailFallbackProviders) == 0 {
+		return nil, err
+	}
+
+	retry := NewRetryConfig(cfg)
+	providers := []ProviderSender{}
+	if err != nil {
+		log.Warn("Primary email provider unavailable, using fallbacks",
+			logger.String("provider", cfg.EmailProvider),
+			logger.String("error", err.Error()))
+	} else {
+		providers = append(providers, ProviderSender{Name: providerName(cfg.EmailProvider), Sender: NewRetrySender(primary, retry, log)})
+	}
+
+	for _, name := range cfg.EmailFallbackProviders {
+		sender, err := newProviderSender(cfg, name)
+		if err != nil {
+			log.Warn("Fallback email provider unavailable",
+				logger.String("provider", name),
+				logger.String("error", err.Error()))
+			continue
+		}
+		providers = append(providers, ProviderSender{Name: name, Sender: NewRetrySender(sender, retry, log)})
+	}
+
+	switch len(providers) {
+	case 0:
+		return nil, fmt.Errorf("no email provider could be initialized")
+	case 1:
+		return providers[0].Sender, nil
+	default:
+		return NewFallbackSender(log, providers...), nil
+	}
+}
+
+func providerName(provider string) string {
+	if provider == "" {
+		return "default"
+	}
+	return provider
+}
@@ -0,0 +1,123 @@
+package email
+
+import (
+	"errors"
+	"net/textproto"
+	"testing"
+
+	"base/core/config"
+	"base/core/logger"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// fakeSender fails with err and counts its sends
+type fakeSender struct {
+	err   error
+	sends int
+}
+
+func (s *fakeSender) Send(msg Message) error {
+	s.sends++
+	return s.err
+}
+
+func observedLogger() (logger.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return logger.NewLoggerFromZap(zap.New(core)), logs
+}
+
+func TestFallbackSenderTriesNextProvider(t *testing.T) {
+	log, logs := observedLogger()
+	primary := &fakeSender{err: errors.New("connection refused")}
+	secondary := &fakeSender{}
+	s := NewFallbackSender(log, ProviderSender{"smtp", primary}, ProviderSender{"sendgrid", secondary})
+
+	if err := s.Send(Message{To: []string{"a@example.com"}, Subject: "Hi"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if primary.sends != 1 || secondary.sends != 1 {
+		t.Errorf("sends = %d, %d; want 1, 1", primary.sends, secondary.sends)
+	}
+
+	delivered := logs.FilterMessage("Email delivered").All()
+	if len(delivered) != 1 {
+		t.Fatalf("logged %d deliveries, want 1", len(delivered))
+	}
+	fields := delivered[0].ContextMap()
+	if fields["provider"] != "sendgrid" || fields["fallback"] != true {
+		t.Errorf("delivery logged with %v, want provider sendgrid by fallback", fields)
+	}
+	if logs.FilterMessage("Email provider failed, trying next provider").Len() != 1 {
+		t.Error("the failing provider was not logged")
+	}
+}
+
+func TestFallbackSenderStopsOnPermanentError(t *testing.T) {
+	log, _ := observedLogger()
+	for name, err := range map[string]error{
+		"wrapped":  Permanent(errors.New("invalid recipient")),
+		"smtp 5xx": &textproto.Error{Code: 550, Msg: "mailbox unavailable"},
+	} {
+		primary := &fakeSender{err: err}
+		secondary := &fakeSender{}
+		s := NewFallbackSender(log, ProviderSender{"smtp", primary}, ProviderSender{"sendgrid", secondary})
+
+		if got := s.Send(Message{}); !errors.Is(got, err) {
+			t.Errorf("%s: Send = %v, want the primary error", name, got)
+		}
+		if secondary.sends != 0 {
+			t.Errorf("%s: fallback provider tried after a permanent error", name)
+		}
+	}
+}
+
+func TestFallbackSenderReturnsLastError(t *testing.T) {
+	log, logs := observedLogger()
+	last := &textproto.Error{Code: 421, Msg: "try again later"}
+	s := NewFallbackSender(log,
+		ProviderSender{"smtp", &fakeSender{err: errors.New("timeout")}},
+		ProviderSender{"postmark", &fakeSender{err: last}})
+
+	if err := s.Send(Message{}); !errors.Is(err, last) {
+		t.Errorf("Send = %v, want the last provider's error", err)
+	}
+	if logs.FilterMessage("Email delivered").Len() != 0 {
+		t.Error("a delivery was logged although every provider failed")
+	}
+
+	if err := NewFallbackSender(log).Send(Message{}); err == nil {
+		t.Error("sending without providers succeeded")
+	}
+}
+
+func TestNewSenderFromConfigUsesFallbackWhenPrimaryUnavailable(t *testing.T) {
+	log, logs := observedLogger()
+
+	cfg := &config.Config{EmailProvider: "carrier-pigeon", EmailFallbackProviders: []string{"default"}, EmailRetryAttempts: 1}
+	sender, err := NewSenderFromConfig(cfg, log)
+	if err != nil {
+		t.Fatalf("NewSenderFromConfig: %v", err)
+	}
+	if _, ok := sender.(*FallbackSender); ok {
+		t.Error("a single usable provider was wrapped in a FallbackSender")
+	}
+	if logs.FilterMessage("Primary email provider unavailable, using fallbacks").Len() != 1 {
+		t.Error("the unavailable primary provider was not logged")
+	}
+
+	cfg = &config.Config{EmailProvider: "default", EmailFallbackProviders: []string{"default"}, EmailRetryAttempts: 1}
+	if sender, err = NewSenderFromConfig(cfg, log); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sender.(*FallbackSender); !ok {
+		t.Errorf("two providers built %T, want *FallbackSender", sender)
+	}
+
+	cfg = &config.Config{EmailProvider: "carrier-pigeon"}
+	if _, err := NewSenderFromConfig(cfg, log); err == nil {
+		t.Error("an unsupported provider without fallbacks was accepted")
+	}
+}
@@ -34,6 +34,10 @@ func (s *PostmarkSender) Send(msg Message) error {
 		email.TextBody = ""
 	}
 
-	_, err := s.client.SendEmail(email)
+	response, err := s.client.SendEmail(email)
+	if err != nil && response.ErrorCode != 0 {
+		// Postmark API error codes (invalid address, inactive recipient, bad token) will not resolve on retry
+		return Permanent(err)
+	}
 	return err
 }
@@ -0,0 +1,137 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"time"
+
+	"base/core/config"
+	"base/core/logger"
+)
+
+// RetryConfig controls how failed sends are retried
+type RetryConfig struct {
+	Attempts  int           // Total attempts including the first one
+	BaseDelay time.Duration // Delay before the first retry, doubled on each further retry
+	MaxDelay  time.Duration // Upper bound for a single delay
+}
+
+// NewRetryConfig builds a RetryConfig from the application configuration
+func NewRetryConfig(cfg *config.Config) RetryConfig {
+	return RetryConfig{
+		Attempts:  cfg.EmailRetryAttempts,
+		BaseDelay: cfg.EmailRetryBaseDelay,
+		MaxDelay:  cfg.EmailRetryMaxDelay,
+	}
+}
+
+// PermanentError marks a send failure that will not succeed on retry,
+// such as an invalid recipient or a rejected API token
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err so it is not retried
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsRetryable reports whether a send error is likely transient.
+// SMTP 4xx replies are temporary by definition; 5xx replies (unknown mailbox,
+// relay denied, ...) are permanent. Errors explicitly wrapped with Permanent
+// are never retried. Anything else (network errors, timeouts, provider 5xx)
+// is treated as transient.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var permanent *PermanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code >= 400 && smtpErr.Code < 500
+	}
+
+	return true
+}
+
+// RetrySender wraps a Sender and retries transient failures with exponential backoff.
+// Sends stay synchronous; the total wait is bounded by Attempts and MaxDelay.
+type RetrySender struct {
+	sender Sender
+	config RetryConfig
+	logger logger.Logger
+}
+
+// NewRetrySender wraps sender with retry behaviour
+func NewRetrySender(sender Sender, cfg RetryConfig, log logger.Logger) *RetrySender {
+	if cfg.Attempts < 1 {
+		cfg.Attempts = 1
+	}
+	if cfg.MaxDelay > 0 && cfg.BaseDelay > cfg.MaxDelay {
+		cfg.BaseDelay = cfg.MaxDelay
+	}
+	return &RetrySender{
+		sender: sender,
+		config: cfg,
+		logger: log,
+	}
+}
+
+// Send implements Sender
+func (s *RetrySender) Send(msg Message) error {
+	if len(msg.To) == 0 {
+		return Permanent(fmt.Errorf("email has no recipients"))
+	}
+
+	delay := s.config.BaseDelay
+	var err error
+
+	for attempt := 1; attempt <= s.config.Attempts; attempt++ {
+		err = s.sender.Send(msg)
+		if err == nil {
+			if attempt > 1 {
+				s.logger.Info("Email sent after retry",
+					logger.Int("attempt", attempt),
+					logger.String("subject", msg.Subject))
+			}
+			return nil
+		}
+
+		retryable := IsRetryable(err)
+		s.logger.Warn("Email send attempt failed",
+			logger.Int("attempt", attempt),
+			logger.Int("max_attempts", s.config.Attempts),
+			logger.Bool("retryable", retryable),
+			logger.String("subject", msg.Subject),
+			logger.String("error", err.Error()))
+
+		if !retryable || attempt == s.config.Attempts {
+			break
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+		if s.config.MaxDelay > 0 && delay > s.config.MaxDelay {
+			delay = s.config.MaxDelay
+		}
+	}
+
+	return err
+}
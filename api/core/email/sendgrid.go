@@ -2,6 +2,8 @@ package email
 
 import (
 	"base/core/config"
+	"fmt"
+	"net/http"
 
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
@@ -30,6 +32,19 @@ func (s *SendGridSender) Send(msg Message) error {
 
 	email := mail.NewV3MailInit(from, msg.Subject, to, content)
 
-	_, err := s.client.Send(email)
-	return err
+	response, err := s.client.Send(email)
+	if err != nil {
+		return err
+	}
+
+	// SendGrid reports rejections through the status code rather than err
+	if response.StatusCode >= 400 {
+		sendErr := fmt.Errorf("sendgrid returned status %d: %s", response.StatusCode, response.Body)
+		if response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500 {
+			return sendErr
+		}
+		return Permanent(sendErr)
+	}
+
+	return nil
 }
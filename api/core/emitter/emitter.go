@@ -2,96 +2,147 @@ package emitter
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 	"time"
+
+	"base/core/logger"
 )
 
+// Events are dispatched in one of two ways, chosen by the emitter:
+//
+//   - EmitSync runs the listeners one after another in the caller's
+//     goroutine and returns their errors, so a listener can veto the
+//     operation or must be done before it continues. user.login_attempt
+//     (a listener may reject the login) and the authorization events
+//     user.role_changed and role.permissions_changed (cached permissions
+//     are dropped before the response) are emitted this way.
+//   - EmitAsync starts every listener on its own goroutine and returns at
+//     once. Listener errors and panics are logged and never reach the
+//     caller. Every other core event is emitted this way.
+//
+// Persisted events are stored in the outbox by both and delivered later.
+
+// Listener handles an event. The error it returns is reported by EmitSync
+// and makes an outbox delivery fail.
+type Listener func(data any) error
+
 type Emitter struct {
-	listeners map[string][]func(any)
+	listeners map[string][]Listener
 	mutex     sync.RWMutex
+	persisted map[string]reflect.Type // Payload type of each event routed through the outbox
+	outbox    *outbox
+	logger    logger.Logger // Reports async listener failures; see SetLogger
 }
 
 func New() *Emitter {
 	return &Emitter{
-		listeners: make(map[string][]func(any)),
+		listeners: make(map[string][]Listener),
 	}
 }
 
+// SetLogger sets the logger async listener errors and panics are reported
+// to. Until it is called they go to the default logger.
+func (e *Emitter) SetLogger(log logger.Logger) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.logger = log
+}
+
+// On adds a listener that cannot fail
 func (e *Emitter) On(event string, listener func(any)) {
+	e.Handle(event, func(data any) error {
+		listener(data)
+		return nil
+	})
+}
+
+// Handle adds a listener whose error is reported to the emitter
+func (e *Emitter) Handle(event string, listener Listener) {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 	e.listeners[event] = append(e.listeners[event], listener)
 }
 
+// Emit runs the listeners like EmitSync, dropping their errors.
+//
+// Deprecated: use EmitSync when the caller depends on the listeners, and
+// EmitAsync otherwise.
 func (e *Emitter) Emit(event string, data any) {
-	e.mutex.RLock()
-	defer e.mutex.RUnlock()
+	_ = e.EmitSync(event, data)
+}
 
-	// Use a WaitGroup to wait for all listeners to finish
-	var wg sync.WaitGroup
-	for _, listener := range e.listeners[event] {
-		wg.Add(1)
-		go func(listener func(any)) {
-			defer wg.Done()
-			defer func() {
-				if r := recover(); r != nil {
-					fmt.Printf("Recovered from panic in listener for event %s: %v\n", event, r)
-				}
-			}()
-			listener(data)
-		}(listener)
+// EmitSync runs the listeners of event in the caller's goroutine, in the
+// order they were added, and returns their errors joined. A panicking
+// listener is reported as an error and the remaining listeners still run.
+// A persisted event is only stored, and the error is the one storing it.
+func (e *Emitter) EmitSync(event string, data any) error {
+	if e.isPersisted(event) {
+		return e.store(e.outbox.db, event, data)
 	}
-	wg.Wait() // Block until all listeners complete
+
+	var errs []error
+	for _, listener := range e.listenersOf(event) {
+		if err := call(event, listener, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 func (e *Emitter) Clear() {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
-	e.listeners = make(map[string][]func(any))
+	e.listeners = make(map[string][]Listener)
 }
 
-// EmitAsync emits an event asynchronously without blocking
+// EmitAsync emits an event asynchronously without blocking. Listener errors
+// and panics are logged.
 func (e *Emitter) EmitAsync(event string, data any) {
-	e.mutex.RLock()
-	listeners := make([]func(any), len(e.listeners[event]))
-	copy(listeners, e.listeners[event])
-	e.mutex.RUnlock()
+	if e.isPersisted(event) {
+		e.storeOrLog(event, data)
+		return
+	}
 
 	// Fire and forget - don't wait for listeners
-	for _, listener := range listeners {
-		go func(listener func(any)) {
-			defer func() {
-				if r := recover(); r != nil {
-					fmt.Printf("Recovered from panic in async listener for event %s: %v\n", event, r)
-				}
-			}()
-			listener(data)
+	log := e.log()
+	for _, listener := range e.listenersOf(event) {
+		go func(listener Listener) {
+			if err := call(event, listener, data); err != nil {
+				log.Error("Async listener failed", logger.String("event", event), logger.String("error", err.Error()))
+			}
 		}(listener)
 	}
 }
 
-// EmitWithContext emits an event with context support
+// EmitWithContext emits an event with context support, returning the joined
+// listener errors, or the context's error when it ends first
 func (e *Emitter) EmitWithContext(ctx context.Context, event string, data any) error {
-	e.mutex.RLock()
-	listeners := make([]func(any), len(e.listeners[event]))
-	copy(listeners, e.listeners[event])
-	e.mutex.RUnlock()
+	if e.isPersisted(event) {
+		return e.store(e.outbox.db.WithContext(ctx), event, data)
+	}
+
+	listeners := e.listenersOf(event)
 
 	// Create a channel to signal completion
 	done := make(chan struct{})
-	var wg sync.WaitGroup
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
 
 	for _, listener := range listeners {
 		wg.Add(1)
-		go func(listener func(any)) {
+		go func(listener Listener) {
 			defer wg.Done()
-			defer func() {
-				if r := recover(); r != nil {
-					fmt.Printf("Recovered from panic in context listener for event %s: %v\n", event, r)
-				}
-			}()
-			listener(data)
+			if err := call(event, listener, data); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
 		}(listener)
 	}
 
@@ -102,7 +153,7 @@ func (e *Emitter) EmitWithContext(ctx context.Context, event string, data any) e
 
 	select {
 	case <-done:
-		return nil
+		return errors.Join(errs...)
 	case <-ctx.Done():
 		return ctx.Err()
 	}
@@ -133,3 +184,33 @@ func (e *Emitter) EventNames() []string {
 	}
 	return names
 }
+
+// log returns the logger set with SetLogger, or the default logger
+func (e *Emitter) log() logger.Logger {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	if e.logger == nil {
+		return logger.GetLogger()
+	}
+	return e.logger
+}
+
+// listenersOf returns a copy of the listeners of event, so they run without
+// the lock held and may add listeners themselves
+func (e *Emitter) listenersOf(event string) []Listener {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	listeners := make([]Listener, len(e.listeners[event]))
+	copy(listeners, e.listeners[event])
+	return listeners
+}
+
+// call runs listener, turning a panic into an error
+func call(event string, listener Listener, data any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("listener for event %s panicked: %v", event, r)
+		}
+	}()
+	return listener(data)
+}
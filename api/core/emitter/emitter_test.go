@@ -0,0 +1,118 @@
+package emitter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/logger"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestEmitSyncCollectsErrorsAndPanics(t *testing.T) {
+	e := New()
+	var ran []string
+	e.Handle("order.placed", func(any) error {
+		ran = append(ran, "first")
+		return errors.New("out of stock")
+	})
+	e.Handle("order.placed", func(any) error {
+		ran = append(ran, "second")
+		panic("boom")
+	})
+	e.On("order.placed", func(any) { ran = append(ran, "third") })
+
+	err := e.EmitSync("order.placed", nil)
+	if err == nil {
+		t.Fatal("EmitSync returned no error")
+	}
+	for _, want := range []string{"out of stock", "listener for event order.placed panicked: boom"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	if got := strings.Join(ran, ","); got != "first,second,third" {
+		t.Errorf("listeners ran %s, want first,second,third in order", got)
+	}
+}
+
+func TestEmitSyncVetoesCaller(t *testing.T) {
+	e := New()
+	e.Handle("user.login_attempt", func(data any) error {
+		if data.(string) == "blocked" {
+			return errors.New("login rejected")
+		}
+		return nil
+	})
+
+	if err := e.EmitSync("user.login_attempt", "alice"); err != nil {
+		t.Errorf("allowed login: %v", err)
+	}
+	if err := e.EmitSync("user.login_attempt", "blocked"); err == nil {
+		t.Error("blocked login was not rejected")
+	}
+}
+
+func TestEmitAsyncLogsPanicsWithoutCrashing(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	e := New()
+	e.SetLogger(logger.NewLoggerFromZap(zap.New(core)))
+
+	done := make(chan struct{})
+	e.Handle("media.uploaded", func(any) error { panic("bad payload") })
+	e.Handle("media.uploaded", func(any) error { return errors.New("thumbnail failed") })
+	e.On("media.uploaded", func(any) { close(done) })
+
+	e.EmitAsync("media.uploaded", nil)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("healthy listener did not run")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for logs.Len() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	entries := logs.FilterMessage("Async listener failed").All()
+	if len(entries) != 2 {
+		t.Fatalf("got %d logged failures, want 2", len(entries))
+	}
+	var errs []string
+	for _, entry := range entries {
+		fields := entry.ContextMap()
+		if fields["event"] != "media.uploaded" {
+			t.Errorf("logged event %v, want media.uploaded", fields["event"])
+		}
+		errs = append(errs, fields["error"].(string))
+	}
+	joined := strings.Join(errs, "\n")
+	for _, want := range []string{"panicked: bad payload", "thumbnail failed"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("logged errors %q do not contain %q", joined, want)
+		}
+	}
+}
+
+func TestEmitAsyncReturnsBeforeListenersFinish(t *testing.T) {
+	e := New()
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	e.On("report.requested", func(any) {
+		<-release
+		close(finished)
+	})
+
+	e.EmitAsync("report.requested", nil)
+	close(release)
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("async listener never ran")
+	}
+}
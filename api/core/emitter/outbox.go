@@ -0,0 +1,321 @@
+package emitter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"base/core/logger"
+
+	"gorm.io/gorm"
+)
+
+// Statuses of an outbox event
+const (
+	EventPending   = "pending"
+	EventDelivered = "delivered"
+	EventFailed    = "failed"
+)
+
+// Event is an emitted event stored in the outbox until its listeners have
+// run. Events only reach the outbox when their name was registered with
+// Persist.
+type Event struct {
+	Id          uint       `gorm:"column:id;primary_key;auto_increment" json:"id"`
+	Name        string     `gorm:"column:name;size:255;not null;index" json:"name"`
+	Payload     string     `gorm:"column:payload;type:text" json:"payload"`
+	Status      string     `gorm:"column:status;size:16;not null;index:idx_events_status_available" json:"status"`
+	Attempts    int        `gorm:"column:attempts;not null;default:0" json:"attempts"`
+	LastError   string     `gorm:"column:last_error;type:text" json:"last_error,omitempty"`
+	AvailableAt time.Time  `gorm:"column:available_at;index:idx_events_status_available" json:"available_at"` // Not delivered before this time
+	CreatedAt   time.Time  `gorm:"column:created_at;index" json:"created_at"`
+	DeliveredAt *time.Time `gorm:"column:delivered_at" json:"delivered_at,omitempty"`
+}
+
+func (Event) TableName() string {
+	return "events"
+}
+
+// OutboxConfig tunes the outbox dispatcher. Zero values use the defaults.
+type OutboxConfig struct {
+	PollInterval time.Duration // How often pending events are looked up, default 1s
+	BatchSize    int           // Events delivered per poll, default 100
+	MaxAttempts  int           // Deliveries before an event is marked failed, default 10
+	Lease        time.Duration // How long a claimed event is hidden from other dispatchers, default 1m
+}
+
+type outbox struct {
+	db      *gorm.DB
+	logger  logger.Logger
+	config  OutboxConfig
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// Persist routes an event through the outbox once EnableOutbox was called:
+// emitting it stores it, and the dispatcher delivers it to the listeners,
+// retrying until they all return without an error or panic. Listeners may
+// therefore see an event more than once. payload is a value of the type the
+// event is emitted with; stored payloads are decoded back into that type.
+func (e *Emitter) Persist(event string, payload any) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.persisted == nil {
+		e.persisted = make(map[string]reflect.Type)
+	}
+	e.persisted[event] = reflect.TypeOf(payload)
+}
+
+// EnableOutbox creates the events table and stores persisted events in it
+// from now on. Until the dispatcher is started they are only stored.
+func (e *Emitter) EnableOutbox(db *gorm.DB, log logger.Logger, config OutboxConfig) error {
+	if err := db.AutoMigrate(&Event{}); err != nil {
+		return fmt.Errorf("failed to migrate events table: %w", err)
+	}
+
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 10
+	}
+	if config.Lease <= 0 {
+		config.Lease = time.Minute
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.outbox = &outbox{db: db, logger: log, config: config}
+	return nil
+}
+
+// EmitTx emits an event as part of the transaction tx. A persisted event is
+// stored with tx, so it is delivered only if tx commits. Other events are
+// emitted right away with EmitSync, whose error fails the transaction; call
+// EmitTx as the last step before committing.
+func (e *Emitter) EmitTx(tx *gorm.DB, event string, data any) error {
+	if e.isPersisted(event) {
+		return e.store(tx, event, data)
+	}
+	return e.EmitSync(event, data)
+}
+
+// StartDispatcher delivers stored events in the background until
+// StopDispatcher is called. It does nothing without an outbox.
+func (e *Emitter) StartDispatcher() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.outbox == nil || e.outbox.stop != nil {
+		return
+	}
+	e.outbox.stop = make(chan struct{})
+	e.outbox.stopped = make(chan struct{})
+	go e.dispatch(e.outbox, e.outbox.stop, e.outbox.stopped)
+}
+
+// StopDispatcher stops the dispatcher and waits for the delivery in progress
+func (e *Emitter) StopDispatcher() {
+	e.mutex.Lock()
+	o := e.outbox
+	if o == nil || o.stop == nil {
+		e.mutex.Unlock()
+		return
+	}
+	stop, stopped := o.stop, o.stopped
+	o.stop = nil
+	e.mutex.Unlock()
+
+	close(stop)
+	<-stopped
+}
+
+// isPersisted reports whether event goes through the outbox
+func (e *Emitter) isPersisted(event string) bool {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	_, ok := e.persisted[event]
+	return ok && e.outbox != nil
+}
+
+// store writes event to the outbox with db, which may be a transaction
+func (e *Emitter) store(db *gorm.DB, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s: %w", event, err)
+	}
+
+	now := time.Now()
+	record := Event{
+		Name:        event,
+		Payload:     string(payload),
+		Status:      EventPending,
+		AvailableAt: now,
+		CreatedAt:   now,
+	}
+	if err := db.Create(&record).Error; err != nil {
+		return fmt.Errorf("failed to store event %s: %w", event, err)
+	}
+	return nil
+}
+
+// storeOrLog stores a persisted event emitted outside a transaction
+func (e *Emitter) storeOrLog(event string, data any) {
+	e.mutex.RLock()
+	o := e.outbox
+	e.mutex.RUnlock()
+
+	if err := e.store(o.db, event, data); err != nil {
+		o.logger.Error("Failed to store event", logger.String("event", event), logger.String("error", err.Error()))
+	}
+}
+
+func (e *Emitter) dispatch(o *outbox, stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+
+	ticker := time.NewTicker(o.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep draining while full batches come back
+		for e.deliverBatch(o) == o.config.BatchSize {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverBatch delivers the pending events that are due and returns how many
+// it looked at
+func (e *Emitter) deliverBatch(o *outbox) int {
+	now := time.Now()
+
+	var due []Event
+	err := o.db.Where("status = ? AND available_at <= ?", EventPending, now).
+		Order("id ASC").
+		Limit(o.config.BatchSize).
+		Find(&due).Error
+	if err != nil {
+		o.logger.Error("Failed to load pending events", logger.String("error", err.Error()))
+		return 0
+	}
+
+	for _, event := range due {
+		// Claim the event by pushing its availability past the lease, so a
+		// concurrent dispatcher skips it
+		claim := o.db.Model(&Event{}).
+			Where("id = ? AND status = ? AND available_at <= ?", event.Id, EventPending, now).
+			Updates(map[string]any{
+				"attempts":     gorm.Expr("attempts + 1"),
+				"available_at": now.Add(o.config.Lease),
+			})
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			continue
+		}
+		event.Attempts++
+
+		if err := e.deliver(event); err != nil {
+			e.retryLater(o, event, err)
+			continue
+		}
+
+		delivered := time.Now()
+		o.db.Model(&Event{}).Where("id = ?", event.Id).Updates(map[string]any{
+			"status":       EventDelivered,
+			"delivered_at": &delivered,
+			"last_error":   "",
+		})
+	}
+
+	return len(due)
+}
+
+// retryLater backs off exponentially, up to an hour, and marks the event
+// failed once it used up its attempts
+func (e *Emitter) retryLater(o *outbox, event Event, err error) {
+	o.logger.Warn("Event delivery failed",
+		logger.String("event", event.Name),
+		logger.Int("id", int(event.Id)),
+		logger.Int("attempt", event.Attempts),
+		logger.String("error", err.Error()))
+
+	updates := map[string]any{"last_error": err.Error()}
+	if event.Attempts >= o.config.MaxAttempts {
+		updates["status"] = EventFailed
+	} else {
+		backoff := time.Second << min(event.Attempts, 12)
+		updates["available_at"] = time.Now().Add(min(backoff, time.Hour))
+	}
+	o.db.Model(&Event{}).Where("id = ?", event.Id).Updates(updates)
+}
+
+// deliver runs every listener of a stored event and fails if one returns an
+// error or panics
+func (e *Emitter) deliver(event Event) error {
+	e.mutex.RLock()
+	payloadType := e.persisted[event.Name]
+	e.mutex.RUnlock()
+
+	data, err := decodePayload(event.Payload, payloadType)
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, listener := range e.listenersOf(event.Name) {
+		wg.Add(1)
+		go func(listener Listener) {
+			defer wg.Done()
+			if err := call(event.Name, listener, data); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(listener)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// decodePayload decodes a stored payload into a new value of payloadType, or
+// into a generic value when the type is unknown
+func decodePayload(payload string, payloadType reflect.Type) (any, error) {
+	if payloadType == nil {
+		var data any
+		err := json.Unmarshal([]byte(payload), &data)
+		return data, err
+	}
+
+	if payloadType.Kind() == reflect.Pointer {
+		target := reflect.New(payloadType.Elem())
+		if err := json.Unmarshal([]byte(payload), target.Interface()); err != nil {
+			return nil, err
+		}
+		return target.Interface(), nil
+	}
+
+	target := reflect.New(payloadType)
+	if err := json.Unmarshal([]byte(payload), target.Interface()); err != nil {
+		return nil, err
+	}
+	return target.Elem().Interface(), nil
+}
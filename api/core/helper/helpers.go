@@ -1,14 +1,12 @@
 package helper
 
 import (
-	"base/core/config"
 	"base/core/types"
 	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/gertd/go-pluralize"
-	"github.com/golang-jwt/jwt/v5"
 	"gorm.io/gorm"
 )
 
@@ -23,24 +21,20 @@ func GenerateJWT(userId uint) (string, error) {
 	return types.GenerateJWT(userId, nil)
 }
 
+// ValidateJWT verifies a token and returns its claims and user id. Revoked
+// tokens are rejected.
 func ValidateJWT(tokenString string) (any, uint, error) {
-	cfg := config.NewConfig()
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		return []byte(cfg.JWTSecret), nil
-	})
-
+	claims, err := types.ParseJWT(tokenString)
 	if err != nil {
 		return 0, 0, err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userId := uint(claims["user_id"].(float64))
-
-		return nil, userId, nil
+	if types.IsTokenRevoked(claims) {
+		return nil, 0, types.ErrTokenRevoked
 	}
+	userId := uint(claims["user_id"].(float64))
 
-	return nil, 0, jwt.ErrSignatureInvalid
+	return claims, userId, nil
 }
 
 // ModelRegistry holds registered model constructors for dynamic object retrieval
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Redacted replaces sensitive values in log output
+const Redacted = "[REDACTED]"
+
+// sensitiveKeyParts mark a key as holding a secret when it contains any of
+// them. Database URLs are included because DSNs embed the password.
+var sensitiveKeyParts = []string{"SECRET", "PASSWORD", "KEY", "TOKEN", "DB_URL", "DB_READ_URL"}
+
+// IsSensitiveKey reports whether a key such as an environment variable name
+// or a struct field name looks like it holds a secret. Underscores are
+// ignored, so DB_URL also matches DBURL.
+func IsSensitiveKey(key string) bool {
+	upper := strings.ReplaceAll(strings.ToUpper(key), "_", "")
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(upper, strings.ReplaceAll(part, "_", "")) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mask returns the placeholder for a secret value. An empty value stays
+// empty so unset secrets remain visible.
+func Mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	return Redacted
+}
+
+// RedactValue masks value when key is sensitive
+func RedactValue(key, value string) string {
+	if IsSensitiveKey(key) {
+		return Mask(value)
+	}
+	return value
+}
+
+// Secret is a field whose value is always masked
+func Secret(key string, value string) Field {
+	return zap.String(key, Mask(value))
+}
+
+// secretPatterns find secrets embedded in free text: key=value and key: value
+// pairs with a sensitive key, bearer credentials and URL user info
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b([\w-]*(?:secret|password|passwd|pwd|key|token)[\w-]*)(["']?\s*[:=]\s*["']?)[^\s"'&,;]+`),
+	regexp.MustCompile(`(?i)\b(bearer|basic)(\s+)[\w\-.~+/=]+`),
+	regexp.MustCompile(`(://[^:/@\s]+)(:)[^@\s]+@`),
+}
+
+// RedactText masks the secrets found in free text such as an error message,
+// which may quote a DSN, a query string or a header
+func RedactText(text string) string {
+	for i, pattern := range secretPatterns {
+		replacement := "${1}${2}" + Redacted
+		if i == len(secretPatterns)-1 {
+			replacement += "@"
+		}
+		text = pattern.ReplaceAllString(text, replacement)
+	}
+	return text
+}
@@ -72,7 +72,7 @@ func (co *CoreOrchestrator) initializeCoreModules(modules map[string]Module, dep
 
 		// Migrate
 		if migrator, ok := mod.(interface{ Migrate() error }); ok {
-			if err := migrator.Migrate(); err != nil {
+			if err := RunMigration(deps.DB, name, mod.GetModels(), MigrationSourceStartup, migrator.Migrate); err != nil {
 				deps.Logger.Error("Failed to migrate core module",
 					logger.String("module", name),
 					logger.String("error", err.Error()))
@@ -7,6 +7,7 @@ import (
 	"base/core/logger"
 	"base/core/router"
 	"base/core/storage"
+	"base/core/websocket"
 
 	"gorm.io/gorm"
 )
@@ -20,6 +21,7 @@ type Dependencies struct {
 	Storage     *storage.ActiveStorage
 	EmailSender email.Sender
 	Config      *config.Config
+	WSHub       *websocket.Hub // nil when WebSockets are disabled
 }
 
 // Initializer handles module initialization logic
@@ -61,7 +63,7 @@ func (mi *Initializer) Initialize(modules map[string]Module, deps Dependencies)
 
 		// Migrate
 		if migrator, ok := mod.(interface{ Migrate() error }); ok {
-			if err := migrator.Migrate(); err != nil {
+			if err := RunMigration(deps.DB, name, mod.GetModels(), MigrationSourceStartup, migrator.Migrate); err != nil {
 				mi.logger.Error("Failed to migrate module",
 					logger.String("module", name),
 					logger.String("error", err.Error()))
@@ -0,0 +1,76 @@
+package module
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Where a migration run was started from
+const (
+	MigrationSourceStartup = "startup"
+	MigrationSourceCLI     = "cli"
+)
+
+// MigrationRun records one migration of a module, so operators can see what
+// ran, when and whether it succeeded
+type MigrationRun struct {
+	Id         uint      `gorm:"column:id;primary_key;auto_increment" json:"id"`
+	Module     string    `gorm:"column:module;size:100;not null;index" json:"module"`
+	Tables     string    `gorm:"column:tables;type:text" json:"tables"` // Comma-separated tables of the module's models
+	Source     string    `gorm:"column:source;size:16" json:"source"`
+	Success    bool      `gorm:"column:success" json:"success"`
+	Error      string    `gorm:"column:error;type:text" json:"error,omitempty"`
+	DurationMs int64     `gorm:"column:duration_ms" json:"duration_ms"`
+	RanAt      time.Time `gorm:"column:ran_at;index" json:"ran_at"`
+}
+
+func (MigrationRun) TableName() string {
+	return "migration_runs"
+}
+
+// EnsureMigrationTable creates the migration_runs table if needed. Run it
+// before any module migrates so the runs can be recorded.
+func EnsureMigrationTable(db *gorm.DB) error {
+	return db.AutoMigrate(&MigrationRun{})
+}
+
+// RunMigration runs migrate for the named module and records the run. The
+// record is best effort: failing to write it does not fail the migration.
+func RunMigration(db *gorm.DB, name string, models []any, source string, migrate func() error) error {
+	start := time.Now()
+	err := migrate()
+
+	if db != nil {
+		run := MigrationRun{
+			Module:     name,
+			Tables:     strings.Join(TableNames(db, models), ","),
+			Source:     source,
+			Success:    err == nil,
+			DurationMs: time.Since(start).Milliseconds(),
+			RanAt:      start,
+		}
+		if err != nil {
+			run.Error = err.Error()
+		}
+		db.Create(&run)
+	}
+
+	return err
+}
+
+// TableNames returns the table of each model
+func TableNames(db *gorm.DB, models []any) []string {
+	tables := make([]string, 0, len(models))
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			tables = append(tables, fmt.Sprintf("%T", model))
+			continue
+		}
+		tables = append(tables, stmt.Table)
+	}
+	return tables
+}
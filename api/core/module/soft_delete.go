@@ -0,0 +1,199 @@
+package module
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"base/core/router"
+	"base/core/types"
+
+	"gorm.io/gorm"
+)
+
+// Soft delete errors
+var (
+	ErrRecordNotFound = errors.New("record not found")
+	ErrNotInTrash     = errors.New("record not found in trash")
+)
+
+// SoftDeletable gives a module trash and restore for a model T with a
+// DeletedAt gorm.DeletedAt field in the deleted_at column. GORM already
+// hides soft-deleted rows from ordinary queries; SoftDeletable adds the
+// operations on the trashed ones and the routes for them.
+//
+// Modules embed it in their controller and call TrashRoutes:
+//
+//	type ItemController struct {
+//		*module.SoftDeletable[Item]
+//	}
+//
+//	c.TrashRoutes(router.Group("/items"))
+type SoftDeletable[T any] struct {
+	DB *gorm.DB
+	// Present converts a trashed row for ListTrashed; nil returns the rows as is
+	Present func(*T) any
+}
+
+// NewSoftDeletable creates the trash helper for model T
+func NewSoftDeletable[T any](db *gorm.DB, present func(*T) any) *SoftDeletable[T] {
+	return &SoftDeletable[T]{
+		DB:      db,
+		Present: present,
+	}
+}
+
+// SoftDelete moves a record to the trash
+func (s *SoftDeletable[T]) SoftDelete(id uint) error {
+	result := s.DB.Delete(new(T), id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// Restore takes a record out of the trash
+func (s *SoftDeletable[T]) Restore(id uint) error {
+	item, err := s.trashed(id)
+	if err != nil {
+		return err
+	}
+	return s.DB.Unscoped().Model(item).Update("deleted_at", nil).Error
+}
+
+// ListTrashed returns a page of trashed records, most recently deleted first
+func (s *SoftDeletable[T]) ListTrashed(page, limit *int) (*types.PaginatedResponse, error) {
+	currentPage, pageSize := types.NormalizePagination(page, limit)
+
+	query := s.DB.Unscoped().Model(new(T)).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var items []*T
+	offset := (currentPage - 1) * pageSize
+	if err := query.Order("deleted_at DESC").Offset(offset).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	var data any = items
+	if s.Present != nil {
+		responses := make([]any, len(items))
+		for i, item := range items {
+			responses[i] = s.Present(item)
+		}
+		data = responses
+	}
+
+	return &types.PaginatedResponse{
+		Data: data,
+		Pagination: types.Pagination{
+			Total:      int(total),
+			Page:       currentPage,
+			PageSize:   pageSize,
+			TotalPages: int(total+int64(pageSize)-1) / pageSize,
+		},
+	}, nil
+}
+
+// Purge permanently deletes a trashed record. Records that are not in the
+// trash are left alone, so nothing is lost without being trashed first.
+func (s *SoftDeletable[T]) Purge(id uint) error {
+	item, err := s.trashed(id)
+	if err != nil {
+		return err
+	}
+	return s.DB.Unscoped().Delete(item).Error
+}
+
+func (s *SoftDeletable[T]) trashed(id uint) (*T, error) {
+	item := new(T)
+	err := s.DB.Unscoped().Where("deleted_at IS NOT NULL").First(item, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotInTrash
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// TrashRoutes registers the trash endpoints on the module's group:
+//
+//	GET    /trash               trashed records, paginated with page and limit
+//	POST   /trash/:id/restore   restore a trashed record
+//	DELETE /trash/:id           permanently delete a trashed record
+//
+// Keeping them under /trash leaves the module's own routes free of
+// conflicts, as long as TrashRoutes is called before the module registers
+// its /:id routes on the same group. Moving a record to the trash stays with
+// the module's DELETE route, or HandleSoftDelete for modules without one.
+func (s *SoftDeletable[T]) TrashRoutes(group *router.RouterGroup) {
+	group.GET("/trash", s.HandleListTrashed)
+	group.POST("/trash/:id/restore", s.HandleRestore)
+	group.DELETE("/trash/:id", s.HandlePurge)
+}
+
+// HandleSoftDelete moves the record :id to the trash
+func (s *SoftDeletable[T]) HandleSoftDelete(ctx *router.Context) error {
+	return s.handle(ctx, s.SoftDelete)
+}
+
+// HandleRestore restores the trashed record :id
+func (s *SoftDeletable[T]) HandleRestore(ctx *router.Context) error {
+	return s.handle(ctx, s.Restore)
+}
+
+// HandlePurge permanently deletes the trashed record :id
+func (s *SoftDeletable[T]) HandlePurge(ctx *router.Context) error {
+	return s.handle(ctx, s.Purge)
+}
+
+// HandleListTrashed lists the trashed records
+func (s *SoftDeletable[T]) HandleListTrashed(ctx *router.Context) error {
+	var page, limit *int
+	if pageStr := ctx.Query("page"); pageStr != "" {
+		pageNum, err := strconv.Atoi(pageStr)
+		if err != nil || pageNum < 1 {
+			return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid page number"})
+		}
+		page = &pageNum
+	}
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		limitNum, err := strconv.Atoi(limitStr)
+		if err != nil || limitNum < 1 {
+			return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid limit number"})
+		}
+		limit = &limitNum
+	}
+
+	response, err := s.ListTrashed(page, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to fetch trash"})
+	}
+	response.AddLinks(ctx.Request.URL)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (s *SoftDeletable[T]) handle(ctx *router.Context, action func(uint) error) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid ID"})
+	}
+
+	if err := action(uint(id)); err != nil {
+		if errors.Is(err, ErrRecordNotFound) || errors.Is(err, ErrNotInTrash) {
+			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{Error: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to update the record"})
+	}
+
+	ctx.Status(http.StatusNoContent)
+	return nil
+}
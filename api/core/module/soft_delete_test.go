@@ -0,0 +1,175 @@
+package module
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"base/core/router"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// note is a sample model with soft deletes
+type note struct {
+	Id        uint `gorm:"primarykey"`
+	Text      string
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func newNoteTrash(t *testing.T) (*SoftDeletable[note], []*note) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(&note{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	notes := []*note{{Text: "first"}, {Text: "second"}, {Text: "third"}}
+	for _, n := range notes {
+		if err := db.Create(n).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewSoftDeletable[note](db, nil), notes
+}
+
+func visible(t *testing.T, s *SoftDeletable[note]) int64 {
+	t.Helper()
+	var count int64
+	if err := s.DB.Model(&note{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestSoftDeleteLifecycle(t *testing.T) {
+	s, notes := newNoteTrash(t)
+	first, second := notes[0], notes[1]
+
+	if err := s.SoftDelete(first.Id); err != nil {
+		t.Fatalf("SoftDelete: %v", err)
+	}
+	if err := s.SoftDelete(second.Id); err != nil {
+		t.Fatalf("SoftDelete: %v", err)
+	}
+	if n := visible(t, s); n != 1 {
+		t.Errorf("%d notes visible after trashing two of three, want 1", n)
+	}
+	if err := s.SoftDelete(first.Id); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("trashing twice: got %v, want ErrRecordNotFound", err)
+	}
+
+	trash, err := s.ListTrashed(nil, nil)
+	if err != nil {
+		t.Fatalf("ListTrashed: %v", err)
+	}
+	if items := trash.Data.([]*note); len(items) != 2 || trash.Pagination.Total != 2 {
+		t.Errorf("trash holds %d of %d notes, want 2", len(items), trash.Pagination.Total)
+	}
+
+	// Only trashed records can be restored or purged
+	if err := s.Restore(notes[2].Id); !errors.Is(err, ErrNotInTrash) {
+		t.Errorf("restoring a live note: got %v, want ErrNotInTrash", err)
+	}
+	if err := s.Purge(notes[2].Id); !errors.Is(err, ErrNotInTrash) {
+		t.Errorf("purging a live note: got %v, want ErrNotInTrash", err)
+	}
+
+	if err := s.Restore(first.Id); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	var restored note
+	if err := s.DB.First(&restored, first.Id).Error; err != nil || restored.Text != "first" {
+		t.Errorf("restored note = %+v, %v", restored, err)
+	}
+
+	if err := s.Purge(second.Id); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	var count int64
+	s.DB.Unscoped().Model(&note{}).Where("id = ?", second.Id).Count(&count)
+	if count != 0 {
+		t.Error("purged note is still in the table")
+	}
+	if err := s.Restore(second.Id); !errors.Is(err, ErrNotInTrash) {
+		t.Errorf("restoring a purged note: got %v, want ErrNotInTrash", err)
+	}
+
+	trash, _ = s.ListTrashed(nil, nil)
+	if trash.Pagination.Total != 0 {
+		t.Errorf("trash holds %d notes at the end, want 0", trash.Pagination.Total)
+	}
+}
+
+func TestTrashRoutes(t *testing.T) {
+	s, notes := newNoteTrash(t)
+	s.Present = func(n *note) any { return map[string]string{"text": n.Text} }
+
+	r := router.New()
+	group := r.Group("/api/notes")
+	// Before /:id, like the parameterized routes of every module
+	s.TrashRoutes(group)
+	group.DELETE("/:id", s.HandleSoftDelete)
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	for _, n := range notes[:2] {
+		if w := do(http.MethodDelete, "/api/notes/"+itoa(n.Id)); w.Code != http.StatusNoContent {
+			t.Fatalf("DELETE note %d: status %d: %s", n.Id, w.Code, w.Body)
+		}
+	}
+
+	w := do(http.MethodGet, "/api/notes/trash?limit=1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET trash: status %d: %s", w.Code, w.Body)
+	}
+	var response struct {
+		Data       []map[string]string `json:"data"`
+		Pagination struct {
+			Total int `json:"total"`
+		} `json:"pagination"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Data) != 1 || response.Data[0]["text"] == "" || response.Pagination.Total != 2 {
+		t.Errorf("trash page = %s", w.Body)
+	}
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodPost, "/api/notes/trash/" + itoa(notes[0].Id) + "/restore", http.StatusNoContent},
+		{http.MethodPost, "/api/notes/trash/" + itoa(notes[0].Id) + "/restore", http.StatusNotFound},
+		{http.MethodDelete, "/api/notes/trash/" + itoa(notes[1].Id), http.StatusNoContent},
+		{http.MethodDelete, "/api/notes/trash/" + itoa(notes[2].Id), http.StatusNotFound},
+		{http.MethodDelete, "/api/notes/trash/abc", http.StatusBadRequest},
+		{http.MethodDelete, "/api/notes/999", http.StatusNotFound},
+		{http.MethodGet, "/api/notes/trash?page=0", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if w := do(tt.method, tt.path); w.Code != tt.want {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, w.Code, tt.want)
+		}
+	}
+	if n := visible(t, s); n != 2 {
+		t.Errorf("%d notes visible at the end, want 2", n)
+	}
+}
+
+func itoa(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
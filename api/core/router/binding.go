@@ -0,0 +1,95 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"base/core/types"
+)
+
+// DefaultMaxJSONBodySize is the largest JSON body BindJSON reads unless the
+// router sets another limit
+const DefaultMaxJSONBodySize int64 = 1 << 20 // 1MB
+
+// SetJSONBinding configures BindJSON for every request: bodies above
+// maxBodySize bytes are rejected (0 or less keeps DefaultMaxJSONBodySize), and
+// in strict mode fields the target struct does not declare are rejected
+// instead of ignored, which catches client typos.
+func (r *Router) SetJSONBinding(maxBodySize int64, strict bool) {
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxJSONBodySize
+	}
+	r.maxJSONBodySize = maxBodySize
+	r.strictJSON = strict
+}
+
+// isJSONContentType reports whether a Content-Type header is JSON. A missing
+// header is accepted, as many clients omit it for JSON bodies.
+func isJSONContentType(contentType string) bool {
+	if strings.TrimSpace(contentType) == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// decodeJSONError turns a decoder error into one that tells the client what
+// is wrong with the body. Type mismatches and unknown fields become
+// types.FieldErrors keyed by the field path; an empty body stays io.EOF.
+func decodeJSONError(err error, limit int64) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return err
+	case errors.As(err, &maxBytesErr):
+		return fmt.Errorf("%w: the limit is %d bytes", types.ErrBodyTooLarge, limit)
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("%w: %s at offset %d", types.ErrMalformedJSON, syntaxErr.Error(), syntaxErr.Offset)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("%w: unexpected end of body", types.ErrMalformedJSON)
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			return fmt.Errorf("%w: body must be %s", types.ErrMalformedJSON, jsonTypeName(typeErr.Type.Kind().String()))
+		}
+		return types.FieldErrors{field: "must be " + jsonTypeName(typeErr.Type.Kind().String())}
+	}
+
+	// The decoder has no typed error for unknown fields
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return types.FieldErrors{strings.Trim(name, `"`): "unknown field"}
+	}
+	return fmt.Errorf("%w: %s", types.ErrMalformedJSON, err.Error())
+}
+
+// jsonTypeName names a Go kind the way a JSON client would know it
+func jsonTypeName(kind string) string {
+	switch kind {
+	case "bool":
+		return "a boolean"
+	case "string":
+		return "a string"
+	case "slice", "array":
+		return "an array"
+	case "map", "struct":
+		return "an object"
+	case "float32", "float64":
+		return "a number"
+	case "int", "int8", "int16", "int32", "int64":
+		return "an integer"
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		return "a non-negative integer"
+	}
+	return "a valid value"
+}
@@ -0,0 +1,121 @@
+package router
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"base/core/types"
+)
+
+type bindTarget struct {
+	Name    string `json:"name"`
+	Age     int    `json:"age"`
+	Address struct {
+		Zip int `json:"zip"`
+	} `json:"address"`
+}
+
+// bindJSON posts body to a router with the given JSON binding and returns
+// what BindJSON returned
+func bindJSON(t *testing.T, maxBodySize int64, strict bool, contentType, body string) (*bindTarget, error) {
+	t.Helper()
+
+	var target bindTarget
+	var bindErr error
+	r := New()
+	r.SetJSONBinding(maxBodySize, strict)
+	r.POST("/bind", func(c *Context) error {
+		bindErr = c.BindJSON(&target)
+		return c.NoContent()
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/bind", strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	return &target, bindErr
+}
+
+func TestBindJSONRejectsWrongContentType(t *testing.T) {
+	for _, contentType := range []string{"application/x-www-form-urlencoded", "text/plain", "multipart/form-data; boundary=x", "not a media type;"} {
+		_, err := bindJSON(t, 0, false, contentType, `{"name":"ada"}`)
+		if !errors.Is(err, types.ErrUnsupportedContentType) {
+			t.Errorf("%s: got %v, want ErrUnsupportedContentType", contentType, err)
+		}
+	}
+
+	for _, contentType := range []string{"", "application/json", "application/json; charset=utf-8", "application/merge-patch+json"} {
+		target, err := bindJSON(t, 0, false, contentType, `{"name":"ada"}`)
+		if err != nil || target.Name != "ada" {
+			t.Errorf("%q: got %+v, %v; want the body bound", contentType, target, err)
+		}
+	}
+}
+
+func TestBindJSONRejectsOversizedBody(t *testing.T) {
+	body := `{"name":"` + strings.Repeat("a", 100) + `"}`
+
+	_, err := bindJSON(t, 64, false, "application/json", body)
+	if !errors.Is(err, types.ErrBodyTooLarge) {
+		t.Fatalf("got %v, want ErrBodyTooLarge", err)
+	}
+	if !strings.Contains(err.Error(), "64 bytes") {
+		t.Errorf("error %q does not name the limit", err)
+	}
+	if response := types.NewBindErrorResponse(err, "Invalid request"); response.Error != err.Error() {
+		t.Errorf("response error %q, want the size error", response.Error)
+	}
+
+	if _, err := bindJSON(t, 1024, false, "application/json", body); err != nil {
+		t.Errorf("body under the limit: %v", err)
+	}
+	// A non-positive limit keeps the default
+	if _, err := bindJSON(t, 0, false, "application/json", body); err != nil {
+		t.Errorf("default limit: %v", err)
+	}
+	large := `{"name":"` + strings.Repeat("a", int(DefaultMaxJSONBodySize)) + `"}`
+	if _, err := bindJSON(t, -1, false, "application/json", large); !errors.Is(err, types.ErrBodyTooLarge) {
+		t.Errorf("body over the default limit: got %v, want ErrBodyTooLarge", err)
+	}
+}
+
+func TestBindJSONErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		strict bool
+		body   string
+		field  string // Expected key of a types.FieldErrors
+		want   error  // Expected sentinel otherwise
+	}{
+		{"wrong type", false, `{"age":"ten"}`, "age", nil},
+		{"nested wrong type", false, `{"address":{"zip":"x"}}`, "address.zip", nil},
+		{"unknown field in strict mode", true, `{"nmae":"ada"}`, "nmae", nil},
+		{"syntax error", false, `{"name":}`, "", types.ErrMalformedJSON},
+		{"truncated body", false, `{"name":"ada"`, "", types.ErrMalformedJSON},
+		{"not an object", false, `[1,2]`, "", types.ErrMalformedJSON},
+		{"empty body", false, ``, "", io.EOF},
+	}
+	for _, tt := range tests {
+		_, err := bindJSON(t, 0, tt.strict, "application/json", tt.body)
+		if tt.field != "" {
+			var fields types.FieldErrors
+			if !errors.As(err, &fields) || fields[tt.field] == "" {
+				t.Errorf("%s: got %v, want an error for field %s", tt.name, err, tt.field)
+			}
+			continue
+		}
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// Unknown fields are ignored outside strict mode
+	if target, err := bindJSON(t, 0, false, "application/json", `{"name":"ada","nmae":"x"}`); err != nil || target.Name != "ada" {
+		t.Errorf("lenient mode: got %+v, %v", target, err)
+	}
+}
@@ -8,10 +8,13 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"base/core/types"
 )
 
 // Context represents the context of an HTTP request
@@ -23,6 +26,11 @@ type Context struct {
 	mu       sync.RWMutex
 	index    int8
 	handlers []HandlerFunc
+
+	trustedProxies  []*net.IPNet
+	maxJSONBodySize int64
+	strictJSON      bool
+	localizer       JSONLocalizer
 }
 
 // Param represents a URL parameter
@@ -145,6 +153,12 @@ func (c *Context) MultipartForm() (*multipart.Form, error) {
 	return c.Request.MultipartForm, err
 }
 
+// MultipartReader returns a reader over the parts of a multipart request
+// body, for streaming uploads instead of parsing the whole form first
+func (c *Context) MultipartReader() (*multipart.Reader, error) {
+	return c.Request.MultipartReader()
+}
+
 // Header returns the request header value
 func (c *Context) Header(key string) string {
 	return c.Request.Header.Get(key)
@@ -204,18 +218,42 @@ func (c *Context) Bind(obj any) error {
 	}
 }
 
-// BindJSON binds the request body as JSON to a struct
+// BindJSON binds the request body as JSON to a struct. Bodies with another
+// Content-Type fail with types.ErrUnsupportedContentType and bodies over the
+// router's size limit with types.ErrBodyTooLarge. Type mismatches, and
+// unknown fields in strict mode, are returned as types.FieldErrors; other
+// malformed bodies as types.ErrMalformedJSON. An empty body returns io.EOF.
 func (c *Context) BindJSON(obj any) error {
 	if c.Request.Body == nil {
 		return fmt.Errorf("request body is nil")
 	}
+	if contentType := c.GetHeader("Content-Type"); !isJSONContentType(contentType) {
+		return fmt.Errorf("%w, got %s", types.ErrUnsupportedContentType, contentType)
+	}
+
+	limit := c.maxJSONBodySize
+	if limit <= 0 {
+		limit = DefaultMaxJSONBodySize
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
 	decoder := json.NewDecoder(c.Request.Body)
-	return decoder.Decode(obj)
+	if c.strictJSON {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(obj); err != nil {
+		return decodeJSONError(err, limit)
+	}
+	return nil
 }
 
-// ShouldBindJSON binds the request body as JSON to a struct with validation
+// ShouldBindJSON binds the request body as JSON to a struct with validation.
+// Validation failures are returned as types.FieldErrors.
 func (c *Context) ShouldBindJSON(obj any) error {
-	return c.BindJSON(obj)
+	if err := c.BindJSON(obj); err != nil {
+		return err
+	}
+	return Validate(obj)
 }
 
 // BindQuery binds the query parameters to a struct
@@ -234,14 +272,76 @@ func (c *Context) BindForm(obj any) error {
 	return bindData(obj, c.Request.Form)
 }
 
-// JSON sends a JSON response
+// JSON sends a JSON response, localized for the request's languages when
+// the router has a JSONLocalizer
 func (c *Context) JSON(code int, obj any) error {
+	if c.localizer != nil {
+		if languages := c.Languages(); len(languages) > 0 {
+			obj = c.localizer(obj, languages)
+		}
+	}
 	c.SetHeader("Content-Type", "application/json")
 	c.Writer.WriteHeader(code)
 	encoder := json.NewEncoder(c.Writer)
 	return encoder.Encode(obj)
 }
 
+// XML sends an XML response. Fields use their xml tag when present and fall
+// back to the json tag name otherwise; maps and slices are supported.
+func (c *Context) XML(code int, obj any) error {
+	c.SetHeader("Content-Type", "application/xml; charset=utf-8")
+	c.Writer.WriteHeader(code)
+	return encodeXML(c.Writer, obj)
+}
+
+// Negotiate sends obj as XML or JSON depending on the Accept header, defaulting
+// to JSON when the client accepts both equally or names neither
+func (c *Context) Negotiate(code int, obj any) error {
+	if c.acceptsXML() {
+		return c.XML(code, obj)
+	}
+	return c.JSON(code, obj)
+}
+
+// acceptsXML reports whether the Accept header prefers XML over JSON
+func (c *Context) acceptsXML() bool {
+	return c.acceptQuality("application/xml", "text/xml") > c.acceptQuality(jsonMediaTypes...)
+}
+
+// PrefersHTML reports whether the Accept header prefers HTML over JSON, as
+// browsers navigating to a page do
+func (c *Context) PrefersHTML() bool {
+	return c.acceptQuality("text/html", "application/xhtml+xml") > c.acceptQuality(jsonMediaTypes...)
+}
+
+// jsonMediaTypes are the Accept entries satisfied by a JSON response
+var jsonMediaTypes = []string{"application/json", "*/*", "application/*"}
+
+// acceptQuality returns the highest quality the Accept header gives any of
+// mediaTypes, 0 when it names none of them
+func (c *Context) acceptQuality(mediaTypes ...string) float64 {
+	best := 0.0
+
+	for _, part := range strings.Split(c.Header("Accept"), ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !slices.Contains(mediaTypes, strings.ToLower(strings.TrimSpace(mediaType))) {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		best = max(best, quality)
+	}
+
+	return best
+}
+
 // String sends a string response
 func (c *Context) String(code int, format string, values ...any) error {
 	c.SetHeader("Content-Type", "text/plain")
@@ -301,27 +401,32 @@ func (c *Context) NoContent() error {
 	return nil
 }
 
-// ClientIP returns the client's IP address
+// ClientIP returns the client's IP address. X-Forwarded-For and X-Real-IP
+// are only honoured when the connecting peer is a trusted proxy (see
+// Router.SetTrustedProxies); otherwise the peer address is returned.
 func (c *Context) ClientIP() string {
-	// Check X-Forwarded-For header
+	remote := c.Request.RemoteAddr
+	if ip, _, err := net.SplitHostPort(remote); err == nil {
+		remote = ip
+	}
+
+	remoteIP := net.ParseIP(remote)
+	if remoteIP == nil || !isTrustedProxy(c.trustedProxies, remoteIP) {
+		return remote
+	}
+
 	if xff := c.Header("X-Forwarded-For"); xff != "" {
-		if i := strings.Index(xff, ","); i != -1 {
-			return strings.TrimSpace(xff[:i])
+		if ip, ok := forwardedClientIP(c.trustedProxies, xff); ok {
+			return ip
 		}
-		return xff
+		return remote
 	}
 
-	// Check X-Real-IP header
-	if xri := c.Header("X-Real-IP"); xri != "" {
+	if xri := strings.TrimSpace(c.Header("X-Real-IP")); net.ParseIP(xri) != nil {
 		return xri
 	}
 
-	// Fall back to RemoteAddr
-	if ip, _, err := net.SplitHostPort(c.Request.RemoteAddr); err == nil {
-		return ip
-	}
-
-	return c.Request.RemoteAddr
+	return remote
 }
 
 // ContentType returns the Content-Type header of the request
@@ -360,6 +465,14 @@ func (c *Context) IsAborted() bool {
 	return c.index >= int8(len(c.handlers))
 }
 
+// GetString returns a string value from context, empty when it is missing
+// or not a string
+func (c *Context) GetString(key string) string {
+	value, _ := c.Get(key)
+	s, _ := value.(string)
+	return s
+}
+
 // GetUint returns a uint value from context
 func (c *Context) GetUint(key string) uint {
 	value, exists := c.Get(key)
@@ -0,0 +1,46 @@
+package router
+
+// UserLoader loads the authenticated user of a request, returning nil for
+// anonymous requests
+type UserLoader func(c *Context) (any, error)
+
+const (
+	userLoaderKey  = "current_user_loader"
+	currentUserKey = "current_user"
+)
+
+// currentUser caches the loaded user, including a nil result
+type currentUser struct {
+	user any
+}
+
+// SetUserLoader sets how CurrentUser loads the authenticated user. The
+// loader runs at most once per request, on the first CurrentUser call.
+func (c *Context) SetUserLoader(loader UserLoader) {
+	c.Set(userLoaderKey, loader)
+}
+
+// CurrentUser returns the authenticated user, loading it on first use and
+// caching it for the rest of the request. It returns nil for anonymous
+// requests, when loading fails, or when no loader was set.
+func (c *Context) CurrentUser() any {
+	if cached, ok := c.Get(currentUserKey); ok {
+		return cached.(currentUser).user
+	}
+
+	value, ok := c.Get(userLoaderKey)
+	if !ok {
+		return nil
+	}
+	loader, ok := value.(UserLoader)
+	if !ok {
+		return nil
+	}
+
+	user, err := loader(c)
+	if err != nil {
+		user = nil
+	}
+	c.Set(currentUserKey, currentUser{user: user})
+	return user
+}
@@ -0,0 +1,65 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCurrentUserLoadsOncePerRequest(t *testing.T) {
+	calls := 0
+	loader := func(c *Context) (any, error) {
+		calls++
+		if c.Request.Header.Get("X-User") == "" {
+			return nil, nil
+		}
+		return c.Request.Header.Get("X-User"), nil
+	}
+
+	var got []any
+	r := New()
+	r.GET("/me", func(c *Context) error {
+		c.SetUserLoader(loader)
+		got = append(got, c.CurrentUser(), c.CurrentUser())
+		return c.String(http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("X-User", "ada")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if got[0] != "ada" || got[1] != "ada" {
+		t.Errorf("CurrentUser = %v, want ada twice", got)
+	}
+	if calls != 1 {
+		t.Errorf("loader ran %d times for one request, want once", calls)
+	}
+
+	// A nil user is cached too
+	got, calls = nil, 0
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/me", nil))
+	if got[0] != nil || got[1] != nil || calls != 1 {
+		t.Errorf("anonymous request: CurrentUser = %v after %d loads, want nil after 1", got, calls)
+	}
+}
+
+func TestCurrentUserWithoutLoaderOrOnError(t *testing.T) {
+	var got []any
+	r := New()
+	r.GET("/none", func(c *Context) error {
+		got = append(got, c.CurrentUser())
+		return nil
+	})
+	r.GET("/failing", func(c *Context) error {
+		c.SetUserLoader(func(*Context) (any, error) { return "partial", errors.New("database down") })
+		got = append(got, c.CurrentUser())
+		return nil
+	})
+
+	for _, path := range []string{"/none", "/failing"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	if len(got) != 2 || got[0] != nil || got[1] != nil {
+		t.Errorf("CurrentUser without a loader and after a failed load = %v, want nil both times", got)
+	}
+}
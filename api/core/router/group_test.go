@@ -0,0 +1,78 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// trace returns a middleware appending name to the X-Trace response header
+func trace(name string) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Writer.Header().Add("X-Trace", name)
+			return next(c)
+		}
+	}
+}
+
+func serve(t *testing.T, r *Router, path string) []string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d, want 200", path, w.Code)
+	}
+	return w.Header().Values("X-Trace")
+}
+
+func TestGroupMiddlewareRunsForChildRoutesOnly(t *testing.T) {
+	r := New()
+	r.Use(trace("global"))
+
+	ok := func(c *Context) error { return c.String(http.StatusOK, "ok") }
+
+	api := r.Group("/api", trace("api"))
+	admin := api.Group("/admin", trace("admin"))
+	admin.GET("/users", ok, trace("route"))
+	admin.Group("/reports", trace("reports")).GET("/daily", ok)
+	api.GET("/public", ok)
+	api.Group("/other").GET("/ping", ok)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/api/admin/users", []string{"global", "api", "admin", "route"}},
+		{"/api/admin/reports/daily", []string{"global", "api", "admin", "reports"}},
+		{"/api/public", []string{"global", "api"}},
+		{"/api/other/ping", []string{"global", "api"}},
+	}
+	for _, tt := range tests {
+		if got := serve(t, r, tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GET %s ran %s, want %s", tt.path, strings.Join(got, ","), strings.Join(tt.want, ","))
+		}
+	}
+}
+
+func TestSiblingGroupsDoNotShareMiddleware(t *testing.T) {
+	r := New()
+	ok := func(c *Context) error { return c.String(http.StatusOK, "ok") }
+
+	// Sub-groups of a group with spare slice capacity must not overwrite
+	// each other's middleware
+	parent := r.Group("/p", trace("a"), trace("b"), trace("c"))
+	first := parent.Group("/one", trace("one"))
+	second := parent.Group("/two", trace("two"))
+	first.GET("/x", ok)
+	second.GET("/x", ok)
+
+	if got, want := serve(t, r, "/p/one/x"), []string{"a", "b", "c", "one"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("first group ran %v, want %v", got, want)
+	}
+	if got, want := serve(t, r, "/p/two/x"), []string{"a", "b", "c", "two"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("second group ran %v, want %v", got, want)
+	}
+}
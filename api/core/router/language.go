@@ -0,0 +1,74 @@
+package router
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LanguagesKey is the context key of the language preference list set by
+// middleware.Language
+const LanguagesKey = "languages"
+
+// JSONLocalizer rewrites a response body for a language preference list,
+// most preferred first, before JSON encodes it
+type JSONLocalizer func(obj any, languages []string) any
+
+// SetJSONLocalizer makes JSON pass every body through localize when the
+// request has a language preference list. nil disables localization.
+func (r *Router) SetJSONLocalizer(localize JSONLocalizer) {
+	r.localizer = localize
+}
+
+// Languages returns the languages the client prefers, most preferred first,
+// or nil when no language middleware ran or the client named none
+func (c *Context) Languages() []string {
+	if value, exists := c.Get(LanguagesKey); exists {
+		if languages, ok := value.([]string); ok {
+			return languages
+		}
+	}
+	return nil
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header
+// ordered by quality, in header order for equal qualities. Tags with q=0 and
+// the * wildcard are left out, and tags are lower-cased.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].quality > tags[j].quality
+	})
+
+	languages := make([]string, 0, len(tags))
+	for _, t := range tags {
+		languages = append(languages, t.tag)
+	}
+	return languages
+}
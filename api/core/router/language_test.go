@@ -0,0 +1,24 @@
+package router
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"de", []string{"de"}},
+		{"fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5", []string{"fr-ch", "fr", "en", "de"}},
+		{"en;q=0.5, SQ, de;q=0.5", []string{"sq", "en", "de"}},
+		{"it;q=0, es;q=bogus", []string{"es"}},
+	}
+	for _, tt := range tests {
+		if got := ParseAcceptLanguage(tt.header); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
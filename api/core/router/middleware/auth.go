@@ -6,7 +6,11 @@ import (
 	"net/http"
 	"strings"
 
+	"base/core/helper"
 	"base/core/router"
+	"base/core/types"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
 // contextKey is an empty struct with a descriptive name tag. Using a
@@ -121,6 +125,11 @@ func Auth(config *AuthConfig) router.MiddlewareFunc {
 			} else if userID, ok := user.(uint64); ok {
 				c.Set("user_id", userID)
 				c.Set(config.Key, userID) // Also store with configured key for backward compatibility
+			} else if identity, ok := user.(ImpersonatedUser); ok {
+				c.Set("user_id", identity.UserId)
+				c.Set(config.Key, identity.UserId)
+				c.Set("impersonated_by", identity.ImpersonatedBy)
+				user = identity.UserId
 			}
 
 			// Also add to request context for deeper layers
@@ -132,6 +141,55 @@ func Auth(config *AuthConfig) router.MiddlewareFunc {
 	}
 }
 
+// ImpersonatedUser is the identity behind an impersonation token. Auth stores
+// UserId as "user_id" like for any token and ImpersonatedBy as
+// "impersonated_by", so handlers and logs can tell who is really acting.
+type ImpersonatedUser struct {
+	UserId         uint
+	ImpersonatedBy uint
+}
+
+// JWTAuthConfig returns an auth configuration that validates bearer JWTs issued
+// by the authentication module, rejecting expired and revoked tokens
+func JWTAuthConfig() *AuthConfig {
+	config := DefaultAuthConfig()
+	config.TokenValidator = func(token string) (any, error) {
+		claims, userID, err := helper.ValidateJWT(token)
+		if err != nil {
+			return userID, err
+		}
+		if mapClaims, ok := claims.(jwt.MapClaims); ok {
+			if by := types.ImpersonatorOf(mapClaims); by != 0 {
+				return ImpersonatedUser{UserId: userID, ImpersonatedBy: by}, nil
+			}
+		}
+		return userID, nil
+	}
+	return config
+}
+
+// Authenticate validates the bearer JWT, stores the user ID as "user_id" and
+// injects authorizationService as "authorization_service" so the authorization
+// middlewares can run after it. Pass nil when no authorization checks follow.
+// Requests without a valid token are rejected with 401.
+func Authenticate(authorizationService any) router.MiddlewareFunc {
+	auth := Auth(JWTAuthConfig())
+
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return auth(func(c *router.Context) error {
+			if c.Request.Method != "OPTIONS" && c.GetUint("user_id") == 0 {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error": "Unauthorized: invalid user",
+				})
+			}
+			if authorizationService != nil {
+				c.Set("authorization_service", authorizationService)
+			}
+			return next(c)
+		})
+	}
+}
+
 // RequireAuth is a simple auth middleware that just checks if user is present
 func RequireAuth(key string) router.MiddlewareFunc {
 	if key == "" {
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"base/core/router"
+	"base/core/types"
+)
+
+func TestAuthenticate(t *testing.T) {
+	t.Setenv("JWT_SECRET", "authenticate-test-secret")
+	service := &struct{}{}
+
+	r := router.New()
+	r.GET("/me", func(c *router.Context) error {
+		if _, ok := c.Get("authorization_service"); !ok {
+			t.Error("authorization service was not injected")
+		}
+		return c.JSON(http.StatusOK, map[string]uint{"user_id": c.GetUint("user_id")})
+	}, Authenticate(service))
+
+	token, err := types.GenerateJWT(7, nil)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"valid token", "Bearer " + token, http.StatusOK},
+		{"missing header", "", http.StatusUnauthorized},
+		{"wrong scheme", "Basic " + token, http.StatusUnauthorized},
+		{"malformed token", "Bearer not-a-jwt", http.StatusUnauthorized},
+		{"tampered token", "Bearer " + token + "x", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"base/core/router"
+)
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+
+	// DefaultCompressionMinSize is the smallest response body that gets compressed
+	DefaultCompressionMinSize = 1024
+)
+
+// incompressibleTypes are content types that are already compressed
+var incompressibleTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+	"application/pdf",
+}
+
+// CompressConfig configures the compression middleware
+type CompressConfig struct {
+	// MinSize is the body size in bytes below which responses are sent as is
+	MinSize int
+	// Level is a compress/flate level; -1 selects the default
+	Level int
+}
+
+// Compress gzip- or deflate-encodes responses for clients that accept it.
+// The body is buffered until MinSize bytes are written, so small responses and
+// responses that are already compressed (images, archives) pass through
+// unchanged. WebSocket upgrades, HEAD and Range requests are not wrapped.
+func Compress(config CompressConfig) router.MiddlewareFunc {
+	if config.MinSize < 0 {
+		config.MinSize = DefaultCompressionMinSize
+	}
+	if config.Level < gzip.HuffmanOnly || config.Level > gzip.BestCompression {
+		config.Level = gzip.DefaultCompression
+	}
+
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			request := c.Request
+			if request.Method == http.MethodHead || request.Header.Get("Range") != "" ||
+				strings.EqualFold(request.Header.Get("Upgrade"), "websocket") {
+				return next(c)
+			}
+
+			c.Writer.Header().Add("Vary", "Accept-Encoding")
+			encoding := negotiateEncoding(request.Header.Get("Accept-Encoding"))
+			if encoding == "" {
+				return next(c)
+			}
+
+			original := c.Writer
+			cw := &compressWriter{
+				ResponseWriter: original,
+				encoding:       encoding,
+				config:         config,
+				status:         http.StatusOK,
+			}
+			c.Writer = cw
+
+			defer func() {
+				c.Writer = original
+				if r := recover(); r != nil {
+					// Buffered output is dropped so recovery can write its own
+					// response; a stream already on the wire is terminated
+					if cw.compressor != nil {
+						cw.compressor.Close()
+					}
+					panic(r)
+				}
+			}()
+
+			err := next(c)
+			if closeErr := cw.Close(); err == nil {
+				err = closeErr
+			}
+			return err
+		}
+	}
+}
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header,
+// preferring gzip when both have the same weight
+func negotiateEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		if name == "*" {
+			name = encodingGzip
+		}
+		if name != encodingGzip && name != encodingDeflate {
+			continue
+		}
+		if q > bestQ || (q == bestQ && name == encodingGzip) {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// compressWriter holds back the status and the first MinSize bytes, then
+// decides once whether the response is worth compressing
+type compressWriter struct {
+	router.ResponseWriter
+	encoding   string
+	config     CompressConfig
+	status     int
+	statusSet  bool
+	buffer     []byte
+	decided    bool
+	compressor io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+	w.statusSet = true
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buffer = append(w.buffer, data...)
+		if len(w.buffer) < w.config.MinSize {
+			return len(data), nil
+		}
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+
+	if w.compressor != nil {
+		return w.compressor.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// decide writes the held-back header, compressing when the buffered body is
+// large enough and of a compressible type, and flushes the buffer
+func (w *compressWriter) decide() error {
+	w.decided = true
+	header := w.Header()
+
+	if header.Get("Content-Type") == "" && len(w.buffer) > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buffer))
+	}
+
+	if len(w.buffer) >= w.config.MinSize && w.compressible() {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", w.encoding)
+
+		var err error
+		if w.encoding == encodingGzip {
+			w.compressor, err = gzip.NewWriterLevel(w.ResponseWriter, w.config.Level)
+		} else {
+			w.compressor, err = zlib.NewWriterLevel(w.ResponseWriter, w.config.Level)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buffered := w.buffer
+	w.buffer = nil
+	if len(buffered) == 0 {
+		return nil
+	}
+	if w.compressor != nil {
+		_, err := w.compressor.Write(buffered)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buffered)
+	return err
+}
+
+func (w *compressWriter) compressible() bool {
+	if w.status < http.StatusOK || w.status == http.StatusNoContent ||
+		w.status == http.StatusNotModified || w.status == http.StatusPartialContent {
+		return false
+	}
+
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+
+	contentType := strings.ToLower(header.Get("Content-Type"))
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// Close sends whatever is still buffered and finishes the compressed stream.
+// A handler that wrote nothing leaves the response untouched, so an error
+// returned from it can still be written by the router.
+func (w *compressWriter) Close() error {
+	if !w.decided {
+		if !w.statusSet && len(w.buffer) == 0 {
+			return nil
+		}
+		if err := w.decide(); err != nil {
+			return err
+		}
+	}
+	if w.compressor != nil {
+		return w.compressor.Close()
+	}
+	return nil
+}
+
+// Flush sends buffered output immediately, so streamed responses keep working
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(); err != nil {
+			return
+		}
+	}
+	if flusher, ok := w.compressor.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Status() int {
+	if !w.decided {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *compressWriter) Written() bool {
+	return w.decided || w.statusSet || w.ResponseWriter.Written()
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Hijack hands the connection over as is; nothing buffered is sent
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decided = true
+	w.buffer = nil
+	return w.ResponseWriter.Hijack()
+}
@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"base/core/logger"
+	"base/core/router"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// largeBody is a compressible body above the default minimum size
+var largeBody = strings.Repeat(`{"player":"anna","score":42},`, 100)
+
+// newCompressRouter serves large, small and image bodies, a file and a
+// panicking handler behind Compress
+func newCompressRouter(t *testing.T, config CompressConfig) *router.Router {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "export.txt")
+	if err := os.WriteFile(file, []byte(largeBody), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := router.New()
+	r.Use(Recovery(logger.NewLoggerFromZap(zap.NewNop())))
+	r.Use(Compress(config))
+	r.GET("/large", func(c *router.Context) error {
+		c.Writer.Header().Set("Content-Length", "2900")
+		return c.Data(http.StatusOK, "application/json", []byte(largeBody))
+	})
+	r.GET("/small", func(c *router.Context) error { return c.String(http.StatusOK, "ok") })
+	r.GET("/image", func(c *router.Context) error {
+		return c.Data(http.StatusOK, "image/png", []byte(largeBody))
+	})
+	r.GET("/file", func(c *router.Context) error {
+		c.File(file)
+		return nil
+	})
+	r.GET("/panic", func(c *router.Context) error {
+		c.String(http.StatusOK, "partial")
+		panic("lost")
+	})
+	return r
+}
+
+func compressRequest(r *router.Router, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// decode returns the body of w decoded per its Content-Encoding
+func decode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var reader io.Reader = w.Body
+	var err error
+	switch w.Header().Get("Content-Encoding") {
+	case "gzip":
+		reader, err = gzip.NewReader(w.Body)
+	case "deflate":
+		reader, err = zlib.NewReader(w.Body)
+	}
+	if err != nil {
+		t.Fatalf("decode %s body: %v", w.Header().Get("Content-Encoding"), err)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return string(body)
+}
+
+func TestCompressNegotiatesEncoding(t *testing.T) {
+	r := newCompressRouter(t, CompressConfig{MinSize: DefaultCompressionMinSize, Level: -1})
+
+	tests := []struct {
+		acceptEncoding, want string
+	}{
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"deflate, gzip", "gzip"},
+		{"gzip;q=0.5, deflate", "deflate"},
+		{"gzip;q=0, deflate;q=0.1", "deflate"},
+		{"gzip;q=0", ""},
+		{"*", "gzip"},
+		{"br", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		w := compressRequest(r, "/large", tt.acceptEncoding)
+		if got := w.Header().Get("Content-Encoding"); got != tt.want {
+			t.Errorf("Accept-Encoding %q: Content-Encoding %q, want %q", tt.acceptEncoding, got, tt.want)
+		}
+		if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("Accept-Encoding %q: Vary %q", tt.acceptEncoding, got)
+		}
+		if tt.want != "" && w.Header().Get("Content-Length") != "" {
+			t.Errorf("Accept-Encoding %q: Content-Length %q kept on a compressed body", tt.acceptEncoding, w.Header().Get("Content-Length"))
+		}
+		if w.Code != http.StatusOK || decode(t, w) != largeBody {
+			t.Errorf("Accept-Encoding %q: status %d, body not the original", tt.acceptEncoding, w.Code)
+		}
+	}
+}
+
+func TestCompressSkipsSmallAndCompressedBodies(t *testing.T) {
+	r := newCompressRouter(t, CompressConfig{MinSize: DefaultCompressionMinSize, Level: -1})
+
+	w := compressRequest(r, "/small", "gzip")
+	if w.Header().Get("Content-Encoding") != "" || w.Body.String() != "ok" {
+		t.Errorf("small body: encoding %q, body %q", w.Header().Get("Content-Encoding"), w.Body)
+	}
+	w = compressRequest(r, "/image", "gzip")
+	if w.Header().Get("Content-Encoding") != "" || w.Body.String() != largeBody || w.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("image: encoding %q, type %q", w.Header().Get("Content-Encoding"), w.Header().Get("Content-Type"))
+	}
+
+	// Lowering the minimum size compresses the small body too
+	r = newCompressRouter(t, CompressConfig{MinSize: 1, Level: gzip.BestSpeed})
+	if w := compressRequest(r, "/small", "gzip"); w.Header().Get("Content-Encoding") != "gzip" || decode(t, w) != "ok" {
+		t.Errorf("small body with MinSize 1: encoding %q", w.Header().Get("Content-Encoding"))
+	}
+}
+
+func TestCompressKeepsFileAndRangeRequests(t *testing.T) {
+	r := newCompressRouter(t, CompressConfig{MinSize: DefaultCompressionMinSize, Level: -1})
+
+	w := compressRequest(r, "/file", "gzip")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Encoding") != "gzip" || decode(t, w) != largeBody {
+		t.Errorf("file: status %d, encoding %q", w.Code, w.Header().Get("Content-Encoding"))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/file", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("Range", "bytes=0-9")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusPartialContent || w.Header().Get("Content-Encoding") != "" || w.Body.String() != largeBody[:10] {
+		t.Errorf("range: status %d, encoding %q, body %q", w.Code, w.Header().Get("Content-Encoding"), w.Body)
+	}
+	if w.Header().Get("Content-Range") != "bytes 0-9/2900" || w.Header().Get("Content-Length") != "10" {
+		t.Errorf("range: Content-Range %q, Content-Length %q", w.Header().Get("Content-Range"), w.Header().Get("Content-Length"))
+	}
+}
+
+func TestCompressDropsBufferedOutputOnPanic(t *testing.T) {
+	r := newCompressRouter(t, CompressConfig{MinSize: DefaultCompressionMinSize, Level: -1})
+
+	w := compressRequest(r, "/panic", "gzip")
+	if w.Code != http.StatusInternalServerError || w.Header().Get("Content-Encoding") != "" {
+		t.Errorf("panic: status %d, encoding %q", w.Code, w.Header().Get("Content-Encoding"))
+	}
+	if strings.Contains(w.Body.String(), "partial") {
+		t.Errorf("the output buffered before the panic was sent: %s", w.Body)
+	}
+}
+
+func TestCompressLeavesWebSocketUpgrades(t *testing.T) {
+	r := router.New()
+	r.Use(Compress(CompressConfig{MinSize: 1, Level: -1}))
+	upgrader := websocket.Upgrader{}
+	r.GET("/ws", func(c *router.Context) error {
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		messageType, message, err := conn.ReadMessage()
+		if err != nil {
+			return nil
+		}
+		return conn.WriteMessage(messageType, message)
+	})
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	header := http.Header{"Accept-Encoding": {"gzip, deflate"}}
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", header)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Content-Encoding") != "" {
+		t.Errorf("upgrade: status %d, encoding %q", resp.StatusCode, resp.Header.Get("Content-Encoding"))
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(largeBody)); err != nil {
+		t.Fatal(err)
+	}
+	if _, message, err := conn.ReadMessage(); err != nil || string(message) != largeBody {
+		t.Errorf("echo: %v, %d bytes", err, len(message))
+	}
+}
@@ -1,24 +1,69 @@
 package middleware
 
 import (
+	"fmt"
+	"sync"
+	"time"
+
 	"base/core/config"
-	"base/core/helper"
 	"base/core/router"
-	"strings"
 )
 
 // ConfigurableMiddleware creates middleware that can be conditionally applied based on configuration
 type ConfigurableMiddleware struct {
 	config *config.MiddlewareConfig
+
+	// limiters keeps one token bucket per request budget, so counts persist
+	// across requests and a reload with new limits starts fresh buckets
+	limiters   map[string]*TokenBucket
+	limitersMu sync.Mutex
 }
 
 // NewConfigurableMiddleware creates a new configurable middleware instance
 func NewConfigurableMiddleware(cfg *config.MiddlewareConfig) *ConfigurableMiddleware {
 	return &ConfigurableMiddleware{
-		config: cfg,
+		config:   cfg,
+		limiters: make(map[string]*TokenBucket),
 	}
 }
 
+// limiterFor returns the shared token bucket for a request budget. Overrides
+// pass their path pattern as scope to get a bucket of their own.
+func (cm *ConfigurableMiddleware) limiterFor(scope string, requests int, window time.Duration) *TokenBucket {
+	key := limiterKey(scope, requests, window)
+
+	cm.limitersMu.Lock()
+	defer cm.limitersMu.Unlock()
+	limiter, ok := cm.limiters[key]
+	if !ok {
+		// A budget not seen before means the config was reloaded; drop the
+		// buckets of budgets no longer in effect
+		cm.pruneLimiters()
+		limiter = NewTokenBucket(requests, window, requests)
+		cm.limiters[key] = limiter
+	}
+	return limiter
+}
+
+// pruneLimiters stops and forgets the buckets whose budget the config no
+// longer has. Callers hold limitersMu.
+func (cm *ConfigurableMiddleware) pruneLimiters() {
+	live := make(map[string]bool)
+	for _, budget := range cm.config.RateLimitBudgets() {
+		live[limiterKey(budget.Scope, budget.Requests, budget.Window)] = true
+	}
+	for key, limiter := range cm.limiters {
+		if !live[key] {
+			limiter.Stop()
+			delete(cm.limiters, key)
+		}
+	}
+}
+
+func limiterKey(scope string, requests int, window time.Duration) string {
+	return fmt.Sprintf("%s|%d/%s", scope, requests, window)
+}
+
 // ConditionalAPIKey returns API key middleware only if required for the path
 func (cm *ConfigurableMiddleware) ConditionalAPIKey() router.MiddlewareFunc {
 	return func(next router.HandlerFunc) router.HandlerFunc {
@@ -50,12 +95,7 @@ func (cm *ConfigurableMiddleware) ConditionalAuth() router.MiddlewareFunc {
 			
 			if cm.config.IsAuthRequired(path) {
 				// Apply auth middleware
-				authConfig := DefaultAuthConfig()
-				authConfig.TokenValidator = func(token string) (any, error) {
-					_, userID, err := helper.ValidateJWT(token)
-					return userID, err
-				}
-				authMiddleware := Auth(authConfig)
+				authMiddleware := Auth(JWTAuthConfig())
 				return authMiddleware(next)(c)
 			}
 			
@@ -72,22 +112,19 @@ func (cm *ConfigurableMiddleware) ConditionalRateLimit() router.MiddlewareFunc {
 			path := c.Request.URL.Path
 			
 			if cm.config.IsRateLimitRequired(path) {
-				// Determine rate limit settings based on path
-				requests := cm.config.RateLimitRequests
-				window := cm.config.GetRateLimitDuration()
+				// Determine rate limit settings based on path (overrides first, then webhook settings)
+				requests, window, scope := cm.config.RateLimitFor(path)
 				
-				// Use webhook settings if it's a webhook path
-				if cm.isWebhookPath(path) {
-					requests = cm.config.WebhookRateLimitRequests
-					window = cm.config.GetWebhookRateLimitDuration()
+				// Count per user or per IP as configured for the path
+				keyFunc := IPRateLimitKey
+				if cm.config.RateLimitKeyFor(path) == config.RateLimitKeyUser {
+					keyFunc = UserRateLimitKey
 				}
-				
+
 				// Apply rate limit middleware
 				rateLimitConfig := &RateLimitConfig{
-					Limiter: NewTokenBucket(requests, window, requests),
-					KeyFunc: func(c *router.Context) string {
-						return c.ClientIP()
-					},
+					Limiter: cm.limiterFor(scope, requests, window),
+					KeyFunc: keyFunc,
 					ErrorHandler: func(c *router.Context) error {
 						return c.JSON(429, map[string]string{
 							"error": "Rate limit exceeded",
@@ -129,7 +166,7 @@ func (cm *ConfigurableMiddleware) WebhookSignature(provider string) router.Middl
 			path := c.Request.URL.Path
 			
 			// Only apply to webhook paths if signature verification is enabled
-			if cm.isWebhookPath(path) && cm.config.WebhookSignatureEnabled {
+			if cm.config.IsWebhookSignatureRequired(path) {
 				// TODO: Implement provider-specific signature verification
 				// For now, just log and continue
 				// This would verify HMAC signatures from Stripe, GitHub, etc.
@@ -140,47 +177,56 @@ func (cm *ConfigurableMiddleware) WebhookSignature(provider string) router.Middl
 	}
 }
 
-// isWebhookPath checks if a path is configured as a webhook path
-func (cm *ConfigurableMiddleware) isWebhookPath(path string) bool {
-	for _, webhookPath := range cm.config.WebhookPaths {
-		if cm.pathMatches(path, webhookPath) {
-			return true
-		}
+// ApplyConfigurableMiddleware mounts the middleware named in cfg.Order,
+// outermost first. Recovery, compression and CORS are mounted only when
+// enabled; API key, auth, rate limit and logging decide per request. handlers
+// supplies the middleware only the caller can build, such as "cors", and may
+// replace a built-in one of the same name.
+func ApplyConfigurableMiddleware(router *router.Router, cfg *config.MiddlewareConfig, handlers map[string]router.MiddlewareFunc) error {
+	if err := config.ValidateMiddlewareOrder(cfg.Order); err != nil {
+		return err
 	}
-	return false
-}
+	cm := NewConfigurableMiddleware(cfg)
 
-// pathMatches checks if a path matches a pattern (supports wildcards)
-func (cm *ConfigurableMiddleware) pathMatches(path, pattern string) bool {
-	if pattern == path {
-		return true
-	}
-	
-	// Handle wildcard patterns
-	if strings.HasSuffix(pattern, "/*") {
-		prefix := strings.TrimSuffix(pattern, "/*")
-		return strings.HasPrefix(path, prefix)
-	}
-	
-	return false
-}
+	for _, name := range cfg.Order {
+		switch name {
+		case config.MiddlewareRecovery:
+			if !cfg.RecoveryEnabled {
+				continue
+			}
+		case config.MiddlewareCompression:
+			if !cfg.CompressionEnabled {
+				continue
+			}
+		case config.MiddlewareCORS:
+			if !cfg.CORSEnabled {
+				continue
+			}
+		}
 
-// ApplyConfigurableMiddleware is a helper function to apply all configurable middleware
-func ApplyConfigurableMiddleware(router *router.Router, cfg *config.MiddlewareConfig) {
-	cm := NewConfigurableMiddleware(cfg)
-	
-	// Apply middleware in the correct order
-	if cfg.RecoveryEnabled {
-		router.Use(Recovery(nil)) // Recovery should be first
-	}
-	
-	if cfg.CORSEnabled {
-		// CORS middleware will be applied in main.go
+		if handler, ok := handlers[name]; ok {
+			router.Use(handler)
+			continue
+		}
+
+		switch name {
+		case config.MiddlewareRecovery:
+			router.Use(Recovery(nil))
+		case config.MiddlewareCompression:
+			router.Use(Compress(CompressConfig{
+				MinSize: cfg.CompressionMinSize,
+				Level:   cfg.CompressionLevel,
+			}))
+		case config.MiddlewareAPIKey:
+			router.Use(cm.ConditionalAPIKey())
+		case config.MiddlewareAuth:
+			router.Use(cm.ConditionalAuth())
+		case config.MiddlewareRateLimit:
+			router.Use(cm.ConditionalRateLimit())
+		case config.MiddlewareLogging:
+			router.Use(cm.ConditionalLogging())
+		}
 	}
-	
-	// Apply conditional middleware
-	router.Use(cm.ConditionalAPIKey())
-	router.Use(cm.ConditionalAuth())
-	router.Use(cm.ConditionalRateLimit())
-	router.Use(cm.ConditionalLogging())
+
+	return nil
 }
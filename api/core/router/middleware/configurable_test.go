@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"base/core/config"
+	"base/core/router"
+)
+
+// newRateLimitedRouter serves a user keyed and an IP keyed path behind the
+// configurable rate limit. The X-User header stands in for an auth middleware.
+func newRateLimitedRouter(t *testing.T, cm *ConfigurableMiddleware) *router.Router {
+	t.Helper()
+
+	asUser := func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			if id, err := strconv.ParseUint(c.Header("X-User"), 10, 64); err == nil {
+				c.Set("user_id", uint(id))
+			}
+			return next(c)
+		}
+	}
+	ok := func(c *router.Context) error { return c.String(http.StatusOK, "ok") }
+
+	r := router.New()
+	r.Use(asUser, cm.ConditionalRateLimit())
+	r.POST("/api/games/1/progress", ok)
+	r.GET("/api/items", ok)
+	return r
+}
+
+// loadConfig reads the middleware config from the environment like a reload does
+func loadConfig(t *testing.T, cfg *config.Config, overrides string) {
+	t.Helper()
+	t.Setenv("MIDDLEWARE_RATE_LIMIT_ENABLED", "true")
+	t.Setenv("MIDDLEWARE_RATE_LIMIT_REQUESTS", "2")
+	t.Setenv("MIDDLEWARE_RATE_LIMIT_USER_PATHS", "/api/games/*/progress")
+	t.Setenv("MIDDLEWARE_OVERRIDES", overrides)
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+}
+
+func request(r *router.Router, method, path, user string) int {
+	req := httptest.NewRequest(method, path, nil)
+	if user != "" {
+		req.Header.Set("X-User", user)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestConditionalRateLimitKeys(t *testing.T) {
+	cfg := &config.Config{}
+	loadConfig(t, cfg, "{}")
+	r := newRateLimitedRouter(t, NewConfigurableMiddleware(&cfg.Middleware))
+
+	t.Run("per user", func(t *testing.T) {
+		// Both users share the test client IP but have budgets of their own
+		for _, user := range []string{"1", "2"} {
+			for i := 0; i < 2; i++ {
+				if code := request(r, http.MethodPost, "/api/games/1/progress", user); code != http.StatusOK {
+					t.Fatalf("user %s request %d: status %d, want 200", user, i+1, code)
+				}
+			}
+			if code := request(r, http.MethodPost, "/api/games/1/progress", user); code != http.StatusTooManyRequests {
+				t.Errorf("user %s over budget: status %d, want 429", user, code)
+			}
+		}
+	})
+
+	t.Run("per IP", func(t *testing.T) {
+		// Users on the same IP share one budget on IP keyed paths
+		for i, user := range []string{"1", "2"} {
+			if code := request(r, http.MethodGet, "/api/items", user); code != http.StatusOK {
+				t.Fatalf("request %d: status %d, want 200", i+1, code)
+			}
+		}
+		if code := request(r, http.MethodGet, "/api/items", "3"); code != http.StatusTooManyRequests {
+			t.Errorf("third request from the same IP: status %d, want 429", code)
+		}
+	})
+}
+
+func TestReloadStopsSupersededLimiters(t *testing.T) {
+	cfg := &config.Config{}
+	loadConfig(t, cfg, `{"/api/games/*/progress":{"rate_limit":"1/1m"}}`)
+	cm := NewConfigurableMiddleware(&cfg.Middleware)
+	r := newRateLimitedRouter(t, cm)
+
+	request(r, http.MethodPost, "/api/games/1/progress", "1")
+	request(r, http.MethodGet, "/api/items", "")
+	before := make(map[string]*TokenBucket)
+	for key, limiter := range cm.limiters {
+		before[key] = limiter
+	}
+	if len(before) != 2 {
+		t.Fatalf("got %d limiters before the reload, want 2", len(before))
+	}
+
+	loadConfig(t, cfg, `{"/api/games/*/progress":{"rate_limit":"3/1m"}}`)
+	for i := 0; i < 3; i++ {
+		if code := request(r, http.MethodPost, "/api/games/1/progress", "1"); code != http.StatusOK {
+			t.Fatalf("request %d under the reloaded budget: status %d, want 200", i+1, code)
+		}
+	}
+
+	for key, limiter := range before {
+		_, kept := cm.limiters[key]
+		stopped := false
+		select {
+		case <-limiter.done:
+			stopped = true
+		default:
+		}
+		switch key {
+		case limiterKey("", 2, time.Minute):
+			if !kept || stopped {
+				t.Errorf("limiter %s still in effect was stopped", key)
+			}
+		default:
+			if kept || !stopped {
+				t.Errorf("superseded limiter %s kept %v, stopped %v", key, kept, stopped)
+			}
+		}
+	}
+	if len(cm.limiters) != 2 {
+		t.Errorf("got %d limiters after the reload, want 2", len(cm.limiters))
+	}
+}
+
+func TestRateLimitOverrideAppliesToItsPath(t *testing.T) {
+	cfg := &config.Config{}
+	loadConfig(t, cfg, `{"/api/auth/forgot-password":{"rate_limit":"1/1m"},"/api/health":{"rate_limit":"disabled"}}`)
+	r := newRateLimitedRouter(t, NewConfigurableMiddleware(&cfg.Middleware))
+	ok := func(c *router.Context) error { return c.String(http.StatusOK, "ok") }
+	r.POST("/api/auth/forgot-password", ok)
+	r.GET("/api/health", ok)
+
+	// The sensitive endpoint gets the tighter budget
+	if code := request(r, http.MethodPost, "/api/auth/forgot-password", ""); code != http.StatusOK {
+		t.Fatalf("first reset request: status %d, want 200", code)
+	}
+	if code := request(r, http.MethodPost, "/api/auth/forgot-password", ""); code != http.StatusTooManyRequests {
+		t.Errorf("second reset request: status %d, want 429", code)
+	}
+
+	// Other paths keep the global budget, untouched by the override's requests
+	for i := 0; i < 2; i++ {
+		if code := request(r, http.MethodGet, "/api/items", ""); code != http.StatusOK {
+			t.Fatalf("request %d to a global path: status %d, want 200", i+1, code)
+		}
+	}
+	if code := request(r, http.MethodGet, "/api/items", ""); code != http.StatusTooManyRequests {
+		t.Errorf("global path over budget: status %d, want 429", code)
+	}
+
+	// A disabled override turns the limit off
+	for i := 0; i < 5; i++ {
+		if code := request(r, http.MethodGet, "/api/health", ""); code != http.StatusOK {
+			t.Fatalf("request %d to a path without limit: status %d, want 200", i+1, code)
+		}
+	}
+}
+
+func TestInvalidRateLimitOverrideIsRejected(t *testing.T) {
+	cfg := &config.Config{}
+	loadConfig(t, cfg, `{"/api/auth/forgot-password":{"rate_limit":"1/1m"}}`)
+
+	for _, value := range []string{"5", "0/1m", "x/1m", "5/soon", "5/-1m"} {
+		t.Setenv("MIDDLEWARE_OVERRIDES", `{"/api/auth/forgot-password":{"rate_limit":"`+value+`"}}`)
+		if err := cfg.Reload(); err == nil {
+			t.Errorf("%q was accepted", value)
+		}
+	}
+	// The last valid settings stay in effect
+	if requests, window, scope := cfg.Middleware.RateLimitFor("/api/auth/forgot-password"); requests != 1 || window != time.Minute || scope != "/api/auth/forgot-password" {
+		t.Errorf("after rejected reloads: %d/%s scope %q, want 1/1m from the override", requests, window, scope)
+	}
+}
@@ -1,27 +1,70 @@
 package middleware
 
 import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	"base/core/router"
 )
 
+// DefaultCORSMaxAge is how long browsers may cache a preflight response
+const DefaultCORSMaxAge = 12 * time.Hour
+
+// CORSConfig configures the CORS middleware
+type CORSConfig struct {
+	// AllowedOrigins applies outside every group; a single "*" allows any origin
+	AllowedOrigins []string
+	// GroupOrigins replaces AllowedOrigins for the paths under a prefix such as
+	// /api/webhooks. The longest matching prefix wins.
+	GroupOrigins map[string][]string
+	// MaxAge is sent as Access-Control-Max-Age; 0 omits the header
+	MaxAge time.Duration
+}
+
+// corsGroup is a path prefix with its own allowed origins
+type corsGroup struct {
+	prefix  string
+	origins []string
+}
+
+// CORSMiddleware allows allowedOrigins on every path, caching preflights for
+// DefaultCORSMaxAge
 func CORSMiddleware(allowedOrigins []string) router.MiddlewareFunc {
+	return CORS(CORSConfig{AllowedOrigins: allowedOrigins, MaxAge: DefaultCORSMaxAge})
+}
+
+// CORS sets the CORS headers for allowed origins and answers their preflight
+// requests with 204. The policy is picked by request path, so a preflight
+// gets the same answer as the request it precedes.
+func CORS(config CORSConfig) router.MiddlewareFunc {
+	groups := make([]corsGroup, 0, len(config.GroupOrigins))
+	for prefix, origins := range config.GroupOrigins {
+		groups = append(groups, corsGroup{prefix: strings.TrimSuffix(prefix, "/"), origins: origins})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return len(groups[i].prefix) > len(groups[j].prefix)
+	})
+
+	maxAge := ""
+	if config.MaxAge > 0 {
+		maxAge = strconv.Itoa(int(config.MaxAge / time.Second))
+	}
+
 	return func(next router.HandlerFunc) router.HandlerFunc {
 		return func(c *router.Context) error {
-			origin := c.GetHeader("Origin")
-
-			// Allow all origins if "*" is present, otherwise match against allowedOrigins
-			allowOrigin := ""
-			if len(allowedOrigins) == 1 && allowedOrigins[0] == "*" {
-				allowOrigin = "*"
-			} else {
-				for _, o := range allowedOrigins {
-					if o == origin {
-						allowOrigin = origin
-						break
-					}
+			allowedOrigins := config.AllowedOrigins
+			path := c.Request.URL.Path
+			for _, group := range groups {
+				if path == group.prefix || strings.HasPrefix(path, group.prefix+"/") {
+					allowedOrigins = group.origins
+					break
 				}
 			}
 
+			allowOrigin := allowedOrigin(allowedOrigins, c.GetHeader("Origin"))
+
 			// Always set CORS headers if origin is allowed
 			if allowOrigin != "" {
 				c.SetHeader("Access-Control-Allow-Origin", allowOrigin)
@@ -29,7 +72,13 @@ func CORSMiddleware(allowedOrigins []string) router.MiddlewareFunc {
 				c.SetHeader("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Api-Key, Base-Orgid")
 				c.SetHeader("Access-Control-Expose-Headers", "Content-Length, Content-Type")
 				c.SetHeader("Access-Control-Allow-Credentials", "true")
-				c.SetHeader("Access-Control-Max-Age", "43200") // 12 hours
+				if maxAge != "" {
+					c.SetHeader("Access-Control-Max-Age", maxAge)
+				}
+			}
+			// The answer depends on the origin, so shared caches must not reuse it across origins
+			if allowOrigin != "*" {
+				c.Writer.Header().Add("Vary", "Origin")
 			}
 
 			// Handle preflight OPTIONS requests - respond immediately with 204
@@ -44,3 +93,20 @@ func CORSMiddleware(allowedOrigins []string) router.MiddlewareFunc {
 		}
 	}
 }
+
+// allowedOrigin returns the Access-Control-Allow-Origin value for origin, or
+// "" when it is not allowed
+func allowedOrigin(allowedOrigins []string, origin string) string {
+	if len(allowedOrigins) == 1 && allowedOrigins[0] == "*" {
+		return "*"
+	}
+	if origin == "" {
+		return ""
+	}
+	for _, o := range allowedOrigins {
+		if o == origin {
+			return origin
+		}
+	}
+	return ""
+}
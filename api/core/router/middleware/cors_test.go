@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"base/core/router"
+)
+
+// newCORSRouter serves /api and /api/webhooks behind CORS with a catch-all
+// preflight route, like the application does
+func newCORSRouter(config CORSConfig) *router.Router {
+	ok := func(c *router.Context) error { return c.String(http.StatusOK, "ok") }
+
+	r := router.New()
+	r.Use(CORS(config))
+	r.GET("/api/items", ok)
+	r.POST("/api/webhooks/stripe", ok)
+	r.POST("/api/webhooksmith", ok)
+	r.OPTIONS("/*catchall", func(c *router.Context) error { return c.NoContent() })
+	return r
+}
+
+func corsRequest(r *router.Router, method, path, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if method == http.MethodOptions {
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSRestrictsGroupOrigins(t *testing.T) {
+	r := newCORSRouter(CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		GroupOrigins:   map[string][]string{"/api/webhooks/": {"https://hooks.stripe.com"}},
+		MaxAge:         10 * time.Minute,
+	})
+
+	tests := []struct {
+		method, path, origin string
+		allowed              bool
+	}{
+		{http.MethodGet, "/api/items", "https://app.example.com", true},
+		{http.MethodGet, "/api/items", "https://hooks.stripe.com", false},
+		{http.MethodPost, "/api/webhooks/stripe", "https://hooks.stripe.com", true},
+		{http.MethodPost, "/api/webhooks/stripe", "https://app.example.com", false},
+		// The group is matched by path segment, not by string prefix
+		{http.MethodPost, "/api/webhooksmith", "https://app.example.com", true},
+		{http.MethodPost, "/api/webhooksmith", "https://hooks.stripe.com", false},
+		{http.MethodOptions, "/api/webhooks/stripe", "https://hooks.stripe.com", true},
+		{http.MethodOptions, "/api/webhooks/stripe", "https://app.example.com", false},
+		{http.MethodOptions, "/api/items", "https://app.example.com", true},
+		{http.MethodOptions, "/api/items", "https://evil.example.com", false},
+	}
+	for _, tt := range tests {
+		w := corsRequest(r, tt.method, tt.path, tt.origin)
+		got := w.Header().Get("Access-Control-Allow-Origin")
+		if tt.allowed && got != tt.origin {
+			t.Errorf("%s %s from %s: Allow-Origin %q, want the origin", tt.method, tt.path, tt.origin, got)
+		}
+		if !tt.allowed && got != "" {
+			t.Errorf("%s %s from %s: Allow-Origin %q, want none", tt.method, tt.path, tt.origin, got)
+		}
+		if w.Header().Get("Vary") != "Origin" {
+			t.Errorf("%s %s: Vary %q, want Origin", tt.method, tt.path, w.Header().Get("Vary"))
+		}
+
+		if tt.method != http.MethodOptions {
+			continue
+		}
+		// Preflights carry the max age only when the origin is allowed
+		maxAge := w.Header().Get("Access-Control-Max-Age")
+		if tt.allowed && maxAge != "600" {
+			t.Errorf("preflight %s from %s: Max-Age %q, want 600", tt.path, tt.origin, maxAge)
+		}
+		if !tt.allowed && maxAge != "" {
+			t.Errorf("preflight %s from %s: Max-Age %q for a refused origin", tt.path, tt.origin, maxAge)
+		}
+	}
+}
+
+func TestCORSWildcardAndMaxAge(t *testing.T) {
+	r := newCORSRouter(CORSConfig{
+		AllowedOrigins: []string{"*"},
+		GroupOrigins:   map[string][]string{"/api/webhooks": {"https://hooks.stripe.com"}},
+	})
+
+	w := corsRequest(r, http.MethodOptions, "/api/items", "https://anything.example.com")
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("wildcard preflight: status %d, Allow-Origin %q", w.Code, w.Header().Get("Access-Control-Allow-Origin"))
+	}
+	if w.Header().Get("Vary") != "" {
+		t.Error("a wildcard answer varies by origin")
+	}
+	if w.Header().Get("Access-Control-Max-Age") != "" {
+		t.Error("Max-Age sent although it is not configured")
+	}
+	// The wildcard does not reach into a restricted group
+	if got := corsRequest(r, http.MethodPost, "/api/webhooks/stripe", "https://anything.example.com").Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("restricted group allowed %q", got)
+	}
+
+	r = router.New()
+	r.Use(CORSMiddleware([]string{"https://app.example.com"}))
+	r.OPTIONS("/*catchall", func(c *router.Context) error { return c.NoContent() })
+	if got := corsRequest(r, http.MethodOptions, "/api/items", "https://app.example.com").Header().Get("Access-Control-Max-Age"); got != "43200" {
+		t.Errorf("CORSMiddleware Max-Age %q, want the 12 hour default", got)
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"strings"
+
+	"base/core/router"
+)
+
+// Language stores the languages the client prefers on the context, read by
+// ctx.Languages. A ?lang= query parameter, which may list several languages
+// separated by commas, comes before the Accept-Language header.
+func Language() router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			c.Writer.Header().Add("Vary", "Accept-Language")
+
+			var languages []string
+			for _, lang := range strings.Split(c.Query("lang"), ",") {
+				if lang = strings.ToLower(strings.TrimSpace(lang)); lang != "" {
+					languages = append(languages, lang)
+				}
+			}
+			languages = append(languages, router.ParseAcceptLanguage(c.GetHeader("Accept-Language"))...)
+
+			if len(languages) > 0 {
+				c.Set(router.LanguagesKey, languages)
+			}
+			return next(c)
+		}
+	}
+}
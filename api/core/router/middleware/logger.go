@@ -2,12 +2,17 @@ package middleware
 
 import (
 	"fmt"
+	"html"
+	"net/http"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"time"
 
 	"base/core/logger"
 	"base/core/router"
+
+	"go.uber.org/zap"
 )
 
 // LoggerConfig contains logger middleware configuration
@@ -54,6 +59,7 @@ func Logger(config *LoggerConfig) router.MiddlewareFunc {
 			start := time.Now()
 			path := c.Request.URL.Path
 			raw := c.Request.URL.RawQuery
+			requestId := EnsureRequestId(c)
 
 			// Process request
 			err := next(c)
@@ -62,10 +68,11 @@ func Logger(config *LoggerConfig) router.MiddlewareFunc {
 			latency := time.Since(start)
 
 			// Get response status
-			status := c.Writer.Status()
+			status := ResponseStatus(c, err)
 
 			// Build log fields
 			fields := []logger.Field{
+				logger.String("request_id", requestId),
 				logger.String("method", c.Request.Method),
 				logger.String("path", path),
 				logger.Int("status", status),
@@ -75,19 +82,26 @@ func Logger(config *LoggerConfig) router.MiddlewareFunc {
 			}
 
 			if raw != "" {
-				fields = append(fields, logger.String("query", raw))
+				fields = append(fields, logger.String("query", logger.RedactText(raw)))
+			}
+
+			if by := c.GetUint("impersonated_by"); by != 0 {
+				fields = append(fields, logger.Uint("impersonated_by", by))
 			}
 
 			if config.IncludeHeaders {
 				headers := make(map[string][]string)
 				for k, v := range c.Request.Header {
+					if isSensitiveHeader(k) {
+						v = []string{logger.Redacted}
+					}
 					headers[k] = v
 				}
 				fields = append(fields, logger.Any("headers", headers))
 			}
 
 			if err != nil {
-				fields = append(fields, logger.String("error", err.Error()))
+				fields = append(fields, ErrorField(err))
 			}
 
 			// Log based on status code
@@ -107,24 +121,84 @@ func Logger(config *LoggerConfig) router.MiddlewareFunc {
 	}
 }
 
-// Recovery creates panic recovery middleware
+// PanicInfo describes a panic recovered while handling a request
+type PanicInfo struct {
+	RequestId string
+	Value     any
+	Stack     []byte
+}
+
+// RecoveryConfig configures the recovery middleware
+type RecoveryConfig struct {
+	// Logger logs every panic with its stack trace; nil logs to the global
+	// zap logger
+	Logger logger.Logger
+
+	// OnPanic is called after the panic is logged, e.g. to alert. A panic
+	// inside it is ignored.
+	OnPanic func(c *router.Context, info PanicInfo)
+
+	// APIPrefixes are the paths always answered with JSON, "/api" when nil.
+	// Other requests get an HTML page when their Accept header prefers HTML.
+	APIPrefixes []string
+
+	// HTMLPage renders the page sent to browsers, DefaultRecoveryPage when nil
+	HTMLPage func(requestId string) string
+
+	// ExposeDetails adds the panic value and stack trace to responses. Only
+	// set it in development: it leaks internals to the client.
+	ExposeDetails bool
+}
+
+// Recovery creates panic recovery middleware. The panic is logged with the
+// request id and stack trace; the client only gets the request id. A nil log
+// logs to the global zap logger.
 func Recovery(log logger.Logger) router.MiddlewareFunc {
+	return RecoveryWithConfig(RecoveryConfig{Logger: log})
+}
+
+// RecoveryWithConfig creates panic recovery middleware answering with a 500
+// carrying the request id, as JSON for API paths and clients wanting JSON and
+// as an HTML page for browsers
+func RecoveryWithConfig(config RecoveryConfig) router.MiddlewareFunc {
+	log := config.Logger
+	if log == nil {
+		log = logger.NewLoggerFromZap(zap.L())
+	}
+	if config.APIPrefixes == nil {
+		config.APIPrefixes = []string{"/api"}
+	}
+	if config.HTMLPage == nil {
+		config.HTMLPage = DefaultRecoveryPage
+	}
+
 	return func(next router.HandlerFunc) router.HandlerFunc {
 		return func(c *router.Context) (err error) {
+			requestId := EnsureRequestId(c)
+
 			defer func() {
 				if r := recover(); r != nil {
+					info := PanicInfo{RequestId: requestId, Value: r, Stack: debug.Stack()}
+
 					// Log the panic
 					log.Error("Panic recovered",
-						logger.Any("panic", r),
+						logger.String("request_id", requestId),
+						logger.String("panic", logger.RedactText(fmt.Sprint(r))),
 						logger.String("path", c.Request.URL.Path),
 						logger.String("method", c.Request.Method),
 						logger.String("ip", c.ClientIP()),
+						logger.String("stack", string(info.Stack)),
 					)
 
-					// Return 500 error
-					err = c.JSON(500, map[string]string{
-						"error": "Internal server error",
-					})
+					if config.OnPanic != nil {
+						notifyPanic(log, config.OnPanic, c, info)
+					}
+
+					// Return 500 error unless the handler already started its response
+					err = nil
+					if !c.Writer.Written() {
+						err = writeRecoveryResponse(c, &config, info)
+					}
 				}
 			}()
 
@@ -133,22 +207,108 @@ func Recovery(log logger.Logger) router.MiddlewareFunc {
 	}
 }
 
+// notifyPanic runs the OnPanic callback, logging instead of propagating a
+// panic raised by it
+func notifyPanic(log logger.Logger, onPanic func(*router.Context, PanicInfo), c *router.Context, info PanicInfo) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("Panic in recovery callback",
+				logger.String("request_id", info.RequestId),
+				logger.String("panic", logger.RedactText(fmt.Sprint(r))))
+		}
+	}()
+	onPanic(c, info)
+}
+
+// writeRecoveryResponse answers a recovered request with a 500
+func writeRecoveryResponse(c *router.Context, config *RecoveryConfig, info PanicInfo) error {
+	isAPI := false
+	for _, prefix := range config.APIPrefixes {
+		if strings.HasPrefix(c.Request.URL.Path, prefix) {
+			isAPI = true
+			break
+		}
+	}
+
+	if !isAPI && c.PrefersHTML() {
+		page := config.HTMLPage(info.RequestId)
+		if config.ExposeDetails {
+			details := "<pre>" + html.EscapeString(fmt.Sprint(info.Value)+"\n\n"+string(info.Stack)) + "</pre>\n"
+			if i := strings.LastIndex(page, "</body>"); i >= 0 {
+				page = page[:i] + details + page[i:]
+			} else {
+				page += details
+			}
+		}
+		return c.HTML(http.StatusInternalServerError, page)
+	}
+
+	body := map[string]string{
+		"error":      "Internal server error",
+		"request_id": info.RequestId,
+	}
+	if config.ExposeDetails {
+		body["panic"] = fmt.Sprint(info.Value)
+		body["stack"] = string(info.Stack)
+	}
+	return c.JSON(http.StatusInternalServerError, body)
+}
+
+// DefaultRecoveryPage is the HTML page sent to browsers after a panic
+func DefaultRecoveryPage(requestId string) string {
+	return `<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Something went wrong</title></head>
+<body>
+<h1>Something went wrong</h1>
+<p>The server hit an unexpected error. Please try again later.</p>
+<p>If the problem persists, contact support with this reference: <code>` + html.EscapeString(requestId) + `</code></p>
+</body>
+</html>
+`
+}
+
 // RequestId generates and adds a request Id to the context
 func RequestId() router.MiddlewareFunc {
 	return func(next router.HandlerFunc) router.HandlerFunc {
 		return func(c *router.Context) error {
-			// Generate request Id
-			requestId := generateRequestId()
+			EnsureRequestId(c)
+			return next(c)
+		}
+	}
+}
 
-			// Add to context
-			c.Set("request_id", requestId)
+// EnsureRequestId returns the request id of c, generating one and sending it
+// as X-Request-Id when the request has none yet
+func EnsureRequestId(c *router.Context) string {
+	if requestId := c.GetString("request_id"); requestId != "" {
+		return requestId
+	}
 
-			// Add to response header
-			c.SetHeader("X-Request-Id", requestId)
+	// Generate request Id
+	requestId := generateRequestId()
 
-			return next(c)
-		}
+	// Add to context
+	c.Set("request_id", requestId)
+
+	// Add to response header
+	c.SetHeader("X-Request-Id", requestId)
+
+	return requestId
+}
+
+// ResponseStatus is the status of the response to c. A handler that returned
+// err without writing a response is answered with a 500 by the router.
+func ResponseStatus(c *router.Context, err error) int {
+	if err != nil && !c.Writer.Written() {
+		return http.StatusInternalServerError
 	}
+	return c.Writer.Status()
+}
+
+// ErrorField logs a handler error with the secrets in its message masked
+func ErrorField(err error) logger.Field {
+	return logger.String("error", logger.RedactText(err.Error()))
 }
 
 // generateRequestId generates a unique request Id
@@ -285,3 +445,12 @@ func (s *SimpleMetricsCollector) GetMetrics() map[string]*RequestMetrics {
 	}
 	return result
 }
+
+// isSensitiveHeader reports whether a request header carries credentials
+func isSensitiveHeader(name string) bool {
+	switch http.CanonicalHeaderKey(name) {
+	case "Authorization", "Proxy-Authorization", "Cookie":
+		return true
+	}
+	return logger.IsSensitiveKey(name)
+}
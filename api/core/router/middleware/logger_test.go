@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"base/core/logger"
+	"base/core/router"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observedRouter returns a router with recovery and request logging writing
+// to the returned logs
+func observedRouter() (*router.Router, *observer.ObservedLogs) {
+	core, logs := observer.New(zap.InfoLevel)
+	log := logger.NewLoggerFromZap(zap.New(core))
+	r := router.New()
+	r.Use(Recovery(log), Logger(DefaultLoggerConfig(log)))
+	return r, logs
+}
+
+// serverError decodes a sanitized 500 response and returns its request id
+func serverError(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d, want 500", w.Code)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %q: %v", w.Body, err)
+	}
+	if body["error"] != "Internal server error" {
+		t.Errorf("error %q, want the generic message", body["error"])
+	}
+	if body["request_id"] == "" || body["request_id"] != w.Header().Get("X-Request-Id") {
+		t.Errorf("request id %q does not match the header %q", body["request_id"], w.Header().Get("X-Request-Id"))
+	}
+	return body["request_id"]
+}
+
+func TestHandlerErrorIsLoggedAndAnswered500(t *testing.T) {
+	r, logs := observedRouter()
+	r.GET("/fail", func(c *router.Context) error {
+		return errors.New("connect postgres://app:pa55word@db/app: refused")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fail?token=abc123", nil))
+	requestId := serverError(t, w)
+	if strings.Contains(w.Body.String(), "refused") {
+		t.Errorf("the error reached the client: %s", w.Body)
+	}
+
+	entries := logs.FilterMessage("Server error").All()
+	if len(entries) != 1 {
+		t.Fatalf("%d server error entries, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["request_id"] != requestId || fields["status"] != int64(http.StatusInternalServerError) {
+		t.Errorf("fields = %v, want request id %s and status 500", fields, requestId)
+	}
+	message, _ := fields["error"].(string)
+	if !strings.Contains(message, "refused") || strings.Contains(message, "pa55word") {
+		t.Errorf("logged error %q, want the detail without the password", message)
+	}
+	if query, _ := fields["query"].(string); strings.Contains(query, "abc123") {
+		t.Errorf("logged query %q carries the token", query)
+	}
+}
+
+func TestPanicIsLoggedWithStack(t *testing.T) {
+	r, logs := observedRouter()
+	r.GET("/panic", func(c *router.Context) error { panic("password=hunter2 lost") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	requestId := serverError(t, w)
+	if strings.Contains(w.Body.String(), "hunter2") {
+		t.Errorf("the panic reached the client: %s", w.Body)
+	}
+
+	entries := logs.FilterMessage("Panic recovered").All()
+	if len(entries) != 1 {
+		t.Fatalf("%d panic entries, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["request_id"] != requestId {
+		t.Errorf("panic logged with request id %v, want %s", fields["request_id"], requestId)
+	}
+	if panicValue, _ := fields["panic"].(string); !strings.Contains(panicValue, "lost") || strings.Contains(panicValue, "hunter2") {
+		t.Errorf("logged panic %q, want it without the password", panicValue)
+	}
+	if stack, _ := fields["stack"].(string); !strings.Contains(stack, "logger_test.go") {
+		t.Errorf("stack does not reach the handler: %.200s", stack)
+	}
+}
+
+func TestHandlerErrorAfterWritingKeepsResponse(t *testing.T) {
+	r, logs := observedRouter()
+	r.GET("/partial", func(c *router.Context) error {
+		c.String(http.StatusAccepted, "started")
+		return errors.New("stream broke")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/partial", nil))
+	if w.Code != http.StatusAccepted || w.Body.String() != "started" {
+		t.Errorf("got %d %q, want the handler's response", w.Code, w.Body)
+	}
+	entries := logs.FilterField(zap.String("error", "stream broke")).All()
+	if len(entries) != 1 || entries[0].ContextMap()["status"] != int64(http.StatusAccepted) {
+		t.Errorf("error entries = %v, want one with the written status", entries)
+	}
+}
+
+// recoveryRouter returns a router whose handlers at /api/panic and /panic
+// panic with a value mentioning <secret>
+func recoveryRouter(config RecoveryConfig) *router.Router {
+	if config.Logger == nil {
+		config.Logger = logger.NewLoggerFromZap(zap.NewNop())
+	}
+	r := router.New()
+	r.Use(RecoveryWithConfig(config))
+	panics := func(c *router.Context) error { panic("lost <secret>") }
+	r.GET("/api/panic", panics)
+	r.GET("/panic", panics)
+	return r
+}
+
+func requestWithAccept(r *router.Router, path, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Accept", accept)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+const browserAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+
+func TestRecoveryNegotiatesResponseFormat(t *testing.T) {
+	r := recoveryRouter(RecoveryConfig{})
+
+	// API paths answer JSON even to a browser
+	w := requestWithAccept(r, "/api/panic", browserAccept)
+	serverError(t, w)
+	if strings.Contains(w.Body.String(), "secret") || strings.Contains(w.Body.String(), "goroutine") {
+		t.Errorf("API response leaks the panic: %s", w.Body)
+	}
+
+	// Browsers get a page with the request id
+	w = requestWithAccept(r, "/panic", browserAccept)
+	if w.Code != http.StatusInternalServerError || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("browser: status %d content type %q, want a 500 HTML page", w.Code, w.Header().Get("Content-Type"))
+	}
+	requestId := w.Header().Get("X-Request-Id")
+	if requestId == "" || !strings.Contains(w.Body.String(), requestId) {
+		t.Errorf("page does not show request id %q: %s", requestId, w.Body)
+	}
+	if strings.Contains(w.Body.String(), "secret") || strings.Contains(w.Body.String(), "goroutine") {
+		t.Errorf("page leaks the panic: %s", w.Body)
+	}
+
+	// Outside the API, clients preferring JSON still get JSON
+	for _, accept := range []string{"application/json", "", "text/html;q=0.5,application/json"} {
+		serverError(t, requestWithAccept(r, "/panic", accept))
+	}
+}
+
+func TestRecoveryCallbackAndDetails(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	var seen []PanicInfo
+	r := recoveryRouter(RecoveryConfig{
+		Logger: logger.NewLoggerFromZap(zap.New(core)),
+		OnPanic: func(c *router.Context, info PanicInfo) {
+			seen = append(seen, info)
+			panic("alerting is down")
+		},
+		HTMLPage:      func(requestId string) string { return "<html><body>oops " + requestId + "</body></html>" },
+		ExposeDetails: true,
+	})
+
+	w := requestWithAccept(r, "/api/panic", "application/json")
+	var body map[string]string
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusInternalServerError || body["panic"] != "lost <secret>" || !strings.Contains(body["stack"], "goroutine") {
+		t.Errorf("development response %d %v, want the panic and stack", w.Code, body)
+	}
+
+	// The callback sees every panic, and its own panic is only logged
+	if len(seen) != 1 || seen[0].RequestId != body["request_id"] || seen[0].Value != "lost <secret>" || len(seen[0].Stack) == 0 {
+		t.Errorf("callback got %+v", seen)
+	}
+	if entries := logs.FilterMessage("Panic in recovery callback").All(); len(entries) != 1 {
+		t.Errorf("%d callback panic entries, want 1", len(entries))
+	}
+
+	w = requestWithAccept(r, "/panic", browserAccept)
+	page := w.Body.String()
+	if !strings.HasPrefix(page, "<html><body>oops "+w.Header().Get("X-Request-Id")) || !strings.HasSuffix(page, "</body></html>") {
+		t.Errorf("custom page = %s", page)
+	}
+	if !strings.Contains(page, "<pre>lost &lt;secret&gt;") || strings.Contains(page, "<secret>") {
+		t.Errorf("page details are missing or unescaped: %s", page)
+	}
+}
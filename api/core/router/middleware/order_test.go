@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"base/core/config"
+	"base/core/router"
+)
+
+// traceAs returns a middleware appending name to the X-Trace response header
+func traceAs(name string) router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			c.Writer.Header().Add("X-Trace", name)
+			return next(c)
+		}
+	}
+}
+
+// tracedChain mounts cfg with every middleware replaced by a tracer and
+// returns the order they ran in
+func tracedChain(t *testing.T, cfg *config.MiddlewareConfig) []string {
+	t.Helper()
+	handlers := make(map[string]router.MiddlewareFunc, len(config.MiddlewareNames))
+	for _, name := range config.MiddlewareNames {
+		handlers[name] = traceAs(name)
+	}
+
+	r := router.New()
+	if err := ApplyConfigurableMiddleware(r, cfg, handlers); err != nil {
+		t.Fatalf("ApplyConfigurableMiddleware: %v", err)
+	}
+	r.GET("/ping", func(c *router.Context) error { return c.String(http.StatusOK, "pong") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	return w.Header().Values("X-Trace")
+}
+
+func TestMiddlewareChainFollowsConfiguredOrder(t *testing.T) {
+	cfg := &config.MiddlewareConfig{
+		RecoveryEnabled:    true,
+		CompressionEnabled: true,
+		CORSEnabled:        true,
+		Order:              strings.Split(config.DefaultMiddlewareOrder, ","),
+	}
+	if got := tracedChain(t, cfg); !reflect.DeepEqual(got, cfg.Order) {
+		t.Errorf("default order ran as %v, want %v", got, cfg.Order)
+	}
+
+	// CORS ahead of auth, and logging outermost
+	cfg.Order = []string{"logging", "cors", "recovery", "auth", "api_key"}
+	if got := tracedChain(t, cfg); !reflect.DeepEqual(got, cfg.Order) {
+		t.Errorf("custom order ran as %v, want %v", got, cfg.Order)
+	}
+
+	// Listed but disabled middleware is skipped
+	cfg.Order = strings.Split(config.DefaultMiddlewareOrder, ",")
+	cfg.RecoveryEnabled, cfg.CompressionEnabled, cfg.CORSEnabled = false, false, false
+	want := []string{"api_key", "auth", "rate_limit", "logging"}
+	if got := tracedChain(t, cfg); !reflect.DeepEqual(got, want) {
+		t.Errorf("with recovery, compression and CORS disabled ran %v, want %v", got, want)
+	}
+}
+
+func TestMiddlewareOrderMountsBuiltins(t *testing.T) {
+	// Recovery outside a tracer turns the panic into a 500 after the tracer ran
+	cfg := &config.MiddlewareConfig{RecoveryEnabled: true, Order: []string{"recovery", "logging"}}
+	r := router.New()
+	if err := ApplyConfigurableMiddleware(r, cfg, map[string]router.MiddlewareFunc{"logging": traceAs("logging")}); err != nil {
+		t.Fatal(err)
+	}
+	r.GET("/panic", func(c *router.Context) error { panic("boom") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status %d, want the built-in recovery's 500", w.Code)
+	}
+	if got := w.Header().Values("X-Trace"); len(got) != 1 {
+		t.Errorf("trace %v, want the logging tracer inside recovery", got)
+	}
+}
+
+func TestMiddlewareOrderRejectsUnknownNames(t *testing.T) {
+	for _, order := range [][]string{{"recovery", "csrf"}, {"cors", "auth", "cors"}} {
+		err := ApplyConfigurableMiddleware(router.New(), &config.MiddlewareConfig{Order: order}, nil)
+		if err == nil {
+			t.Errorf("order %v was accepted", order)
+		}
+	}
+
+	t.Setenv("MIDDLEWARE_ORDER", "auth, cors,recovery")
+	cfg := config.NewConfig()
+	if want := []string{"auth", "cors", "recovery"}; !reflect.DeepEqual(cfg.Middleware.Order, want) {
+		t.Errorf("MIDDLEWARE_ORDER parsed as %v, want %v", cfg.Middleware.Order, want)
+	}
+}
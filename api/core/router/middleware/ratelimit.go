@@ -3,9 +3,11 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
+	"base/core/helper"
 	"base/core/router"
 )
 
@@ -26,6 +28,8 @@ type TokenBucket struct {
 	buckets   map[string]*bucket
 	mu        sync.RWMutex
 	cleanup   *time.Ticker
+	done      chan struct{}
+	stopOnce  sync.Once
 }
 
 type bucket struct {
@@ -42,6 +46,7 @@ func NewTokenBucket(rate int, interval time.Duration, maxTokens int) *TokenBucke
 		maxTokens: maxTokens,
 		buckets:   make(map[string]*bucket),
 		cleanup:   time.NewTicker(5 * time.Minute),
+		done:      make(chan struct{}),
 	}
 
 	// Start cleanup goroutine
@@ -97,7 +102,13 @@ func (tb *TokenBucket) Reset(key string) {
 
 // cleanupRoutine removes old buckets periodically
 func (tb *TokenBucket) cleanupRoutine() {
-	for range tb.cleanup.C {
+	for {
+		select {
+		case <-tb.done:
+			return
+		case <-tb.cleanup.C:
+		}
+
 		tb.mu.Lock()
 		now := time.Now()
 		for key, b := range tb.buckets {
@@ -111,9 +122,13 @@ func (tb *TokenBucket) cleanupRoutine() {
 	}
 }
 
-// Stop stops the cleanup routine
+// Stop stops the cleanup routine and ends its goroutine. The limiter keeps
+// answering Allow afterwards, so requests already holding it are unaffected.
 func (tb *TokenBucket) Stop() {
-	tb.cleanup.Stop()
+	tb.stopOnce.Do(func() {
+		tb.cleanup.Stop()
+		close(tb.done)
+	})
 }
 
 // RateLimitConfig contains rate limiting configuration
@@ -146,6 +161,26 @@ func DefaultRateLimitConfig() *RateLimitConfig {
 	}
 }
 
+// IPRateLimitKey counts requests per client IP
+func IPRateLimitKey(c *router.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// UserRateLimitKey counts requests per authenticated user, falling back to
+// the client IP for anonymous requests. The user comes from "user_id" when an
+// auth middleware already ran, otherwise from a valid bearer token.
+func UserRateLimitKey(c *router.Context) string {
+	if userId := c.GetUint("user_id"); userId != 0 {
+		return fmt.Sprintf("user:%d", userId)
+	}
+	if token, found := strings.CutPrefix(c.Header("Authorization"), "Bearer "); found && token != "" {
+		if _, userId, err := helper.ValidateJWT(token); err == nil && userId != 0 {
+			return fmt.Sprintf("user:%d", userId)
+		}
+	}
+	return IPRateLimitKey(c)
+}
+
 // RateLimit creates rate limiting middleware
 func RateLimit(config *RateLimitConfig) router.MiddlewareFunc {
 	if config == nil {
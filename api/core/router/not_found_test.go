@@ -0,0 +1,93 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newFallbackRouter() *Router {
+	ok := func(c *Context) error { return c.String(http.StatusOK, "ok") }
+	r := New()
+	r.Use(trace("global"))
+	r.GET("/api/items/:id", ok)
+	r.PUT("/api/items/:id", ok)
+	r.DELETE("/api/items/:id", ok)
+	r.OPTIONS("/*catchall", func(c *Context) error { return c.NoContent() })
+	return r
+}
+
+func decodeJSON(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("Content-Type %q, want JSON", ct)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %q: %v", w.Body, err)
+	}
+	return body
+}
+
+func TestUnknownPathAnswersJSON404(t *testing.T) {
+	r := newFallbackRouter()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/nothing/here", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status %d, want 404", w.Code)
+	}
+	body := decodeJSON(t, w)
+	if body["error"] != "not found" || body["path"] != "/api/nothing/here" {
+		t.Errorf("body = %v", body)
+	}
+	if w.Header().Get("Allow") != "" {
+		t.Error("a 404 carries an Allow header")
+	}
+	if got := w.Header().Values("X-Trace"); len(got) != 1 || got[0] != "global" {
+		t.Errorf("global middleware ran as %v for a 404, want once", got)
+	}
+}
+
+func TestUnsupportedMethodAnswersJSON405(t *testing.T) {
+	r := newFallbackRouter()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/items/7/", nil))
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status %d, want 405", w.Code)
+	}
+	// The OPTIONS catch-all does not count as a route of the path
+	if allow := w.Header().Get("Allow"); allow != "DELETE, GET, PUT" {
+		t.Errorf("Allow %q, want DELETE, GET, PUT", allow)
+	}
+	body := decodeJSON(t, w)
+	if body["error"] != "method not allowed" || body["method"] != "POST" || body["path"] != "/api/items/7/" {
+		t.Errorf("body = %v", body)
+	}
+	if got := w.Header().Values("X-Trace"); len(got) != 1 {
+		t.Errorf("global middleware ran as %v for a 405, want once", got)
+	}
+}
+
+func TestCustomFallbackHandlers(t *testing.T) {
+	r := newFallbackRouter()
+	r.NotFound(func(c *Context) error { return c.String(http.StatusTeapot, "custom 404") })
+	r.MethodNotAllowed(func(c *Context) error {
+		return c.String(http.StatusMethodNotAllowed, "use %s", c.Writer.Header().Get("Allow"))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if w.Code != http.StatusTeapot || w.Body.String() != "custom 404" {
+		t.Errorf("custom 404: %d %q", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/api/items/7", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Body.String() != "use DELETE, GET, PUT" {
+		t.Errorf("custom 405: %d %q", w.Code, w.Body)
+	}
+}
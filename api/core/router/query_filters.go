@@ -0,0 +1,129 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidQuery is wrapped by the errors of ParseQueryFilters and
+// QueryFilters.Apply; handlers answer it with 400
+var ErrInvalidQuery = errors.New("invalid query")
+
+// SortField is one entry of the sort parameter
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// QueryFilters are the list parameters of a request:
+//
+//	?page=2&limit=20&sort=-created_at,key&filter[language]=en
+//
+// A leading "-" sorts that field descending. Fields are the names clients
+// use; a QueryAllowlist maps them to columns.
+type QueryFilters struct {
+	Page    *int
+	Limit   *int
+	Sort    []SortField
+	Filters map[string]string
+}
+
+// QueryAllowlist maps the fields clients may filter and sort by to their
+// columns. Fields missing from it are rejected.
+type QueryAllowlist struct {
+	Filters map[string]string
+	Sort    map[string]string
+}
+
+// QueryFilters parses the list parameters of the request
+func (c *Context) QueryFilters() (*QueryFilters, error) {
+	return ParseQueryFilters(c.Request.URL.Query())
+}
+
+// ParseQueryFilters parses page, limit, sort and filter[field] parameters.
+// Other parameters are left to the handler.
+func ParseQueryFilters(values url.Values) (*QueryFilters, error) {
+	filters := &QueryFilters{Filters: make(map[string]string)}
+
+	var err error
+	if filters.Page, err = positiveParam(values, "page"); err != nil {
+		return nil, err
+	}
+	if filters.Limit, err = positiveParam(values, "limit"); err != nil {
+		return nil, err
+	}
+
+	for _, field := range strings.Split(values.Get("sort"), ",") {
+		field = strings.TrimSpace(field)
+		desc := strings.HasPrefix(field, "-")
+		field = strings.TrimLeft(field, "+-")
+		if field == "" {
+			continue
+		}
+		filters.Sort = append(filters.Sort, SortField{Field: field, Desc: desc})
+	}
+
+	for key, vals := range values {
+		name, ok := strings.CutPrefix(key, "filter[")
+		if !ok {
+			continue
+		}
+		name, ok = strings.CutSuffix(name, "]")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%w: malformed filter parameter %q", ErrInvalidQuery, key)
+		}
+		filters.Filters[name] = vals[0]
+	}
+
+	return filters, nil
+}
+
+// Apply adds a Where for each filter and an Order for each sort field to db,
+// failing for fields the allowlist does not name. Filters compare for
+// equality. Pagination is left to the caller, which usually counts first.
+func (f *QueryFilters) Apply(db *gorm.DB, allow QueryAllowlist) (*gorm.DB, error) {
+	// Sorted so that the generated SQL does not depend on map order
+	names := make([]string, 0, len(f.Filters))
+	for name := range f.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		column, ok := allow.Filters[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot filter by %q", ErrInvalidQuery, name)
+		}
+		db = db.Where(clause.Eq{Column: clause.Column{Name: column}, Value: f.Filters[name]})
+	}
+
+	for _, field := range f.Sort {
+		column, ok := allow.Sort[field.Field]
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot sort by %q", ErrInvalidQuery, field.Field)
+		}
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: field.Desc})
+	}
+
+	return db, nil
+}
+
+// positiveParam parses an optional positive integer parameter
+func positiveParam(values url.Values, name string) (*int, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("%w: %s must be a positive integer", ErrInvalidQuery, name)
+	}
+	return &n, nil
+}
@@ -0,0 +1,83 @@
+package router
+
+import (
+	"errors"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func TestParseQueryFilters(t *testing.T) {
+	values, _ := url.ParseQuery("page=2&limit=20&sort=-created_at, key,,+language&filter[language]=en&filter[model_id]=7&q=other")
+	filters, err := ParseQueryFilters(values)
+	if err != nil {
+		t.Fatalf("ParseQueryFilters: %v", err)
+	}
+	if filters.Page == nil || *filters.Page != 2 || filters.Limit == nil || *filters.Limit != 20 {
+		t.Errorf("page %v, limit %v; want 2 and 20", filters.Page, filters.Limit)
+	}
+	wantSort := []SortField{{"created_at", true}, {"key", false}, {"language", false}}
+	if !reflect.DeepEqual(filters.Sort, wantSort) {
+		t.Errorf("sort = %v, want %v", filters.Sort, wantSort)
+	}
+	wantFilters := map[string]string{"language": "en", "model_id": "7"}
+	if !reflect.DeepEqual(filters.Filters, wantFilters) {
+		t.Errorf("filters = %v, want %v", filters.Filters, wantFilters)
+	}
+
+	empty, err := ParseQueryFilters(url.Values{})
+	if err != nil || empty.Page != nil || empty.Limit != nil || empty.Sort != nil || len(empty.Filters) != 0 {
+		t.Errorf("no parameters: got %+v, %v", empty, err)
+	}
+
+	for _, query := range []string{"page=0", "limit=-1", "page=two", "filter[]=x", "filter[language=en"} {
+		values, _ := url.ParseQuery(query)
+		if _, err := ParseQueryFilters(values); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%s: got %v, want ErrInvalidQuery", query, err)
+		}
+	}
+}
+
+func TestQueryFiltersApplyEnforcesAllowlist(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	allow := QueryAllowlist{
+		Filters: map[string]string{"language": "language", "lang": "language", "model": "model"},
+		Sort:    map[string]string{"created_at": "created_at", "key": "key"},
+	}
+	sql := func(query string) (string, error) {
+		values, _ := url.ParseQuery(query)
+		filters, err := ParseQueryFilters(values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scoped, err := filters.Apply(db.Session(&gorm.Session{DryRun: true}).Table("translations"), allow)
+		if err != nil {
+			return "", err
+		}
+		var rows []map[string]any
+		return scoped.Find(&rows).Statement.SQL.String(), nil
+	}
+
+	got, err := sql("filter[model]=post&filter[lang]=en&sort=-created_at,key")
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	want := "SELECT * FROM `translations` WHERE `language` = ? AND `model` = ? ORDER BY `created_at` DESC,`key`"
+	if got != want {
+		t.Errorf("SQL\n got %s\nwant %s", got, want)
+	}
+
+	for _, query := range []string{"filter[password]=x", "sort=password", "sort=-model", "filter[language%29%20OR%201%3D1]=x"} {
+		if _, err := sql(query); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%s: got %v, want ErrInvalidQuery", query, err)
+		}
+	}
+}
@@ -64,6 +64,11 @@ func (w *responseWriter) Flush() {
 	}
 }
 
+// Unwrap returns the underlying writer, for http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // Push implements the http.Pusher interface
 func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
 	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
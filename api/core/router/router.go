@@ -1,8 +1,10 @@
 package router
 
 import (
+	"net"
 	"net/http"
 	"path"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -14,13 +16,29 @@ type Router struct {
 	notFound   HandlerFunc
 	pool       sync.Pool
 	mu         sync.RWMutex
+
+	// methodNotAllowed answers paths that only other methods route
+	methodNotAllowed HandlerFunc
+
+	trustedProxies  []*net.IPNet
+	maxJSONBodySize int64
+	strictJSON      bool
+	localizer       JSONLocalizer
+	limits          ServerLimits
+
+	// server is the one started by Run or RunTLS, stopped by Shutdown
+	serverMu sync.Mutex
+	server   *http.Server
 }
 
 // New creates a new router
 func New() *Router {
 	r := &Router{
-		trees:    make(map[string]*node),
-		notFound: defaultNotFound,
+		trees:            make(map[string]*node),
+		notFound:         defaultNotFound,
+		methodNotAllowed: defaultMethodNotAllowed,
+		maxJSONBodySize:  DefaultMaxJSONBodySize,
+		limits:           DefaultServerLimits(),
 	}
 	r.pool.New = func() any {
 		return &Context{
@@ -106,12 +124,13 @@ func (r *Router) Handle(method, path string, handler HandlerFunc, middleware ...
 	root.addRoute(path, finalHandler)
 }
 
-// Group creates a new route group with prefix
+// Group creates a new route group with prefix. The middleware runs for
+// every route of the group and its sub-groups, after the global middleware.
 func (r *Router) Group(prefix string, middleware ...MiddlewareFunc) *RouterGroup {
 	return &RouterGroup{
 		router:     r,
 		prefix:     prefix,
-		middleware: middleware,
+		middleware: joinMiddleware(nil, middleware),
 	}
 }
 
@@ -119,6 +138,10 @@ func (r *Router) Group(prefix string, middleware ...MiddlewareFunc) *RouterGroup
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	c := r.pool.Get().(*Context)
 	c.reset(w, req)
+	c.trustedProxies = r.trustedProxies
+	c.maxJSONBodySize = r.maxJSONBodySize
+	c.strictJSON = r.strictJSON
+	c.localizer = r.localizer
 	defer r.pool.Put(c)
 
 	r.handleRequest(c)
@@ -126,10 +149,10 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 // handleRequest processes the HTTP request
 func (r *Router) handleRequest(c *Context) {
-	// Apply global middleware for all requests
-	finalHandler := r.notFound
-	for i := len(r.middleware) - 1; i >= 0; i-- {
-		finalHandler = r.middleware[i](finalHandler)
+	// Normalize path: remove trailing slash except for root "/"
+	reqPath := c.Request.URL.Path
+	if len(reqPath) > 1 {
+		reqPath = strings.TrimSuffix(reqPath, "/")
 	}
 
 	r.mu.RLock()
@@ -137,32 +160,77 @@ func (r *Router) handleRequest(c *Context) {
 	r.mu.RUnlock()
 
 	if root != nil {
-		// Normalize path: remove trailing slash except for root "/"
-		reqPath := c.Request.URL.Path
-		if len(reqPath) > 1 {
-			reqPath = strings.TrimSuffix(reqPath, "/")
-		}
-
 		if handler, params, _ := root.getValue(reqPath); handler != nil {
 			c.params = params
 			if err := handler(c); err != nil {
-				c.Error(http.StatusInternalServerError, err)
+				handleError(c, err)
 			}
 			return
 		}
 	}
 
-	// Handle 404 with global middleware applied
+	// Unmatched requests get a 405 when another method routes the path and a
+	// 404 otherwise, both with global middleware applied
+	finalHandler := r.notFound
+	if allowed := r.allowedMethods(reqPath); len(allowed) > 0 {
+		c.SetHeader("Allow", strings.Join(allowed, ", "))
+		finalHandler = r.methodNotAllowed
+	}
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		finalHandler = r.middleware[i](finalHandler)
+	}
+
 	if err := finalHandler(c); err != nil {
-		c.Error(http.StatusInternalServerError, err)
+		handleError(c, err)
+	}
+}
+
+// handleError answers a request whose handler returned err without writing a
+// response. The error is for the logs, which the logging middleware writes;
+// clients get a generic message with the request id to quote.
+func handleError(c *Context, err error) {
+	if c.Writer.Written() {
+		return
+	}
+	body := map[string]any{"error": "Internal server error"}
+	if requestId := c.GetString("request_id"); requestId != "" {
+		body["request_id"] = requestId
 	}
+	c.JSON(http.StatusInternalServerError, body)
 }
 
-// NotFound sets the 404 handler
+// allowedMethods returns the sorted methods with a route for path
+func (r *Router) allowedMethods(path string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var allowed []string
+	for method, root := range r.trees {
+		// The OPTIONS catch-all for preflights does not make a path routable
+		if method == http.MethodOptions {
+			continue
+		}
+		if handler, _, _ := root.getValue(path); handler != nil {
+			allowed = append(allowed, method)
+		}
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+// NotFound sets the handler for requests no route matches. It replaces the
+// default JSON 404.
 func (r *Router) NotFound(handler HandlerFunc) {
 	r.notFound = handler
 }
 
+// MethodNotAllowed sets the handler for requests whose path is routed for
+// other methods only. The Allow header is already set when it runs. It
+// replaces the default JSON 405.
+func (r *Router) MethodNotAllowed(handler HandlerFunc) {
+	r.methodNotAllowed = handler
+}
+
 // Static serves static files
 func (r *Router) Static(prefix, root string) {
 	// Ensure prefix starts with /
@@ -193,10 +261,29 @@ func (r *Router) Static(prefix, root string) {
 
 // defaultNotFound is the default 404 handler
 func defaultNotFound(c *Context) error {
-	return c.String(http.StatusNotFound, "404 page not found")
+	return c.JSON(http.StatusNotFound, map[string]any{
+		"error": "not found",
+		"path":  c.Request.URL.Path,
+	})
 }
 
-// RouterGroup represents a group of routes with common prefix and middleware
+// defaultMethodNotAllowed is the default 405 handler
+func defaultMethodNotAllowed(c *Context) error {
+	return c.JSON(http.StatusMethodNotAllowed, map[string]any{
+		"error":  "method not allowed",
+		"method": c.Request.Method,
+		"path":   c.Request.URL.Path,
+	})
+}
+
+// RouterGroup represents a group of routes with common prefix and middleware.
+//
+// Middleware of a route runs in this order, outermost first: global
+// middleware, then the middleware of each group from the root down, then
+// the middleware passed to the route itself. A group takes a copy of the
+// middleware of its parent when it is created, so Use on a group affects
+// routes registered on it afterwards and sub-groups created afterwards, but
+// never its siblings.
 type RouterGroup struct {
 	router     *Router
 	prefix     string
@@ -208,7 +295,8 @@ func (g *RouterGroup) Use(middleware ...MiddlewareFunc) {
 	g.middleware = append(g.middleware, middleware...)
 }
 
-// Group creates a sub-group
+// Group creates a sub-group whose routes run the middleware of g, then the
+// given middleware
 func (g *RouterGroup) Group(prefix string, middleware ...MiddlewareFunc) *RouterGroup {
 	// Normalize path to avoid double slashes
 	normalizedPrefix := g.prefix + prefix
@@ -221,7 +309,7 @@ func (g *RouterGroup) Group(prefix string, middleware ...MiddlewareFunc) *Router
 	return &RouterGroup{
 		router:     g.router,
 		prefix:     normalizedPrefix,
-		middleware: append(g.middleware, middleware...),
+		middleware: joinMiddleware(g.middleware, middleware),
 	}
 }
 
@@ -260,8 +348,15 @@ func (g *RouterGroup) Handle(method, path string, handler HandlerFunc, middlewar
 	finalPath := g.prefix + path
 	// Clean up double slashes
 	finalPath = strings.ReplaceAll(finalPath, "//", "/")
-	allMiddleware := append(g.middleware, middleware...)
-	g.router.Handle(method, finalPath, handler, allMiddleware...)
+	g.router.Handle(method, finalPath, handler, joinMiddleware(g.middleware, middleware)...)
+}
+
+// joinMiddleware returns parent followed by own in a new slice, so groups
+// sharing a parent never write into each other's middleware
+func joinMiddleware(parent, own []MiddlewareFunc) []MiddlewareFunc {
+	joined := make([]MiddlewareFunc, 0, len(parent)+len(own))
+	joined = append(joined, parent...)
+	return append(joined, own...)
 }
 
 // Static serves static files for the group
@@ -269,18 +364,9 @@ func (g *RouterGroup) Static(relativePath, root string) {
 	g.router.Static(g.prefix+relativePath, root)
 }
 
-// Run starts the HTTP server
+// Run starts the HTTP server. It returns nil once Shutdown stopped it.
 func (r *Router) Run(addr string) error {
-	if !strings.HasPrefix(addr, ":") {
-		addr = ":" + addr
-	}
-
-	server := &http.Server{
-		Addr:    addr,
-		Handler: r,
-	}
-
-	return server.ListenAndServe()
+	return serveUntilShutdown(r.newServer(addr).ListenAndServe())
 }
 
 // setupDefaultOptionsHandler adds a catch-all OPTIONS handler for CORS support
@@ -0,0 +1,108 @@
+package router
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Default server limits. The read header timeout is what disconnects clients
+// trickling their headers to hold connections open (Slowloris).
+const (
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultReadTimeout       = 60 * time.Second
+	DefaultWriteTimeout      = 60 * time.Second
+	DefaultIdleTimeout       = 120 * time.Second
+	DefaultMaxHeaderBytes    = 64 << 10 // 64KB
+)
+
+// ServerLimits bound how long and how much a client may take. A zero timeout
+// disables it.
+type ServerLimits struct {
+	ReadHeaderTimeout time.Duration // Time to read the request headers
+	ReadTimeout       time.Duration // Time to read the whole request, body included
+	WriteTimeout      time.Duration // Time to write the response; event streams clear it
+	IdleTimeout       time.Duration // How long a keep-alive connection waits for the next request
+	MaxHeaderBytes    int           // Largest request headers, request line included
+}
+
+// DefaultServerLimits returns the limits used unless SetServerLimits changes them
+func DefaultServerLimits() ServerLimits {
+	return ServerLimits{
+		ReadHeaderTimeout: DefaultReadHeaderTimeout,
+		ReadTimeout:       DefaultReadTimeout,
+		WriteTimeout:      DefaultWriteTimeout,
+		IdleTimeout:       DefaultIdleTimeout,
+		MaxHeaderBytes:    DefaultMaxHeaderBytes,
+	}
+}
+
+// SetServerLimits configures the server started by Run and RunTLS. A
+// MaxHeaderBytes of 0 or less keeps DefaultMaxHeaderBytes.
+func (r *Router) SetServerLimits(limits ServerLimits) {
+	if limits.MaxHeaderBytes <= 0 {
+		limits.MaxHeaderBytes = DefaultMaxHeaderBytes
+	}
+	r.limits = limits
+}
+
+// RunTLS starts the HTTPS server with the PEM certificate and key files.
+// Clients negotiating it through ALPN are served over HTTP/2. It returns nil
+// once Shutdown stopped it.
+func (r *Router) RunTLS(addr, certFile, keyFile string) error {
+	server := r.newServer(addr)
+	server.TLSConfig = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	return serveUntilShutdown(server.ListenAndServeTLS(certFile, keyFile))
+}
+
+// Shutdown gracefully stops the server started by Run or RunTLS: it stops
+// accepting connections and waits for the requests in flight until ctx is
+// done. It does nothing when no server is running.
+func (r *Router) Shutdown(ctx context.Context) error {
+	r.serverMu.Lock()
+	server := r.server
+	r.server = nil
+	r.serverMu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
+
+// newServer creates the server for addr, a port with or without its colon,
+// and keeps it for Shutdown
+func (r *Router) newServer(addr string) *http.Server {
+	if !strings.Contains(addr, ":") {
+		addr = ":" + addr
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           r,
+		ReadHeaderTimeout: r.limits.ReadHeaderTimeout,
+		ReadTimeout:       r.limits.ReadTimeout,
+		WriteTimeout:      r.limits.WriteTimeout,
+		IdleTimeout:       r.limits.IdleTimeout,
+		MaxHeaderBytes:    r.limits.MaxHeaderBytes,
+	}
+
+	r.serverMu.Lock()
+	r.server = server
+	r.serverMu.Unlock()
+	return server
+}
+
+// serveUntilShutdown treats the error returned after Shutdown as success
+func serveUntilShutdown(err error) error {
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,291 @@
+package router
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeSelfSignedCert writes a certificate for 127.0.0.1 and its key as PEM
+// files and returns their paths with a pool trusting the certificate
+func writeSelfSignedCert(t *testing.T) (certFile, keyFile string, pool *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+
+	cert, _ := x509.ParseCertificate(der)
+	pool = x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
+
+// freeAddr returns a local address nothing listens on
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+// startServer runs start in the background and returns its result channel once
+// addr accepts connections
+func startServer(t *testing.T, addr string, start func() error) chan error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- start() }()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			return done
+		}
+		select {
+		case err := <-done:
+			t.Fatalf("server stopped: %v", err)
+		default:
+		}
+	}
+	t.Fatalf("nothing listens on %s", addr)
+	return nil
+}
+
+// shutdown stops r and checks the server returned cleanly
+func shutdown(t *testing.T, r *Router, done chan error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := r.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("server returned %v after Shutdown, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("server still running after Shutdown")
+	}
+}
+
+func TestRunTLSServesHTTP2(t *testing.T) {
+	certFile, keyFile, pool := writeSelfSignedCert(t)
+	addr := freeAddr(t)
+	r := New()
+	r.GET("/proto", func(c *Context) error { return c.String(http.StatusOK, "%s", c.Request.Proto) })
+	done := startServer(t, addr, func() error { return r.RunTLS(addr, certFile, keyFile) })
+
+	get := func(transport *http.Transport) (int, string) {
+		t.Helper()
+		resp, err := (&http.Client{Transport: transport, Timeout: 5 * time.Second}).Get("https://" + addr + "/proto")
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.ProtoMajor, string(body)
+	}
+	if major, proto := get(&http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}, ForceAttemptHTTP2: true}); major != 2 || proto != "HTTP/2.0" {
+		t.Errorf("HTTP/2 client was served over %s", proto)
+	}
+	// Clients without HTTP/2 still get HTTP/1.1
+	if major, proto := get(&http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}); major != 1 || proto != "HTTP/1.1" {
+		t.Errorf("HTTP/1.1 client was served over %s", proto)
+	}
+
+	// The certificate is the self-signed one, so an untrusting client fails
+	if _, err := (&http.Client{Timeout: 5 * time.Second}).Get("https://" + addr + "/proto"); err == nil {
+		t.Error("a client not trusting the certificate connected")
+	}
+
+	shutdown(t, r, done)
+}
+
+func TestRunShutsDownGracefully(t *testing.T) {
+	addr := freeAddr(t)
+	r := New()
+	release := make(chan struct{})
+	r.GET("/slow", func(c *Context) error {
+		<-release
+		return c.String(http.StatusOK, "done")
+	})
+	done := startServer(t, addr, func() error { return r.Run(addr) })
+
+	// A request in flight is finished before Shutdown returns
+	responses := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			responses <- 0
+			return
+		}
+		resp.Body.Close()
+		responses <- resp.StatusCode
+	}()
+	time.Sleep(50 * time.Millisecond)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+	shutdown(t, r, done)
+	if code := <-responses; code != http.StatusOK {
+		t.Errorf("request in flight got %d, want 200", code)
+	}
+
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Errorf("second Shutdown: %v", err)
+	}
+}
+
+func TestRunTLSReportsBadCertificate(t *testing.T) {
+	if err := New().RunTLS(freeAddr(t), filepath.Join(t.TempDir(), "missing.pem"), "missing-key.pem"); err == nil {
+		t.Error("RunTLS started without a certificate")
+	}
+}
+
+func TestSlowHeaderSenderIsDisconnected(t *testing.T) {
+	addr := freeAddr(t)
+	r := New()
+	r.GET("/", func(c *Context) error { return c.String(http.StatusOK, "ok") })
+	limits := DefaultServerLimits()
+	limits.ReadHeaderTimeout = 200 * time.Millisecond
+	r.SetServerLimits(limits)
+	done := startServer(t, addr, func() error { return r.Run(addr) })
+	defer shutdown(t, r, done)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Trickle a header line every 50ms, never finishing the headers
+	start := time.Now()
+	closed := make(chan time.Duration, 1)
+	go func() {
+		io.Copy(io.Discard, conn)
+		closed <- time.Since(start)
+	}()
+	conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n"))
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case elapsed := <-closed:
+			if elapsed < limits.ReadHeaderTimeout {
+				t.Errorf("disconnected after %s, before the read header timeout", elapsed)
+			}
+			// A client sending its headers at once is served
+			resp, err := http.Get("http://" + addr + "/")
+			if err != nil || resp.StatusCode != http.StatusOK {
+				t.Fatalf("regular request: %v, %v", resp, err)
+			}
+			resp.Body.Close()
+			return
+		case <-ticker.C:
+			conn.Write([]byte("X-Slow: 1\r\n"))
+		case <-timeout:
+			t.Fatal("the slow client is still connected")
+		}
+	}
+}
+
+func TestOversizedHeadersAreRejected(t *testing.T) {
+	addr := freeAddr(t)
+	r := New()
+	r.GET("/", func(c *Context) error { return c.String(http.StatusOK, "ok") })
+	r.SetServerLimits(ServerLimits{MaxHeaderBytes: 1 << 10})
+	done := startServer(t, addr, func() error { return r.Run(addr) })
+	defer shutdown(t, r, done)
+
+	get := func(headerSize int) int {
+		req, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/", nil)
+		req.Header.Set("X-Padding", strings.Repeat("a", headerSize))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := get(512); code != http.StatusOK {
+		t.Errorf("headers under the cap: status %d, want 200", code)
+	}
+	// net/http allows 4KB over the cap before refusing
+	if code := get(16 << 10); code != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("headers over the cap: status %d, want 431", code)
+	}
+}
+
+func TestEventStreamOutlivesWriteTimeout(t *testing.T) {
+	addr := freeAddr(t)
+	r := New()
+	r.GET("/events", func(c *Context) error {
+		c.SSEStart()
+		for i := 0; i < 4; i++ {
+			time.Sleep(100 * time.Millisecond)
+			if err := c.SSEvent("tick", i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	limits := DefaultServerLimits()
+	limits.WriteTimeout = 150 * time.Millisecond
+	r.SetServerLimits(limits)
+	done := startServer(t, addr, func() error { return r.Run(addr) })
+	defer shutdown(t, r, done)
+
+	resp, err := http.Get("http://" + addr + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("stream cut after %q: %v", body, err)
+	}
+	if n := strings.Count(string(body), "event: tick"); n != 4 {
+		t.Errorf("got %d events past the write timeout, want 4: %q", n, body)
+	}
+}
@@ -0,0 +1,56 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SSEStart begins a server-sent events stream: it sets the event-stream
+// headers, writes the 200 status and flushes so the client sees the stream open.
+// The server write timeout is lifted, as a stream outlives any response.
+func (c *Context) SSEStart() {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.SetHeader("Content-Type", "text/event-stream")
+	c.SetHeader("Cache-Control", "no-cache")
+	c.SetHeader("Connection", "keep-alive")
+	// Stop reverse proxies such as nginx from buffering the stream
+	c.SetHeader("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+	c.Flush()
+}
+
+// SSEvent writes one event with data encoded as JSON and flushes it
+func (c *Context) SSEvent(event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", sseLine(event), payload); err != nil {
+		return err
+	}
+	c.Flush()
+	return nil
+}
+
+// SSEComment writes a comment line, which clients ignore; use it as a
+// heartbeat to keep idle connections open through proxies
+func (c *Context) SSEComment(comment string) error {
+	if _, err := fmt.Fprintf(c.Writer, ": %s\n\n", sseLine(comment)); err != nil {
+		return err
+	}
+	c.Flush()
+	return nil
+}
+
+// Flush sends any buffered response data to the client
+func (c *Context) Flush() {
+	c.Writer.Flush()
+}
+
+// sseLine keeps a field on one line, since a newline would end it early
+func sseLine(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
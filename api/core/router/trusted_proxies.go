@@ -0,0 +1,80 @@
+package router
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// SetTrustedProxies sets the proxies whose X-Forwarded-For and X-Real-IP
+// headers ClientIP honours. Entries are CIDRs or single IPs. Without trusted
+// proxies ClientIP always returns the address of the connecting peer.
+func (r *Router) SetTrustedProxies(proxies []string) error {
+	networks, err := ParseTrustedProxies(proxies)
+	if err != nil {
+		return err
+	}
+	r.trustedProxies = networks
+	return nil
+}
+
+// ParseTrustedProxies parses CIDRs and single IPs, ignoring empty entries
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// isTrustedProxy reports whether ip belongs to one of the trusted networks
+func isTrustedProxy(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedClientIP walks X-Forwarded-For from the right, skipping trusted
+// proxies, and returns the first other hop. That is the address the nearest
+// trusted proxy saw; entries left of it may be forged by the client. When
+// every hop is trusted the leftmost one is returned. ok is false when the
+// header is empty or holds something that is not an IP.
+func forwardedClientIP(networks []*net.IPNet, xff string) (string, bool) {
+	hops := strings.Split(xff, ",")
+	client := ""
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		ip := net.ParseIP(hop)
+		if ip == nil {
+			return "", false
+		}
+		client = hop
+		if !isTrustedProxy(networks, ip) {
+			break
+		}
+	}
+	return client, client != ""
+}
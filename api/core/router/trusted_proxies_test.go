@@ -0,0 +1,94 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// clientIP serves one request from remoteAddr with headers and returns the
+// ClientIP the handler saw
+func clientIP(t *testing.T, r *Router, remoteAddr string, headers map[string]string) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+	req.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Body.String()
+}
+
+func newIPRouter(t *testing.T, proxies ...string) *Router {
+	t.Helper()
+	r := New()
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		t.Fatalf("SetTrustedProxies: %v", err)
+	}
+	r.GET("/ip", func(c *Context) error { return c.String(http.StatusOK, "%s", c.ClientIP()) })
+	return r
+}
+
+func TestClientIPIgnoresSpoofedHeaders(t *testing.T) {
+	spoofed := map[string]string{"X-Forwarded-For": "1.2.3.4", "X-Real-IP": "5.6.7.8"}
+
+	// Without trusted proxies the headers are never honoured
+	if ip := clientIP(t, newIPRouter(t), "203.0.113.9:5000", spoofed); ip != "203.0.113.9" {
+		t.Errorf("no trusted proxies: ClientIP = %q, want the peer address", ip)
+	}
+	// Nor when the peer is outside the trusted set
+	r := newIPRouter(t, "10.0.0.0/8")
+	if ip := clientIP(t, r, "203.0.113.9:5000", spoofed); ip != "203.0.113.9" {
+		t.Errorf("untrusted peer: ClientIP = %q, want the peer address", ip)
+	}
+}
+
+func TestClientIPFromTrustedProxy(t *testing.T) {
+	r := newIPRouter(t, "10.0.0.0/8", "192.168.1.1")
+
+	tests := []struct {
+		name    string
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{"single hop", "10.0.0.2:80", map[string]string{"X-Forwarded-For": "198.51.100.7"}, "198.51.100.7"},
+		{"single IP proxy", "192.168.1.1:80", map[string]string{"X-Forwarded-For": "198.51.100.7"}, "198.51.100.7"},
+		// The client prepended a forged hop; the proxy appended the real one
+		{"forged leftmost hop", "10.0.0.2:80", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.7"}, "198.51.100.7"},
+		{"chain of trusted proxies", "10.0.0.2:80", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.7, 10.0.0.5, 10.1.2.3"}, "198.51.100.7"},
+		{"only trusted hops", "10.0.0.2:80", map[string]string{"X-Forwarded-For": "10.0.0.7, 10.0.0.5"}, "10.0.0.7"},
+		{"malformed header", "10.0.0.2:80", map[string]string{"X-Forwarded-For": "not-an-ip"}, "10.0.0.2"},
+		{"real ip header", "10.0.0.2:80", map[string]string{"X-Real-IP": "198.51.100.7"}, "198.51.100.7"},
+		{"forwarded for wins", "10.0.0.2:80", map[string]string{"X-Forwarded-For": "198.51.100.7", "X-Real-IP": "5.6.7.8"}, "198.51.100.7"},
+		{"no headers", "10.0.0.2:80", nil, "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		if ip := clientIP(t, r, tt.remote, tt.headers); ip != tt.want {
+			t.Errorf("%s: ClientIP = %q, want %q", tt.name, ip, tt.want)
+		}
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	networks, err := ParseTrustedProxies([]string{" 10.0.0.0/8 ", "", "192.168.1.1", "::1"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+	if len(networks) != 3 {
+		t.Fatalf("parsed %d networks, want 3", len(networks))
+	}
+	if networks[1].String() != "192.168.1.1/32" || networks[2].String() != "::1/128" {
+		t.Errorf("single IPs parsed as %s and %s", networks[1], networks[2])
+	}
+
+	for _, proxy := range []string{"10.0.0.0/33", "proxy.local"} {
+		if _, err := ParseTrustedProxies([]string{proxy}); err == nil {
+			t.Errorf("%q was accepted", proxy)
+		}
+	}
+	if err := New().SetTrustedProxies([]string{"nope"}); err == nil {
+		t.Error("SetTrustedProxies accepted an invalid entry")
+	}
+}
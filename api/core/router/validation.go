@@ -0,0 +1,91 @@
+package router
+
+import (
+	"base/core/types"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	validate     *validator.Validate
+	validateOnce sync.Once
+)
+
+// bindingValidator returns the shared validator for `binding` struct tags.
+// Field errors are keyed by the json name so they match the request body.
+func bindingValidator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New()
+		validate.SetTagName("binding")
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+	})
+	return validate
+}
+
+// Validate checks obj against its `binding` tags. Failures are returned as
+// types.FieldErrors; anything that is not a struct passes unchecked.
+func Validate(obj any) error {
+	value := reflect.ValueOf(obj)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	err := bindingValidator().Struct(obj)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	fields := make(types.FieldErrors, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		fields[fieldPath(fieldErr)] = validationMessage(fieldErr)
+	}
+	return fields
+}
+
+// fieldPath drops the root struct name from the namespace, so nested fields
+// read as "checks[0].action"
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return fieldErr.Field()
+}
+
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "required"
+	case "email":
+		return "must be a valid email"
+	case "min":
+		return "must be at least " + fieldErr.Param()
+	case "max":
+		return "must be at most " + fieldErr.Param()
+	}
+	if fieldErr.Param() != "" {
+		return fieldErr.Tag() + "=" + fieldErr.Param()
+	}
+	return fieldErr.Tag()
+}
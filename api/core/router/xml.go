@@ -0,0 +1,341 @@
+package router
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// xmlRootName is the root element used when the response value does not name one
+	xmlRootName = "response"
+	// xmlItemName is the element used for each entry of a slice or array
+	xmlItemName = "item"
+)
+
+var (
+	xmlMarshalerType  = reflect.TypeOf((*xml.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// encodeXML writes obj as an XML document. Unlike encoding/xml it accepts maps
+// and falls back to json tag names for fields without an xml tag, so response
+// types written for JSON render sensibly. Fields with xml tags keep their name,
+// omitempty and attr options.
+func encodeXML(w io.Writer, obj any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	value := reflect.ValueOf(obj)
+	if err := writeXMLElement(encoder, xmlRootNameOf(value), value); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+// xmlRootNameOf returns the name from an XMLName field tag, if the value has one
+func xmlRootNameOf(v reflect.Value) string {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return xmlRootName
+		}
+		v = v.Elem()
+	}
+	if v.IsValid() && v.Kind() == reflect.Struct {
+		if field, ok := v.Type().FieldByName("XMLName"); ok {
+			if name, _, _ := strings.Cut(field.Tag.Get("xml"), ","); name != "" {
+				return name
+			}
+		}
+	}
+	return xmlRootName
+}
+
+func writeXMLElement(enc *xml.Encoder, name string, v reflect.Value) error {
+	start := xml.StartElement{Name: xml.Name{Local: xmlElementName(name)}}
+
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return writeXMLText(enc, start, "")
+		}
+		if v.Type().Implements(xmlMarshalerType) {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return writeXMLText(enc, start, "")
+	}
+
+	switch {
+	case v.Type().Implements(xmlMarshalerType):
+		return enc.EncodeElement(v.Interface(), start)
+	case v.Type().Implements(textMarshalerType):
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		return writeXMLText(enc, start, string(text))
+	case v.Type().Implements(jsonMarshalerType):
+		return writeXMLFromJSON(enc, start, v)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return writeXMLStruct(enc, start, v)
+	case reflect.Map:
+		return writeXMLMap(enc, start, v)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return writeXMLText(enc, start, string(v.Bytes()))
+		}
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := writeXMLElement(enc, xmlItemName, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	default:
+		return writeXMLText(enc, start, xmlScalar(v))
+	}
+}
+
+// writeXMLFromJSON renders types that only define their JSON form (such as
+// gorm.DeletedAt) by converting that form to XML
+func writeXMLFromJSON(enc *xml.Encoder, start xml.StartElement, v reflect.Value) error {
+	data, err := v.Interface().(json.Marshaler).MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	return writeXMLElement(enc, start.Name.Local, reflect.ValueOf(decoded))
+}
+
+func writeXMLStruct(enc *xml.Encoder, start xml.StartElement, v reflect.Value) error {
+	fields := xmlFields(v)
+
+	for _, field := range fields {
+		if field.attr {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: field.name}, Value: xmlScalar(field.value)})
+		}
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if field.attr {
+			continue
+		}
+		if err := writeXMLElement(enc, field.name, field.value); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+func writeXMLMap(enc *xml.Encoder, start xml.StartElement, v reflect.Value) error {
+	keys := v.MapKeys()
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = fmt.Sprint(key.Interface())
+	}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return names[order[a]] < names[order[b]] })
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, i := range order {
+		if err := writeXMLElement(enc, names[i], v.MapIndex(keys[i])); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+func writeXMLText(enc *xml.Encoder, start xml.StartElement, text string) error {
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if text != "" {
+		if err := enc.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// xmlField is a struct field selected for XML output
+type xmlField struct {
+	name  string
+	value reflect.Value
+	attr  bool
+}
+
+// xmlFields lists the exported fields of a struct in declaration order,
+// flattening embedded structs the way encoding/json does
+func xmlFields(v reflect.Value) []xmlField {
+	fields := []xmlField{}
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Name == "XMLName" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		value := v.Field(i)
+
+		name, options := xmlFieldTag(field)
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := value
+			for embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					break
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, xmlFields(embedded)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if hasTagOption(options, "omitempty") && isEmptyXMLValue(value) {
+			continue
+		}
+		if hasTagOption(options, "chardata") || hasTagOption(options, "innerxml") {
+			// Not supported outside encoding/xml; render as a regular element
+			options = ""
+		}
+
+		fields = append(fields, xmlField{
+			name:  name,
+			value: value,
+			attr:  hasTagOption(options, "attr") && isXMLScalar(value),
+		})
+	}
+	return fields
+}
+
+// xmlFieldTag reads the xml tag of a field, falling back to the json tag
+func xmlFieldTag(field reflect.StructField) (name, options string) {
+	if tag, ok := field.Tag.Lookup("xml"); ok {
+		name, options, _ = strings.Cut(tag, ",")
+		return name, options
+	}
+	if tag, ok := field.Tag.Lookup("json"); ok {
+		name, options, _ = strings.Cut(tag, ",")
+		// Only omitempty carries over from json options
+		if !hasTagOption(options, "omitempty") {
+			options = ""
+		}
+		return name, options
+	}
+	return "", ""
+}
+
+func hasTagOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyXMLValue matches the omitempty rules of encoding/json and encoding/xml
+func isEmptyXMLValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+func isXMLScalar(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func xmlScalar(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// xmlElementName turns an arbitrary key into a valid XML element name
+func xmlElementName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		valid := unicode.IsLetter(r) || r == '_' ||
+			(i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'))
+		if valid {
+			b.WriteRune(r)
+		} else {
+			if i == 0 && (unicode.IsDigit(r) || r == '-' || r == '.') {
+				b.WriteRune('_')
+				b.WriteRune(r)
+				continue
+			}
+			b.WriteRune('_')
+		}
+	}
+	if b.Len() == 0 {
+		return xmlItemName
+	}
+	return b.String()
+}
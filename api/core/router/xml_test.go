@@ -0,0 +1,153 @@
+package router
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type xmlTestOwner struct {
+	Id   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type xmlTestGame struct {
+	Id        uint              `json:"id" xml:"id,attr"`
+	Title     string            `json:"title" xml:"name"`
+	Slug      string            `json:"slug"`
+	Notes     string            `json:"notes,omitempty"`
+	Secret    string            `json:"-"`
+	Tags      []string          `json:"tags"`
+	Owner     *xmlTestOwner     `json:"owner"`
+	Settings  map[string]any    `json:"settings"`
+	CreatedAt time.Time         `json:"created_at"`
+	Extra     map[string]string `json:"extra,omitempty"`
+}
+
+func newXMLTestRouter() *Router {
+	r := New()
+	r.GET("/games/tetris", func(c *Context) error {
+		return c.Negotiate(http.StatusOK, xmlTestGame{
+			Id:        7,
+			Title:     "Tetris & Co <classic>",
+			Slug:      "tetris",
+			Secret:    "hidden",
+			Tags:      []string{"puzzle", "retro"},
+			Owner:     &xmlTestOwner{Id: 1, Name: "Ada"},
+			Settings:  map[string]any{"max-level": 15, "2player": true},
+			CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		})
+	})
+	return r
+}
+
+func get(r *Router, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/games/tetris", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestNegotiateRendersWellFormedXML(t *testing.T) {
+	w := get(newXMLTestRouter(), "application/xml")
+
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/xml") {
+		t.Fatalf("Content-Type = %q, want application/xml", got)
+	}
+	body := w.Body.String()
+	if !strings.HasPrefix(body, xml.Header) {
+		t.Errorf("body does not start with the XML declaration: %s", body)
+	}
+
+	// Every token must parse for the document to be well-formed
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("malformed XML: %v\n%s", err, body)
+		}
+	}
+
+	var doc struct {
+		XMLName   xml.Name `xml:"response"`
+		Id        uint     `xml:"id,attr"`
+		Name      string   `xml:"name"`
+		Slug      string   `xml:"slug"`
+		Notes     *string  `xml:"notes"`
+		Secret    *string  `xml:"Secret"`
+		Tags      []string `xml:"tags>item"`
+		OwnerName string   `xml:"owner>name"`
+		Settings  struct {
+			MaxLevel  int  `xml:"max-level"`
+			TwoPlayer bool `xml:"_2player"`
+		} `xml:"settings"`
+		CreatedAt string `xml:"created_at"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode: %v\n%s", err, body)
+	}
+
+	if doc.Id != 7 {
+		t.Errorf("id attribute = %d, want 7", doc.Id)
+	}
+	if doc.Name != "Tetris & Co <classic>" {
+		t.Errorf("xml tag name = %q, want the escaped title round-tripped", doc.Name)
+	}
+	if doc.Slug != "tetris" || doc.OwnerName != "Ada" {
+		t.Errorf("json tag names not used: slug %q, owner %q", doc.Slug, doc.OwnerName)
+	}
+	if doc.Notes != nil || doc.Secret != nil {
+		t.Error("omitempty or json:\"-\" fields were rendered")
+	}
+	if strings.Join(doc.Tags, ",") != "puzzle,retro" {
+		t.Errorf("tags = %v, want puzzle, retro", doc.Tags)
+	}
+	if doc.Settings.MaxLevel != 15 || !doc.Settings.TwoPlayer {
+		t.Errorf("map entries = %+v, want max-level 15 and 2player true", doc.Settings)
+	}
+	if doc.CreatedAt != "2024-05-01T12:00:00Z" {
+		t.Errorf("created_at = %q, want RFC3339", doc.CreatedAt)
+	}
+}
+
+func TestNegotiatePicksFormatFromAccept(t *testing.T) {
+	r := newXMLTestRouter()
+
+	tests := []struct {
+		accept string
+		xml    bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/xml", true},
+		{"text/xml", true},
+		{"application/xml, application/json", false},
+		{"application/xml;q=0.5, application/json", false},
+		{"application/json;q=0.4, application/xml", true},
+		{"application/xml, */*;q=0.1", true},
+		{"text/html", false},
+	}
+	for _, tt := range tests {
+		w := get(r, tt.accept)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Accept %q: status %d", tt.accept, w.Code)
+		}
+		if tt.xml {
+			if err := xml.Unmarshal(w.Body.Bytes(), new(struct{})); err != nil {
+				t.Errorf("Accept %q: want XML, got %s", tt.accept, w.Body)
+			}
+		} else if !json.Valid(w.Body.Bytes()) {
+			t.Errorf("Accept %q: want JSON, got %s", tt.accept, w.Body)
+		}
+	}
+}
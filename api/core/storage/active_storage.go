@@ -1,13 +1,24 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Validation errors returned by Attach; callers can map them to client errors
+var (
+	ErrFileTooLarge        = errors.New("file too large")
+	ErrExtensionNotAllowed = errors.New("file extension not allowed")
 )
 
 func NewActiveStorage(db *gorm.DB, config Config) (*ActiveStorage, error) {
@@ -26,37 +37,9 @@ func NewActiveStorage(db *gorm.DB, config Config) (*ActiveStorage, error) {
 		storagePath = filepath.Join(cwd, storagePath)
 	}
 
-	switch strings.ToLower(config.Provider) {
-	case "local":
-		provider, err = NewLocalProvider(LocalConfig{
-			BasePath: storagePath,
-			BaseURL:  config.BaseURL,
-		})
-	case "s3":
-		provider, err = NewS3Provider(S3Config{
-			APIKey:          config.APIKey,
-			APISecret:       config.APISecret,
-			AccessKeyID:     config.APIKey,
-			AccessKeySecret: config.APISecret,
-			AccountID:       config.AccountID,
-			Endpoint:        config.Endpoint,
-			Bucket:          config.Bucket,
-			BaseURL:         config.BaseURL,
-			Region:          config.Region,
-		})
-	case "r2":
-		provider, err = NewR2Provider(R2Config{
-			AccessKeyID:     config.APIKey,
-			AccessKeySecret: config.APISecret,
-			AccountID:       config.AccountID,
-			Bucket:          config.Bucket,
-			BaseURL:         config.BaseURL,
-			CDN:             config.CDN,
-		})
-	default:
-		return nil, fmt.Errorf("unsupported storage provider: %s", config.Provider)
-	}
-
+	// Providers receive the resolved absolute path
+	config.Path = storagePath
+	provider, err = NewProvider(config.Provider, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize storage provider: %w", err)
 	}
@@ -94,6 +77,9 @@ func (as *ActiveStorage) Attach(model Attachable, field string, file *multipart.
 	if err := as.validateFile(file, config); err != nil {
 		return nil, err
 	}
+	if err := as.inspectFile(file); err != nil {
+		return nil, err
+	}
 
 	// Create attachment record
 	attachment := &Attachment{
@@ -104,35 +90,114 @@ func (as *ActiveStorage) Attach(model Attachable, field string, file *multipart.
 		Size:      file.Size,
 	}
 
-	// Upload file using provider
-	result, err := as.provider.Upload(file, UploadConfig{
-		AllowedExtensions: config.AllowedExtensions,
-		MaxFileSize:       config.MaxFileSize,
-		UploadPath:        filepath.Join(config.Path, model.GetModelName(), field),
+	// Open source file
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer src.Close()
+
+	// Hash the content to reuse an identical stored file
+	if attachment.Hash, err = hashContent(src); err != nil {
+		return nil, err
+	}
+
+	// Reference an identical stored file in the transaction that locked it
+	reused := false
+	err = as.db.Transaction(func(tx *gorm.DB) error {
+		existing, err := findByHash(tx, attachment.Hash, file.Size)
+		if err != nil || existing == nil {
+			return err
+		}
+		attachment.Path = existing.Path
+		attachment.URL = existing.URL
+		reused = true
+		return tx.Create(attachment).Error
 	})
 	if err != nil {
 		return nil, err
 	}
+	if reused {
+		return attachment, nil
+	}
+
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind source file: %w", err)
+	}
+
+	// Upload file using provider under a unique key
+	key := path.Join(filepath.ToSlash(config.Path), model.GetModelName(), field, generateUniqueFilename(file.Filename))
+	if err := as.provider.Put(key, src, file.Size, file.Header.Get("Content-Type")); err != nil {
+		return nil, err
+	}
 
 	// Update attachment with upload result
-	attachment.Path = result.Path
-	attachment.URL = as.provider.GetURL(result.Path)
+	attachment.Path = key
+	attachment.URL = as.provider.URL(key)
 
 	// Save attachment record
 	if err := as.db.Create(attachment).Error; err != nil {
 		// Try to delete uploaded file if record creation fails
-		_ = as.provider.Delete(result.Path)
+		_ = as.provider.Delete(key)
 		return nil, err
 	}
 
 	return attachment, nil
 }
 
+// Provider returns the storage provider backing this instance
+func (as *ActiveStorage) Provider() Provider {
+	return as.provider
+}
+
+// SignedURL returns a temporary URL for an attachment
+func (as *ActiveStorage) SignedURL(attachment *Attachment, expiry time.Duration) (string, error) {
+	return as.provider.SignedURL(attachment.Path, expiry)
+}
+
+// Delete removes an attachment, and its stored file unless other attachments
+// with the same content still reference it
 func (as *ActiveStorage) Delete(attachment *Attachment) error {
-	if err := as.provider.Delete(attachment.Path); err != nil {
+	var unreferenced bool
+	err := as.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		unreferenced, err = DeleteRecord(tx, attachment)
+		return err
+	})
+	if err != nil || !unreferenced {
 		return err
 	}
-	return as.db.Delete(attachment).Error
+	return as.Purge(attachment)
+}
+
+// DeleteRecord deletes the row of attachment through tx and reports whether
+// no attachment references its stored object anymore. The rows sharing the
+// object are locked first, so an Attach reusing it has either saved its
+// reference before they are counted or finds none to reuse. The object is
+// left for Purge, to be called once tx commits, or for a StagedPurge.
+func DeleteRecord(tx *gorm.DB, attachment *Attachment) (bool, error) {
+	var sharing []uint
+	err := tx.Model(&Attachment{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("path = ?", attachment.Path).
+		Order("id").
+		Pluck("id", &sharing).Error
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Delete(attachment).Error; err != nil {
+		return false, err
+	}
+
+	references, err := referencesOf(tx, attachment.Path)
+	if err != nil {
+		return false, err
+	}
+	return references == 0, nil
+}
+
+// Purge deletes the stored object of attachment, whose row is gone
+func (as *ActiveStorage) Purge(attachment *Attachment) error {
+	return as.provider.Delete(attachment.Path)
 }
 
 func (as *ActiveStorage) getConfig(modelName, field string) (AttachmentConfig, error) {
@@ -150,14 +215,31 @@ func (as *ActiveStorage) getConfig(modelName, field string) (AttachmentConfig, e
 }
 
 func (as *ActiveStorage) validateFile(file *multipart.FileHeader, config AttachmentConfig) error {
-	if file.Size > config.MaxFileSize {
-		return fmt.Errorf("file size exceeds maximum allowed size of %d bytes", config.MaxFileSize)
+	if config.MaxFileSize > 0 && file.Size > config.MaxFileSize {
+		return fmt.Errorf("%w: maximum allowed size is %d bytes", ErrFileTooLarge, config.MaxFileSize)
 	}
+	return validateExtension(file.Filename, config)
+}
 
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if len(config.AllowedExtensions) > 0 && !strings.Contains(strings.Join(config.AllowedExtensions, ","), ext) {
-		return fmt.Errorf("file extension %s is not allowed", ext)
+// validateExtension checks filename against the allowed extensions, if any
+func validateExtension(filename string, config AttachmentConfig) error {
+	if len(config.AllowedExtensions) == 0 {
+		return nil
 	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, allowed := range config.AllowedExtensions {
+		if ext != "" && strings.EqualFold(ext, allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q, allowed: %s", ErrExtensionNotAllowed, ext, strings.Join(config.AllowedExtensions, ", "))
+}
 
-	return nil
+// AttachmentsFor returns the attachments stored for a model field, oldest first
+func (as *ActiveStorage) AttachmentsFor(model Attachable, field string) ([]Attachment, error) {
+	var attachments []Attachment
+	err := as.db.Where("model_type = ? AND model_id = ? AND field = ?", model.GetModelName(), model.GetId(), field).
+		Order("id").
+		Find(&attachments).Error
+	return attachments, err
 }
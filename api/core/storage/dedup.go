@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// hashContent returns the hex SHA-256 of the content of r
+func hashContent(r io.Reader) (string, error) {
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, r); err != nil {
+		return "", fmt.Errorf("failed to hash source file: %w", err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// findByHash returns an attachment whose stored object has the given content,
+// or nil when none does. Its row stays locked until tx ends, so DeleteRecord
+// cannot count the references of the object until a new one is saved.
+func findByHash(tx *gorm.DB, hash string, size int64) (*Attachment, error) {
+	var existing Attachment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("hash = ? AND size = ?", hash, size).
+		Order("id").
+		First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// References returns how many attachments share the stored object of
+// attachment, itself included. Attachments with the same content share one
+// object: the first upload stores it and later ones only reference its path,
+// and Delete removes it with the last reference.
+func (as *ActiveStorage) References(attachment *Attachment) (int64, error) {
+	return referencesOf(as.db, attachment.Path)
+}
+
+// referencesOf counts the attachments stored at path
+func referencesOf(db *gorm.DB, path string) (int64, error) {
+	var count int64
+	err := db.Model(&Attachment{}).Where("path = ?", path).Count(&count).Error
+	return count, err
+}
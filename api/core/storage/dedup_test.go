@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func TestAttachDeduplicatesByContent(t *testing.T) {
+	as, files := newScanStorage(t)
+	content := []byte("shared game asset")
+	sum := sha256.Sum256(content)
+
+	first, err := as.Attach(scanModel{id: 1}, "file", fileHeader(t, "asset.txt", content))
+	if err != nil {
+		t.Fatalf("first attach: %v", err)
+	}
+	if first.Hash != hex.EncodeToString(sum[:]) {
+		t.Errorf("hash %q, want the SHA-256 of the content", first.Hash)
+	}
+	encoded, _ := json.Marshal(first)
+	if !strings.Contains(string(encoded), `"hash":"`+first.Hash+`"`) {
+		t.Errorf("metadata %s does not expose the hash", encoded)
+	}
+
+	// The same content under another name and model reuses the stored file
+	second, err := as.Attach(scanModel{id: 2}, "file", fileHeader(t, "copy.txt", content))
+	if err != nil {
+		t.Fatalf("second attach: %v", err)
+	}
+	if second.Id == first.Id || second.Path != first.Path || second.URL != first.URL || second.Filename != "copy.txt" {
+		t.Errorf("second attachment %+v, want its own record sharing %s", second, first.Path)
+	}
+	if n := storedFiles(t, files); n != 1 {
+		t.Errorf("%d files stored, want 1", n)
+	}
+	if references, err := as.References(first); err != nil || references != 2 {
+		t.Errorf("references = %d, %v; want 2", references, err)
+	}
+
+	// Different content is stored separately
+	other, err := as.Attach(scanModel{id: 1}, "file", fileHeader(t, "other.txt", []byte("another asset")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Path == first.Path || other.Hash == first.Hash || storedFiles(t, files) != 2 {
+		t.Errorf("other content %+v shares the first file", other)
+	}
+}
+
+func TestDeleteRemovesFileWithLastReference(t *testing.T) {
+	as, files := newScanStorage(t)
+	content := []byte("shared game asset")
+	first, err := as.Attach(scanModel{id: 1}, "file", fileHeader(t, "asset.txt", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := as.Attach(scanModel{id: 2}, "file", fileHeader(t, "asset.txt", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := filepath.Join(files, first.Path)
+
+	if err := as.Delete(first); err != nil {
+		t.Fatalf("delete first: %v", err)
+	}
+	if _, err := os.Stat(stored); err != nil {
+		t.Errorf("the file was removed while referenced: %v", err)
+	}
+	if references, _ := as.References(second); references != 1 {
+		t.Errorf("references = %d after one delete, want 1", references)
+	}
+
+	if err := as.Delete(second); err != nil {
+		t.Fatalf("delete second: %v", err)
+	}
+	if _, err := os.Stat(stored); !os.IsNotExist(err) {
+		t.Errorf("the file outlived its last reference: %v", err)
+	}
+	if n := storedFiles(t, files); n != 0 {
+		t.Errorf("%d files left, want none", n)
+	}
+
+	// A new upload of the content stores it again
+	again, err := as.Attach(scanModel{id: 3}, "file", fileHeader(t, "asset.txt", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(files, again.Path)); err != nil {
+		t.Errorf("reupload not stored: %v", err)
+	}
+}
+
+func TestDeleteDuringAttachKeepsReusedFile(t *testing.T) {
+	// sqlite has no row locks; transactions taking the write lock when they
+	// begin serialize like SELECT ... FOR UPDATE does elsewhere
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "test.db")+"?_busy_timeout=10000&_txlock=immediate"), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	files := filepath.Join(dir, "files")
+	as, err := NewActiveStorage(db, Config{Provider: "local", Path: files})
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+	as.RegisterAttachment("scan", AttachmentConfig{Field: "file", Path: "uploads"})
+	content := []byte("shared game asset")
+	first, err := as.Attach(scanModel{id: 1}, "file", fileHeader(t, "asset.txt", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Delete the only reference right after the second Attach looked it up
+	var started atomic.Bool
+	deleted := make(chan error, 1)
+	db.Callback().Query().After("gorm:query").Register("test:delete_during_attach", func(tx *gorm.DB) {
+		if tx.Statement.Table != "attachments" || !started.CompareAndSwap(false, true) {
+			return
+		}
+		go func() { deleted <- as.Delete(first) }()
+		// Give the delete time to finish unless it waits for the attach
+		time.Sleep(200 * time.Millisecond)
+	})
+
+	second, err := as.Attach(scanModel{id: 2}, "file", fileHeader(t, "copy.txt", content))
+	if err != nil {
+		t.Fatalf("second attach: %v", err)
+	}
+	if err := <-deleted; err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if second.Path != first.Path {
+		t.Fatalf("second attachment stored at %s, want it to reuse %s", second.Path, first.Path)
+	}
+	if _, err := os.Stat(filepath.Join(files, second.Path)); err != nil {
+		t.Errorf("the file of the new reference was removed: %v", err)
+	}
+	if references, _ := as.References(second); references != 1 {
+		t.Errorf("references = %d, want the second attachment only", references)
+	}
+}
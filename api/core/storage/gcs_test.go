@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeServiceAccountKey writes a service-account JSON key for a fresh RSA key
+func writeServiceAccountKey(t *testing.T) (string, *rsa.PrivateKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: mustPKCS8(t, key)})
+	credentials, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "test",
+		"private_key_id": "1",
+		"private_key":    string(keyPEM),
+		"client_email":   "uploader@test.iam.gserviceaccount.com",
+		"client_id":      "1",
+		"token_uri":      "https://oauth2.googleapis.com/token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(path, credentials, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path, key
+}
+
+func mustPKCS8(t *testing.T, key *rsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestGCSProviderConfigErrors(t *testing.T) {
+	credentials, _ := writeServiceAccountKey(t)
+	userKey := filepath.Join(t.TempDir(), "user.json")
+	if err := os.WriteFile(userKey, []byte(`{"type":"authorized_user"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]Config{
+		"no credentials":    {Bucket: "uploads"},
+		"no bucket":         {CredentialsFile: credentials},
+		"missing file":      {CredentialsFile: filepath.Join(t.TempDir(), "absent.json"), Bucket: "uploads"},
+		"not a service key": {CredentialsFile: userKey, Bucket: "uploads"},
+	}
+	for name, config := range tests {
+		if _, err := NewProvider("gcs", config); err == nil {
+			t.Errorf("%s: NewProvider succeeded, want an error", name)
+		}
+	}
+}
+
+func TestGCSProviderURLs(t *testing.T) {
+	credentials, key := writeServiceAccountKey(t)
+	provider, err := NewProvider("gcs", Config{CredentialsFile: credentials, Bucket: "uploads"})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+
+	if got, want := provider.URL("media/a b.png"), "https://storage.googleapis.com/uploads/media/a%20b.png"; got != want {
+		t.Errorf("URL = %q, want %q", got, want)
+	}
+
+	if _, err := provider.SignedURL("media/a.png", 8*24*time.Hour); err == nil {
+		t.Error("SignedURL accepted an expiry over 7 days")
+	}
+
+	signed, err := provider.SignedURL("media/a.png", 15*time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+	parsed, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("X-Goog-Expires") != "900" || !strings.HasPrefix(query.Get("X-Goog-Credential"), "uploader@test.iam.gserviceaccount.com/") {
+		t.Errorf("signed URL %s has unexpected parameters", signed)
+	}
+
+	// The signature must verify against the service account's public key
+	canonicalQuery, signatureHex, _ := strings.Cut(parsed.RawQuery, "&X-Goog-Signature=")
+	canonicalRequest := strings.Join([]string{"GET", parsed.EscapedPath(), canonicalQuery, "host:" + gcsHost + "\n", "host", "UNSIGNED-PAYLOAD"}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	credential := strings.SplitN(query.Get("X-Goog-Credential"), "/", 2)
+	stringToSign := strings.Join([]string{"GOOG4-RSA-SHA256", query.Get("X-Goog-Date"), credential[1], hex.EncodeToString(requestHash[:])}, "\n")
+	digest := sha256.Sum256([]byte(stringToSign))
+	signature, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+}
@@ -3,9 +3,9 @@ package storage
 import (
 	"fmt"
 	"io"
-	"mime/multipart"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // LocalConfig holds configuration for local storage
@@ -31,50 +31,47 @@ func NewLocalProvider(config LocalConfig) (Provider, error) {
 	}, nil
 }
 
-func (p *localProvider) Upload(file *multipart.FileHeader, config UploadConfig) (*UploadResult, error) {
-	// Create upload directory
-	uploadPath := filepath.Join(p.basePath, config.UploadPath)
-	if err := os.MkdirAll(uploadPath, os.ModePerm); err != nil {
-		return nil, fmt.Errorf("failed to create upload directory: %w", err)
-	}
-
-	// Generate unique filename
-	filename := generateUniqueFilename(file.Filename)
-	dst := filepath.Join(uploadPath, filename)
+func (p *localProvider) Put(path string, body io.Reader, size int64, contentType string) error {
+	dst := p.fullPath(path)
 
-	// Open source file
-	src, err := file.Open()
-	if err != nil {
-		return nil, fmt.Errorf("failed to open source file: %w", err)
+	// Create upload directory
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create upload directory: %w", err)
 	}
-	defer src.Close()
 
 	// Create destination file
 	out, err := os.Create(dst)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create destination file: %w", err)
+		return fmt.Errorf("failed to create destination file: %w", err)
 	}
 	defer out.Close()
 
 	// Copy file
-	if _, err = io.Copy(out, src); err != nil {
-		return nil, fmt.Errorf("failed to copy file: %w", err)
+	if _, err = io.Copy(out, body); err != nil {
+		return fmt.Errorf("failed to copy file: %w", err)
 	}
 
-	relativePath := filepath.Join(config.UploadPath, filename)
+	return nil
+}
 
-	return &UploadResult{
-		Filename: filename,
-		Path:     relativePath,
-		Size:     file.Size,
-	}, nil
+func (p *localProvider) Get(path string) (io.ReadCloser, error) {
+	return os.Open(p.fullPath(path))
 }
 
 func (p *localProvider) Delete(path string) error {
-	fullPath := filepath.Join(p.basePath, path)
-	return os.Remove(fullPath)
+	return os.Remove(p.fullPath(path))
 }
 
-func (p *localProvider) GetURL(path string) string {
+func (p *localProvider) URL(path string) string {
 	return fmt.Sprintf("%s/%s", p.baseURL, path)
 }
+
+// SignedURL returns the public URL: local files are served statically and
+// have no access control to sign against
+func (p *localProvider) SignedURL(path string, expiry time.Duration) (string, error) {
+	return p.URL(path), nil
+}
+
+func (p *localProvider) fullPath(path string) string {
+	return filepath.Join(p.basePath, filepath.FromSlash(path))
+}
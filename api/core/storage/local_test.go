@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalProviderThroughInterface(t *testing.T) {
+	var provider Provider
+	provider, err := NewProvider("local", Config{Path: t.TempDir(), BaseURL: "http://localhost/storage"})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+
+	const key = "avatars/2024/me.txt"
+	if err := provider.Put(key, strings.NewReader("hello"), -1, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	body, err := provider.Get(key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	content, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || string(content) != "hello" {
+		t.Errorf("Get read %q, %v, want hello", content, err)
+	}
+
+	if got, want := provider.URL(key), "http://localhost/storage/"+key; got != want {
+		t.Errorf("URL = %q, want %q", got, want)
+	}
+	if signed, err := provider.SignedURL(key, time.Minute); err != nil || signed != provider.URL(key) {
+		t.Errorf("SignedURL = %q, %v, want the public URL", signed, err)
+	}
+
+	if err := provider.Delete(key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := provider.Get(key); err == nil {
+		t.Error("Get found the deleted object")
+	}
+	if err := provider.Delete(key); err == nil {
+		t.Error("deleting a missing object succeeded")
+	}
+}
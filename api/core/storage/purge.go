@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"path"
+)
+
+// trashPrefix is where StagedPurge keeps the copies of purged objects
+const trashPrefix = ".trash/"
+
+// StagedPurge deletes stored objects inside a database transaction so that
+// they can be put back when it rolls back. Each object is copied aside before
+// it is deleted; Commit drops the copies once the transaction committed and
+// Rollback restores the objects from them.
+type StagedPurge struct {
+	provider Provider
+	staged   []string // Paths of the purged objects
+}
+
+// StagePurge starts a purge whose deletes can be undone
+func (as *ActiveStorage) StagePurge() *StagedPurge {
+	return &StagedPurge{provider: as.provider}
+}
+
+// Purge copies the stored object of attachment aside and deletes it
+func (p *StagedPurge) Purge(attachment *Attachment) error {
+	if err := p.copy(attachment.Path, trashPrefix+attachment.Path, attachment.Size); err != nil {
+		return fmt.Errorf("failed to stage %s: %w", attachment.Path, err)
+	}
+	if err := p.provider.Delete(attachment.Path); err != nil {
+		_ = p.provider.Delete(trashPrefix + attachment.Path)
+		return err
+	}
+	p.staged = append(p.staged, attachment.Path)
+	return nil
+}
+
+// Commit drops the copies of the purged objects. A copy that cannot be
+// removed is only garbage, so callers log the error.
+func (p *StagedPurge) Commit() error {
+	var errs []error
+	for _, staged := range p.staged {
+		if err := p.provider.Delete(trashPrefix + staged); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	p.staged = nil
+	return errors.Join(errs...)
+}
+
+// Rollback puts the purged objects back from their copies
+func (p *StagedPurge) Rollback() error {
+	var errs []error
+	for _, staged := range p.staged {
+		if err := p.copy(trashPrefix+staged, staged, -1); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", staged, err))
+			continue
+		}
+		_ = p.provider.Delete(trashPrefix + staged)
+	}
+	p.staged = nil
+	return errors.Join(errs...)
+}
+
+func (p *StagedPurge) copy(from, to string, size int64) error {
+	src, err := p.provider.Get(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return p.provider.Put(to, src, size, mime.TypeByExtension(path.Ext(from)))
+}
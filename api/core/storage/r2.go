@@ -2,13 +2,15 @@ package storage
 
 import (
 	"fmt"
-	"mime/multipart"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 )
 
 // R2Config holds configuration for Cloudflare R2 storage
@@ -58,35 +60,33 @@ func NewR2Provider(config R2Config) (Provider, error) {
 	}, nil
 }
 
-func (p *r2Provider) Upload(file *multipart.FileHeader, config UploadConfig) (*UploadResult, error) {
-	// Open source file
-	src, err := file.Open()
-	if err != nil {
-		return nil, fmt.Errorf("failed to open source file: %w", err)
+func (p *r2Provider) Put(path string, body io.Reader, size int64, contentType string) error {
+	input := &s3manager.UploadInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(path),
+		Body:   body,
+		// Note: R2 doesn't support ACL, so we remove the ACL setting
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
 	}
-	defer src.Close()
 
-	// Generate unique filename
-	filename := generateUniqueFilename(file.Filename)
-	key := fmt.Sprintf("%s/%s", config.UploadPath, filename)
+	// The upload manager accepts any reader, streaming large bodies in parts
+	if _, err := s3manager.NewUploaderWithClient(p.client).Upload(input); err != nil {
+		return fmt.Errorf("failed to upload to R2: %w", err)
+	}
+	return nil
+}
 
-	// Upload to R2
-	_, err = p.client.PutObject(&s3.PutObjectInput{
-		Bucket:      aws.String(p.bucket),
-		Key:         aws.String(key),
-		Body:        src,
-		ContentType: aws.String(file.Header.Get("Content-Type")),
-		// Note: R2 doesn't support ACL, so we remove the ACL setting
+func (p *r2Provider) Get(path string) (io.ReadCloser, error) {
+	output, err := p.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(path),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to upload to R2: %w", err)
+		return nil, fmt.Errorf("failed to download from R2: %w", err)
 	}
-
-	return &UploadResult{
-		Filename: filename,
-		Path:     key,
-		Size:     file.Size,
-	}, nil
+	return output.Body, nil
 }
 
 func (p *r2Provider) Delete(path string) error {
@@ -97,7 +97,7 @@ func (p *r2Provider) Delete(path string) error {
 	return err
 }
 
-func (p *r2Provider) GetURL(path string) string {
+func (p *r2Provider) URL(path string) string {
 	// Always prefer CDN for R2 storage
 	if p.cdn != "" {
 		return fmt.Sprintf("%s/%s", strings.TrimRight(p.cdn, "/"), path)
@@ -109,3 +109,11 @@ func (p *r2Provider) GetURL(path string) string {
 	// Last resort: use R2 URL
 	return fmt.Sprintf("https://%s/%s/%s", p.endpoint, p.bucket, path)
 }
+
+func (p *r2Provider) SignedURL(path string, expiry time.Duration) (string, error) {
+	req, _ := p.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(path),
+	})
+	return req.Presign(expiry)
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Open returns a seekable reader over an attachment's file, suitable for
+// http.ServeContent. Providers whose reader cannot seek are reopened on a
+// backward seek and skip ahead on a forward one, so ranged reads work with
+// every provider.
+func (as *ActiveStorage) Open(attachment *Attachment) (io.ReadSeekCloser, error) {
+	body, err := as.provider.Get(attachment.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open attachment: %w", err)
+	}
+	if seeker, ok := body.(io.ReadSeekCloser); ok {
+		return seeker, nil
+	}
+
+	return &seekingReader{
+		open: func() (io.ReadCloser, error) { return as.provider.Get(attachment.Path) },
+		body: body,
+		size: attachment.Size,
+	}, nil
+}
+
+// seekingReader emulates Seek over a forward-only provider reader of a known size
+type seekingReader struct {
+	open   func() (io.ReadCloser, error)
+	body   io.ReadCloser
+	size   int64
+	pos    int64 // Position of body
+	offset int64 // Position requested by Seek
+}
+
+func (r *seekingReader) Read(p []byte) (int, error) {
+	if r.offset < r.pos {
+		r.body.Close()
+		body, err := r.open()
+		if err != nil {
+			return 0, err
+		}
+		r.body, r.pos = body, 0
+	}
+	if r.offset > r.pos {
+		skipped, err := io.CopyN(io.Discard, r.body, r.offset-r.pos)
+		r.pos += skipped
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.body.Read(p)
+	r.pos += int64(n)
+	r.offset = r.pos
+	return n, err
+}
+
+func (r *seekingReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+func (r *seekingReader) Close() error {
+	return r.body.Close()
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ProviderFactory builds a storage provider from the storage configuration
+type ProviderFactory func(config Config) (Provider, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]ProviderFactory)
+)
+
+func init() {
+	RegisterProvider("local", func(config Config) (Provider, error) {
+		return NewLocalProvider(LocalConfig{
+			BasePath: config.Path,
+			BaseURL:  config.BaseURL,
+		})
+	})
+	RegisterProvider("s3", func(config Config) (Provider, error) {
+		return NewS3Provider(S3Config{
+			APIKey:          config.APIKey,
+			APISecret:       config.APISecret,
+			AccessKeyID:     config.APIKey,
+			AccessKeySecret: config.APISecret,
+			AccountID:       config.AccountID,
+			Endpoint:        config.Endpoint,
+			Bucket:          config.Bucket,
+			BaseURL:         config.BaseURL,
+			Region:          config.Region,
+		})
+	})
+	RegisterProvider("r2", func(config Config) (Provider, error) {
+		return NewR2Provider(R2Config{
+			AccessKeyID:     config.APIKey,
+			AccessKeySecret: config.APISecret,
+			AccountID:       config.AccountID,
+			Bucket:          config.Bucket,
+			BaseURL:         config.BaseURL,
+			CDN:             config.CDN,
+		})
+	})
+	RegisterProvider("gcs", func(config Config) (Provider, error) {
+		return NewGCSProvider(GCSConfig{
+			CredentialsFile: config.CredentialsFile,
+			Bucket:          config.Bucket,
+			BaseURL:         config.BaseURL,
+			CDN:             config.CDN,
+		})
+	})
+}
+
+// RegisterProvider makes a storage provider available under name (case-insensitive).
+// Third-party providers call it from an init function; registering an existing
+// name replaces the previous factory.
+func RegisterProvider(name string, factory ProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	providers[strings.ToLower(name)] = factory
+}
+
+// NewProvider builds the provider registered under name
+func NewProvider(name string, config Config) (Provider, error) {
+	providersMu.RLock()
+	factory, ok := providers[strings.ToLower(name)]
+	providersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported storage provider: %s", name)
+	}
+	return factory(config)
+}
+
+// Providers returns the names of all registered providers
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
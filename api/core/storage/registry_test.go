@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// memoryProvider is a third-party style provider keeping objects in memory
+type memoryProvider struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	bucket  string
+}
+
+func (p *memoryProvider) Put(path string, body io.Reader, size int64, contentType string) error {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.objects[path] = content
+	return nil
+}
+
+func (p *memoryProvider) Get(path string) (io.ReadCloser, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	content, ok := p.objects[path]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", path)
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (p *memoryProvider) Delete(path string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.objects, path)
+	return nil
+}
+
+func (p *memoryProvider) URL(path string) string {
+	return "memory://" + p.bucket + "/" + path
+}
+
+func (p *memoryProvider) SignedURL(path string, expiry time.Duration) (string, error) {
+	return p.URL(path) + "?expires=" + expiry.String(), nil
+}
+
+func TestRegisteredProviderIsSelectedByName(t *testing.T) {
+	memory := &memoryProvider{objects: make(map[string][]byte)}
+	RegisterProvider("Memory", func(config Config) (Provider, error) {
+		memory.bucket = config.Bucket
+		return memory, nil
+	})
+	t.Cleanup(func() {
+		providersMu.Lock()
+		delete(providers, "memory")
+		providersMu.Unlock()
+	})
+
+	if !slices.Contains(Providers(), "memory") {
+		t.Errorf("Providers() = %v, want it to list memory", Providers())
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	as, err := NewActiveStorage(db, Config{Provider: "MEMORY", Path: t.TempDir(), Bucket: "uploads"})
+	if err != nil {
+		t.Fatalf("NewActiveStorage: %v", err)
+	}
+	if as.provider != Provider(memory) {
+		t.Fatalf("ActiveStorage uses %T, want the registered provider", as.provider)
+	}
+
+	if err := as.provider.Put("a.txt", strings.NewReader("hi"), 2, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(memory.objects["a.txt"]); got != "hi" {
+		t.Errorf("stored %q, want hi", got)
+	}
+	if got := as.provider.URL("a.txt"); got != "memory://uploads/a.txt" {
+		t.Errorf("URL = %q, want the provider's URL built from the config", got)
+	}
+}
+
+func TestUnknownProviderIsRejected(t *testing.T) {
+	if _, err := NewProvider("ftp", Config{}); err == nil || !strings.Contains(err.Error(), "unsupported storage provider: ftp") {
+		t.Errorf("NewProvider(ftp) = %v, want an unsupported provider error", err)
+	}
+}
@@ -2,12 +2,14 @@ package storage
 
 import (
 	"fmt"
-	"mime/multipart"
+	"io"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 )
 
 // S3Config holds configuration for S3 storage
@@ -54,34 +56,33 @@ func NewS3Provider(config S3Config) (Provider, error) {
 	}, nil
 }
 
-func (p *s3Provider) Upload(file *multipart.FileHeader, config UploadConfig) (*UploadResult, error) {
-	// Open source file
-	src, err := file.Open()
-	if err != nil {
-		return nil, fmt.Errorf("failed to open source file: %w", err)
+func (p *s3Provider) Put(path string, body io.Reader, size int64, contentType string) error {
+	input := &s3manager.UploadInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(path),
+		Body:   body,
+		ACL:    aws.String("public-read"),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
 	}
-	defer src.Close()
 
-	// Generate unique filename
-	filename := generateUniqueFilename(file.Filename)
-	key := fmt.Sprintf("%s/%s", config.UploadPath, filename)
+	// The upload manager accepts any reader, streaming large bodies in parts
+	if _, err := s3manager.NewUploaderWithClient(p.client).Upload(input); err != nil {
+		return fmt.Errorf("failed to upload to S3: %w", err)
+	}
+	return nil
+}
 
-	// Upload to S3
-	_, err = p.client.PutObject(&s3.PutObjectInput{
+func (p *s3Provider) Get(path string) (io.ReadCloser, error) {
+	output, err := p.client.GetObject(&s3.GetObjectInput{
 		Bucket: aws.String(p.bucket),
-		Key:    aws.String(key),
-		Body:   src,
-		ACL:    aws.String("public-read"),
+		Key:    aws.String(path),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to upload to S3: %w", err)
+		return nil, fmt.Errorf("failed to download from S3: %w", err)
 	}
-
-	return &UploadResult{
-		Filename: filename,
-		Path:     key,
-		Size:     file.Size,
-	}, nil
+	return output.Body, nil
 }
 
 func (p *s3Provider) Delete(path string) error {
@@ -92,6 +93,14 @@ func (p *s3Provider) Delete(path string) error {
 	return err
 }
 
-func (p *s3Provider) GetURL(path string) string {
+func (p *s3Provider) URL(path string) string {
 	return fmt.Sprintf("https://%s/%s/%s", p.endpoint, p.bucket, path)
 }
+
+func (p *s3Provider) SignedURL(path string, expiry time.Duration) (string, error) {
+	req, _ := p.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(path),
+	})
+	return req.Presign(expiry)
+}
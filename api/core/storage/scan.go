@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// Errors returned by Attach for files rejected after looking at their content
+var (
+	ErrContentTypeMismatch = errors.New("file content does not match its extension")
+	ErrFileRejected        = errors.New("file rejected by scan")
+)
+
+// ScanHook inspects an upload before it is stored, e.g. with an antivirus
+// engine. Returning an error rejects the file; Attach wraps it with
+// ErrFileRejected.
+type ScanHook interface {
+	Scan(file *multipart.FileHeader) error
+}
+
+// ScanHookFunc adapts a function to ScanHook
+type ScanHookFunc func(file *multipart.FileHeader) error
+
+func (f ScanHookFunc) Scan(file *multipart.FileHeader) error {
+	return f(file)
+}
+
+// extensionContentTypes lists the sniffed content types accepted for an
+// extension. Extensions not listed are not sniffed.
+var extensionContentTypes = map[string][]string{
+	".jpg":  {"image/jpeg"},
+	".jpeg": {"image/jpeg"},
+	".png":  {"image/png"},
+	".gif":  {"image/gif"},
+	".webp": {"image/webp"},
+	".bmp":  {"image/bmp"},
+	".ico":  {"image/x-icon"},
+	".pdf":  {"application/pdf"},
+	".zip":  {"application/zip"},
+	".wav":  {"audio/wave"},
+	".ogg":  {"application/ogg", "audio/ogg"},
+	".webm": {"video/webm"},
+	".mp4":  {"video/mp4"},
+	".avi":  {"video/avi"},
+	// MP3 files without an ID3 tag have no signature DetectContentType knows
+	".mp3": {"audio/mpeg", "application/octet-stream"},
+	".txt": {"text/plain"},
+	".csv": {"text/plain"},
+}
+
+// SetScanHook installs hook to run on every attached file, or removes the
+// hook when it is nil
+func (as *ActiveStorage) SetScanHook(hook ScanHook) {
+	as.scanHook = hook
+}
+
+// inspectFile checks that the file content matches its extension and runs
+// the scan hook
+func (as *ActiveStorage) inspectFile(file *multipart.FileHeader) error {
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if accepted, ok := extensionContentTypes[ext]; ok {
+		detected, err := sniffContentType(file)
+		if err != nil {
+			return err
+		}
+		if !contentTypeAccepted(detected, accepted) {
+			return fmt.Errorf("%w: %q looks like %s", ErrContentTypeMismatch, ext, detected)
+		}
+	}
+
+	if as.scanHook != nil {
+		if err := as.scanHook.Scan(file); err != nil {
+			return fmt.Errorf("%w: %v", ErrFileRejected, err)
+		}
+	}
+	return nil
+}
+
+// sniffContentType detects the content type from the first 512 bytes
+func sniffContentType(file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer src.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read source file: %w", err)
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// contentTypeAccepted ignores parameters such as "; charset=utf-8"
+func contentTypeAccepted(detected string, accepted []string) bool {
+	mediaType, _, _ := strings.Cut(detected, ";")
+	for _, contentType := range accepted {
+		if strings.EqualFold(strings.TrimSpace(mediaType), contentType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// pngHeader is the signature of a PNG file
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+type scanModel struct{ id uint }
+
+func (m scanModel) GetId() uint          { return m.id }
+func (m scanModel) GetModelName() string { return "scan" }
+
+// newScanStorage returns local storage accepting images and text for scanModel
+func newScanStorage(t *testing.T) (*ActiveStorage, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "test.db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	files := filepath.Join(dir, "files")
+	as, err := NewActiveStorage(db, Config{Provider: "local", Path: files})
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+	as.RegisterAttachment("scan", AttachmentConfig{
+		Field:             "file",
+		Path:              "uploads",
+		AllowedExtensions: []string{".png", ".jpg", ".txt", ".bin"},
+		MaxFileSize:       1 << 20,
+	})
+	return as, files
+}
+
+// fileHeader returns an uploaded multipart file
+func fileHeader(t *testing.T, filename string, content []byte) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatal(err)
+	}
+	return req.MultipartForm.File["file"][0]
+}
+
+// storedFiles counts the files written to storage
+func storedFiles(t *testing.T, dir string) int {
+	t.Helper()
+	count := 0
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
+func TestAttachRejectsSpoofedExtension(t *testing.T) {
+	as, files := newScanStorage(t)
+
+	spoofed := map[string][]byte{
+		"photo.png":  []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff"), // A Windows executable
+		"script.jpg": []byte("<html><script>alert(1)</script></html>"),
+		"notes.txt":  pngHeader,
+	}
+	for name, content := range spoofed {
+		if _, err := as.Attach(scanModel{id: 1}, "file", fileHeader(t, name, content)); !errors.Is(err, ErrContentTypeMismatch) {
+			t.Errorf("%s: got %v, want ErrContentTypeMismatch", name, err)
+		}
+	}
+	if n := storedFiles(t, files); n != 0 {
+		t.Errorf("%d spoofed files were stored", n)
+	}
+
+	// Matching content and extensions without a known signature are stored
+	for name, content := range map[string][]byte{
+		"photo.png": pngHeader,
+		"notes.txt": []byte("just text"),
+		"data.bin":  []byte("MZ anything goes"),
+	} {
+		if _, err := as.Attach(scanModel{id: 1}, "file", fileHeader(t, name, content)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestAttachStreamRejectsSpoofedExtension(t *testing.T) {
+	as, files := newScanStorage(t)
+
+	stream := &FileStream{Filename: "photo.png", Reader: bytes.NewReader([]byte("<html></html>"))}
+	if _, err := as.AttachStream(scanModel{id: 1}, "file", stream); !errors.Is(err, ErrContentTypeMismatch) {
+		t.Errorf("spoofed stream: got %v, want ErrContentTypeMismatch", err)
+	}
+	if n := storedFiles(t, files); n != 0 {
+		t.Errorf("%d spoofed files were stored", n)
+	}
+
+	stream = &FileStream{Filename: "photo.png", Reader: bytes.NewReader(pngHeader)}
+	if _, err := as.AttachStream(scanModel{id: 1}, "file", stream); err != nil {
+		t.Errorf("real png stream: %v", err)
+	}
+}
+
+func TestScanHookRejectsFiles(t *testing.T) {
+	as, files := newScanStorage(t)
+
+	var scanned []string
+	as.SetScanHook(ScanHookFunc(func(file *multipart.FileHeader) error {
+		scanned = append(scanned, file.Filename)
+		if file.Filename == "eicar.txt" {
+			return errors.New("Eicar-Test-Signature found")
+		}
+		return nil
+	}))
+
+	if _, err := as.Attach(scanModel{id: 1}, "file", fileHeader(t, "eicar.txt", []byte("X5O!P%@AP"))); !errors.Is(err, ErrFileRejected) {
+		t.Errorf("infected file: got %v, want ErrFileRejected", err)
+	}
+	if n := storedFiles(t, files); n != 0 {
+		t.Errorf("%d rejected files were stored", n)
+	}
+	if _, err := as.Attach(scanModel{id: 1}, "file", fileHeader(t, "clean.txt", []byte("clean"))); err != nil {
+		t.Errorf("clean file: %v", err)
+	}
+	// Spoofed files are rejected before they reach the hook
+	as.Attach(scanModel{id: 1}, "file", fileHeader(t, "photo.png", []byte("text")))
+	if len(scanned) != 2 {
+		t.Errorf("scanned %v, want only the two files with matching content", scanned)
+	}
+
+	// Streams cannot be scanned, so they are refused while a hook is set
+	if as.CanStream() {
+		t.Error("CanStream with a scan hook")
+	}
+	stream := &FileStream{Filename: "clean.txt", Reader: bytes.NewReader([]byte("clean"))}
+	if _, err := as.AttachStream(scanModel{id: 1}, "file", stream); !errors.Is(err, ErrStreamingUnsupported) {
+		t.Errorf("AttachStream with a scan hook: got %v, want ErrStreamingUnsupported", err)
+	}
+
+	as.SetScanHook(nil)
+	if !as.CanStream() {
+		t.Error("CanStream after removing the scan hook")
+	}
+}
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrStreamingUnsupported is returned by AttachStream while a scan hook is
+// installed: scan hooks inspect a whole multipart file, which a stream is not
+var ErrStreamingUnsupported = errors.New("streaming uploads are not supported with a scan hook")
+
+// FileStream is an upload read straight from the request, e.g. a
+// multipart.Part, whose size is not known up front
+type FileStream struct {
+	Filename    string
+	ContentType string
+	Reader      io.Reader
+}
+
+// CanStream reports whether AttachStream can store uploads. Callers fall
+// back to Attach with a parsed multipart form when it cannot.
+func (as *ActiveStorage) CanStream() bool {
+	return as.scanHook == nil
+}
+
+// AttachStream stores file like Attach, but copies it from its reader to the
+// provider without holding it in memory or spilling it to a temporary file.
+// Only the first 512 bytes are buffered to check the content against the
+// extension. A file growing past the configured maximum size is removed
+// again and ErrFileTooLarge is returned.
+func (as *ActiveStorage) AttachStream(model Attachable, field string, file *FileStream) (*Attachment, error) {
+	if !as.CanStream() {
+		return nil, ErrStreamingUnsupported
+	}
+
+	config, err := as.getConfig(model.GetModelName(), field)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateExtension(file.Filename, config); err != nil {
+		return nil, err
+	}
+
+	// Sniff the head and put it back in front of the rest of the stream
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file.Reader, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read source file: %w", err)
+	}
+	head = head[:n]
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if accepted, ok := extensionContentTypes[ext]; ok {
+		detected := http.DetectContentType(head)
+		if !contentTypeAccepted(detected, accepted) {
+			return nil, fmt.Errorf("%w: %q looks like %s", ErrContentTypeMismatch, ext, detected)
+		}
+	}
+
+	hasher := sha256.New()
+	body := &countingReader{
+		reader: io.TeeReader(io.MultiReader(bytes.NewReader(head), file.Reader), hasher),
+		limit:  config.MaxFileSize,
+	}
+
+	key := path.Join(filepath.ToSlash(config.Path), model.GetModelName(), field, generateUniqueFilename(file.Filename))
+	if err := as.provider.Put(key, body, -1, file.ContentType); err != nil {
+		_ = as.provider.Delete(key)
+		if body.exceeded {
+			return nil, fmt.Errorf("%w: maximum allowed size is %d bytes", ErrFileTooLarge, config.MaxFileSize)
+		}
+		return nil, err
+	}
+
+	attachment := &Attachment{
+		ModelType: model.GetModelName(),
+		ModelId:   model.GetId(),
+		Field:     field,
+		Filename:  file.Filename,
+		Size:      body.read,
+		Hash:      hex.EncodeToString(hasher.Sum(nil)),
+		Path:      key,
+		URL:       as.provider.URL(key),
+	}
+
+	// The hash is only known once stored; keep an identical file stored
+	// before and drop this copy
+	err = as.db.Transaction(func(tx *gorm.DB) error {
+		existing, err := findByHash(tx, attachment.Hash, attachment.Size)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			attachment.Path = existing.Path
+			attachment.URL = existing.URL
+		}
+		return tx.Create(attachment).Error
+	})
+	if err != nil || attachment.Path != key {
+		_ = as.provider.Delete(key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// countingReader counts the bytes read and fails once more than limit bytes
+// were read, unless limit is 0
+type countingReader struct {
+	reader   io.Reader
+	limit    int64
+	read     int64
+	exceeded bool
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	if r.limit > 0 && r.read > r.limit {
+		r.exceeded = true
+		return n, ErrFileTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// generatedFile reads size bytes of a PNG signature followed by filler,
+// without ever holding the file in memory
+type generatedFile struct {
+	size, read int64
+}
+
+func (f *generatedFile) Read(p []byte) (int, error) {
+	if f.read >= f.size {
+		return 0, io.EOF
+	}
+	if left := f.size - f.read; int64(len(p)) > left {
+		p = p[:left]
+	}
+	for i := range p {
+		if offset := f.read + int64(i); offset < int64(len(pngHeader)) {
+			p[i] = pngHeader[offset]
+		} else {
+			p[i] = byte(offset % 251)
+		}
+	}
+	f.read += int64(len(p))
+	return len(p), nil
+}
+
+// generatedHash returns the SHA-256 of a generated file of size bytes
+func generatedHash(size int64) string {
+	hasher := sha256.New()
+	io.Copy(hasher, &generatedFile{size: size})
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// allocatedDuring returns the bytes allocated on the heap while fn ran
+func allocatedDuring(fn func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	fn()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+func TestAttachStreamKeepsMemoryBounded(t *testing.T) {
+	as, files := newScanStorage(t)
+	as.RegisterAttachment("scan", AttachmentConfig{
+		Field:             "file",
+		Path:              "uploads",
+		AllowedExtensions: []string{".png"},
+		MaxFileSize:       64 << 20,
+	})
+	const size = 48 << 20
+
+	var attachment *Attachment
+	var err error
+	allocated := allocatedDuring(func() {
+		attachment, err = as.AttachStream(scanModel{id: 1}, "file", &FileStream{
+			Filename:    "large.png",
+			ContentType: "image/png",
+			Reader:      &generatedFile{size: size},
+		})
+	})
+	if err != nil {
+		t.Fatalf("AttachStream: %v", err)
+	}
+	if allocated > 4<<20 {
+		t.Errorf("streaming %d MB allocated %d KB, want it bounded by the copy buffers", size>>20, allocated>>10)
+	}
+
+	if attachment.Size != size || attachment.Hash != generatedHash(size) {
+		t.Errorf("attachment size %d hash %s, want the generated file", attachment.Size, attachment.Hash)
+	}
+	info, err := os.Stat(filepath.Join(files, attachment.Path))
+	if err != nil || info.Size() != size {
+		t.Errorf("stored file: %v, %v; want %d bytes", info, err, size)
+	}
+}
+
+func TestAttachStreamStopsAtMaxFileSize(t *testing.T) {
+	as, files := newScanStorage(t)
+
+	_, err := as.AttachStream(scanModel{id: 1}, "file", &FileStream{
+		Filename: "large.png",
+		Reader:   &generatedFile{size: 3 << 20},
+	})
+	if !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("got %v, want ErrFileTooLarge", err)
+	}
+	if n := storedFiles(t, files); n != 0 {
+		t.Errorf("%d files left in storage", n)
+	}
+	var records int64
+	as.db.Model(&Attachment{}).Count(&records)
+	if records != 0 {
+		t.Errorf("%d attachment records for a rejected upload", records)
+	}
+}
+
+func TestAttachStreamReusesIdenticalFile(t *testing.T) {
+	as, files := newScanStorage(t)
+
+	var paths []string
+	for id := uint(1); id <= 2; id++ {
+		attachment, err := as.AttachStream(scanModel{id: id}, "file", &FileStream{
+			Filename: "same.png",
+			Reader:   &generatedFile{size: 4096},
+		})
+		if err != nil {
+			t.Fatalf("AttachStream %d: %v", id, err)
+		}
+		paths = append(paths, attachment.Path)
+	}
+	if paths[0] != paths[1] {
+		t.Errorf("identical uploads stored at %v, want one path", paths)
+	}
+	if n := storedFiles(t, files); n != 1 {
+		t.Errorf("%d files stored, want 1", n)
+	}
+}
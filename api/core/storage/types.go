@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/textproto"
 	"os"
@@ -22,6 +23,7 @@ type Attachment struct {
 	Filename  string    `json:"filename"`
 	Path      string    `json:"path"`
 	Size      int64     `json:"size"`
+	Hash      string    `json:"hash" gorm:"size:64;index"` // Hex SHA-256 of the content; attachments with the same hash share Path
 	URL       string    `json:"url"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -91,6 +93,9 @@ type Config struct {
 	Bucket    string
 	CDN       string
 	Region    string
+
+	// CredentialsFile is the path to a service-account JSON key (gcs)
+	CredentialsFile string
 }
 
 // Attachable interface for models that can have attachments
@@ -99,11 +104,20 @@ type Attachable interface {
 	GetModelName() string
 }
 
-// Provider interface for storage providers
+// Provider interface for storage providers.
+// Paths are slash-separated keys relative to the provider root.
 type Provider interface {
-	Upload(file *multipart.FileHeader, config UploadConfig) (*UploadResult, error)
+	// Put stores the content of body under path. size is -1 when the
+	// length of a streamed body is not known up front.
+	Put(path string, body io.Reader, size int64, contentType string) error
+	// Get opens the object stored under path; the caller closes it
+	Get(path string) (io.ReadCloser, error)
+	// Delete removes the object stored under path
 	Delete(path string) error
-	GetURL(path string) string
+	// URL returns the public URL of the object
+	URL(path string) string
+	// SignedURL returns a URL granting temporary read access to the object
+	SignedURL(path string, expiry time.Duration) (string, error)
 }
 
 // ActiveStorage handles file storage operations
@@ -112,18 +126,5 @@ type ActiveStorage struct {
 	provider    Provider
 	defaultPath string
 	configs     map[string]map[string]AttachmentConfig
-}
-
-// UploadConfig holds configuration for file uploads
-type UploadConfig struct {
-	AllowedExtensions []string
-	MaxFileSize       int64
-	UploadPath        string
-}
-
-// UploadResult holds the result of a file upload
-type UploadResult struct {
-	Filename string
-	Path     string
-	Size     int64
+	scanHook    ScanHook
 }
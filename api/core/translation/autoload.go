@@ -0,0 +1,170 @@
+package translation
+
+import (
+	"errors"
+	"reflect"
+
+	"base/core/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// autoLoadBatchSize caps the ids per translations query, keeping the IN list
+// below the bound parameter limits of the drivers
+const autoLoadBatchSize = 500
+
+var fieldType = reflect.TypeOf(Field{})
+
+// defaultService is the service registered with EnableAutoLoad, used by
+// Field.LoadTranslations
+var defaultService *TranslationService
+
+// AutoLoader is a GORM plugin that fills the Values of Field columns after
+// every query. Translations are looked up by model name, model id and the
+// column name as key, with one query per model for all rows found. The model
+// name is GetModelName() when the model defines it, else its table name.
+type AutoLoader struct {
+	Service *TranslationService
+}
+
+// EnableAutoLoad registers the AutoLoader on db and makes service the one
+// Field.LoadTranslations uses. Registering again is a no-op.
+func EnableAutoLoad(db *gorm.DB, service *TranslationService) error {
+	defaultService = service
+	if err := db.Use(&AutoLoader{Service: service}); err != nil && !errors.Is(err, gorm.ErrRegistered) {
+		return err
+	}
+	return nil
+}
+
+// Name implements gorm.Plugin
+func (p *AutoLoader) Name() string {
+	return "translation_autoload"
+}
+
+// Initialize implements gorm.Plugin. The callback runs after preloading and
+// before the AfterFind hooks, so those already see the translations.
+func (p *AutoLoader) Initialize(db *gorm.DB) error {
+	return db.Callback().Query().
+		After("gorm:preload").
+		Before("gorm:after_query").
+		Register("translation:autoload", p.afterQuery)
+}
+
+func (p *AutoLoader) afterQuery(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil || db.Statement.RowsAffected == 0 {
+		return
+	}
+	sch := db.Statement.Schema
+	primary := sch.PrioritizedPrimaryField
+	if primary == nil {
+		return
+	}
+
+	var fields []*schema.Field
+	keys := make([]string, 0)
+	for _, field := range sch.Fields {
+		if field.FieldType == fieldType && field.DBName != "" {
+			fields = append(fields, field)
+			keys = append(keys, field.DBName)
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	ctx := db.Statement.Context
+	rows := make(map[uint][]reflect.Value)
+	ids := make([]uint, 0)
+	eachRow(db.Statement.ReflectValue, func(row reflect.Value) {
+		value, zero := primary.ValueOf(ctx, row)
+		if zero {
+			return
+		}
+		id, ok := toUint(value)
+		if !ok {
+			return
+		}
+		if _, seen := rows[id]; !seen {
+			ids = append(ids, id)
+		}
+		rows[id] = append(rows[id], row)
+	})
+	if len(ids) == 0 {
+		return
+	}
+
+	modelName := sch.Table
+	if named, ok := reflect.New(sch.ModelType).Interface().(interface{ GetModelName() string }); ok {
+		modelName = named.GetModelName()
+	}
+
+	session := p.Service.DB.Session(&gorm.Session{NewDB: true, Context: ctx})
+	for start := 0; start < len(ids); start += autoLoadBatchSize {
+		end := min(start+autoLoadBatchSize, len(ids))
+
+		var translations []Translation
+		err := session.
+			Where("model = ? AND model_id IN ?", modelName, ids[start:end]).
+			Where(clause.IN{Column: clause.Column{Name: "key"}, Values: toAny(keys)}).
+			Find(&translations).Error
+		if err != nil {
+			// The originals are still usable, so the find itself does not fail
+			p.Service.Logger.Warn("Failed to load translations",
+				logger.String("model", modelName),
+				logger.String("error", err.Error()))
+			return
+		}
+
+		for _, t := range translations {
+			for _, row := range rows[t.ModelId] {
+				for _, field := range fields {
+					if field.DBName != t.Key {
+						continue
+					}
+					if value := field.ReflectValueOf(ctx, row); value.CanAddr() {
+						value.Addr().Interface().(*Field).SetTranslation(t.Language, t.Value)
+					}
+				}
+			}
+		}
+	}
+}
+
+// eachRow calls fn with every struct in a query destination
+func eachRow(value reflect.Value, fn func(reflect.Value)) {
+	value = reflect.Indirect(value)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if row := reflect.Indirect(value.Index(i)); row.Kind() == reflect.Struct {
+				fn(row)
+			}
+		}
+	case reflect.Struct:
+		fn(value)
+	}
+}
+
+func toUint(value any) (uint, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return uint(v.Uint()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() > 0 {
+			return uint(v.Int()), true
+		}
+	}
+	return 0, false
+}
+
+func toAny(values []string) []any {
+	result := make([]any, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+package translation
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// article is a model with translatable fields
+type article struct {
+	Id    uint
+	Title Field `gorm:"type:text"`
+	Body  Field `gorm:"type:text"`
+}
+
+// post names its translations by GetModelName instead of its table
+type post struct {
+	Id    uint
+	Title Field `gorm:"type:text"`
+}
+
+func (post) GetModelName() string { return "blog_post" }
+
+// newAutoLoadService returns a translation service with the AutoLoader
+// registered and a counter of the queries on the translations table
+func newAutoLoadService(t *testing.T) (*TranslationService, *int) {
+	t.Helper()
+
+	s := newTestService(t)
+	previous := defaultService
+	t.Cleanup(func() { defaultService = previous })
+	if err := EnableAutoLoad(s.DB, s); err != nil {
+		t.Fatalf("EnableAutoLoad: %v", err)
+	}
+	if err := EnableAutoLoad(s.DB, s); err != nil {
+		t.Fatalf("EnableAutoLoad twice: %v", err)
+	}
+	if err := s.DB.AutoMigrate(&article{}, &post{}); err != nil {
+		t.Fatal(err)
+	}
+
+	queries := 0
+	s.DB.Callback().Query().After("gorm:query").Register("test:count_translations", func(db *gorm.DB) {
+		if db.Statement.Table == "translations" {
+			queries++
+		}
+	})
+	return s, &queries
+}
+
+func translate(t *testing.T, s *TranslationService, model string, id uint, key, language, value string) {
+	t.Helper()
+	if err := s.DB.Create(&Translation{Model: model, ModelId: id, Key: key, Language: language, Value: value}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTranslationsAppearAfterFind(t *testing.T) {
+	s, queries := newAutoLoadService(t)
+
+	first := &article{Title: NewField("Hello"), Body: NewField("World")}
+	second := &article{Title: NewField("Bye")}
+	untranslated := &article{Title: NewField("Plain")}
+	for _, a := range []*article{first, second, untranslated} {
+		if err := s.DB.Create(a).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	translate(t, s, "articles", first.Id, "title", "sq", "Përshëndetje")
+	translate(t, s, "articles", first.Id, "title", "de", "Hallo")
+	translate(t, s, "articles", first.Id, "body", "sq", "Botë")
+	translate(t, s, "articles", second.Id, "title", "sq", "Mirupafshim")
+	// Another model's translation with the same id and key is not loaded
+	translate(t, s, "pages", first.Id, "title", "sq", "Faqe")
+
+	*queries = 0
+	var found article
+	if err := s.DB.First(&found, first.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+	if found.Title.Original != "Hello" || found.Title.Values["sq"] != "Përshëndetje" || found.Title.Values["de"] != "Hallo" {
+		t.Errorf("title = %+v", found.Title)
+	}
+	if len(found.Body.Values) != 1 || found.Body.Values["sq"] != "Botë" {
+		t.Errorf("body = %+v", found.Body)
+	}
+	if *queries != 1 {
+		t.Errorf("%d translation queries for one row with two fields, want 1", *queries)
+	}
+
+	*queries = 0
+	var all []article
+	if err := s.DB.Order("id").Find(&all).Error; err != nil {
+		t.Fatal(err)
+	}
+	if *queries != 1 {
+		t.Errorf("%d translation queries for %d rows, want 1", *queries, len(all))
+	}
+	if all[1].Title.Values["sq"] != "Mirupafshim" || len(all[1].Body.Values) != 0 {
+		t.Errorf("second article = %+v", all[1])
+	}
+	if len(all[2].Title.Values) != 0 {
+		t.Errorf("untranslated article has translations %v", all[2].Title.Values)
+	}
+}
+
+func TestAutoLoadUsesModelName(t *testing.T) {
+	s, _ := newAutoLoadService(t)
+
+	p := &post{Title: NewField("News")}
+	if err := s.DB.Create(p).Error; err != nil {
+		t.Fatal(err)
+	}
+	translate(t, s, "posts", p.Id, "title", "sq", "Wrong")
+	translate(t, s, "blog_post", p.Id, "title", "sq", "Lajme")
+
+	var found post
+	if err := s.DB.First(&found, p.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+	if found.Title.Values["sq"] != "Lajme" {
+		t.Errorf("title = %+v, want the blog_post translation", found.Title)
+	}
+
+	// LoadTranslations uses the registered service
+	field := NewField("News")
+	if err := field.LoadTranslations("blog_post", p.Id, "title"); err != nil {
+		t.Fatal(err)
+	}
+	if field.Values["sq"] != "Lajme" {
+		t.Errorf("LoadTranslations = %+v", field)
+	}
+}
@@ -0,0 +1,169 @@
+package translation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"base/core/router"
+)
+
+// newBulkService stores the sq title and body of post 1
+func newBulkService(t *testing.T) *TranslationService {
+	t.Helper()
+	s := newTestService(t)
+	for key, value := range map[string]string{"title": "Titulli", "body": "Teksti"} {
+		if err := s.DB.Create(&Translation{Key: key, Value: value, Model: "post", ModelId: 1, Language: "sq"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+// mixedBatch holds a new translation, an existing one with a new value, an
+// existing one with its stored value and a translation repeated in the batch
+func mixedBatch(policy string) *BulkCreateTranslationRequest {
+	return &BulkCreateTranslationRequest{
+		OnConflict: policy,
+		Translations: []CreateTranslationRequest{
+			{Key: "title", Value: "Title", Model: "post", ModelId: 1, Language: "en"},
+			{Key: "title", Value: "Titull i ri", Model: "post", ModelId: 1, Language: "sq"},
+			{Key: "body", Value: "Teksti", Model: "post", ModelId: 1, Language: "sq"},
+			{Key: "title", Value: "Heading", Model: "post", ModelId: 1, Language: "en"},
+		},
+	}
+}
+
+func statuses(result *BulkCreateResult) []string {
+	var statuses []string
+	for _, item := range result.Results {
+		statuses = append(statuses, item.Status)
+	}
+	return statuses
+}
+
+// storedValue returns the value of a translation of post 1
+func storedValue(t *testing.T, s *TranslationService, key, language string) string {
+	t.Helper()
+	var translation Translation
+	if err := s.DB.Where("`key` = ? AND model = ? AND model_id = ? AND language = ?", key, "post", 1, language).First(&translation).Error; err != nil {
+		return ""
+	}
+	return translation.Value
+}
+
+func TestBulkCreateSkipPolicy(t *testing.T) {
+	s := newBulkService(t)
+
+	result, err := s.BulkCreate(mixedBatch(ConflictSkip))
+	if err != nil {
+		t.Fatalf("BulkCreate: %v", err)
+	}
+	if want := []string{BulkItemCreated, BulkItemSkipped, BulkItemSkipped, BulkItemSkipped}; !reflect.DeepEqual(statuses(result), want) {
+		t.Errorf("statuses %v, want %v", statuses(result), want)
+	}
+	if !result.Committed || result.Created != 1 || result.Skipped != 3 {
+		t.Errorf("result = %+v", result)
+	}
+	// The first of the repeated items wins
+	if result.Results[3].Id != result.Results[0].Id || storedValue(t, s, "title", "en") != "Title" {
+		t.Errorf("repeated item resolved to %d, want %d with value Title", result.Results[3].Id, result.Results[0].Id)
+	}
+	if got := storedValue(t, s, "title", "sq"); got != "Titulli" {
+		t.Errorf("skipped translation changed to %q", got)
+	}
+}
+
+func TestBulkCreateUpdatePolicy(t *testing.T) {
+	s := newBulkService(t)
+
+	result, err := s.BulkCreate(mixedBatch(ConflictUpdate))
+	if err != nil {
+		t.Fatalf("BulkCreate: %v", err)
+	}
+	if want := []string{BulkItemCreated, BulkItemUpdated, BulkItemUnchanged, BulkItemUpdated}; !reflect.DeepEqual(statuses(result), want) {
+		t.Errorf("statuses %v, want %v", statuses(result), want)
+	}
+	if !result.Committed || result.Created != 1 || result.Updated != 2 || result.Unchanged != 1 {
+		t.Errorf("result = %+v", result)
+	}
+	if storedValue(t, s, "title", "sq") != "Titull i ri" || storedValue(t, s, "title", "en") != "Heading" {
+		t.Errorf("values sq %q en %q, want the batch's last values", storedValue(t, s, "title", "sq"), storedValue(t, s, "title", "en"))
+	}
+
+	var updated Translation
+	s.DB.First(&updated, result.Results[1].Id)
+	if updated.Version != 2 {
+		t.Errorf("updated translation at version %d, want 2", updated.Version)
+	}
+}
+
+func TestBulkCreateErrorPolicyRollsBack(t *testing.T) {
+	s := newBulkService(t)
+
+	result, err := s.BulkCreate(mixedBatch(""))
+	if !errors.Is(err, ErrBulkCreateConflict) {
+		t.Fatalf("got %v, want ErrBulkCreateConflict", err)
+	}
+	if want := []string{BulkItemCreated, BulkItemConflict, BulkItemConflict, BulkItemConflict}; !reflect.DeepEqual(statuses(result), want) {
+		t.Errorf("statuses %v, want every conflict listed: %v", statuses(result), want)
+	}
+	if result.Committed || result.OnConflict != ConflictError || result.Conflicts != 3 {
+		t.Errorf("result = %+v", result)
+	}
+	// The rolled back translation has no id, existing ones keep theirs
+	if result.Results[0].Id != 0 || result.Results[3].Id != 0 || result.Results[1].Id == 0 {
+		t.Errorf("ids %+v", result.Results)
+	}
+	if got := storedValue(t, s, "title", "en"); got != "" {
+		t.Errorf("the rolled back batch left title/en = %q", got)
+	}
+
+	// Without conflicts the error policy creates everything
+	batch := mixedBatch(ConflictError)
+	batch.Translations = batch.Translations[:1]
+	if result, err := s.BulkCreate(batch); err != nil || !result.Committed || result.Created != 1 {
+		t.Errorf("clean batch: %+v, %v", result, err)
+	}
+}
+
+func TestBulkCreateRoute(t *testing.T) {
+	s := newBulkService(t)
+	r := router.New()
+	NewTranslationController(s, nil).Routes(r.Group("/api"))
+	post := func(body string) (int, map[string]json.RawMessage) {
+		req := httptest.NewRequest(http.MethodPost, "/api/translations/bulk-create", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var response map[string]json.RawMessage
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+	existing := `{"key":"title","value":"Titulli","model":"post","model_id":1,"language":"sq"}`
+	fresh := `{"key":"title","value":"Title","model":"post","model_id":1,"language":"en"}`
+
+	if code, response := post(`{"translations":[` + fresh + `,` + existing + `]}`); code != http.StatusConflict || response["result"] == nil {
+		t.Errorf("conflict: status %d, %v", code, response)
+	}
+	code, response := post(`{"on_conflict":"skip","translations":[` + fresh + `,` + existing + `]}`)
+	var result BulkCreateResult
+	json.Unmarshal(response["result"], &result)
+	if code != http.StatusOK || result.Created != 1 || result.Skipped != 1 {
+		t.Errorf("skip: status %d, %+v", code, result)
+	}
+
+	for _, body := range []string{
+		`{"on_conflict":"replace","translations":[` + fresh + `]}`,
+		`{"translations":[]}`,
+		`{"translations":[{"key":"title"}]}`,
+	} {
+		if code, _ := post(body); code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", body, code)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package translation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	BundleFormatJSON = "json"
+	BundleFormatCSV  = "csv"
+
+	// importBatchSize bounds how many rows are held in memory during an import
+	importBatchSize = 500
+	// maxImportErrors caps the number of row errors reported back
+	maxImportErrors = 100
+)
+
+var (
+	ErrUnsupportedBundleFormat = errors.New("unsupported format, expected json or csv")
+	ErrInvalidBundle           = errors.New("invalid bundle")
+
+	// localePattern matches codes that fit the language column: "en", "en-US", "en_US"
+	localePattern = regexp.MustCompile(`^[a-z]{2}([-_][A-Z]{2})?$`)
+
+	bundleCSVHeader = []string{"model", "model_id", "language", "key", "value"}
+)
+
+// IsValidLocale reports whether code is a supported locale code
+func IsValidLocale(code string) bool {
+	return localePattern.MatchString(code)
+}
+
+// IsValidBundleFormat reports whether format can be exported and imported
+func IsValidBundleFormat(format string) bool {
+	return format == BundleFormatJSON || format == BundleFormatCSV
+}
+
+// Export streams every translation matching model and language (both optional)
+// to w as a flat JSON array or CSV file. Rows are read from the database one at
+// a time, so large sets are never fully loaded into memory.
+func (s *TranslationService) Export(w io.Writer, format, model, language string) (int, error) {
+	if !IsValidBundleFormat(format) {
+		return 0, ErrUnsupportedBundleFormat
+	}
+
+	query := s.DB.Model(&Translation{})
+	if model != "" {
+		query = query.Where("model = ?", model)
+	}
+	if language != "" {
+		query = query.Where("language = ?", language)
+	}
+
+	rows, err := query.Order("model, model_id, language, `key`").Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	encoder := newBundleEncoder(w, format)
+	if err := encoder.begin(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for rows.Next() {
+		var item Translation
+		if err := s.DB.ScanRows(rows, &item); err != nil {
+			return count, err
+		}
+		if err := encoder.write(item.toBundleRow()); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	s.Logger.Info("Exported translations",
+		zap.String("format", format),
+		zap.String("model", model),
+		zap.String("language", language),
+		zap.Int("count", count))
+
+	return count, encoder.end()
+}
+
+// Import upserts translations from a JSON or CSV bundle in the Export layout.
+// Rows are decoded as a stream and written in batches like BulkSetTranslations,
+// recording changed values in the history on behalf of editorId.
+// Rows whose value is unchanged or that fail validation are counted as skipped.
+// When decoding fails part-way, the counts for the batches already written are
+// returned alongside the error.
+func (s *TranslationService) Import(r io.Reader, format string, editorId uint) (*ImportResult, error) {
+	if !IsValidBundleFormat(format) {
+		return nil, ErrUnsupportedBundleFormat
+	}
+
+	result := &ImportResult{}
+	batch := make([]BundleRow, 0, importBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := s.importBatch(batch, result, editorId)
+		batch = batch[:0]
+		return err
+	}
+
+	rowNumber := 0
+	err := decodeBundle(r, format, func(row BundleRow, rowErr error) error {
+		rowNumber++
+		if rowErr == nil {
+			rowErr = row.validate()
+		}
+		if rowErr != nil {
+			result.skip(fmt.Sprintf("row %d: %v", rowNumber, rowErr))
+			return nil
+		}
+
+		batch = append(batch, row)
+		if len(batch) >= importBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+
+	s.Logger.Info("Imported translations",
+		zap.String("format", format),
+		zap.Int("created", result.Created),
+		zap.Int("updated", result.Updated),
+		zap.Int("skipped", result.Skipped))
+
+	return result, err
+}
+
+// importBatch compares a batch against stored values and upserts the changed rows
+func (s *TranslationService) importBatch(batch []BundleRow, result *ImportResult, editorId uint) error {
+	type groupKey struct {
+		model    string
+		modelId  uint
+		language string
+	}
+
+	groups := make(map[groupKey]map[string]string)
+	order := []groupKey{}
+	for _, row := range batch {
+		key := groupKey{row.Model, row.ModelId, row.Language}
+		values, ok := groups[key]
+		if !ok {
+			values = make(map[string]string)
+			groups[key] = values
+			order = append(order, key)
+		}
+		if _, duplicate := values[row.Key]; duplicate {
+			// The last occurrence wins; the earlier one is not written
+			result.Skipped++
+		}
+		values[row.Key] = row.Value
+	}
+
+	for _, group := range order {
+		values := groups[group]
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+
+		var existing []Translation
+		err := s.DB.Select("`key`", "value").
+			Where("model = ? AND model_id = ? AND language = ? AND `key` IN ?", group.model, group.modelId, group.language, keys).
+			Find(&existing).Error
+		if err != nil {
+			return err
+		}
+
+		stored := make(map[string]string, len(existing))
+		for _, item := range existing {
+			stored[item.Key] = item.Value
+		}
+
+		changed := make(map[string]string)
+		created, updated := 0, 0
+		for key, value := range values {
+			current, exists := stored[key]
+			switch {
+			case !exists:
+				created++
+			case current != value:
+				updated++
+			default:
+				result.Skipped++
+				continue
+			}
+			changed[key] = value
+		}
+
+		if len(changed) == 0 {
+			continue
+		}
+		if _, err := s.bulkSetTranslations(group.model, group.modelId, group.language, changed, false, editorId); err != nil {
+			return err
+		}
+		result.Created += created
+		result.Updated += updated
+	}
+
+	return nil
+}
+
+func (item *Translation) toBundleRow() BundleRow {
+	return BundleRow{
+		Model:    item.Model,
+		ModelId:  item.ModelId,
+		Language: item.Language,
+		Key:      item.Key,
+		Value:    item.Value,
+	}
+}
+
+func (row BundleRow) validate() error {
+	if strings.TrimSpace(row.Model) == "" {
+		return errors.New("model is required")
+	}
+	if strings.TrimSpace(row.Key) == "" {
+		return errors.New("key is required")
+	}
+	if !IsValidLocale(row.Language) {
+		return fmt.Errorf("invalid locale %q", row.Language)
+	}
+	return nil
+}
+
+func (r *ImportResult) skip(reason string) {
+	r.Skipped++
+	if len(r.Errors) < maxImportErrors {
+		r.Errors = append(r.Errors, reason)
+	}
+}
+
+// bundleEncoder writes rows in one of the bundle formats
+type bundleEncoder struct {
+	w      io.Writer
+	format string
+	csv    *csv.Writer
+	rows   int
+}
+
+func newBundleEncoder(w io.Writer, format string) *bundleEncoder {
+	encoder := &bundleEncoder{w: w, format: format}
+	if format == BundleFormatCSV {
+		encoder.csv = csv.NewWriter(w)
+	}
+	return encoder
+}
+
+func (e *bundleEncoder) begin() error {
+	if e.csv != nil {
+		return e.csv.Write(bundleCSVHeader)
+	}
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *bundleEncoder) write(row BundleRow) error {
+	e.rows++
+
+	if e.csv != nil {
+		err := e.csv.Write([]string{row.Model, strconv.FormatUint(uint64(row.ModelId), 10), row.Language, row.Key, row.Value})
+		if err == nil && e.rows%importBatchSize == 0 {
+			e.csv.Flush()
+			err = e.csv.Error()
+		}
+		return err
+	}
+
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	prefix := ",\n"
+	if e.rows == 1 {
+		prefix = "\n"
+	}
+	if _, err := io.WriteString(e.w, prefix); err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *bundleEncoder) end() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		return e.csv.Error()
+	}
+	_, err := io.WriteString(e.w, "\n]\n")
+	return err
+}
+
+// decodeBundle reads rows one at a time and passes each to handle. Row-level
+// problems (such as a non-numeric model_id) are passed as rowErr; malformed
+// input that cannot be read further aborts decoding.
+func decodeBundle(r io.Reader, format string, handle func(row BundleRow, rowErr error) error) error {
+	if format == BundleFormatCSV {
+		return decodeCSVBundle(r, handle)
+	}
+	return decodeJSONBundle(r, handle)
+}
+
+func decodeJSONBundle(r io.Reader, handle func(row BundleRow, rowErr error) error) error {
+	decoder := json.NewDecoder(r)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("%w: expected a JSON array of translations", ErrInvalidBundle)
+	}
+
+	for decoder.More() {
+		var row BundleRow
+		if err := decoder.Decode(&row); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				if err := handle(row, fmt.Errorf("invalid %s", typeErr.Field)); err != nil {
+					return err
+				}
+				continue
+			}
+			return fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		if err := handle(row, nil); err != nil {
+			return err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	return nil
+}
+
+func decodeCSVBundle(r io.Reader, handle func(row BundleRow, rowErr error) error) error {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range bundleCSVHeader {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("%w: missing %q column", ErrInvalidBundle, name)
+		}
+	}
+	reader.FieldsPerRecord = len(header)
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+
+		row := BundleRow{
+			Model:    record[columns["model"]],
+			Language: record[columns["language"]],
+			Key:      record[columns["key"]],
+			Value:    record[columns["value"]],
+		}
+
+		var rowErr error
+		modelId, err := strconv.ParseUint(strings.TrimSpace(record[columns["model_id"]]), 10, 32)
+		if err != nil {
+			rowErr = errors.New("invalid model_id")
+		}
+		row.ModelId = uint(modelId)
+
+		if err := handle(row, rowErr); err != nil {
+			return err
+		}
+	}
+}
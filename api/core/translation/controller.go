@@ -1,13 +1,24 @@
 package translation
 
 import (
+	"base/core/module"
 	"base/core/router"
 	"base/core/storage"
+	"base/core/types"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"path/filepath"
 	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
 )
 
 type TranslationController struct {
+	*module.SoftDeletable[Translation]
 	Service *TranslationService
 	Storage *storage.ActiveStorage
 }
@@ -18,8 +29,9 @@ type ErrorResponse struct {
 
 func NewTranslationController(service *TranslationService, storage *storage.ActiveStorage) *TranslationController {
 	return &TranslationController{
-		Service: service,
-		Storage: storage,
+		SoftDeletable: module.NewSoftDeletable(service.DB, func(t *Translation) any { return t.ToResponse() }),
+		Service:       service,
+		Storage:       storage,
 	}
 }
 
@@ -30,9 +42,18 @@ func (c *TranslationController) Routes(router *router.RouterGroup) {
 
 	// Bulk operations - MUST come before parameterized routes
 	router.POST("/translations/bulk", c.BulkUpdate)
+	router.POST("/translations/bulk-create", c.BulkCreate)
+	router.DELETE("/translations/orphans", c.RemoveOrphans)
 
 	// Utility endpoints - MUST come before parameterized routes
 	router.GET("/translations/languages", c.GetSupportedLanguages)
+	router.GET("/translations/search", c.Search)
+	router.GET("/translations/coverage", c.Coverage)
+
+	// Trash: GET /translations/trash, POST /translations/trash/:id/restore, DELETE /translations/trash/:id
+	c.TrashRoutes(router.Group("/translations"))
+	router.GET("/translations/export", c.Export)
+	router.POST("/translations/import", c.Import)
 
 	// Model-specific operations - MUST come before parameterized routes
 	router.GET("/translations/models/:model/:model_id", c.GetForModel)
@@ -42,24 +63,83 @@ func (c *TranslationController) Routes(router *router.RouterGroup) {
 	router.GET("/translations/by-id/:id", c.Get)
 	router.PUT("/translations/by-id/:id", c.Update)
 	router.DELETE("/translations/by-id/:id", c.Delete)
+	router.GET("/translations/by-id/:id/history", c.History)
+	router.POST("/translations/by-id/:id/history/:history_id/revert", c.Revert)
 }
 
 // List godoc
 // @Summary List translations
-// @Description Get a paginated list of translations with optional filtering
+// @Description Get a paginated list of translations with optional filtering and sorting
 // @Tags Core/Translations
 // @Security ApiKeyAuth
 // @Produce json
 // @Param page query int false "Page number"
-// @Param limit query int false "Number of items per page"
-// @Param model query string false "Filter by model name"
-// @Param model_id query int false "Filter by model ID"
+// @Param limit query int false "Number of items per page, capped at MAX_PAGE_SIZE"
+// @Param sort query string false "Comma-separated fields, - for descending: id, key, language, created_at, updated_at" default(-updated_at)
+// @Param filter[model] query string false "Filter by model name"
+// @Param filter[model_id] query int false "Filter by model ID"
+// @Param filter[key] query string false "Filter by key"
+// @Param filter[language] query string false "Filter by language code"
+// @Param model query string false "Filter by model name (same as filter[model])"
+// @Param model_id query int false "Filter by model ID (same as filter[model_id])"
 // @Success 200 {object} types.PaginatedResponse
+// @Failure 400 {object} types.ErrorResponse
 // @Failure 500 {object} types.ErrorResponse
 // @Router /translations [get]
 func (c *TranslationController) List(ctx *router.Context) error {
+	filters, err := ctx.QueryFilters()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	// model and model_id predate filter[...] and stay supported
+	for _, name := range []string{"model", "model_id"} {
+		if value := ctx.Query(name); value != "" {
+			if _, set := filters.Filters[name]; !set {
+				filters.Filters[name] = value
+			}
+		}
+	}
+	if modelId, ok := filters.Filters["model_id"]; ok {
+		if _, err := strconv.ParseUint(modelId, 10, 32); err != nil {
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid model_id"})
+		}
+	}
+
+	paginatedResponse, err := c.Service.GetAll(filters)
+	if err != nil {
+		if errors.Is(err, router.ErrInvalidQuery) {
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch translations: " + err.Error()})
+	}
+	paginatedResponse.AddLinks(ctx.Request.URL)
+
+	return ctx.JSON(http.StatusOK, paginatedResponse)
+}
+
+// Search godoc
+// @Summary Search translations
+// @Description Search translations by key or value text with optional language and model filters
+// @Tags Core/Translations
+// @Security ApiKeyAuth
+// @Produce json
+// @Param q query string true "Text to search for in key or value"
+// @Param language query string false "Filter by language code"
+// @Param model query string false "Filter by model name"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page, capped at MAX_PAGE_SIZE"
+// @Success 200 {object} types.PaginatedResponse
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /translations/search [get]
+func (c *TranslationController) Search(ctx *router.Context) error {
 	var page, limit *int
-	var modelId *uint
+
+	query := strings.TrimSpace(ctx.Query("q"))
+	if query == "" {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Search query is required"})
+	}
 
 	if pageStr := ctx.Query("page"); pageStr != "" {
 		if pageNum, err := strconv.Atoi(pageStr); err == nil && pageNum > 0 {
@@ -77,23 +157,11 @@ func (c *TranslationController) List(ctx *router.Context) error {
 		}
 	}
 
-	// Handle model_id filter
-	if modelIdStr := ctx.Query("model_id"); modelIdStr != "" {
-		if modelIdNum, err := strconv.ParseUint(modelIdStr, 10, 32); err == nil {
-			modelIdUint := uint(modelIdNum)
-			modelId = &modelIdUint
-		} else {
-			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid model_id"})
-		}
-	}
-
-	// Get model filter
-	model := ctx.Query("model")
-
-	paginatedResponse, err := c.Service.GetAll(page, limit, model, modelId)
+	paginatedResponse, err := c.Service.Search(query, page, limit, ctx.Query("language"), ctx.Query("model"))
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch translations: " + err.Error()})
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to search translations: " + err.Error()})
 	}
+	paginatedResponse.AddLinks(ctx.Request.URL)
 
 	return ctx.JSON(http.StatusOK, paginatedResponse)
 }
@@ -144,7 +212,7 @@ func (c *TranslationController) Get(ctx *router.Context) error {
 func (c *TranslationController) Create(ctx *router.Context) error {
 	var request CreateTranslationRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request data: " + err.Error()})
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid request data: "+err.Error()))
 	}
 
 	translation, err := c.Service.Create(&request)
@@ -157,7 +225,7 @@ func (c *TranslationController) Create(ctx *router.Context) error {
 
 // Update godoc
 // @Summary Update translation
-// @Description Update an existing translation
+// @Description Update an existing translation. The body must carry the version last read; a stale version returns 409.
 // @Tags Core/Translations
 // @Security ApiKeyAuth
 // @Accept json
@@ -167,6 +235,7 @@ func (c *TranslationController) Create(ctx *router.Context) error {
 // @Success 200 {object} translation.TranslationResponse
 // @Failure 400 {object} types.ErrorResponse
 // @Failure 404 {object} types.ErrorResponse
+// @Failure 409 {object} types.ErrorResponse
 // @Failure 500 {object} types.ErrorResponse
 // @Router /translations/by-id/{id} [put]
 func (c *TranslationController) Update(ctx *router.Context) error {
@@ -178,14 +247,17 @@ func (c *TranslationController) Update(ctx *router.Context) error {
 
 	var request UpdateTranslationRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request data: " + err.Error()})
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid request data: "+err.Error()))
 	}
 
 	request.Id = uint(id)
+	request.EditorId = ctx.GetUint("user_id")
 	translation, err := c.Service.Update(&request)
 	if err != nil {
 		if err.Error() == "translation not found" {
 			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		} else if errors.Is(err, types.ErrVersionConflict) {
+			return ctx.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
 		} else {
 			return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update translation: " + err.Error()})
 		}
@@ -196,7 +268,8 @@ func (c *TranslationController) Update(ctx *router.Context) error {
 
 // Delete godoc
 // @Summary Delete translation
-// @Description Delete a translation by ID
+// @Description Move a translation to the trash. It can be restored with POST /translations/trash/{id}/restore
+// @Description or deleted for good with DELETE /translations/trash/{id}.
 // @Tags Core/Translations
 // @Security ApiKeyAuth
 // @Param id path int true "Translation ID"
@@ -212,7 +285,7 @@ func (c *TranslationController) Delete(ctx *router.Context) error {
 		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid translation ID"})
 	}
 
-	err = c.Service.Delete(uint(id))
+	err = c.Service.Delete(uint(id), ctx.GetUint("user_id"))
 	if err != nil {
 		if err.Error() == "translation not found" {
 			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
@@ -225,30 +298,179 @@ func (c *TranslationController) Delete(ctx *router.Context) error {
 	return nil
 }
 
+// History godoc
+// @Summary Translation history
+// @Description Get the changes of a translation, newest first: its old and new value, the editor and when, for every update,
+// @Description delete and revert. The history of a translation in the trash stays readable.
+// @Tags Core/Translations
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path int true "Translation ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page, capped at MAX_PAGE_SIZE"
+// @Success 200 {object} types.PaginatedResponse{data=[]translation.TranslationHistory}
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 404 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /translations/by-id/{id}/history [get]
+func (c *TranslationController) History(ctx *router.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid translation ID"})
+	}
+	filters, err := ctx.QueryFilters()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	response, err := c.Service.GetHistory(uint(id), filters.Page, filters.Limit)
+	if err != nil {
+		if err.Error() == "translation not found" {
+			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch translation history: " + err.Error()})
+	}
+	response.AddLinks(ctx.Request.URL)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// Revert godoc
+// @Summary Revert translation
+// @Description Set a translation back to the value it had before a change of its history. The revert is recorded as a change too.
+// @Tags Core/Translations
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path int true "Translation ID"
+// @Param history_id path int true "History entry ID"
+// @Success 200 {object} translation.TranslationResponse
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 404 {object} types.ErrorResponse
+// @Failure 409 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /translations/by-id/{id}/history/{history_id}/revert [post]
+func (c *TranslationController) Revert(ctx *router.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid translation ID"})
+	}
+	historyId, err := strconv.ParseUint(ctx.Param("history_id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid history ID"})
+	}
+
+	translation, err := c.Service.Revert(uint(id), uint(historyId), ctx.GetUint("user_id"))
+	if err != nil {
+		if err.Error() == "translation not found" || errors.Is(err, ErrHistoryNotFound) {
+			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		} else if errors.Is(err, types.ErrVersionConflict) {
+			return ctx.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to revert translation: " + err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, translation)
+}
+
 // BulkUpdate godoc
 // @Summary Bulk update translations
-// @Description Update multiple translations for a model at once
+// @Description Update multiple translations for a model at once. With dry_run set in the body or query,
+// @Description returns the keys that would be created or updated, with old and new values, without writing them.
 // @Tags Core/Translations
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param bulk body translation.BulkTranslationRequest true "Bulk translation data"
-// @Success 200 {object} map[string]string
+// @Param dry_run query bool false "Preview the changes without writing them"
+// @Success 200 {object} map[string]any
 // @Failure 400 {object} types.ErrorResponse
 // @Failure 500 {object} types.ErrorResponse
 // @Router /translations/bulk [post]
 func (c *TranslationController) BulkUpdate(ctx *router.Context) error {
 	var request BulkTranslationRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request data: " + err.Error()})
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid request data: "+err.Error()))
 	}
 
-	err := c.Service.BulkUpdate(&request)
+	if dryRun, err := strconv.ParseBool(ctx.Query("dry_run")); err == nil && dryRun {
+		request.DryRun = true
+	}
+	request.EditorId = ctx.GetUint("user_id")
+
+	result, err := c.Service.BulkUpdate(&request)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update translations: " + err.Error()})
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]any{"message": "Translations updated successfully"})
+	message := "Translations updated successfully"
+	if result.DryRun {
+		message = "Dry run: no translations were changed"
+	}
+	return ctx.JSON(http.StatusOK, map[string]any{"message": message, "result": result})
+}
+
+// BulkCreate godoc
+// @Summary Bulk create translations
+// @Description Create many translations in one transaction. on_conflict decides what happens to translations that already exist
+// @Description for the same key, model, model_id and language: error (default) rolls the batch back and returns 409 listing the conflicts,
+// @Description skip keeps them and update overwrites their value. Each item gets a status: created, updated, unchanged, skipped or conflict.
+// @Tags Core/Translations
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param bulk body translation.BulkCreateTranslationRequest true "Translations and conflict policy"
+// @Success 200 {object} map[string]any
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 409 {object} map[string]any
+// @Failure 500 {object} types.ErrorResponse
+// @Router /translations/bulk-create [post]
+func (c *TranslationController) BulkCreate(ctx *router.Context) error {
+	var request BulkCreateTranslationRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, types.NewBindErrorResponse(err, "Invalid request data: "+err.Error()))
+	}
+
+	request.EditorId = ctx.GetUint("user_id")
+	result, err := c.Service.BulkCreate(&request)
+	if errors.Is(err, ErrBulkCreateConflict) {
+		return ctx.JSON(http.StatusConflict, map[string]any{"error": err.Error(), "result": result})
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create translations: " + err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{"message": "Translations created successfully", "result": result})
+}
+
+// RemoveOrphans godoc
+// @Summary Remove orphaned translations
+// @Description Delete the translations of model instances that no longer exist, reporting the counts by model.
+// @Description Only models with a registered resolver are checked; the others are listed as unresolved.
+// @Description With dry_run the translations are only counted.
+// @Tags Core/Translations
+// @Security ApiKeyAuth
+// @Produce json
+// @Param dry_run query bool false "Count the orphaned translations without deleting them"
+// @Success 200 {object} translation.OrphanResult
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /translations/orphans [delete]
+func (c *TranslationController) RemoveOrphans(ctx *router.Context) error {
+	dryRun := false
+	if value := ctx.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "dry_run must be a boolean"})
+		}
+		dryRun = parsed
+	}
+
+	result, err := c.Service.RemoveOrphans(dryRun)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to remove orphaned translations"})
+	}
+
+	return ctx.JSON(http.StatusOK, result)
 }
 
 // GetForModel godoc
@@ -311,6 +533,45 @@ func (c *TranslationController) GetForModelAndLanguage(ctx *router.Context) erro
 	return ctx.JSON(http.StatusOK, translations)
 }
 
+// Coverage godoc
+// @Summary Translation coverage
+// @Description Report, for each key of a model, how many instances have it and how many of those are translated in the language.
+// @Description completeness is the percentage of instance keys translated and missing_keys lists the keys some instance lacks.
+// @Tags Core/Translations
+// @Security ApiKeyAuth
+// @Produce json
+// @Param model query string true "Model name"
+// @Param language query string true "Target language"
+// @Param model_id query int false "Only this instance of the model"
+// @Success 200 {object} translation.CoverageResult
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /translations/coverage [get]
+func (c *TranslationController) Coverage(ctx *router.Context) error {
+	model := ctx.Query("model")
+	language := ctx.Query("language")
+	if model == "" || language == "" {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "model and language are required"})
+	}
+
+	var modelId *uint
+	if value := ctx.Query("model_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid model ID"})
+		}
+		id := uint(parsed)
+		modelId = &id
+	}
+
+	result, err := c.Service.Coverage(model, modelId, language)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to compute translation coverage: " + err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
 // GetSupportedLanguages godoc
 // @Summary Get supported languages
 // @Description Get a list of all languages that have translations in the system
@@ -328,3 +589,124 @@ func (c *TranslationController) GetSupportedLanguages(ctx *router.Context) error
 
 	return ctx.JSON(http.StatusOK, languages)
 }
+
+// Export godoc
+// @Summary Export translations
+// @Description Download matching translations as a flat JSON array or CSV file. The response is streamed.
+// @Tags Core/Translations
+// @Security ApiKeyAuth
+// @Produce json,text/csv
+// @Param model query string false "Filter by model name"
+// @Param language query string false "Filter by language code"
+// @Param format query string false "Bundle format: json (default) or csv"
+// @Success 200 {array} translation.BundleRow
+// @Failure 400 {object} types.ErrorResponse
+// @Router /translations/export [get]
+func (c *TranslationController) Export(ctx *router.Context) error {
+	format := strings.ToLower(ctx.DefaultQuery("format", BundleFormatJSON))
+	if !IsValidBundleFormat(format) {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrUnsupportedBundleFormat.Error()})
+	}
+
+	model := ctx.Query("model")
+	language := ctx.Query("language")
+	if language != "" && !IsValidLocale(language) {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid language code"})
+	}
+
+	filename := "translations"
+	for _, part := range []string{model, language} {
+		if part != "" {
+			filename += "-" + part
+		}
+	}
+
+	contentType := "application/json"
+	if format == BundleFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	ctx.SetHeader("Content-Type", contentType)
+	ctx.SetHeader("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+"."+format))
+	ctx.Writer.WriteHeader(http.StatusOK)
+
+	// Headers are already sent, so a failure part-way can only be logged
+	if _, err := c.Service.Export(ctx.Writer, format, model, language); err != nil {
+		c.Service.Logger.Error("Failed to export translations", zap.Error(err))
+	}
+	return nil
+}
+
+// Import godoc
+// @Summary Import translations
+// @Description Upsert translations from a JSON or CSV bundle in the export layout, sent as the request body or as a multipart "file" field.
+// @Description Unchanged and invalid rows are skipped; invalid rows are listed in errors.
+// @Tags Core/Translations
+// @Security ApiKeyAuth
+// @Accept json,text/csv,multipart/form-data
+// @Produce json
+// @Param format query string false "Bundle format: json or csv (defaults to the file extension or content type)"
+// @Param file formData file false "Bundle file"
+// @Success 200 {object} translation.ImportResult
+// @Failure 400 {object} types.ErrorResponse
+// @Failure 500 {object} types.ErrorResponse
+// @Router /translations/import [post]
+func (c *TranslationController) Import(ctx *router.Context) error {
+	format := strings.ToLower(ctx.Query("format"))
+
+	var body io.Reader = ctx.Request.Body
+	if strings.HasPrefix(ctx.ContentType(), "multipart/form-data") {
+		// Read the multipart stream directly so the upload is never buffered to disk
+		reader, err := ctx.Request.MultipartReader()
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid multipart request"})
+		}
+		part, err := nextFilePart(reader, "file")
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "file is required"})
+		}
+		defer part.Close()
+
+		body = part
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(part.FileName())), ".")
+		}
+	} else if format == "" {
+		format = BundleFormatJSON
+		if strings.Contains(ctx.ContentType(), "csv") {
+			format = BundleFormatCSV
+		}
+	}
+
+	if !IsValidBundleFormat(format) {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrUnsupportedBundleFormat.Error()})
+	}
+
+	result, err := c.Service.Import(body, format, ctx.GetUint("user_id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrInvalidBundle) {
+			status = http.StatusBadRequest
+		}
+		// Batches written before the failure stay applied, so report them
+		return ctx.JSON(status, map[string]any{
+			"error":  "Failed to import translations: " + err.Error(),
+			"result": result,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// nextFilePart advances reader to the form file named field
+func nextFilePart(reader *multipart.Reader, field string) (*multipart.Part, error) {
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == field && part.FileName() != "" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
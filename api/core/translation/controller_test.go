@@ -0,0 +1,147 @@
+package translation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"base/core/router"
+	"base/core/types"
+)
+
+func TestUpdateRouteAnswersConflictForStaleVersion(t *testing.T) {
+	s := newTestService(t)
+	tr := Translation{Key: "title", Value: "Hello", Model: "post", ModelId: 1, Language: "en"}
+	if err := s.DB.Create(&tr).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	r := router.New()
+	NewTranslationController(s, nil).Routes(r.Group("/api"))
+	update := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/translations/by-id/"+strconv.Itoa(int(tr.Id)), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := update(`{"version":1,"value":"Hi"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("current version: status %d, want 200: %s", w.Code, w.Body)
+	}
+	var response TranslationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Version != 2 {
+		t.Errorf("response version %d, want 2 for the client to round-trip", response.Version)
+	}
+
+	if w := update(`{"version":1,"value":"Hey"}`); w.Code != http.StatusConflict {
+		t.Errorf("stale version: status %d, want 409", w.Code)
+	}
+	if w := update(`{"value":"Hey"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("missing version: status %d, want 400", w.Code)
+	}
+}
+
+func TestListCapsLimitAndReportsPageSize(t *testing.T) {
+	s := newTestService(t)
+	for i := 1; i <= 3; i++ {
+		if err := s.DB.Create(&Translation{Key: "title", Value: "Hello", Model: "post", ModelId: uint(i), Language: "en"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	defaultSize, maxSize := types.DefaultPageSize, types.MaxPageSize
+	t.Cleanup(func() { types.DefaultPageSize, types.MaxPageSize = defaultSize, maxSize })
+	types.DefaultPageSize, types.MaxPageSize = 1, 2
+
+	r := router.New()
+	NewTranslationController(s, nil).Routes(r.Group("/api"))
+
+	for _, path := range []string{"/api/translations", "/api/translations/search?q=Hello"} {
+		for limit, want := range map[string]int{"": 1, "1000000": 2} {
+			target := path
+			if limit != "" {
+				if strings.Contains(path, "?") {
+					target += "&limit=" + limit
+				} else {
+					target += "?limit=" + limit
+				}
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("GET %s: status %d: %s", target, w.Code, w.Body)
+			}
+			var response types.PaginatedResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Pagination.PageSize != want {
+				t.Errorf("GET %s: page size %d, want %d", target, response.Pagination.PageSize, want)
+			}
+		}
+	}
+}
+
+func TestListFiltersAndSorts(t *testing.T) {
+	s := newTestService(t)
+	for _, tr := range []Translation{
+		{Key: "title", Value: "Titulli", Model: "post", ModelId: 1, Language: "sq"},
+		{Key: "body", Value: "Teksti", Model: "post", ModelId: 1, Language: "sq"},
+		{Key: "title", Value: "Titel", Model: "post", ModelId: 1, Language: "de"},
+		{Key: "title", Value: "Titulli", Model: "page", ModelId: 2, Language: "sq"},
+	} {
+		if err := s.DB.Create(&tr).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := router.New()
+	NewTranslationController(s, nil).Routes(r.Group("/api"))
+	list := func(query string) (int, []TranslationResponse) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/translations?"+query, nil))
+		var response struct {
+			Data []TranslationResponse `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response.Data
+	}
+
+	tests := []struct {
+		query string
+		want  []string // model/key/language of the rows in order
+	}{
+		{"filter[language]=sq&sort=key,-id", []string{"post/body/sq", "page/title/sq", "post/title/sq"}},
+		{"filter[model]=post&filter[key]=title&sort=-language", []string{"post/title/sq", "post/title/de"}},
+		// The older parameters still filter
+		{"model=page&model_id=2", []string{"page/title/sq"}},
+		{"filter[model]=post&model=page&sort=id", []string{"post/title/sq", "post/body/sq", "post/title/de"}},
+	}
+	for _, tt := range tests {
+		code, rows := list(tt.query)
+		if code != http.StatusOK {
+			t.Errorf("%s: status %d", tt.query, code)
+			continue
+		}
+		got := make([]string, len(rows))
+		for i, row := range rows {
+			got[i] = row.Model + "/" + row.Key + "/" + row.Language
+		}
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("%s: got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	for _, query := range []string{"filter[value]=Titel", "sort=value", "filter[model_id]=abc", "page=0"} {
+		if code, _ := list(query); code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, code)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package translation
+
+import (
+	"math"
+)
+
+// KeyCoverage is how many instances of a model have a key, in any language,
+// and how many of them have it in the target language
+type KeyCoverage struct {
+	Key        string `json:"key"`
+	Instances  int    `json:"instances"`
+	Translated int    `json:"translated"`
+	Complete   bool   `json:"complete"`
+}
+
+// CoverageResult reports the translation completeness of a model in a
+// language. Completeness is the percentage of (instance, key) pairs
+// translated; MissingKeys lists the keys some instance lacks.
+type CoverageResult struct {
+	Model        string        `json:"model"`
+	ModelId      *uint         `json:"model_id,omitempty"`
+	Language     string        `json:"language"`
+	TotalKeys    int           `json:"total_keys"`
+	CompleteKeys int           `json:"complete_keys"`
+	Completeness float64       `json:"completeness"`
+	MissingKeys  []string      `json:"missing_keys"`
+	Keys         []KeyCoverage `json:"keys"`
+}
+
+// Coverage compares, key by key, the instances of model having a translation
+// in any language with those having one in language, in a single grouped
+// query. A model id narrows it to one instance. A model without translations
+// is reported complete.
+func (s *TranslationService) Coverage(model string, modelId *uint, language string) (*CoverageResult, error) {
+	query := s.DB.Model(&Translation{}).
+		Select("`key` AS `key`, COUNT(DISTINCT model_id) AS instances, "+
+			"COUNT(DISTINCT CASE WHEN language = ? THEN model_id END) AS translated", language).
+		Where("model = ?", model)
+	if modelId != nil {
+		query = query.Where("model_id = ?", *modelId)
+	}
+
+	var keys []KeyCoverage
+	if err := query.Group("`key`").Order("`key` ASC").Scan(&keys).Error; err != nil {
+		return nil, err
+	}
+
+	result := &CoverageResult{
+		Model:        model,
+		ModelId:      modelId,
+		Language:     language,
+		TotalKeys:    len(keys),
+		Completeness: 100,
+		MissingKeys:  []string{},
+		Keys:         keys,
+	}
+
+	pairs, translated := 0, 0
+	for i := range result.Keys {
+		key := &result.Keys[i]
+		key.Complete = key.Translated == key.Instances
+		if key.Complete {
+			result.CompleteKeys++
+		} else {
+			result.MissingKeys = append(result.MissingKeys, key.Key)
+		}
+		pairs += key.Instances
+		translated += key.Translated
+	}
+	if pairs > 0 {
+		result.Completeness = math.Round(float64(translated)/float64(pairs)*10000) / 100
+	}
+	if result.Keys == nil {
+		result.Keys = []KeyCoverage{}
+	}
+
+	return result, nil
+}
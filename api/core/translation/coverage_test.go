@@ -0,0 +1,101 @@
+package translation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"base/core/router"
+
+	"gorm.io/gorm"
+)
+
+// newCoverageService stores the title and body of post 1 in sq, its title in
+// en, the title of post 2 in sq and en, and a page title in sq only
+func newCoverageService(t *testing.T) *TranslationService {
+	t.Helper()
+	s := newTestService(t)
+	for _, translation := range []Translation{
+		{Key: "title", Value: "Titulli", Model: "post", ModelId: 1, Language: "sq"},
+		{Key: "body", Value: "Teksti", Model: "post", ModelId: 1, Language: "sq"},
+		{Key: "title", Value: "Title", Model: "post", ModelId: 1, Language: "en"},
+		{Key: "title", Value: "Titulli 2", Model: "post", ModelId: 2, Language: "sq"},
+		{Key: "title", Value: "Title 2", Model: "post", ModelId: 2, Language: "en"},
+		{Key: "title", Value: "Faqja", Model: "page", ModelId: 1, Language: "sq"},
+	} {
+		if err := s.DB.Create(&translation).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func TestCoverageWithPartialTranslations(t *testing.T) {
+	s := newCoverageService(t)
+	queries := 0
+	count := func(*gorm.DB) { queries++ }
+	s.DB.Callback().Query().After("gorm:query").Register("test:count_coverage_queries", count)
+	s.DB.Callback().Row().After("gorm:row").Register("test:count_coverage_rows", count)
+
+	result, err := s.Coverage("post", nil, "en")
+	if err != nil {
+		t.Fatalf("Coverage: %v", err)
+	}
+	if queries != 1 {
+		t.Errorf("%d queries, want a single grouped query", queries)
+	}
+	want := []KeyCoverage{
+		{Key: "body", Instances: 1, Translated: 0, Complete: false},
+		{Key: "title", Instances: 2, Translated: 2, Complete: true},
+	}
+	if !reflect.DeepEqual(result.Keys, want) {
+		t.Errorf("keys %+v, want %+v", result.Keys, want)
+	}
+	if result.TotalKeys != 2 || result.CompleteKeys != 1 || result.Completeness != 66.67 {
+		t.Errorf("result = %+v, want 1 of 2 keys and 66.67%%", result)
+	}
+	if !reflect.DeepEqual(result.MissingKeys, []string{"body"}) {
+		t.Errorf("missing %v, want [body]", result.MissingKeys)
+	}
+
+	// One instance narrows the report
+	id := uint(1)
+	if result, _ := s.Coverage("post", &id, "en"); result.Completeness != 50 || !reflect.DeepEqual(result.MissingKeys, []string{"body"}) {
+		t.Errorf("post 1: %+v, want 50%% missing body", result)
+	}
+	if result, _ := s.Coverage("post", nil, "sq"); result.Completeness != 100 || len(result.MissingKeys) != 0 {
+		t.Errorf("sq: %+v, want complete", result)
+	}
+	if result, _ := s.Coverage("page", nil, "en"); result.Completeness != 0 || !reflect.DeepEqual(result.MissingKeys, []string{"title"}) {
+		t.Errorf("page: %+v, want nothing translated", result)
+	}
+	// A model without translations has nothing missing
+	if result, _ := s.Coverage("comment", nil, "en"); result.Completeness != 100 || result.TotalKeys != 0 || result.Keys == nil {
+		t.Errorf("comment: %+v, want complete with empty keys", result)
+	}
+}
+
+func TestCoverageRoute(t *testing.T) {
+	s := newCoverageService(t)
+	r := router.New()
+	NewTranslationController(s, nil).Routes(r.Group("/api"))
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/translations/coverage?"+query, nil))
+		return w
+	}
+
+	w := get("model=post&language=en&model_id=2")
+	var result CoverageResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if w.Code != http.StatusOK || result.Completeness != 100 || result.ModelId == nil || *result.ModelId != 2 {
+		t.Errorf("post 2: status %d, %s", w.Code, w.Body)
+	}
+	for _, query := range []string{"model=post", "language=en", "model=post&language=en&model_id=x"} {
+		if w := get(query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, w.Code)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package translation
+
+import (
+	"errors"
+	"time"
+
+	"base/core/types"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Actions recorded in the translation history
+const (
+	HistoryUpdate = "update"
+	HistoryDelete = "delete"
+	HistoryRevert = "revert"
+)
+
+// ErrHistoryNotFound is returned for a history entry that does not exist or
+// belongs to another translation
+var ErrHistoryNotFound = errors.New("translation history entry not found")
+
+// TranslationHistory is a change of a translation value, written in the
+// transaction of the change. Entries outlive the translation they describe.
+type TranslationHistory struct {
+	Id            uint      `json:"id" gorm:"primarykey"`
+	TranslationId uint      `json:"translation_id" gorm:"not null;index"`
+	Action        string    `json:"action" gorm:"type:varchar(16)" example:"update"`
+	OldValue      string    `json:"old_value" gorm:"type:text"`
+	NewValue      string    `json:"new_value" gorm:"type:text"` // Empty for deletes
+	Version       uint      `json:"version"`                    // Version of the translation after the change
+	EditorId      uint      `json:"editor_id"`                  // 0 when no signed-in user made the change
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (TranslationHistory) TableName() string {
+	return "translation_history"
+}
+
+// recordHistory stores a change of translation within tx
+func recordHistory(tx *gorm.DB, translation *Translation, action, oldValue string, editorId uint) error {
+	entry := &TranslationHistory{
+		TranslationId: translation.Id,
+		Action:        action,
+		OldValue:      oldValue,
+		Version:       translation.Version,
+		EditorId:      editorId,
+	}
+	if action != HistoryDelete {
+		entry.NewValue = translation.Value
+	}
+	return tx.Create(entry).Error
+}
+
+// GetHistory returns a page of the changes of a translation, newest first.
+// The history of a translation in the trash stays readable.
+func (s *TranslationService) GetHistory(id uint, page, limit *int) (*types.PaginatedResponse, error) {
+	var count int64
+	if err := s.DB.Unscoped().Model(&Translation{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		s.Logger.Error("Failed to fetch translation", zap.Error(err))
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errors.New("translation not found")
+	}
+
+	currentPage, pageSize := types.NormalizePagination(page, limit)
+	query := s.DB.Model(&TranslationHistory{}).Where("translation_id = ?", id)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		s.Logger.Error("Failed to count translation history", zap.Error(err))
+		return nil, err
+	}
+
+	var entries []TranslationHistory
+	offset := (currentPage - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&entries).Error; err != nil {
+		s.Logger.Error("Failed to fetch translation history", zap.Error(err))
+		return nil, err
+	}
+
+	return &types.PaginatedResponse{
+		Data: entries,
+		Pagination: types.Pagination{
+			Total:      int(total),
+			Page:       currentPage,
+			PageSize:   pageSize,
+			TotalPages: int(total+int64(pageSize)-1) / pageSize,
+		},
+	}, nil
+}
+
+// Revert sets a translation back to the value it had before the history
+// entry, recording the revert as a change of its own
+func (s *TranslationService) Revert(id, historyId, editorId uint) (*TranslationResponse, error) {
+	var translation Translation
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&translation, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("translation not found")
+			}
+			return err
+		}
+
+		var entry TranslationHistory
+		if err := tx.Where("id = ? AND translation_id = ?", historyId, id).First(&entry).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrHistoryNotFound
+			}
+			return err
+		}
+
+		old := translation.Value
+		result := tx.Model(&translation).
+			Where("version = ?", translation.Version).
+			Updates(map[string]any{
+				"value":   entry.OldValue,
+				"version": gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return types.ErrVersionConflict
+		}
+		translation.Value = entry.OldValue
+		translation.Version++
+
+		return recordHistory(tx, &translation, HistoryRevert, old, editorId)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrHistoryNotFound) && !errors.Is(err, types.ErrVersionConflict) && err.Error() != "translation not found" {
+			s.Logger.Error("Failed to revert translation", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	s.Logger.Info("Translation reverted successfully", zap.Uint("id", id), zap.Uint("history_id", historyId))
+	return translation.ToResponse(), nil
+}
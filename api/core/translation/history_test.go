@@ -0,0 +1,182 @@
+package translation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"base/core/router"
+	"base/core/types"
+)
+
+// historyOf returns the history of a translation, newest first
+func historyOf(t *testing.T, s *TranslationService, id uint) []TranslationHistory {
+	t.Helper()
+	response, err := s.GetHistory(id, nil, nil)
+	if err != nil {
+		t.Fatalf("GetHistory: %v", err)
+	}
+	return response.Data.([]TranslationHistory)
+}
+
+func TestHistoryRecordsUpdatesAndDeletes(t *testing.T) {
+	s := newTestService(t)
+	created, err := s.Create(&CreateTranslationRequest{Key: "title", Value: "Hello", Model: "post", ModelId: 1, Language: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries := historyOf(t, s, created.Id); len(entries) != 0 {
+		t.Errorf("creating recorded %+v", entries)
+	}
+
+	if _, err := s.Update(&UpdateTranslationRequest{Id: created.Id, Version: 1, Value: "Hi", EditorId: 7}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Update(&UpdateTranslationRequest{Id: created.Id, Version: 2, Value: "Hey", EditorId: 8}); err != nil {
+		t.Fatal(err)
+	}
+	// A conflicting update changes nothing and records nothing
+	if _, err := s.Update(&UpdateTranslationRequest{Id: created.Id, Version: 1, Value: "Stale", EditorId: 9}); !errors.Is(err, types.ErrVersionConflict) {
+		t.Fatalf("stale update: %v", err)
+	}
+
+	entries := historyOf(t, s, created.Id)
+	if len(entries) != 2 {
+		t.Fatalf("history %+v, want the two updates", entries)
+	}
+	latest, first := entries[0], entries[1]
+	if first.Action != HistoryUpdate || first.OldValue != "Hello" || first.NewValue != "Hi" || first.Version != 2 || first.EditorId != 7 {
+		t.Errorf("first update = %+v", first)
+	}
+	if latest.OldValue != "Hi" || latest.NewValue != "Hey" || latest.Version != 3 || latest.EditorId != 8 || latest.CreatedAt.IsZero() {
+		t.Errorf("second update = %+v", latest)
+	}
+
+	if err := s.Delete(created.Id, 8); err != nil {
+		t.Fatal(err)
+	}
+	// The history of a deleted translation stays readable
+	entries = historyOf(t, s, created.Id)
+	if len(entries) != 3 || entries[0].Action != HistoryDelete || entries[0].OldValue != "Hey" || entries[0].NewValue != "" || entries[0].EditorId != 8 {
+		t.Errorf("after delete: %+v", entries)
+	}
+	if _, err := s.GetHistory(999, nil, nil); err == nil {
+		t.Error("history of a missing translation")
+	}
+}
+
+func TestRevertRestoresOldValue(t *testing.T) {
+	s := newTestService(t)
+	created, _ := s.Create(&CreateTranslationRequest{Key: "title", Value: "Hello", Model: "post", ModelId: 1, Language: "en"})
+	s.Update(&UpdateTranslationRequest{Id: created.Id, Version: 1, Value: "Typo", EditorId: 7})
+	mistake := historyOf(t, s, created.Id)[0]
+
+	reverted, err := s.Revert(created.Id, mistake.Id, 9)
+	if err != nil {
+		t.Fatalf("Revert: %v", err)
+	}
+	if reverted.Value != "Hello" || reverted.Version != 3 {
+		t.Errorf("reverted to %q at version %d, want Hello at 3", reverted.Value, reverted.Version)
+	}
+	entries := historyOf(t, s, created.Id)
+	if len(entries) != 2 || entries[0].Action != HistoryRevert || entries[0].OldValue != "Typo" || entries[0].NewValue != "Hello" || entries[0].EditorId != 9 {
+		t.Errorf("revert recorded as %+v", entries[0])
+	}
+
+	other, _ := s.Create(&CreateTranslationRequest{Key: "body", Value: "Text", Model: "post", ModelId: 1, Language: "en"})
+	if _, err := s.Revert(other.Id, mistake.Id, 9); !errors.Is(err, ErrHistoryNotFound) {
+		t.Errorf("reverting with another translation's entry: %v", err)
+	}
+}
+
+func TestHistoryRoutes(t *testing.T) {
+	s := newTestService(t)
+	created, _ := s.Create(&CreateTranslationRequest{Key: "title", Value: "Hello", Model: "post", ModelId: 1, Language: "en"})
+	r := router.New()
+	api := r.Group("/api")
+	api.Use(func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			c.Set("user_id", uint(5))
+			return next(c)
+		}
+	})
+	NewTranslationController(s, nil).Routes(api)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	base := "/api/translations/by-id/" + strconv.Itoa(int(created.Id))
+
+	if w := do(http.MethodPut, base, `{"version":1,"value":"Hi"}`); w.Code != http.StatusOK {
+		t.Fatalf("update: status %d: %s", w.Code, w.Body)
+	}
+	w := do(http.MethodGet, base+"/history", "")
+	var page struct {
+		Data []TranslationHistory `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &page)
+	if w.Code != http.StatusOK || len(page.Data) != 1 || page.Data[0].EditorId != 5 || page.Data[0].NewValue != "Hi" {
+		t.Fatalf("history: status %d: %s", w.Code, w.Body)
+	}
+
+	revert := base + "/history/" + strconv.Itoa(int(page.Data[0].Id)) + "/revert"
+	if w := do(http.MethodPost, revert, ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"value":"Hello"`) {
+		t.Errorf("revert: status %d: %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodDelete, base, ""); w.Code != http.StatusOK && w.Code != http.StatusNoContent {
+		t.Fatalf("delete: status %d: %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodGet, base+"/history", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"action":"delete"`) {
+		t.Errorf("history after delete: status %d: %s", w.Code, w.Body)
+	}
+
+	for path, want := range map[string]int{
+		"/api/translations/by-id/999/history":          http.StatusNotFound,
+		"/api/translations/by-id/x/history":            http.StatusBadRequest,
+		base + "/history/999/revert":                   http.StatusNotFound,
+		"/api/translations/by-id/999/history/1/revert": http.StatusNotFound,
+	} {
+		method := http.MethodGet
+		if strings.HasSuffix(path, "/revert") {
+			method = http.MethodPost
+		}
+		if w := do(method, path, ""); w.Code != want {
+			t.Errorf("%s %s: status %d, want %d", method, path, w.Code, want)
+		}
+	}
+}
+
+func TestBulkUpdateRecordsChangedValues(t *testing.T) {
+	s := newTestService(t)
+	title, _ := s.Create(&CreateTranslationRequest{Key: "title", Value: "Hello", Model: "post", ModelId: 1, Language: "en"})
+	body, _ := s.Create(&CreateTranslationRequest{Key: "body", Value: "Text", Model: "post", ModelId: 1, Language: "en"})
+
+	request := &BulkTranslationRequest{Model: "post", ModelId: 1, Language: "en", EditorId: 4,
+		Translations: map[string]string{"title": "Hi", "body": "Text", "footer": "Bye"}}
+	request.DryRun = true
+	if _, err := s.BulkUpdate(request); err != nil {
+		t.Fatal(err)
+	}
+	if entries := historyOf(t, s, title.Id); len(entries) != 0 {
+		t.Errorf("dry run recorded %+v", entries)
+	}
+
+	request.DryRun = false
+	if _, err := s.BulkUpdate(request); err != nil {
+		t.Fatal(err)
+	}
+	entries := historyOf(t, s, title.Id)
+	if len(entries) != 1 || entries[0].OldValue != "Hello" || entries[0].NewValue != "Hi" || entries[0].EditorId != 4 {
+		t.Errorf("title history %+v", entries)
+	}
+	if entries := historyOf(t, s, body.Id); len(entries) != 0 {
+		t.Errorf("unchanged body recorded %+v", entries)
+	}
+}
@@ -0,0 +1,173 @@
+package translation
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// maxLocalizeDepth bounds how deep Localize follows nested values, so
+// self-referencing data cannot recurse forever
+const maxLocalizeDepth = 32
+
+// localizableTypes caches whether a type can hold a Field
+var localizableTypes sync.Map
+
+// Localize returns a copy of obj with every Field set to its best translation
+// for languages, most preferred first, so it marshals as a plain string. A
+// language matches a translation of the same tag or, for a regional tag such
+// as de-CH, of its base language; a Field with no match keeps its original.
+// It is the router's JSONLocalizer; obj itself is never modified.
+func Localize(obj any, languages []string) any {
+	if obj == nil || len(languages) == 0 {
+		return obj
+	}
+
+	value := reflect.ValueOf(obj)
+	if !localizable(value.Type()) {
+		return obj
+	}
+	return localizeValue(value, languages, 0).Interface()
+}
+
+// Best returns the translation of f best matching languages, falling back to
+// the original
+func (f Field) Best(languages []string) string {
+	if len(f.Values) == 0 {
+		return f.Original
+	}
+
+	for _, language := range languages {
+		if value, ok := f.lookup(language); ok {
+			return value
+		}
+		if base, _, regional := strings.Cut(language, "-"); regional {
+			if value, ok := f.lookup(base); ok {
+				return value
+			}
+		}
+	}
+	return f.Original
+}
+
+// lookup returns the non-empty translation of language, matching tags case
+// insensitively
+func (f Field) lookup(language string) (string, bool) {
+	if value := f.Values[language]; value != "" {
+		return value, true
+	}
+	for tag, value := range f.Values {
+		if value != "" && strings.EqualFold(tag, language) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// localizeValue returns a copy of v with its Fields localized. Values of
+// types that cannot hold a Field are returned as they are.
+func localizeValue(v reflect.Value, languages []string, depth int) reflect.Value {
+	if depth > maxLocalizeDepth || !v.IsValid() || !localizable(v.Type()) {
+		return v
+	}
+
+	if v.Type() == fieldType {
+		return reflect.ValueOf(Field{Original: v.Interface().(Field).Best(languages)})
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(localizeValue(v.Elem(), languages, depth+1))
+		return out
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(localizeValue(v.Elem(), languages, depth+1))
+		return out
+
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			field := out.Field(i)
+			if field.CanSet() && localizable(field.Type()) {
+				field.Set(localizeValue(v.Field(i), languages, depth+1))
+			}
+		}
+		return out
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(localizeValue(v.Index(i), languages, depth+1))
+		}
+		return out
+
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(localizeValue(v.Index(i), languages, depth+1))
+		}
+		return out
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), localizeValue(iter.Value(), languages, depth+1))
+		}
+		return out
+	}
+
+	return v
+}
+
+// localizable reports whether values of t can hold a Field. Interfaces can
+// hold anything, so they always can.
+func localizable(t reflect.Type) bool {
+	if cached, ok := localizableTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	result := holdsField(t, map[reflect.Type]bool{})
+	localizableTypes.Store(t, result)
+	return result
+}
+
+// holdsField walks t for a Field. Types already being walked are skipped;
+// a recursive type holds a Field only if one is reachable another way.
+func holdsField(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if t == fieldType {
+		return true
+	}
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return holdsField(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() && holdsField(t.Field(i).Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
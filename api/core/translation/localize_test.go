@@ -0,0 +1,92 @@
+package translation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"base/core/router"
+	"base/core/router/middleware"
+)
+
+type localizedPost struct {
+	Id    uint   `json:"id"`
+	Title Field  `json:"title"`
+	Slug  string `json:"slug"`
+}
+
+func newPost() localizedPost {
+	return localizedPost{
+		Id:    1,
+		Title: Field{Original: "Hello", Values: map[string]string{"sq": "Përshëndetje", "de": "Hallo", "fr": ""}},
+		Slug:  "hello",
+	}
+}
+
+func TestFieldsAreServedInPreferredLanguage(t *testing.T) {
+	r := router.New()
+	r.SetJSONLocalizer(Localize)
+	r.Use(middleware.Language())
+	post := newPost()
+	r.GET("/posts/1", func(c *router.Context) error { return c.JSON(http.StatusOK, post) })
+	r.GET("/posts", func(c *router.Context) error {
+		return c.JSON(http.StatusOK, map[string]any{"data": []*localizedPost{&post}})
+	})
+
+	get := func(path, acceptLanguage string) string {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return strings.TrimSuffix(w.Body.String(), "\n")
+	}
+
+	tests := []struct {
+		path, acceptLanguage, want string
+	}{
+		{"/posts/1", "sq", `{"id":1,"title":"Përshëndetje","slug":"hello"}`},
+		{"/posts/1", "de-CH, sq;q=0.8", `{"id":1,"title":"Hallo","slug":"hello"}`},
+		{"/posts/1", "en;q=0.5, SQ;q=0.9", `{"id":1,"title":"Përshëndetje","slug":"hello"}`},
+		// An empty translation does not count, so the next preference wins
+		{"/posts/1", "fr, de;q=0.5", `{"id":1,"title":"Hallo","slug":"hello"}`},
+		{"/posts/1", "it", `{"id":1,"title":"Hello","slug":"hello"}`},
+		{"/posts/1?lang=de", "sq", `{"id":1,"title":"Hallo","slug":"hello"}`},
+		{"/posts/1?lang=it,sq", "de", `{"id":1,"title":"Përshëndetje","slug":"hello"}`},
+		{"/posts", "de", `{"data":[{"id":1,"title":"Hallo","slug":"hello"}]}`},
+	}
+	for _, tt := range tests {
+		if got := get(tt.path, tt.acceptLanguage); got != tt.want {
+			t.Errorf("%s with %q: %s, want %s", tt.path, tt.acceptLanguage, got, tt.want)
+		}
+	}
+
+	// Without a language the field keeps its full form
+	var plain map[string]any
+	json.Unmarshal([]byte(get("/posts/1", "")), &plain)
+	if title, ok := plain["title"].(map[string]any); !ok || title["original"] != "Hello" {
+		t.Errorf("no language: title %v, want the original with its translations", plain["title"])
+	}
+	if !reflect.DeepEqual(post, newPost()) {
+		t.Errorf("serializing changed the handler's value: %+v", post)
+	}
+}
+
+func TestLocalizeLeavesOtherValuesAlone(t *testing.T) {
+	type plain struct{ Name string }
+	value := plain{Name: "x"}
+	if got := Localize(value, []string{"de"}); got != value {
+		t.Errorf("Localize(%v) = %v", value, got)
+	}
+	if got := Localize(nil, []string{"de"}); got != nil {
+		t.Errorf("Localize(nil) = %v", got)
+	}
+	post := newPost()
+	if got := Localize(&post, nil).(*localizedPost); got != &post {
+		t.Error("no languages copied the value")
+	}
+}
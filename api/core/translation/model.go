@@ -4,22 +4,26 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 // Translation represents a translation entity for any model field
+// idx_translation_lookup serves single lookups by key; idx_translation_list
+// serves listing by model and model_id ordered by updated_at.
 type Translation struct {
 	Id        uint           `json:"id" gorm:"primarykey"`
 	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"index:idx_translation_list,priority:3"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 	Key       string         `json:"key" gorm:"type:varchar(255);index:idx_translation_lookup"`
 	Value     string         `json:"value" gorm:"type:text"`
-	Model     string         `json:"model" gorm:"type:varchar(255);index:idx_translation_lookup"`
-	ModelId   uint           `json:"model_id" gorm:"type:uint;index:idx_translation_lookup"`
+	Model     string         `json:"model" gorm:"type:varchar(255);index:idx_translation_lookup;index:idx_translation_list,priority:1"`
+	ModelId   uint           `json:"model_id" gorm:"type:uint;index:idx_translation_lookup;index:idx_translation_list,priority:2"`
 	Language  string         `json:"language" gorm:"type:char(5);index:idx_translation_lookup"`
+	Version   uint           `json:"version" gorm:"not null;default:1"` // Incremented on each update for optimistic locking
 }
 
 // Field represents a field that can be translated into multiple languages
@@ -179,11 +183,15 @@ func NewField(original string) Field {
 	}
 }
 
-// LoadTranslations loads translations from the database using the global translation service
+// LoadTranslations loads translations from the database using the service
+// registered with EnableAutoLoad. It does nothing when none is registered.
+// Fields of queried models are filled by the AutoLoader already; this is for
+// values built outside a query.
 func (f *Field) LoadTranslations(modelName string, modelId uint, fieldName string) error {
-	// This would need to be implemented with a global service instance
-	// For now, we'll implement this as a hook in the GORM callbacks
-	return nil
+	if defaultService == nil {
+		return nil
+	}
+	return defaultService.LoadTranslationsForField(f, modelName, modelId, fieldName)
 }
 
 // AutoLoadTranslations automatically loads translations if they haven't been loaded yet
@@ -218,6 +226,7 @@ type TranslationListResponse struct {
 	Model     string    `json:"model"`
 	ModelId   uint      `json:"model_id"`
 	Language  string    `json:"language"`
+	Version   uint      `json:"version"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
@@ -232,6 +241,7 @@ type TranslationResponse struct {
 	Model     string         `json:"model"`
 	ModelId   uint           `json:"model_id"`
 	Language  string         `json:"language"`
+	Version   uint           `json:"version"`
 }
 
 // CreateTranslationRequest represents the request payload for creating a Translation
@@ -243,14 +253,17 @@ type CreateTranslationRequest struct {
 	Language string `json:"language" binding:"required"`
 }
 
-// UpdateTranslationRequest represents the request payload for updating a Translation
+// UpdateTranslationRequest represents the request payload for updating a Translation.
+// Version must be the version the client last read; the update is rejected if it changed since.
 type UpdateTranslationRequest struct {
-	Id       uint   `json:"id" binding:"required"`
+	Id       uint   `json:"id"` // Taken from the route
+	Version  uint   `json:"version" binding:"required"`
 	Key      string `json:"key,omitempty"`
 	Value    string `json:"value,omitempty"`
 	Model    string `json:"model,omitempty"`
 	ModelId  uint   `json:"model_id,omitempty"`
 	Language string `json:"language,omitempty"`
+	EditorId uint   `json:"-"` // Signed-in user making the change, recorded in the history
 }
 
 // BulkTranslationRequest represents a request to update multiple translations at once
@@ -259,6 +272,106 @@ type BulkTranslationRequest struct {
 	ModelId      uint              `json:"model_id" binding:"required"`
 	Language     string            `json:"language" binding:"required"`
 	Translations map[string]string `json:"translations" binding:"required"` // key -> value mapping
+	DryRun       bool              `json:"dry_run"`                         // Report the changes without writing them
+	EditorId     uint              `json:"-"`                               // Signed-in user making the changes, recorded in the history
+}
+
+// Actions reported for a key by a bulk update
+const (
+	ChangeCreate    = "create"
+	ChangeUpdate    = "update"
+	ChangeUnchanged = "unchanged"
+)
+
+// TranslationChange is what a bulk update did, or would do, to one key
+type TranslationChange struct {
+	Key      string  `json:"key"`
+	Action   string  `json:"action"`
+	OldValue *string `json:"old_value,omitempty"`
+	NewValue string  `json:"new_value"`
+}
+
+// BulkUpdateResult summarizes a bulk update. Changes lists created and
+// updated keys; unchanged keys are only counted.
+type BulkUpdateResult struct {
+	DryRun    bool                `json:"dry_run"`
+	Created   int                 `json:"created"`
+	Updated   int                 `json:"updated"`
+	Unchanged int                 `json:"unchanged"`
+	Changes   []TranslationChange `json:"changes"`
+}
+
+// Policies for translations that already exist in a bulk create
+const (
+	ConflictError  = "error"  // Roll the whole batch back
+	ConflictSkip   = "skip"   // Keep the existing translation
+	ConflictUpdate = "update" // Overwrite the existing value
+)
+
+// BulkCreateTranslationRequest creates many translations in one transaction.
+// A translation exists when one with the same key, model, model_id and
+// language does, including one created earlier in the same batch.
+type BulkCreateTranslationRequest struct {
+	Translations []CreateTranslationRequest `json:"translations" binding:"required,min=1,max=1000,dive"`
+	OnConflict   string                     `json:"on_conflict" binding:"omitempty,oneof=error skip update" example:"skip"` // error (default), skip or update
+	EditorId     uint                       `json:"-"`                                                                      // Signed-in user overwriting values, recorded in the history
+}
+
+// Statuses of an item of a bulk create
+const (
+	BulkItemCreated   = "created"
+	BulkItemUpdated   = "updated"
+	BulkItemUnchanged = "unchanged" // Existed with the same value under the update policy
+	BulkItemSkipped   = "skipped"
+	BulkItemConflict  = "conflict" // Existed under the error policy
+)
+
+// BulkCreateItemResult is what happened to one item of a bulk create, at
+// the item's index in the request
+type BulkCreateItemResult struct {
+	Index    int    `json:"index"`
+	Key      string `json:"key"`
+	Model    string `json:"model"`
+	ModelId  uint   `json:"model_id"`
+	Language string `json:"language"`
+	Status   string `json:"status"`
+	Id       uint   `json:"id,omitempty"` // Translation created, updated or left in place
+}
+
+// BulkCreateResult summarizes a bulk create. When Committed is false a
+// conflict rolled the batch back and nothing was written.
+type BulkCreateResult struct {
+	OnConflict string                 `json:"on_conflict"`
+	Committed  bool                   `json:"committed"`
+	Created    int                    `json:"created"`
+	Updated    int                    `json:"updated"`
+	Unchanged  int                    `json:"unchanged"`
+	Skipped    int                    `json:"skipped"`
+	Conflicts  int                    `json:"conflicts"`
+	Results    []BulkCreateItemResult `json:"results"`
+}
+
+// TranslationSearchResponse represents a search hit with the fields that matched the query
+type TranslationSearchResponse struct {
+	TranslationListResponse
+	MatchedFields []string `json:"matched_fields"`
+}
+
+// BundleRow is one translation in an export/import bundle
+type BundleRow struct {
+	Model    string `json:"model"`
+	ModelId  uint   `json:"model_id"`
+	Language string `json:"language"`
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+}
+
+// ImportResult summarizes a bundle import
+type ImportResult struct {
+	Created int      `json:"created"`
+	Updated int      `json:"updated"`
+	Skipped int      `json:"skipped"`
+	Errors  []string `json:"errors,omitempty"`
 }
 
 // ToListResponse converts the model to a list response
@@ -273,6 +386,7 @@ func (item *Translation) ToListResponse() *TranslationListResponse {
 		Model:     item.Model,
 		ModelId:   item.ModelId,
 		Language:  item.Language,
+		Version:   item.Version,
 		UpdatedAt: item.UpdatedAt,
 	}
 }
@@ -292,6 +406,7 @@ func (item *Translation) ToResponse() *TranslationResponse {
 		Model:     item.Model,
 		ModelId:   item.ModelId,
 		Language:  item.Language,
+		Version:   item.Version,
 	}
 }
 
@@ -300,3 +415,23 @@ func (item *Translation) Preload(db *gorm.DB) *gorm.DB {
 	query := db
 	return query
 }
+
+// ToSearchResponse converts the model to a search response, recording which
+// of key and value contain the (case-insensitive) query
+func (item *Translation) ToSearchResponse(query string) *TranslationSearchResponse {
+	if item == nil {
+		return nil
+	}
+	needle := strings.ToLower(query)
+	matched := make([]string, 0, 2)
+	if strings.Contains(strings.ToLower(item.Key), needle) {
+		matched = append(matched, "key")
+	}
+	if strings.Contains(strings.ToLower(item.Value), needle) {
+		matched = append(matched, "value")
+	}
+	return &TranslationSearchResponse{
+		TranslationListResponse: *item.ToListResponse(),
+		MatchedFields:           matched,
+	}
+}
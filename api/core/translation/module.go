@@ -21,6 +21,9 @@ type Module struct {
 
 func NewTranslationModule(db *gorm.DB, router *router.RouterGroup, log logger.Logger, emitter *emitter.Emitter, storage *storage.ActiveStorage) module.Module {
 	service := NewTranslationService(db, emitter, storage, log)
+	if err := EnableAutoLoad(db, service); err != nil {
+		log.Error("Failed to register translation auto-loading", logger.String("error", err.Error()))
+	}
 	controller := NewTranslationController(service, storage)
 
 	m := &Module{
@@ -41,9 +44,9 @@ func (m *Module) Routes(router *router.RouterGroup) {
 }
 
 func (m *Module) Migrate() error {
-	return m.DB.AutoMigrate(&Translation{})
+	return m.DB.AutoMigrate(&Translation{}, &TranslationHistory{})
 }
 
 func (m *Module) GetModels() []any {
-	return []any{&Translation{}}
+	return []any{&Translation{}, &TranslationHistory{}}
 }
@@ -0,0 +1,151 @@
+package translation
+
+import (
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ExistenceResolver reports which of ids still exist for a model. Ids missing
+// from the returned set have orphaned translations.
+type ExistenceResolver func(db *gorm.DB, ids []uint) (map[uint]bool, error)
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = make(map[string]ExistenceResolver)
+)
+
+// RegisterModelResolver registers how to check that the instances of a
+// translated model exist. Orphan cleanup leaves the translations of models
+// without a resolver alone, since it cannot tell whether they are orphaned.
+func RegisterModelResolver(model string, resolver ExistenceResolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[model] = resolver
+}
+
+// TableResolver resolves ids against the id column of table. Soft-deleted
+// rows still exist, so their translations survive a restore.
+func TableResolver(table string) ExistenceResolver {
+	return func(db *gorm.DB, ids []uint) (map[uint]bool, error) {
+		var found []uint
+		if err := db.Table(table).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+			return nil, err
+		}
+		existing := make(map[uint]bool, len(found))
+		for _, id := range found {
+			existing[id] = true
+		}
+		return existing, nil
+	}
+}
+
+func resolverFor(model string) ExistenceResolver {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	return resolvers[model]
+}
+
+// OrphanResult counts the translations whose model instance no longer exists,
+// by model. Unresolved lists the models skipped for lack of a resolver.
+type OrphanResult struct {
+	DryRun     bool             `json:"dry_run"`
+	Total      int64            `json:"total"`
+	Models     map[string]int64 `json:"models"`
+	Unresolved []string         `json:"unresolved"`
+}
+
+// RemoveOrphans deletes the translations of model instances that no longer
+// exist, or only counts them with dryRun. Deleted translations go to the
+// trash like any other.
+func (s *TranslationService) RemoveOrphans(dryRun bool) (*OrphanResult, error) {
+	result := &OrphanResult{
+		DryRun:     dryRun,
+		Models:     make(map[string]int64),
+		Unresolved: []string{},
+	}
+
+	var models []string
+	if err := s.DB.Model(&Translation{}).Distinct("model").Order("model").Pluck("model", &models).Error; err != nil {
+		s.Logger.Error("Failed to list translated models", zap.Error(err))
+		return nil, err
+	}
+
+	for _, model := range models {
+		resolver := resolverFor(model)
+		if resolver == nil {
+			result.Unresolved = append(result.Unresolved, model)
+			continue
+		}
+
+		count, err := s.removeModelOrphans(model, resolver, dryRun)
+		if err != nil {
+			s.Logger.Error("Failed to remove orphaned translations", zap.String("model", model), zap.Error(err))
+			return nil, err
+		}
+		if count > 0 {
+			result.Models[model] = count
+			result.Total += count
+		}
+	}
+	sort.Strings(result.Unresolved)
+
+	if !dryRun && result.Total > 0 {
+		s.Logger.Info("Orphaned translations deleted", zap.Int64("count", result.Total))
+	}
+	return result, nil
+}
+
+// removeModelOrphans walks the ids translated for model in batches and
+// deletes or counts the translations of those the resolver does not find
+func (s *TranslationService) removeModelOrphans(model string, resolver ExistenceResolver, dryRun bool) (int64, error) {
+	var total int64
+	var lastId uint
+	for {
+		var ids []uint
+		err := s.DB.Model(&Translation{}).
+			Where("model = ? AND model_id > ?", model, lastId).
+			Distinct("model_id").
+			Order("model_id").
+			Limit(autoLoadBatchSize).
+			Pluck("model_id", &ids).Error
+		if err != nil {
+			return 0, err
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+		lastId = ids[len(ids)-1]
+
+		existing, err := resolver(s.DB, ids)
+		if err != nil {
+			return 0, err
+		}
+		var missing []uint
+		for _, id := range ids {
+			if !existing[id] {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		query := s.DB.Model(&Translation{}).Where("model = ? AND model_id IN ?", model, missing)
+		if dryRun {
+			var count int64
+			if err := query.Count(&count).Error; err != nil {
+				return 0, err
+			}
+			total += count
+			continue
+		}
+		deleted := query.Delete(&Translation{})
+		if deleted.Error != nil {
+			return 0, deleted.Error
+		}
+		total += deleted.RowsAffected
+	}
+}
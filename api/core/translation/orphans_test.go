@@ -0,0 +1,161 @@
+package translation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"base/core/router"
+
+	"gorm.io/gorm"
+)
+
+type orphanPost struct {
+	Id        uint
+	DeletedAt gorm.DeletedAt
+}
+
+// registerResolver registers resolver for model for the rest of the test
+func registerResolver(t *testing.T, model string, resolver ExistenceResolver) {
+	t.Helper()
+	RegisterModelResolver(model, resolver)
+	t.Cleanup(func() {
+		resolversMu.Lock()
+		delete(resolvers, model)
+		resolversMu.Unlock()
+	})
+}
+
+// newOrphanService stores posts 1 and 2, with post 2 soft-deleted, and
+// translations for posts 1 to 4, pages 1 and 9 and an unresolved model
+func newOrphanService(t *testing.T) *TranslationService {
+	t.Helper()
+	s := newTestService(t)
+	if err := s.DB.AutoMigrate(&orphanPost{}); err != nil {
+		t.Fatal(err)
+	}
+	s.DB.Create(&orphanPost{Id: 1})
+	s.DB.Create(&orphanPost{Id: 2})
+	s.DB.Delete(&orphanPost{Id: 2})
+
+	registerResolver(t, "post", TableResolver("orphan_posts"))
+	registerResolver(t, "page", func(db *gorm.DB, ids []uint) (map[uint]bool, error) {
+		return map[uint]bool{1: true}, nil
+	})
+
+	for _, tr := range []Translation{
+		{Model: "post", ModelId: 1, Key: "title", Language: "sq"},
+		{Model: "post", ModelId: 2, Key: "title", Language: "sq"},
+		{Model: "post", ModelId: 3, Key: "title", Language: "sq"},
+		{Model: "post", ModelId: 3, Key: "body", Language: "sq"},
+		{Model: "post", ModelId: 4, Key: "title", Language: "de"},
+		{Model: "page", ModelId: 1, Key: "title", Language: "sq"},
+		{Model: "page", ModelId: 9, Key: "title", Language: "sq"},
+		{Model: "widget", ModelId: 5, Key: "title", Language: "sq"},
+	} {
+		tr.Value = "v"
+		if err := s.DB.Create(&tr).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func remaining(t *testing.T, s *TranslationService) int64 {
+	t.Helper()
+	var count int64
+	if err := s.DB.Model(&Translation{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestRemoveOrphans(t *testing.T) {
+	s := newOrphanService(t)
+
+	result, err := s.RemoveOrphans(true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	want := map[string]int64{"post": 3, "page": 1}
+	if !result.DryRun || result.Total != 4 || !reflect.DeepEqual(result.Models, want) {
+		t.Errorf("dry run = %+v, want 4 orphans: %v", result, want)
+	}
+	if !reflect.DeepEqual(result.Unresolved, []string{"widget"}) {
+		t.Errorf("unresolved = %v, want widget", result.Unresolved)
+	}
+	if n := remaining(t, s); n != 8 {
+		t.Fatalf("the dry run deleted translations: %d left", n)
+	}
+
+	if result, err = s.RemoveOrphans(false); err != nil {
+		t.Fatalf("RemoveOrphans: %v", err)
+	}
+	if result.DryRun || result.Total != 4 || !reflect.DeepEqual(result.Models, want) {
+		t.Errorf("result = %+v", result)
+	}
+	var left []Translation
+	s.DB.Order("model, model_id").Find(&left)
+	var kept []string
+	for _, tr := range left {
+		kept = append(kept, fmt.Sprintf("%s/%d", tr.Model, tr.ModelId))
+	}
+	// The soft-deleted post keeps its translations for a restore
+	if wantKept := []string{"page/1", "post/1", "post/2", "widget/5"}; !reflect.DeepEqual(kept, wantKept) {
+		t.Errorf("kept %v, want %v", kept, wantKept)
+	}
+
+	// Deleted translations go to the trash
+	var trashed int64
+	s.DB.Unscoped().Model(&Translation{}).Where("deleted_at IS NOT NULL").Count(&trashed)
+	if trashed != 4 {
+		t.Errorf("%d translations in the trash, want 4", trashed)
+	}
+
+	if result, _ = s.RemoveOrphans(false); result.Total != 0 {
+		t.Errorf("second run removed %d", result.Total)
+	}
+}
+
+func TestRemoveOrphansStopsOnResolverError(t *testing.T) {
+	s := newOrphanService(t)
+	failure := errors.New("table missing")
+	registerResolver(t, "page", func(*gorm.DB, []uint) (map[uint]bool, error) { return nil, failure })
+
+	if _, err := s.RemoveOrphans(false); !errors.Is(err, failure) {
+		t.Errorf("got %v, want the resolver error", err)
+	}
+}
+
+func TestRemoveOrphansRoute(t *testing.T) {
+	s := newOrphanService(t)
+	r := router.New()
+	NewTranslationController(s, nil).Routes(r.Group("/api"))
+	remove := func(query string) (int, OrphanResult) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/translations/orphans"+query, nil))
+		var result OrphanResult
+		json.Unmarshal(w.Body.Bytes(), &result)
+		return w.Code, result
+	}
+
+	if code, result := remove("?dry_run=true"); code != http.StatusOK || !result.DryRun || result.Total != 4 {
+		t.Errorf("dry run: status %d, %+v", code, result)
+	}
+	if n := remaining(t, s); n != 8 {
+		t.Errorf("the dry run deleted translations: %d left", n)
+	}
+	if code, _ := remove("?dry_run=maybe"); code != http.StatusBadRequest {
+		t.Errorf("invalid dry_run: status %d, want 400", code)
+	}
+	if code, result := remove(""); code != http.StatusOK || result.DryRun || result.Models["post"] != 3 {
+		t.Errorf("cleanup: status %d, %+v", code, result)
+	}
+	if n := remaining(t, s); n != 4 {
+		t.Errorf("%d translations left, want 4", n)
+	}
+}
@@ -3,13 +3,17 @@ package translation
 import (
 	"base/core/emitter"
 	"base/core/logger"
+	"base/core/router"
 	"base/core/storage"
 	"base/core/types"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TranslationService struct {
@@ -28,30 +32,39 @@ func NewTranslationService(db *gorm.DB, emitter *emitter.Emitter, storage *stora
 	}
 }
 
-func (s *TranslationService) GetAll(page *int, limit *int, model string, modelId *uint) (*types.PaginatedResponse, error) {
-	// Default values for pagination
-	currentPage := 1
-	pageSize := 10
+// listQueryFields are the fields the translation list can be filtered and sorted by
+var listQueryFields = router.QueryAllowlist{
+	Filters: map[string]string{
+		"model":    "model",
+		"model_id": "model_id",
+		"key":      "key",
+		"language": "language",
+	},
+	Sort: map[string]string{
+		"id":         "id",
+		"key":        "key",
+		"language":   "language",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+}
 
-	if page != nil {
-		currentPage = *page
-	}
-	if limit != nil {
-		pageSize = *limit
-	}
+// GetAll returns a page of translations matching filters, most recently
+// updated first unless filters sort otherwise. Unknown filter or sort fields
+// fail with router.ErrInvalidQuery.
+func (s *TranslationService) GetAll(filters *router.QueryFilters) (*types.PaginatedResponse, error) {
+	currentPage, pageSize := types.NormalizePagination(filters.Page, filters.Limit)
 
 	var translations []*Translation
 	var total int64
 
 	// Build query with filters
-	query := s.DB.Model(&Translation{})
-	if model != "" {
-		s.Logger.Info("Filtering translations by model", zap.String("model", model))
-		query = query.Where("model = ?", model)
+	query, err := filters.Apply(s.DB.Model(&Translation{}), listQueryFields)
+	if err != nil {
+		return nil, err
 	}
-	if modelId != nil {
-		s.Logger.Info("Filtering translations by model_id", zap.Uint("model_id", *modelId))
-		query = query.Where("model_id = ?", *modelId)
+	if len(filters.Sort) == 0 {
+		query = query.Order("updated_at DESC")
 	}
 
 	// Count total records with filters
@@ -64,7 +77,7 @@ func (s *TranslationService) GetAll(page *int, limit *int, model string, modelId
 	offset := (currentPage - 1) * pageSize
 
 	// Get translations with pagination and filters
-	if err := query.Offset(offset).Limit(pageSize).Order("updated_at DESC").Find(&translations).Error; err != nil {
+	if err := query.Offset(offset).Limit(pageSize).Find(&translations).Error; err != nil {
 		s.Logger.Error("Failed to fetch translations", zap.Error(err))
 		return nil, err
 	}
@@ -89,6 +102,77 @@ func (s *TranslationService) GetAll(page *int, limit *int, model string, modelId
 	}, nil
 }
 
+// Search finds translations whose key or value contains the query, optionally
+// narrowed by language and model, and reports which field matched on each hit
+func (s *TranslationService) Search(query string, page *int, limit *int, language string, model string) (*types.PaginatedResponse, error) {
+	currentPage, pageSize := types.NormalizePagination(page, limit)
+
+	var translations []*Translation
+	var total int64
+
+	// Columns are quoted by the dialect, as key is reserved in MySQL
+	pattern := "%" + escapeLike(strings.ToLower(query)) + "%"
+	db := s.DB.Model(&Translation{}).Where(clause.Or(
+		containsPattern("key", pattern),
+		containsPattern("value", pattern),
+	))
+	if language != "" {
+		db = db.Where("language = ?", language)
+	}
+	if model != "" {
+		db = db.Where("model = ?", model)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		s.Logger.Error("Failed to count translation search results", zap.Error(err))
+		return nil, err
+	}
+
+	offset := (currentPage - 1) * pageSize
+	if err := db.Offset(offset).Limit(pageSize).Order("updated_at DESC").Find(&translations).Error; err != nil {
+		s.Logger.Error("Failed to search translations", zap.Error(err))
+		return nil, err
+	}
+
+	responses := make([]*TranslationSearchResponse, len(translations))
+	for i, translation := range translations {
+		responses[i] = translation.ToSearchResponse(query)
+	}
+
+	totalPages := int(total+int64(pageSize)-1) / pageSize
+
+	return &types.PaginatedResponse{
+		Data: responses,
+		Pagination: types.Pagination{
+			Total:      int(total),
+			Page:       currentPage,
+			PageSize:   pageSize,
+			TotalPages: totalPages,
+		},
+	}, nil
+}
+
+// likeEscape escapes the wildcards of a LIKE pattern. A character other than
+// the backslash is used, as MySQL also treats backslashes in strings as
+// escapes.
+const likeEscape = "!"
+
+// likeEscaper makes % and _ in a search query match themselves
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// escapeLike escapes text for use inside a LIKE pattern with likeEscape
+func escapeLike(text string) string {
+	return likeEscaper.Replace(text)
+}
+
+// containsPattern matches the lower-cased column against an escaped pattern
+func containsPattern(column, pattern string) clause.Expression {
+	return clause.Expr{
+		SQL:  "LOWER(?) LIKE ? ESCAPE ?",
+		Vars: []any{clause.Column{Name: column}, pattern, likeEscape},
+	}
+}
+
 func (s *TranslationService) GetByID(id uint) (*TranslationResponse, error) {
 	var translation Translation
 	if err := s.DB.First(&translation, id).Error; err != nil {
@@ -145,6 +229,7 @@ func (s *TranslationService) Update(request *UpdateTranslationRequest) (*Transla
 	}
 
 	// Update fields if provided
+	oldValue := translation.Value
 	if request.Key != "" {
 		translation.Key = request.Key
 	}
@@ -161,7 +246,34 @@ func (s *TranslationService) Update(request *UpdateTranslationRequest) (*Transla
 		translation.Language = request.Language
 	}
 
-	if err := s.DB.Save(&translation).Error; err != nil {
+	// Only write if nobody else updated the row since the client read it
+	if translation.Version != request.Version {
+		return nil, types.ErrVersionConflict
+	}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&translation).
+			Where("version = ?", request.Version).
+			Updates(map[string]any{
+				"key":      translation.Key,
+				"value":    translation.Value,
+				"model":    translation.Model,
+				"model_id": translation.ModelId,
+				"language": translation.Language,
+				"version":  gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return types.ErrVersionConflict
+		}
+		translation.Version++
+		return recordHistory(tx, &translation, HistoryUpdate, oldValue, request.EditorId)
+	})
+	if errors.Is(err, types.ErrVersionConflict) {
+		return nil, err
+	}
+	if err != nil {
 		s.Logger.Error("Failed to update translation", zap.Error(err))
 		return nil, err
 	}
@@ -170,7 +282,9 @@ func (s *TranslationService) Update(request *UpdateTranslationRequest) (*Transla
 	return translation.ToResponse(), nil
 }
 
-func (s *TranslationService) Delete(id uint) error {
+// Delete moves a translation to the trash, recording it in the history on
+// behalf of editorId
+func (s *TranslationService) Delete(id, editorId uint) error {
 	var translation Translation
 	if err := s.DB.First(&translation, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -180,7 +294,13 @@ func (s *TranslationService) Delete(id uint) error {
 		return err
 	}
 
-	if err := s.DB.Delete(&translation).Error; err != nil {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&translation).Error; err != nil {
+			return err
+		}
+		return recordHistory(tx, &translation, HistoryDelete, translation.Value, editorId)
+	})
+	if err != nil {
 		s.Logger.Error("Failed to delete translation", zap.Error(err))
 		return err
 	}
@@ -215,26 +335,181 @@ func (s *TranslationService) GetTranslationsForModel(model string, modelId uint,
 	return result, nil
 }
 
-// BulkUpdate updates multiple translations for a model at once
-func (s *TranslationService) BulkUpdate(request *BulkTranslationRequest) error {
+// BulkUpdate updates multiple translations for a model at once. With
+// DryRun set it computes the same changes and rolls them back.
+func (s *TranslationService) BulkUpdate(request *BulkTranslationRequest) (*BulkUpdateResult, error) {
 	s.Logger.Info("Starting bulk translation update",
 		zap.String("model", request.Model),
 		zap.Uint("model_id", request.ModelId),
 		zap.String("language", request.Language),
-		zap.Int("count", len(request.Translations)))
+		zap.Int("count", len(request.Translations)),
+		zap.Bool("dry_run", request.DryRun))
 
-	err := s.BulkSetTranslations(request.Model, request.ModelId, request.Language, request.Translations)
+	changes, err := s.bulkSetTranslations(request.Model, request.ModelId, request.Language, request.Translations, request.DryRun, request.EditorId)
 	if err != nil {
 		s.Logger.Error("Failed to bulk update translations", zap.Error(err))
-		return err
+		return nil, err
 	}
 
-	s.Logger.Info("Bulk translation update completed successfully")
-	return nil
+	result := &BulkUpdateResult{DryRun: request.DryRun, Changes: []TranslationChange{}}
+	for _, change := range changes {
+		switch change.Action {
+		case ChangeCreate:
+			result.Created++
+		case ChangeUpdate:
+			result.Updated++
+		default:
+			result.Unchanged++
+			continue
+		}
+		result.Changes = append(result.Changes, change)
+	}
+
+	s.Logger.Info("Bulk translation update completed successfully",
+		zap.Int("created", result.Created),
+		zap.Int("updated", result.Updated),
+		zap.Bool("dry_run", request.DryRun))
+	return result, nil
 }
 
 // BulkSetTranslations sets multiple translations for a model instance in a single transaction
 func (s *TranslationService) BulkSetTranslations(modelName string, modelId uint, language string, translations map[string]string) error {
+	_, err := s.bulkSetTranslations(modelName, modelId, language, translations, false, 0)
+	return err
+}
+
+// ErrBulkCreateConflict is returned with the result of a bulk create rolled
+// back because of translations that already exist
+var ErrBulkCreateConflict = errors.New("translations already exist, nothing was created")
+
+// BulkCreate creates the translations of request in one transaction,
+// resolving existing ones by request.OnConflict. Under the error policy every
+// item is still checked, so the result lists all the conflicts, and the batch
+// is rolled back with ErrBulkCreateConflict.
+func (s *TranslationService) BulkCreate(request *BulkCreateTranslationRequest) (*BulkCreateResult, error) {
+	policy := request.OnConflict
+	if policy == "" {
+		policy = ConflictError
+	}
+
+	result := &BulkCreateResult{
+		OnConflict: policy,
+		Results:    make([]BulkCreateItemResult, 0, len(request.Translations)),
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		for i, item := range request.Translations {
+			itemResult := BulkCreateItemResult{
+				Index:    i,
+				Key:      item.Key,
+				Model:    item.Model,
+				ModelId:  item.ModelId,
+				Language: item.Language,
+			}
+
+			var existing Translation
+			err := tx.Where("`key` = ? AND model = ? AND model_id = ? AND language = ?",
+				item.Key, item.Model, item.ModelId, item.Language).First(&existing).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				translation := &Translation{
+					Key:      item.Key,
+					Value:    item.Value,
+					Model:    item.Model,
+					ModelId:  item.ModelId,
+					Language: item.Language,
+				}
+				if err := tx.Create(translation).Error; err != nil {
+					return err
+				}
+				itemResult.Id = translation.Id
+				itemResult.Status = BulkItemCreated
+				result.Created++
+			case policy == ConflictSkip:
+				itemResult.Id = existing.Id
+				itemResult.Status = BulkItemSkipped
+				result.Skipped++
+			case policy == ConflictUpdate && existing.Value == item.Value:
+				itemResult.Id = existing.Id
+				itemResult.Status = BulkItemUnchanged
+				result.Unchanged++
+			case policy == ConflictUpdate:
+				old := existing.Value
+				if err := tx.Model(&existing).Updates(map[string]any{
+					"value":   item.Value,
+					"version": gorm.Expr("version + 1"),
+				}).Error; err != nil {
+					return err
+				}
+				existing.Value = item.Value
+				existing.Version++
+				if err := recordHistory(tx, &existing, HistoryUpdate, old, request.EditorId); err != nil {
+					return err
+				}
+				itemResult.Id = existing.Id
+				itemResult.Status = BulkItemUpdated
+				result.Updated++
+			default:
+				itemResult.Id = existing.Id
+				itemResult.Status = BulkItemConflict
+				result.Conflicts++
+			}
+
+			result.Results = append(result.Results, itemResult)
+		}
+
+		if result.Conflicts > 0 {
+			return ErrBulkCreateConflict
+		}
+		return nil
+	})
+
+	if errors.Is(err, ErrBulkCreateConflict) {
+		// The ids of the rolled back translations, also reported by the items
+		// conflicting with them, do not exist
+		rolledBack := make(map[uint]bool)
+		for _, item := range result.Results {
+			if item.Status == BulkItemCreated {
+				rolledBack[item.Id] = true
+			}
+		}
+		for i := range result.Results {
+			if rolledBack[result.Results[i].Id] {
+				result.Results[i].Id = 0
+			}
+		}
+		s.Logger.Info("Bulk translation create rolled back", zap.Int("conflicts", result.Conflicts))
+		return result, err
+	}
+	if err != nil {
+		s.Logger.Error("Failed to bulk create translations", zap.Error(err))
+		return nil, err
+	}
+
+	result.Committed = true
+	s.Logger.Info("Bulk translation create completed successfully",
+		zap.String("on_conflict", policy),
+		zap.Int("created", result.Created),
+		zap.Int("updated", result.Updated),
+		zap.Int("skipped", result.Skipped))
+	return result, nil
+}
+
+// bulkSetTranslations writes the translations in one transaction and returns
+// what happened to each key, in key order. Changed values are recorded in the
+// history on behalf of editorId. A dry run rolls the transaction back instead
+// of committing it.
+func (s *TranslationService) bulkSetTranslations(modelName string, modelId uint, language string, translations map[string]string, dryRun bool, editorId uint) ([]TranslationChange, error) {
+	keys := make([]string, 0, len(translations))
+	for key := range translations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
 	tx := s.DB.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -242,14 +517,16 @@ func (s *TranslationService) BulkSetTranslations(modelName string, modelId uint,
 		}
 	}()
 
-	for key, value := range translations {
+	changes := make([]TranslationChange, 0, len(keys))
+	for _, key := range keys {
+		value := translations[key]
 		var translation Translation
 		err := tx.Where("model = ? AND model_id = ? AND `key` = ? AND language = ?",
 			modelName, modelId, key, language).First(&translation).Error
 
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 			tx.Rollback()
-			return err
+			return nil, err
 		}
 
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -263,19 +540,36 @@ func (s *TranslationService) BulkSetTranslations(modelName string, modelId uint,
 			}
 			if err := tx.Create(&translation).Error; err != nil {
 				tx.Rollback()
-				return err
+				return nil, err
 			}
+			changes = append(changes, TranslationChange{Key: key, Action: ChangeCreate, NewValue: value})
 		} else {
 			// Update existing translation
+			old := translation.Value
+			change := TranslationChange{Key: key, Action: ChangeUpdate, OldValue: &old, NewValue: value}
+			if old == value {
+				change.Action = ChangeUnchanged
+			}
+
 			translation.Value = value
 			if err := tx.Save(&translation).Error; err != nil {
 				tx.Rollback()
-				return err
+				return nil, err
+			}
+			if change.Action == ChangeUpdate {
+				if err := recordHistory(tx, &translation, HistoryUpdate, old, editorId); err != nil {
+					tx.Rollback()
+					return nil, err
+				}
 			}
+			changes = append(changes, change)
 		}
 	}
 
-	return tx.Commit().Error
+	if dryRun {
+		return changes, tx.Rollback().Error
+	}
+	return changes, tx.Commit().Error
 }
 
 // GetSupportedLanguages returns a list of languages that have translations in the system
@@ -0,0 +1,235 @@
+package translation
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/emitter"
+	"base/core/logger"
+	"base/core/router"
+	"base/core/types"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func newTestService(t testing.TB) *TranslationService {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(&Translation{}, &TranslationHistory{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return NewTranslationService(db, emitter.New(), nil, logger.NewLoggerFromZap(zap.NewNop()))
+}
+
+// searchKeys returns the keys of the translations Search finds, sorted
+func searchKeys(t *testing.T, s *TranslationService, query, language string) []string {
+	t.Helper()
+
+	result, err := s.Search(query, nil, nil, language, "")
+	if err != nil {
+		t.Fatalf("Search(%q): %v", query, err)
+	}
+	var keys []string
+	for _, hit := range result.Data.([]*TranslationSearchResponse) {
+		keys = append(keys, hit.Key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestSearchMatchesWildcardsLiterally(t *testing.T) {
+	s := newTestService(t)
+	for _, tr := range []Translation{
+		{Key: "discount", Value: "50% off", Model: "product", ModelId: 1, Language: "en"},
+		{Key: "discount", Value: "500 points", Model: "product", ModelId: 2, Language: "en"},
+		{Key: "first_name", Value: "First name", Model: "user", ModelId: 1, Language: "en"},
+		{Key: "firstname", Value: "Emri", Model: "user", ModelId: 2, Language: "sq"},
+		{Key: "note", Value: "Wow!", Model: "post", ModelId: 1, Language: "en"},
+	} {
+		if err := s.DB.Create(&tr).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query    string
+		language string
+		want     string
+	}{
+		{"50%", "", "discount"},
+		{"t_n", "", "first_name"},
+		{"FIRST", "", "first_name,firstname"},
+		{"first", "sq", "firstname"},
+		{"wow!", "", "note"},
+		{"%", "", "discount"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(searchKeys(t, s, tt.query, tt.language), ","); got != tt.want {
+			t.Errorf("Search(%q, %q) found %q, want %q", tt.query, tt.language, got, tt.want)
+		}
+	}
+}
+
+func TestSearchQuotesColumnsForTheDialect(t *testing.T) {
+	// The pgx driver connects lazily, so nothing is dialed
+	conn, err := sql.Open("pgx", "host=localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               gormLogger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open dry run database: %v", err)
+	}
+
+	stmt := db.Model(&Translation{}).Where(containsPattern("key", "%x%")).Find(&[]Translation{}).Statement
+	sql := stmt.SQL.String()
+	if !strings.Contains(sql, `LOWER("key") LIKE $1 ESCAPE $2`) {
+		t.Errorf("postgres query is %s", sql)
+	}
+	if strings.Contains(sql, "`") {
+		t.Errorf("postgres query uses backticks: %s", sql)
+	}
+}
+
+// seedTranslations stores n translations spread over 10 models with 100
+// records each, updated at distinct times
+func seedTranslations(t testing.TB, s *TranslationService, n int) {
+	t.Helper()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	batch := make([]Translation, 0, 100)
+	for i := 0; i < n; i++ {
+		batch = append(batch, Translation{
+			Key:       fmt.Sprintf("field_%d", i%7),
+			Value:     fmt.Sprintf("value %d", i),
+			Model:     fmt.Sprintf("model_%d", i%10),
+			ModelId:   uint(i/10%100 + 1),
+			Language:  []string{"en", "sq", "de"}[i%3],
+			UpdatedAt: base.Add(time.Duration(i) * time.Second),
+		})
+		if len(batch) == cap(batch) || i == n-1 {
+			if err := s.DB.Create(&batch).Error; err != nil {
+				t.Fatalf("seed: %v", err)
+			}
+			batch = batch[:0]
+		}
+	}
+}
+
+// modelFilters lists the translations of one record, newest first
+func modelFilters() *router.QueryFilters {
+	return &router.QueryFilters{Filters: map[string]string{"model": "model_3", "model_id": "42"}}
+}
+
+func TestGetAllByModelUsesListIndex(t *testing.T) {
+	s := newTestService(t)
+	seedTranslations(t, s, 2000)
+	if err := s.DB.Exec("ANALYZE").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	query := s.DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		tx, err := modelFilters().Apply(tx.Model(&Translation{}), listQueryFields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx.Order("updated_at DESC").Limit(20).Find(&[]*Translation{})
+	})
+
+	var plan []struct{ Detail string }
+	if err := s.DB.Raw("EXPLAIN QUERY PLAN " + query).Scan(&plan).Error; err != nil {
+		t.Fatalf("explain %s: %v", query, err)
+	}
+	var details []string
+	for _, step := range plan {
+		details = append(details, step.Detail)
+	}
+	joined := strings.Join(details, "; ")
+	if !strings.Contains(joined, "idx_translation_list") {
+		t.Errorf("plan %q does not use idx_translation_list", joined)
+	}
+	// The index also yields the order, so no sort step is needed
+	if strings.Contains(joined, "TEMP B-TREE") {
+		t.Errorf("plan %q sorts the rows instead of reading them in index order", joined)
+	}
+
+	result, err := s.GetAll(modelFilters())
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	items := result.Data.([]*TranslationListResponse)
+	if len(items) != 2 || result.Pagination.Total != 2 {
+		t.Fatalf("got %d of %d translations, want 2 of 2", len(items), result.Pagination.Total)
+	}
+	if !items[0].UpdatedAt.After(items[1].UpdatedAt) {
+		t.Error("translations are not listed newest first")
+	}
+}
+
+// BenchmarkGetAllByModel lists the translations of one record from a large
+// table, with and without idx_translation_list
+func BenchmarkGetAllByModel(b *testing.B) {
+	s := newTestService(b)
+	seedTranslations(b, s, 50000)
+
+	run := func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := s.GetAll(modelFilters()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.Run("with index", run)
+	if err := s.DB.Migrator().DropIndex(&Translation{}, "idx_translation_list"); err != nil {
+		b.Fatal(err)
+	}
+	b.Run("without index", run)
+}
+
+func TestUpdateRequiresCurrentVersion(t *testing.T) {
+	s := newTestService(t)
+	tr := Translation{Key: "title", Value: "Hello", Model: "post", ModelId: 1, Language: "en"}
+	if err := s.DB.Create(&tr).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := s.Update(&UpdateTranslationRequest{Id: tr.Id, Version: 1, Value: "Hi"})
+	if err != nil {
+		t.Fatalf("Update with the current version: %v", err)
+	}
+	if updated.Version != 2 || updated.Value != "Hi" {
+		t.Errorf("got version %d value %q, want version 2 value Hi", updated.Version, updated.Value)
+	}
+
+	// A second editor still holding version 1 must not overwrite the change
+	if _, err := s.Update(&UpdateTranslationRequest{Id: tr.Id, Version: 1, Value: "Hey"}); !errors.Is(err, types.ErrVersionConflict) {
+		t.Fatalf("Update with a stale version = %v, want ErrVersionConflict", err)
+	}
+	var stored Translation
+	if err := s.DB.First(&stored, tr.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.Value != "Hi" || stored.Version != 2 {
+		t.Errorf("stored version %d value %q, want version 2 value Hi", stored.Version, stored.Value)
+	}
+}
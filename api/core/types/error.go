@@ -8,6 +8,14 @@ type ValidationError struct {
 	Message string `json:"message"`
 }
 
+// FieldErrors maps request field names to why they failed validation.
+// Its Error text is the generic "validation failed" kept for older clients.
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	return "validation failed"
+}
+
 // ValidationErrorResponse represents a response containing multiple validation errors
 type ValidationErrorResponse struct {
 	Errors []ValidationError `json:"errors"`
@@ -20,4 +28,11 @@ var (
 	ErrInvalidPassword = errors.New("invalid password")
 	ErrEmailExists     = errors.New("email already exists")
 	ErrInvalidEmail    = errors.New("invalid email")
+	ErrTokenRevoked    = errors.New("token revoked")
+	ErrVersionConflict = errors.New("version conflict: the resource was modified by another request")
+
+	// Request body errors, returned by router.Context.BindJSON with the details
+	ErrUnsupportedContentType = errors.New("unsupported content type: expected application/json")
+	ErrBodyTooLarge           = errors.New("request body too large")
+	ErrMalformedJSON          = errors.New("malformed JSON")
 )
@@ -1,23 +1,47 @@
 package types
 
 import (
-	"base/core/config"
+	"crypto/rand"
+	"encoding/hex"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// GenerateJWT creates a new JWT token for the given user ID
+// TokenRevocationCheck reports whether the token with the given id (jti claim)
+// has been revoked. It is installed by the authentication module, which backs it
+// with the revoked_tokens denylist; tokens without an id are never checked.
+var TokenRevocationCheck func(tokenId string) bool
+
+// TokenRevoker adds the token with the given id to the denylist until
+// expiresAt. It is installed by the authentication module.
+var TokenRevoker func(tokenId string, expiresAt time.Time) error
+
+// ImpersonatedByClaim holds the id of the user acting through an
+// impersonation token
+const ImpersonatedByClaim = "impersonated_by"
+
+// GenerateJWT creates a new JWT token for the given user ID with a random jti
 func GenerateJWT(userID uint, extend any) (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
-	cfg := config.NewConfig()
+	return GenerateJWTWithId(userID, extend, "")
+}
 
-	claims := token.Claims.(jwt.MapClaims)
-	claims["user_id"] = userID
-	claims["exp"] = time.Now().Add(time.Hour * 24).Unix()
-	claims["extend"] = extend
+// GenerateJWTWithId creates a new JWT token carrying tokenId as its jti claim,
+// so the token can later be revoked. A random id is used when tokenId is empty.
+func GenerateJWTWithId(userID uint, extend any, tokenId string) (string, error) {
+	if tokenId == "" {
+		var err error
+		if tokenId, err = NewTokenId(); err != nil {
+			return "", err
+		}
+	}
 
-	tokenString, err := token.SignedString([]byte(cfg.JWTSecret))
+	tokenString, err := signJWT(jwt.MapClaims{
+		"user_id": userID,
+		"jti":     tokenId,
+		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+		"extend":  extend,
+	})
 	if err != nil {
 		return "", err
 	}
@@ -25,22 +49,89 @@ func GenerateJWT(userID uint, extend any) (string, error) {
 	return tokenString, nil
 }
 
+// GenerateImpersonationJWT creates a token that lets impersonatorID act as
+// userID until ttl passes. It carries no extend data and, unlike login
+// tokens, no session, so it cannot be refreshed or outlive ttl.
+func GenerateImpersonationJWT(userID, impersonatorID uint, ttl time.Duration) (tokenString, tokenId string, expiresAt time.Time, err error) {
+	if tokenId, err = NewTokenId(); err != nil {
+		return "", "", time.Time{}, err
+	}
+	expiresAt = time.Now().Add(ttl)
+
+	tokenString, err = signJWT(jwt.MapClaims{
+		"user_id":           userID,
+		"jti":               tokenId,
+		"exp":               expiresAt.Unix(),
+		ImpersonatedByClaim: impersonatorID,
+	})
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	return tokenString, tokenId, expiresAt, nil
+}
+
+// ImpersonatorOf returns the impersonated_by claim of a parsed token, or 0
+// for an ordinary token
+func ImpersonatorOf(claims jwt.MapClaims) uint {
+	if id, ok := claims[ImpersonatedByClaim].(float64); ok && id > 0 {
+		return uint(id)
+	}
+	return 0
+}
+
+// NewTokenId returns a random, unique value for the jti claim
+func NewTokenId() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // ValidateJWT validates a JWT token and returns the user ID
 func ValidateJWT(tokenString string) (uint, error) {
-	cfg := config.NewConfig()
+	claims, err := ParseJWT(tokenString)
+	if err != nil {
+		return 0, err
+	}
+
+	if IsTokenRevoked(claims) {
+		return 0, ErrTokenRevoked
+	}
+	userID := uint(claims["user_id"].(float64))
+	return userID, nil
+}
+
+// ParseJWT verifies the signature and expiry of a JWT token and returns its
+// claims. Only the configured algorithm is accepted, so a token cannot pick
+// another one to be checked with. Revocation is not checked.
+func ParseJWT(tokenString string) (jwt.MapClaims, error) {
+	keys, err := currentJWTKeys()
+	if err != nil {
+		return nil, err
+	}
 
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		return []byte(cfg.JWTSecret), nil
-	})
+		return keys.verifyKey, nil
+	}, jwt.WithValidMethods([]string{keys.method.Alg()}))
 
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID := uint(claims["user_id"].(float64))
-		return userID, nil
+		return claims, nil
 	}
 
-	return 0, jwt.ErrSignatureInvalid
+	return nil, jwt.ErrSignatureInvalid
+}
+
+// IsTokenRevoked runs TokenRevocationCheck against the jti claim of a parsed token.
+// Tokens issued before jti claims were added are never considered revoked.
+func IsTokenRevoked(claims jwt.MapClaims) bool {
+	tokenId, _ := claims["jti"].(string)
+	if tokenId == "" || TokenRevocationCheck == nil {
+		return false
+	}
+	return TokenRevocationCheck(tokenId)
 }
@@ -0,0 +1,174 @@
+package types
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// useJWTConfig makes tokens use cfg for the rest of the test
+func useJWTConfig(t *testing.T, cfg *config.Config) {
+	t.Helper()
+	previous := jwtConfig
+	t.Cleanup(func() {
+		jwtConfig = previous
+		jwtKeyCache.Lock()
+		jwtKeyCache.keys = nil
+		jwtKeyCache.Unlock()
+	})
+	jwtConfig = func() *config.Config { return cfg }
+}
+
+// writeRSAKey writes a new RSA key pair as PEM files and returns their paths
+func writeRSAKey(t *testing.T, dir, name string) (privateKeyFile, publicKeyFile string, key *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyFile = filepath.Join(dir, name+".pem")
+	publicKeyFile = filepath.Join(dir, name+".pub.pem")
+	if err := os.WriteFile(privateKeyFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return privateKeyFile, publicKeyFile, key
+}
+
+// tokenHeader returns the header of a signed token
+func tokenHeader(t *testing.T, tokenString string) map[string]any {
+	t.Helper()
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token.Header
+}
+
+func TestHS256SignsAndVerifies(t *testing.T) {
+	useJWTConfig(t, &config.Config{JWTAlgorithm: config.JWTAlgorithmHS256, JWTSecret: "hs256-secret"})
+
+	token, err := GenerateJWT(7, nil)
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+	if header := tokenHeader(t, token); header["alg"] != "HS256" || header["kid"] != nil {
+		t.Errorf("header = %v, want HS256 without a key id", header)
+	}
+	if userId, err := ValidateJWT(token); err != nil || userId != 7 {
+		t.Errorf("ValidateJWT = %d, %v; want 7", userId, err)
+	}
+
+	// A token signed with another secret is rejected
+	forged, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": 7, "exp": time.Now().Add(time.Hour).Unix()}).SignedString([]byte("other-secret"))
+	if _, err := ValidateJWT(forged); err == nil {
+		t.Error("a token signed with another secret was accepted")
+	}
+
+	// The shared secret is never published
+	if _, err := PublicJWKS(); !errors.Is(err, ErrNoPublicKey) {
+		t.Errorf("PublicJWKS = %v, want ErrNoPublicKey", err)
+	}
+}
+
+func TestRS256SignsAndVerifies(t *testing.T) {
+	privateKeyFile, publicKeyFile, key := writeRSAKey(t, t.TempDir(), "jwt")
+	useJWTConfig(t, &config.Config{JWTAlgorithm: config.JWTAlgorithmRS256, JWTPrivateKeyFile: privateKeyFile, JWTPublicKeyFile: publicKeyFile})
+
+	token, err := GenerateJWT(7, nil)
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+	header := tokenHeader(t, token)
+	if header["alg"] != "RS256" || header["kid"] == nil {
+		t.Errorf("header = %v, want RS256 with a key id", header)
+	}
+	if userId, err := ValidateJWT(token); err != nil || userId != 7 {
+		t.Errorf("ValidateJWT = %d, %v; want 7", userId, err)
+	}
+
+	// Another service verifies the token with the published key alone
+	jwks, err := PublicJWKS()
+	if err != nil {
+		t.Fatalf("PublicJWKS: %v", err)
+	}
+	if len(jwks.Keys) != 1 {
+		t.Fatalf("%d published keys, want 1", len(jwks.Keys))
+	}
+	jwk := jwks.Keys[0]
+	if jwk.Kty != "RSA" || jwk.Alg != "RS256" || jwk.Use != "sig" || jwk.Kid != header["kid"] {
+		t.Errorf("published key %+v does not match the token header %v", jwk, header)
+	}
+	n, _ := base64.RawURLEncoding.DecodeString(jwk.N)
+	e, _ := base64.RawURLEncoding.DecodeString(jwk.E)
+	published := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	if !published.Equal(&key.PublicKey) {
+		t.Fatal("the published key is not the signing key's public key")
+	}
+	if _, err := jwt.Parse(token, func(*jwt.Token) (any, error) { return published, nil }, jwt.WithValidMethods([]string{"RS256"})); err != nil {
+		t.Errorf("verifying with the published key: %v", err)
+	}
+
+	// A token signed with the public key as an HMAC secret is refused
+	publicPEM, _ := os.ReadFile(publicKeyFile)
+	confused, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": 1, "exp": time.Now().Add(time.Hour).Unix()}).SignedString(publicPEM)
+	if _, err := ValidateJWT(confused); err == nil {
+		t.Error("an HS256 token was accepted while RS256 is configured")
+	}
+}
+
+func TestRS256KeyErrors(t *testing.T) {
+	dir := t.TempDir()
+	privateKeyFile, _, _ := writeRSAKey(t, dir, "first")
+	_, otherPublicKeyFile, _ := writeRSAKey(t, dir, "second")
+
+	tests := []struct {
+		name               string
+		privateKey, pubKey string
+		want               string
+	}{
+		{"no private key", "", "", "JWT_PRIVATE_KEY_FILE"},
+		{"missing private key", filepath.Join(dir, "missing.pem"), "", "read JWT private key"},
+		{"not a key", otherPublicKeyFile, "", "parse JWT private key"},
+		{"mismatched public key", privateKeyFile, otherPublicKeyFile, "does not match"},
+	}
+	for _, tt := range tests {
+		if _, err := loadJWTKeys(config.JWTAlgorithmRS256, "", tt.privateKey, tt.pubKey); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want an error about %s", tt.name, err, tt.want)
+		}
+	}
+
+	// Without a public key file it is derived from the private key
+	keys, err := loadJWTKeys(config.JWTAlgorithmRS256, "", privateKeyFile, "")
+	if err != nil || keys.publicKey == nil {
+		t.Errorf("private key only: got %v, %v", keys, err)
+	}
+
+	if _, err := loadJWTKeys("ES256", "", "", ""); err == nil {
+		t.Error("an unsupported algorithm was accepted")
+	}
+	t.Cleanup(func() {
+		jwtKeyCache.Lock()
+		jwtKeyCache.keys = nil
+		jwtKeyCache.Unlock()
+	})
+}
@@ -0,0 +1,190 @@
+package types
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+
+	"base/core/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrNoPublicKey is returned by PublicJWKS when tokens are signed with a
+// shared secret, which must never be published
+var ErrNoPublicKey = errors.New("tokens are not signed with a public key algorithm")
+
+// jwtKeys is the key material of the configured algorithm
+type jwtKeys struct {
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+	publicKey *rsa.PublicKey // RS256 only
+	keyId     string         // RS256 only, the RFC 7638 thumbprint of publicKey
+}
+
+// jwtKeyCache keeps the parsed keys of the last configuration, so key files
+// are read once rather than for every token
+var jwtKeyCache struct {
+	sync.Mutex
+	source string
+	keys   *jwtKeys
+}
+
+// jwtConfig reads the configuration once, rather than for every token signed
+// or verified; the JWT settings do not change while the process runs
+var jwtConfig = sync.OnceValue(config.NewConfig)
+
+// currentJWTKeys returns the keys of the configuration
+func currentJWTKeys() (*jwtKeys, error) {
+	cfg := jwtConfig()
+	return loadJWTKeys(cfg.JWTAlgorithm, cfg.JWTSecret, cfg.JWTPrivateKeyFile, cfg.JWTPublicKeyFile)
+}
+
+// loadJWTKeys builds the keys for algorithm, reusing the cached ones while
+// the configuration is unchanged
+func loadJWTKeys(algorithm, secret, privateKeyFile, publicKeyFile string) (*jwtKeys, error) {
+	source := algorithm + "\x00" + secret + "\x00" + privateKeyFile + "\x00" + publicKeyFile
+
+	jwtKeyCache.Lock()
+	defer jwtKeyCache.Unlock()
+	if jwtKeyCache.keys != nil && jwtKeyCache.source == source {
+		return jwtKeyCache.keys, nil
+	}
+
+	var keys *jwtKeys
+	switch algorithm {
+	case "", config.JWTAlgorithmHS256:
+		keys = &jwtKeys{
+			method:    jwt.SigningMethodHS256,
+			signKey:   []byte(secret),
+			verifyKey: []byte(secret),
+		}
+	case config.JWTAlgorithmRS256:
+		privateKey, publicKey, err := readRSAKeys(privateKeyFile, publicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = &jwtKeys{
+			method:    jwt.SigningMethodRS256,
+			signKey:   privateKey,
+			verifyKey: publicKey,
+			publicKey: publicKey,
+			keyId:     rsaThumbprint(publicKey),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", algorithm)
+	}
+
+	jwtKeyCache.source = source
+	jwtKeyCache.keys = keys
+	return keys, nil
+}
+
+// readRSAKeys reads a PEM private key and its public key. Without a public
+// key file the public key is taken from the private key; with one, both must
+// belong together.
+func readRSAKeys(privateKeyFile, publicKeyFile string) (*rsa.PrivateKey, *rsa.PublicKey, error) {
+	if privateKeyFile == "" {
+		return nil, nil, errors.New("RS256 requires JWT_PRIVATE_KEY_FILE")
+	}
+	data, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read JWT private key: %w", err)
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse JWT private key: %w", err)
+	}
+
+	publicKey := &privateKey.PublicKey
+	if publicKeyFile != "" {
+		data, err := os.ReadFile(publicKeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read JWT public key: %w", err)
+		}
+		if publicKey, err = jwt.ParseRSAPublicKeyFromPEM(data); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse JWT public key: %w", err)
+		}
+		if !publicKey.Equal(&privateKey.PublicKey) {
+			return nil, nil, errors.New("JWT public key does not match the private key")
+		}
+	}
+	return privateKey, publicKey, nil
+}
+
+// signJWT signs claims with the configured algorithm
+func signJWT(claims jwt.MapClaims) (string, error) {
+	keys, err := currentJWTKeys()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(keys.method, claims)
+	if keys.keyId != "" {
+		token.Header["kid"] = keys.keyId
+	}
+	return token.SignedString(keys.signKey)
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS returns the key set that verifies the tokens issued by this
+// service, or ErrNoPublicKey when they are signed with a shared secret
+func PublicJWKS() (*JWKSet, error) {
+	keys, err := currentJWTKeys()
+	if err != nil {
+		return nil, err
+	}
+	if keys.publicKey == nil {
+		return nil, ErrNoPublicKey
+	}
+
+	n, e := rsaComponents(keys.publicKey)
+	return &JWKSet{Keys: []JWK{{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: keys.method.Alg(),
+		Kid: keys.keyId,
+		N:   n,
+		E:   e,
+	}}}, nil
+}
+
+// rsaComponents returns the base64url modulus and exponent of key
+func rsaComponents(key *rsa.PublicKey) (n, e string) {
+	n = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+	e = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	return n, e
+}
+
+// rsaThumbprint is the RFC 7638 JWK thumbprint of key, a stable key id
+func rsaThumbprint(key *rsa.PublicKey) string {
+	n, e := rsaComponents(key)
+	// Members in lexicographic order, as the RFC requires
+	canonical, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{E: e, Kty: "RSA", N: n})
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
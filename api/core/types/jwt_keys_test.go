@@ -0,0 +1,30 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestJWTRoundTripReadsConfigurationOnce(t *testing.T) {
+	t.Setenv("JWT_SECRET", "first-secret")
+
+	token, err := GenerateJWT(42, nil)
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+
+	// The keys are loaded once, so a later change of the environment does
+	// not invalidate tokens issued by this process
+	t.Setenv("JWT_SECRET", "second-secret")
+
+	userId, err := ValidateJWT(token)
+	if err != nil {
+		t.Fatalf("ValidateJWT: %v", err)
+	}
+	if userId != 42 {
+		t.Errorf("user id = %d, want 42", userId)
+	}
+
+	if _, err := ValidateJWT(token + "x"); err == nil {
+		t.Error("tampered token was accepted")
+	}
+}
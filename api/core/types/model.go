@@ -9,3 +9,14 @@ type UserData struct {
 
 	// Add other necessary fields
 }
+
+// EventRoleChanged is emitted with a RoleChange when a user is given another role
+const EventRoleChanged = "user.role_changed"
+
+// RoleChange is the payload of the user.role_changed event
+type RoleChange struct {
+	UserId         uint   `json:"user_id"`
+	RoleId         uint   `json:"role_id"`
+	RoleName       string `json:"role_name"`
+	PreviousRoleId uint   `json:"previous_role_id"`
+}
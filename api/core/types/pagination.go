@@ -0,0 +1,91 @@
+package types
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// Page sizes applied by NormalizePagination. SetPageSizes replaces them from
+// configuration at startup.
+var (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// PaginationLinksEnabled makes AddLinks fill in the links of paginated
+// responses. It is set from configuration at startup.
+var PaginationLinksEnabled = false
+
+// SetPageSizes sets the page size used when a request gives no limit and the
+// largest limit a request may ask for. Non-positive values are ignored.
+func SetPageSizes(defaultSize, maxSize int) {
+	if maxSize > 0 {
+		MaxPageSize = maxSize
+	}
+	if defaultSize > 0 {
+		DefaultPageSize = defaultSize
+	}
+	if DefaultPageSize > MaxPageSize {
+		DefaultPageSize = MaxPageSize
+	}
+}
+
+// NormalizePagination returns the page and page size to query. A missing or
+// non-positive page is the first page, a missing or non-positive limit is
+// DefaultPageSize, and limits above MaxPageSize are capped to it.
+func NormalizePagination(page, limit *int) (int, int) {
+	currentPage := 1
+	if page != nil && *page > 0 {
+		currentPage = *page
+	}
+
+	pageSize := DefaultPageSize
+	if limit != nil && *limit > 0 {
+		pageSize = *limit
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+
+	return currentPage, pageSize
+}
+
+// AddLinks sets the first, prev, next and last page links of the response
+// from the request URL, keeping its other query parameters such as filters.
+// Links are relative to the host. It does nothing unless
+// PaginationLinksEnabled is set.
+func (r *PaginatedResponse) AddLinks(requestURL *url.URL) {
+	if !PaginationLinksEnabled || requestURL == nil {
+		return
+	}
+	r.Pagination.Links = BuildPaginationLinks(requestURL, r.Pagination)
+}
+
+// BuildPaginationLinks returns the links for navigating from the page
+// described by p. Prev is empty on the first page and next on the last.
+func BuildPaginationLinks(requestURL *url.URL, p Pagination) *PaginationLinks {
+	lastPage := p.TotalPages
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	pageURL := func(page int) string {
+		query := requestURL.Query()
+		query.Set("page", strconv.Itoa(page))
+		link := url.URL{Path: requestURL.Path, RawQuery: query.Encode()}
+		return link.String()
+	}
+
+	links := &PaginationLinks{
+		First: pageURL(1),
+		Last:  pageURL(lastPage),
+	}
+	if p.Page > 1 {
+		// A page past the end steps back to the last one
+		links.Prev = pageURL(min(p.Page-1, lastPage))
+	}
+	if p.Page < lastPage {
+		links.Next = pageURL(p.Page + 1)
+	}
+	return links
+}
@@ -0,0 +1,113 @@
+package types
+
+import (
+	"net/url"
+	"testing"
+)
+
+// setPageSizes sets the page sizes for the duration of the test
+func setPageSizes(t *testing.T, defaultSize, maxSize int) {
+	t.Helper()
+	previousDefault, previousMax := DefaultPageSize, MaxPageSize
+	t.Cleanup(func() { DefaultPageSize, MaxPageSize = previousDefault, previousMax })
+	DefaultPageSize, MaxPageSize = defaultSize, maxSize
+}
+
+func TestNormalizePaginationClampsLimit(t *testing.T) {
+	setPageSizes(t, 10, 100)
+	value := func(n int) *int { return &n }
+
+	tests := []struct {
+		name      string
+		page      *int
+		limit     *int
+		wantPage  int
+		wantLimit int
+	}{
+		{"defaults", nil, nil, 1, 10},
+		{"within bounds", value(3), value(25), 3, 25},
+		{"exactly the max", value(1), value(100), 1, 100},
+		{"above the max", value(2), value(1000000), 2, 100},
+		{"zero limit", value(1), value(0), 1, 10},
+		{"negative limit", value(1), value(-5), 1, 10},
+		{"zero page", value(0), value(20), 1, 20},
+		{"negative page", value(-2), nil, 1, 10},
+	}
+	for _, tt := range tests {
+		page, limit := NormalizePagination(tt.page, tt.limit)
+		if page != tt.wantPage || limit != tt.wantLimit {
+			t.Errorf("%s: got page %d limit %d, want page %d limit %d", tt.name, page, limit, tt.wantPage, tt.wantLimit)
+		}
+	}
+}
+
+func TestSetPageSizes(t *testing.T) {
+	tests := []struct {
+		defaultSize, maxSize int
+		wantDefault, wantMax int
+	}{
+		{25, 50, 25, 50},
+		// Non-positive values keep the current sizes
+		{0, -1, 10, 100},
+		// The default never exceeds the max
+		{80, 40, 40, 40},
+		{0, 5, 5, 5},
+	}
+	for _, tt := range tests {
+		setPageSizes(t, 10, 100)
+		SetPageSizes(tt.defaultSize, tt.maxSize)
+		if DefaultPageSize != tt.wantDefault || MaxPageSize != tt.wantMax {
+			t.Errorf("SetPageSizes(%d, %d) = %d, %d; want %d, %d",
+				tt.defaultSize, tt.maxSize, DefaultPageSize, MaxPageSize, tt.wantDefault, tt.wantMax)
+		}
+	}
+}
+
+func TestBuildPaginationLinksAtPageBoundaries(t *testing.T) {
+	requestURL, err := url.Parse("/api/media?tags=logo&tags=dark&limit=10&page=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := func(page string) string {
+		return "/api/media?limit=10&page=" + page + "&tags=logo&tags=dark"
+	}
+
+	tests := []struct {
+		name       string
+		page       int
+		totalPages int
+		want       PaginationLinks
+	}{
+		{"first page", 1, 3, PaginationLinks{First: link("1"), Next: link("2"), Last: link("3")}},
+		{"middle page", 2, 3, PaginationLinks{First: link("1"), Prev: link("1"), Next: link("3"), Last: link("3")}},
+		{"last page", 3, 3, PaginationLinks{First: link("1"), Prev: link("2"), Last: link("3")}},
+		{"only page", 1, 1, PaginationLinks{First: link("1"), Last: link("1")}},
+		{"empty result", 1, 0, PaginationLinks{First: link("1"), Last: link("1")}},
+		{"past the end", 7, 3, PaginationLinks{First: link("1"), Prev: link("3"), Last: link("3")}},
+	}
+	for _, tt := range tests {
+		got := BuildPaginationLinks(requestURL, Pagination{Page: tt.page, TotalPages: tt.totalPages})
+		if *got != tt.want {
+			t.Errorf("%s:\n got %+v\nwant %+v", tt.name, *got, tt.want)
+		}
+	}
+}
+
+func TestAddLinksOnlyWhenEnabled(t *testing.T) {
+	requestURL, _ := url.Parse("/api/translations?model=post")
+	enabled := PaginationLinksEnabled
+	t.Cleanup(func() { PaginationLinksEnabled = enabled })
+
+	PaginationLinksEnabled = false
+	response := &PaginatedResponse{Pagination: Pagination{Page: 1, TotalPages: 2}}
+	response.AddLinks(requestURL)
+	if response.Pagination.Links != nil {
+		t.Errorf("links added while disabled: %+v", response.Pagination.Links)
+	}
+
+	PaginationLinksEnabled = true
+	response.AddLinks(requestURL)
+	if links := response.Pagination.Links; links == nil || links.Next != "/api/translations?model=post&page=2" {
+		t.Errorf("links = %+v, want next keeping the model filter", links)
+	}
+}
@@ -1,10 +1,27 @@
 package types
 
+import "errors"
+
 // ErrorResponse represents a standard error response
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Success bool   `json:"success"`
-	Details any    `json:"details,omitempty"`
+	Error   string            `json:"error"`
+	Success bool              `json:"success"`
+	Details any               `json:"details,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty"` // field -> message, set on validation failures
+}
+
+// NewBindErrorResponse builds the response for a request that failed to bind.
+// Validation failures carry a per-field map and rejected bodies their own
+// reason; other errors use message.
+func NewBindErrorResponse(err error, message string) ErrorResponse {
+	var fields FieldErrors
+	if errors.As(err, &fields) {
+		return ErrorResponse{Error: fields.Error(), Fields: fields}
+	}
+	if errors.Is(err, ErrUnsupportedContentType) || errors.Is(err, ErrBodyTooLarge) || errors.Is(err, ErrMalformedJSON) {
+		return ErrorResponse{Error: err.Error()}
+	}
+	return ErrorResponse{Error: message}
 }
 
 // SuccessResponse represents a standard success response
@@ -16,10 +33,19 @@ type SuccessResponse struct {
 
 // Pagination represents pagination metadata
 type Pagination struct {
-	Total      int `json:"total"`
-	Page       int `json:"page"`
-	PageSize   int `json:"page_size"`
-	TotalPages int `json:"total_pages"`
+	Total      int              `json:"total"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"page_size"`
+	TotalPages int              `json:"total_pages"`
+	Links      *PaginationLinks `json:"links,omitempty"` // Set by PaginatedResponse.AddLinks
+}
+
+// PaginationLinks are the URLs of the pages around the current one
+type PaginationLinks struct {
+	First string `json:"first"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last"`
 }
 
 // PaginatedResponse represents a paginated response
@@ -0,0 +1,116 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// Presence message types sent to the other players of a game
+const (
+	PresenceJoin  = "presence_join"
+	PresenceLeave = "presence_leave"
+)
+
+// PresenceEntry is a user online in a game
+type PresenceEntry struct {
+	UserId   uint      `json:"user_id"`
+	Nickname string    `json:"nickname"`
+	JoinedAt time.Time `json:"joined_at"`
+
+	connections int
+}
+
+// Presence returns the users online in a game, earliest arrival first
+func (h *Hub) Presence(game string) []PresenceEntry {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	entries := make([]PresenceEntry, 0, len(h.presence[game]))
+	for _, entry := range h.presence[game] {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].JoinedAt.Equal(entries[j].JoinedAt) {
+			return entries[i].UserId < entries[j].UserId
+		}
+		return entries[i].JoinedAt.Before(entries[j].JoinedAt)
+	})
+	return entries
+}
+
+// joinGame records an authenticated client of a game. A user is online
+// while at least one connection is open; the other players hear about the
+// first one only. Called with the hub mutex held.
+func (h *Hub) joinGame(client *Client) {
+	if client.Game == "" || client.UserId == 0 {
+		return
+	}
+	if h.games[client.Game] == nil {
+		h.games[client.Game] = make(map[*Client]bool)
+		h.presence[client.Game] = make(map[uint]*PresenceEntry)
+	}
+	h.games[client.Game][client] = true
+	client.present = true
+
+	entry, ok := h.presence[client.Game][client.UserId]
+	if !ok {
+		entry = &PresenceEntry{UserId: client.UserId, Nickname: client.Nickname, JoinedAt: time.Now()}
+		h.presence[client.Game][client.UserId] = entry
+	}
+	entry.connections++
+	if entry.connections == 1 {
+		h.notifyGame(client, PresenceJoin, *entry)
+	}
+}
+
+// leaveGame removes a client from its game's presence, telling the other
+// players when the user's last connection is gone. Called with the hub
+// mutex held; safe to call more than once per client.
+func (h *Hub) leaveGame(client *Client) {
+	if !client.present {
+		return
+	}
+	client.present = false
+	delete(h.games[client.Game], client)
+
+	entry, ok := h.presence[client.Game][client.UserId]
+	if !ok {
+		return
+	}
+	entry.connections--
+	if entry.connections > 0 {
+		return
+	}
+	delete(h.presence[client.Game], client.UserId)
+	h.notifyGame(client, PresenceLeave, *entry)
+
+	if len(h.games[client.Game]) == 0 {
+		delete(h.games, client.Game)
+		delete(h.presence, client.Game)
+	}
+}
+
+// notifyGame sends a presence change to the game's clients of other users.
+// A client whose buffer is full is skipped; it is disconnected by the ping
+// deadline if it stopped reading.
+func (h *Hub) notifyGame(from *Client, messageType string, entry PresenceEntry) {
+	msgBytes, err := json.Marshal(Message{
+		Type:     messageType,
+		Content:  entry,
+		Room:     from.Room,
+		Nickname: "System",
+	})
+	if err != nil {
+		return
+	}
+	for c := range h.games[from.Game] {
+		if c.UserId == from.UserId {
+			continue
+		}
+		select {
+		case c.Send <- msgBytes:
+		default:
+		}
+	}
+}
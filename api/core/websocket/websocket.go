@@ -5,11 +5,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+const (
+	// pongWait is how long a client may stay silent, pongs included, before
+	// it is considered gone
+	pongWait = 60 * time.Second
+	// pingPeriod must be shorter than pongWait
+	pingPeriod = pongWait * 9 / 10
+	writeWait  = 10 * time.Second
+	// maxBatchMessages caps the messages coalesced into one frame
+	maxBatchMessages = 128
+)
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
@@ -18,6 +31,15 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// Options tunes the hub. Zero values disable compression and batching.
+type Options struct {
+	// Compression negotiates permessage-deflate with clients supporting it
+	Compression bool
+	// BatchWindow is how long messages are coalesced into one frame for
+	// clients connecting with batch=true
+	BatchWindow time.Duration
+}
+
 // Client represents a WebSocket client
 type Client struct {
 	ID       string
@@ -25,6 +47,16 @@ type Client struct {
 	Room     string
 	Conn     *websocket.Conn
 	Send     chan []byte
+	// UserId is the authenticated user, 0 for anonymous clients
+	UserId uint
+	// Game is the slug of the game the client plays; authenticated clients
+	// of a game are listed by Hub.Presence
+	Game string
+	// BatchWindow, when set, coalesces the messages sent within it into one
+	// frame holding a JSON array of them, in send order
+	BatchWindow time.Duration
+
+	present bool
 }
 
 // Message represents a message structure
@@ -42,16 +74,22 @@ type Hub struct {
 	register   chan *Client
 	unregister chan *Client
 	mutex      *sync.Mutex
+	games      map[string]map[*Client]bool
+	presence   map[string]map[uint]*PresenceEntry
+	options    Options
 }
 
 // NewHub creates a new Hub instance
-func NewHub() *Hub {
+func NewHub(options Options) *Hub {
 	return &Hub{
 		rooms:      make(map[string]map[*Client]bool),
 		broadcast:  make(chan []byte),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		mutex:      &sync.Mutex{},
+		games:      make(map[string]map[*Client]bool),
+		presence:   make(map[string]map[uint]*PresenceEntry),
+		options:    options,
 	}
 }
 
@@ -81,8 +119,7 @@ func (h *Hub) Run() {
 					select {
 					case c.Send <- usersBytes:
 					default:
-						close(c.Send)
-						delete(h.rooms[client.Room], c)
+						h.dropClient(client.Room, c)
 					}
 				}
 			}
@@ -99,14 +136,15 @@ func (h *Hub) Run() {
 				select {
 				case c.Send <- msgBytes:
 				default:
-					close(c.Send)
-					delete(h.rooms[client.Room], c)
+					h.dropClient(client.Room, c)
 				}
 			}
+			h.joinGame(client)
 			h.mutex.Unlock()
 
 		case client := <-h.unregister:
 			h.mutex.Lock()
+			h.leaveGame(client)
 			if _, ok := h.rooms[client.Room]; ok {
 				if _, ok := h.rooms[client.Room][client]; ok {
 					delete(h.rooms[client.Room], client)
@@ -124,8 +162,7 @@ func (h *Hub) Run() {
 						select {
 						case c.Send <- msgBytes:
 						default:
-							close(c.Send)
-							delete(h.rooms[client.Room], c)
+							h.dropClient(client.Room, c)
 						}
 					}
 
@@ -144,8 +181,7 @@ func (h *Hub) Run() {
 							select {
 							case c.Send <- usersBytes:
 							default:
-								close(c.Send)
-								delete(h.rooms[client.Room], c)
+								h.dropClient(client.Room, c)
 							}
 						}
 					}
@@ -166,8 +202,7 @@ func (h *Hub) Run() {
 						select {
 						case client.Send <- message:
 						default:
-							close(client.Send)
-							delete(h.rooms[msg.Room], client)
+							h.dropClient(msg.Room, client)
 						}
 					}
 				}
@@ -177,12 +212,27 @@ func (h *Hub) Run() {
 	}
 }
 
+// dropClient disconnects a client that cannot keep up. Called with the hub
+// mutex held.
+func (h *Hub) dropClient(room string, c *Client) {
+	h.leaveGame(c)
+	close(c.Send)
+	delete(h.rooms[room], c)
+}
+
 func (c *Client) readPump(hub *Hub) {
 	defer func() {
 		hub.unregister <- c
 		c.Conn.Close()
 	}()
 
+	// A connection dropped without a close frame stops answering pings and
+	// fails the next read once the deadline passes
+	c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.Conn.SetPongHandler(func(string) error {
+		return c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
 	for {
 		_, message, err := c.Conn.ReadMessage()
 		if err != nil {
@@ -209,16 +259,17 @@ func (c *Client) readPump(hub *Hub) {
 			if msg.Type == "cursor_update" || msg.Type == "cursor_move" ||
 				msg.Type == "draw" || msg.Type == "code_update" ||
 				msg.Type == "clear" {
+				hub.mutex.Lock()
 				if room, ok := hub.rooms[c.Room]; ok {
 					for client := range room {
 						select {
 						case client.Send <- msgBytes:
 						default:
-							close(client.Send)
-							delete(hub.rooms[c.Room], client)
+							hub.dropClient(c.Room, client)
 						}
 					}
 				}
+				hub.mutex.Unlock()
 			} else {
 				// For other messages, use the general broadcast channel
 				hub.broadcast <- msgBytes
@@ -228,28 +279,104 @@ func (c *Client) readPump(hub *Hub) {
 }
 
 func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
 	defer func() {
+		ticker.Stop()
 		c.Conn.Close()
 	}()
 
-	for message := range c.Send {
-		w, err := c.Conn.NextWriter(websocket.TextMessage)
-		if err != nil {
-			return
+	for {
+		select {
+		case message, ok := <-c.Send:
+			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+
+			if c.BatchWindow <= 0 {
+				if err := c.write(message); err != nil {
+					return
+				}
+				continue
+			}
+
+			batch, open := c.collect(message)
+			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.writeBatch(batch); err != nil {
+				return
+			}
+			if !open {
+				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+
+		case <-ticker.C:
+			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
 		}
-		if _, err := w.Write(message); err != nil {
-			return
+	}
+}
+
+// collect gathers the messages queued within the batch window after first,
+// up to maxBatchMessages. It reports false when Send was closed meanwhile.
+func (c *Client) collect(first []byte) ([][]byte, bool) {
+	batch := [][]byte{first}
+	timer := time.NewTimer(c.BatchWindow)
+	defer timer.Stop()
+
+	for len(batch) < maxBatchMessages {
+		select {
+		case message, ok := <-c.Send:
+			if !ok {
+				return batch, false
+			}
+			batch = append(batch, message)
+		case <-timer.C:
+			return batch, true
 		}
+	}
+	return batch, true
+}
 
-		if err := w.Close(); err != nil {
-			return
+// write sends message as one text frame
+func (c *Client) write(message []byte) error {
+	w, err := c.Conn.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// writeBatch sends the messages as one text frame holding their JSON array
+func (c *Client) writeBatch(batch [][]byte) error {
+	w, err := c.Conn.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return err
+	}
+	w.Write([]byte{'['})
+	for i, message := range batch {
+		if i > 0 {
+			w.Write([]byte{','})
+		}
+		if _, err := w.Write(message); err != nil {
+			return err
 		}
 	}
+	w.Write([]byte{']'})
+	return w.Close()
 }
 
 // ServeWs handles WebSocket requests from the peer
 func ServeWs(hub *Hub, c *router.Context) {
 	fmt.Println("Received WebSocket connection request")
+	upgrader := upgrader
+	upgrader.EnableCompression = hub.options.Compression
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		fmt.Printf("Failed to upgrade connection to WebSocket: %v\n", err)
@@ -263,6 +390,11 @@ func ServeWs(hub *Hub, c *router.Context) {
 		Room:     c.Query("room"),
 		Conn:     conn,
 		Send:     make(chan []byte, 256),
+		UserId:   c.GetUint("user_id"),
+		Game:     c.Query("game"),
+	}
+	if batch, _ := strconv.ParseBool(c.Query("batch")); batch {
+		client.BatchWindow = hub.options.BatchWindow
 	}
 
 	hub.register <- client
@@ -284,8 +416,8 @@ func (h *Hub) BroadcastMessage(messageType string, content any) {
 }
 
 // InitWebSocketModule initializes the WebSocket module
-func InitWebSocketModule(router *router.RouterGroup) *Hub {
-	hub := NewHub()
+func InitWebSocketModule(router *router.RouterGroup, options Options) *Hub {
+	hub := NewHub(options)
 	go hub.Run()
 	SetupWebSocketRoutes(router, hub)
 	return hub
@@ -299,6 +431,7 @@ func SetupWebSocketRoutes(router *router.RouterGroup, hub *Hub) {
 // WebSocketHandler returns a router.HandlerFunc for handling WebSocket connections
 // @Summary Connect to WebSocket
 // @Description Establishes a WebSocket connection, check example at: /static/chat.html
+// @Description permessage-deflate is negotiated when WS_COMPRESSION is enabled.
 // @Security ApiKeyAuth
 // @Security BearerAuth
 // @Tags Core/Websocket
@@ -307,6 +440,8 @@ func SetupWebSocketRoutes(router *router.RouterGroup, hub *Hub) {
 // @Param id query string false "Client ID"
 // @Param nickname query string false "User Nickname"
 // @Param room query string false "Chat Room"
+// @Param game query string false "Game slug; authenticated clients appear in the game's presence list"
+// @Param batch query bool false "Coalesce the messages of each WS_BATCH_WINDOW into one frame holding a JSON array of them, in send order"
 // @Success 101 {string} string "Switching Protocols"
 // @Failure 400 {object} ErrorResponse
 // @Router /ws [get]
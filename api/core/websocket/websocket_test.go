@@ -0,0 +1,130 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/router"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestServer serves the websocket route of a running hub
+func newTestServer(t *testing.T, options Options) (*Hub, string) {
+	t.Helper()
+	hub := NewHub(options)
+	go hub.Run()
+	r := router.New()
+	SetupWebSocketRoutes(r.Group("/api"), hub)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return hub, "ws" + strings.TrimPrefix(server.URL, "http") + "/api/ws"
+}
+
+// readFrame returns the messages of the next frame, a single message or a
+// batch of them
+func readFrame(t *testing.T, conn *websocket.Conn) []Message {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var messages []Message
+	if strings.HasPrefix(string(data), "[") {
+		err = json.Unmarshal(data, &messages)
+	} else {
+		var message Message
+		err = json.Unmarshal(data, &message)
+		messages = append(messages, message)
+	}
+	if err != nil {
+		t.Fatalf("frame %s: %v", data, err)
+	}
+	return messages
+}
+
+// connect dials the hub and waits until its join message arrived
+func connect(t *testing.T, url string, dialer *websocket.Dialer) (*websocket.Conn, *http.Response) {
+	t.Helper()
+	conn, resp, err := dialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	for joined := false; !joined; {
+		for _, message := range readFrame(t, conn) {
+			joined = joined || message.Type == "system"
+		}
+	}
+	return conn, resp
+}
+
+// broadcastAndRead sends count numbered messages at once and returns how many
+// frames delivered them and their numbers in arrival order
+func broadcastAndRead(t *testing.T, hub *Hub, conn *websocket.Conn, count int) (int, []int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		hub.BroadcastMessage("score", i)
+	}
+	frames, received := 0, []int{}
+	for len(received) < count {
+		frames++
+		for _, message := range readFrame(t, conn) {
+			received = append(received, int(message.Content.(float64)))
+		}
+	}
+	return frames, received
+}
+
+func TestBatchingCoalescesRapidMessages(t *testing.T) {
+	hub, url := newTestServer(t, Options{BatchWindow: 50 * time.Millisecond})
+	const count = 20
+
+	batched, _ := connect(t, url+"?batch=true&nickname=batched", websocket.DefaultDialer)
+	frames, received := broadcastAndRead(t, hub, batched, count)
+	if frames >= count/2 {
+		t.Errorf("%d messages took %d frames, want them coalesced", count, frames)
+	}
+	for i, n := range received {
+		if n != i {
+			t.Fatalf("received %v, want the send order", received)
+		}
+	}
+}
+
+func TestClientsWithoutBatchGetOneFramePerMessage(t *testing.T) {
+	hub, url := newTestServer(t, Options{BatchWindow: 50 * time.Millisecond})
+	const count = 5
+
+	plain, _ := connect(t, url+"?nickname=plain", websocket.DefaultDialer)
+	frames, received := broadcastAndRead(t, hub, plain, count)
+	if frames != count {
+		t.Errorf("%d messages took %d frames, want one each", count, frames)
+	}
+	for i, n := range received {
+		if n != i {
+			t.Fatalf("received %v, want the send order", received)
+		}
+	}
+}
+
+func TestCompressionIsNegotiated(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		hub, url := newTestServer(t, Options{Compression: enabled})
+		conn, resp := connect(t, url, &websocket.Dialer{EnableCompression: true})
+		negotiated := strings.Contains(resp.Header.Get("Sec-Websocket-Extensions"), "permessage-deflate")
+		if negotiated != enabled {
+			t.Errorf("compression %v: negotiated %v", enabled, negotiated)
+		}
+
+		// Messages still arrive either way
+		if _, received := broadcastAndRead(t, hub, conn, 3); len(received) != 3 {
+			t.Errorf("compression %v: received %v", enabled, received)
+		}
+	}
+}
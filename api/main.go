@@ -4,6 +4,9 @@ import (
 	appmodules "base/app"
 	"base/app/models"
 	coremodules "base/core/app"
+	"base/core/app/authentication"
+	"base/core/app/profile"
+	"base/core/app/webhook"
 	"base/core/config"
 	"base/core/database"
 	"base/core/email"
@@ -13,12 +16,17 @@ import (
 	"base/core/router"
 	"base/core/router/middleware"
 	"base/core/storage"
-	_ "base/core/translation"
+	"base/core/translation"
+	"base/core/types"
 	"base/core/websocket"
+	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/joho/godotenv" // swagger embed files
@@ -64,8 +72,15 @@ type App struct {
 	emailSender email.Sender
 	wsHub       *websocket.Hub
 
+	tokenCleaner      *authentication.TokenCleaner
+	webhookDispatcher *webhook.Dispatcher
+
 	// State
 	running bool
+	// stopped is closed once Stop has drained the server
+	stopped chan struct{}
+	// migrationSource is recorded with each migration run; empty means startup
+	migrationSource string
 }
 
 // New creates a new Base application instance
@@ -79,10 +94,12 @@ func (app *App) Start() error {
 		loadEnvironment().
 		initConfig().
 		initLogger().
+		validateConfig().
 		initDatabase().
 		initInfrastructure().
 		initRouter().
 		autoDiscoverModules().
+		bootstrapAdmin().
 		setupRoutes().
 		displayServerInfo().
 		run()
@@ -99,6 +116,8 @@ func (app *App) loadEnvironment() *App {
 // initConfig initializes configuration
 func (app *App) initConfig() *App {
 	app.config = config.NewConfig()
+	types.SetPageSizes(app.config.DefaultPageSize, app.config.MaxPageSize)
+	types.PaginationLinksEnabled = app.config.PaginationLinks
 	return app
 }
 
@@ -123,6 +142,41 @@ func (app *App) initLogger() *App {
 	return app
 }
 
+// validateConfig reports configuration problems through the logger. Production
+// refuses to start while any remain; other environments only warn.
+func (app *App) validateConfig() *App {
+	if err := checkConfig(app.config, app.logger); err != nil {
+		app.logger.Fatal("❌ Refusing to start in production with invalid configuration; fix the errors above and restart",
+			logger.String("error", err.Error()))
+	}
+	return app
+}
+
+// checkConfig logs every validation error of cfg and returns an error when
+// the app must not start with them, which is only in production
+func checkConfig(cfg *config.Config, log logger.Logger) error {
+	errs := cfg.Validate()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	for _, err := range errs {
+		if cfg.IsProduction() {
+			log.Error("Invalid configuration", logger.String("error", err.Error()))
+		} else {
+			log.Warn("Invalid configuration", logger.String("error", err.Error()))
+		}
+	}
+
+	if cfg.IsProduction() {
+		return fmt.Errorf("%d configuration errors in production", len(errs))
+	}
+
+	log.Warn("⚠️ Continuing with invalid configuration outside production",
+		logger.String("environment", cfg.Env))
+	return nil
+}
+
 // initDatabase initializes the database connection
 func (app *App) initDatabase() *App {
 	db, err := database.InitDB(app.config)
@@ -133,6 +187,22 @@ func (app *App) initDatabase() *App {
 
 	app.db = db
 	app.logger.Info("✅ Database initialized")
+	app.logger.Info("Database connection pool",
+		logger.String("driver", app.config.DBDriver),
+		logger.Int("max_open_conns", app.config.DBMaxOpenConns),
+		logger.Int("max_idle_conns", app.config.DBMaxIdleConns),
+		logger.Duration("conn_max_lifetime", app.config.DBConnMaxLifetime))
+
+	if err := database.EnableSlowQueryLog(db.DB, app.config.SlowQueryThreshold, app.logger); err != nil {
+		app.logger.Warn("Failed to enable slow query logging", logger.String("error", err.Error()))
+	} else if app.config.SlowQueryThreshold > 0 {
+		app.logger.Info("✅ Slow query logging enabled",
+			logger.Duration("threshold", app.config.SlowQueryThreshold))
+	}
+
+	if err := module.EnsureMigrationTable(db.DB); err != nil {
+		app.logger.Warn("Failed to create migration_runs table", logger.String("error", err.Error()))
+	}
 
 	// Run game models migrations
 	app.migrateGameModels()
@@ -143,18 +213,28 @@ func (app *App) initDatabase() *App {
 // initInfrastructure initializes core infrastructure components
 func (app *App) initInfrastructure() *App {
 	// Initialize emitter
-	app.emitter = &emitter.Emitter{}
+	app.emitter = emitter.New()
+	app.emitter.SetLogger(app.logger)
+	if app.config.EventOutboxEnabled {
+		if err := app.emitter.EnableOutbox(app.db.DB, app.logger, emitter.OutboxConfig{}); err != nil {
+			app.logger.Warn("Failed to enable event outbox; persisted events are emitted directly",
+				logger.String("error", err.Error()))
+		}
+	}
 
 	// Initialize storage
 	storageConfig := storage.Config{
-		Provider:  app.config.StorageProvider,
-		Path:      app.config.StoragePath,
-		BaseURL:   app.config.StorageBaseURL,
-		APIKey:    app.config.StorageAPIKey,
-		APISecret: app.config.StorageAPISecret,
-		Endpoint:  app.config.StorageEndpoint,
-		Bucket:    app.config.StorageBucket,
-		CDN:       app.config.CDN,
+		Provider:        app.config.StorageProvider,
+		Path:            app.config.StoragePath,
+		BaseURL:         app.config.StorageBaseURL,
+		APIKey:          app.config.StorageAPIKey,
+		APISecret:       app.config.StorageAPISecret,
+		AccountID:       app.config.StorageAccountID,
+		Endpoint:        app.config.StorageEndpoint,
+		Bucket:          app.config.StorageBucket,
+		CDN:             app.config.CDN,
+		Region:          app.config.StorageRegion,
+		CredentialsFile: app.config.StorageCredentialsFile,
 	}
 
 	activeStorage, err := storage.NewActiveStorage(app.db.DB, storageConfig)
@@ -165,7 +245,7 @@ func (app *App) initInfrastructure() *App {
 	app.storage = activeStorage
 
 	// Initialize email sender (non-fatal)
-	emailSender, err := email.NewSender(app.config)
+	emailSender, err := email.NewSenderFromConfig(app.config, app.logger)
 	if err != nil {
 		app.logger.Warn("Email sender initialization failed - continuing without email functionality",
 			logger.String("error", err.Error()))
@@ -181,6 +261,18 @@ func (app *App) initInfrastructure() *App {
 // initRouter initializes the router with middleware
 func (app *App) initRouter() *App {
 	app.router = router.New()
+	if err := app.router.SetTrustedProxies(app.config.TrustedProxies); err != nil {
+		app.logger.Error("Invalid trusted proxies", logger.String("error", err.Error()))
+	}
+	app.router.SetJSONBinding(app.config.JSONMaxBodySize, app.config.JSONStrict)
+	app.router.SetJSONLocalizer(translation.Localize)
+	app.router.SetServerLimits(router.ServerLimits{
+		ReadHeaderTimeout: app.config.ReadHeaderTimeout,
+		ReadTimeout:       app.config.ReadTimeout,
+		WriteTimeout:      app.config.WriteTimeout,
+		IdleTimeout:       app.config.IdleTimeout,
+		MaxHeaderBytes:    app.config.MaxHeaderBytes,
+	})
 	app.setupMiddleware()
 	app.setupStaticRoutes()
 	app.initWebSocket()
@@ -189,55 +281,105 @@ func (app *App) initRouter() *App {
 	return app
 }
 
+// emitPanic emits server.panic for each recovered panic, so listeners can
+// alert on it. The stack trace stays in the logs.
+func (app *App) emitPanic(c *router.Context, info middleware.PanicInfo) {
+	app.emitter.EmitAsync("server.panic", map[string]any{
+		"request_id": info.RequestId,
+		"method":     c.Request.Method,
+		"path":       c.Request.URL.Path,
+		"panic":      logger.RedactText(fmt.Sprint(info.Value)),
+	})
+}
+
 // setupMiddleware configures all middleware using the new configurable system
 func (app *App) setupMiddleware() {
-	// Apply configurable middleware system
-	middleware.ApplyConfigurableMiddleware(app.router, &app.config.Middleware)
+	// Apply configurable middleware system in the configured order
+	err := middleware.ApplyConfigurableMiddleware(app.router, &app.config.Middleware, map[string]router.MiddlewareFunc{
+		config.MiddlewareRecovery: middleware.RecoveryWithConfig(middleware.RecoveryConfig{
+			Logger:        app.logger,
+			OnPanic:       app.emitPanic,
+			ExposeDetails: app.config.IsDevelopment(),
+		}),
+		config.MiddlewareLogging:  app.requestLogging(),
+		config.MiddlewareCORS: middleware.CORS(middleware.CORSConfig{
+			AllowedOrigins: app.config.CORSAllowedOrigins,
+			GroupOrigins:   app.config.CORSGroupOrigins,
+			MaxAge:         app.config.CORSMaxAge,
+		}),
+	})
+	if err != nil {
+		app.logger.Fatal("Invalid middleware order", logger.String("error", err.Error()))
+	}
 
-	// Custom request logging middleware (conditional based on config)
-	app.router.Use(func(next router.HandlerFunc) router.HandlerFunc {
-		return func(c *router.Context) error {
-			path := c.Request.URL.Path
+	// Handlers read the authenticated user with profile.CurrentUser; it is
+	// only queried when asked for
+	app.router.Use(profile.LoadCurrentUser(app.db.DB))
 
-			// Check if logging is required for this path
-			if app.config.Middleware.IsLoggingRequired(path) {
-				start := time.Now()
-				err := next(c)
-
-				app.logger.Info("Request",
-					logger.String("method", c.Request.Method),
-					logger.String("path", path),
-					logger.Int("status", c.Writer.Status()),
-					logger.Duration("duration", time.Since(start)),
-					logger.String("ip", c.ClientIP()),
-				)
-				return err
-			}
-
-			// Skip logging for this path
-			return next(c)
-		}
-	})
+	// Translated fields are answered in the language of ?lang= or
+	// Accept-Language, with their original as fallback
+	app.router.Use(middleware.Language())
 
-	// CORS middleware (conditional based on config)
 	if app.config.Middleware.CORSEnabled {
-		corsOrigins := strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",")
-		app.router.Use(middleware.CORSMiddleware(corsOrigins))
-
 		// Add a catch-all OPTIONS handler for preflight requests
-		// This ensures OPTIONS requests don't 404 even if no explicit OPTIONS route exists
+		// This ensures OPTIONS requests don't 404 even if no explicit OPTIONS route exists.
+		// The CORS middleware answers them with the policy of the requested path.
 		app.router.OPTIONS("/*catchall", func(c *router.Context) error {
-			// CORS headers are already set by the middleware above
+			// CORS headers are already set by the middleware
 			return c.NoContent()
 		})
 	}
 }
 
-// setupStaticRoutes configures static file serving
+// requestLogging logs every request on a path that IsLoggingRequired, and
+// every request whose handler returned an error with that error
+func (app *App) requestLogging() router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			path := c.Request.URL.Path
+			requestId := middleware.EnsureRequestId(c)
+
+			start := time.Now()
+			err := next(c)
+
+			// Check if logging is required for this path
+			if err == nil && !app.config.Middleware.IsLoggingRequired(path) {
+				return nil
+			}
+
+			fields := []logger.Field{
+				logger.String("request_id", requestId),
+				logger.String("method", c.Request.Method),
+				logger.String("path", path),
+				logger.Int("status", middleware.ResponseStatus(c, err)),
+				logger.Duration("duration", time.Since(start)),
+				logger.String("ip", c.ClientIP()),
+			}
+			// Tag requests made through an impersonation token with the real actor
+			if by := c.GetUint("impersonated_by"); by != 0 {
+				fields = append(fields, logger.Uint("impersonated_by", by))
+			}
+			if err != nil {
+				app.logger.Error("Request failed", append(fields, middleware.ErrorField(err))...)
+				return err
+			}
+			app.logger.Info("Request", fields...)
+			return nil
+		}
+	}
+}
+
+// setupStaticRoutes serves the STATIC_MOUNTS directories. A missing
+// directory is still mounted, since it may be created later, but logged.
 func (app *App) setupStaticRoutes() {
-	app.router.Static("/static", "./static")
-	app.router.Static("/storage", "./storage")
-	app.router.Static("/docs", "./docs")
+	for _, mount := range app.config.StaticMounts {
+		if info, err := os.Stat(mount.Dir); err != nil || !info.IsDir() {
+			app.logger.Warn("Static mount directory not found",
+				logger.String("prefix", mount.Prefix),
+				logger.String("dir", mount.Dir))
+		}
+		app.router.Static(mount.Prefix, mount.Dir)
+	}
 }
 
 // initWebSocket initializes the WebSocket hub if enabled
@@ -247,7 +389,10 @@ func (app *App) initWebSocket() {
 		return
 	}
 
-	app.wsHub = websocket.InitWebSocketModule(app.router.Group("/api"))
+	app.wsHub = websocket.InitWebSocketModule(app.router.Group("/api"), websocket.Options{
+		Compression: app.config.WSCompression,
+		BatchWindow: app.config.WSBatchWindow,
+	})
 	app.logger.Info("✅ WebSocket hub initialized")
 }
 
@@ -271,6 +416,7 @@ func (app *App) registerCoreModules() {
 		Storage:     app.storage,
 		EmailSender: app.emailSender,
 		Config:      app.config,
+		WSHub:       app.wsHub,
 	}
 
 	// Initialize core modules via orchestrator to ensure proper init/migrate/routes
@@ -297,6 +443,7 @@ func (app *App) discoverAndRegisterAppModules() {
 		Storage:     app.storage,
 		EmailSender: app.emailSender,
 		Config:      app.config,
+		WSHub:       app.wsHub,
 	}
 
 	// Use app module provider (like core modules)
@@ -340,6 +487,20 @@ func (app *App) setupRoutes() *App {
 		})
 	})
 
+	// Public key for verifying RS256 tokens; HS256 secrets are never published
+	app.router.GET("/.well-known/jwks.json", func(c *router.Context) error {
+		jwks, err := types.PublicJWKS()
+		if errors.Is(err, types.ErrNoPublicKey) {
+			return c.JSON(404, types.ErrorResponse{Error: err.Error()})
+		}
+		if err != nil {
+			app.logger.Error("Failed to load JWT public key", logger.String("error", err.Error()))
+			return c.JSON(500, types.ErrorResponse{Error: "Failed to load public key"})
+		}
+		c.SetHeader("Cache-Control", "public, max-age=3600")
+		return c.JSON(200, jwks)
+	})
+
 	// Swagger documentation - serve swag-generated docs
 	app.router.GET("/swagger/*any", func(c *router.Context) error {
 		// Redirect to docs index.html for swagger UI
@@ -353,13 +514,17 @@ func (app *App) setupRoutes() *App {
 func (app *App) displayServerInfo() *App {
 	localIP := app.getLocalIP()
 	port := app.config.ServerPort
+	scheme := "http"
+	if app.config.TLSEnabled() {
+		scheme = "https"
+	}
 
 	fmt.Printf("\n🎉 Base Framework Ready!\n\n")
 	fmt.Printf("📍 Server URLs:\n")
-	fmt.Printf("   • Local:   http://localhost%s\n", port)
-	fmt.Printf("   • Network: http://%s%s\n", localIP, port)
+	fmt.Printf("   • Local:   %s://localhost%s\n", scheme, port)
+	fmt.Printf("   • Network: %s://%s%s\n", scheme, localIP, port)
 	fmt.Printf("\n📚 Documentation:\n")
-	fmt.Printf("   • Swagger: http://localhost%s/docs/index.html\n", port)
+	fmt.Printf("   • Swagger: %s://localhost%s/docs/index.html\n", scheme, port)
 	fmt.Printf("\n")
 
 	return app
@@ -382,6 +547,23 @@ func (app *App) getLocalIP() string {
 	return "localhost"
 }
 
+// bootstrapAdmin creates the Owner from BOOTSTRAP_ADMIN_EMAIL and
+// BOOTSTRAP_ADMIN_PASSWORD when the database has no users yet
+func (app *App) bootstrapAdmin() *App {
+	if app.config.BootstrapAdminEmail == "" {
+		return app
+	}
+
+	created, err := authentication.NewAuthService(app.db.DB, nil, nil).
+		BootstrapAdmin(app.config.BootstrapAdminEmail, app.config.BootstrapAdminPassword)
+	if err != nil {
+		app.logger.Error("Failed to bootstrap the admin", logger.String("error", err.Error()))
+	} else if created {
+		app.logger.Info("✅ Bootstrap admin created", logger.String("email", app.config.BootstrapAdminEmail))
+	}
+	return app
+}
+
 // run starts the HTTP server
 func (app *App) run() error {
 	app.running = true
@@ -390,7 +572,39 @@ func (app *App) run() error {
 	app.logger.Info("🌐 Server starting",
 		logger.String("port", port))
 
-	err := app.router.Run(port)
+	// Deliver events stored in the outbox, including those left by a previous run
+	app.emitter.StartDispatcher()
+
+	// Remove expired reset tokens, sessions and revoked tokens periodically
+	app.tokenCleaner = authentication.NewTokenCleaner(app.db.DB, app.logger, app.config.TokenCleanupInterval)
+	app.tokenCleaner.Start()
+
+	// Send outbound webhook deliveries, including those queued by a previous run
+	app.webhookDispatcher = webhook.NewDispatcher(app.db.DB, app.logger, webhook.DispatcherConfig{
+		Timeout:     app.config.WebhookTimeout,
+		MaxAttempts: app.config.WebhookMaxAttempts,
+	})
+	app.webhookDispatcher.Start()
+
+	// Drain the server and stop the workers on SIGINT or SIGTERM
+	app.stopped = make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		if _, ok := <-signals; ok {
+			app.Stop()
+		}
+	}()
+
+	// Serve HTTPS, negotiating HTTP/2, when a certificate is configured
+	var err error
+	if app.config.TLSEnabled() {
+		app.logger.Info("🔒 TLS enabled", logger.String("cert", app.config.TLSCert))
+		err = app.router.RunTLS(port, app.config.TLSCert, app.config.TLSKey)
+	} else {
+		err = app.router.Run(port)
+	}
 	if err != nil {
 		// Check if it's an "address already in use" error
 		if strings.Contains(err.Error(), "bind: address already in use") {
@@ -404,34 +618,59 @@ func (app *App) run() error {
 			logger.String("error", err.Error()))
 		return fmt.Errorf("server failed to start: %w", err)
 	}
+
+	// Shutdown stopped the server; wait until the requests in flight are done
+	<-app.stopped
 	return nil
 }
 
 // migrateGameModels runs migrations for game-related models
 func (app *App) migrateGameModels() {
-	if err := models.AutoMigrate(app.db.DB); err != nil {
+	source := app.migrationSource
+	if source == "" {
+		source = module.MigrationSourceStartup
+	}
+	err := module.RunMigration(app.db.DB, "games.models", models.Models(), source, func() error {
+		return models.AutoMigrate(app.db.DB)
+	})
+	if err != nil {
 		app.logger.Error("Failed to migrate game models", logger.String("error", err.Error()))
 	}
 }
 
-// seedGameData seeds initial game data
-func (app *App) seedGameData() error {
-	return appmodules.SeedGamesData(app.db.DB)
-}
+// shutdownTimeout bounds how long Stop waits for the requests in flight
+const shutdownTimeout = 30 * time.Second
 
-// Graceful shutdown (future enhancement)
+// Stop gracefully shuts the server down, waiting up to shutdownTimeout for the
+// requests in flight, then stops the background workers
 func (app *App) Stop() error {
 	if !app.running {
 		return nil
 	}
 
 	app.logger.Info("🛑 Shutting down gracefully...")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := app.router.Shutdown(ctx); err != nil {
+		app.logger.Warn("Server did not shut down cleanly", logger.String("error", err.Error()))
+	}
+
+	app.emitter.StopDispatcher()
+	if app.tokenCleaner != nil {
+		app.tokenCleaner.Stop()
+	}
+	if app.webhookDispatcher != nil {
+		app.webhookDispatcher.Stop()
+	}
 	app.running = false
+	if app.stopped != nil {
+		close(app.stopped)
+	}
 	return nil
 }
 
 func main() {
-	// Check for seed command
+	// Check for seed command: seed [games|authz|admin|all] [--reconcile [--prune|--deprecate]]
 	if len(os.Args) > 1 && os.Args[1] == "seed" {
 		// Load environment
 		if err := godotenv.Load(); err != nil {
@@ -444,8 +683,14 @@ func main() {
 		app.initLogger()
 		app.initDatabase()
 
-		fmt.Println("Running database seed...")
-		if err := app.seedGameData(); err != nil {
+		target, options, err := parseSeedArgs(os.Args[2:])
+		if err != nil {
+			fmt.Printf("❌ Seed failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Running database seed (%s)...\n", target)
+		if err := app.runSeed(target, options); err != nil {
 			fmt.Printf("❌ Seed failed: %v\n", err)
 			os.Exit(1)
 		}
@@ -453,6 +698,29 @@ func main() {
 		return
 	}
 
+	// Check for migrate command: runs every module migration without serving
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := godotenv.Load(); err != nil {
+			fmt.Println("Warning: .env file not found")
+		}
+
+		app := New()
+		app.migrationSource = module.MigrationSourceCLI
+		started := time.Now()
+		app.initConfig()
+		app.initLogger()
+		app.initDatabase()
+		app.initInfrastructure()
+
+		fmt.Println("Running database migrations...")
+		if err := app.runMigrations(started); err != nil {
+			fmt.Printf("❌ Migrate failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("✅ Migrations completed successfully")
+		return
+	}
+
 	// Initialize the Base application
 	app := New()
 
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"base/core/config"
+	"base/core/logger"
+	"base/core/router"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// loadConfig reads the configuration of env with the default secrets
+func loadConfig(t *testing.T, env string) *config.Config {
+	t.Helper()
+	t.Setenv("ENV", env)
+	t.Setenv("JWT_SECRET", config.DefaultJWTSecret)
+	t.Setenv("API_KEY", config.DefaultAPIKey)
+	return config.NewConfig()
+}
+
+func TestCheckConfigRefusesProductionWithDefaultSecrets(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	err := checkConfig(loadConfig(t, "production"), logger.NewLoggerFromZap(zap.New(core)))
+	if err == nil {
+		t.Fatal("production config with default secrets was accepted")
+	}
+
+	var logged []string
+	for _, entry := range logs.FilterMessage("Invalid configuration").All() {
+		if entry.Level != zapcore.ErrorLevel {
+			t.Errorf("production config error logged at %s, want error", entry.Level)
+		}
+		logged = append(logged, entry.ContextMap()["error"].(string))
+	}
+	joined := strings.Join(logged, "\n")
+	for _, want := range []string{"JWT_SECRET must be changed", "API_KEY must be changed"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("logged errors %q do not mention %q", joined, want)
+		}
+	}
+}
+
+func TestCheckConfigOnlyWarnsInDevelopment(t *testing.T) {
+	cfg := loadConfig(t, "development")
+	cfg.EmailProvider, cfg.SMTPHost = "smtp", "" // An error in any environment
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	if err := checkConfig(cfg, logger.NewLoggerFromZap(zap.New(core))); err != nil {
+		t.Fatalf("development config was refused: %v", err)
+	}
+	entries := logs.FilterMessage("Invalid configuration").All()
+	if len(entries) == 0 {
+		t.Fatal("validation errors were not logged")
+	}
+	for _, entry := range entries {
+		if entry.Level != zapcore.WarnLevel {
+			t.Errorf("development config error logged at %s, want warn", entry.Level)
+		}
+	}
+}
+
+func TestSetupStaticRoutesServesConfiguredMounts(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello from assets"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	app := &App{
+		config: &config.Config{StaticMounts: []config.StaticMount{
+			{Prefix: "/assets", Dir: dir},
+			{Prefix: "/later", Dir: missing},
+		}},
+		router: router.New(),
+		logger: logger.NewLoggerFromZap(zap.New(core)),
+	}
+	app.setupStaticRoutes()
+
+	w := httptest.NewRecorder()
+	app.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/assets/hello.txt", nil))
+	if body, _ := io.ReadAll(w.Body); w.Code != http.StatusOK || string(body) != "hello from assets" {
+		t.Errorf("GET /assets/hello.txt: status %d, body %q", w.Code, body)
+	}
+
+	// Only configured mounts are served
+	w = httptest.NewRecorder()
+	app.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/static/hello.txt", nil))
+	if w.Code == http.StatusOK {
+		t.Error("an unconfigured default mount was served")
+	}
+
+	warnings := logs.FilterMessage("Static mount directory not found").All()
+	if len(warnings) != 1 || warnings[0].ContextMap()["dir"] != missing {
+		t.Errorf("missing directory warnings = %v, want one for %s", warnings, missing)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	appmodules "base/app"
+	coremodules "base/core/app"
+	"base/core/module"
+	"base/core/router"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// runMigrations runs the Migrate step of every core and app module, without
+// initializing them or serving routes, then prints each run recorded since
+// started. Game models are migrated by initDatabase and appear in the summary.
+func (app *App) runMigrations(started time.Time) error {
+	deps := module.Dependencies{
+		DB: app.db.DB,
+		// Some constructors take a router; nothing is served from it
+		Router:      router.New().Group("/api"),
+		Logger:      app.logger,
+		Emitter:     app.emitter,
+		Storage:     app.storage,
+		EmailSender: app.emailSender,
+		Config:      app.config,
+	}
+
+	modules := coremodules.NewCoreModules().GetCoreModules(deps)
+	for name, mod := range appmodules.NewAppModules().GetAppModules(deps) {
+		modules[name] = mod
+	}
+
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		mod := modules[name]
+		// Failures are recorded with the run and reported below
+		module.RunMigration(app.db.DB, name, mod.GetModels(), module.MigrationSourceCLI, mod.Migrate)
+	}
+
+	var runs []module.MigrationRun
+	if err := app.db.DB.Where("ran_at >= ?", started).Order("id ASC").Find(&runs).Error; err != nil {
+		return fmt.Errorf("failed to read migration runs: %w", err)
+	}
+	var failed []string
+	for _, run := range runs {
+		status := "✅"
+		if !run.Success {
+			status = "❌"
+			failed = append(failed, run.Module)
+		}
+		fmt.Printf("   %s %-20s %6dms  %s\n", status, run.Module, run.DurationMs, run.Tables)
+		if run.Error != "" {
+			fmt.Printf("      %s\n", run.Error)
+		}
+	}
+
+	if len(failed) > 0 {
+		return errors.New("migrations failed for " + strings.Join(failed, ", "))
+	}
+	return nil
+}
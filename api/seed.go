@@ -0,0 +1,230 @@
+package main
+
+import (
+	appmodules "base/app"
+	"base/app/models"
+	"base/core/app/authentication"
+	"base/core/app/authorization"
+	"base/core/config"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Targets accepted by the seed command: `seed [games|authz|admin|all]`
+const (
+	seedTargetGames = "games"
+	seedTargetAuthz = "authz"
+	seedTargetAdmin = "admin"
+	seedTargetAll   = "all"
+)
+
+// Flags of the seed command
+const (
+	seedFlagReconcile = "--reconcile"
+	seedFlagPrune     = "--prune"
+	seedFlagDeprecate = "--deprecate"
+)
+
+// seedOptions are the flags given to the seed command
+type seedOptions struct {
+	// reconcile updates the names, descriptions and deprecation of existing
+	// permissions to what the code declares
+	reconcile bool
+	// prune deletes permissions the code no longer declares; needs reconcile
+	prune bool
+	// deprecate marks permissions the code no longer declares deprecated
+	// instead, keeping them with the roles that have them; needs reconcile
+	deprecate bool
+}
+
+// undeclared is what reconciling does with undeclared permissions
+func (o seedOptions) undeclared() authorization.UndeclaredPolicy {
+	switch {
+	case o.prune:
+		return authorization.PruneUndeclared
+	case o.deprecate:
+		return authorization.DeprecateUndeclared
+	}
+	return authorization.KeepUndeclared
+}
+
+// parseSeedArgs reads `seed [target] [--reconcile [--prune|--deprecate]]`
+func parseSeedArgs(args []string) (string, seedOptions, error) {
+	target := seedTargetAll
+	var options seedOptions
+	targetSet := false
+	for _, arg := range args {
+		switch {
+		case arg == seedFlagReconcile:
+			options.reconcile = true
+		case arg == seedFlagPrune:
+			options.prune = true
+		case arg == seedFlagDeprecate:
+			options.deprecate = true
+		case strings.HasPrefix(arg, "-"):
+			return "", options, fmt.Errorf("unknown seed flag %q, expected %s, %s or %s", arg, seedFlagReconcile, seedFlagPrune, seedFlagDeprecate)
+		case !targetSet:
+			target, targetSet = arg, true
+		default:
+			return "", options, fmt.Errorf("unexpected seed argument %q", arg)
+		}
+	}
+	if options.prune && !options.reconcile {
+		return "", options, fmt.Errorf("%s requires %s", seedFlagPrune, seedFlagReconcile)
+	}
+	if options.deprecate && !options.reconcile {
+		return "", options, fmt.Errorf("%s requires %s", seedFlagDeprecate, seedFlagReconcile)
+	}
+	if options.prune && options.deprecate {
+		return "", options, fmt.Errorf("%s and %s cannot be combined", seedFlagPrune, seedFlagDeprecate)
+	}
+	return target, options, nil
+}
+
+// seedStep is one seeding job and the models it fills. Rows are counted
+// before and after the run so the command can report what it created and
+// what was already there.
+type seedStep struct {
+	name   string
+	models []any
+	run    func(db *gorm.DB) error
+}
+
+var seedSteps = map[string]seedStep{
+	seedTargetAuthz: {
+		name: "authorization",
+		models: []any{
+			&authorization.Role{},
+			&authorization.Permission{},
+			&authorization.RolePermission{},
+		},
+		run: seedAuthorizationData,
+	},
+	seedTargetGames: {
+		name:   "games",
+		models: []any{&models.Game{}, &models.Achievement{}},
+		run:    appmodules.SeedGamesData,
+	},
+}
+
+// seedAuthorizationData creates the authorization tables if needed, then the
+// default permissions, roles and role permissions. Every row is looked up
+// before it is created, so running it again changes nothing.
+func seedAuthorizationData(db *gorm.DB) error {
+	err := db.AutoMigrate(
+		&authorization.Role{},
+		&authorization.Permission{},
+		&authorization.RolePermission{},
+		&authorization.ResourcePermission{},
+		&authorization.ResourceAccess{},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to migrate authorization models: %w", err)
+	}
+	return authorization.NewAuthorizationService(db).SetupRolePermissions()
+}
+
+// adminSeedStep creates the Owner from BOOTSTRAP_ADMIN_EMAIL and
+// BOOTSTRAP_ADMIN_PASSWORD while there are no users. Unlike the other steps
+// it reads the configuration, so it is built per run.
+func adminSeedStep(cfg *config.Config) seedStep {
+	return seedStep{
+		name:   "admin",
+		models: []any{&authentication.AuthUser{}},
+		run: func(db *gorm.DB) error {
+			if cfg.BootstrapAdminEmail == "" {
+				fmt.Println("   BOOTSTRAP_ADMIN_EMAIL is not set, no admin to create")
+				return nil
+			}
+			if err := db.AutoMigrate(&authentication.AuthUser{}); err != nil {
+				return fmt.Errorf("failed to migrate users: %w", err)
+			}
+			_, err := authentication.NewAuthService(db, nil, nil).
+				BootstrapAdmin(cfg.BootstrapAdminEmail, cfg.BootstrapAdminPassword)
+			return err
+		},
+	}
+}
+
+// resolveSeedTargets maps a seed target to its steps; authorization runs
+// first so a fresh database has roles before anything else is created
+func resolveSeedTargets(target string, cfg *config.Config) ([]seedStep, error) {
+	switch strings.ToLower(target) {
+	case "", seedTargetAll:
+		return []seedStep{seedSteps[seedTargetAuthz], adminSeedStep(cfg), seedSteps[seedTargetGames]}, nil
+	case seedTargetAuthz, seedTargetGames:
+		return []seedStep{seedSteps[strings.ToLower(target)]}, nil
+	case seedTargetAdmin:
+		return []seedStep{adminSeedStep(cfg)}, nil
+	default:
+		return nil, fmt.Errorf("unknown seed target %q, expected %s, %s, %s or %s", target, seedTargetGames, seedTargetAuthz, seedTargetAdmin, seedTargetAll)
+	}
+}
+
+// runSeed runs the steps for target and prints, per table, how many rows
+// were created and how many already existed and were skipped. With the
+// reconcile option the permissions are then aligned with the code.
+func (app *App) runSeed(target string, options seedOptions) error {
+	steps, err := resolveSeedTargets(target, app.config)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		fmt.Printf("🌱 Seeding %s...\n", step.name)
+
+		before := make([]int64, len(step.models))
+		for i, model := range step.models {
+			// Tables that do not exist yet simply count as empty
+			app.db.DB.Model(model).Count(&before[i])
+		}
+
+		if err := step.run(app.db.DB); err != nil {
+			return fmt.Errorf("%s: %w", step.name, err)
+		}
+
+		for i, model := range step.models {
+			var after int64
+			if err := app.db.DB.Model(model).Count(&after).Error; err != nil {
+				return fmt.Errorf("%s: %w", step.name, err)
+			}
+			fmt.Printf("   %-20s %d created, %d skipped (already present)\n", seedTableName(app.db.DB, model), after-before[i], before[i])
+		}
+
+		if options.reconcile && step.name == seedSteps[seedTargetAuthz].name {
+			if err := reconcilePermissions(app.db.DB, options.undeclared()); err != nil {
+				return fmt.Errorf("%s: %w", step.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// reconcilePermissions aligns the permissions with the code and prints what
+// changed
+func reconcilePermissions(db *gorm.DB, undeclared authorization.UndeclaredPolicy) error {
+	fmt.Println("🔄 Reconciling permissions...")
+	result, err := authorization.NewAuthorizationService(db).ReconcilePermissions(undeclared)
+	if err != nil {
+		return err
+	}
+	switch undeclared {
+	case authorization.PruneUndeclared:
+		fmt.Printf("   %d added, %d updated, %d pruned\n", result.Added, result.Updated, result.Pruned)
+	case authorization.DeprecateUndeclared:
+		fmt.Printf("   %d added, %d updated, %d deprecated\n", result.Added, result.Updated, result.Deprecated)
+	default:
+		fmt.Printf("   %d added, %d updated (run with %s to deprecate or %s to delete undeclared permissions)\n", result.Added, result.Updated, seedFlagDeprecate, seedFlagPrune)
+	}
+	return nil
+}
+
+func seedTableName(db *gorm.DB, model any) string {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return fmt.Sprintf("%T", model)
+	}
+	return stmt.Table
+}